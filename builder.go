@@ -0,0 +1,132 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"flag"
+	"os"
+	"strings"
+)
+
+// Builder constructs, in a fluent chain, the loaders tree and the [DefaultConfig] based on it.
+//
+// Precedence is given by the order sources are declared in: a later declared source
+// overwrites an earlier declared source's same key. Defaults have always the lowest precedence,
+// no matter when they are declared.
+//
+// Example:
+//
+//	cfg, err := xconf.Build().
+//		Defaults(map[string]any{"APP_PORT": 8080}).
+//		File("config.yaml").
+//		OptionalFile("config.local.yaml").
+//		Consul("app/config", xconf.ConsulLoaderWithValueFormat(xconf.RemoteValueYAML)).
+//		EnvPrefix("APP").
+//		Flags(flag.CommandLine).
+//		Validate(validatePort).
+//		With(xconf.DefaultConfigWithReloadInterval(time.Minute)).
+//		New()
+type Builder struct {
+	defaults   map[string]any
+	sources    []Loader
+	validators []func(configMap map[string]any) error
+	configOpts []DefaultConfigOption
+}
+
+// Build starts a new Builder chain.
+func Build() *Builder {
+	return &Builder{}
+}
+
+// Defaults sets the default configuration, which has the lowest precedence.
+// Calling it multiple times merges the defaults.
+func (b *Builder) Defaults(configMap map[string]any) *Builder {
+	if b.defaults == nil {
+		b.defaults = make(map[string]any, len(configMap))
+	}
+	for key, value := range configMap {
+		b.defaults[key] = value
+	}
+
+	return b
+}
+
+// File adds a (mandatory) file source, see [FileLoader].
+func (b *Builder) File(filePath string, opts ...FileLoaderOption) *Builder {
+	return b.Source(FileLoader(filePath, opts...))
+}
+
+// OptionalFile adds a file source which is not mandatory to exist, see [FileLoader].
+func (b *Builder) OptionalFile(filePath string, opts ...FileLoaderOption) *Builder {
+	return b.Source(IgnoreErrorLoader(FileLoader(filePath, opts...), os.ErrNotExist))
+}
+
+// EnvPrefix adds the environment variables prefixed with given prefix (followed by "_") as a source.
+func (b *Builder) EnvPrefix(prefix string) *Builder {
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+
+	return b.Source(FilterKVLoader(EnvLoader(), FilterKVWhitelistFunc(FilterKeyWithPrefix(prefix))))
+}
+
+// Consul adds a Consul source, see [ConsulLoader].
+func (b *Builder) Consul(key string, opts ...ConsulLoaderOption) *Builder {
+	return b.Source(NewConsulLoader(key, opts...))
+}
+
+// Flags adds a flag set source, see [FlagSetLoader].
+func (b *Builder) Flags(flgSet *flag.FlagSet, visitAll ...bool) *Builder {
+	return b.Source(FlagSetLoader(flgSet, visitAll...))
+}
+
+// Source adds a custom loader as a source.
+func (b *Builder) Source(loader Loader) *Builder {
+	b.sources = append(b.sources, loader)
+
+	return b
+}
+
+// Validate adds validator(s) for the merged configuration map (see [ValidateLoader],
+// built-in rules like [RequiredKeys] can be passed, too).
+// A configuration which fails validation is rejected (the error is returned by New,
+// or, on reload, passed to the reload error handler, previous configuration remaining active).
+func (b *Builder) Validate(validators ...func(configMap map[string]any) error) *Builder {
+	b.validators = append(b.validators, validators...)
+
+	return b
+}
+
+// With adds DefaultConfig options.
+func (b *Builder) With(opts ...DefaultConfigOption) *Builder {
+	b.configOpts = append(b.configOpts, opts...)
+
+	return b
+}
+
+// Loader returns the loaders tree built so far.
+func (b *Builder) Loader() Loader {
+	loaders := make([]Loader, 0, len(b.sources)+1)
+	if b.defaults != nil {
+		loaders = append(loaders, PlainLoader(b.defaults))
+	}
+	loaders = append(loaders, b.sources...)
+	var loader Loader = NewMultiLoader(true, loaders...)
+	if len(b.validators) > 0 {
+		rules := make([]ValidationRule, len(b.validators))
+		for idx, validator := range b.validators {
+			rules[idx] = validator
+		}
+		loader = ValidateLoader(loader, rules...)
+	}
+
+	return loader
+}
+
+// New instantiates the DefaultConfig based on the built loaders tree.
+func (b *Builder) New() (*DefaultConfig, error) {
+	return NewDefaultConfig(b.Loader(), b.configOpts...)
+}
@@ -0,0 +1,13 @@
+//go:build !js
+
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+// Etcd adds an Etcd source, see [EtcdLoader].
+func (b *Builder) Etcd(key string, opts ...EtcdLoaderOption) *Builder {
+	return b.Source(NewEtcdLoader(key, opts...))
+}
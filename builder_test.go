@@ -0,0 +1,106 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestBuilder(t *testing.T) {
+	// Note: do not run this test with t.Parallel() as it sets ENVs.
+	t.Setenv("XCONF_BUILDER_JSON_FOO", "env foo")
+	t.Setenv("XCONF_BUILDER_OTHER", "env other")
+
+	t.Run("success - precedence", testBuilderPrecedence)
+	t.Run("success - optional file", testBuilderOptionalFile)
+	t.Run("error - mandatory file", testBuilderReturnsErrForMissingFile)
+	t.Run("error - validation", testBuilderReturnsValidationErr)
+}
+
+func testBuilderPrecedence(t *testing.T) {
+	// arrange
+	flgSet := flag.NewFlagSet("builder", flag.ContinueOnError)
+	flgSet.String("XCONF_BUILDER_OTHER", "", "")
+	requireNil(t, flgSet.Parse([]string{"-XCONF_BUILDER_OTHER=flag other"}))
+	subject := xconf.Build().
+		File(jsonFilePath).
+		Defaults(map[string]any{
+			"json_foo":  "default foo",
+			"json_year": 1999,
+			"port":      8080,
+		}).
+		Source(xconf.PlainLoader(map[string]any{"XCONF_BUILDER_JSON_FOO": "plain foo"})).
+		EnvPrefix("XCONF_BUILDER").
+		Flags(flgSet).
+		With(xconf.DefaultConfigWithIgnoreCaseSensitivity())
+
+	// act
+	config, err := subject.New()
+
+	// assert
+	requireNil(t, err)
+	defer config.Close()
+	assertEqual(t, "bar", config.Get("json_foo"))                   // file overwrites defaults
+	assertEqual(t, 2022.0, config.Get("json_year"))                 // file overwrites defaults
+	assertEqual(t, 8080, config.Get("port"))                        // defaults
+	assertEqual(t, "env foo", config.Get("XCONF_BUILDER_JSON_FOO")) // env overwrites previous source
+	assertEqual(t, "flag other", config.Get("xconf_builder_other")) // flags overwrite env
+}
+
+func testBuilderOptionalFile(t *testing.T) {
+	// arrange
+	subject := xconf.Build().
+		Defaults(map[string]any{"foo": "bar"}).
+		OptionalFile("testdata/this-file-does-not-exist.json")
+
+	// act
+	config, err := subject.Loader().Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"foo": "bar"}, config)
+}
+
+func testBuilderReturnsErrForMissingFile(t *testing.T) {
+	// arrange
+	subject := xconf.Build().File("testdata/this-file-does-not-exist.json")
+
+	// act
+	config, err := subject.New()
+
+	// assert
+	assertTrue(t, errors.Is(err, os.ErrNotExist))
+	assertNil(t, config)
+}
+
+func testBuilderReturnsValidationErr(t *testing.T) {
+	// arrange
+	var (
+		expectedErr = errors.New("port is mandatory")
+		validator   = func(configMap map[string]any) error {
+			if _, found := configMap["port"]; !found {
+				return expectedErr
+			}
+
+			return nil
+		}
+		subject = xconf.Build().
+			Defaults(map[string]any{"host": "localhost"}).
+			Validate(validator)
+	)
+
+	// act
+	config, err := subject.New()
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertNil(t, config)
+}
@@ -0,0 +1,120 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/gob"
+	"time"
+)
+
+func init() {
+	// register types commonly found in configuration maps, for gob to be able to (de)serialize them.
+	gob.Register(map[string]any{})
+	gob.Register(map[any]any{})
+	gob.Register([]any{})
+	gob.Register([]map[string]any{})
+	gob.Register(time.Time{})
+	gob.Register(time.Duration(0))
+}
+
+// cacheObfuscator serializes and obfuscates configuration maps stored by cache layers,
+// so that secrets are not exposed in plaintext in core dumps of long-running processes.
+// Note: it is an obfuscation, not a protection: the key lives in process' memory, too.
+type cacheObfuscator struct {
+	block cipher.Block // the cipher, with a random key.
+}
+
+// newCacheObfuscator instantiates a new cacheObfuscator with a random key.
+func newCacheObfuscator() *cacheObfuscator {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	block, _ := aes.NewCipher(key) // Note: it never fails for a 32 bytes key.
+
+	return &cacheObfuscator{block: block}
+}
+
+// seal serializes and obfuscates given configuration map.
+func (obfuscator *cacheObfuscator) seal(configMap map[string]any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(configMap); err != nil {
+		return nil, err
+	}
+	blob := make([]byte, aes.BlockSize+buf.Len())
+	iv := blob[:aes.BlockSize]
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	cipher.NewCTR(obfuscator.block, iv).XORKeyStream(blob[aes.BlockSize:], buf.Bytes())
+
+	return blob, nil
+}
+
+// open deobfuscates and deserializes given blob into a configuration map.
+func (obfuscator *cacheObfuscator) open(blob []byte) (map[string]any, error) {
+	content := make([]byte, len(blob)-aes.BlockSize)
+	cipher.NewCTR(obfuscator.block, blob[:aes.BlockSize]).XORKeyStream(content, blob[aes.BlockSize:])
+	var configMap map[string]any
+	if err := gob.NewDecoder(bytes.NewReader(content)).Decode(&configMap); err != nil {
+		return nil, err
+	}
+
+	return configMap, nil
+}
+
+// cacheEntry holds a cached configuration map, either live,
+// or serialized and obfuscated, if an obfuscator is used.
+type cacheEntry struct {
+	configMap map[string]any // live config map.
+	blob      []byte         // serialized and obfuscated config map.
+}
+
+// newCacheEntry returns a cache entry for a copy of given configuration map.
+// If the configuration map cannot be serialized (it contains values of unregistered
+// types - see [gob.Register]), an empty entry is returned, thus nothing gets cached.
+func newCacheEntry(configMap map[string]any, obfuscator *cacheObfuscator) cacheEntry {
+	if configMap == nil {
+		return cacheEntry{}
+	}
+	if obfuscator == nil {
+		return cacheEntry{configMap: DeepCopyConfigMap(configMap)}
+	}
+	blob, err := obfuscator.seal(configMap)
+	if err != nil {
+		return cacheEntry{}
+	}
+
+	return cacheEntry{blob: blob}
+}
+
+// isZero returns true if nothing is cached.
+func (entry cacheEntry) isZero() bool {
+	return entry.configMap == nil && entry.blob == nil
+}
+
+// load returns a new copy of the cached configuration map, or nil, if nothing is cached.
+func (entry cacheEntry) load(obfuscator *cacheObfuscator) map[string]any {
+	if entry.isZero() {
+		return nil
+	}
+	if entry.blob != nil {
+		configMap, err := obfuscator.open(entry.blob)
+		if err != nil {
+			return nil
+		}
+
+		return configMap
+	}
+
+	// return a copy not to modify this state from outside (for example from a decorator,
+	// which usually modifies directly the original returned configuration map reference
+	// - for performance reasons, so we ensure from this stateful loader that we return a
+	// new configuration map each time)
+	return DeepCopyConfigMap(entry.configMap)
+}
@@ -0,0 +1,199 @@
+//go:build !js
+
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/actforgood/xconf"
+)
+
+// errKeyNotFound is returned by get command if the key does not exist.
+var errKeyNotFound = errors.New("key not found")
+
+// newCommandFlagSet returns a flag set for a command.
+func newCommandFlagSet(name, usage string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: xconf [source flags] "+usage)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// runGet prints a key's value (nested keys can be looked up with "." delimiter, like "db.host").
+// Strings are printed as they are, other values JSON encoded.
+func runGet(sources *sourceFlags, args []string, stdout, stderr io.Writer) error {
+	fs := newCommandFlagSet("get", "get [-key-delimiter .] <key>", stderr)
+	keyDelimiter := fs.String("key-delimiter", ".", "nested keys' `delimiter`")
+	positional, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+
+		return errUsage
+	}
+	key := positional[0]
+
+	builder, err := sources.builder()
+	if err != nil {
+		return err
+	}
+	config, err := builder.With(xconf.DefaultConfigWithKeyDelimiter(*keyDelimiter)).New()
+	if err != nil {
+		return err
+	}
+	defer config.Close()
+
+	value := config.Get(key)
+	if value == nil {
+		return fmt.Errorf("%w: %s", errKeyNotFound, key)
+	}
+	if strValue, isStr := value.(string); isStr {
+		_, err = fmt.Fprintln(stdout, strValue)
+
+		return err
+	}
+	content, err := json.Marshal(jsonCompatible(value))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, string(content))
+
+	return err
+}
+
+// runDump prints the effective configuration, in the requested format.
+func runDump(sources *sourceFlags, args []string, stdout, stderr io.Writer) error {
+	fs := newCommandFlagSet("dump", "dump [-format yaml] [-redact=true]", stderr)
+	format := fs.String("format", xconf.DumpFormatYAML, "output `format`: json, yaml, toml, properties, env")
+	redact := fs.Bool("redact", true, "mask secrets' values (keys like *password*, *secret*, *token*, ...)")
+	if _, err := parseInterleaved(fs, args); err != nil {
+		return err
+	}
+
+	builder, err := sources.builder()
+	if err != nil {
+		return err
+	}
+	config, err := builder.New()
+	if err != nil {
+		return err
+	}
+	defer config.Close()
+
+	var opts []xconf.DumpOption
+	if *redact {
+		opts = append(opts, xconf.DumpWithRedaction())
+	}
+
+	return xconf.Dump(config, stdout, *format, opts...)
+}
+
+// runValidate validates the configuration against a JSON Schema.
+// Nested keys are validated through their flattened, "." delimited, keys.
+func runValidate(sources *sourceFlags, args []string, stdout, stderr io.Writer) error {
+	fs := newCommandFlagSet("validate", "validate -schema schema.json", stderr)
+	schemaPath := fs.String("schema", "", "JSON Schema `file`")
+	if _, err := parseInterleaved(fs, args); err != nil {
+		return err
+	}
+	if *schemaPath == "" {
+		fs.Usage()
+
+		return errUsage
+	}
+
+	rules, err := readSchemaRules(*schemaPath)
+	if err != nil {
+		return err
+	}
+	builder, err := sources.builder()
+	if err != nil {
+		return err
+	}
+	loader := xconf.ValidateLoader(xconf.NewFlattenLoader(builder.Loader()), rules...)
+	if _, err := loader.Load(); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, "configuration is valid")
+
+	return err
+}
+
+// runConvert converts a configuration file into another format.
+func runConvert(args []string, stdout, stderr io.Writer) error {
+	fs := newCommandFlagSet("convert", "convert <file> -to json", stderr)
+	format := fs.String("to", xconf.DumpFormatJSON, "output `format`: json, yaml, toml, properties, env")
+	output := fs.String("o", "", "output `file` (default stdout)")
+	positional, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+
+		return errUsage
+	}
+
+	configMap, err := xconf.FileLoader(positional[0]).Load()
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		return xconf.DumpConfigMap(configMap, stdout, *format)
+	}
+
+	var buf bytes.Buffer
+	if err := xconf.DumpConfigMap(configMap, &buf, *format); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Clean(*output), buf.Bytes(), 0o600)
+}
+
+// jsonCompatible converts map[any]any values (as decoded by some formats)
+// into map[string]any, so that they can be JSON encoded.
+func jsonCompatible(value any) any {
+	switch v := value.(type) {
+	case map[any]any:
+		result := make(map[string]any, len(v))
+		for key, nestedValue := range v {
+			result[fmt.Sprint(key)] = jsonCompatible(nestedValue)
+		}
+
+		return result
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, nestedValue := range v {
+			result[key] = jsonCompatible(nestedValue)
+		}
+
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for idx, item := range v {
+			result[idx] = jsonCompatible(item)
+		}
+
+		return result
+	default:
+		return value
+	}
+}
@@ -0,0 +1,129 @@
+//go:build !js
+
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+// Command xconf inspects and converts configuration, assembling xconf loaders
+// from command line flags / environment variables.
+//
+// Usage:
+//
+//	xconf [source flags] <command> [command flags] [args]
+//
+// Commands:
+//
+//	get <key>                          prints a key's value.
+//	dump [-format yaml] [-redact=true] prints the effective configuration.
+//	validate -schema schema.json       validates the configuration against a JSON Schema (subset).
+//	convert <file> -to json            converts a configuration file to another format.
+//
+// Source flags (each one has an environment variable counterpart, used as default value):
+//
+//	-file path          configuration file (json/yaml/toml/ini/properties/env), can be repeated (XCONF_FILES, comma separated).
+//	-consul-key key     Consul KV key (XCONF_CONSUL_KEY).
+//	-consul-host host   Consul host (XCONF_CONSUL_HOST).
+//	-consul-format fmt  Consul value format (XCONF_CONSUL_FORMAT).
+//	-etcd-key key       Etcd key (XCONF_ETCD_KEY).
+//	-etcd-endpoints eps Etcd endpoints, comma separated (XCONF_ETCD_ENDPOINTS).
+//	-etcd-format fmt    Etcd value format (XCONF_ETCD_FORMAT).
+//	-env-prefix prefix  environment variables prefix (XCONF_ENV_PREFIX).
+//
+// Sources are merged in the order above, a later source overwriting an earlier source's same key.
+//
+// Examples:
+//
+//	xconf -file config.yaml -env-prefix APP get db.host
+//	xconf -consul-key app/config -consul-format yaml dump -format json
+//	xconf -file config.yaml validate -schema schema.json
+//	xconf convert config.toml -to json
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// exit codes.
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// errUsage is returned for an invalid command line.
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Getenv, os.Stdout, os.Stderr))
+}
+
+// run executes the command given by args, and returns the exit code.
+func run(args []string, getenv func(string) string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("xconf", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	sources := newSourceFlags(fs, getenv)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: xconf [source flags] <get|dump|validate|convert> [command flags] [args]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+
+		return exitUsage
+	}
+
+	var (
+		cmdArgs = fs.Args()[1:]
+		err     error
+	)
+	switch fs.Arg(0) {
+	case "get":
+		err = runGet(sources, cmdArgs, stdout, stderr)
+	case "dump":
+		err = runDump(sources, cmdArgs, stdout, stderr)
+	case "validate":
+		err = runValidate(sources, cmdArgs, stdout, stderr)
+	case "convert":
+		err = runConvert(cmdArgs, stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "xconf: unknown command %q\n", fs.Arg(0))
+		fs.Usage()
+
+		return exitUsage
+	}
+
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errUsage), errors.Is(err, flag.ErrHelp):
+		return exitUsage
+	default:
+		fmt.Fprintln(stderr, "xconf:", err)
+
+		return exitFailure
+	}
+}
+
+// parseInterleaved parses flags which may be given also after positional arguments
+// (like "convert config.toml -to json"), and returns the positional arguments.
+func parseInterleaved(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
@@ -0,0 +1,252 @@
+//go:build !js
+
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const yamlConfig = `app:
+  name: demo
+  port: 8080
+db:
+  host: localhost
+  password: s3cr3t
+log_level: debug
+`
+
+// writeFile writes a file in a temporary directory, and returns its path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(filePath, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return filePath
+}
+
+// runCmd runs the command with given args and env, and returns its exit code and outputs.
+func runCmd(args []string, env map[string]string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	getenv := func(name string) string { return env[name] }
+	code := run(args, getenv, &stdout, &stderr)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - get", testRunGet)
+	t.Run("error - get missing key", testRunGetMissingKey)
+	t.Run("success - dump", testRunDump)
+	t.Run("success - validate", testRunValidate)
+	t.Run("error - validate", testRunValidateFails)
+	t.Run("success - convert", testRunConvert)
+	t.Run("success - sources from env", testRunSourcesFromEnv)
+	t.Run("error - usage", testRunUsageErrors)
+}
+
+func testRunGet(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	configFile := writeFile(t, "config.yaml", yamlConfig)
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{key: "log_level", expected: "debug\n"},
+		{key: "app.port", expected: "8080\n"},
+		{key: "app", expected: `{"name":"demo","port":8080}` + "\n"},
+	}
+
+	for _, test := range tests {
+		// act
+		code, stdout, stderr := runCmd([]string{"-file", configFile, "get", test.key}, nil)
+
+		// assert
+		if code != exitOK || stdout != test.expected {
+			t.Errorf("key %q: expected %q, got %q (code %d, stderr %q)", test.key, test.expected, stdout, code, stderr)
+		}
+	}
+}
+
+func testRunGetMissingKey(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	configFile := writeFile(t, "config.yaml", yamlConfig)
+
+	// act
+	code, stdout, stderr := runCmd([]string{"-file", configFile, "get", "db.port"}, nil)
+
+	// assert
+	if code != exitFailure || stdout != "" || !strings.Contains(stderr, "key not found: db.port") {
+		t.Errorf("unexpected result: code %d, stdout %q, stderr %q", code, stdout, stderr)
+	}
+}
+
+func testRunDump(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	configFile := writeFile(t, "config.yaml", yamlConfig)
+
+	// act
+	code, stdout, stderr := runCmd([]string{"-file", configFile, "dump", "-format", "json"}, nil)
+
+	// assert
+	if code != exitOK {
+		t.Fatalf("unexpected code %d, stderr %q", code, stderr)
+	}
+	if !strings.Contains(stdout, `"password": "*****"`) || strings.Contains(stdout, "s3cr3t") {
+		t.Errorf("expected password to be redacted, got %s", stdout)
+	}
+	if !strings.Contains(stdout, `"host": "localhost"`) {
+		t.Errorf("expected host to be dumped, got %s", stdout)
+	}
+}
+
+func testRunValidate(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	configFile := writeFile(t, "config.yaml", yamlConfig)
+	schemaFile := writeFile(t, "schema.json", `{
+		"type": "object",
+		"required": ["app", "db"],
+		"properties": {
+			"app": {
+				"type": "object",
+				"required": ["name"],
+				"properties": {
+					"name": {"type": "string", "pattern": "^[a-z]+$"},
+					"port": {"type": "integer", "minimum": 1, "maximum": 65535}
+				}
+			},
+			"log_level": {"enum": ["debug", "info", "error"]}
+		}
+	}`)
+
+	// act
+	code, stdout, stderr := runCmd([]string{"-file", configFile, "validate", "-schema", schemaFile}, nil)
+
+	// assert
+	if code != exitOK || stdout != "configuration is valid\n" {
+		t.Errorf("unexpected result: code %d, stdout %q, stderr %q", code, stdout, stderr)
+	}
+}
+
+func testRunValidateFails(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	configFile := writeFile(t, "config.yaml", yamlConfig)
+	schemaFile := writeFile(t, "schema.json", `{
+		"required": ["cache"],
+		"properties": {
+			"app": {
+				"properties": {
+					"port": {"type": "integer", "maximum": 1024}
+				}
+			},
+			"log_level": {"enum": ["info", "error"]}
+		}
+	}`)
+
+	// act
+	code, _, stderr := runCmd([]string{"-file", configFile, "validate", "-schema", schemaFile}, nil)
+
+	// assert
+	if code != exitFailure {
+		t.Errorf("expected code %d, got %d", exitFailure, code)
+	}
+	for _, expected := range []string{`"cache" is required`, `"app.port"`, `"log_level"`} {
+		if !strings.Contains(stderr, expected) {
+			t.Errorf("expected stderr to contain %s, got %q", expected, stderr)
+		}
+	}
+}
+
+func testRunConvert(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	configFile := writeFile(t, "config.toml", "[app]\nname = \"demo\"\nport = 8080\n")
+	outputFile := filepath.Join(t.TempDir(), "config.json")
+
+	// act
+	code, stdout, stderr := runCmd([]string{"convert", configFile, "-to", "json"}, nil)
+	codeFile, _, _ := runCmd([]string{"convert", "-to", "yaml", "-o", outputFile, configFile}, nil)
+
+	// assert
+	expected := "{\n  \"app\": {\n    \"name\": \"demo\",\n    \"port\": 8080\n  }\n}\n"
+	if code != exitOK || stdout != expected {
+		t.Errorf("expected %q, got %q (code %d, stderr %q)", expected, stdout, code, stderr)
+	}
+	content, err := os.ReadFile(outputFile)
+	if codeFile != exitOK || err != nil || string(content) != "app:\n  name: demo\n  port: 8080\n" {
+		t.Errorf("unexpected output file content %q (code %d, err %v)", content, codeFile, err)
+	}
+}
+
+func testRunSourcesFromEnv(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	configFile := writeFile(t, "config.yaml", yamlConfig)
+	otherFile := writeFile(t, "other.yaml", "log_level: info\n")
+
+	// act
+	code, stdout, stderr := runCmd([]string{"get", "log_level"}, map[string]string{"XCONF_FILES": configFile})
+	codeFlag, stdoutFlag, _ := runCmd(
+		[]string{"-file", otherFile, "get", "log_level"},
+		map[string]string{"XCONF_FILES": configFile},
+	)
+
+	// assert
+	if code != exitOK || stdout != "debug\n" {
+		t.Errorf("unexpected result: code %d, stdout %q, stderr %q", code, stdout, stderr)
+	}
+	if codeFlag != exitOK || stdoutFlag != "info\n" {
+		t.Errorf("expected flag to take precedence over env, got code %d, stdout %q", codeFlag, stdoutFlag)
+	}
+}
+
+func testRunUsageErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		args         []string
+		expectedCode int
+	}{
+		{name: "no command", args: nil, expectedCode: exitUsage},
+		{name: "unknown command", args: []string{"-file", "config.yaml", "set"}, expectedCode: exitUsage},
+		{name: "get without key", args: []string{"-file", "config.yaml", "get"}, expectedCode: exitUsage},
+		{name: "validate without schema", args: []string{"-file", "config.yaml", "validate"}, expectedCode: exitUsage},
+		{name: "no source", args: []string{"get", "foo"}, expectedCode: exitFailure},
+	}
+
+	for _, test := range tests {
+		// act
+		code, _, _ := runCmd(test.args, nil)
+
+		// assert
+		if code != test.expectedCode {
+			t.Errorf("%s: expected code %d, got %d", test.name, test.expectedCode, code)
+		}
+	}
+}
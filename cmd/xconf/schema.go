@@ -0,0 +1,123 @@
+//go:build !js
+
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+
+	"github.com/actforgood/xconf"
+)
+
+// jsonSchema is the supported subset of a JSON Schema.
+type jsonSchema struct {
+	Type       string                 `json:"type"`
+	Properties map[string]*jsonSchema `json:"properties"`
+	Required   []string               `json:"required"`
+	Pattern    string                 `json:"pattern"`
+	Minimum    *float64               `json:"minimum"`
+	Maximum    *float64               `json:"maximum"`
+	Enum       []any                  `json:"enum"`
+}
+
+// schemaTypeSamples holds a sample value for JSON Schema's scalar types, used for casting.
+var schemaTypeSamples = map[string]any{
+	"string":  "",
+	"integer": 0,
+	"number":  0.0,
+	"boolean": false,
+}
+
+// readSchemaRules reads a JSON Schema file and translates it into validation rules.
+// Supported keywords: type, properties, required, pattern, minimum, maximum, enum.
+func readSchemaRules(schemaPath string) ([]xconf.ValidationRule, error) {
+	content, err := os.ReadFile(filepath.Clean(schemaPath))
+	if err != nil {
+		return nil, err
+	}
+	var schema jsonSchema
+	if err := json.Unmarshal(content, &schema); err != nil {
+		return nil, fmt.Errorf("invalid schema %s: %w", schemaPath, err)
+	}
+
+	return schema.rules("", nil)
+}
+
+// rules appends the validation rules of given (object) schema, whose properties' keys are prefixed with prefix.
+func (schema *jsonSchema) rules(prefix string, rules []xconf.ValidationRule) ([]xconf.ValidationRule, error) {
+	for _, key := range schema.Required {
+		rules = append(rules, xconf.RequiredKeys(prefix+key))
+	}
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, key := schema.Properties[name], prefix+name
+		if property == nil {
+			continue
+		}
+		if sample, isScalar := schemaTypeSamples[property.Type]; isScalar {
+			rules = append(rules, xconf.KeyType(key, sample))
+		}
+		if property.Pattern != "" {
+			pattern, err := regexp.Compile(property.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern of %q: %w", key, err)
+			}
+			rules = append(rules, xconf.KeyPattern(key, pattern))
+		}
+		if property.Minimum != nil || property.Maximum != nil {
+			minimum, maximum := math.Inf(-1), math.Inf(1)
+			if property.Minimum != nil {
+				minimum = *property.Minimum
+			}
+			if property.Maximum != nil {
+				maximum = *property.Maximum
+			}
+			rules = append(rules, xconf.KeyRange(key, minimum, maximum))
+		}
+		if len(property.Enum) > 0 {
+			rules = append(rules, enumRule(key, property.Enum))
+		}
+		if property.Type == "object" || len(property.Properties) > 0 {
+			var err error
+			if rules, err = property.rules(key+".", rules); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return rules, nil
+}
+
+// enumRule is a validation rule which checks that given key's value is one of the allowed ones.
+// A missing key is not checked.
+func enumRule(key string, allowed []any) xconf.ValidationRule {
+	return func(configMap map[string]any) error {
+		value, found := configMap[key]
+		if !found {
+			return nil
+		}
+		for _, allowedValue := range allowed {
+			if reflect.DeepEqual(value, allowedValue) || fmt.Sprint(value) == fmt.Sprint(allowedValue) {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("%w: key %q: value %#v is not one of %v", xconf.ErrValidation, key, value, allowed)
+	}
+}
@@ -0,0 +1,118 @@
+//go:build !js
+
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"strings"
+
+	"github.com/actforgood/xconf"
+)
+
+// errNoSource is returned if no configuration source was given.
+var errNoSource = errors.New("no configuration source given (see -file, -consul-key, -etcd-key, -env-prefix)")
+
+// sourceFlags holds the flags configuring the configuration sources.
+type sourceFlags struct {
+	files         listFlag
+	consulKey     *string
+	consulHost    *string
+	consulFormat  *string
+	etcdKey       *string
+	etcdEndpoints *string
+	etcdFormat    *string
+	envPrefix     *string
+}
+
+// newSourceFlags defines the source flags on given flag set,
+// with their environment variables' values as defaults.
+func newSourceFlags(fs *flag.FlagSet, getenv func(string) string) *sourceFlags {
+	sources := &sourceFlags{}
+	if files := getenv("XCONF_FILES"); files != "" {
+		sources.files = listFlag{values: strings.Split(files, ","), isDefault: true}
+	}
+	fs.Var(&sources.files, "file", "configuration `file` (json/yaml/toml/ini/properties/env), can be repeated")
+	sources.consulKey = fs.String("consul-key", getenv("XCONF_CONSUL_KEY"), "Consul KV `key`")
+	sources.consulHost = fs.String("consul-host", getenv("XCONF_CONSUL_HOST"), "Consul `host`")
+	sources.consulFormat = fs.String("consul-format", getenv("XCONF_CONSUL_FORMAT"), "Consul value `format`")
+	sources.etcdKey = fs.String("etcd-key", getenv("XCONF_ETCD_KEY"), "Etcd `key`")
+	sources.etcdEndpoints = fs.String(
+		"etcd-endpoints",
+		getenv("XCONF_ETCD_ENDPOINTS"),
+		"Etcd `endpoints`, comma separated",
+	)
+	sources.etcdFormat = fs.String("etcd-format", getenv("XCONF_ETCD_FORMAT"), "Etcd value `format`")
+	sources.envPrefix = fs.String("env-prefix", getenv("XCONF_ENV_PREFIX"), "environment variables `prefix`")
+
+	return sources
+}
+
+// builder returns a builder with the configured sources.
+func (sources *sourceFlags) builder() (*xconf.Builder, error) {
+	builder := xconf.Build()
+	hasSource := false
+	for _, filePath := range sources.files.values {
+		if filePath = strings.TrimSpace(filePath); filePath != "" {
+			builder.File(filePath)
+			hasSource = true
+		}
+	}
+	if *sources.consulKey != "" {
+		var opts []xconf.ConsulLoaderOption
+		if *sources.consulHost != "" {
+			opts = append(opts, xconf.ConsulLoaderWithHost(*sources.consulHost))
+		}
+		if *sources.consulFormat != "" {
+			opts = append(opts, xconf.ConsulLoaderWithValueFormat(*sources.consulFormat))
+		}
+		builder.Consul(*sources.consulKey, opts...)
+		hasSource = true
+	}
+	if *sources.etcdKey != "" {
+		var opts []xconf.EtcdLoaderOption
+		if *sources.etcdEndpoints != "" {
+			opts = append(opts, xconf.EtcdLoaderWithEndpoints(strings.Split(*sources.etcdEndpoints, ",")))
+		}
+		if *sources.etcdFormat != "" {
+			opts = append(opts, xconf.EtcdLoaderWithValueFormat(*sources.etcdFormat))
+		}
+		builder.Etcd(*sources.etcdKey, opts...)
+		hasSource = true
+	}
+	if *sources.envPrefix != "" {
+		builder.EnvPrefix(*sources.envPrefix)
+		hasSource = true
+	}
+	if !hasSource {
+		return nil, errNoSource
+	}
+
+	return builder, nil
+}
+
+// listFlag is a flag which can be repeated.
+type listFlag struct {
+	values    []string
+	isDefault bool // values are the default ones, replaced on first Set.
+}
+
+// String returns the values, comma separated. It implements [flag.Value].
+func (list *listFlag) String() string {
+	return strings.Join(list.values, ",")
+}
+
+// Set appends a value. It implements [flag.Value].
+func (list *listFlag) Set(value string) error {
+	if list.isDefault {
+		list.values, list.isDefault = nil, false
+	}
+	list.values = append(list.values, value)
+
+	return nil
+}
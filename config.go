@@ -6,10 +6,12 @@
 package xconf
 
 import (
+	"context"
 	"reflect"
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/spf13/cast"
@@ -37,13 +39,28 @@ type DefaultConfig struct {
 type defaultConfig struct {
 	// loader to retrieve configuration from.
 	loader Loader
-	// configMap the loaded key-value configuration map.
+	// configMap the effective key-value configuration map (loaded one, with overrides applied).
 	configMap map[string]any
+	// loadedConfigMap the key-value configuration map, as it was loaded (keys are not uppercased).
+	loadedConfigMap map[string]any
+	// overrides hold the runtime overridden keys (see Set / Unset).
+	// The map is never modified in place, but replaced, on setting / unsetting.
+	overrides map[string]any
 	// observers contain the list of registered observers for changed keys.
-	observers []ConfigObserver
+	// The slice is never modified in place, but replaced, on (un)registering.
+	observers []registeredObserver
+	// watchers contain the list of registered key / prefix watchers (see Watch).
+	// The slice is never modified in place, but replaced, on (un)registering.
+	watchers []*watcher
+	// lastObserverHandle is the handle of the last registered observer.
+	lastObserverHandle ObserverHandle
 	// refreshInterval represents the interval to reload the configMap.
 	// If it is <=0, reload will be disabled.
 	reloadInterval time.Duration
+	// secretKeys are the globs of the keys whose values are masked in notifications / dumps / errors.
+	secretKeys []string
+	// reloadTimeout bounds how long a single (re)load may take. If it is <=0, there is no timeout.
+	reloadTimeout time.Duration
 	// reloadErrorHandler is an optional handler for errors occurred during reloading configuration.
 	// You can log the error, for example.
 	reloadErrorHandler func(error)
@@ -51,12 +68,43 @@ type defaultConfig struct {
 	ticker *time.Ticker
 	// ignoreCaseSensitivity is a flag indicating whether keys' case sensitivity should be ignored.
 	ignoreCaseSensitivity bool
+	// keyDelimiter is the delimiter of nested keys' segments, if nested keys lookup is enabled.
+	keyDelimiter string
+	// notifyInitialLoad is a flag indicating whether an observer should be notified
+	// with all the keys, at registration.
+	notifyInitialLoad bool
 	// mu is a concurrency semaphore for accessing the configMap.
 	mu *sync.RWMutex
 	// wg is a wait group used to notify main thread that reload goroutine stopped.
 	wg *sync.WaitGroup
 	// closed is a channel to notify reload goroutine to stop.
 	closed chan struct{}
+	// loadCtx is the parent of the context passed to loader at each (re)load.
+	// It gets canceled on Close, so that a hung load does not block closing.
+	loadCtx context.Context
+	// cancelLoadCtx cancels loadCtx.
+	cancelLoadCtx context.CancelFunc
+	// closeOnce is used to close the config only once.
+	closeOnce *sync.Once
+	// isClosed holds the closed state.
+	isClosed int32
+	// credentialExpiry is used to renew credentials before they expire, if enabled.
+	credentialExpiry *credentialExpiry
+	// snapshots is used to write loaded configurations on disk, if enabled.
+	snapshots *snapshots
+	// castFailureHandler is an optional handler for Get's cast failures.
+	castFailureHandler func(CastFailure)
+	// keysMetadata holds keys' documentation / metadata (see Meta).
+	// The map is never modified in place, but replaced, on registering.
+	keysMetadata KeysMetadata
+	// deprecation is used to enforce keys' removal timeline, if enabled.
+	deprecation *deprecationEnforcement
+	// validationRules are the rules every loaded configuration is validated against.
+	validationRules []ValidationRule
+	// notifyDebounce is used to coalesce observers' notifications, if enabled.
+	notifyDebounce *notifyDebounce
+	// logger is an optional logger for the reload lifecycle.
+	logger Logger
 }
 
 // NewDefaultConfig instantiates a new default config object.
@@ -64,9 +112,11 @@ type defaultConfig struct {
 // The second parameter represents a list of optional functions to configure the object.
 func NewDefaultConfig(loader Loader, opts ...DefaultConfigOption) (*DefaultConfig, error) {
 	config := &DefaultConfig{&defaultConfig{
-		loader: loader,
-		mu:     new(sync.RWMutex),
+		loader:    loader,
+		mu:        new(sync.RWMutex),
+		closeOnce: new(sync.Once),
 	}}
+	config.loadCtx, config.cancelLoadCtx = context.WithCancel(context.Background())
 
 	// apply options, if any.
 	for _, opt := range opts {
@@ -74,6 +124,8 @@ func NewDefaultConfig(loader Loader, opts ...DefaultConfigOption) (*DefaultConfi
 	}
 
 	if err := config.setConfigMap(); err != nil {
+		config.cancelLoadCtx()
+
 		return nil, err
 	}
 
@@ -81,6 +133,9 @@ func NewDefaultConfig(loader Loader, opts ...DefaultConfigOption) (*DefaultConfi
 		config.ticker = time.NewTicker(config.reloadInterval)
 		config.wg = new(sync.WaitGroup)
 		config.closed = make(chan struct{}, 1)
+		if config.credentialExpiry != nil {
+			config.scheduleCredentialRenewal()
+		}
 		config.wg.Add(1)
 		go config.reloadAsync()
 		// register also a finalizer, just in case, user forgets to call Close().
@@ -100,7 +155,46 @@ func NewDefaultConfig(loader Loader, opts ...DefaultConfigOption) (*DefaultConfi
 // Only basic types (string, bool, int, uint, float, and their flavours),
 // time.Duration, time.Time, []int, []string are covered.
 // If a cast error occurs, the defaultValue is returned.
+// After Close, the last loaded configuration is still served.
 func (cfg *defaultConfig) Get(key string, def ...any) any {
+	return cfg.get(key, cfg.keyDelimiter, def...)
+}
+
+// GetE is like Get, but, instead of silently returning the default value
+// if key's value cannot be casted to default value's type, it also returns
+// the [CastFailure] as error (see [ErrCastFailed]), so applications can log / fail fast.
+// The cast failure handler, if any, is not called, the error being returned to caller.
+//
+// Usage example:
+//
+//	timeout, err := cfg.GetE("http.timeout", 5*time.Second)
+//	if err != nil {
+//		return err // key "http.timeout": cannot cast "30seconds" (string) to time.Duration...
+//	}
+func (cfg *defaultConfig) GetE(key string, def ...any) (any, error) {
+	value, failure := cfg.lookup(key, cfg.keyDelimiter, def...)
+	if failure != nil {
+		return value, *failure
+	}
+
+	return value, nil
+}
+
+// get returns a configuration value for a given key, looking up nested keys
+// by given delimiter, if not empty. See Get.
+func (cfg *defaultConfig) get(key, delimiter string, def ...any) any {
+	value, failure := cfg.lookup(key, delimiter, def...)
+	if failure != nil && cfg.castFailureHandler != nil {
+		cfg.castFailureHandler(*failure)
+	}
+
+	return value
+}
+
+// lookup returns a configuration value for a given key, looking up nested keys
+// by given delimiter, if not empty, and the cast failure, if any (in which case
+// the default value is returned).
+func (cfg *defaultConfig) lookup(key, delimiter string, def ...any) (any, *CastFailure) {
 	if cfg.ignoreCaseSensitivity {
 		key = strings.ToUpper(key)
 	}
@@ -111,64 +205,208 @@ func (cfg *defaultConfig) Get(key string, def ...any) any {
 		cfg.mu.RLock()
 	}
 	value, foundKey := cfg.configMap[key]
+	if !foundKey && delimiter != "" && strings.Contains(key, delimiter) {
+		value, foundKey = lookupNestedKey(cfg.configMap, key, delimiter, cfg.ignoreCaseSensitivity)
+	}
 	if cfg.reloadInterval > 0 {
 		cfg.mu.RUnlock()
 	}
+	if cfg.deprecation != nil && cfg.enforceDeprecation(key) != nil {
+		value, foundKey = nil, false
+	}
 
 	if len(def) > 0 {
 		defaultValue := def[0]
 		if !foundKey {
-			return defaultValue
+			return defaultValue, nil
 		}
 		if defaultValue != nil {
-			return castValueByDefault(value, defaultValue)
+			castValue, err := castValueByDefault(value, defaultValue)
+			if err != nil {
+				failure := cfg.newCastFailure(key, value, defaultValue, err)
+
+				return defaultValue, &failure
+			}
+
+			return castValue, nil
 		}
 	}
 
-	return value
+	return value, nil
 }
 
 // RegisterObserver adds a new observer that will get notified of keys changes.
-func (cfg *defaultConfig) RegisterObserver(observer ConfigObserver) {
+// If DefaultConfigWithNotifyInitialLoad was applied, the observer gets
+// notified right away with all the keys.
+// The returned handle can be used to unregister the observer.
+func (cfg *defaultConfig) RegisterObserver(observer ConfigObserver) ObserverHandle {
+	cfg.mu.Lock()
+	cfg.lastObserverHandle++
+	handle := cfg.lastObserverHandle
+	observers := make([]registeredObserver, len(cfg.observers), len(cfg.observers)+1)
+	copy(observers, cfg.observers)
+	cfg.observers = append(observers, registeredObserver{handle: handle, observer: observer})
+	var keys []string
+	if cfg.notifyInitialLoad {
+		keys = make([]string, 0, len(cfg.configMap))
+		for key := range cfg.configMap {
+			keys = append(keys, key)
+		}
+	}
+	cfg.mu.Unlock()
+
+	if cfg.notifyInitialLoad {
+		observer(cfg, keys...)
+	}
+
+	return handle
+}
+
+// RegisterChangeObserver adds a new observer that will get notified of keys changes,
+// with their old and new values, and change type (added / updated / deleted).
+// If DefaultConfigWithNotifyInitialLoad was applied, the observer gets
+// notified right away with all the keys, as added.
+// The returned handle can be used to unregister the observer, see UnregisterObserver.
+//
+// Usage example:
+//
+//	cfg.RegisterChangeObserver(func(_ xconf.Config, changes xconf.Changes) {
+//		for _, change := range changes {
+//			log.Printf("%s %s: %v => %v", change.Op, change.Key, change.OldValue, change.NewValue)
+//		}
+//	})
+func (cfg *defaultConfig) RegisterChangeObserver(observer ChangeObserver) ObserverHandle {
 	cfg.mu.Lock()
-	if cfg.observers == nil {
-		cfg.observers = []ConfigObserver{observer}
-	} else {
-		cfg.observers = append(cfg.observers, observer)
+	cfg.lastObserverHandle++
+	handle := cfg.lastObserverHandle
+	observers := make([]registeredObserver, len(cfg.observers), len(cfg.observers)+1)
+	copy(observers, cfg.observers)
+	cfg.observers = append(observers, registeredObserver{handle: handle, changeObserver: observer})
+	var changes Changes
+	if cfg.notifyInitialLoad {
+		changes = cfg.maskChanges(Diff(nil, cfg.configMap))
 	}
 	cfg.mu.Unlock()
+
+	if cfg.notifyInitialLoad {
+		observer(cfg, changes)
+	}
+
+	return handle
+}
+
+// UnregisterObserver removes the observer registered with given handle.
+// It is safe to be called concurrently with a reload, even from within an observer,
+// but note that a notification already in progress may still reach the observer.
+func (cfg *defaultConfig) UnregisterObserver(handle ObserverHandle) {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+
+	for idx, regObserver := range cfg.observers {
+		if regObserver.handle != handle {
+			continue
+		}
+		observers := make([]registeredObserver, 0, len(cfg.observers)-1)
+		observers = append(observers, cfg.observers[:idx]...)
+		cfg.observers = append(observers, cfg.observers[idx+1:]...)
+
+		return
+	}
+}
+
+// configMapSnapshot returns a copy of the current configuration map.
+func (cfg *defaultConfig) configMapSnapshot() map[string]any {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+
+	return DeepCopyConfigMap(cfg.configMap)
 }
 
 // setConfigMap loads the config map.
+// The loader is given a context (see [ContextLoader]) which is canceled on Close,
+// and which is bounded by the reload timeout, if set (see [DefaultConfigWithReloadTimeout]).
 func (cfg *defaultConfig) setConfigMap() error {
-	newConfigMap, err := cfg.loader.Load()
+	newLoadedConfigMap, err := cfg.load()
 	if err != nil {
 		return err
 	}
-	if cfg.ignoreCaseSensitivity {
-		toUppercaseConfigMap(newConfigMap)
-	}
 
 	cfg.mu.Lock()
+	newConfigMap, err := cfg.effectiveConfigMap(newLoadedConfigMap, cfg.overrides)
+	if err != nil {
+		cfg.mu.Unlock()
+
+		return err
+	}
 	oldConfigMap := cfg.configMap
+	cfg.loadedConfigMap = newLoadedConfigMap
 	cfg.configMap = newConfigMap
 	cfg.mu.Unlock()
 
-	cfg.notifyObservers(oldConfigMap, newConfigMap)
+	cfg.afterConfigMapChange(oldConfigMap, newConfigMap)
 
 	return nil
 }
 
+// effectiveConfigMap returns the configuration map to be served, computed from given loaded
+// configuration map and overrides. The configuration map with overrides applied is validated
+// against validation rules, if any, with keys as they were loaded, and afterwards, keys are
+// uppercased, if case sensitivity is ignored.
+// Every configuration change (reload, ReloadPrefix, Set / Unset) goes through it,
+// so that the same configuration map is validated, regardless of the change's source.
+func (cfg *defaultConfig) effectiveConfigMap(loadedConfigMap, overrides map[string]any) (map[string]any, error) {
+	configMap := applyOverrides(loadedConfigMap, overrides, cfg.ignoreCaseSensitivity)
+	if len(cfg.validationRules) > 0 {
+		if err := validateConfigMap(configMap, cfg.validationRules); err != nil {
+			return nil, cfg.maskErrorSecrets(err, configMap)
+		}
+	}
+	if cfg.ignoreCaseSensitivity {
+		configMap = toUppercaseConfigMap(configMap)
+	}
+
+	return configMap, nil
+}
+
+// afterConfigMapChange saves a snapshot, if enabled, and notifies observers and watchers
+// about a configuration map replacement.
+func (cfg *defaultConfig) afterConfigMapChange(oldConfigMap, newConfigMap map[string]any) {
+	if cfg.snapshots != nil {
+		if err := cfg.snapshots.save(newConfigMap, time.Now()); err != nil && cfg.reloadErrorHandler != nil {
+			cfg.reloadErrorHandler(err)
+		}
+	}
+
+	if cfg.logger != nil {
+		cfg.logChangedKeys(oldConfigMap, newConfigMap)
+	}
+
+	cfg.notifyObservers(oldConfigMap, newConfigMap)
+	cfg.notifyWatchers(oldConfigMap, newConfigMap)
+}
+
 // notifyObservers computes changed (updated/deleted/new) keys on a config reload,
 // and notifies registered observers about them, if there are any changed keys and observers.
 func (cfg *defaultConfig) notifyObservers(oldConfigMap, newConfigMap map[string]any) {
 	cfg.mu.RLock()
-	defer cfg.mu.RUnlock()
+	observers := cfg.observers
+	cfg.mu.RUnlock()
 
-	if cfg.observers == nil || reflect.DeepEqual(oldConfigMap, newConfigMap) {
+	if len(observers) == 0 || reflect.DeepEqual(oldConfigMap, newConfigMap) {
 		return
 	}
+	if cfg.notifyDebounce != nil {
+		cfg.debounceObserversNotification(oldConfigMap)
 
+		return
+	}
+
+	cfg.notifyChangedKeys(observers, oldConfigMap, newConfigMap)
+}
+
+// notifyChangedKeys computes changed (updated/deleted/new) keys between given
+// configuration maps, and notifies given observers about them.
+func (cfg *defaultConfig) notifyChangedKeys(observers []registeredObserver, oldConfigMap, newConfigMap map[string]any) {
 	// max will be reached only if all old config map keys get deleted,
 	// highly improbable
 	maxChangedKeysCap := len(oldConfigMap) + len(newConfigMap)
@@ -184,8 +422,26 @@ func (cfg *defaultConfig) notifyObservers(oldConfigMap, newConfigMap map[string]
 		}
 	}
 
-	for _, notifyObserver := range cfg.observers {
-		notifyObserver(cfg, changedKeys...)
+	var (
+		changes   Changes
+		startTime = time.Now()
+	)
+	for _, regObserver := range observers {
+		if regObserver.changeObserver != nil {
+			if changes == nil {
+				changes = cfg.maskChanges(Diff(oldConfigMap, newConfigMap))
+			}
+			regObserver.changeObserver(cfg, changes)
+		} else {
+			regObserver.observer(cfg, changedKeys...)
+		}
+	}
+	if cfg.logger != nil {
+		cfg.logger.Info(
+			"[xconf] observers notified",
+			"observers", len(observers),
+			"duration", time.Since(startTime),
+		)
 	}
 }
 
@@ -198,16 +454,39 @@ func (cfg *defaultConfig) reloadAsync() {
 		select {
 		case <-cfg.closed:
 			cfg.ticker.Stop()
+			if cfg.credentialExpiry != nil && cfg.credentialExpiry.timer != nil {
+				cfg.credentialExpiry.timer.Stop()
+			}
 
 			return
 		case <-cfg.ticker.C:
-			if err := cfg.setConfigMap(); err != nil && cfg.reloadErrorHandler != nil {
-				cfg.reloadErrorHandler(err)
+			cfg.reload()
+			if cfg.credentialExpiry != nil {
+				cfg.scheduleCredentialRenewal()
 			}
+		case <-cfg.credentialRenewalC():
+			cfg.reload()
+			cfg.checkCredentialExpiries()
+			cfg.scheduleCredentialRenewal()
 		}
 	}
 }
 
+// reload reloads the config map, passing the error, if any, to the reload error handler.
+func (cfg *defaultConfig) reload() {
+	startTime := time.Now()
+	if cfg.logger != nil {
+		cfg.logger.Info("[xconf] reloading configuration")
+	}
+	err := cfg.setConfigMap()
+	if cfg.logger != nil {
+		cfg.logReloadResult(startTime, err)
+	}
+	if err != nil && cfg.reloadErrorHandler != nil {
+		cfg.reloadErrorHandler(err)
+	}
+}
+
 // close stops the underlying ticker used to reload config, avoiding memory leaks.
 func (cfg *defaultConfig) close() {
 	if cfg != nil {
@@ -218,21 +497,45 @@ func (cfg *defaultConfig) close() {
 
 // Close stops the underlying ticker used to reload config, avoiding memory leaks.
 // It should be called at your application shutdown.
-// It implements [io.Closer] and the returned error can be disregarded (is nil all the time).
+// It implements [io.Closer]. Loaders implementing [io.Closer] too (like [EtcdLoader]
+// with watcher), even if decorated / encapsulated by other loaders, get closed
+// (see [CloseLoaders]), and their error is returned.
+//
+// It is safe to call Close multiple times, concurrently; only first call has effect.
+// After Close, Get keeps returning values from the last loaded configuration.
 func (cfg *DefaultConfig) Close() error {
-	if cfg != nil && cfg.reloadInterval > 0 {
-		cfg.close()
-		runtime.SetFinalizer(cfg, nil)
+	if cfg == nil {
+		return nil
 	}
 
-	return nil
+	var err error
+	cfg.closeOnce.Do(func() {
+		atomic.StoreInt32(&cfg.isClosed, 1)
+		cfg.cancelLoadCtx() // before waiting reload goroutine, as it may be blocked loading.
+		cfg.closeWatchers() // before waiting reload goroutine, as it may be blocked sending events.
+		if cfg.notifyDebounce != nil {
+			cfg.notifyDebounce.stop()
+		}
+		if cfg.reloadInterval > 0 {
+			cfg.close()
+			runtime.SetFinalizer(cfg, nil)
+		}
+		err = CloseLoaders(cfg.loader)
+	})
+
+	return err
+}
+
+// Closed returns true if Close was called.
+func (cfg *defaultConfig) Closed() bool {
+	return atomic.LoadInt32(&cfg.isClosed) == 1
 }
 
 // castValueByDefault casts a key's value to provided default value's type.
 // Only basic types (string, bool, int, uint, float, and their flavours),
 // time.Duration, time.Time, []int, []string are covered.
-// If a cast error occurs, the defaultValue is returned.
-func castValueByDefault(value, defaultValue any) any {
+// If a cast error occurs, it is returned along with the defaultValue.
+func castValueByDefault(value, defaultValue any) (any, error) {
 	var (
 		castValue any
 		castErr   error
@@ -279,19 +582,32 @@ func castValueByDefault(value, defaultValue any) any {
 	}
 
 	if castErr == nil {
-		return castValue
+		return castValue, nil
 	}
 
-	return defaultValue
+	return defaultValue, castErr
 }
 
-// toUppercaseConfigMap transforms all (first level) keys to uppercase.
-func toUppercaseConfigMap(configMap map[string]any) {
+// toUppercaseConfigMap returns a copy of given configuration map, with all (first level) keys uppercased.
+func toUppercaseConfigMap(configMap map[string]any) map[string]any {
+	uppercasedConfigMap := make(map[string]any, len(configMap))
 	for key, value := range configMap {
-		delete(configMap, key)
 		// Note: here if a duplicate key exists, it will get overwritten.
-		configMap[strings.ToUpper(key)] = value
+		uppercasedConfigMap[strings.ToUpper(key)] = value
 	}
+
+	return uppercasedConfigMap
+}
+
+// hasPrefixFold checks whether key begins with prefix, case-insensitively.
+func hasPrefixFold(key, prefix string) bool {
+	return len(key) >= len(prefix) && strings.EqualFold(key[:len(prefix)], prefix)
+}
+
+// configMapSnapshotter is implemented by configs able to provide
+// their whole key-value configuration map.
+type configMapSnapshotter interface {
+	configMapSnapshot() map[string]any
 }
 
 // DefaultConfigOption defines optional function for configuring
@@ -334,6 +650,30 @@ func DefaultConfigWithIgnoreCaseSensitivity() DefaultConfigOption {
 	}
 }
 
+// DefaultConfigWithNotifyInitialLoad makes observers get notified, at registration,
+// with all the keys from the loaded configuration, as if they were all changed.
+// This way, a component can initialize and update itself through a single code path,
+// its observer.
+//
+// By default, observers get notified only about keys changed on reload.
+//
+// Usage example:
+//
+//	cfg, err := xconf.NewDefaultConfig(
+//		loader,
+//		xconf.DefaultConfigWithReloadInterval(time.Minute),
+//		xconf.DefaultConfigWithNotifyInitialLoad(),
+//	)
+//	if err != nil {
+//		panic(err)
+//	}
+//	cfg.RegisterObserver(redisClient.OnConfigChange) // gets called right away with all keys.
+func DefaultConfigWithNotifyInitialLoad() DefaultConfigOption {
+	return func(config *DefaultConfig) {
+		config.notifyInitialLoad = true
+	}
+}
+
 // DefaultConfigWithReloadErrorHandler sets the handler for errors that may occur
 // during reloading configuration, if DefaultConfigWithReloadInterval was applied.
 // If reload fails, "old"/previous configuration is active.
@@ -349,3 +689,19 @@ func DefaultConfigWithReloadErrorHandler(errHandler func(error)) DefaultConfigOp
 
 // ConfigObserver gets called to notify about changed keys on Config reload.
 type ConfigObserver func(cfg Config, changedKeys ...string)
+
+// ChangeObserver gets called to notify about keys changes on Config reload,
+// with their old and new values, and change type, sorted by key.
+// Note: changes are shared between observers, they should not be modified.
+type ChangeObserver func(cfg Config, changes Changes)
+
+// ObserverHandle identifies a registered observer.
+// It is returned by DefaultConfig's RegisterObserver, and can be used to unregister it.
+type ObserverHandle uint64
+
+// registeredObserver is an observer along with its handle.
+type registeredObserver struct {
+	handle         ObserverHandle
+	observer       ConfigObserver
+	changeObserver ChangeObserver
+}
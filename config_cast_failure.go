@@ -0,0 +1,108 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrCastFailed is the error a [CastFailure] returned by [DefaultConfig.GetE] wraps.
+var ErrCastFailed = errors.New("configuration value cannot be casted")
+
+// CastFailure describes a failure of casting a key's value to the default value's type,
+// in DefaultConfig's Get (in which case, the default value is returned).
+// See [DefaultConfigWithCastFailureHandler], [DefaultConfig.GetE].
+// It implements error, wrapping [ErrCastFailed] and the cast error.
+type CastFailure struct {
+	// Key is the key whose value could not be casted.
+	Key string
+	// Value is the raw value.
+	Value any
+	// TargetType is the default value's type.
+	TargetType reflect.Type
+	// Source is the source the value was loaded from, if known (see [KeySourcer]),
+	// or empty string otherwise.
+	Source string
+	// Err is the cast error.
+	Err error
+}
+
+// String returns string representation of the CastFailure.
+func (failure CastFailure) String() string {
+	msg := fmt.Sprintf("key %q: cannot cast %#v (%T) to %s", failure.Key, failure.Value, failure.Value, failure.TargetType)
+	if failure.Source != "" {
+		msg += ", loaded from " + failure.Source
+	}
+
+	return msg
+}
+
+// Error returns the error message of the CastFailure.
+func (failure CastFailure) Error() string {
+	if failure.Err == nil {
+		return failure.String()
+	}
+
+	return failure.String() + ": " + failure.Err.Error()
+}
+
+// Unwrap returns the wrapped errors: [ErrCastFailed] and the cast error.
+func (failure CastFailure) Unwrap() []error {
+	return []error{ErrCastFailed, failure.Err}
+}
+
+// KeySourcer can be implemented by a loader which knows the source (like a file path,
+// an env variable name, a remote key) each key was loaded from.
+// If DefaultConfig's loader implements it, [CastFailure] has the source filled.
+type KeySourcer interface {
+	// KeySource returns the source given key was loaded from, if known.
+	KeySource(key string) (string, bool)
+}
+
+// DefaultConfigWithCastFailureHandler sets a handler for Get's cast failures (in which
+// case, the default value is returned), so that silent misconfigurations (like
+// "30seconds" for a [time.Duration]) become observable. You can log them, for example.
+// The handler is called synchronously, from Get, so it should be fast and must not call Get itself
+// for the same key, with the same default value type.
+//
+// By default, cast failures are ignored.
+//
+// Usage example:
+//
+//	cfg, err := xconf.NewDefaultConfig(
+//		loader,
+//		xconf.DefaultConfigWithCastFailureHandler(func(failure xconf.CastFailure) {
+//			logger.Warn(xlog.MessageKey, "[xconf] invalid configuration value", "failure", failure.String())
+//		}),
+//	)
+func DefaultConfigWithCastFailureHandler(handler func(CastFailure)) DefaultConfigOption {
+	return func(config *DefaultConfig) {
+		config.castFailureHandler = handler
+	}
+}
+
+// newCastFailure builds a CastFailure for given key.
+func (cfg *defaultConfig) newCastFailure(key string, value, defaultValue any, err error) CastFailure {
+	failure := CastFailure{
+		Key:        key,
+		Value:      value,
+		TargetType: reflect.TypeOf(defaultValue),
+		Err:        err,
+	}
+	if len(cfg.secretKeys) > 0 && isRedactedKey(key, cfg.secretKeys) {
+		failure.Value = redactedValue
+		if strValue, isStr := value.(string); isStr && strValue != "" && err != nil {
+			failure.Err = maskError(err, []string{strValue})
+		}
+	}
+	if sourcer, ok := cfg.loader.(KeySourcer); ok {
+		failure.Source, _ = sourcer.KeySource(key)
+	}
+
+	return failure
+}
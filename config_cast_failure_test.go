@@ -0,0 +1,184 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+// keySourcerLoader is a loader which knows keys' sources.
+type keySourcerLoader struct {
+	xconf.Loader
+	sources map[string]string
+}
+
+func (loader keySourcerLoader) KeySource(key string) (string, bool) {
+	source, found := loader.sources[key]
+
+	return source, found
+}
+
+func TestDefaultConfigWithCastFailureHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - cast failures are reported", testDefaultConfigWithCastFailureHandlerReports)
+	t.Run("success - source is filled, if known", testDefaultConfigWithCastFailureHandlerWithSource)
+}
+
+func testDefaultConfigWithCastFailureHandlerReports(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var failures []xconf.CastFailure
+	subject, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{
+			"timeout": "30seconds",
+			"port":    "8080",
+			"debug":   "maybe",
+		}),
+		xconf.DefaultConfigWithCastFailureHandler(func(failure xconf.CastFailure) {
+			failures = append(failures, failure)
+		}),
+	)
+	requireNil(t, err)
+
+	// act
+	timeout := subject.Get("timeout", 10*time.Second)
+	port := subject.Get("port", 80)
+	debug := subject.Get("debug", false)
+	missing := subject.Get("missing", "default")
+
+	// assert
+	assertEqual(t, 10*time.Second, timeout)
+	assertEqual(t, 8080, port)
+	assertEqual(t, false, debug)
+	assertEqual(t, "default", missing)
+	if assertEqual(t, 2, len(failures)) {
+		assertEqual(t, "timeout", failures[0].Key)
+		assertEqual(t, "30seconds", failures[0].Value)
+		assertEqual(t, reflect.TypeOf(time.Duration(0)), failures[0].TargetType)
+		assertEqual(t, "", failures[0].Source)
+		assertNotNil(t, failures[0].Err)
+		assertEqual(t, `key "timeout": cannot cast "30seconds" (string) to time.Duration`, failures[0].String())
+		assertEqual(t, "debug", failures[1].Key)
+		assertEqual(t, reflect.TypeOf(false), failures[1].TargetType)
+	}
+}
+
+func testDefaultConfigWithCastFailureHandlerWithSource(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var failure xconf.CastFailure
+	subject, err := xconf.NewDefaultConfig(
+		keySourcerLoader{
+			Loader:  xconf.PlainLoader(map[string]any{"TIMEOUT": "30seconds"}),
+			sources: map[string]string{"TIMEOUT": "env"},
+		},
+		xconf.DefaultConfigWithIgnoreCaseSensitivity(),
+		xconf.DefaultConfigWithCastFailureHandler(func(f xconf.CastFailure) {
+			failure = f
+		}),
+	)
+	requireNil(t, err)
+
+	// act
+	timeout := subject.Get("timeout", time.Second)
+
+	// assert
+	assertEqual(t, time.Second, timeout)
+	assertEqual(t, "TIMEOUT", failure.Key)
+	assertEqual(t, "env", failure.Source)
+	assertEqual(t, `key "TIMEOUT": cannot cast "30seconds" (string) to time.Duration, loaded from env`, failure.String())
+}
+
+func TestDefaultConfig_GetE(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - values are casted", testDefaultConfigGetESuccess)
+	t.Run("error - cast failure is returned", testDefaultConfigGetEReturnsCastFailure)
+	t.Run("error - sub config's cast failure is returned", testDefaultConfigGetEReturnsSubConfigCastFailure)
+}
+
+func testDefaultConfigGetESuccess(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{
+			"timeout": "30s",
+			"port":    "8080",
+		}),
+	)
+	requireNil(t, err)
+
+	// act
+	timeout, errTimeout := subject.GetE("timeout", 10*time.Second)
+	port, errPort := subject.GetE("port")
+	missing, errMissing := subject.GetE("missing", "default")
+
+	// assert
+	assertNil(t, errTimeout)
+	assertEqual(t, 30*time.Second, timeout)
+	assertNil(t, errPort)
+	assertEqual(t, "8080", port)
+	assertNil(t, errMissing)
+	assertEqual(t, "default", missing)
+}
+
+func testDefaultConfigGetEReturnsCastFailure(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	handlerCallsCnt := 0
+	subject, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{"timeout": "30seconds"}),
+		xconf.DefaultConfigWithCastFailureHandler(func(xconf.CastFailure) {
+			handlerCallsCnt++
+		}),
+	)
+	requireNil(t, err)
+
+	// act
+	timeout, err := subject.GetE("timeout", 10*time.Second)
+
+	// assert
+	assertEqual(t, 10*time.Second, timeout)
+	assertTrue(t, errors.Is(err, xconf.ErrCastFailed))
+	var failure xconf.CastFailure
+	if assertTrue(t, errors.As(err, &failure)) {
+		assertEqual(t, "timeout", failure.Key)
+		assertEqual(t, "30seconds", failure.Value)
+		assertTrue(t, errors.Is(err, failure.Err))
+	}
+	assertEqual(t, 0, handlerCallsCnt)
+}
+
+func testDefaultConfigGetEReturnsSubConfigCastFailure(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{"http.port": "eighty", "http.host": "localhost"}),
+	)
+	requireNil(t, err)
+	subject := config.Sub("http")
+
+	// act
+	port, errPort := subject.GetE("port", 80)
+	host, errHost := subject.GetE("host", "")
+
+	// assert
+	assertEqual(t, 80, port)
+	assertTrue(t, errors.Is(errPort, xconf.ErrCastFailed))
+	assertEqual(t, "localhost", host)
+	assertNil(t, errHost)
+}
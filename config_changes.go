@@ -0,0 +1,130 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ChangeOp is the operation a configuration key suffered.
+type ChangeOp string
+
+const (
+	// KeyAdded is the operation for a key that did not exist before.
+	KeyAdded ChangeOp = "added"
+	// KeyUpdated is the operation for a key whose value changed.
+	KeyUpdated ChangeOp = "updated"
+	// KeyDeleted is the operation for a key that does not exist anymore.
+	KeyDeleted ChangeOp = "deleted"
+)
+
+// KeyChange describes a configuration key's change.
+type KeyChange struct {
+	// Key is the changed configuration key.
+	Key string
+	// Op is the operation the key suffered.
+	Op ChangeOp
+	// OldValue is the key's value before the change (nil for an added key).
+	OldValue any
+	// NewValue is the key's value after the change (nil for a deleted key).
+	NewValue any
+}
+
+// String returns string representation of the KeyChange.
+func (change KeyChange) String() string {
+	switch change.Op {
+	case KeyAdded:
+		return fmt.Sprintf("+ %s: %v", change.Key, change.NewValue)
+	case KeyDeleted:
+		return fmt.Sprintf("- %s: %v", change.Key, change.OldValue)
+	default:
+		return fmt.Sprintf("~ %s: %v => %v", change.Key, change.OldValue, change.NewValue)
+	}
+}
+
+// Changes is a list of configuration keys' changes, sorted by key.
+type Changes []KeyChange
+
+// Keys returns the changed keys.
+func (changes Changes) Keys() []string {
+	keys := make([]string, len(changes))
+	for idx, change := range changes {
+		keys[idx] = change.Key
+	}
+
+	return keys
+}
+
+// String returns string representation of the Changes, one change per line.
+// Example:
+//
+//	~ db.host: 10.0.0.1 => 10.0.0.2
+//	+ db.pool_size: 20
+//	- db.timeout: 5s
+func (changes Changes) String() string {
+	var sb strings.Builder
+	for idx, change := range changes {
+		if idx > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(change.String())
+	}
+
+	return sb.String()
+}
+
+// Diff returns the changes (added / updated / deleted keys, with their values) between two
+// configuration maps (like two [DefaultConfig.AllSettings] snapshots), sorted by key.
+// Values are compared deeply, keys case-sensitive.
+// It is the same computation [DefaultConfig]'s change observers / watchers are notified with,
+// so tooling (CLI, tests, admin endpoints) can reuse it.
+func Diff(oldConfigMap, newConfigMap map[string]any) Changes {
+	changes := make(Changes, 0)
+	for oldKey, oldValue := range oldConfigMap { // compute updated/deleted keys
+		newValue, found := newConfigMap[oldKey]
+		if !found {
+			changes = append(changes, KeyChange{Key: oldKey, Op: KeyDeleted, OldValue: oldValue})
+		} else if !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, KeyChange{Key: oldKey, Op: KeyUpdated, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	for newKey, newValue := range newConfigMap { // compute new keys
+		if _, found := oldConfigMap[newKey]; !found {
+			changes = append(changes, KeyChange{Key: newKey, Op: KeyAdded, NewValue: newValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes
+}
+
+// Preview loads configuration from given (candidate) loader and returns
+// the changes it would produce against the current configuration, without applying them.
+// It can be used, for example, by deployment tooling to show what a pending change would do.
+// Runtime overrides (see Set) are applied on the candidate configuration, as they would be on reload,
+// and an error is returned if the candidate configuration fails validation (see [DefaultConfigWithValidation]).
+// Secret keys' values are masked (see [DefaultConfigWithSecretKeys]).
+func (cfg *defaultConfig) Preview(loader Loader) (Changes, error) {
+	candidateConfigMap, err := loader.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+
+	candidateConfigMap, err = cfg.effectiveConfigMap(candidateConfigMap, cfg.overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.maskChanges(Diff(cfg.configMap, candidateConfigMap)), nil
+}
@@ -0,0 +1,338 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestDefaultConfig_Preview(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - changes are returned, not applied", testDefaultConfigPreviewReturnsChanges)
+	t.Run("success - no changes", testDefaultConfigPreviewReturnsNoChanges)
+	t.Run("success - case insensitive keys", testDefaultConfigPreviewCaseInsensitive)
+	t.Run("success - secrets are masked", testDefaultConfigPreviewMasksSecrets)
+	t.Run("success - overrides are applied", testDefaultConfigPreviewAppliesOverrides)
+	t.Run("error - candidate loader", testDefaultConfigPreviewReturnsErrFromLoader)
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	oldConfigMap := map[string]any{
+		"db":         map[string]any{"host": "10.0.0.1", "ports": []any{5432}},
+		"db.timeout": "5s",
+		"app":        "demo",
+	}
+	newConfigMap := map[string]any{
+		"db":           map[string]any{"host": "10.0.0.1", "ports": []any{5432, 5433}},
+		"db.pool_size": 20,
+		"app":          "demo",
+	}
+
+	// act
+	changes := xconf.Diff(oldConfigMap, newConfigMap)
+	noChanges := xconf.Diff(oldConfigMap, oldConfigMap)
+	allAdded := xconf.Diff(nil, map[string]any{"app": "demo"})
+
+	// assert
+	assertEqual(
+		t,
+		xconf.Changes{
+			{
+				Key:      "db",
+				Op:       xconf.KeyUpdated,
+				OldValue: map[string]any{"host": "10.0.0.1", "ports": []any{5432}},
+				NewValue: map[string]any{"host": "10.0.0.1", "ports": []any{5432, 5433}},
+			},
+			{Key: "db.pool_size", Op: xconf.KeyAdded, NewValue: 20},
+			{Key: "db.timeout", Op: xconf.KeyDeleted, OldValue: "5s"},
+		},
+		changes,
+	)
+	assertEqual(t, 0, len(noChanges))
+	assertEqual(t, xconf.Changes{{Key: "app", Op: xconf.KeyAdded, NewValue: "demo"}}, allAdded)
+}
+
+func TestDefaultConfig_RegisterChangeObserver(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - changes with old and new values are notified", testDefaultConfigRegisterChangeObserverNotifiesChanges)
+	t.Run("success - initial load is notified", testDefaultConfigRegisterChangeObserverNotifiesInitialLoad)
+}
+
+func testDefaultConfigRegisterChangeObserverNotifiesChanges(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{
+			"db.host":    "10.0.0.1",
+			"db.timeout": "5s",
+		}),
+		xconf.DefaultConfigWithReloadInterval(time.Hour),
+	)
+	requireNil(t, err)
+	defer subject.Close()
+	var (
+		notifiedChanges []xconf.Changes
+		notifiedKeys    [][]string
+	)
+	handle := subject.RegisterChangeObserver(func(cfg xconf.Config, changes xconf.Changes) {
+		notifiedChanges = append(notifiedChanges, changes)
+		assertEqual(t, "10.0.0.2", cfg.Get("db.host"))
+	})
+	subject.RegisterObserver(func(_ xconf.Config, changedKeys ...string) {
+		notifiedKeys = append(notifiedKeys, changedKeys)
+	})
+
+	// act
+	requireNil(t, subject.Set("db.host", "10.0.0.2"))
+	requireNil(t, subject.Set("db.pool_size", 20))
+	requireNil(t, subject.Unset("db.pool_size"))
+	subject.UnregisterObserver(handle)
+	requireNil(t, subject.Set("db.timeout", "10s"))
+
+	// assert
+	assertEqual(
+		t,
+		[]xconf.Changes{
+			{{Key: "db.host", Op: xconf.KeyUpdated, OldValue: "10.0.0.1", NewValue: "10.0.0.2"}},
+			{{Key: "db.pool_size", Op: xconf.KeyAdded, NewValue: 20}},
+			{{Key: "db.pool_size", Op: xconf.KeyDeleted, OldValue: 20}},
+		},
+		notifiedChanges,
+	)
+	assertEqual(t, 4, len(notifiedKeys)) // plain observer is still notified.
+}
+
+func testDefaultConfigRegisterChangeObserverNotifiesInitialLoad(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{"foo": "bar", "abc": "xyz"}),
+		xconf.DefaultConfigWithNotifyInitialLoad(),
+	)
+	requireNil(t, err)
+	var notifiedChanges xconf.Changes
+
+	// act
+	subject.RegisterChangeObserver(func(_ xconf.Config, changes xconf.Changes) {
+		notifiedChanges = changes
+	})
+
+	// assert
+	assertEqual(
+		t,
+		xconf.Changes{
+			{Key: "abc", Op: xconf.KeyAdded, NewValue: "xyz"},
+			{Key: "foo", Op: xconf.KeyAdded, NewValue: "bar"},
+		},
+		notifiedChanges,
+	)
+}
+
+func testDefaultConfigPreviewReturnsChanges(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(xconf.PlainLoader(map[string]any{
+		"db.host":    "10.0.0.1",
+		"db.timeout": "5s",
+		"db.name":    "app",
+	}))
+	requireNil(t, err)
+	candidate := xconf.PlainLoader(map[string]any{
+		"db.host":      "10.0.0.2",
+		"db.pool_size": 20,
+		"db.name":      "app",
+	})
+
+	// act
+	changes, err := subject.Preview(candidate)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		xconf.Changes{
+			{Key: "db.host", Op: xconf.KeyUpdated, OldValue: "10.0.0.1", NewValue: "10.0.0.2"},
+			{Key: "db.pool_size", Op: xconf.KeyAdded, NewValue: 20},
+			{Key: "db.timeout", Op: xconf.KeyDeleted, OldValue: "5s"},
+		},
+		changes,
+	)
+	assertEqual(t, []string{"db.host", "db.pool_size", "db.timeout"}, changes.Keys())
+	assertEqual(
+		t,
+		"~ db.host: 10.0.0.1 => 10.0.0.2\n+ db.pool_size: 20\n- db.timeout: 5s",
+		changes.String(),
+	)
+	assertEqual(t, "10.0.0.1", subject.Get("db.host")) // not applied
+}
+
+func testDefaultConfigPreviewReturnsNoChanges(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	configMap := map[string]any{"foo": "bar"}
+	subject, err := xconf.NewDefaultConfig(xconf.PlainLoader(configMap))
+	requireNil(t, err)
+
+	// act
+	changes, err := subject.Preview(xconf.PlainLoader(configMap))
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 0, len(changes))
+	assertEqual(t, "", changes.String())
+}
+
+func testDefaultConfigPreviewCaseInsensitive(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{"foo": "bar"}),
+		xconf.DefaultConfigWithIgnoreCaseSensitivity(),
+	)
+	requireNil(t, err)
+
+	// act
+	changes, err := subject.Preview(xconf.PlainLoader(map[string]any{"Foo": "baz"}))
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		xconf.Changes{{Key: "FOO", Op: xconf.KeyUpdated, OldValue: "bar", NewValue: "baz"}},
+		changes,
+	)
+}
+
+func testDefaultConfigPreviewMasksSecrets(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{
+			"db.host":     "10.0.0.1",
+			"db.password": "s3cr3t",
+		}),
+		xconf.DefaultConfigWithSecretKeys("*password"),
+	)
+	requireNil(t, err)
+	candidate := xconf.PlainLoader(map[string]any{
+		"db.host":     "10.0.0.1",
+		"db.password": "n3w-s3cr3t",
+	})
+
+	// act
+	changes, err := subject.Preview(candidate)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, "~ db.password: ***** => *****", changes.String())
+	assertEqual(t, "s3cr3t", subject.Get("db.password"))
+}
+
+func testDefaultConfigPreviewAppliesOverrides(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{
+			"log.level": "info",
+			"db.host":   "10.0.0.1",
+		}),
+		xconf.DefaultConfigWithReloadInterval(time.Hour),
+	)
+	requireNil(t, err)
+	defer subject.Close()
+	requireNil(t, subject.Set("log.level", "debug"))
+	candidate := xconf.PlainLoader(map[string]any{
+		"log.level": "info",
+		"db.host":   "10.0.0.2",
+	})
+
+	// act
+	changes, err := subject.Preview(candidate)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		xconf.Changes{{Key: "db.host", Op: xconf.KeyUpdated, OldValue: "10.0.0.1", NewValue: "10.0.0.2"}},
+		changes,
+	)
+}
+
+func testDefaultConfigPreviewReturnsErrFromLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(xconf.PlainLoader(map[string]any{"foo": "bar"}))
+	requireNil(t, err)
+	expectedErr := errors.New("intentionally triggered Load error")
+	candidate := xconf.LoaderFunc(func() (map[string]any, error) {
+		return nil, expectedErr
+	})
+
+	// act
+	changes, err := subject.Preview(candidate)
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertNil(t, changes)
+}
+
+func ExampleDefaultConfig_Preview() {
+	config, err := xconf.NewDefaultConfig(xconf.PlainLoader(map[string]any{
+		"db.host":    "10.0.0.1",
+		"db.timeout": "5s",
+	}))
+	if err != nil {
+		panic(err)
+	}
+	candidate := xconf.PlainLoader(map[string]any{
+		"db.host":      "10.0.0.2",
+		"db.pool_size": 20,
+	})
+
+	changes, err := config.Preview(candidate)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(changes)
+
+	// Output:
+	// ~ db.host: 10.0.0.1 => 10.0.0.2
+	// + db.pool_size: 20
+	// - db.timeout: 5s
+}
+
+func ExampleDiff() {
+	changes := xconf.Diff(
+		map[string]any{"db.host": "10.0.0.1", "db.timeout": "5s"},
+		map[string]any{"db.host": "10.0.0.2", "db.pool_size": 20},
+	)
+	for _, change := range changes {
+		fmt.Println(change.Op, change.Key)
+	}
+
+	// Output:
+	// updated db.host
+	// added db.pool_size
+	// deleted db.timeout
+}
@@ -0,0 +1,216 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/actforgood/xerr"
+)
+
+// ErrKeysNotExercised is returned by [KeyCoverage.CheckAllExercised] if
+// some declared keys were not read.
+var ErrKeysNotExercised = errors.New("configuration keys not exercised")
+
+// KeyCoverage is a [Config] which records the keys read through it,
+// meant to be used in tests, to assert that all declared keys are exercised
+// (a coverage-style report of configuration keys).
+type KeyCoverage struct {
+	config Config
+	reads  map[string]int
+	mu     sync.Mutex
+}
+
+// NewKeyCoverage instantiates a new [KeyCoverage] recording the keys read from given config.
+//
+// Usage example:
+//
+//	func TestMain(m *testing.M) {
+//		config, _ := xconf.NewDefaultConfig(xconf.DefaultsLoader(defaults))
+//		coverage = xconf.NewKeyCoverage(config)
+//		code := m.Run() // tests use coverage as their Config.
+//		if err := coverage.CheckAllExercised(xconf.SchemaKeys(AppConfig{})...); err != nil {
+//			fmt.Println(err)
+//			code = 1
+//		}
+//		os.Exit(code)
+//	}
+func NewKeyCoverage(config Config) *KeyCoverage {
+	return &KeyCoverage{
+		config: config,
+		reads:  make(map[string]int),
+	}
+}
+
+// Get returns decorated config's value for given key, recording the read.
+// It implements [Config].
+func (coverage *KeyCoverage) Get(key string, def ...any) any {
+	coverage.mu.Lock()
+	coverage.reads[key]++
+	coverage.mu.Unlock()
+
+	return coverage.config.Get(key, def...)
+}
+
+// Reads returns how many times each key was read.
+func (coverage *KeyCoverage) Reads() map[string]int {
+	coverage.mu.Lock()
+	defer coverage.mu.Unlock()
+
+	reads := make(map[string]int, len(coverage.reads))
+	for key, cnt := range coverage.reads {
+		reads[key] = cnt
+	}
+
+	return reads
+}
+
+// Exercised returns the keys read, sorted.
+func (coverage *KeyCoverage) Exercised() []string {
+	reads := coverage.Reads()
+	keys := make([]string, 0, len(reads))
+	for key := range reads {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Missing returns the given declared keys which were not read, sorted.
+// A key is considered read also if a key holding it was read
+// (like "db" for "db.host", its value being the nested map).
+func (coverage *KeyCoverage) Missing(declaredKeys ...string) []string {
+	reads := coverage.Reads()
+	var missing []string
+	for _, key := range declaredKeys {
+		if !isKeyExercised(key, reads) {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+
+	return missing
+}
+
+// CheckAllExercised returns an [ErrKeysNotExercised] based error
+// if any of given declared keys was not read.
+func (coverage *KeyCoverage) CheckAllExercised(declaredKeys ...string) error {
+	if missing := coverage.Missing(declaredKeys...); len(missing) > 0 {
+		return xerr.Wrapf(
+			ErrKeysNotExercised,
+			"%d/%d: %s",
+			len(missing), len(declaredKeys), strings.Join(missing, ", "),
+		)
+	}
+
+	return nil
+}
+
+// isKeyExercised checks whether given key, or a key holding it, was read.
+func isKeyExercised(key string, reads map[string]int) bool {
+	if _, found := reads[key]; found {
+		return true
+	}
+	for idx := strings.LastIndexByte(key, '.'); idx > 0; idx = strings.LastIndexByte(key, '.') {
+		key = key[:idx]
+		if _, found := reads[key]; found {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SchemaKeys returns the keys declared by a schema / defaults, sorted, which can be:
+//   - a struct (or a pointer to a struct), whose fields' keys are taken from their yaml tags,
+//     or are the lower-cased field names; nested structs' keys are joined with ".".
+//     Fields tagged with "-" and unexported fields are skipped.
+//   - a configuration map, whose keys are returned (nested maps' keys are joined with ".").
+//
+// Example:
+//
+//	type AppConfig struct {
+//		DB struct {
+//			Host string `yaml:"host"`
+//			Port int    `yaml:"port"`
+//		} `yaml:"db"`
+//		LogLevel string `yaml:"log_level"`
+//	}
+//	keys := xconf.SchemaKeys(AppConfig{}) // [db.host db.port log_level]
+func SchemaKeys(schema any) []string {
+	var keys []string
+	if configMap, ok := schema.(map[string]any); ok {
+		keys = mapSchemaKeys(configMap, "", keys)
+	} else {
+		keys = structSchemaKeys(reflect.ValueOf(schema), "", keys)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// mapSchemaKeys appends the keys of given configuration map, prefixed with given prefix.
+func mapSchemaKeys(configMap map[string]any, prefix string, keys []string) []string {
+	for key, value := range configMap {
+		if nestedMap, isMap := value.(map[string]any); isMap && len(nestedMap) > 0 {
+			keys = mapSchemaKeys(nestedMap, prefix+key+".", keys)
+		} else {
+			keys = append(keys, prefix+key)
+		}
+	}
+
+	return keys
+}
+
+// structSchemaKeys appends the keys of given struct's fields, prefixed with given prefix.
+func structSchemaKeys(value reflect.Value, prefix string, keys []string) []string {
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			value = reflect.New(value.Type().Elem())
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return keys
+	}
+
+	valueType := value.Type()
+	for idx := 0; idx < valueType.NumField(); idx++ {
+		field := valueType.Field(idx)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct && fieldType.NumField() > 0 && !isLeafStruct(fieldType) {
+			keys = structSchemaKeys(value.Field(idx), prefix+name+".", keys)
+		} else {
+			keys = append(keys, prefix+name)
+		}
+	}
+
+	return keys
+}
+
+// isLeafStruct checks whether given struct type is a value on its own (like [time.Time]),
+// not a group of keys.
+func isLeafStruct(structType reflect.Type) bool {
+	return structType.PkgPath() == "time"
+}
@@ -0,0 +1,116 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+type testCoverageSchema struct {
+	DB struct {
+		Host string `yaml:"host"`
+		Port int    `yaml:"port,omitempty"`
+	} `yaml:"db"`
+	Cache *struct {
+		TTL time.Duration `yaml:"ttl"`
+	} `yaml:"cache"`
+	StartedAt time.Time `yaml:"started_at"`
+	LogLevel  string
+	Ignored   string `yaml:"-"`
+}
+
+func TestKeyCoverage(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - all keys exercised", testKeyCoverageAllKeysExercised)
+	t.Run("error - some keys not exercised", testKeyCoverageReturnsErrForMissingKeys)
+}
+
+func testKeyCoverageAllKeysExercised(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.NewKeyCoverage(xconf.NewMockConfig("db", map[string]any{"host": "127.0.0.1", "port": 3306}))
+
+	// act
+	db := subject.Get("db")
+	_ = subject.Get("db")
+	_ = subject.Get("log_level", "info")
+	err := subject.CheckAllExercised("db.host", "db.port", "log_level")
+
+	// assert
+	assertNil(t, err)
+	assertNotNil(t, db)
+	assertEqual(t, []string{"db", "log_level"}, subject.Exercised())
+	assertEqual(t, map[string]int{"db": 2, "log_level": 1}, subject.Reads())
+}
+
+func testKeyCoverageReturnsErrForMissingKeys(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.NewKeyCoverage(xconf.NewMockConfig("db.host", "127.0.0.1", "db.port", 3306))
+	declaredKeys := xconf.SchemaKeys(testCoverageSchema{})
+
+	// act
+	host := subject.Get("db.host")
+	err := subject.CheckAllExercised(declaredKeys...)
+
+	// assert
+	assertEqual(t, "127.0.0.1", host)
+	assertTrue(t, errors.Is(err, xconf.ErrKeysNotExercised))
+	assertEqual(
+		t,
+		[]string{"cache.ttl", "db.port", "loglevel", "started_at"},
+		subject.Missing(declaredKeys...),
+	)
+}
+
+func TestSchemaKeys(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - struct", testSchemaKeysFromStruct)
+	t.Run("success - pointer to struct", testSchemaKeysFromPointerToStruct)
+	t.Run("success - configuration map", testSchemaKeysFromConfigMap)
+}
+
+func testSchemaKeysFromStruct(t *testing.T) {
+	t.Parallel()
+
+	// act
+	keys := xconf.SchemaKeys(testCoverageSchema{})
+
+	// assert
+	assertEqual(t, []string{"cache.ttl", "db.host", "db.port", "loglevel", "started_at"}, keys)
+}
+
+func testSchemaKeysFromPointerToStruct(t *testing.T) {
+	t.Parallel()
+
+	// act
+	keys := xconf.SchemaKeys(&testCoverageSchema{})
+
+	// assert
+	assertEqual(t, []string{"cache.ttl", "db.host", "db.port", "loglevel", "started_at"}, keys)
+}
+
+func testSchemaKeysFromConfigMap(t *testing.T) {
+	t.Parallel()
+
+	// act
+	keys := xconf.SchemaKeys(map[string]any{
+		"db":        map[string]any{"host": "127.0.0.1", "port": 3306},
+		"log_level": "info",
+		"tags":      map[string]any{},
+	})
+
+	// assert
+	assertEqual(t, []string{"db.host", "db.port", "log_level", "tags"}, keys)
+}
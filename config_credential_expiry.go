@@ -0,0 +1,180 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// DefaultCredentialExpirySuffix is the suffix of the key holding a credential's expiry timestamp,
+// used if no other is provided to [DefaultConfigWithCredentialExpiry].
+// For example, "db_password_expires_at" holds the expiry timestamp of "db_password" credential.
+const DefaultCredentialExpirySuffix = "_expires_at"
+
+// CredentialExpiryError is an error passed to DefaultConfig's reload error handler
+// if a credential was not renewed before its expiry.
+// See [DefaultConfigWithCredentialExpiry].
+type CredentialExpiryError struct {
+	key       string    // the credential key
+	expiresAt time.Time // the credential expiry timestamp
+}
+
+// NewCredentialExpiryError instantiates a new CredentialExpiryError.
+// The credential key and its expiry timestamp must be provided.
+func NewCredentialExpiryError(key string, expiresAt time.Time) CredentialExpiryError {
+	return CredentialExpiryError{key: key, expiresAt: expiresAt}
+}
+
+// Error returns string representation of the CredentialExpiryError.
+// It implements standard go error interface.
+func (e CredentialExpiryError) Error() string {
+	return fmt.Sprintf(
+		`credential "%s" was not renewed, it expires at %s`,
+		e.key,
+		e.expiresAt.Format(time.RFC3339),
+	)
+}
+
+// Key returns the credential key.
+func (e CredentialExpiryError) Key() string {
+	return e.key
+}
+
+// ExpiresAt returns the credential expiry timestamp.
+func (e CredentialExpiryError) ExpiresAt() time.Time {
+	return e.expiresAt
+}
+
+// credentialExpiry holds the state needed to renew credentials before they expire.
+type credentialExpiry struct {
+	// suffix is the suffix of the keys holding credentials' expiry timestamp.
+	suffix string
+	// renewBefore is the duration before expiry a reload is triggered.
+	renewBefore time.Duration
+	// timer triggers a reload before the earliest expiry.
+	timer *time.Timer
+	// reported holds the expiry timestamps already reported as not renewed, per credential key.
+	reported map[string]time.Time
+}
+
+// DefaultConfigWithCredentialExpiry enables proactive renewal of credentials.
+// Keys representing credentials carry, by convention, an expiry timestamp key alongside,
+// named as the credential key plus the provided suffix (matched case-insensitive).
+// The expiry timestamp can be anything castable to [time.Time], like a RFC3339 string,
+// or an unix timestamp.
+//
+// A reload is triggered renewBefore duration before the earliest expiry. If, after it,
+// the credential still expires in less than renewBefore duration, a [CredentialExpiryError]
+// is passed to the reload error handler (see [DefaultConfigWithReloadErrorHandler]),
+// once per expiry timestamp.
+//
+// It has effect only if DefaultConfigWithReloadInterval was applied, and only first level
+// keys are checked (use a [FlattenLoader] for nested configurations).
+// If suffix is empty, [DefaultCredentialExpirySuffix] is used.
+//
+// Usage example:
+//
+//	// given "db_password" and "db_password_expires_at" keys,
+//	// try to renew the password 5 minutes before it expires:
+//	cfg, err := xconf.NewDefaultConfig(
+//		loader,
+//		xconf.DefaultConfigWithReloadInterval(time.Hour),
+//		xconf.DefaultConfigWithCredentialExpiry("_expires_at", 5*time.Minute),
+//		xconf.DefaultConfigWithReloadErrorHandler(errHandler),
+//	)
+func DefaultConfigWithCredentialExpiry(suffix string, renewBefore time.Duration) DefaultConfigOption {
+	return func(config *DefaultConfig) {
+		if suffix == "" {
+			suffix = DefaultCredentialExpirySuffix
+		}
+		config.credentialExpiry = &credentialExpiry{
+			suffix:      suffix,
+			renewBefore: renewBefore,
+			reported:    make(map[string]time.Time),
+		}
+	}
+}
+
+// credentialExpiries returns the expiry timestamps of the credentials, per credential key.
+func (cfg *defaultConfig) credentialExpiries() map[string]time.Time {
+	suffix := cfg.credentialExpiry.suffix
+	expiries := make(map[string]time.Time)
+
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	for key, value := range cfg.configMap {
+		if len(key) <= len(suffix) || !strings.EqualFold(key[len(key)-len(suffix):], suffix) {
+			continue
+		}
+		expiresAt, err := cast.ToTimeE(value)
+		if err != nil {
+			continue
+		}
+		expiries[key[:len(key)-len(suffix)]] = expiresAt
+	}
+
+	return expiries
+}
+
+// scheduleCredentialRenewal (re)sets the timer to trigger a reload
+// before the earliest, not reported, credential expiry.
+func (cfg *defaultConfig) scheduleCredentialRenewal() {
+	credExpiry := cfg.credentialExpiry
+	if credExpiry.timer != nil {
+		credExpiry.timer.Stop()
+		credExpiry.timer = nil
+	}
+
+	var renewAt time.Time
+	expiries := cfg.credentialExpiries()
+	for key := range credExpiry.reported {
+		if _, found := expiries[key]; !found {
+			delete(credExpiry.reported, key)
+		}
+	}
+	for key, expiresAt := range expiries {
+		if credExpiry.reported[key].Equal(expiresAt) {
+			continue
+		}
+		keyRenewAt := expiresAt.Add(-credExpiry.renewBefore)
+		if renewAt.IsZero() || keyRenewAt.Before(renewAt) {
+			renewAt = keyRenewAt
+		}
+	}
+	if !renewAt.IsZero() {
+		credExpiry.timer = time.NewTimer(max(time.Until(renewAt), 0))
+	}
+}
+
+// credentialRenewalC returns the channel on which the renewal time is delivered.
+// If there is nothing to renew, a nil channel is returned (which blocks forever).
+func (cfg *defaultConfig) credentialRenewalC() <-chan time.Time {
+	if cfg.credentialExpiry == nil || cfg.credentialExpiry.timer == nil {
+		return nil
+	}
+
+	return cfg.credentialExpiry.timer.C
+}
+
+// checkCredentialExpiries reports to the reload error handler the credentials
+// that were not renewed.
+func (cfg *defaultConfig) checkCredentialExpiries() {
+	credExpiry := cfg.credentialExpiry
+	now := time.Now()
+	for key, expiresAt := range cfg.credentialExpiries() {
+		if credExpiry.reported[key].Equal(expiresAt) || now.Before(expiresAt.Add(-credExpiry.renewBefore)) {
+			continue
+		}
+		credExpiry.reported[key] = expiresAt
+		if cfg.reloadErrorHandler != nil {
+			cfg.reloadErrorHandler(NewCredentialExpiryError(key, expiresAt))
+		}
+	}
+}
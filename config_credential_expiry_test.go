@@ -0,0 +1,161 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestDefaultConfigWithCredentialExpiry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("credential is renewed before expiry", testDefaultConfigWithCredentialExpiryRenewed)
+	t.Run("credential is not renewed before expiry", testDefaultConfigWithCredentialExpiryNotRenewed)
+	t.Run("no reload without credentials", testDefaultConfigWithCredentialExpiryNoCredentials)
+}
+
+func testDefaultConfigWithCredentialExpiryRenewed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loaderCallsCnt uint32
+		firstExpiresAt = time.Now().Add(400 * time.Millisecond)
+		loader         = xconf.LoaderFunc(func() (map[string]any, error) {
+			if atomic.AddUint32(&loaderCallsCnt, 1) == 1 {
+				return map[string]any{
+					"db_password":            "old-secret",
+					"db_password_expires_at": firstExpiresAt.Format(time.RFC3339Nano),
+				}, nil
+			}
+
+			return map[string]any{
+				"db_password":            "new-secret",
+				"db_password_expires_at": time.Now().Add(time.Hour).Format(time.RFC3339Nano),
+			}, nil
+		})
+		errHandlerCallsCnt uint32
+		errHandler         = func(error) {
+			atomic.AddUint32(&errHandlerCallsCnt, 1)
+		}
+		subject, err = xconf.NewDefaultConfig(
+			loader,
+			xconf.DefaultConfigWithReloadInterval(time.Hour),
+			xconf.DefaultConfigWithCredentialExpiry("", 200*time.Millisecond),
+			xconf.DefaultConfigWithReloadErrorHandler(errHandler),
+		)
+	)
+	requireNil(t, err)
+	defer subject.Close()
+
+	// act
+	result := subject.Get("db_password")
+
+	// assert
+	assertEqual(t, "old-secret", result)
+
+	// act
+	time.Sleep(500 * time.Millisecond)
+	result = subject.Get("db_password")
+
+	// assert
+	assertEqual(t, "new-secret", result)
+	assertEqual(t, uint32(2), atomic.LoadUint32(&loaderCallsCnt))
+	assertEqual(t, uint32(0), atomic.LoadUint32(&errHandlerCallsCnt))
+}
+
+func testDefaultConfigWithCredentialExpiryNotRenewed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loaderCallsCnt uint32
+		expiresAt      = time.Now().Add(400 * time.Millisecond).Truncate(time.Second)
+		loader         = xconf.LoaderFunc(func() (map[string]any, error) {
+			atomic.AddUint32(&loaderCallsCnt, 1)
+
+			return map[string]any{
+				"DB_PASSWORD":            "secret",
+				"DB_PASSWORD_EXPIRES_AT": expiresAt.Unix(),
+			}, nil
+		})
+		errHandlerCallsCnt uint32
+		errHandler         = func(err error) {
+			atomic.AddUint32(&errHandlerCallsCnt, 1)
+			var credErr xconf.CredentialExpiryError
+			if assertTrue(t, errors.As(err, &credErr)) {
+				assertEqual(t, "DB_PASSWORD", credErr.Key())
+				assertTrue(t, expiresAt.Equal(credErr.ExpiresAt()))
+			}
+		}
+		subject, err = xconf.NewDefaultConfig(
+			loader,
+			xconf.DefaultConfigWithReloadInterval(time.Hour),
+			xconf.DefaultConfigWithCredentialExpiry("_expires_at", 200*time.Millisecond),
+			xconf.DefaultConfigWithReloadErrorHandler(errHandler),
+		)
+	)
+	requireNil(t, err)
+	defer subject.Close()
+
+	// act
+	time.Sleep(600 * time.Millisecond)
+
+	// assert
+	assertEqual(t, uint32(2), atomic.LoadUint32(&loaderCallsCnt))
+	assertEqual(t, uint32(1), atomic.LoadUint32(&errHandlerCallsCnt)) // reported only once
+}
+
+func testDefaultConfigWithCredentialExpiryNoCredentials(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loaderCallsCnt uint32
+		loader         = xconf.LoaderFunc(func() (map[string]any, error) {
+			atomic.AddUint32(&loaderCallsCnt, 1)
+
+			return map[string]any{
+				"foo":            "bar",
+				"foo_expires_at": "not a timestamp",
+			}, nil
+		})
+		subject, err = xconf.NewDefaultConfig(
+			loader,
+			xconf.DefaultConfigWithReloadInterval(time.Hour),
+			xconf.DefaultConfigWithCredentialExpiry("", time.Minute),
+		)
+	)
+	requireNil(t, err)
+	defer subject.Close()
+
+	// act
+	time.Sleep(100 * time.Millisecond)
+
+	// assert
+	assertEqual(t, uint32(1), atomic.LoadUint32(&loaderCallsCnt))
+}
+
+func TestCredentialExpiryError(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	expiresAt := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+	subject := xconf.NewCredentialExpiryError("db_password", expiresAt)
+
+	// act
+	result := subject.Error()
+
+	// assert
+	assertEqual(t, `credential "db_password" was not renewed, it expires at 2024-03-01T10:00:00Z`, result)
+	assertEqual(t, "db_password", subject.Key())
+	assertEqual(t, expiresAt, subject.ExpiresAt())
+}
@@ -0,0 +1,113 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// notifyDebounce coalesces consecutive configuration changes into a single observers' notification.
+type notifyDebounce struct {
+	// interval is the quiet period after the last change, after which observers get notified.
+	interval time.Duration
+	// timer is the pending notification's timer, if any.
+	timer *time.Timer
+	// baseConfigMap is the configuration map before the first coalesced change.
+	baseConfigMap map[string]any
+	// generation identifies the pending notification, so that a replaced / stopped timer's
+	// late run has no effect.
+	generation uint64
+	// mu protects the fields above.
+	mu sync.Mutex
+}
+
+// DefaultConfigWithNotifyDebounce sets a quiet period for observers' notifications:
+// consecutive reloads changing the configuration are coalesced into a single notification
+// with the merged changed keys set, sent after no other change occurred for given interval.
+// Observers are notified about the keys whose value differs from the one before the first
+// coalesced change (a key changed and reverted in between is not reported).
+// This way, observers don't get hammered when using a short reload interval with a churny remote source.
+// Note that observers are notified from a separate goroutine, and that [DefaultConfig.Watch]'s
+// events are not debounced. A pending notification is dropped on Close.
+//
+// By default, observers are notified on each reload which changed the configuration.
+//
+// Usage example:
+//
+//	cfg, err := xconf.NewDefaultConfig(
+//		consulLoader,
+//		xconf.DefaultConfigWithReloadInterval(time.Second),
+//		xconf.DefaultConfigWithNotifyDebounce(5*time.Second),
+//	)
+func DefaultConfigWithNotifyDebounce(interval time.Duration) DefaultConfigOption {
+	return func(config *DefaultConfig) {
+		if interval > 0 {
+			config.notifyDebounce = &notifyDebounce{interval: interval}
+		} else {
+			config.notifyDebounce = nil
+		}
+	}
+}
+
+// debounceObserversNotification schedules (or postpones) the observers' notification
+// about the changes since given configuration map (if this is the first coalesced change).
+func (cfg *defaultConfig) debounceObserversNotification(oldConfigMap map[string]any) {
+	debounce := cfg.notifyDebounce
+	debounce.mu.Lock()
+	defer debounce.mu.Unlock()
+
+	if debounce.timer != nil {
+		debounce.timer.Stop()
+	} else {
+		debounce.baseConfigMap = oldConfigMap
+	}
+	debounce.generation++
+	generation := debounce.generation
+	debounce.timer = time.AfterFunc(debounce.interval, func() {
+		cfg.flushObserversNotification(generation)
+	})
+}
+
+// flushObserversNotification notifies observers about the coalesced changes,
+// if given generation's notification is still the pending one.
+func (cfg *defaultConfig) flushObserversNotification(generation uint64) {
+	debounce := cfg.notifyDebounce
+	debounce.mu.Lock()
+	if generation != debounce.generation || debounce.timer == nil || cfg.Closed() {
+		debounce.mu.Unlock()
+
+		return
+	}
+	baseConfigMap := debounce.baseConfigMap
+	debounce.timer = nil
+	debounce.baseConfigMap = nil
+	debounce.mu.Unlock()
+
+	cfg.mu.RLock()
+	observers := cfg.observers
+	currentConfigMap := cfg.configMap
+	cfg.mu.RUnlock()
+
+	if len(observers) == 0 || reflect.DeepEqual(baseConfigMap, currentConfigMap) {
+		return
+	}
+	cfg.notifyChangedKeys(observers, baseConfigMap, currentConfigMap)
+}
+
+// stop drops the pending notification, if any.
+func (debounce *notifyDebounce) stop() {
+	debounce.mu.Lock()
+	defer debounce.mu.Unlock()
+
+	if debounce.timer != nil {
+		debounce.timer.Stop()
+		debounce.timer = nil
+		debounce.baseConfigMap = nil
+	}
+	debounce.generation++
+}
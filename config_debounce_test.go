@@ -0,0 +1,116 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestDefaultConfigWithNotifyDebounce(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - consecutive changes are coalesced", testDefaultConfigWithNotifyDebounceCoalescesChanges)
+	t.Run("success - reverted changes are not notified", testDefaultConfigWithNotifyDebounceSkipsRevertedChanges)
+	t.Run("success - pending notification is dropped on close", testDefaultConfigWithNotifyDebounceDropsPendingOnClose)
+}
+
+func newDebouncedConfig(t *testing.T, debounce time.Duration) (*xconf.DefaultConfig, <-chan []string) {
+	t.Helper()
+
+	config, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{"foo": "bar", "abc": "xyz"}),
+		xconf.DefaultConfigWithReloadInterval(time.Hour),
+		xconf.DefaultConfigWithNotifyDebounce(debounce),
+	)
+	requireNil(t, err)
+	notifications := make(chan []string, 10)
+	config.RegisterObserver(func(_ xconf.Config, changedKeys ...string) {
+		sort.Strings(changedKeys)
+		notifications <- changedKeys
+	})
+
+	return config, notifications
+}
+
+func testDefaultConfigWithNotifyDebounceCoalescesChanges(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, notifications := newDebouncedConfig(t, 50*time.Millisecond)
+	defer subject.Close()
+
+	// act
+	requireNil(t, subject.Set("foo", "baz"))
+	requireNil(t, subject.Set("abc", "def"))
+	requireNil(t, subject.Set("new", "value"))
+
+	// assert
+	select {
+	case changedKeys := <-notifications:
+		assertEqual(t, []string{"abc", "foo", "new"}, changedKeys)
+	case <-time.After(time.Second):
+		t.Fatal("expected a notification")
+	}
+	select {
+	case changedKeys := <-notifications:
+		t.Errorf("unexpected notification %v", changedKeys)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// act
+	requireNil(t, subject.Unset("new"))
+
+	// assert
+	select {
+	case changedKeys := <-notifications:
+		assertEqual(t, []string{"new"}, changedKeys)
+	case <-time.After(time.Second):
+		t.Fatal("expected a notification")
+	}
+}
+
+func testDefaultConfigWithNotifyDebounceSkipsRevertedChanges(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, notifications := newDebouncedConfig(t, 30*time.Millisecond)
+	defer subject.Close()
+
+	// act
+	requireNil(t, subject.Set("foo", "baz"))
+	requireNil(t, subject.Set("abc", "def"))
+	requireNil(t, subject.Unset("foo"))
+
+	// assert
+	select {
+	case changedKeys := <-notifications:
+		assertEqual(t, []string{"abc"}, changedKeys)
+	case <-time.After(time.Second):
+		t.Fatal("expected a notification")
+	}
+}
+
+func testDefaultConfigWithNotifyDebounceDropsPendingOnClose(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, notifications := newDebouncedConfig(t, 30*time.Millisecond)
+
+	// act
+	requireNil(t, subject.Set("foo", "baz"))
+	requireNil(t, subject.Close())
+
+	// assert
+	select {
+	case changedKeys := <-notifications:
+		t.Errorf("unexpected notification %v", changedKeys)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/actforgood/xerr"
+)
+
+// ErrKeyRemoved is returned by [DeprecationActionReject] for keys read
+// past their removal version.
+var ErrKeyRemoved = errors.New("key is past its removal version")
+
+// DeprecatedKeyUsage describes a read (a Get call) of a key past its removal version.
+type DeprecatedKeyUsage struct {
+	// Key is the read key.
+	Key string
+	// Meta is the key's metadata.
+	Meta KeyMeta
+	// CurrentVersion is the application's current version.
+	CurrentVersion string
+}
+
+// String returns string representation of the DeprecatedKeyUsage.
+func (usage DeprecatedKeyUsage) String() string {
+	msg := "key " + strconv.Quote(usage.Key) + " was scheduled for removal in " +
+		usage.Meta.RemovalVersion + ", current version is " + usage.CurrentVersion
+	if usage.Meta.DeprecationNote != "" {
+		msg += " (" + usage.Meta.DeprecationNote + ")"
+	}
+
+	return msg
+}
+
+// DeprecationAction is called on a read of a key past its removal version.
+// If it returns an error, the key is treated as missing (Get returns the default value).
+// It is called synchronously, from Get, so it should be fast.
+type DeprecationAction func(DeprecatedKeyUsage) error
+
+// DeprecationActionReject is a [DeprecationAction] which makes a key past its removal version
+// be treated as missing, forcing its usage to be retired.
+func DeprecationActionReject(usage DeprecatedKeyUsage) error {
+	return xerr.Wrapf(ErrKeyRemoved, "%s", usage.String())
+}
+
+// DeprecationActions chains multiple actions into a single one.
+// All actions are called, in the given order; the first error is returned.
+//
+// Example, count, log and reject:
+//
+//	xconf.DeprecationActions(
+//		func(usage xconf.DeprecatedKeyUsage) error {
+//			removedKeysReadsCounter.WithLabelValues(usage.Key).Inc()
+//
+//			return nil
+//		},
+//		xconf.DeprecationActionLog(logger),
+//		xconf.DeprecationActionReject,
+//	)
+func DeprecationActions(actions ...DeprecationAction) DeprecationAction {
+	return func(usage DeprecatedKeyUsage) error {
+		var firstErr error
+		for _, action := range actions {
+			if err := action(usage); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+
+		return firstErr
+	}
+}
+
+// DefaultConfigWithDeprecationEnforcement enables enforcing keys' removal timeline:
+// reading (Get) a key whose metadata has a removal version (see [KeyMeta], [DefaultConfigWithKeysMetadata])
+// lower or equal to application's current version triggers given action.
+// The key is read regardless of its presence in the configuration, as the purpose is to
+// retire its usage from code.
+// Versions are compared as dot separated numbers (an eventual "v" prefix is ignored), like "1.10.2".
+//
+// By default, removal versions are not enforced.
+//
+// Usage example:
+//
+//	cfg, err := xconf.NewDefaultConfig(
+//		loader,
+//		xconf.DefaultConfigWithKeysMetadata(xconf.KeysMetadata{
+//			"http.timeout": {Deprecated: true, DeprecationNote: "use http.read_timeout", RemovalVersion: "2.0"},
+//		}),
+//		xconf.DefaultConfigWithDeprecationEnforcement(appVersion, xconf.DeprecationActionLog(logger)),
+//	)
+func DefaultConfigWithDeprecationEnforcement(currentVersion string, action DeprecationAction) DefaultConfigOption {
+	return func(config *DefaultConfig) {
+		config.deprecation = &deprecationEnforcement{
+			currentVersion: currentVersion,
+			action:         action,
+		}
+	}
+}
+
+// deprecationEnforcement holds the state for enforcing keys' removal timeline.
+type deprecationEnforcement struct {
+	// currentVersion is application's current version.
+	currentVersion string
+	// action is triggered on a read of a key past its removal version.
+	action DeprecationAction
+	// removedKeys caches, per read key, its metadata if it's past its removal version, or nil otherwise.
+	removedKeys sync.Map
+}
+
+// enforceDeprecation triggers the deprecation action if key is past its removal version.
+func (cfg *defaultConfig) enforceDeprecation(key string) error {
+	var meta *KeyMeta
+	if cached, found := cfg.deprecation.removedKeys.Load(key); found {
+		meta = cached.(*KeyMeta)
+	} else {
+		keyMeta, found := cfg.Meta(key)
+		if found && keyMeta.RemovalVersion != "" &&
+			compareVersions(cfg.deprecation.currentVersion, keyMeta.RemovalVersion) >= 0 {
+			meta = &keyMeta
+		}
+		cfg.deprecation.removedKeys.Store(key, meta)
+	}
+	if meta == nil {
+		return nil
+	}
+
+	return cfg.deprecation.action(DeprecatedKeyUsage{
+		Key:            key,
+		Meta:           *meta,
+		CurrentVersion: cfg.deprecation.currentVersion,
+	})
+}
+
+// resetDeprecationCache forgets cached keys' removal status, as metadata changed.
+func (cfg *defaultConfig) resetDeprecationCache() {
+	if cfg.deprecation == nil {
+		return
+	}
+	cfg.deprecation.removedKeys.Range(func(key, _ any) bool {
+		cfg.deprecation.removedKeys.Delete(key)
+
+		return true
+	})
+}
+
+// compareVersions compares 2 dot separated versions, like "1.10.2" and "v1.9".
+// It returns -1 if v1 < v2, 0 if v1 == v2, +1 if v1 > v2.
+// Missing parts are considered 0. Non-numeric parts are compared lexically.
+func compareVersions(v1, v2 string) int {
+	parts1 := strings.Split(strings.TrimPrefix(strings.TrimSpace(v1), "v"), ".")
+	parts2 := strings.Split(strings.TrimPrefix(strings.TrimSpace(v2), "v"), ".")
+	for idx := 0; idx < len(parts1) || idx < len(parts2); idx++ {
+		part1, part2 := "0", "0"
+		if idx < len(parts1) {
+			part1 = parts1[idx]
+		}
+		if idx < len(parts2) {
+			part2 = parts2[idx]
+		}
+		num1, err1 := strconv.ParseUint(part1, 10, 64)
+		num2, err2 := strconv.ParseUint(part2, 10, 64)
+		switch {
+		case err1 == nil && err2 == nil:
+			if num1 != num2 {
+				if num1 < num2 {
+					return -1
+				}
+
+				return 1
+			}
+		default:
+			if cmp := strings.Compare(part1, part2); cmp != 0 {
+				return cmp
+			}
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,233 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/actforgood/xconf"
+	"github.com/actforgood/xlog"
+)
+
+func TestDefaultConfig_WithDeprecationEnforcement(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - action is triggered past removal version", testDefaultConfigDeprecationActionIsTriggered)
+	t.Run("success - reject action treats key as missing", testDefaultConfigDeprecationRejectAction)
+	t.Run("success - log action logs once per key", testDefaultConfigDeprecationLogAction)
+	t.Run("success - runtime registered metadata", testDefaultConfigDeprecationRuntimeRegisteredMeta)
+	t.Run("success - concurrency", testDefaultConfigDeprecationConcurrency)
+}
+
+func testDefaultConfigDeprecationActionIsTriggered(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		usages  []xconf.DeprecatedKeyUsage
+		subject *xconf.DefaultConfig
+		err     error
+	)
+	subject, err = xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{
+			"http.timeout":     "30s",
+			"legacy.host":      "10.0.0.1",
+			"http.max_headers": 100,
+		}),
+		xconf.DefaultConfigWithKeysMetadata(xconf.KeysMetadata{
+			"http.timeout": {
+				Deprecated:      true,
+				DeprecationNote: "use http.read_timeout",
+				RemovalVersion:  "v1.9",
+			},
+			"legacy.*":         {Deprecated: true, RemovalVersion: "1.10.0"},
+			"http.max_headers": {Deprecated: true, RemovalVersion: "2.0"},
+		}),
+		xconf.DefaultConfigWithDeprecationEnforcement("1.10", func(usage xconf.DeprecatedKeyUsage) error {
+			usages = append(usages, usage)
+
+			return nil
+		}),
+	)
+	requireNil(t, err)
+
+	// act
+	timeout := subject.Get("http.timeout", "")
+	host := subject.Get("legacy.host")
+	maxHeaders := subject.Get("http.max_headers", 0)
+	notFound := subject.Get("legacy.port", 80)
+
+	// assert
+	assertEqual(t, "30s", timeout)
+	assertEqual(t, "10.0.0.1", host)
+	assertEqual(t, 100, maxHeaders)
+	assertEqual(t, 80, notFound)
+	if assertEqual(t, 3, len(usages)) {
+		assertEqual(t, "http.timeout", usages[0].Key)
+		assertEqual(t, "1.10", usages[0].CurrentVersion)
+		assertEqual(
+			t,
+			`key "http.timeout" was scheduled for removal in v1.9, current version is 1.10 (use http.read_timeout)`,
+			usages[0].String(),
+		)
+		assertEqual(t, "legacy.host", usages[1].Key)
+		assertEqual(t, "legacy.port", usages[2].Key) // not found keys reads are reported, too.
+	}
+}
+
+func testDefaultConfigDeprecationRejectAction(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		counter int
+		subject *xconf.DefaultConfig
+		err     error
+	)
+	subject, err = xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{"http.timeout": "30s"}),
+		xconf.DefaultConfigWithKeysMetadata(xconf.KeysMetadata{
+			"http.timeout": {Deprecated: true, RemovalVersion: "2.0"},
+		}),
+		xconf.DefaultConfigWithDeprecationEnforcement("2.0.0", xconf.DeprecationActions(
+			func(xconf.DeprecatedKeyUsage) error {
+				counter++
+
+				return nil
+			},
+			xconf.DeprecationActionReject,
+		)),
+	)
+	requireNil(t, err)
+
+	// act
+	timeout := subject.Get("http.timeout", "10s")
+	rawTimeout := subject.Get("http.timeout")
+
+	// assert
+	assertEqual(t, "10s", timeout)
+	assertNil(t, rawTimeout)
+	assertEqual(t, 2, counter)
+
+	// act
+	err = xconf.DeprecationActionReject(xconf.DeprecatedKeyUsage{
+		Key:            "http.timeout",
+		Meta:           xconf.KeyMeta{RemovalVersion: "2.0"},
+		CurrentVersion: "2.0.0",
+	})
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrKeyRemoved))
+}
+
+func testDefaultConfigDeprecationLogAction(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	logger := xlog.NewMockLogger()
+	defer logger.Close()
+	logger.SetLogCallback(xlog.LevelWarning, func(keyValues ...any) {
+		assertEqual(
+			t,
+			[]any{
+				xlog.MessageKey, "[xconf] key is past its removal version",
+				"key", "HTTP_TIMEOUT",
+				"removalVersion", "2.0",
+				"currentVersion", "3.1",
+				"note", "use HTTP_READ_TIMEOUT",
+			},
+			keyValues,
+		)
+	})
+	subject, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{"http_timeout": "30s"}),
+		xconf.DefaultConfigWithIgnoreCaseSensitivity(),
+		xconf.DefaultConfigWithKeysMetadata(xconf.KeysMetadata{
+			"http_timeout": {Deprecated: true, DeprecationNote: "use HTTP_READ_TIMEOUT", RemovalVersion: "2.0"},
+		}),
+		xconf.DefaultConfigWithDeprecationEnforcement("3.1", xconf.DeprecationActionLog(logger)),
+	)
+	requireNil(t, err)
+
+	// act
+	for i := 0; i < 3; i++ {
+		assertEqual(t, "30s", subject.Get("http_timeout", ""))
+	}
+
+	// assert
+	assertEqual(t, 1, logger.LogCallsCount(xlog.LevelWarning))
+}
+
+func testDefaultConfigDeprecationRuntimeRegisteredMeta(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		usagesCnt int
+		subject   *xconf.DefaultConfig
+		err       error
+	)
+	subject, err = xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{"foo": "bar"}),
+		xconf.DefaultConfigWithDeprecationEnforcement("1.0", func(xconf.DeprecatedKeyUsage) error {
+			usagesCnt++
+
+			return nil
+		}),
+	)
+	requireNil(t, err)
+	_ = subject.Get("foo")
+	assertEqual(t, 0, usagesCnt)
+
+	// act
+	subject.RegisterMeta("foo", xconf.KeyMeta{Deprecated: true, RemovalVersion: "1.0"})
+	_ = subject.Get("foo")
+
+	// assert
+	assertEqual(t, 1, usagesCnt)
+}
+
+func testDefaultConfigDeprecationConcurrency(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		mu        sync.Mutex
+		usagesCnt int
+		wg        sync.WaitGroup
+	)
+	subject, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{"foo": "bar"}),
+		xconf.DefaultConfigWithKeysMetadata(xconf.KeysMetadata{"foo": {RemovalVersion: "1.0"}}),
+		xconf.DefaultConfigWithDeprecationEnforcement("1.0", func(xconf.DeprecatedKeyUsage) error {
+			mu.Lock()
+			usagesCnt++
+			mu.Unlock()
+
+			return nil
+		}),
+	)
+	requireNil(t, err)
+
+	// act
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = subject.Get("foo")
+		}()
+		go func() {
+			defer wg.Done()
+			subject.RegisterMeta("bar", xconf.KeyMeta{Owner: "team"})
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	assertEqual(t, 10, usagesCnt)
+}
@@ -0,0 +1,108 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrGlobalNotSet is an error returned by [GlobalE] if no process-wide
+// configuration, nor an initializer for it, was registered.
+var ErrGlobalNotSet = errors.New("global configuration is not set")
+
+// globalRegistry holds the process-wide configuration.
+var globalRegistry struct {
+	// cfg is the process-wide configuration, nil if not initialized yet.
+	cfg atomic.Pointer[Config]
+	// initFn is the optional function which lazily initializes the configuration.
+	initFn func() (Config, error)
+	// mu is a concurrency semaphore for initialization.
+	mu sync.Mutex
+}
+
+// SetGlobal registers given configuration as the process-wide one,
+// returned by [Global] / [GlobalE].
+func SetGlobal(cfg Config) {
+	globalRegistry.mu.Lock()
+	globalRegistry.cfg.Store(&cfg)
+	globalRegistry.mu.Unlock()
+}
+
+// SetGlobalInitializer registers a function which initializes the process-wide
+// configuration, lazily, at the first [Global] / [GlobalE] call.
+// Initializer is called once, even if [Global] is called concurrently;
+// if it fails, it is called again at next [Global] / [GlobalE] call.
+// It does not replace an already initialized process-wide configuration.
+//
+// Usage example:
+//
+//	xconf.SetGlobalInitializer(func() (xconf.Config, error) {
+//		return xconf.NewDefaultConfig(
+//			xconf.JSONFileLoader("config.json"),
+//			xconf.DefaultConfigWithReloadInterval(time.Minute),
+//		)
+//	})
+//	// ...
+//	port := xconf.Global().Get("port", 8080)
+func SetGlobalInitializer(initFn func() (Config, error)) {
+	globalRegistry.mu.Lock()
+	globalRegistry.initFn = initFn
+	globalRegistry.mu.Unlock()
+}
+
+// GlobalE returns the process-wide configuration, initializing it, if needed,
+// with the function registered through [SetGlobalInitializer].
+// Initializer's error, or [ErrGlobalNotSet] is returned if configuration is not available.
+func GlobalE() (Config, error) {
+	if cfg := globalRegistry.cfg.Load(); cfg != nil {
+		return *cfg, nil
+	}
+
+	globalRegistry.mu.Lock()
+	defer globalRegistry.mu.Unlock()
+
+	if cfg := globalRegistry.cfg.Load(); cfg != nil { // initialized meanwhile.
+		return *cfg, nil
+	}
+	if globalRegistry.initFn == nil {
+		return nil, ErrGlobalNotSet
+	}
+	cfg, err := globalRegistry.initFn()
+	if err != nil {
+		return nil, err
+	}
+	globalRegistry.cfg.Store(&cfg)
+
+	return cfg, nil
+}
+
+// Global returns the process-wide configuration (see [GlobalE]).
+// If configuration is not available, a [NopConfig] is returned.
+func Global() Config {
+	cfg, err := GlobalE()
+	if err != nil {
+		return NopConfig{}
+	}
+
+	return cfg
+}
+
+// ResetGlobal unregisters the process-wide configuration and its initializer,
+// returning the previous configuration, if any (so it can be closed, for example).
+// It is mainly useful in tests.
+func ResetGlobal() Config {
+	globalRegistry.mu.Lock()
+	defer globalRegistry.mu.Unlock()
+
+	globalRegistry.initFn = nil
+	if cfg := globalRegistry.cfg.Swap(nil); cfg != nil {
+		return *cfg
+	}
+
+	return nil
+}
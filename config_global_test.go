@@ -0,0 +1,122 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestGlobal(t *testing.T) {
+	// Note: do not run this test with t.Parallel() as subtests share the process-wide configuration.
+
+	t.Run("success - set global", testGlobalSet)
+	t.Run("success - lazy init once, concurrently", testGlobalLazyInitOnce)
+	t.Run("success - lazy init is retried after failure", testGlobalLazyInitRetriedAfterFailure)
+	t.Run("error - global not set", testGlobalReturnsErrGlobalNotSet)
+}
+
+func testGlobalSet(t *testing.T) {
+	// arrange
+	t.Cleanup(func() { xconf.ResetGlobal() })
+	config := xconf.NewMockConfig("foo", "bar")
+	xconf.SetGlobalInitializer(func() (xconf.Config, error) {
+		t.Error("initializer should not be called")
+
+		return nil, nil
+	})
+
+	// act
+	xconf.SetGlobal(config)
+	result1 := xconf.Global()
+	result2, err := xconf.GlobalE()
+	previous := xconf.ResetGlobal()
+
+	// assert
+	assertEqual(t, "bar", result1.Get("foo"))
+	assertNil(t, err)
+	assertEqual(t, "bar", result2.Get("foo"))
+	assertEqual(t, "bar", previous.Get("foo"))
+	assertEqual(t, xconf.NopConfig{}, xconf.Global())
+}
+
+func testGlobalLazyInitOnce(t *testing.T) {
+	// arrange
+	t.Cleanup(func() { xconf.ResetGlobal() })
+	var (
+		initCallsCnt uint32
+		goroutinesNo = 50
+		results      = make([]xconf.Config, goroutinesNo)
+		wg           sync.WaitGroup
+	)
+	xconf.SetGlobalInitializer(func() (xconf.Config, error) {
+		atomic.AddUint32(&initCallsCnt, 1)
+
+		return xconf.NewDefaultConfig(xconf.PlainLoader(map[string]any{"foo": "bar"}))
+	})
+
+	// act
+	for i := 0; i < goroutinesNo; i++ {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			results[idx] = xconf.Global()
+		}(i)
+	}
+	wg.Wait()
+
+	// assert
+	assertEqual(t, uint32(1), atomic.LoadUint32(&initCallsCnt))
+	for _, result := range results {
+		assertEqual(t, "bar", result.Get("foo"))
+		assertTrue(t, result == results[0])
+	}
+}
+
+func testGlobalLazyInitRetriedAfterFailure(t *testing.T) {
+	// arrange
+	t.Cleanup(func() { xconf.ResetGlobal() })
+	var (
+		initCallsCnt int
+		expectedErr  = errors.New("intentionally triggered init error")
+	)
+	xconf.SetGlobalInitializer(func() (xconf.Config, error) {
+		initCallsCnt++
+		if initCallsCnt == 1 {
+			return nil, expectedErr
+		}
+
+		return xconf.NewMockConfig("foo", "bar"), nil
+	})
+
+	// act
+	result1, err1 := xconf.GlobalE()
+	result2, err2 := xconf.GlobalE()
+
+	// assert
+	assertNil(t, result1)
+	assertTrue(t, errors.Is(err1, expectedErr))
+	assertNil(t, err2)
+	assertEqual(t, "bar", result2.Get("foo"))
+	assertEqual(t, 2, initCallsCnt)
+}
+
+func testGlobalReturnsErrGlobalNotSet(t *testing.T) {
+	// arrange
+	t.Cleanup(func() { xconf.ResetGlobal() })
+
+	// act
+	result, err := xconf.GlobalE()
+
+	// assert
+	assertNil(t, result)
+	assertTrue(t, errors.Is(err, xconf.ErrGlobalNotSet))
+	assertEqual(t, xconf.NopConfig{}, xconf.Global())
+}
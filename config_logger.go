@@ -0,0 +1,62 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import "time"
+
+// Logger is a structured logger, [DefaultConfig] logs its reload lifecycle with
+// (see [DefaultConfigWithLogger]). Arguments are alternating key-value pairs.
+// A *[log/slog.Logger] implements it; a xlog.Logger can be adapted with [XlogLogger].
+type Logger interface {
+	// Info logs an informational message.
+	Info(msg string, keyValues ...any)
+	// Error logs an error message.
+	Error(msg string, keyValues ...any)
+}
+
+// DefaultConfigWithLogger sets the logger the reload lifecycle is logged with, so that
+// reload behavior is observable without custom handlers:
+//   - reload start, success and failure (with its error), along with reload's duration;
+//   - changed keys' names (their values are never logged, so secrets do not leak);
+//   - observers' notification duration.
+//
+// Reload errors are still passed to the reload error handler, if any.
+//
+// By default, nothing is logged.
+//
+// Usage example:
+//
+//	cfg, err := xconf.NewDefaultConfig(
+//		loader,
+//		xconf.DefaultConfigWithReloadInterval(time.Minute),
+//		xconf.DefaultConfigWithLogger(slog.Default()),
+//	)
+func DefaultConfigWithLogger(logger Logger) DefaultConfigOption {
+	return func(config *DefaultConfig) {
+		config.logger = logger
+	}
+}
+
+// logReloadResult logs the result of a reload started at given time.
+func (cfg *defaultConfig) logReloadResult(startTime time.Time, err error) {
+	if err != nil {
+		cfg.logger.Error(
+			"[xconf] could not reload configuration",
+			"error", err,
+			"duration", time.Since(startTime),
+		)
+
+		return
+	}
+	cfg.logger.Info("[xconf] configuration reloaded", "duration", time.Since(startTime))
+}
+
+// logChangedKeys logs the names of the keys changed between given configuration maps, if any.
+func (cfg *defaultConfig) logChangedKeys(oldConfigMap, newConfigMap map[string]any) {
+	if changedKeys := Diff(oldConfigMap, newConfigMap).Keys(); len(changedKeys) > 0 {
+		cfg.logger.Info("[xconf] configuration changed", "changedKeys", changedKeys)
+	}
+}
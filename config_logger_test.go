@@ -0,0 +1,152 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+var _ xconf.Logger = slog.Default() // test *slog.Logger implements Logger.
+
+// loggerMock is a [xconf.Logger] mock, recording the logged entries.
+type loggerMock struct {
+	entries []string
+	mu      sync.Mutex
+}
+
+func (mock *loggerMock) Info(msg string, keyValues ...any) {
+	mock.log("INFO", msg, keyValues...)
+}
+
+func (mock *loggerMock) Error(msg string, keyValues ...any) {
+	mock.log("ERROR", msg, keyValues...)
+}
+
+func (mock *loggerMock) log(level, msg string, keyValues ...any) {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	mock.entries = append(mock.entries, fmt.Sprintf("%s %s %v", level, msg, keyValues))
+}
+
+// contains checks whether an entry contains all given substrings.
+func (mock *loggerMock) contains(substrings ...string) bool {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+
+	for _, entry := range mock.entries {
+		found := true
+		for _, substring := range substrings {
+			if !strings.Contains(entry, substring) {
+				found = false
+
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String returns all entries, one per line.
+func (mock *loggerMock) String() string {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+
+	return strings.Join(mock.entries, "\n")
+}
+
+func TestDefaultConfigWithLogger(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - reload lifecycle is logged", testDefaultConfigWithLoggerLogsReload)
+	t.Run("error - reload failure is logged", testDefaultConfigWithLoggerLogsReloadFailure)
+}
+
+func testDefaultConfigWithLoggerLogsReload(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loadsCnt uint32
+		loader   = xconf.LoaderFunc(func() (map[string]any, error) {
+			cnt := atomic.AddUint32(&loadsCnt, 1)
+
+			return map[string]any{
+				"app":         "demo",
+				"db_password": fmt.Sprintf("s3cr3t-%d", cnt),
+			}, nil
+		})
+		logger = new(loggerMock)
+	)
+	subject, err := xconf.NewDefaultConfig(
+		loader,
+		xconf.DefaultConfigWithReloadInterval(20*time.Millisecond),
+		xconf.DefaultConfigWithLogger(logger),
+	)
+	requireNil(t, err)
+	subject.RegisterObserver(func(xconf.Config, ...string) {})
+
+	// act
+	time.Sleep(70 * time.Millisecond)
+	_ = subject.Close()
+
+	// assert
+	assertTrue(t, logger.contains("INFO [xconf] configuration changed [changedKeys [app db_password]]"))
+	assertTrue(t, logger.contains("INFO [xconf] reloading configuration"))
+	assertTrue(t, logger.contains("INFO [xconf] configuration reloaded [duration"))
+	assertTrue(t, logger.contains("INFO [xconf] configuration changed [changedKeys [db_password]]"))
+	assertTrue(t, logger.contains("INFO [xconf] observers notified [observers 1 duration"))
+	assertTrue(t, !strings.Contains(logger.String(), "s3cr3t"))
+}
+
+func testDefaultConfigWithLoggerLogsReloadFailure(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loadsCnt    uint32
+		expectedErr = errors.New("intentionally triggered reload error")
+		loader      = xconf.LoaderFunc(func() (map[string]any, error) {
+			if atomic.AddUint32(&loadsCnt, 1) > 1 {
+				return nil, expectedErr
+			}
+
+			return map[string]any{"app": "demo"}, nil
+		})
+		logger        = new(loggerMock)
+		handledErrCnt uint32
+	)
+	subject, err := xconf.NewDefaultConfig(
+		loader,
+		xconf.DefaultConfigWithReloadInterval(20*time.Millisecond),
+		xconf.DefaultConfigWithLogger(logger),
+		xconf.DefaultConfigWithReloadErrorHandler(func(error) {
+			atomic.AddUint32(&handledErrCnt, 1)
+		}),
+	)
+	requireNil(t, err)
+
+	// act
+	time.Sleep(50 * time.Millisecond)
+	_ = subject.Close()
+
+	// assert
+	assertTrue(t, logger.contains("ERROR [xconf] could not reload configuration [error "+expectedErr.Error()+" duration"))
+	assertTrue(t, !logger.contains("[xconf] configuration reloaded"))
+	assertTrue(t, atomic.LoadUint32(&handledErrCnt) > 0)
+}
@@ -0,0 +1,216 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KeyMeta is the documentation / metadata attached to a configuration key.
+type KeyMeta struct {
+	// Description describes the key's purpose.
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Owner is the team / person responsible for the key.
+	Owner string `json:"owner,omitempty" yaml:"owner,omitempty"`
+	// Deprecated marks the key as deprecated.
+	Deprecated bool `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	// DeprecationNote explains what to use instead of a deprecated key.
+	DeprecationNote string `json:"deprecation_note,omitempty" yaml:"deprecation_note,omitempty"`
+	// RemovalVersion is the application's version the key is scheduled to be removed in,
+	// see [DefaultConfigWithDeprecationEnforcement].
+	RemovalVersion string `json:"removal_version,omitempty" yaml:"removal_version,omitempty"`
+	// Sensitive marks the key's value as sensitive (a secret),
+	// it gets masked wherever configuration is exposed (like [LogEffectiveConfig]).
+	Sensitive bool `json:"sensitive,omitempty" yaml:"sensitive,omitempty"`
+}
+
+// KeysMetadata holds keys' metadata.
+// A map key is either a configuration key, or a [path.Match] pattern, like "db.*".
+type KeysMetadata map[string]KeyMeta
+
+// Lookup returns the metadata for given configuration key.
+// An exact match has priority over patterns; patterns are tried in lexical order.
+// If ignoreCase is true, keys / patterns are matched case-insensitive.
+func (metadata KeysMetadata) Lookup(key string, ignoreCase bool) (KeyMeta, bool) {
+	if meta, found := metadata[key]; found {
+		return meta, true
+	}
+	if ignoreCase {
+		key = strings.ToLower(key)
+	}
+	for _, pattern := range metadata.sortedKeys() {
+		candidate := pattern
+		if ignoreCase {
+			candidate = strings.ToLower(pattern)
+		}
+		if matched, _ := path.Match(candidate, key); matched {
+			return metadata[pattern], true
+		}
+	}
+
+	return KeyMeta{}, false
+}
+
+// Markdown returns a documentation table of the keys' metadata, sorted by key.
+//
+// Example:
+//
+//	| Key | Description | Owner | Notes |
+//	|-----|-------------|-------|-------|
+//	| `db.password` | Database password. | platform | sensitive |
+//	| `http.timeout` | Server timeout. | web | deprecated: use http.read_timeout |
+func (metadata KeysMetadata) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("| Key | Description | Owner | Notes |\n")
+	sb.WriteString("|-----|-------------|-------|-------|\n")
+	for _, key := range metadata.sortedKeys() {
+		meta := metadata[key]
+		notes := make([]string, 0, 2)
+		if meta.Sensitive {
+			notes = append(notes, "sensitive")
+		}
+		if meta.Deprecated {
+			note := "deprecated"
+			if meta.DeprecationNote != "" {
+				note += ": " + meta.DeprecationNote
+			}
+			if meta.RemovalVersion != "" {
+				note += " (removal in " + meta.RemovalVersion + ")"
+			}
+			notes = append(notes, note)
+		}
+		sb.WriteString("| `" + key + "` | ")
+		sb.WriteString(escapeMarkdownCell(meta.Description) + " | ")
+		sb.WriteString(escapeMarkdownCell(meta.Owner) + " | ")
+		sb.WriteString(escapeMarkdownCell(strings.Join(notes, ", ")) + " |\n")
+	}
+
+	return sb.String()
+}
+
+// sortedKeys returns the map keys, sorted.
+func (metadata KeysMetadata) sortedKeys() []string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// escapeMarkdownCell escapes a value to be placed inside a markdown table cell.
+func escapeMarkdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", `\|`)
+
+	return strings.ReplaceAll(value, "\n", " ")
+}
+
+// LoadKeysMetadataFile reads keys' metadata from a sidecar file.
+// The file can be a YAML or a JSON one, having configuration keys (or patterns) as
+// top level keys, and their metadata as values.
+//
+// Example of YAML content:
+//
+//	db.password:
+//	  description: Database password.
+//	  owner: platform
+//	  sensitive: true
+//	http.timeout:
+//	  description: Server timeout.
+//	  deprecated: true
+//	  deprecation_note: use http.read_timeout
+//	  removal_version: "2.0"
+func LoadKeysMetadataFile(filePath string) (KeysMetadata, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	metadata := make(KeysMetadata)
+	if err := yaml.Unmarshal(content, &metadata); err != nil { // JSON is valid YAML, too.
+		return nil, err
+	}
+
+	return metadata, nil
+}
+
+// DefaultConfigWithKeysMetadata attaches metadata to keys.
+// It can be applied multiple times, metadata being merged.
+// Keys' metadata can be queried at runtime through DefaultConfig's Meta.
+//
+// Usage example:
+//
+//	metadata, err := xconf.LoadKeysMetadataFile("config.meta.yaml")
+//	if err != nil {
+//		panic(err)
+//	}
+//	cfg, err := xconf.NewDefaultConfig(loader, xconf.DefaultConfigWithKeysMetadata(metadata))
+func DefaultConfigWithKeysMetadata(metadata KeysMetadata) DefaultConfigOption {
+	return func(config *DefaultConfig) {
+		if config.keysMetadata == nil {
+			config.keysMetadata = make(KeysMetadata, len(metadata))
+		}
+		for key, meta := range metadata {
+			config.keysMetadata[key] = meta
+		}
+	}
+}
+
+// RegisterMeta attaches metadata to a key (or a [path.Match] pattern).
+// Previous metadata of the same key, if any, is replaced.
+func (cfg *defaultConfig) RegisterMeta(key string, meta KeyMeta) {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+
+	keysMetadata := make(KeysMetadata, len(cfg.keysMetadata)+1)
+	for k, m := range cfg.keysMetadata {
+		keysMetadata[k] = m
+	}
+	keysMetadata[key] = meta
+	cfg.keysMetadata = keysMetadata
+	cfg.resetDeprecationCache()
+}
+
+// Meta returns the metadata attached to given key, if any.
+func (cfg *defaultConfig) Meta(key string) (KeyMeta, bool) {
+	cfg.mu.RLock()
+	keysMetadata := cfg.keysMetadata
+	cfg.mu.RUnlock()
+
+	return keysMetadata.Lookup(key, cfg.ignoreCaseSensitivity)
+}
+
+// KeysMetadata returns a copy of all keys' metadata.
+func (cfg *defaultConfig) KeysMetadata() KeysMetadata {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+
+	keysMetadata := make(KeysMetadata, len(cfg.keysMetadata))
+	for key, meta := range cfg.keysMetadata {
+		keysMetadata[key] = meta
+	}
+
+	return keysMetadata
+}
+
+// keyMetaProvider is implemented by configs able to provide keys' metadata.
+type keyMetaProvider interface {
+	Meta(key string) (KeyMeta, bool)
+}
+
+// redactSensitiveKeys masks (in place) values of (first level) keys marked as sensitive.
+func redactSensitiveKeys(configMap map[string]any, provider keyMetaProvider) {
+	for key := range configMap {
+		if meta, found := provider.Meta(key); found && meta.Sensitive {
+			configMap[key] = redactedValue
+		}
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestDefaultConfig_Meta(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - option and registration", testDefaultConfigMetaFromOptionAndRegistration)
+	t.Run("success - case insensitive keys", testDefaultConfigMetaCaseInsensitive)
+	t.Run("success - concurrency", testDefaultConfigMetaConcurrency)
+}
+
+func testDefaultConfigMetaFromOptionAndRegistration(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{"db.host": "10.0.0.1", "db.password": "s3cr3t"}),
+		xconf.DefaultConfigWithKeysMetadata(xconf.KeysMetadata{
+			"db.*":        {Owner: "platform"},
+			"db.password": {Description: "Database password.", Owner: "platform", Sensitive: true},
+		}),
+	)
+	requireNil(t, err)
+
+	// act
+	meta, found := subject.Meta("db.password")
+
+	// assert
+	assertTrue(t, found)
+	assertEqual(t, xconf.KeyMeta{Description: "Database password.", Owner: "platform", Sensitive: true}, meta)
+
+	// act - pattern match
+	meta, found = subject.Meta("db.host")
+
+	// assert
+	assertTrue(t, found)
+	assertEqual(t, xconf.KeyMeta{Owner: "platform"}, meta)
+
+	// act - not found
+	meta, found = subject.Meta("http.timeout")
+
+	// assert
+	assertTrue(t, !found)
+	assertEqual(t, xconf.KeyMeta{}, meta)
+
+	// act - register at runtime
+	subject.RegisterMeta("http.timeout", xconf.KeyMeta{Deprecated: true, DeprecationNote: "use http.read_timeout"})
+	meta, found = subject.Meta("http.timeout")
+
+	// assert
+	assertTrue(t, found)
+	assertEqual(t, xconf.KeyMeta{Deprecated: true, DeprecationNote: "use http.read_timeout"}, meta)
+	assertEqual(t, 3, len(subject.KeysMetadata()))
+}
+
+func testDefaultConfigMetaCaseInsensitive(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{"db_password": "s3cr3t"}),
+		xconf.DefaultConfigWithIgnoreCaseSensitivity(),
+		xconf.DefaultConfigWithKeysMetadata(xconf.KeysMetadata{"db_password": {Sensitive: true}}),
+	)
+	requireNil(t, err)
+
+	// act
+	meta, found := subject.Meta("DB_PASSWORD")
+
+	// assert
+	assertTrue(t, found)
+	assertTrue(t, meta.Sensitive)
+}
+
+func testDefaultConfigMetaConcurrency(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject, _ = xconf.NewDefaultConfig(xconf.PlainLoader(map[string]any{"foo": "bar"}))
+		wg         sync.WaitGroup
+	)
+
+	// act
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			subject.RegisterMeta("foo", xconf.KeyMeta{Owner: "team"})
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = subject.Meta("foo")
+			_ = subject.KeysMetadata()
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	meta, found := subject.Meta("foo")
+	assertTrue(t, found)
+	assertEqual(t, "team", meta.Owner)
+}
+
+func TestLoadKeysMetadataFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - yaml", testLoadKeysMetadataFileYAML)
+	t.Run("success - json", testLoadKeysMetadataFileJSON)
+	t.Run("error - not found", testLoadKeysMetadataFileReturnsErrNotExist)
+}
+
+func testLoadKeysMetadataFileYAML(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	filePath := filepath.Join(t.TempDir(), "config.meta.yaml")
+	content := `db.password:
+  description: Database password.
+  owner: platform
+  sensitive: true
+http.timeout:
+  description: Server timeout.
+  deprecated: true
+  deprecation_note: use http.read_timeout
+`
+	requireNil(t, os.WriteFile(filePath, []byte(content), 0o600))
+
+	// act
+	metadata, err := xconf.LoadKeysMetadataFile(filePath)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		xconf.KeysMetadata{
+			"db.password": {Description: "Database password.", Owner: "platform", Sensitive: true},
+			"http.timeout": {
+				Description:     "Server timeout.",
+				Deprecated:      true,
+				DeprecationNote: "use http.read_timeout",
+			},
+		},
+		metadata,
+	)
+	assertEqual(
+		t,
+		"| Key | Description | Owner | Notes |\n"+
+			"|-----|-------------|-------|-------|\n"+
+			"| `db.password` | Database password. | platform | sensitive |\n"+
+			"| `http.timeout` | Server timeout. |  | deprecated: use http.read_timeout |\n",
+		metadata.Markdown(),
+	)
+}
+
+func testLoadKeysMetadataFileJSON(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	filePath := filepath.Join(t.TempDir(), "config.meta.json")
+	content := `{"api.key": {"description": "API key | v2", "sensitive": true}}`
+	requireNil(t, os.WriteFile(filePath, []byte(content), 0o600))
+
+	// act
+	metadata, err := xconf.LoadKeysMetadataFile(filePath)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, xconf.KeysMetadata{"api.key": {Description: "API key | v2", Sensitive: true}}, metadata)
+	assertEqual(
+		t,
+		"| Key | Description | Owner | Notes |\n"+
+			"|-----|-------------|-------|-------|\n"+
+			"| `api.key` | API key \\| v2 |  | sensitive |\n",
+		metadata.Markdown(),
+	)
+}
+
+func testLoadKeysMetadataFileReturnsErrNotExist(t *testing.T) {
+	t.Parallel()
+
+	// act
+	metadata, err := xconf.LoadKeysMetadataFile(filepath.Join(t.TempDir(), "not-existing.yaml"))
+
+	// assert
+	assertTrue(t, errors.Is(err, os.ErrNotExist))
+	assertNil(t, metadata)
+}
@@ -48,6 +48,14 @@ func (mock *MockConfig) Get(key string, def ...any) any {
 	return mock.cfg.Get(key, def...)
 }
 
+// configMapSnapshot returns a copy of the mocked configuration map.
+func (mock *MockConfig) configMapSnapshot() map[string]any {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+
+	return DeepCopyConfigMap(mock.configMap)
+}
+
 // SetKeyValues sets/resets given key-values.
 // Make sure you pass an even number of elements and that the keys are strings.
 func (mock *MockConfig) SetKeyValues(kv ...any) {
@@ -0,0 +1,92 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultConfigWithKeyDelimiter enables nested keys lookup in Get:
+// a key not found as it is, and containing given delimiter, is looked up
+// by traversing the nested maps (like "db.mysql.host" for the "host" key of
+// the "mysql" map under "db" key), avoiding the memory cost of a [FlattenLoader]
+// duplicating all the nested values under flat keys.
+// A key's segment may contain the delimiter itself (like "app.name" key of a map).
+// If [DefaultConfigWithIgnoreCaseSensitivity] is applied, too, nested maps' keys are
+// matched case-insensitively.
+//
+// By default, nested keys lookup is disabled.
+//
+// Usage example:
+//
+//	cfg, err := xconf.NewDefaultConfig(
+//		xconf.YAMLFileLoader("config.yaml"),
+//		xconf.DefaultConfigWithKeyDelimiter("."),
+//	)
+//	if err != nil {
+//		panic(err)
+//	}
+//	host := cfg.Get("db.mysql.host", "localhost").(string)
+func DefaultConfigWithKeyDelimiter(delimiter string) DefaultConfigOption {
+	return func(config *DefaultConfig) {
+		config.keyDelimiter = delimiter
+	}
+}
+
+// lookupNestedKey looks up the value of a key whose segments, separated by delimiter,
+// are the keys of nested maps.
+func lookupNestedKey(configMap map[string]any, key, delimiter string, ignoreCase bool) (any, bool) {
+	segments := strings.Split(key, delimiter)
+
+	return lookupNestedSegments(configMap, segments, delimiter, ignoreCase)
+}
+
+// lookupNestedSegments looks up recursively the value of given key segments,
+// trying, for current map, keys made of one or more leading segments.
+func lookupNestedSegments(value any, segments []string, delimiter string, ignoreCase bool) (any, bool) {
+	for idx := 1; idx <= len(segments); idx++ {
+		key := strings.Join(segments[:idx], delimiter)
+		nestedValue, found := lookupMapKey(value, key, ignoreCase)
+		if !found {
+			continue
+		}
+		if idx == len(segments) {
+			return nestedValue, true
+		}
+		if result, found := lookupNestedSegments(nestedValue, segments[idx:], delimiter, ignoreCase); found {
+			return result, true
+		}
+	}
+
+	return nil, false
+}
+
+// lookupMapKey returns the value of a key, if given value is a map containing it.
+func lookupMapKey(value any, key string, ignoreCase bool) (any, bool) {
+	switch m := value.(type) {
+	case map[string]any:
+		if mapValue, found := m[key]; found {
+			return mapValue, true
+		}
+		if ignoreCase {
+			for mapKey, mapValue := range m {
+				if strings.EqualFold(mapKey, key) {
+					return mapValue, true
+				}
+			}
+		}
+	case map[any]any:
+		for mapKey, mapValue := range m {
+			strKey := fmt.Sprintf("%v", mapKey)
+			if strKey == key || (ignoreCase && strings.EqualFold(strKey, key)) {
+				return mapValue, true
+			}
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,92 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestDefaultConfig_WithKeyDelimiter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - nested keys are looked up", testDefaultConfigWithKeyDelimiter)
+	t.Run("success - nested keys are looked up case-insensitively", testDefaultConfigWithKeyDelimiterIgnoreCase)
+	t.Run("success - nested keys lookup is disabled by default", testDefaultConfigWithoutKeyDelimiter)
+}
+
+func getNestedConfigMap() map[string]any {
+	return map[string]any{
+		"db": map[string]any{
+			"mysql": map[string]any{
+				"host": "127.0.0.1",
+				"port": 3306,
+			},
+		},
+		"app": map[any]any{
+			"timeout":  "5s",
+			"log.file": "/var/log/app.log",
+		},
+		"db.mysql.user": "root",
+	}
+}
+
+func testDefaultConfigWithKeyDelimiter(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(getNestedConfigMap()),
+		xconf.DefaultConfigWithKeyDelimiter("."),
+		xconf.DefaultConfigWithReloadInterval(time.Hour),
+	)
+	requireNil(t, err)
+	defer subject.Close()
+
+	// act & assert
+	assertEqual(t, "127.0.0.1", subject.Get("db.mysql.host"))
+	assertEqual(t, uint16(3306), subject.Get("db.mysql.port", uint16(0)))
+	assertEqual(t, 5*time.Second, subject.Get("app.timeout", time.Duration(0)))
+	assertEqual(t, "/var/log/app.log", subject.Get("app.log.file"))
+	assertEqual(t, "root", subject.Get("db.mysql.user"))
+	assertEqual(t, map[string]any{"host": "127.0.0.1", "port": 3306}, subject.Get("db.mysql"))
+	assertNil(t, subject.Get("db.mysql.host.name"))
+	assertNil(t, subject.Get("db.postgres.host"))
+	assertEqual(t, "localhost", subject.Get("db.postgres.host", "localhost"))
+	assertNil(t, subject.Get("DB.MYSQL.HOST"))
+}
+
+func testDefaultConfigWithKeyDelimiterIgnoreCase(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(getNestedConfigMap()),
+		xconf.DefaultConfigWithKeyDelimiter("__"),
+		xconf.DefaultConfigWithIgnoreCaseSensitivity(),
+	)
+	requireNil(t, err)
+
+	// act & assert
+	assertEqual(t, "127.0.0.1", subject.Get("DB__MYSQL__HOST"))
+	assertEqual(t, "127.0.0.1", subject.Get("db__mysql__host"))
+	assertEqual(t, "/var/log/app.log", subject.Get("App__Log.File"))
+	assertNil(t, subject.Get("db.mysql.host"))
+}
+
+func testDefaultConfigWithoutKeyDelimiter(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(xconf.PlainLoader(getNestedConfigMap()))
+	requireNil(t, err)
+
+	// act & assert
+	assertNil(t, subject.Get("db.mysql.host"))
+	assertEqual(t, "root", subject.Get("db.mysql.user"))
+}
@@ -0,0 +1,106 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"strings"
+)
+
+// Set overrides a key's value at runtime. The override sits on top of the
+// loaded configuration and survives reloads, until Unset is called for the key.
+// It is useful for tests and admin endpoints, without building a custom Loader.
+// Observers and watchers are notified about the key, if its value changed.
+// Validation rules, if any (see [DefaultConfigWithValidation]), are checked against
+// the resulting configuration, and, if they fail, the override is not applied.
+// Reload must be enabled, otherwise [ErrReloadDisabled] is returned.
+func (cfg *defaultConfig) Set(key string, value any) error {
+	return cfg.changeOverrides(key, func(overrides map[string]any, key string) {
+		overrides[key] = value
+	})
+}
+
+// Unset removes a key's runtime override (see Set), the loaded value (if any) becoming active again.
+// Observers and watchers are notified about the key, if its value changed.
+// Reload must be enabled, otherwise [ErrReloadDisabled] is returned.
+func (cfg *defaultConfig) Unset(key string) error {
+	return cfg.changeOverrides(key, func(overrides map[string]any, key string) {
+		delete(overrides, key)
+	})
+}
+
+// Overrides returns a copy of the runtime overridden keys and their values.
+func (cfg *defaultConfig) Overrides() map[string]any {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+
+	return DeepCopyConfigMap(cfg.overrides)
+}
+
+// changeOverrides replaces the overrides with a copy altered by given function,
+// and computes the new effective configuration.
+func (cfg *defaultConfig) changeOverrides(key string, alter func(overrides map[string]any, key string)) error {
+	if cfg.reloadInterval <= 0 {
+		return ErrReloadDisabled
+	}
+	if cfg.ignoreCaseSensitivity {
+		key = strings.ToUpper(key)
+	}
+
+	cfg.mu.Lock()
+	overrides := make(map[string]any, len(cfg.overrides)+1)
+	for overriddenKey, value := range cfg.overrides {
+		overrides[overriddenKey] = value
+	}
+	alter(overrides, key)
+	if len(overrides) == 0 {
+		overrides = nil
+	}
+	newConfigMap, err := cfg.effectiveConfigMap(cfg.loadedConfigMap, overrides)
+	if err != nil {
+		cfg.mu.Unlock()
+
+		return err
+	}
+	oldConfigMap := cfg.configMap
+	cfg.overrides = overrides
+	cfg.configMap = newConfigMap
+	cfg.mu.Unlock()
+
+	cfg.afterConfigMapChange(oldConfigMap, newConfigMap)
+
+	return nil
+}
+
+// applyOverrides returns the configuration map with overrides applied.
+// If ignoreCase is true, overrides' keys are uppercased, and they replace the values
+// of the keys matching them case-insensitively (keeping the keys' case).
+// If there are no overrides, given configuration map is returned as it is.
+func applyOverrides(configMap, overrides map[string]any, ignoreCase bool) map[string]any {
+	if len(overrides) == 0 {
+		return configMap
+	}
+
+	newConfigMap := make(map[string]any, len(configMap)+len(overrides))
+	appliedOverrides := make(map[string]struct{}, len(overrides))
+	for key, value := range configMap {
+		overriddenKey := key
+		if ignoreCase {
+			overriddenKey = strings.ToUpper(key)
+		}
+		if overriddenValue, found := overrides[overriddenKey]; found {
+			value = overriddenValue
+			appliedOverrides[overriddenKey] = struct{}{}
+		}
+		newConfigMap[key] = value
+	}
+	for key, value := range overrides {
+		if _, applied := appliedOverrides[key]; !applied {
+			newConfigMap[key] = value
+		}
+	}
+
+	return newConfigMap
+}
@@ -0,0 +1,200 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestDefaultConfig_Set(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - override survives reloads", testDefaultConfigSetSurvivesReloads)
+	t.Run("success - unset restores loaded value", testDefaultConfigUnsetRestoresLoadedValue)
+	t.Run("success - ignore case sensitivity", testDefaultConfigSetIgnoreCase)
+	t.Run("error - reload disabled", testDefaultConfigSetReturnsErrReloadDisabled)
+	t.Run("error - validation", testDefaultConfigSetReturnsValidationErr)
+	t.Run("error - validation, ignore case sensitivity", testDefaultConfigSetReturnsValidationErrIgnoreCase)
+}
+
+func testDefaultConfigSetSurvivesReloads(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loadsCnt uint32
+		loader   = xconf.LoaderFunc(func() (map[string]any, error) {
+			atomic.AddUint32(&loadsCnt, 1)
+
+			return map[string]any{"log.level": "info", "app.name": "demo"}, nil
+		})
+		mu           sync.Mutex
+		observedKeys []string
+		subject, err = xconf.NewDefaultConfig(loader, xconf.DefaultConfigWithReloadInterval(10*time.Millisecond))
+	)
+	requireNil(t, err)
+	defer subject.Close()
+	subject.RegisterObserver(func(_ xconf.Config, changedKeys ...string) {
+		mu.Lock()
+		observedKeys = append(observedKeys, changedKeys...)
+		mu.Unlock()
+	})
+
+	// act
+	err = subject.Set("log.level", "debug")
+	requireNil(t, err)
+	err = subject.Set("feature.x", true)
+	requireNil(t, err)
+	time.Sleep(35 * time.Millisecond) // some reloads
+
+	// assert
+	assertTrue(t, atomic.LoadUint32(&loadsCnt) > 1)
+	assertEqual(t, "debug", subject.Get("log.level"))
+	assertEqual(t, true, subject.Get("feature.x"))
+	assertEqual(t, "demo", subject.Get("app.name"))
+	assertEqual(t, map[string]any{"log.level": "debug", "feature.x": true}, subject.Overrides())
+	mu.Lock()
+	assertEqual(t, []string{"log.level", "feature.x"}, observedKeys)
+	mu.Unlock()
+}
+
+func testDefaultConfigUnsetRestoresLoadedValue(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		observedKeys []string
+		subject, err = xconf.NewDefaultConfig(
+			xconf.PlainLoader(map[string]any{"log.level": "info"}),
+			xconf.DefaultConfigWithReloadInterval(time.Hour),
+		)
+	)
+	requireNil(t, err)
+	defer subject.Close()
+	requireNil(t, subject.Set("log.level", "debug"))
+	requireNil(t, subject.Set("feature.x", true))
+	subject.RegisterObserver(func(_ xconf.Config, changedKeys ...string) {
+		observedKeys = append(observedKeys, changedKeys...)
+	})
+
+	// act
+	err1 := subject.Unset("log.level")
+	err2 := subject.Unset("feature.x")
+	err3 := subject.Unset("not.overridden")
+
+	// assert
+	assertNil(t, err1)
+	assertNil(t, err2)
+	assertNil(t, err3)
+	assertEqual(t, "info", subject.Get("log.level"))
+	assertNil(t, subject.Get("feature.x"))
+	assertEqual(t, map[string]any{}, subject.Overrides())
+	assertEqual(t, []string{"log.level", "feature.x"}, observedKeys)
+}
+
+func testDefaultConfigSetIgnoreCase(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{"log.level": "info"}),
+		xconf.DefaultConfigWithReloadInterval(time.Hour),
+		xconf.DefaultConfigWithIgnoreCaseSensitivity(),
+	)
+	requireNil(t, err)
+	defer subject.Close()
+
+	// act
+	err = subject.Set("Log.Level", "debug")
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, "debug", subject.Get("log.level"))
+	assertEqual(t, map[string]any{"LOG.LEVEL": "debug"}, subject.Overrides())
+
+	// act
+	err = subject.Unset("LOG.level")
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, "info", subject.Get("log.level"))
+}
+
+func testDefaultConfigSetReturnsErrReloadDisabled(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(xconf.PlainLoader(map[string]any{"log.level": "info"}))
+	requireNil(t, err)
+
+	// act
+	err1 := subject.Set("log.level", "debug")
+	err2 := subject.Unset("log.level")
+
+	// assert
+	assertTrue(t, errors.Is(err1, xconf.ErrReloadDisabled))
+	assertTrue(t, errors.Is(err2, xconf.ErrReloadDisabled))
+	assertEqual(t, "info", subject.Get("log.level"))
+}
+
+func testDefaultConfigSetReturnsValidationErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{"http.port": 8080}),
+		xconf.DefaultConfigWithReloadInterval(time.Hour),
+		xconf.DefaultConfigWithValidation(xconf.KeyRange("http.port", 1, 65535)),
+	)
+	requireNil(t, err)
+	defer subject.Close()
+
+	// act
+	err = subject.Set("http.port", 70000)
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrValidation))
+	assertEqual(t, 8080, subject.Get("http.port"))
+	assertEqual(t, map[string]any{}, subject.Overrides())
+}
+
+func testDefaultConfigSetReturnsValidationErrIgnoreCase(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{"db.host": "10.0.0.1", "db.port": 5432}),
+		xconf.DefaultConfigWithReloadInterval(time.Hour),
+		xconf.DefaultConfigWithIgnoreCaseSensitivity(),
+		xconf.DefaultConfigWithValidation(
+			xconf.RequiredKeys("db.host"),
+			xconf.KeyRange("db.port", 1, 65535),
+		),
+	)
+	requireNil(t, err)
+	defer subject.Close()
+
+	// act
+	errSet := subject.Set("DB.Host", "10.0.0.2")
+	errReloadPrefix := subject.ReloadPrefix(context.Background(), "DB.")
+	errUnset := subject.Unset("db.host")
+	errInvalidSet := subject.Set("DB.PORT", 70000)
+
+	// assert
+	assertNil(t, errSet)
+	assertNil(t, errReloadPrefix)
+	assertNil(t, errUnset)
+	assertTrue(t, errors.Is(errInvalidSet, xconf.ErrValidation))
+	assertEqual(t, "10.0.0.1", subject.Get("db.host"))
+	assertEqual(t, 5432, subject.Get("db.port"))
+}
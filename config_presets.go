@@ -0,0 +1,51 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"log/slog"
+	"time"
+)
+
+const (
+	// ProductionReloadInterval is the reload interval set by [Production] preset.
+	ProductionReloadInterval = time.Minute
+	// DevelopmentReloadInterval is the reload interval set by [Development] preset.
+	DevelopmentReloadInterval = 2 * time.Second
+)
+
+// Production instantiates a new DefaultConfig with defaults suitable for a production environment:
+//   - configuration is reloaded every [ProductionReloadInterval];
+//   - reload errors are logged with [slog.Default] logger;
+//   - keys' case sensitivity is ignored.
+//
+// Passed options are applied after preset's ones, so they can overwrite them.
+func Production(loader Loader, opts ...DefaultConfigOption) (*DefaultConfig, error) {
+	return NewDefaultConfig(loader, presetOptions(ProductionReloadInterval, opts)...)
+}
+
+// Development instantiates a new DefaultConfig with defaults suitable for a development environment:
+//   - configuration is reloaded every [DevelopmentReloadInterval], so changes are picked up fast;
+//   - reload errors are logged with [slog.Default] logger;
+//   - keys' case sensitivity is ignored.
+//
+// Passed options are applied after preset's ones, so they can overwrite them.
+func Development(loader Loader, opts ...DefaultConfigOption) (*DefaultConfig, error) {
+	return NewDefaultConfig(loader, presetOptions(DevelopmentReloadInterval, opts)...)
+}
+
+// presetOptions returns the common preset options, followed by the custom ones.
+func presetOptions(reloadInterval time.Duration, opts []DefaultConfigOption) []DefaultConfigOption {
+	presetOpts := make([]DefaultConfigOption, 0, 3+len(opts))
+	presetOpts = append(
+		presetOpts,
+		DefaultConfigWithReloadInterval(reloadInterval),
+		DefaultConfigWithReloadErrorHandler(SlogErrorHandler(slog.Default)),
+		DefaultConfigWithIgnoreCaseSensitivity(),
+	)
+
+	return append(presetOpts, opts...)
+}
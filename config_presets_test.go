@@ -0,0 +1,69 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestProduction(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	loader := xconf.PlainLoader(map[string]any{"foo": "bar"})
+
+	// act
+	subject, err := xconf.Production(loader)
+
+	// assert
+	requireNil(t, err)
+	defer subject.Close()
+	assertEqual(t, "bar", subject.Get("FOO"))
+	assertEqual(t, "bar", subject.Get("foo"))
+}
+
+func TestDevelopment(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loadsCnt    uint32
+		expectedErr = errors.New("intentionally triggered reload error")
+		loader      = xconf.LoaderFunc(func() (map[string]any, error) {
+			if atomic.AddUint32(&loadsCnt, 1) > 1 {
+				return nil, expectedErr
+			}
+
+			return map[string]any{"foo": "bar"}, nil
+		})
+		reloadErrs = make(chan error, 10)
+	)
+
+	// act
+	subject, err := xconf.Development(
+		loader,
+		xconf.DefaultConfigWithReloadInterval(10*time.Millisecond), // overwrite preset
+		xconf.DefaultConfigWithReloadErrorHandler(func(err error) {
+			reloadErrs <- err
+		}),
+	)
+
+	// assert
+	requireNil(t, err)
+	defer subject.Close()
+	assertEqual(t, "bar", subject.Get("Foo"))
+	select {
+	case reloadErr := <-reloadErrs:
+		assertTrue(t, errors.Is(reloadErr, expectedErr))
+	case <-time.After(time.Second):
+		t.Error("expected custom reload error handler to be called")
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/actforgood/xerr"
+)
+
+// ErrNotReady is returned by WaitReady if required keys are still missing when its context is done.
+var ErrNotReady = errors.New("configuration is not ready")
+
+const (
+	// waitReadyMinBackoff is the initial interval between WaitReady's loads.
+	waitReadyMinBackoff = 50 * time.Millisecond
+	// waitReadyMaxBackoff is the maximum interval between WaitReady's loads.
+	waitReadyMaxBackoff = 5 * time.Second
+)
+
+// WaitReady blocks until all required keys are present in the configuration,
+// re-loading it with exponential backoff (starting at 50ms, capped at 5s) meanwhile.
+// It is intended for services whose remote configuration may lag behind their startup.
+// If context is done before, an error wrapping [ErrNotReady] and context's error
+// (and the last load error, if any) is returned, mentioning the missing keys.
+// Observers and watchers are notified about the keys which appear, as on a regular reload.
+//
+// Note: if reload is disabled (see [DefaultConfigWithReloadInterval]), configuration is read
+// without synchronization, so WaitReady should be called before the config is used by other goroutines.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//	if err := cfg.WaitReady(ctx, "db.dsn", "feature.flags"); err != nil {
+//		log.Fatal(err)
+//	}
+func (cfg *defaultConfig) WaitReady(ctx context.Context, requiredKeys ...string) error {
+	var (
+		backoff = waitReadyMinBackoff
+		loadErr error
+	)
+	for {
+		missingKeys := cfg.missingKeys(requiredKeys)
+		if len(missingKeys) == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			mErr := xerr.NewMultiError().Add(
+				xerr.Wrapf(ErrNotReady, "missing keys %s", strings.Join(missingKeys, ", ")),
+				ctx.Err(),
+			)
+			if loadErr != nil {
+				mErr.Add(loadErr)
+			}
+
+			return mErr
+		case <-timer.C:
+		}
+
+		loadErr = cfg.setConfigMap()
+		backoff = min(2*backoff, waitReadyMaxBackoff)
+	}
+}
+
+// missingKeys returns the keys which are not present in the configuration.
+func (cfg *defaultConfig) missingKeys(keys []string) []string {
+	var missingKeys []string
+	cfg.mu.RLock()
+	for _, key := range keys {
+		lookupKey := key
+		if cfg.ignoreCaseSensitivity {
+			lookupKey = strings.ToUpper(key)
+		}
+		if _, found := cfg.configMap[lookupKey]; !found {
+			missingKeys = append(missingKeys, key)
+		}
+	}
+	cfg.mu.RUnlock()
+
+	return missingKeys
+}
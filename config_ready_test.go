@@ -0,0 +1,114 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestDefaultConfig_WaitReady(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - keys are already present", testDefaultConfigWaitReadyKeysPresent)
+	t.Run("success - keys appear later", testDefaultConfigWaitReadyKeysAppearLater)
+	t.Run("error - context done", testDefaultConfigWaitReadyReturnsErrNotReady)
+}
+
+func testDefaultConfigWaitReadyKeysPresent(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loader = &countingLoader{Loader: xconf.PlainLoader(map[string]any{
+			"db.dsn":        "user:pass@/db",
+			"feature.flags": "a,b",
+		})}
+		subject, err = xconf.NewDefaultConfig(loader, xconf.DefaultConfigWithIgnoreCaseSensitivity())
+	)
+	requireNil(t, err)
+
+	// act
+	err = subject.WaitReady(context.Background(), "db.dsn", "FEATURE.FLAGS")
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 1, loader.LoadsCount())
+}
+
+func testDefaultConfigWaitReadyKeysAppearLater(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loadsCnt uint32
+		loader   = xconf.LoaderFunc(func() (map[string]any, error) {
+			switch atomic.AddUint32(&loadsCnt, 1) {
+			case 1:
+				return map[string]any{}, nil
+			case 2:
+				return nil, errors.New("intentionally triggered load error")
+			case 3:
+				return map[string]any{"db.dsn": "user:pass@/db"}, nil
+			default:
+				return map[string]any{"db.dsn": "user:pass@/db", "feature.flags": "a,b"}, nil
+			}
+		})
+		observedKeys []string
+		subject, err = xconf.NewDefaultConfig(loader, xconf.DefaultConfigWithReloadInterval(time.Hour))
+	)
+	requireNil(t, err)
+	defer subject.Close()
+	subject.RegisterObserver(func(_ xconf.Config, changedKeys ...string) {
+		observedKeys = append(observedKeys, changedKeys...)
+	})
+
+	// act
+	err = subject.WaitReady(context.Background(), "db.dsn", "feature.flags")
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, uint32(4), atomic.LoadUint32(&loadsCnt))
+	assertEqual(t, "a,b", subject.Get("feature.flags"))
+	assertEqual(t, []string{"db.dsn", "feature.flags"}, observedKeys)
+}
+
+func testDefaultConfigWaitReadyReturnsErrNotReady(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered load error")
+		loadsCnt    uint32
+		loader      = xconf.LoaderFunc(func() (map[string]any, error) {
+			if atomic.AddUint32(&loadsCnt, 1) == 1 {
+				return map[string]any{"db.dsn": "user:pass@/db"}, nil
+			}
+
+			return nil, expectedErr
+		})
+		subject, err = xconf.NewDefaultConfig(loader)
+		ctx, cancel  = context.WithTimeout(context.Background(), 120*time.Millisecond)
+	)
+	requireNil(t, err)
+	defer cancel()
+
+	// act
+	err = subject.WaitReady(ctx, "db.dsn", "feature.flags", "app.name")
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrNotReady))
+	assertTrue(t, errors.Is(err, context.DeadlineExceeded))
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertTrue(t, strings.Contains(err.Error(), "missing keys feature.flags, app.name"))
+	assertTrue(t, atomic.LoadUint32(&loadsCnt) > 1)
+}
@@ -0,0 +1,84 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrReloadDisabled is returned by operations which alter the configuration at runtime,
+// if reload is disabled (see [DefaultConfigWithReloadInterval]), as in that case,
+// configuration is read without synchronization.
+var ErrReloadDisabled = errors.New("configuration reload is disabled")
+
+// ReloadPrefix refreshes only the keys having given prefix, merging them into the current configuration
+// (keys having the prefix which are not found anymore get deleted).
+// It is useful when a single large subtree changes frequently, but full reloads are expensive.
+// If the config's loader is a [PrefixLoader] (like [MultiLoader]), only the sources responsible for
+// the prefix are re-queried, otherwise, a full load is performed, and only the prefix keys are taken.
+// Observers and watchers are notified about the changed keys, as on a regular reload.
+// Validation rules, if any (see [DefaultConfigWithValidation]), are checked against the merged configuration.
+// On error, the current configuration remains active.
+// Reload must be enabled, otherwise [ErrReloadDisabled] is returned.
+//
+// Example:
+//
+//	// refresh feature flags more often than the whole configuration.
+//	err := cfg.ReloadPrefix(ctx, "feature.")
+func (cfg *defaultConfig) ReloadPrefix(ctx context.Context, prefix string) error {
+	if cfg.reloadInterval <= 0 {
+		return ErrReloadDisabled
+	}
+
+	var (
+		partialConfigMap map[string]any
+		err              error
+	)
+	if prefixLoader, ok := cfg.loader.(PrefixLoader); ok {
+		partialConfigMap, err = prefixLoader.LoadPrefix(ctx, prefix)
+	} else {
+		partialConfigMap, err = LoadWithContext(ctx, cfg.loader)
+	}
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	hasPrefix := strings.HasPrefix
+	if cfg.ignoreCaseSensitivity {
+		hasPrefix = hasPrefixFold
+	}
+
+	cfg.mu.Lock()
+	newLoadedConfigMap := make(map[string]any, len(cfg.loadedConfigMap))
+	for key, value := range cfg.loadedConfigMap {
+		if !hasPrefix(key, prefix) {
+			newLoadedConfigMap[key] = value
+		}
+	}
+	for key, value := range partialConfigMap {
+		if hasPrefix(key, prefix) {
+			newLoadedConfigMap[key] = value
+		}
+	}
+	newConfigMap, err := cfg.effectiveConfigMap(newLoadedConfigMap, cfg.overrides)
+	if err != nil {
+		cfg.mu.Unlock()
+
+		return err
+	}
+	oldConfigMap := cfg.configMap
+	cfg.loadedConfigMap = newLoadedConfigMap
+	cfg.configMap = newConfigMap
+	cfg.mu.Unlock()
+
+	cfg.afterConfigMapChange(oldConfigMap, newConfigMap)
+
+	return nil
+}
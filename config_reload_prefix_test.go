@@ -0,0 +1,176 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestDefaultConfig_ReloadPrefix(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - prefix keys are merged", testDefaultConfigReloadPrefixMergesKeys)
+	t.Run("success - not a prefix loader", testDefaultConfigReloadPrefixWithFullLoad)
+	t.Run("error - reload disabled", testDefaultConfigReloadPrefixReturnsErrReloadDisabled)
+	t.Run("error - loader error keeps config", testDefaultConfigReloadPrefixReturnsErrFromLoader)
+	t.Run("error - validation keeps config", testDefaultConfigReloadPrefixReturnsValidationErr)
+}
+
+func testDefaultConfigReloadPrefixMergesKeys(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loadsCnt       uint32
+		featuresLoader = &countingLoader{Loader: xconf.LoaderFunc(func() (map[string]any, error) {
+			if atomic.AddUint32(&loadsCnt, 1) == 1 {
+				return map[string]any{"feature.a": true, "feature.b": true}, nil
+			}
+
+			return map[string]any{"feature.a": false, "feature.c": true}, nil
+		})}
+		dbLoader     = &countingLoader{Loader: xconf.PlainLoader(map[string]any{"db.host": "10.0.0.1"})}
+		observedKeys []string
+		subject, err = xconf.NewDefaultConfig(
+			xconf.NewMultiLoader(true, featuresLoader, dbLoader),
+			xconf.DefaultConfigWithReloadInterval(time.Hour),
+			xconf.DefaultConfigWithIgnoreCaseSensitivity(),
+		)
+	)
+	requireNil(t, err)
+	defer subject.Close()
+	subject.RegisterObserver(func(_ xconf.Config, changedKeys ...string) {
+		observedKeys = append(observedKeys, changedKeys...)
+	})
+
+	// act
+	err = subject.ReloadPrefix(context.Background(), "feature.")
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, false, subject.Get("feature.a"))
+	assertNil(t, subject.Get("feature.b"))
+	assertEqual(t, true, subject.Get("feature.c"))
+	assertEqual(t, "10.0.0.1", subject.Get("db.host"))
+	sort.Strings(observedKeys)
+	assertEqual(t, []string{"FEATURE.A", "FEATURE.B", "FEATURE.C"}, observedKeys)
+
+	// act - only responsible loader is queried.
+	err = subject.ReloadPrefix(context.Background(), "feature.")
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 3, featuresLoader.LoadsCount())
+	assertEqual(t, 2, dbLoader.LoadsCount())
+}
+
+func testDefaultConfigReloadPrefixWithFullLoad(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loadsCnt uint32
+		loader   = xconf.LoaderFunc(func() (map[string]any, error) {
+			if atomic.AddUint32(&loadsCnt, 1) == 1 {
+				return map[string]any{"feature.a": true, "db.host": "10.0.0.1"}, nil
+			}
+
+			return map[string]any{"feature.a": false, "db.host": "10.0.0.2"}, nil
+		})
+		subject, err = xconf.NewDefaultConfig(loader, xconf.DefaultConfigWithReloadInterval(time.Hour))
+	)
+	requireNil(t, err)
+	defer subject.Close()
+
+	// act
+	err = subject.ReloadPrefix(context.Background(), "feature.")
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, false, subject.Get("feature.a"))
+	assertEqual(t, "10.0.0.1", subject.Get("db.host")) // not refreshed
+}
+
+func testDefaultConfigReloadPrefixReturnsErrReloadDisabled(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(xconf.PlainLoader(map[string]any{"feature.a": true}))
+	requireNil(t, err)
+
+	// act
+	err = subject.ReloadPrefix(context.Background(), "feature.")
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrReloadDisabled))
+}
+
+func testDefaultConfigReloadPrefixReturnsErrFromLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loadsCnt    uint32
+		expectedErr = errors.New("intentionally triggered load error")
+		loader      = xconf.LoaderFunc(func() (map[string]any, error) {
+			if atomic.AddUint32(&loadsCnt, 1) == 1 {
+				return map[string]any{"feature.a": true}, nil
+			}
+
+			return nil, expectedErr
+		})
+		subject, err = xconf.NewDefaultConfig(
+			xconf.NewMultiLoader(true, loader),
+			xconf.DefaultConfigWithReloadInterval(time.Hour),
+		)
+	)
+	requireNil(t, err)
+	defer subject.Close()
+
+	// act
+	err = subject.ReloadPrefix(context.Background(), "feature.")
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertEqual(t, true, subject.Get("feature.a"))
+}
+
+func testDefaultConfigReloadPrefixReturnsValidationErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loadsCnt uint32
+		loader   = xconf.LoaderFunc(func() (map[string]any, error) {
+			if atomic.AddUint32(&loadsCnt, 1) == 1 {
+				return map[string]any{"feature.limit": 10}, nil
+			}
+
+			return map[string]any{"feature.limit": 1000}, nil
+		})
+		subject, err = xconf.NewDefaultConfig(
+			loader,
+			xconf.DefaultConfigWithReloadInterval(time.Hour),
+			xconf.DefaultConfigWithValidation(xconf.KeyRange("feature.limit", 0, 100)),
+		)
+	)
+	requireNil(t, err)
+	defer subject.Close()
+
+	// act
+	err = subject.ReloadPrefix(context.Background(), "feature.")
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrValidation))
+	assertEqual(t, 10, subject.Get("feature.limit"))
+}
@@ -0,0 +1,70 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/actforgood/xerr"
+)
+
+// DefaultConfigWithReloadTimeout bounds how long a single (re)load of the configuration may take.
+// On timeout, the load is abandoned, the previous configuration remains active,
+// and the reload error handler (see [DefaultConfigWithReloadErrorHandler]) gets
+// a [context.DeadlineExceeded] based error (NewDefaultConfig returns it, for the initial load).
+// The loader is given a context with the timeout (see [ContextLoader]); a loader which
+// does not honor it keeps running in background, its result being discarded.
+// Passing a value <= 0 disables the timeout.
+//
+// By default, there is no timeout.
+//
+// Usage example:
+//
+//	cfg, err := xconf.NewDefaultConfig(
+//		consulLoader,
+//		xconf.DefaultConfigWithReloadInterval(time.Minute),
+//		xconf.DefaultConfigWithReloadTimeout(10*time.Second),
+//	)
+func DefaultConfigWithReloadTimeout(timeout time.Duration) DefaultConfigOption {
+	return func(config *DefaultConfig) {
+		config.reloadTimeout = timeout
+	}
+}
+
+// load loads the configuration map under a context canceled on Close,
+// bounded by the reload timeout, if set.
+func (cfg *defaultConfig) load() (map[string]any, error) {
+	if cfg.reloadTimeout <= 0 {
+		ctx, cancel := context.WithCancel(cfg.loadCtx)
+		defer cancel()
+
+		return LoadWithContext(ctx, cfg.loader)
+	}
+
+	ctx, cancel := context.WithTimeout(cfg.loadCtx, cfg.reloadTimeout)
+	defer cancel()
+
+	resultCh := make(chan loadResult, 1) // buffered, so that an abandoned load does not block forever.
+	go func() {
+		configMap, err := LoadWithContext(ctx, cfg.loader)
+		resultCh <- loadResult{configMap: configMap, err: err}
+	}()
+
+	select {
+	case result := <-resultCh:
+		if result.err == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return result.configMap, result.err
+		}
+	case <-ctx.Done():
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, ctx.Err()
+		}
+	}
+
+	return nil, xerr.Wrapf(context.DeadlineExceeded, "configuration load exceeded reload timeout of %s", cfg.reloadTimeout)
+}
@@ -0,0 +1,101 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestDefaultConfigWithReloadTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - load within timeout", testDefaultConfigWithReloadTimeoutLoadsInTime)
+	t.Run("error - hung reload is abandoned, previous config stays", testDefaultConfigWithReloadTimeoutAbandonsHungReload)
+	t.Run("error - initial load not honoring context", testDefaultConfigWithReloadTimeoutInitialLoad)
+}
+
+func testDefaultConfigWithReloadTimeoutLoadsInTime(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	loader := xconf.ContextLoaderFunc(func(ctx context.Context) (map[string]any, error) {
+		_, hasDeadline := ctx.Deadline()
+
+		return map[string]any{"has_deadline": hasDeadline}, nil
+	})
+
+	// act
+	subject, err := xconf.NewDefaultConfig(loader, xconf.DefaultConfigWithReloadTimeout(time.Minute))
+
+	// assert
+	requireNil(t, err)
+	assertEqual(t, true, subject.Get("has_deadline"))
+}
+
+func testDefaultConfigWithReloadTimeoutAbandonsHungReload(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loadsCnt  int32
+		reloadErr = make(chan error, 10)
+		loader    = xconf.ContextLoaderFunc(func(ctx context.Context) (map[string]any, error) {
+			if atomic.AddInt32(&loadsCnt, 1) == 1 {
+				return map[string]any{"foo": "bar"}, nil
+			}
+			<-ctx.Done() // simulate a hung remote call.
+
+			return map[string]any{"foo": "baz"}, nil // late result, should be discarded.
+		})
+		subject, err = xconf.NewDefaultConfig(
+			loader,
+			xconf.DefaultConfigWithReloadInterval(10*time.Millisecond),
+			xconf.DefaultConfigWithReloadTimeout(20*time.Millisecond),
+			xconf.DefaultConfigWithReloadErrorHandler(func(err error) {
+				reloadErr <- err
+			}),
+		)
+	)
+	requireNil(t, err)
+	defer subject.Close()
+
+	// act & assert
+	select {
+	case err := <-reloadErr:
+		assertTrue(t, errors.Is(err, context.DeadlineExceeded))
+	case <-time.After(time.Second):
+		t.Fatal("expected a reload error")
+	}
+	assertEqual(t, "bar", subject.Get("foo"))
+}
+
+func testDefaultConfigWithReloadTimeoutInitialLoad(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		unblock = make(chan struct{})
+		loader  = xconf.LoaderFunc(func() (map[string]any, error) {
+			<-unblock // simulate a hung call of a loader not aware of context.
+
+			return map[string]any{"foo": "bar"}, nil
+		})
+	)
+	defer close(unblock)
+
+	// act
+	subject, err := xconf.NewDefaultConfig(loader, xconf.DefaultConfigWithReloadTimeout(20*time.Millisecond))
+
+	// assert
+	assertTrue(t, errors.Is(err, context.DeadlineExceeded))
+	assertNil(t, subject)
+}
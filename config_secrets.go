@@ -0,0 +1,181 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"errors"
+	"strings"
+)
+
+// MaskSecrets returns a copy of given configuration map, with the values of keys matching
+// (case-insensitive) any of the patterns replaced with "*****".
+// Patterns follow [path.Match] syntax, like "*password*"; nested maps' keys are matched
+// also by their path (nested keys joined with "."), like "db.password".
+// If no pattern is provided, [DefaultRedactionPatterns] are used.
+//
+// Example:
+//
+//	configMap, err := loader.Load()
+//	if err != nil {
+//		return err
+//	}
+//	log.Printf("loaded configuration: %v", xconf.MaskSecrets(configMap, "*password*", "*token*"))
+func MaskSecrets(configMap map[string]any, patterns ...string) map[string]any {
+	if len(patterns) == 0 {
+		patterns = DefaultRedactionPatterns
+	}
+	maskedConfigMap := DeepCopyConfigMap(configMap)
+	redactConfigMap(maskedConfigMap, patterns)
+
+	return maskedConfigMap
+}
+
+// DefaultConfigWithSecretKeys marks the keys matching (case-insensitive) any of given globs
+// as secrets (globs follow [path.Match] syntax, like "*password*", "db.*"; nested keys are
+// matched also by their path, like "db.password").
+// Secret keys' values are replaced with "*****" in:
+//   - [ChangeObserver]s' changes and [DefaultConfig.Watch]'s events,
+//   - [Dump], [DumpOnSignal], [LogEffectiveConfig] output (even if no redaction is requested),
+//   - [CastFailure]s (and [DefaultConfig.GetE]'s errors),
+//   - configuration validation errors (see [DefaultConfigWithValidation]).
+//
+// Get still returns the actual values.
+//
+// Usage example:
+//
+//	cfg, err := xconf.NewDefaultConfig(
+//		loader,
+//		xconf.DefaultConfigWithSecretKeys("*password*", "*token*", "tls.key"),
+//	)
+func DefaultConfigWithSecretKeys(globs ...string) DefaultConfigOption {
+	return func(config *DefaultConfig) {
+		config.secretKeys = globs
+	}
+}
+
+// secretsMasker is implemented by configs which can mask their secret keys' values.
+type secretsMasker interface {
+	// maskSecrets masks (in place) the values of the secret keys of given configuration map.
+	maskSecrets(configMap map[string]any)
+}
+
+// maskSecrets masks (in place) the values of the secret keys of given configuration map.
+func (cfg *defaultConfig) maskSecrets(configMap map[string]any) {
+	if len(cfg.secretKeys) > 0 {
+		redactConfigMap(configMap, cfg.secretKeys)
+	}
+}
+
+// maskSecretValue returns the masked value of given key, if key is a secret one,
+// or a copy of the value with its nested secret keys masked, if value is a map.
+func (cfg *defaultConfig) maskSecretValue(key string, value any) any {
+	if len(cfg.secretKeys) == 0 || value == nil {
+		return value
+	}
+	if isRedactedKey(key, cfg.secretKeys) {
+		return redactedValue
+	}
+	switch value.(type) {
+	case map[string]any, map[any]any:
+		wrapper := DeepCopyConfigMap(map[string]any{key: value})
+		redactConfigMap(wrapper, cfg.secretKeys)
+
+		return wrapper[key]
+	}
+
+	return value
+}
+
+// maskChanges returns the changes with the secret keys' values masked.
+func (cfg *defaultConfig) maskChanges(changes Changes) Changes {
+	if len(cfg.secretKeys) == 0 {
+		return changes
+	}
+	maskedChanges := make(Changes, len(changes))
+	for idx, change := range changes {
+		change.OldValue = cfg.maskSecretValue(change.Key, change.OldValue)
+		change.NewValue = cfg.maskSecretValue(change.Key, change.NewValue)
+		maskedChanges[idx] = change
+	}
+
+	return maskedChanges
+}
+
+// maskErrorSecrets returns an error whose message has the (string) values
+// of the secret keys of given configuration maps masked.
+func (cfg *defaultConfig) maskErrorSecrets(err error, configMaps ...map[string]any) error {
+	if err == nil || len(cfg.secretKeys) == 0 {
+		return err
+	}
+
+	var secretValues []string
+	for _, configMap := range configMaps {
+		secretValues = collectSecretValues(configMap, "", cfg.secretKeys, secretValues)
+	}
+
+	return maskError(err, secretValues)
+}
+
+// maskError returns an error whose message has given secret values masked,
+// or given error, if its message does not contain any of them.
+func maskError(err error, secretValues []string) error {
+	msg := err.Error()
+	maskedMsg := msg
+	for _, secretValue := range secretValues {
+		maskedMsg = strings.ReplaceAll(maskedMsg, secretValue, redactedValue)
+	}
+	if maskedMsg == msg {
+		return err
+	}
+
+	return maskedError{msg: maskedMsg, err: err}
+}
+
+// collectSecretValues appends the non empty string values of the keys matching any of the patterns.
+func collectSecretValues(configMap map[string]any, prefix string, patterns []string, secretValues []string) []string {
+	for key, value := range configMap {
+		isSecret := isRedactedKey(key, patterns) || (prefix != "" && isRedactedKey(prefix+key, patterns))
+		switch val := value.(type) {
+		case string:
+			if isSecret && val != "" {
+				secretValues = append(secretValues, val)
+			}
+		case map[string]any:
+			if isSecret {
+				secretValues = collectSecretValues(val, prefix+key+".", []string{"*"}, secretValues)
+			} else {
+				secretValues = collectSecretValues(val, prefix+key+".", patterns, secretValues)
+			}
+		}
+	}
+
+	return secretValues
+}
+
+// maskedError is an error whose message has secret values masked.
+// The original error is not exposed (through Unwrap), as its message holds the secret values,
+// but it can be checked with [errors.Is].
+type maskedError struct {
+	msg string
+	err error
+}
+
+// Error returns the masked error message.
+func (maskedErr maskedError) Error() string {
+	return maskedErr.msg
+}
+
+// Is reports whether the original error matches target.
+func (maskedErr maskedError) Is(target error) bool {
+	return errors.Is(maskedErr.err, target)
+}
+
+// maskSecrets masks (in place) the values of the secret keys of given sub-configuration map.
+func (sub SubConfig) maskSecrets(configMap map[string]any) {
+	if len(sub.parent.secretKeys) > 0 {
+		redactNestedConfigMap(configMap, sub.prefix+sub.delimiter, sub.parent.secretKeys)
+	}
+}
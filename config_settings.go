@@ -0,0 +1,44 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+// AllKeys returns the (first level) keys of the effective configuration, sorted.
+// Nested maps' keys are not included (see [NewFlattenLoader] for having them as first level keys).
+func (cfg *defaultConfig) AllKeys() []string {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+
+	return sortedKeys(cfg.configMap)
+}
+
+// AllSettings returns a deep copy of the effective key-value configuration map
+// (loaded one, with overrides applied), so that callers can iterate it, feed it to validators,
+// or diff two snapshots, without re-running the loader themselves.
+// Secrets are not masked (see [DumpWithRedaction] for a masked dump).
+func (cfg *defaultConfig) AllSettings() map[string]any {
+	return cfg.configMapSnapshot()
+}
+
+// AllKeys returns the sub-config's keys, sorted. See [DefaultConfig.AllKeys].
+func (sub SubConfig) AllKeys() []string {
+	return sortedKeys(sub.configMapSnapshot())
+}
+
+// AllSettings returns a deep copy of the sub-config's key-value configuration map.
+// See [DefaultConfig.AllSettings].
+func (sub SubConfig) AllSettings() map[string]any {
+	return sub.configMapSnapshot()
+}
+
+// AllKeys returns the mocked configuration's keys, sorted.
+func (mock *MockConfig) AllKeys() []string {
+	return sortedKeys(mock.configMapSnapshot())
+}
+
+// AllSettings returns a deep copy of the mocked configuration map.
+func (mock *MockConfig) AllSettings() map[string]any {
+	return mock.configMapSnapshot()
+}
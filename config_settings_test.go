@@ -0,0 +1,89 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestDefaultConfig_AllSettings(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{
+			"app":       "demo",
+			"db":        map[string]any{"host": "localhost", "ports": []any{5432, 5433}},
+			"log_level": "info",
+		}),
+		xconf.DefaultConfigWithReloadInterval(time.Hour),
+	)
+	requireNil(t, err)
+	defer subject.Close()
+	requireNil(t, subject.Set("log_level", "debug"))
+
+	// act
+	keys := subject.AllKeys()
+	settings := subject.AllSettings()
+	settings["db"].(map[string]any)["host"] = "altered"
+	settings["db"].(map[string]any)["ports"].([]any)[0] = 1
+	otherSettings := subject.AllSettings()
+
+	// assert
+	assertEqual(t, []string{"app", "db", "log_level"}, keys)
+	assertEqual(
+		t,
+		map[string]any{
+			"app":       "demo",
+			"db":        map[string]any{"host": "localhost", "ports": []any{5432, 5433}},
+			"log_level": "debug",
+		},
+		otherSettings,
+	)
+}
+
+func TestSubConfig_AllSettings(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	cfg, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{
+			"db":      map[string]any{"host": "localhost"},
+			"db.port": 5432,
+			"app":     "demo",
+		}),
+		xconf.DefaultConfigWithReloadInterval(0),
+	)
+	requireNil(t, err)
+	defer cfg.Close()
+	subject := cfg.Sub("db")
+
+	// act
+	keys := subject.AllKeys()
+	settings := subject.AllSettings()
+
+	// assert
+	assertEqual(t, []string{"host", "port"}, keys)
+	assertEqual(t, map[string]any{"host": "localhost", "port": 5432}, settings)
+}
+
+func TestMockConfig_AllSettings(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.NewMockConfig("foo", "bar", "year", 2022)
+
+	// act
+	keys := subject.AllKeys()
+	settings := subject.AllSettings()
+
+	// assert
+	assertEqual(t, []string{"foo", "year"}, keys)
+	assertEqual(t, map[string]any{"foo": "bar", "year": 2022}, settings)
+}
@@ -0,0 +1,193 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/actforgood/xerr"
+)
+
+// ErrUnintendedChange is returned by [SimulationReport.CheckOnly] if a configuration
+// change affects other components than the intended ones.
+var ErrUnintendedChange = errors.New("configuration change affects unintended components")
+
+// SimulatedComponent describes a component (an observer) taking part in a [SimulateChange].
+type SimulatedComponent struct {
+	// Name identifies the component in the report.
+	Name string
+	// Keys are the keys / keys' prefixes the component reacts to.
+	// The component fires if any changed key has one of these prefixes.
+	// If empty, the component fires on any change.
+	Keys []string
+	// Observer is the component's real observer, optional.
+	// If set, it gets registered and notified as in production (with all changed keys),
+	// so its reaction can be asserted too.
+	Observer ConfigObserver
+}
+
+// SimulationReport is the result of a [SimulateChange].
+type SimulationReport struct {
+	// Changes are the changes (added / updated / deleted keys) the transition produced.
+	Changes Changes
+	// Fired holds, for each component that fired, the changed keys it reacts to, sorted.
+	Fired map[string][]string
+}
+
+// FiredComponents returns the names of the components that fired, sorted.
+func (report SimulationReport) FiredComponents() []string {
+	names := make([]string, 0, len(report.Fired))
+	for name := range report.Fired {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// CheckOnly returns an [ErrUnintendedChange] based error if other components
+// than given ones fired. It is meant to be used in CI pipelines, to assert that
+// a proposed configuration change only affects intended components.
+func (report SimulationReport) CheckOnly(componentNames ...string) error {
+	var unintended []string
+	for _, name := range report.FiredComponents() {
+		intended := false
+		for _, componentName := range componentNames {
+			if name == componentName {
+				intended = true
+
+				break
+			}
+		}
+		if !intended {
+			unintended = append(unintended, name+" ("+strings.Join(report.Fired[name], ", ")+")")
+		}
+	}
+	if len(unintended) > 0 {
+		return xerr.Wrapf(ErrUnintendedChange, "%s", strings.Join(unintended, "; "))
+	}
+
+	return nil
+}
+
+// SimulateChange simulates a configuration change, without any effect on your running applications.
+// It spins up a [DefaultConfig] with a replay loader, which serves old loader's configuration first,
+// and new loader's configuration afterwards, applies the transition, and reports the changed keys
+// and which components (observers) fire.
+// Given options are applied to the config (reload is disabled, the transition is triggered explicitly),
+// reported changes of the secret keys set with [DefaultConfigWithSecretKeys] are masked.
+// Given loaders are not closed, they are owned by the caller.
+//
+// Example (in a CI pipeline, old and new config file sets come from the base and the proposed revisions):
+//
+//	report, err := xconf.SimulateChange(
+//		xconf.NewMultiLoader(true, xconf.YAMLFileLoader("base/app.yaml"), xconf.DotEnvFileLoader("base/.env")),
+//		xconf.NewMultiLoader(true, xconf.YAMLFileLoader("proposed/app.yaml"), xconf.DotEnvFileLoader("proposed/.env")),
+//		[]xconf.SimulatedComponent{
+//			{Name: "db", Keys: []string{"db."}},
+//			{Name: "cache", Keys: []string{"redis."}},
+//			{Name: "http", Keys: []string{"http."}},
+//		},
+//	)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	if err := report.CheckOnly("cache"); err != nil {
+//		t.Fatal(err, "\n", report.Changes)
+//	}
+func SimulateChange(
+	oldLoader, newLoader Loader,
+	components []SimulatedComponent,
+	opts ...DefaultConfigOption,
+) (SimulationReport, error) {
+	opts = append(opts[:len(opts):len(opts)], DefaultConfigWithReloadInterval(0))
+	config, err := NewDefaultConfig(&replayLoader{loaders: []Loader{oldLoader, newLoader}}, opts...)
+	if err != nil {
+		return SimulationReport{}, err
+	}
+	defer config.Close()
+
+	var (
+		mu            sync.Mutex
+		transitioning int32
+		report        = SimulationReport{Fired: make(map[string][]string)}
+	)
+	for _, component := range components {
+		component := component
+		config.RegisterObserver(func(cfg Config, changedKeys ...string) {
+			if atomic.LoadInt32(&transitioning) == 0 {
+				return // initial load notification, if enabled.
+			}
+			if keys := component.matchKeys(changedKeys, config.ignoreCaseSensitivity); len(keys) > 0 {
+				mu.Lock()
+				report.Fired[component.Name] = keys
+				mu.Unlock()
+			}
+			if component.Observer != nil {
+				component.Observer(cfg, changedKeys...)
+			}
+		})
+	}
+
+	oldConfigMap := config.configMapSnapshot()
+	atomic.StoreInt32(&transitioning, 1)
+	if err := config.setConfigMap(); err != nil {
+		return SimulationReport{}, err
+	}
+	report.Changes = config.maskChanges(Diff(oldConfigMap, config.configMapSnapshot()))
+
+	return report, nil
+}
+
+// matchKeys returns the changed keys the component reacts to, sorted.
+func (component SimulatedComponent) matchKeys(changedKeys []string, ignoreCase bool) []string {
+	keys := make([]string, 0, len(changedKeys))
+	for _, changedKey := range changedKeys {
+		if len(component.Keys) == 0 {
+			keys = append(keys, changedKey)
+
+			continue
+		}
+		for _, prefix := range component.Keys {
+			if (ignoreCase && hasPrefixFold(changedKey, prefix)) || strings.HasPrefix(changedKey, prefix) {
+				keys = append(keys, changedKey)
+
+				break
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// replayLoader serves its loaders' configurations, one per load, in order.
+// After the last loader is reached, it keeps serving it.
+// It intentionally does not expose / close the replayed loaders, they are owned by the caller.
+type replayLoader struct {
+	loaders []Loader
+	idx     int32
+}
+
+// Load returns the configuration of the current loader, and advances to the next one.
+func (loader *replayLoader) Load() (map[string]any, error) {
+	return loader.LoadContext(context.Background())
+}
+
+// LoadContext is like Load, passing given context to the current loader.
+func (loader *replayLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	idx := int(atomic.AddInt32(&loader.idx, 1)) - 1
+	if idx >= len(loader.loaders) {
+		idx = len(loader.loaders) - 1
+	}
+
+	return LoadWithContext(ctx, loader.loaders[idx])
+}
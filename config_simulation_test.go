@@ -0,0 +1,203 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestSimulateChange(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - fired components are reported", testSimulateChangeReportsFiredComponents)
+	t.Run("success - case insensitive keys", testSimulateChangeCaseInsensitive)
+	t.Run("success - secrets are masked", testSimulateChangeMasksSecrets)
+	t.Run("error - unintended components fired", testSimulateChangeCheckOnlyReturnsErr)
+	t.Run("error - old loader", testSimulateChangeReturnsErrFromOldLoader)
+	t.Run("error - new loader", testSimulateChangeReturnsErrFromNewLoader)
+}
+
+func testSimulateChangeReportsFiredComponents(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		oldLoader = xconf.PlainLoader(map[string]any{
+			"db.host":     "10.0.0.1",
+			"redis.addr":  "10.0.0.5:6379",
+			"redis.db":    0,
+			"http.port":   8080,
+			"app.version": "1.0",
+		})
+		newLoader = xconf.PlainLoader(map[string]any{
+			"db.host":     "10.0.0.1",
+			"redis.addr":  "10.0.0.6:6379",
+			"http.port":   8080,
+			"app.version": "1.0",
+		})
+		cacheObserverCalls [][]string
+		components         = []xconf.SimulatedComponent{
+			{Name: "db", Keys: []string{"db."}},
+			{
+				Name: "cache",
+				Keys: []string{"redis."},
+				Observer: func(cfg xconf.Config, changedKeys ...string) {
+					cacheObserverCalls = append(cacheObserverCalls, changedKeys)
+					assertEqual(t, "10.0.0.6:6379", cfg.Get("redis.addr"))
+				},
+			},
+			{Name: "http", Keys: []string{"http.", "app.version"}},
+			{Name: "audit"}, // fires on any change
+		}
+	)
+
+	// act
+	report, err := xconf.SimulateChange(
+		oldLoader,
+		newLoader,
+		components,
+		xconf.DefaultConfigWithNotifyInitialLoad(), // initial load notification is not reported.
+	)
+
+	// assert
+	requireNil(t, err)
+	assertEqual(
+		t,
+		xconf.Changes{
+			{Key: "redis.addr", Op: xconf.KeyUpdated, OldValue: "10.0.0.5:6379", NewValue: "10.0.0.6:6379"},
+			{Key: "redis.db", Op: xconf.KeyDeleted, OldValue: 0},
+		},
+		report.Changes,
+	)
+	assertEqual(
+		t,
+		map[string][]string{
+			"cache": {"redis.addr", "redis.db"},
+			"audit": {"redis.addr", "redis.db"},
+		},
+		report.Fired,
+	)
+	assertEqual(t, []string{"audit", "cache"}, report.FiredComponents())
+	assertEqual(t, 1, len(cacheObserverCalls))
+	assertNil(t, report.CheckOnly("cache", "audit"))
+}
+
+func testSimulateChangeCaseInsensitive(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		oldLoader  = xconf.PlainLoader(map[string]any{"db_host": "10.0.0.1"})
+		newLoader  = xconf.PlainLoader(map[string]any{"db_host": "10.0.0.2"})
+		components = []xconf.SimulatedComponent{{Name: "db", Keys: []string{"db_"}}}
+	)
+
+	// act
+	report, err := xconf.SimulateChange(
+		oldLoader,
+		newLoader,
+		components,
+		xconf.DefaultConfigWithIgnoreCaseSensitivity(),
+	)
+
+	// assert
+	requireNil(t, err)
+	assertEqual(t, map[string][]string{"db": {"DB_HOST"}}, report.Fired)
+}
+
+func testSimulateChangeMasksSecrets(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		oldLoader  = xconf.PlainLoader(map[string]any{"db.password": "s3cr3t"})
+		newLoader  = xconf.PlainLoader(map[string]any{"db.password": "n3w-s3cr3t"})
+		components = []xconf.SimulatedComponent{{Name: "db", Keys: []string{"db."}}}
+	)
+
+	// act
+	report, err := xconf.SimulateChange(
+		oldLoader,
+		newLoader,
+		components,
+		xconf.DefaultConfigWithSecretKeys("*password"),
+	)
+
+	// assert
+	requireNil(t, err)
+	assertEqual(
+		t,
+		xconf.Changes{{Key: "db.password", Op: xconf.KeyUpdated, OldValue: "*****", NewValue: "*****"}},
+		report.Changes,
+	)
+	assertEqual(t, []string{"db"}, report.FiredComponents())
+}
+
+func testSimulateChangeCheckOnlyReturnsErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		oldLoader  = xconf.PlainLoader(map[string]any{"db.host": "10.0.0.1", "http.port": 80})
+		newLoader  = xconf.PlainLoader(map[string]any{"db.host": "10.0.0.2", "http.port": 8080})
+		components = []xconf.SimulatedComponent{
+			{Name: "db", Keys: []string{"db."}},
+			{Name: "http", Keys: []string{"http."}},
+		}
+	)
+	report, err := xconf.SimulateChange(oldLoader, newLoader, components)
+	requireNil(t, err)
+
+	// act
+	err = report.CheckOnly("db")
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrUnintendedChange))
+	assertEqual(t, "http (http.port): configuration change affects unintended components", err.Error())
+}
+
+func testSimulateChangeReturnsErrFromOldLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered old loader error")
+		oldLoader   = xconf.LoaderFunc(func() (map[string]any, error) {
+			return nil, expectedErr
+		})
+		newLoader = xconf.PlainLoader(map[string]any{"foo": "bar"})
+	)
+
+	// act
+	report, err := xconf.SimulateChange(oldLoader, newLoader, nil)
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertNil(t, report.Changes)
+}
+
+func testSimulateChangeReturnsErrFromNewLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered new loader error")
+		oldLoader   = xconf.PlainLoader(map[string]any{"foo": "bar"})
+		newLoader   = xconf.LoaderFunc(func() (map[string]any, error) {
+			return nil, expectedErr
+		})
+	)
+
+	// act
+	report, err := xconf.SimulateChange(oldLoader, newLoader, nil)
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertNil(t, report.Changes)
+}
@@ -0,0 +1,183 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/actforgood/xerr"
+	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidSlice is an error returned by [GetSlice] if key's value
+// cannot be decoded into a slice of requested type.
+var ErrInvalidSlice = errors.New("value cannot be decoded into a slice")
+
+// sliceDefaultDelimiter is the delimiter of indexed flat keys,
+// if no key delimiter is set on the config.
+const sliceDefaultDelimiter = "."
+
+// GetSlice returns the list found under given key, decoded into a slice of T
+// (T is usually a struct, whose fields are matched by their yaml tags / lower-cased names).
+// The list can be:
+//   - a nested list, as loaded from a YAML / JSON file,
+//   - indexed flat keys, like "endpoints.0.url", "endpoints.0.timeout", "endpoints.1.url",
+//     as loaded from ENV / properties files, or produced by [FlattenLoader]
+//     (keys are delimited by the delimiter set with [DefaultConfigWithKeyDelimiter], or ".", by default),
+//   - a JSON / YAML encoded list string.
+//
+// A nil slice is returned if key is not found.
+// Indexed flat keys are supported for configs able to provide their whole configuration map,
+// like [DefaultConfig], [SubConfig], [MockConfig]. An index cannot exceed configuration's keys count.
+//
+// Example:
+//
+//	type Endpoint struct {
+//		URL     string        `yaml:"url"`
+//		Timeout time.Duration `yaml:"timeout"`
+//	}
+//	endpoints, err := xconf.GetSlice[Endpoint](cfg, "endpoints")
+func GetSlice[T any](config Config, key string) ([]T, error) {
+	value := config.Get(key)
+	if value == nil {
+		list, err := indexedKeysList(config, key)
+		if err != nil {
+			return nil, err
+		}
+		if list == nil {
+			return nil, nil
+		}
+		value = list
+	}
+	if strValue, ok := value.(string); ok { // JSON is valid YAML, too.
+		var list []any
+		if err := yaml.Unmarshal([]byte(strValue), &list); err != nil {
+			return nil, xerr.Wrapf(ErrInvalidSlice, "key %q: %v", key, err)
+		}
+		value = list
+	}
+
+	content, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, xerr.Wrapf(ErrInvalidSlice, "key %q: %v", key, err)
+	}
+	var items []T
+	if err := yaml.Unmarshal(content, &items); err != nil {
+		return nil, xerr.Wrapf(ErrInvalidSlice, "key %q: %v", key, err)
+	}
+
+	return items, nil
+}
+
+// indexedKeysList builds a list from the "<key><delimiter><index>[<delimiter><field>]" flat keys
+// of the configuration, if any. Fields containing the delimiter are nested into maps.
+// An index greater than or equal to configuration's keys count is rejected,
+// as the list would be mostly empty (and could not even be allocated, for huge indexes).
+func indexedKeysList(config Config, key string) ([]any, error) {
+	snapshotter, ok := config.(configMapSnapshotter)
+	if !ok {
+		return nil, nil
+	}
+	delimiter := sliceDefaultDelimiter
+	ignoreCase := false
+	switch cfg := config.(type) {
+	case *DefaultConfig:
+		if cfg.keyDelimiter != "" {
+			delimiter = cfg.keyDelimiter
+		}
+		ignoreCase = cfg.ignoreCaseSensitivity
+	case SubConfig:
+		delimiter = cfg.delimiter
+		ignoreCase = cfg.parent.ignoreCaseSensitivity
+	}
+
+	prefix := key + delimiter
+	items := make(map[int]any)
+	maxIdx := -1
+	configMap := snapshotter.configMapSnapshot()
+	for flatKey, value := range configMap {
+		if !strings.HasPrefix(flatKey, prefix) && !(ignoreCase && hasPrefixFold(flatKey, prefix)) {
+			continue
+		}
+		idxStr, field, hasField := strings.Cut(flatKey[len(prefix):], delimiter)
+		idx, err := strconv.Atoi(idxStr)
+		if err != nil || idx < 0 {
+			continue
+		}
+		if idx >= len(configMap) {
+			return nil, xerr.Wrapf(ErrInvalidSlice, "key %q: index %d is out of range", key, idx)
+		}
+		if strValue, isStr := value.(string); isStr {
+			// flat keys' values are usually loaded as strings (from ENV / properties),
+			// let them be resolved implicitly (like "true" into a bool).
+			value = &yaml.Node{Kind: yaml.ScalarNode, Value: strValue}
+		}
+		if !hasField {
+			if _, found := items[idx]; !found {
+				items[idx] = value
+			}
+		} else {
+			item, isMap := items[idx].(map[string]any)
+			if !isMap {
+				item = make(map[string]any)
+				items[idx] = item
+			}
+			if ignoreCase {
+				field = strings.ToLower(field)
+			}
+			setNestedField(item, strings.Split(field, delimiter), value)
+		}
+		if idx > maxIdx {
+			maxIdx = idx
+		}
+	}
+	if maxIdx < 0 {
+		return nil, nil
+	}
+
+	list := make([]any, maxIdx+1)
+	for idx, item := range items {
+		list[idx] = item
+	}
+
+	return list, nil
+}
+
+// setNestedField sets the value of the field whose segments are keys of nested maps.
+func setNestedField(item map[string]any, segments []string, value any) {
+	for _, segment := range segments[:len(segments)-1] {
+		nestedItem, isMap := item[segment].(map[string]any)
+		if !isMap {
+			nestedItem = make(map[string]any)
+			item[segment] = nestedItem
+		}
+		item = nestedItem
+	}
+	item[segments[len(segments)-1]] = value
+}
+
+// TrimmedStringList converts a list, or a comma separated string (like "host1:6379, host2:6379"),
+// into a slice of non-empty trimmed strings.
+// It is useful for reading lists which can be configured in both forms (ex: YAML list / ENV variable).
+func TrimmedStringList(value any) []string {
+	var items []string
+	if strValue, ok := value.(string); ok {
+		items = strings.Split(strValue, ",")
+	} else {
+		items = cast.ToStringSlice(value)
+	}
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,251 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+type testEndpoint struct {
+	URL     string        `yaml:"url"`
+	Timeout time.Duration `yaml:"timeout"`
+	TLS     struct {
+		Enabled bool `yaml:"enabled"`
+	} `yaml:"tls"`
+}
+
+func TestGetSlice(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - nested list", testGetSliceFromNestedList)
+	t.Run("success - indexed flat keys", testGetSliceFromIndexedFlatKeys)
+	t.Run("success - indexed flat keys, custom delimiter, ignore case", testGetSliceFromIndexedFlatKeysWithDelimiterIgnoreCase)
+	t.Run("success - JSON string", testGetSliceFromJSONString)
+	t.Run("success - scalars", testGetSliceOfScalars)
+	t.Run("success - key not found", testGetSliceKeyNotFound)
+	t.Run("error - not a list", testGetSliceReturnsErrForNotAList)
+	t.Run("error - index out of range", testGetSliceReturnsErrForIndexOutOfRange)
+}
+
+func testGetSliceFromNestedList(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config, err := xconf.NewDefaultConfig(xconf.YAMLReaderLoader(strings.NewReader(`
+endpoints:
+  - url: http://a.example.com
+    timeout: 2s
+    tls:
+      enabled: true
+  - url: http://b.example.com
+`)))
+	requireNil(t, err)
+
+	// act
+	endpoints, err := xconf.GetSlice[testEndpoint](config, "endpoints")
+
+	// assert
+	assertNil(t, err)
+	if assertEqual(t, 2, len(endpoints)) {
+		assertEqual(t, "http://a.example.com", endpoints[0].URL)
+		assertEqual(t, 2*time.Second, endpoints[0].Timeout)
+		assertTrue(t, endpoints[0].TLS.Enabled)
+		assertEqual(t, "http://b.example.com", endpoints[1].URL)
+		assertEqual(t, time.Duration(0), endpoints[1].Timeout)
+	}
+}
+
+func testGetSliceFromIndexedFlatKeys(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config := xconf.NewMockConfig(
+		"endpoints.0.url", "http://a.example.com",
+		"endpoints.0.timeout", "2s",
+		"endpoints.0.tls.enabled", "true",
+		"endpoints.1.url", "http://b.example.com",
+		"endpoints.x.url", "http://ignored.example.com",
+		"endpointsx.2.url", "http://ignored.example.com",
+	)
+
+	// act
+	endpoints, err := xconf.GetSlice[testEndpoint](config, "endpoints")
+
+	// assert
+	assertNil(t, err)
+	if assertEqual(t, 2, len(endpoints)) {
+		assertEqual(t, "http://a.example.com", endpoints[0].URL)
+		assertEqual(t, 2*time.Second, endpoints[0].Timeout)
+		assertTrue(t, endpoints[0].TLS.Enabled)
+		assertEqual(t, "http://b.example.com", endpoints[1].URL)
+	}
+}
+
+func testGetSliceFromIndexedFlatKeysWithDelimiterIgnoreCase(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{
+			"APP__ENDPOINTS__0__URL":          "http://a.example.com",
+			"APP__ENDPOINTS__1__URL":          "http://b.example.com",
+			"APP__ENDPOINTS__1__TLS__ENABLED": "true",
+		}),
+		xconf.DefaultConfigWithKeyDelimiter("__"),
+		xconf.DefaultConfigWithIgnoreCaseSensitivity(),
+	)
+	requireNil(t, err)
+
+	// act
+	endpoints, err := xconf.GetSlice[testEndpoint](config.Sub("app"), "endpoints")
+
+	// assert
+	assertNil(t, err)
+	if assertEqual(t, 2, len(endpoints)) {
+		assertEqual(t, "http://a.example.com", endpoints[0].URL)
+		assertEqual(t, "http://b.example.com", endpoints[1].URL)
+		assertTrue(t, endpoints[1].TLS.Enabled)
+	}
+}
+
+func testGetSliceFromJSONString(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config := xconf.NewMockConfig(
+		"ENDPOINTS", `[{"url": "http://a.example.com", "timeout": "1m"}]`,
+	)
+
+	// act
+	endpoints, err := xconf.GetSlice[testEndpoint](config, "ENDPOINTS")
+
+	// assert
+	assertNil(t, err)
+	if assertEqual(t, 1, len(endpoints)) {
+		assertEqual(t, "http://a.example.com", endpoints[0].URL)
+		assertEqual(t, time.Minute, endpoints[0].Timeout)
+	}
+}
+
+func testGetSliceOfScalars(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config := xconf.NewMockConfig(
+		"ports", []any{80, 443},
+		"hosts.1", "b.example.com",
+		"hosts.0", "a.example.com",
+	)
+
+	// act
+	ports, errPorts := xconf.GetSlice[int](config, "ports")
+	hosts, errHosts := xconf.GetSlice[string](config, "hosts")
+
+	// assert
+	assertNil(t, errPorts)
+	assertEqual(t, []int{80, 443}, ports)
+	assertNil(t, errHosts)
+	assertEqual(t, []string{"a.example.com", "b.example.com"}, hosts)
+}
+
+func testGetSliceKeyNotFound(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config := xconf.NewMockConfig("foo", "bar")
+
+	// act
+	endpoints, err := xconf.GetSlice[testEndpoint](config, "endpoints")
+
+	// assert
+	assertNil(t, err)
+	assertNil(t, endpoints)
+}
+
+func testGetSliceReturnsErrForNotAList(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config := xconf.NewMockConfig(
+		"endpoints", map[string]any{"url": "http://a.example.com"},
+		"hosts", "not a list",
+	)
+
+	// act
+	endpoints, errEndpoints := xconf.GetSlice[testEndpoint](config, "endpoints")
+	hosts, errHosts := xconf.GetSlice[string](config, "hosts")
+
+	// assert
+	assertTrue(t, errors.Is(errEndpoints, xconf.ErrInvalidSlice))
+	assertNil(t, endpoints)
+	assertTrue(t, errors.Is(errHosts, xconf.ErrInvalidSlice))
+	assertNil(t, hosts)
+}
+
+func testGetSliceReturnsErrForIndexOutOfRange(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config := xconf.NewMockConfig(
+		"endpoints.0.url", "http://a.example.com",
+		"endpoints.9999999999.url", "http://b.example.com",
+	)
+
+	// act
+	endpoints, err := xconf.GetSlice[testEndpoint](config, "endpoints")
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrInvalidSlice))
+	assertNil(t, endpoints)
+}
+
+func TestTrimmedStringList(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tests := [...]struct {
+		name           string
+		inputValue     any
+		expectedResult []string
+	}{
+		{
+			name:           "comma separated string",
+			inputValue:     " host1:6379, host2:6379 ,, ",
+			expectedResult: []string{"host1:6379", "host2:6379"},
+		},
+		{
+			name:           "list",
+			inputValue:     []any{"host1:6379", " ", " host2:6379"},
+			expectedResult: []string{"host1:6379", "host2:6379"},
+		},
+		{
+			name:           "empty string",
+			inputValue:     "",
+			expectedResult: []string{},
+		},
+		{
+			name:           "nil",
+			inputValue:     nil,
+			expectedResult: []string{},
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			// act
+			result := xconf.TrimmedStringList(test.inputValue)
+
+			// assert
+			assertEqual(t, test.expectedResult, result)
+		})
+	}
+}
@@ -0,0 +1,355 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/actforgood/xerr"
+)
+
+// ErrSnapshotNotFound is an error returned by [LoadFromSnapshot] if no snapshot
+// was active at the given time.
+var ErrSnapshotNotFound = errors.New("configuration snapshot not found")
+
+const (
+	snapshotFilePrefix     = "xconf-snapshot-"
+	snapshotFileExt        = ".json"
+	snapshotDeltaFileExt   = ".delta.json"
+	snapshotTimestampFmt   = "20060102T150405.000000000Z"
+	snapshotFilePermission = 0o600
+)
+
+// snapshots holds the state needed to write configuration snapshots on disk.
+type snapshots struct {
+	// dirPath is the directory snapshot files are written in.
+	dirPath string
+	// maxCount is the maximum number of snapshot files kept.
+	maxCount int
+	// maxTotalSize is the maximum total size, in bytes, of the snapshot files kept.
+	maxTotalSize int64
+	// checkpointEvery is the number of snapshots after which a full checkpoint is written,
+	// when deltas are enabled.
+	checkpointEvery int
+	// mu protects lastConfigMap and deltasCnt.
+	mu sync.Mutex
+	// lastConfigMap is the last written configuration map.
+	lastConfigMap map[string]any
+	// deltasCnt is the number of deltas written since the last full checkpoint.
+	deltasCnt int
+}
+
+// snapshotDelta is the content of a delta snapshot file.
+type snapshotDelta struct {
+	// Set holds the added / updated keys with their new values.
+	Set map[string]any `json:"set,omitempty"`
+	// Deleted holds the deleted keys.
+	Deleted []string `json:"deleted,omitempty"`
+}
+
+// SnapshotOption defines optional function for configuring snapshots.
+type SnapshotOption func(*snapshots)
+
+// SnapshotWithDeltas enables writing, instead of the whole configuration map,
+// only the changes from the previous snapshot, keeping on-disk history compact
+// for very large configurations.
+// Every checkpointEvery-th snapshot is a full checkpoint, which the following
+// deltas are applied upon. The first snapshot written by a process is always
+// a full checkpoint.
+// Snapshots starting with the newest checkpoint are never removed, so
+// checkpointEvery should be lower than maxCount.
+// A value <= 1 disables deltas.
+//
+// By default, every snapshot is a full checkpoint.
+func SnapshotWithDeltas(checkpointEvery int) SnapshotOption {
+	return func(snaps *snapshots) {
+		snaps.checkpointEvery = checkpointEvery
+	}
+}
+
+// DefaultConfigWithSnapshots enables writing every successfully loaded configuration which
+// differs from the previous one to a ring of timestamped snapshot files, in given directory,
+// enabling post-incident analysis of exactly what configuration was active at a given time
+// (see [LoadFromSnapshot]).
+// The oldest snapshot files are removed when there are more than maxCount files,
+// or their total size exceeds maxTotalSize bytes (the newest snapshot is always kept).
+// A value <= 0 disables the corresponding bound.
+//
+// Snapshots are JSON documents, so, for example, numbers are loaded back as float64.
+// Files are written with 0600 permissions, as configuration may contain secrets.
+// Errors that occur while writing snapshots are passed to the reload error handler, if set
+// (see [DefaultConfigWithReloadErrorHandler]).
+//
+// By default, snapshots are not written.
+//
+// Usage example:
+//
+//	cfg, err := xconf.NewDefaultConfig(
+//		loader,
+//		xconf.DefaultConfigWithReloadInterval(time.Minute),
+//		xconf.DefaultConfigWithSnapshots("/var/lib/myapp/config-snapshots", 100, 50<<20),
+//	)
+func DefaultConfigWithSnapshots(
+	dirPath string,
+	maxCount int,
+	maxTotalSize int64,
+	opts ...SnapshotOption,
+) DefaultConfigOption {
+	return func(config *DefaultConfig) {
+		snaps := &snapshots{
+			dirPath:      dirPath,
+			maxCount:     maxCount,
+			maxTotalSize: maxTotalSize,
+		}
+
+		// apply options, if any.
+		for _, opt := range opts {
+			opt(snaps)
+		}
+
+		config.snapshots = snaps
+	}
+}
+
+// save writes the configuration map to a new snapshot file, if it changed,
+// and removes the oldest snapshot files exceeding the bounds.
+func (snaps *snapshots) save(configMap map[string]any, now time.Time) error {
+	snaps.mu.Lock()
+	defer snaps.mu.Unlock()
+
+	if snaps.lastConfigMap != nil && reflect.DeepEqual(snaps.lastConfigMap, configMap) {
+		return nil
+	}
+
+	var (
+		content  []byte
+		err      error
+		isDelta  = snaps.lastConfigMap != nil && snaps.deltasCnt < snaps.checkpointEvery-1
+		fileName = snapshotFileName(now, isDelta)
+	)
+	if isDelta {
+		var delta snapshotDelta
+		for _, change := range Diff(snaps.lastConfigMap, configMap) {
+			if change.Op == KeyDeleted {
+				delta.Deleted = append(delta.Deleted, change.Key)
+
+				continue
+			}
+			if delta.Set == nil {
+				delta.Set = make(map[string]any)
+			}
+			delta.Set[change.Key] = change.NewValue
+		}
+		content, err = json.Marshal(delta)
+	} else {
+		content, err = json.Marshal(configMap)
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(snaps.dirPath, 0o700); err != nil {
+		return err
+	}
+	filePath := filepath.Join(snaps.dirPath, fileName)
+	tmpFilePath := filePath + ".tmp"
+	if err := os.WriteFile(tmpFilePath, content, snapshotFilePermission); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpFilePath, filePath); err != nil {
+		_ = os.Remove(tmpFilePath)
+
+		return err
+	}
+	snaps.lastConfigMap = DeepCopyConfigMap(configMap)
+	if isDelta {
+		snaps.deltasCnt++
+	} else {
+		snaps.deltasCnt = 0
+	}
+
+	return snaps.prune()
+}
+
+// prune removes the oldest snapshot files exceeding the bounds.
+// Snapshots starting with the newest full checkpoint are always kept,
+// and deltas whose previous snapshot is removed are removed, too, as
+// they cannot be applied anymore.
+func (snaps *snapshots) prune() error {
+	files, err := listSnapshotFiles(snaps.dirPath)
+	if err != nil {
+		return err
+	}
+
+	keepFromIdx := len(files) - 1
+	for keepFromIdx > 0 && files[keepFromIdx].delta {
+		keepFromIdx--
+	}
+
+	var (
+		mErr      *xerr.MultiError
+		totalSize int64
+		kept      int
+		remove    = make([]bool, len(files))
+	)
+	for idx := len(files) - 1; idx >= 0; idx-- { // from newest to oldest.
+		info, err := os.Stat(files[idx].path)
+		if err != nil {
+			mErr = mErr.Add(err)
+
+			continue
+		}
+		exceeds := (snaps.maxCount > 0 && kept >= snaps.maxCount) ||
+			(snaps.maxTotalSize > 0 && totalSize+info.Size() > snaps.maxTotalSize)
+		if exceeds && idx < keepFromIdx {
+			remove[idx] = true
+
+			continue
+		}
+		kept++
+		totalSize += info.Size()
+	}
+	for idx := range files { // from oldest to newest.
+		if files[idx].delta && idx < keepFromIdx && (idx == 0 || remove[idx-1]) {
+			remove[idx] = true
+		}
+		if remove[idx] {
+			if err := os.Remove(files[idx].path); err != nil {
+				mErr = mErr.Add(err)
+			}
+		}
+	}
+
+	return mErr.ErrOrNil()
+}
+
+// LoadFromSnapshot returns the configuration map which was active at given time,
+// from the snapshot files written in given directory (see [DefaultConfigWithSnapshots]).
+// If there is no snapshot written before given time, [ErrSnapshotNotFound] is returned.
+//
+// Usage example:
+//
+//	incidentTime, _ := time.Parse(time.RFC3339, "2022-10-12T03:14:15Z")
+//	configMap, err := xconf.LoadFromSnapshot("/var/lib/myapp/config-snapshots", incidentTime)
+func LoadFromSnapshot(dirPath string, t time.Time) (map[string]any, error) {
+	files, err := listSnapshotFiles(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := sort.Search(len(files), func(i int) bool {
+		return files[i].timestamp.After(t)
+	}) - 1
+	if idx < 0 {
+		return nil, xerr.Wrapf(ErrSnapshotNotFound, "at %s", t.Format(time.RFC3339Nano))
+	}
+
+	checkpointIdx := idx
+	for checkpointIdx >= 0 && files[checkpointIdx].delta {
+		checkpointIdx--
+	}
+	if checkpointIdx < 0 {
+		return nil, xerr.Wrapf(
+			ErrSnapshotNotFound,
+			"at %s, no checkpoint found for delta",
+			t.Format(time.RFC3339Nano),
+		)
+	}
+
+	var configMap map[string]any
+	if err := readSnapshotFile(files[checkpointIdx].path, &configMap); err != nil {
+		return nil, err
+	}
+	for _, file := range files[checkpointIdx+1 : idx+1] {
+		var delta snapshotDelta
+		if err := readSnapshotFile(file.path, &delta); err != nil {
+			return nil, err
+		}
+		if configMap == nil {
+			configMap = make(map[string]any, len(delta.Set))
+		}
+		for _, key := range delta.Deleted {
+			delete(configMap, key)
+		}
+		for key, value := range delta.Set {
+			configMap[key] = value
+		}
+	}
+
+	return configMap, nil
+}
+
+// readSnapshotFile reads and decodes the JSON snapshot file at given path into v.
+func readSnapshotFile(filePath string, v any) error {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(content, v)
+}
+
+// snapshotFile holds a snapshot file's path, timestamp and whether it's a delta.
+type snapshotFile struct {
+	path      string
+	timestamp time.Time
+	delta     bool
+}
+
+// snapshotFileName returns the snapshot file name for given time.
+func snapshotFileName(t time.Time, delta bool) string {
+	ext := snapshotFileExt
+	if delta {
+		ext = snapshotDeltaFileExt
+	}
+
+	return snapshotFilePrefix + t.UTC().Format(snapshotTimestampFmt) + ext
+}
+
+// listSnapshotFiles returns the snapshot files from given directory, sorted from oldest to newest.
+func listSnapshotFiles(dirPath string) ([]snapshotFile, error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	files := make([]snapshotFile, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, snapshotFilePrefix) || !strings.HasSuffix(name, snapshotFileExt) {
+			continue
+		}
+		delta := strings.HasSuffix(name, snapshotDeltaFileExt)
+		ext := snapshotFileExt
+		if delta {
+			ext = snapshotDeltaFileExt
+		}
+		timestamp, err := time.Parse(
+			snapshotTimestampFmt,
+			strings.TrimSuffix(strings.TrimPrefix(name, snapshotFilePrefix), ext),
+		)
+		if err != nil {
+			continue // not a snapshot file.
+		}
+		files = append(files, snapshotFile{path: filepath.Join(dirPath, name), timestamp: timestamp, delta: delta})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].timestamp.Before(files[j].timestamp)
+	})
+
+	return files, nil
+}
@@ -0,0 +1,263 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestDefaultConfigWithSnapshots(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - changed configurations are written and loaded", testDefaultConfigWithSnapshotsWritesChanges)
+	t.Run("success - oldest snapshots are removed", testDefaultConfigWithSnapshotsPrunes)
+	t.Run("error - write error is passed to error handler", testDefaultConfigWithSnapshotsWriteErr)
+	t.Run("success - deltas are written and applied", testDefaultConfigWithSnapshotsDeltas)
+	t.Run("success - deltas are not left without checkpoint", testDefaultConfigWithSnapshotsDeltasPrunes)
+}
+
+func testDefaultConfigWithSnapshotsWritesChanges(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		dirPath        = t.TempDir()
+		loaderCallsCnt uint32
+		loader         = xconf.LoaderFunc(func() (map[string]any, error) {
+			version := atomic.AddUint32(&loaderCallsCnt, 1)
+			if version > 2 {
+				version = 2 // configuration does not change anymore.
+			}
+
+			return map[string]any{"version": version}, nil
+		})
+		beforeLoadTime = time.Now()
+	)
+	subject, err := xconf.NewDefaultConfig(
+		loader,
+		xconf.DefaultConfigWithReloadInterval(50*time.Millisecond),
+		xconf.DefaultConfigWithSnapshots(dirPath, 10, 0),
+	)
+	requireNil(t, err)
+	defer subject.Close()
+	firstLoadTime := time.Now()
+
+	// act
+	time.Sleep(220 * time.Millisecond)
+
+	// assert
+	assertTrue(t, atomic.LoadUint32(&loaderCallsCnt) >= 4)
+	files, err := os.ReadDir(dirPath)
+	requireNil(t, err)
+	assertEqual(t, 2, len(files))
+
+	// act
+	configMap, err := xconf.LoadFromSnapshot(dirPath, firstLoadTime)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"version": float64(1)}, configMap)
+
+	// act
+	configMap, err = xconf.LoadFromSnapshot(dirPath, time.Now())
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"version": float64(2)}, configMap)
+
+	// act
+	configMap, err = xconf.LoadFromSnapshot(dirPath, beforeLoadTime)
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrSnapshotNotFound))
+	assertNil(t, configMap)
+}
+
+func testDefaultConfigWithSnapshotsPrunes(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name          string
+		maxCount      int
+		maxTotalSize  int64
+		expectedFiles int
+	}{
+		{name: "max count", maxCount: 3, maxTotalSize: 0, expectedFiles: 3},
+		{name: "max total size", maxCount: 0, maxTotalSize: 3 * int64(len(`{"version":10}`)), expectedFiles: 3},
+		{name: "max total size less than a snapshot", maxCount: 0, maxTotalSize: 1, expectedFiles: 1},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var (
+				dirPath        = t.TempDir()
+				loaderCallsCnt uint32
+				loader         = xconf.LoaderFunc(func() (map[string]any, error) {
+					return map[string]any{"version": 10 + atomic.AddUint32(&loaderCallsCnt, 1)}, nil
+				})
+			)
+			subject, err := xconf.NewDefaultConfig(
+				loader,
+				xconf.DefaultConfigWithReloadInterval(10*time.Millisecond),
+				xconf.DefaultConfigWithSnapshots(dirPath, test.maxCount, test.maxTotalSize),
+			)
+			requireNil(t, err)
+
+			// act
+			time.Sleep(100 * time.Millisecond)
+			_ = subject.Close()
+
+			// assert
+			files, err := os.ReadDir(dirPath)
+			requireNil(t, err)
+			assertEqual(t, test.expectedFiles, len(files))
+			configMap, err := xconf.LoadFromSnapshot(dirPath, time.Now())
+			requireNil(t, err)
+			assertEqual(t, subject.Get("version", 0), int(configMap["version"].(float64)))
+		})
+	}
+}
+
+func testDefaultConfigWithSnapshotsWriteErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		filePath   = filepath.Join(t.TempDir(), "file")
+		handledErr error
+	)
+	requireNil(t, os.WriteFile(filePath, nil, 0o600))
+
+	// act
+	subject, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{"foo": "bar"}),
+		xconf.DefaultConfigWithSnapshots(filepath.Join(filePath, "snapshots"), 10, 0),
+		xconf.DefaultConfigWithReloadErrorHandler(func(err error) {
+			handledErr = err
+		}),
+	)
+
+	// assert
+	requireNil(t, err)
+	assertEqual(t, "bar", subject.Get("foo"))
+	assertNotNil(t, handledErr)
+}
+
+func testDefaultConfigWithSnapshotsDeltas(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		dirPath        = t.TempDir()
+		loaderCallsCnt uint32
+		loader         = xconf.LoaderFunc(func() (map[string]any, error) {
+			version := atomic.AddUint32(&loaderCallsCnt, 1)
+			if version > 5 {
+				version = 5 // configuration does not change anymore.
+			}
+			configMap := map[string]any{"version": version, "static": "value"}
+			if version%2 == 1 {
+				configMap["odd"] = true
+			}
+
+			return configMap, nil
+		})
+	)
+	subject, err := xconf.NewDefaultConfig(
+		loader,
+		xconf.DefaultConfigWithReloadInterval(20*time.Millisecond),
+		xconf.DefaultConfigWithSnapshots(dirPath, 0, 0, xconf.SnapshotWithDeltas(3)),
+	)
+	requireNil(t, err)
+
+	// act
+	time.Sleep(200 * time.Millisecond)
+	_ = subject.Close()
+
+	// assert
+	entries, err := os.ReadDir(dirPath)
+	requireNil(t, err)
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	if assertEqual(t, 5, len(names)) {
+		for idx, expectedDelta := range [...]bool{false, true, true, false, true} {
+			assertEqual(t, expectedDelta, strings.HasSuffix(names[idx], ".delta.json"))
+		}
+
+		// act
+		thirdSnapshotTime, err := time.Parse(
+			"20060102T150405.000000000Z",
+			strings.TrimSuffix(strings.TrimPrefix(names[2], "xconf-snapshot-"), ".delta.json"),
+		)
+		requireNil(t, err)
+		configMap, err := xconf.LoadFromSnapshot(dirPath, thirdSnapshotTime)
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, map[string]any{"version": float64(3), "static": "value", "odd": true}, configMap)
+	}
+
+	// act
+	configMap, err := xconf.LoadFromSnapshot(dirPath, time.Now())
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"version": float64(5), "static": "value", "odd": true}, configMap)
+}
+
+func testDefaultConfigWithSnapshotsDeltasPrunes(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		dirPath        = t.TempDir()
+		loaderCallsCnt uint32
+		loader         = xconf.LoaderFunc(func() (map[string]any, error) {
+			return map[string]any{"version": 10 + atomic.AddUint32(&loaderCallsCnt, 1)}, nil
+		})
+	)
+	subject, err := xconf.NewDefaultConfig(
+		loader,
+		xconf.DefaultConfigWithReloadInterval(10*time.Millisecond),
+		xconf.DefaultConfigWithSnapshots(dirPath, 2, 0, xconf.SnapshotWithDeltas(4)),
+	)
+	requireNil(t, err)
+
+	// act
+	time.Sleep(100 * time.Millisecond)
+	_ = subject.Close()
+
+	// assert
+	entries, err := os.ReadDir(dirPath)
+	requireNil(t, err)
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	if assertTrue(t, len(names) > 0) {
+		assertTrue(t, !strings.HasSuffix(names[0], ".delta.json"))
+	}
+	configMap, err := xconf.LoadFromSnapshot(dirPath, time.Now())
+	requireNil(t, err)
+	assertEqual(t, subject.Get("version", 0), int(configMap["version"].(float64)))
+}
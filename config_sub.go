@@ -0,0 +1,175 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// subConfigDefaultDelimiter is the delimiter of sub-config's prefix and keys,
+// if no key delimiter is set on the parent config.
+const subConfigDefaultDelimiter = "."
+
+// SubConfig is a view of a [DefaultConfig] rooted at a prefix, so that
+// components can receive only their slice of configuration.
+// It shares reloads and observers with the parent config.
+type SubConfig struct {
+	// parent is the config the view is of.
+	parent *defaultConfig
+	// prefix is the prefix the view is rooted at.
+	prefix string
+	// delimiter is the delimiter between prefix and keys.
+	delimiter string
+}
+
+// Sub returns a view of the configuration rooted at given prefix.
+// Sub-config's key is looked up in parent config as a flattened key ("db.mysql.host"),
+// and, if not found, in the nested maps under the prefix (the "host" key of the "mysql" map under "db" key).
+// Prefix and keys are delimited by the delimiter set with [DefaultConfigWithKeyDelimiter], or ".", by default.
+//
+// Example:
+//
+//	dbCfg := cfg.Sub("db.mysql")
+//	host := dbCfg.Get("host", "localhost").(string)
+func (cfg *defaultConfig) Sub(prefix string) SubConfig {
+	delimiter := cfg.keyDelimiter
+	if delimiter == "" {
+		delimiter = subConfigDefaultDelimiter
+	}
+
+	return SubConfig{
+		parent:    cfg,
+		prefix:    prefix,
+		delimiter: delimiter,
+	}
+}
+
+// Get returns a configuration value for a given key, relative to sub-config's prefix.
+// It implements [Config]. See [DefaultConfig.Get].
+func (sub SubConfig) Get(key string, def ...any) any {
+	return sub.parent.get(sub.fullKey(key), sub.delimiter, def...)
+}
+
+// GetE returns a configuration value for a given key, relative to sub-config's prefix,
+// and the cast failure as error, if any. See [DefaultConfig.GetE].
+func (sub SubConfig) GetE(key string, def ...any) (any, error) {
+	value, failure := sub.parent.lookup(sub.fullKey(key), sub.delimiter, def...)
+	if failure != nil {
+		return value, *failure
+	}
+
+	return value, nil
+}
+
+// Sub returns a view of the configuration rooted at given prefix, relative to sub-config's prefix.
+func (sub SubConfig) Sub(prefix string) SubConfig {
+	sub.prefix = sub.fullKey(prefix)
+
+	return sub
+}
+
+// Prefix returns the prefix the sub-config is rooted at.
+func (sub SubConfig) Prefix() string {
+	return sub.prefix
+}
+
+// RegisterObserver adds a new observer on parent config that will get notified
+// of sub-config's keys changes (relative to sub-config's prefix).
+// If the nested map the sub-config is rooted in changes, the observer gets notified
+// with all sub-config's keys.
+// The returned handle can be used to unregister the observer, see [SubConfig.UnregisterObserver].
+func (sub SubConfig) RegisterObserver(observer ConfigObserver) ObserverHandle {
+	return sub.parent.RegisterObserver(func(_ Config, changedKeys ...string) {
+		if subChangedKeys := sub.changedKeys(changedKeys); len(subChangedKeys) > 0 {
+			observer(sub, subChangedKeys...)
+		}
+	})
+}
+
+// UnregisterObserver removes the observer registered with given handle.
+func (sub SubConfig) UnregisterObserver(handle ObserverHandle) {
+	sub.parent.UnregisterObserver(handle)
+}
+
+// fullKey returns parent config's key for given sub-config's key.
+func (sub SubConfig) fullKey(key string) string {
+	return sub.prefix + sub.delimiter + key
+}
+
+// relativeKey returns sub-config's key for given parent config's key,
+// and whether parent config's key is under sub-config's prefix.
+func (sub SubConfig) relativeKey(key string) (string, bool) {
+	prefix := sub.prefix + sub.delimiter
+	if sub.parent.ignoreCaseSensitivity {
+		if hasPrefixFold(key, prefix) {
+			return key[len(prefix):], true
+		}
+
+		return "", false
+	}
+	if strings.HasPrefix(key, prefix) {
+		return key[len(prefix):], true
+	}
+
+	return "", false
+}
+
+// isAncestorKey checks whether given parent config's key holds (nested)
+// the sub-config's prefix, or is the prefix itself.
+func (sub SubConfig) isAncestorKey(key string) bool {
+	if sub.parent.ignoreCaseSensitivity {
+		return strings.EqualFold(key, sub.prefix) || hasPrefixFold(sub.prefix, key+sub.delimiter)
+	}
+
+	return key == sub.prefix || strings.HasPrefix(sub.prefix, key+sub.delimiter)
+}
+
+// changedKeys returns sub-config's keys, corresponding to parent config's changed keys.
+func (sub SubConfig) changedKeys(changedKeys []string) []string {
+	var subChangedKeys []string
+	for _, changedKey := range changedKeys {
+		if relativeKey, ok := sub.relativeKey(changedKey); ok {
+			subChangedKeys = append(subChangedKeys, relativeKey)
+		} else if sub.isAncestorKey(changedKey) {
+			for key := range sub.nestedConfigMap() {
+				subChangedKeys = append(subChangedKeys, key)
+			}
+		}
+	}
+
+	return subChangedKeys
+}
+
+// nestedConfigMap returns the configuration nested under sub-config's prefix, if any.
+func (sub SubConfig) nestedConfigMap() map[string]any {
+	configMap := make(map[string]any)
+	switch nestedMap := sub.parent.get(sub.prefix, sub.delimiter).(type) {
+	case map[string]any:
+		for key, value := range nestedMap {
+			configMap[key] = value
+		}
+	case map[any]any:
+		for key, value := range nestedMap {
+			configMap[fmt.Sprintf("%v", key)] = value
+		}
+	}
+
+	return configMap
+}
+
+// configMapSnapshot returns the sub-config's key-value configuration map
+// (nested configuration under the prefix, and flattened keys having the prefix).
+func (sub SubConfig) configMapSnapshot() map[string]any {
+	configMap := DeepCopyConfigMap(sub.nestedConfigMap())
+	for key, value := range sub.parent.configMapSnapshot() {
+		if relativeKey, ok := sub.relativeKey(key); ok {
+			configMap[relativeKey] = value
+		}
+	}
+
+	return configMap
+}
@@ -0,0 +1,147 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestDefaultConfig_Sub(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - flattened and nested keys", testDefaultConfigSubGet)
+	t.Run("success - sub of sub", testDefaultConfigSubOfSub)
+	t.Run("success - custom key delimiter, ignore case sensitivity", testDefaultConfigSubWithKeyDelimiterIgnoreCase)
+	t.Run("success - observers are notified about sub-config's keys", testDefaultConfigSubRegisterObserver)
+	t.Run("success - dump", testDefaultConfigSubDump)
+}
+
+func getSubConfigMap() map[string]any {
+	return map[string]any{
+		"db": map[string]any{
+			"mysql": map[string]any{
+				"host": "127.0.0.1",
+				"port": 3306,
+			},
+		},
+		"db.mysql.user":  "root",
+		"db.postgres.db": "app",
+		"app.name":       "demo",
+	}
+}
+
+func testDefaultConfigSubGet(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config, err := xconf.NewDefaultConfig(xconf.PlainLoader(getSubConfigMap()))
+	requireNil(t, err)
+
+	// act
+	subject := config.Sub("db.mysql")
+
+	// assert
+	assertEqual(t, "db.mysql", subject.Prefix())
+	assertEqual(t, "127.0.0.1", subject.Get("host"))
+	assertEqual(t, "3306", subject.Get("port", ""))
+	assertEqual(t, "root", subject.Get("user"))
+	assertNil(t, subject.Get("db"))
+	assertEqual(t, "secret", subject.Get("password", "secret"))
+	assertNil(t, subject.Get("name"))
+}
+
+func testDefaultConfigSubOfSub(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config, err := xconf.NewDefaultConfig(xconf.PlainLoader(getSubConfigMap()))
+	requireNil(t, err)
+
+	// act
+	subject := config.Sub("db").Sub("postgres")
+
+	// assert
+	assertEqual(t, "db.postgres", subject.Prefix())
+	assertEqual(t, "app", subject.Get("db"))
+	assertNil(t, subject.Get("host"))
+}
+
+func testDefaultConfigSubWithKeyDelimiterIgnoreCase(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{
+			"DB__MYSQL__USER": "root",
+			"db":              map[any]any{"mysql": map[any]any{"Host": "127.0.0.1"}},
+		}),
+		xconf.DefaultConfigWithKeyDelimiter("__"),
+		xconf.DefaultConfigWithIgnoreCaseSensitivity(),
+	)
+	requireNil(t, err)
+
+	// act
+	subject := config.Sub("db__mysql")
+
+	// assert
+	assertEqual(t, "root", subject.Get("user"))
+	assertEqual(t, "127.0.0.1", subject.Get("HOST"))
+}
+
+func testDefaultConfigSubRegisterObserver(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(getSubConfigMap()),
+		xconf.DefaultConfigWithReloadInterval(time.Hour),
+	)
+	requireNil(t, err)
+	defer config.Close()
+	var (
+		subject     = config.Sub("db.mysql")
+		changedKeys [][]string
+		values      []any
+	)
+	handle := subject.RegisterObserver(func(cfg xconf.Config, keys ...string) {
+		sort.Strings(keys)
+		changedKeys = append(changedKeys, keys)
+		values = append(values, cfg.Get(keys[0]))
+	})
+
+	// act
+	requireNil(t, config.Set("db.mysql.user", "admin"))
+	requireNil(t, config.Set("app.name", "other"))
+	requireNil(t, config.Set("db", map[string]any{"mysql": map[string]any{"host": "10.0.0.1", "port": 3306}}))
+	subject.UnregisterObserver(handle)
+	requireNil(t, config.Unset("db.mysql.user"))
+
+	// assert
+	assertEqual(t, [][]string{{"user"}, {"host", "port"}}, changedKeys)
+	assertEqual(t, []any{"admin", "10.0.0.1"}, values)
+}
+
+func testDefaultConfigSubDump(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config, err := xconf.NewDefaultConfig(xconf.PlainLoader(getSubConfigMap()))
+	requireNil(t, err)
+	subject := config.Sub("db.mysql")
+	var buf bytes.Buffer
+
+	// act
+	err = xconf.Dump(subject, &buf, xconf.DumpFormatDotEnv)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, "host=\"127.0.0.1\"\nport=3306\nuser=\"root\"\n", buf.String())
+}
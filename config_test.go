@@ -6,11 +6,13 @@
 package xconf_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -1658,6 +1660,210 @@ func configObserverFactory(t *testing.T, observerCallsCount *int) xconf.ConfigOb
 	}
 }
 
+func TestDefaultConfig_UnregisterObserver(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - unregistered observer is not notified", testDefaultConfigUnregisterObserver)
+	t.Run("success - unregister from within observer", testDefaultConfigUnregisterObserverFromWithinObserver)
+}
+
+// changingLoader returns a loader which returns a different value for "foo" key on each load.
+func changingLoader() xconf.Loader {
+	var callsCnt uint32
+
+	return xconf.LoaderFunc(func() (map[string]any, error) {
+		return map[string]any{"foo": atomic.AddUint32(&callsCnt, 1)}, nil
+	})
+}
+
+func testDefaultConfigUnregisterObserver(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(
+		changingLoader(),
+		xconf.DefaultConfigWithReloadInterval(50*time.Millisecond),
+	)
+	requireNil(t, err)
+	defer subject.Close()
+	var observer1CallsCnt, observer2CallsCnt uint32
+	handle1 := subject.RegisterObserver(func(xconf.Config, ...string) {
+		atomic.AddUint32(&observer1CallsCnt, 1)
+	})
+	handle2 := subject.RegisterObserver(func(xconf.Config, ...string) {
+		atomic.AddUint32(&observer2CallsCnt, 1)
+	})
+
+	// act
+	subject.UnregisterObserver(handle1)
+	subject.UnregisterObserver(handle1) // no-op
+	time.Sleep(180 * time.Millisecond)
+
+	// assert
+	assertTrue(t, handle1 != handle2)
+	assertEqual(t, uint32(0), atomic.LoadUint32(&observer1CallsCnt))
+	assertTrue(t, atomic.LoadUint32(&observer2CallsCnt) > 0)
+}
+
+func testDefaultConfigUnregisterObserverFromWithinObserver(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(
+		changingLoader(),
+		xconf.DefaultConfigWithReloadInterval(50*time.Millisecond),
+	)
+	requireNil(t, err)
+	defer subject.Close()
+	var (
+		observerCallsCnt uint32
+		handle           atomic.Uint64
+	)
+	handle.Store(uint64(subject.RegisterObserver(func(xconf.Config, ...string) {
+		atomic.AddUint32(&observerCallsCnt, 1)
+		subject.UnregisterObserver(xconf.ObserverHandle(handle.Load()))
+	})))
+
+	// act
+	time.Sleep(180 * time.Millisecond)
+
+	// assert
+	assertEqual(t, uint32(1), atomic.LoadUint32(&observerCallsCnt))
+}
+
+func TestDefaultConfigWithNotifyInitialLoad(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loader = xconf.PlainLoader(map[string]any{
+			"foo": "bar",
+			"abc": "xyz",
+		})
+		subject, err = xconf.NewDefaultConfig(
+			loader,
+			xconf.DefaultConfigWithNotifyInitialLoad(),
+		)
+		observerCallsCnt int
+		observedKeys     []string
+		observer         = func(cfg xconf.Config, changedKeys ...string) {
+			observerCallsCnt++
+			observedKeys = changedKeys
+			assertEqual(t, "bar", cfg.Get("foo"))
+		}
+	)
+	requireNil(t, err)
+	defer subject.Close()
+
+	// act
+	subject.RegisterObserver(observer)
+
+	// assert
+	assertEqual(t, 1, observerCallsCnt)
+	sort.Strings(observedKeys)
+	assertEqual(t, []string{"abc", "foo"}, observedKeys)
+}
+
+func TestDefaultConfig_Close(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - idempotent, concurrent safe", testDefaultConfigCloseIsIdempotent)
+	t.Run("error - from closer loader", testDefaultConfigCloseReturnsErrFromLoader)
+	t.Run("success - hung reload is canceled", testDefaultConfigCloseCancelsHungReload)
+}
+
+func testDefaultConfigCloseIsIdempotent(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loader = &closerLoaderMock{
+			Loader: xconf.PlainLoader(map[string]any{"foo": "bar"}),
+		}
+		subject, err = xconf.NewDefaultConfig(
+			loader,
+			xconf.DefaultConfigWithReloadInterval(time.Minute),
+		)
+		wg sync.WaitGroup
+	)
+	requireNil(t, err)
+	assertTrue(t, !subject.Closed())
+
+	// act
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assertNil(t, subject.Close())
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	assertTrue(t, subject.Closed())
+	assertEqual(t, uint32(1), atomic.LoadUint32(&loader.closeCallsCnt))
+	assertEqual(t, "bar", subject.Get("foo")) // last configuration is still served
+}
+
+func testDefaultConfigCloseReturnsErrFromLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered close error")
+		loader      = &closerLoaderMock{
+			Loader:   xconf.PlainLoader(map[string]any{"foo": "bar"}),
+			closeErr: expectedErr,
+		}
+		subject, err = xconf.NewDefaultConfig(loader)
+	)
+	requireNil(t, err)
+
+	// act
+	closeErr := subject.Close()
+
+	// assert
+	assertTrue(t, errors.Is(closeErr, expectedErr))
+	assertTrue(t, subject.Closed())
+	assertNil(t, subject.Close()) // second call has no effect
+}
+
+func testDefaultConfigCloseCancelsHungReload(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loadsCnt int32
+		hung     = make(chan struct{})
+		loadErr  = make(chan error, 1)
+		loader   = xconf.ContextLoaderFunc(func(ctx context.Context) (map[string]any, error) {
+			if atomic.AddInt32(&loadsCnt, 1) == 1 {
+				return map[string]any{"foo": "bar"}, nil
+			}
+			close(hung)
+			<-ctx.Done() // simulate a hung remote call.
+			loadErr <- ctx.Err()
+
+			return nil, ctx.Err()
+		})
+		subject, err = xconf.NewDefaultConfig(
+			loader,
+			xconf.DefaultConfigWithReloadInterval(10*time.Millisecond),
+		)
+	)
+	requireNil(t, err)
+	<-hung
+
+	// act
+	closeErr := subject.Close()
+
+	// assert
+	assertNil(t, closeErr)
+	assertTrue(t, errors.Is(<-loadErr, context.Canceled))
+	assertEqual(t, int32(2), atomic.LoadInt32(&loadsCnt))
+	assertEqual(t, "bar", subject.Get("foo"))
+}
+
 func TestDefaultConfig_concurrency(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,167 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"strings"
+	"sync"
+)
+
+// watchChanBufferSize is the buffer size of a watcher's events channel.
+const watchChanBufferSize = 64
+
+// ChangeEvent describes a watched key's change, delivered by [DefaultConfig]'s Watch.
+// It holds the key, the change type (added/updated/deleted), the old and the new value.
+type ChangeEvent = KeyChange
+
+// watcher is a subscription to a key / keys prefix changes.
+type watcher struct {
+	// keyOrPrefix is the watched key or keys prefix.
+	keyOrPrefix string
+	// events is the channel events are sent on.
+	events chan ChangeEvent
+	// done is closed when the watcher is canceled, unblocking a pending send.
+	done chan struct{}
+	// cancelOnce is used to cancel the watcher only once.
+	cancelOnce sync.Once
+	// mu protects events channel from being closed while sending on it.
+	mu sync.Mutex
+	// canceled holds the canceled state.
+	canceled bool
+}
+
+// Watch subscribes to changes of the given key, or of the keys starting with given prefix
+// (like "db." for all database related keys). An empty keyOrPrefix watches all the keys.
+// Events are emitted from the reload loop (see [DefaultConfigWithReloadInterval]),
+// on a buffered channel, in keys order, for each reload which changed watched keys.
+// Note that a slow consumer, once the buffer is full, delays the reload loop.
+//
+// The returned cancel function unsubscribes and closes the events channel.
+// It is safe to be called multiple times. The events channel is also closed
+// when the configuration is closed.
+//
+// Usage example:
+//
+//	events, cancel := cfg.Watch("db.")
+//	defer cancel()
+//	for event := range events {
+//		log.Printf("%s %s: %v => %v", event.Op, event.Key, event.OldValue, event.NewValue)
+//	}
+func (cfg *defaultConfig) Watch(keyOrPrefix string) (<-chan ChangeEvent, func()) {
+	w := &watcher{
+		keyOrPrefix: keyOrPrefix,
+		events:      make(chan ChangeEvent, watchChanBufferSize),
+		done:        make(chan struct{}),
+	}
+	cancel := func() {
+		cfg.unregisterWatcher(w)
+		w.cancel()
+	}
+
+	cfg.mu.Lock()
+	if cfg.Closed() {
+		cfg.mu.Unlock()
+		w.cancel()
+
+		return w.events, cancel
+	}
+	watchers := make([]*watcher, len(cfg.watchers), len(cfg.watchers)+1)
+	copy(watchers, cfg.watchers)
+	cfg.watchers = append(watchers, w)
+	cfg.mu.Unlock()
+
+	return w.events, cancel
+}
+
+// unregisterWatcher removes given watcher from the registered ones.
+func (cfg *defaultConfig) unregisterWatcher(w *watcher) {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+
+	for idx, regWatcher := range cfg.watchers {
+		if regWatcher != w {
+			continue
+		}
+		watchers := make([]*watcher, 0, len(cfg.watchers)-1)
+		watchers = append(watchers, cfg.watchers[:idx]...)
+		cfg.watchers = append(watchers, cfg.watchers[idx+1:]...)
+
+		return
+	}
+}
+
+// notifyWatchers computes changes on a config reload, and sends them
+// to the registered watchers interested in them.
+func (cfg *defaultConfig) notifyWatchers(oldConfigMap, newConfigMap map[string]any) {
+	cfg.mu.RLock()
+	watchers := cfg.watchers
+	cfg.mu.RUnlock()
+
+	if len(watchers) == 0 {
+		return
+	}
+
+	changes := cfg.maskChanges(Diff(oldConfigMap, newConfigMap))
+	if len(changes) == 0 {
+		return
+	}
+	for _, w := range watchers {
+		w.send(changes, cfg.ignoreCaseSensitivity)
+	}
+}
+
+// closeWatchers cancels all the registered watchers.
+func (cfg *defaultConfig) closeWatchers() {
+	cfg.mu.Lock()
+	watchers := cfg.watchers
+	cfg.watchers = nil
+	cfg.mu.Unlock()
+
+	for _, w := range watchers {
+		w.cancel()
+	}
+}
+
+// send sends the changes matching watcher's key / prefix.
+// It blocks until the events are consumed or the watcher gets canceled.
+func (w *watcher) send(changes Changes, ignoreCase bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.canceled {
+		return
+	}
+	for _, change := range changes {
+		if !w.matches(change.Key, ignoreCase) {
+			continue
+		}
+		select {
+		case w.events <- change:
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// matches returns true if given key is watched.
+func (w *watcher) matches(key string, ignoreCase bool) bool {
+	if ignoreCase {
+		return hasPrefixFold(key, w.keyOrPrefix)
+	}
+
+	return strings.HasPrefix(key, w.keyOrPrefix)
+}
+
+// cancel closes the events channel, after unblocking a pending send.
+func (w *watcher) cancel() {
+	w.cancelOnce.Do(func() {
+		close(w.done)
+		w.mu.Lock()
+		w.canceled = true
+		close(w.events)
+		w.mu.Unlock()
+	})
+}
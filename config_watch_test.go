@@ -0,0 +1,142 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestDefaultConfig_Watch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - watched keys changes are emitted", testDefaultConfigWatchEmitsChanges)
+	t.Run("success - cancel closes events channel", testDefaultConfigWatchCancel)
+	t.Run("success - close closes events channel", testDefaultConfigWatchClose)
+}
+
+func testDefaultConfigWatchEmitsChanges(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loaderCallsCnt uint32
+		loader         = xconf.LoaderFunc(func() (map[string]any, error) {
+			if atomic.AddUint32(&loaderCallsCnt, 1) == 1 {
+				return map[string]any{
+					"db.host": "localhost",
+					"db.user": "root",
+					"app.env": "dev",
+				}, nil
+			}
+
+			return map[string]any{
+				"db.host": "db.example.com",
+				"db.port": 5432,
+				"app.env": "prod",
+			}, nil
+		})
+	)
+	subject, err := xconf.NewDefaultConfig(
+		loader,
+		xconf.DefaultConfigWithReloadInterval(20*time.Millisecond),
+	)
+	requireNil(t, err)
+	defer subject.Close()
+	events, cancel := subject.Watch("db.")
+	defer cancel()
+
+	// act
+	received := make([]xconf.ChangeEvent, 0, 3)
+	timeout := time.After(2 * time.Second)
+	for len(received) < 3 {
+		select {
+		case event := <-events:
+			received = append(received, event)
+		case <-timeout:
+			t.Fatalf("timeout waiting for events, received %v", received)
+		}
+	}
+
+	// assert
+	assertEqual(
+		t,
+		[]xconf.ChangeEvent{
+			{Key: "db.host", Op: xconf.KeyUpdated, OldValue: "localhost", NewValue: "db.example.com"},
+			{Key: "db.port", Op: xconf.KeyAdded, NewValue: 5432},
+			{Key: "db.user", Op: xconf.KeyDeleted, OldValue: "root"},
+		},
+		received,
+	)
+	select {
+	case event := <-events:
+		t.Errorf("unexpected event %v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func testDefaultConfigWatchCancel(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loaderCallsCnt uint32
+		loader         = xconf.LoaderFunc(func() (map[string]any, error) {
+			return map[string]any{"version": atomic.AddUint32(&loaderCallsCnt, 1)}, nil
+		})
+	)
+	subject, err := xconf.NewDefaultConfig(
+		loader,
+		xconf.DefaultConfigWithReloadInterval(5*time.Millisecond),
+	)
+	requireNil(t, err)
+	defer subject.Close()
+	events, cancel := subject.Watch("")
+	time.Sleep(50 * time.Millisecond) // no one consumes the events.
+
+	// act
+	cancel()
+	cancel() // multiple calls are safe.
+
+	// assert
+	for range events { // drain buffered events, channel is closed.
+	}
+	callsCnt := atomic.LoadUint32(&loaderCallsCnt)
+	time.Sleep(50 * time.Millisecond)
+	assertTrue(t, atomic.LoadUint32(&loaderCallsCnt) > callsCnt) // reload loop is not blocked.
+}
+
+func testDefaultConfigWatchClose(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{"foo": "bar"}),
+		xconf.DefaultConfigWithReloadInterval(time.Minute),
+	)
+	requireNil(t, err)
+	events, cancel := subject.Watch("foo")
+	defer cancel()
+
+	// act
+	err = subject.Close()
+
+	// assert
+	assertNil(t, err)
+	_, ok := <-events
+	assertTrue(t, !ok)
+
+	// act
+	events, cancel = subject.Watch("foo")
+	defer cancel()
+
+	// assert
+	_, ok = <-events
+	assertTrue(t, !ok)
+}
@@ -0,0 +1,158 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/spf13/cast"
+)
+
+// CredentialsProvider provides the current username / password read from a [Config],
+// resolving secret references (see [SecretResolver]).
+// Register its OnConfigChange method as an observer on a [DefaultConfig] with reload enabled,
+// and rotated credentials are picked up without restarting the app.
+// It's meant to be plugged into clients which ask for credentials on each
+// (re)connect, like go-redis's CredentialsProvider, or franz-go's SASL mechanisms.
+type CredentialsProvider struct {
+	// usernameKey is the key for username.
+	usernameKey string
+	// passwordKey is the key for password.
+	passwordKey string
+	// resolver resolves secrets references.
+	resolver SecretResolver
+	// errHandler is an optional handler for credentials resolving errors.
+	errHandler func(error)
+	// rotationHandler is an optional handler called when credentials change.
+	rotationHandler func(username string)
+	// current holds the current credentials.
+	current atomic.Pointer[userPass]
+}
+
+// userPass holds a username / password pair.
+type userPass struct {
+	username string
+	password string
+}
+
+// NewCredentialsProvider instantiates a new CredentialsProvider object,
+// reading credentials from given config's keys.
+// An empty key means the corresponding credential is not used.
+// By default, [ResolveSecretRef] resolver is used.
+func NewCredentialsProvider(
+	config Config,
+	usernameKey, passwordKey string,
+	opts ...CredentialsProviderOption,
+) (*CredentialsProvider, error) {
+	provider := &CredentialsProvider{
+		usernameKey: usernameKey,
+		passwordKey: passwordKey,
+		resolver:    ResolveSecretRef,
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(provider)
+	}
+
+	creds, err := provider.read(config)
+	if err != nil {
+		return nil, err
+	}
+	provider.current.Store(creds)
+
+	return provider, nil
+}
+
+// Credentials returns the current username and password.
+func (provider *CredentialsProvider) Credentials() (string, string) {
+	creds := provider.current.Load()
+
+	return creds.username, creds.password
+}
+
+// OnConfigChange is a [ConfigObserver] that refreshes the credentials if their keys changed.
+// If the new credentials cannot be resolved, the current ones are kept,
+// and the error is passed to the error handler, if set.
+func (provider *CredentialsProvider) OnConfigChange(config Config, changedKeys ...string) {
+	for _, changedKey := range changedKeys {
+		if (provider.usernameKey != "" && strings.EqualFold(changedKey, provider.usernameKey)) ||
+			(provider.passwordKey != "" && strings.EqualFold(changedKey, provider.passwordKey)) {
+			provider.refresh(config)
+
+			return
+		}
+	}
+}
+
+// refresh reads again the credentials.
+func (provider *CredentialsProvider) refresh(config Config) {
+	creds, err := provider.read(config)
+	if err != nil {
+		if provider.errHandler != nil {
+			provider.errHandler(err)
+		}
+
+		return
+	}
+	if oldCreds := provider.current.Swap(creds); *oldCreds != *creds && provider.rotationHandler != nil {
+		provider.rotationHandler(creds.username)
+	}
+}
+
+// read reads and resolves the credentials from given config.
+func (provider *CredentialsProvider) read(config Config) (*userPass, error) {
+	var (
+		creds userPass
+		err   error
+	)
+	if provider.usernameKey != "" {
+		creds.username, err = provider.resolver(cast.ToString(config.Get(provider.usernameKey)))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if provider.passwordKey != "" {
+		creds.password, err = provider.resolver(cast.ToString(config.Get(provider.passwordKey)))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &creds, nil
+}
+
+// CredentialsProviderOption defines optional function for configuring
+// a CredentialsProvider object.
+type CredentialsProviderOption func(*CredentialsProvider)
+
+// CredentialsProviderWithSecretResolver sets the secrets references resolver.
+// By default, [ResolveSecretRef] is used.
+func CredentialsProviderWithSecretResolver(resolver SecretResolver) CredentialsProviderOption {
+	return func(provider *CredentialsProvider) {
+		provider.resolver = resolver
+	}
+}
+
+// CredentialsProviderWithErrorHandler sets the handler for errors occurred while
+// resolving rotated credentials.
+// You can choose to log the error, for example with [LogErrorHandler].
+//
+// By default, error is simply ignored.
+func CredentialsProviderWithErrorHandler(errHandler func(error)) CredentialsProviderOption {
+	return func(provider *CredentialsProvider) {
+		provider.errHandler = errHandler
+	}
+}
+
+// CredentialsProviderWithRotationHandler sets the handler called when credentials change.
+// You can use it, for example, to close idle connections opened with the old credentials.
+func CredentialsProviderWithRotationHandler(rotationHandler func(username string)) CredentialsProviderOption {
+	return func(provider *CredentialsProvider) {
+		provider.rotationHandler = rotationHandler
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestCredentialsProvider(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - credentials are rotated", testCredentialsProviderRotation)
+	t.Run("error - rotated credentials cannot be resolved", testCredentialsProviderKeepsOldCredentialsOnErr)
+	t.Run("error - initial credentials cannot be resolved", testCredentialsProviderReturnsErr)
+}
+
+func testCredentialsProviderRotation(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		rotatedUsernames []string
+		config           = xconf.NewMockConfig("db.user", "app", "db.pass", "secret1")
+	)
+	subject, err := xconf.NewCredentialsProvider(
+		config,
+		"db.user",
+		"db.pass",
+		xconf.CredentialsProviderWithRotationHandler(func(username string) {
+			rotatedUsernames = append(rotatedUsernames, username)
+		}),
+	)
+	requireNil(t, err)
+	username, password := subject.Credentials()
+	assertEqual(t, "app", username)
+	assertEqual(t, "secret1", password)
+	config.SetKeyValues("db.pass", "secret2")
+
+	// act
+	subject.OnConfigChange(config, "other.key")
+
+	// assert
+	_, password = subject.Credentials()
+	assertEqual(t, "secret1", password)
+
+	// act
+	subject.OnConfigChange(config, "DB.PASS")
+
+	// assert
+	username, password = subject.Credentials()
+	assertEqual(t, "app", username)
+	assertEqual(t, "secret2", password)
+	assertEqual(t, []string{"app"}, rotatedUsernames)
+}
+
+func testCredentialsProviderKeepsOldCredentialsOnErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		handledErr error
+		config     = xconf.NewMockConfig("db.pass", "secret1")
+	)
+	subject, err := xconf.NewCredentialsProvider(
+		config,
+		"",
+		"db.pass",
+		xconf.CredentialsProviderWithErrorHandler(func(err error) {
+			handledErr = err
+		}),
+	)
+	requireNil(t, err)
+	config.SetKeyValues("db.pass", "env:XCONF_CREDENTIALS_PROVIDER_NOT_SET")
+
+	// act
+	subject.OnConfigChange(config, "db.pass")
+
+	// assert
+	assertTrue(t, errors.Is(handledErr, xconf.ErrSecretNotFound))
+	_, password := subject.Credentials()
+	assertEqual(t, "secret1", password)
+}
+
+func testCredentialsProviderReturnsErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered resolver error")
+		resolver    = func(string) (string, error) {
+			return "", expectedErr
+		}
+	)
+
+	// act
+	subject, err := xconf.NewCredentialsProvider(
+		xconf.NewMockConfig("db.user", "app"),
+		"db.user",
+		"",
+		xconf.CredentialsProviderWithSecretResolver(resolver),
+	)
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertNil(t, subject)
+}
@@ -0,0 +1,139 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"net/http"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	// DebugPprofEnabledKey is the default key for enabling pprof endpoints.
+	DebugPprofEnabledKey = "debug.pprof_enabled"
+	// DebugBlockProfileRateKey is the default key for runtime's block profile rate.
+	DebugBlockProfileRateKey = "debug.block_profile_rate"
+	// DebugMutexProfileFractionKey is the default key for runtime's mutex profile fraction.
+	DebugMutexProfileFractionKey = "debug.mutex_profile_fraction"
+)
+
+// DebugToggles applies common operational debug toggles read from a [Config]
+// to the runtime: enabling pprof endpoints, block and mutex profiling.
+// Register its OnConfigChange method as an observer on a [DefaultConfig]
+// with reload enabled, and toggles can be switched on/off without restarting the app.
+type DebugToggles struct {
+	// pprofEnabledKey is the key for enabling pprof endpoints.
+	pprofEnabledKey string
+	// blockProfileRateKey is the key for block profile rate.
+	blockProfileRateKey string
+	// mutexProfileFractionKey is the key for mutex profile fraction.
+	mutexProfileFractionKey string
+	// pprofEnabled holds the current pprof enabled state.
+	pprofEnabled int32
+}
+
+// NewDebugToggles instantiates a new DebugToggles object.
+// By default, Debug*Key constants are used as keys.
+func NewDebugToggles(opts ...DebugTogglesOption) *DebugToggles {
+	toggles := &DebugToggles{
+		pprofEnabledKey:         DebugPprofEnabledKey,
+		blockProfileRateKey:     DebugBlockProfileRateKey,
+		mutexProfileFractionKey: DebugMutexProfileFractionKey,
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(toggles)
+	}
+
+	return toggles
+}
+
+// Apply applies all toggles from given config.
+// Call it at your application's startup.
+func (toggles *DebugToggles) Apply(config Config) {
+	toggles.applyPprofEnabled(config)
+	toggles.applyBlockProfileRate(config)
+	toggles.applyMutexProfileFraction(config)
+}
+
+// OnConfigChange is a [ConfigObserver] that applies toggles whose keys changed.
+func (toggles *DebugToggles) OnConfigChange(config Config, changedKeys ...string) {
+	for _, changedKey := range changedKeys {
+		switch {
+		case strings.EqualFold(changedKey, toggles.pprofEnabledKey):
+			toggles.applyPprofEnabled(config)
+		case strings.EqualFold(changedKey, toggles.blockProfileRateKey):
+			toggles.applyBlockProfileRate(config)
+		case strings.EqualFold(changedKey, toggles.mutexProfileFractionKey):
+			toggles.applyMutexProfileFraction(config)
+		}
+	}
+}
+
+// PprofEnabled returns true if pprof endpoints are enabled.
+func (toggles *DebugToggles) PprofEnabled() bool {
+	return atomic.LoadInt32(&toggles.pprofEnabled) == 1
+}
+
+// PprofHandler decorates a (pprof) handler to respond with 404 Not Found
+// while pprof is disabled.
+//
+// Example:
+//
+//	mux.Handle("/debug/pprof/", toggles.PprofHandler(http.HandlerFunc(pprof.Index)))
+func (toggles *DebugToggles) PprofHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !toggles.PprofEnabled() {
+			http.NotFound(w, r)
+
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// applyPprofEnabled stores pprof enabled state.
+func (toggles *DebugToggles) applyPprofEnabled(config Config) {
+	var enabled int32
+	if config.Get(toggles.pprofEnabledKey, false).(bool) {
+		enabled = 1
+	}
+	atomic.StoreInt32(&toggles.pprofEnabled, enabled)
+}
+
+// applyBlockProfileRate sets runtime's block profile rate.
+// A missing key means block profiling is turned off.
+func (toggles *DebugToggles) applyBlockProfileRate(config Config) {
+	runtime.SetBlockProfileRate(config.Get(toggles.blockProfileRateKey, 0).(int))
+}
+
+// applyMutexProfileFraction sets runtime's mutex profile fraction.
+// A missing key means mutex profiling is turned off.
+func (toggles *DebugToggles) applyMutexProfileFraction(config Config) {
+	runtime.SetMutexProfileFraction(config.Get(toggles.mutexProfileFractionKey, 0).(int))
+}
+
+// DebugTogglesOption defines optional function for configuring
+// a DebugToggles object.
+type DebugTogglesOption func(*DebugToggles)
+
+// DebugTogglesWithKeys sets custom keys for toggles.
+// An empty key preserves the default one.
+func DebugTogglesWithKeys(pprofEnabledKey, blockProfileRateKey, mutexProfileFractionKey string) DebugTogglesOption {
+	return func(toggles *DebugToggles) {
+		if pprofEnabledKey != "" {
+			toggles.pprofEnabledKey = pprofEnabledKey
+		}
+		if blockProfileRateKey != "" {
+			toggles.blockProfileRateKey = blockProfileRateKey
+		}
+		if mutexProfileFractionKey != "" {
+			toggles.mutexProfileFractionKey = mutexProfileFractionKey
+		}
+	}
+}
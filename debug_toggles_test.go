@@ -0,0 +1,97 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestDebugToggles(t *testing.T) {
+	// Note: do not run this test with t.Parallel() as it modifies runtime profiling state.
+
+	t.Run("apply", testDebugTogglesApply)
+	t.Run("observer", testDebugTogglesOnConfigChange)
+	t.Run("pprof handler", testDebugTogglesPprofHandler)
+}
+
+func testDebugTogglesApply(t *testing.T) {
+	// arrange
+	defer runtime.SetMutexProfileFraction(runtime.SetMutexProfileFraction(-1))
+	defer runtime.SetBlockProfileRate(0)
+	config := xconf.NewMockConfig(
+		"debug.pprof_enabled", "true",
+		"debug.block_profile_rate", "1",
+		"debug.mutex_profile_fraction", 5,
+	)
+	subject := xconf.NewDebugToggles()
+
+	// act
+	subject.Apply(config)
+
+	// assert
+	assertTrue(t, subject.PprofEnabled())
+	assertEqual(t, 5, runtime.SetMutexProfileFraction(-1))
+}
+
+func testDebugTogglesOnConfigChange(t *testing.T) {
+	// arrange
+	defer runtime.SetMutexProfileFraction(runtime.SetMutexProfileFraction(-1))
+	config := xconf.NewMockConfig(
+		"PPROF", true,
+		"MUTEX_FRACTION", 3,
+	)
+	subject := xconf.NewDebugToggles(xconf.DebugTogglesWithKeys("PPROF", "", "MUTEX_FRACTION"))
+
+	// act
+	subject.OnConfigChange(config, "pprof", "mutex_fraction", "some_other_key")
+
+	// assert
+	assertTrue(t, subject.PprofEnabled())
+	assertEqual(t, 3, runtime.SetMutexProfileFraction(-1))
+
+	// arrange
+	config.SetKeyValues("PPROF", false)
+
+	// act
+	subject.OnConfigChange(config, "PPROF")
+
+	// assert
+	assertTrue(t, !subject.PprofEnabled())
+}
+
+func testDebugTogglesPprofHandler(t *testing.T) {
+	// arrange
+	config := xconf.NewMockConfig("debug.pprof_enabled", false)
+	toggles := xconf.NewDebugToggles()
+	toggles.Apply(config)
+	subject := toggles.PprofHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("profile"))
+	}))
+
+	// act
+	rec := httptest.NewRecorder()
+	subject.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+
+	// assert
+	assertEqual(t, http.StatusNotFound, rec.Code)
+
+	// arrange
+	config.SetKeyValues("debug.pprof_enabled", true)
+	toggles.OnConfigChange(config, "debug.pprof_enabled")
+
+	// act
+	rec = httptest.NewRecorder()
+	subject.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+
+	// assert
+	assertEqual(t, http.StatusOK, rec.Code)
+	assertEqual(t, "profile", rec.Body.String())
+}
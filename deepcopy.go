@@ -102,3 +102,8 @@ func deepCopyInterfaceSlice(src []any) []any {
 
 	return dst
 }
+
+// deepCopyValue makes a deep "copy" of a configuration value.
+func deepCopyValue(value any) any {
+	return deepCopyInterfaceSlice([]any{value})[0]
+}
@@ -0,0 +1,228 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/actforgood/xerr"
+	"github.com/joho/godotenv"
+	"github.com/magiconair/properties"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Dump formats.
+const (
+	DumpFormatJSON       = "json"
+	DumpFormatYAML       = "yaml"
+	DumpFormatTOML       = "toml"
+	DumpFormatProperties = "properties"
+	DumpFormatDotEnv     = "env"
+)
+
+var (
+	// ErrUnsupportedDumpFormat is returned by Dump if given format is not supported.
+	ErrUnsupportedDumpFormat = errors.New("unsupported dump format")
+	// ErrConfigNotDumpable is returned by Dump if given Config does not expose its key-values
+	// (only [DefaultConfig] and [MockConfig] do).
+	ErrConfigNotDumpable = errors.New("configuration cannot be dumped")
+)
+
+// DumpOption defines optional function for configuring a dump.
+type DumpOption func(*dumpOptions)
+
+// dumpOptions holds dump's configuration.
+type dumpOptions struct {
+	// redactionPatterns are the patterns of the keys whose values are masked.
+	redactionPatterns []string
+	// redact is a flag indicating whether values should be masked.
+	redact bool
+}
+
+// DumpWithRedaction masks the values of keys matching (case-insensitive) any of the patterns
+// (which follow [path.Match] syntax, like "*password*").
+// If no pattern is provided, [DefaultRedactionPatterns] are used.
+// Values of keys marked as sensitive through keys' metadata (see [KeyMeta]) are masked, too.
+func DumpWithRedaction(patterns ...string) DumpOption {
+	return func(opts *dumpOptions) {
+		opts.redact = true
+		opts.redactionPatterns = patterns
+	}
+}
+
+// Dump serializes the effective configuration of a Config object to given writer,
+// in given format (one of DumpFormat* constants).
+// It is useful for debugging "what configuration did my app actually see",
+// or for generating effective configuration artifacts in CI.
+// Config must be a [DefaultConfig] (or a [MockConfig]), otherwise [ErrConfigNotDumpable] is returned.
+//
+// Example:
+//
+//	err := xconf.Dump(cfg, os.Stdout, xconf.DumpFormatYAML, xconf.DumpWithRedaction())
+func Dump(config Config, w io.Writer, format string, opts ...DumpOption) error {
+	snapshotter, ok := config.(configMapSnapshotter)
+	if !ok {
+		return ErrConfigNotDumpable
+	}
+	configMap := snapshotter.configMapSnapshot()
+	if masker, ok := config.(secretsMasker); ok {
+		masker.maskSecrets(configMap)
+	}
+	provider, _ := config.(keyMetaProvider)
+
+	return dumpConfigMap(configMap, provider, w, format, opts...)
+}
+
+// DumpConfigMap serializes a configuration map to given writer,
+// in given format (one of DumpFormat* constants). See also [Dump].
+func DumpConfigMap(configMap map[string]any, w io.Writer, format string, opts ...DumpOption) error {
+	return dumpConfigMap(DeepCopyConfigMap(configMap), nil, w, format, opts...)
+}
+
+// dumpConfigMap masks, if requested, and serializes given (owned) configuration map.
+func dumpConfigMap(
+	configMap map[string]any,
+	provider keyMetaProvider,
+	w io.Writer,
+	format string,
+	opts ...DumpOption,
+) error {
+	var dumpOpts dumpOptions
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&dumpOpts)
+	}
+	if dumpOpts.redact {
+		redactDumpConfigMap(configMap, provider, dumpOpts.redactionPatterns)
+	}
+
+	return encodeConfigMap(configMap, w, format)
+}
+
+// redactDumpConfigMap masks (in place) values of keys matching any of the patterns
+// ([DefaultRedactionPatterns], if none), and of keys marked as sensitive, if provider is not nil.
+func redactDumpConfigMap(configMap map[string]any, provider keyMetaProvider, patterns []string) {
+	if len(patterns) == 0 {
+		patterns = DefaultRedactionPatterns
+	}
+	redactConfigMap(configMap, patterns)
+	if provider != nil {
+		redactSensitiveKeys(configMap, provider)
+	}
+}
+
+// encodeConfigMap serializes given (owned) configuration map to given writer, in given format.
+func encodeConfigMap(configMap map[string]any, w io.Writer, format string) error {
+	configMap = stringKeysConfigMap(configMap)
+
+	switch format {
+	case DumpFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(configMap)
+	case DumpFormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(configMap); err != nil {
+			return err
+		}
+
+		return enc.Close()
+	case DumpFormatTOML:
+		return toml.NewEncoder(w).Encode(configMap)
+	case DumpFormatProperties:
+		props := properties.NewProperties()
+		for _, key := range sortedKeys(configMap) {
+			if _, _, err := props.Set(key, flatValue(configMap[key])); err != nil {
+				return err
+			}
+		}
+		_, err := props.Write(w, properties.UTF8)
+
+		return err
+	case DumpFormatDotEnv:
+		envs := make(map[string]string, len(configMap))
+		for key, value := range configMap {
+			envs[key] = flatValue(value)
+		}
+		content, err := godotenv.Marshal(envs)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, content+"\n")
+
+		return err
+	default:
+		return xerr.Wrapf(ErrUnsupportedDumpFormat, "%q", format)
+	}
+}
+
+// stringKeysConfigMap converts (in place) nested map[any]any values (produced by YAML decoder)
+// to map[string]any, as not all encoders support them.
+func stringKeysConfigMap(configMap map[string]any) map[string]any {
+	for key, value := range configMap {
+		configMap[key] = stringKeysValue(value)
+	}
+
+	return configMap
+}
+
+// stringKeysValue converts map[any]any values (even nested) to map[string]any.
+func stringKeysValue(value any) any {
+	switch val := value.(type) {
+	case map[string]any:
+		return stringKeysConfigMap(val)
+	case map[any]any:
+		configMap := make(map[string]any, len(val))
+		for nestedKey, nestedValue := range val {
+			configMap[fmt.Sprint(nestedKey)] = stringKeysValue(nestedValue)
+		}
+
+		return configMap
+	case []any:
+		for idx, item := range val {
+			val[idx] = stringKeysValue(item)
+		}
+
+		return val
+	default:
+		return value
+	}
+}
+
+// flatValue returns the string representation of a value, for flat formats
+// (properties / dotenv). Maps and slices are JSON encoded.
+func flatValue(value any) string {
+	switch val := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case map[string]any, []any, []string, []int:
+		if content, err := json.Marshal(val); err == nil {
+			return string(content)
+		}
+	}
+
+	return fmt.Sprint(value)
+}
+
+// sortedKeys returns configuration map's keys, sorted.
+func sortedKeys(configMap map[string]any) []string {
+	keys := make([]string, 0, len(configMap))
+	for key := range configMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
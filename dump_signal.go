@@ -0,0 +1,205 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// signalDumpFilePermission is the permission of the file the signal dump is written to,
+// as configuration may contain secrets (even if redacted, key names may be sensitive).
+const signalDumpFilePermission = 0o600
+
+// SignalDumpOption defines optional function for configuring [DumpOnSignal].
+type SignalDumpOption func(*signalDumper)
+
+// signalDumper writes the effective configuration, and status, on signal.
+type signalDumper struct {
+	config            Config
+	signals           []os.Signal
+	w                 io.Writer
+	filePath          string
+	format            string
+	redactionPatterns []string
+	errHandler        func(error)
+}
+
+// SignalDumpWithSignals sets the signals triggering the dump.
+// By default, SIGUSR1 is used (on platforms not having it, like Windows, a signal must be set).
+func SignalDumpWithSignals(signals ...os.Signal) SignalDumpOption {
+	return func(dumper *signalDumper) {
+		dumper.signals = signals
+	}
+}
+
+// SignalDumpWithWriter sets the writer the dump is written to.
+// By default, [os.Stderr] is used.
+func SignalDumpWithWriter(w io.Writer) SignalDumpOption {
+	return func(dumper *signalDumper) {
+		dumper.w = w
+	}
+}
+
+// SignalDumpWithFile sets the file the dump is written to (it is overwritten on each signal,
+// with 0600 permissions), instead of the writer.
+func SignalDumpWithFile(filePath string) SignalDumpOption {
+	return func(dumper *signalDumper) {
+		dumper.filePath = filePath
+	}
+}
+
+// SignalDumpWithFormat sets the dump's format (one of DumpFormat* constants).
+// By default, [DumpFormatYAML] is used.
+func SignalDumpWithFormat(format string) SignalDumpOption {
+	return func(dumper *signalDumper) {
+		dumper.format = format
+	}
+}
+
+// SignalDumpWithRedactionPatterns sets the patterns of the keys whose values are masked.
+// By default, [DefaultRedactionPatterns] are used. See [DumpWithRedaction].
+func SignalDumpWithRedactionPatterns(patterns ...string) SignalDumpOption {
+	return func(dumper *signalDumper) {
+		dumper.redactionPatterns = patterns
+	}
+}
+
+// SignalDumpWithErrorHandler sets a handler for dump errors.
+// You can log the error, for example. By default, errors are ignored.
+func SignalDumpWithErrorHandler(errHandler func(error)) SignalDumpOption {
+	return func(dumper *signalDumper) {
+		dumper.errHandler = errHandler
+	}
+}
+
+// DumpOnSignal installs (opt-in) a handler which, on SIGUSR1 (or on chosen signals), writes
+// the current redacted effective configuration and config's status (time, pid, number of keys,
+// and, for a [DefaultConfig], closed state, reload interval, number of overrides, sources' versions)
+// to stderr (or to a chosen writer / file), giving operators a zero-downtime way to inspect
+// what a running process believes its config to be.
+// The dump is written from a separate goroutine, not from the signal handler.
+//
+// The returned stop function uninstalls the handler. It is safe to be called multiple times.
+//
+// Usage example:
+//
+//	stop := xconf.DumpOnSignal(cfg, xconf.SignalDumpWithFile("/tmp/app-config.yaml"))
+//	defer stop()
+//	// and, from a shell: kill -USR1 <pid> && cat /tmp/app-config.yaml
+func DumpOnSignal(config Config, opts ...SignalDumpOption) (stop func()) {
+	dumper := &signalDumper{
+		config:  config,
+		signals: defaultDumpSignals(),
+		w:       os.Stderr,
+		format:  DumpFormatYAML,
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(dumper)
+	}
+
+	if len(dumper.signals) == 0 {
+		return func() {}
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigChan, dumper.signals...)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-sigChan:
+				if err := dumper.dump(); err != nil && dumper.errHandler != nil {
+					dumper.errHandler(err)
+				}
+			}
+		}
+	}()
+
+	var stopOnce sync.Once
+
+	return func() {
+		stopOnce.Do(func() {
+			signal.Stop(sigChan)
+			close(done)
+		})
+	}
+}
+
+// dump writes the redacted effective configuration and status.
+func (dumper *signalDumper) dump() error {
+	snapshotter, ok := dumper.config.(configMapSnapshotter)
+	if !ok {
+		return ErrConfigNotDumpable
+	}
+	configMap := snapshotter.configMapSnapshot()
+	if masker, ok := dumper.config.(secretsMasker); ok {
+		masker.maskSecrets(configMap)
+	}
+	provider, _ := dumper.config.(keyMetaProvider)
+	redactDumpConfigMap(configMap, provider, dumper.redactionPatterns)
+	doc := map[string]any{
+		"status": dumper.status(len(configMap)),
+		"config": configMap,
+	}
+
+	if dumper.filePath == "" {
+		return encodeConfigMap(doc, dumper.w, dumper.format)
+	}
+
+	var buf bytes.Buffer
+	if err := encodeConfigMap(doc, &buf, dumper.format); err != nil {
+		return err
+	}
+	tmpFilePath := dumper.filePath + ".tmp"
+	if err := os.WriteFile(tmpFilePath, buf.Bytes(), signalDumpFilePermission); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpFilePath, dumper.filePath); err != nil {
+		_ = os.Remove(tmpFilePath)
+
+		return err
+	}
+
+	return nil
+}
+
+// status returns config's status.
+func (dumper *signalDumper) status(keysCnt int) map[string]any {
+	status := map[string]any{
+		"time": time.Now().UTC().Format(time.RFC3339),
+		"pid":  os.Getpid(),
+		"keys": keysCnt,
+	}
+	if cfg, ok := dumper.config.(*DefaultConfig); ok {
+		status["closed"] = cfg.Closed()
+		status["reload_interval"] = cfg.reloadInterval.String()
+		status["overrides"] = len(cfg.Overrides())
+		var versions []SourceVersion
+		collectSourceVersions(cfg.loader, &versions)
+		if len(versions) > 0 {
+			sources := make([]any, len(versions))
+			for idx, version := range versions {
+				sources[idx] = map[string]any{
+					"kind":    version.Kind,
+					"name":    version.Name,
+					"version": version.Version,
+				}
+			}
+			status["sources"] = sources
+		}
+	}
+
+	return status
+}
@@ -0,0 +1,16 @@
+//go:build js || windows || plan9
+
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import "os"
+
+// defaultDumpSignals returns the default signals triggering a [DumpOnSignal].
+// SIGUSR1 is not available on this platform, signals must be set explicitly.
+func defaultDumpSignals() []os.Signal {
+	return nil
+}
@@ -0,0 +1,143 @@
+//go:build !js && !windows && !plan9
+
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+// syncBuffer is a concurrent safe bytes.Buffer.
+type syncBuffer struct {
+	buf bytes.Buffer
+	mu  sync.Mutex
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]byte(nil), b.buf.Bytes()...)
+}
+
+func TestDumpOnSignal(t *testing.T) { // Note: not parallel, signals are process wide.
+	t.Run("success - redacted config and status are written to file", testDumpOnSignalWritesFile)
+	t.Run("success - redacted config and status are written to writer", testDumpOnSignalWritesToWriter)
+	t.Run("error - config not dumpable", testDumpOnSignalReturnsErrConfigNotDumpable)
+}
+
+func testDumpOnSignalWritesFile(t *testing.T) {
+	// arrange
+	config, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{
+			"db.host":     "127.0.0.1",
+			"db.password": "secret",
+		}),
+	)
+	requireNil(t, err)
+	filePath := filepath.Join(t.TempDir(), "config-dump.json")
+	stop := xconf.DumpOnSignal(
+		config,
+		xconf.SignalDumpWithFile(filePath),
+		xconf.SignalDumpWithFormat(xconf.DumpFormatJSON),
+	)
+	defer stop()
+
+	// act
+	requireNil(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+
+	// assert
+	var content []byte
+	for i := 0; i < 100 && len(content) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+		content, _ = os.ReadFile(filePath)
+	}
+	var doc map[string]map[string]any
+	requireNil(t, json.Unmarshal(content, &doc))
+	assertEqual(t, map[string]any{"db.host": "127.0.0.1", "db.password": "*****"}, doc["config"])
+	assertEqual(t, float64(os.Getpid()), doc["status"]["pid"])
+	assertEqual(t, float64(2), doc["status"]["keys"])
+	assertEqual(t, false, doc["status"]["closed"])
+	assertEqual(t, "0s", doc["status"]["reload_interval"])
+	assertEqual(t, float64(0), doc["status"]["overrides"])
+	assertNotNil(t, doc["status"]["time"])
+	info, err := os.Stat(filePath)
+	if assertNil(t, err) {
+		assertEqual(t, os.FileMode(0o600), info.Mode().Perm())
+	}
+}
+
+func testDumpOnSignalWritesToWriter(t *testing.T) {
+	// arrange
+	config := xconf.NewMockConfig("api_token", "secret", "name", "app")
+	buf := new(syncBuffer)
+	stop := xconf.DumpOnSignal(
+		config,
+		xconf.SignalDumpWithSignals(syscall.SIGUSR2),
+		xconf.SignalDumpWithWriter(buf),
+		xconf.SignalDumpWithFormat(xconf.DumpFormatJSON),
+		xconf.SignalDumpWithRedactionPatterns("*token*"),
+	)
+	defer stop()
+	defer stop() // idempotent
+
+	// act
+	requireNil(t, syscall.Kill(os.Getpid(), syscall.SIGUSR2))
+
+	// assert
+	var content []byte
+	for i := 0; i < 100 && len(content) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+		content = buf.Bytes()
+	}
+	var doc map[string]map[string]any
+	requireNil(t, json.Unmarshal(content, &doc))
+	assertEqual(t, map[string]any{"api_token": "*****", "name": "app"}, doc["config"])
+	assertEqual(t, float64(2), doc["status"]["keys"])
+	_, found := doc["status"]["closed"]
+	assertTrue(t, !found)
+}
+
+func testDumpOnSignalReturnsErrConfigNotDumpable(t *testing.T) {
+	// arrange
+	errChan := make(chan error, 1)
+	stop := xconf.DumpOnSignal(
+		xconf.NopConfig{},
+		xconf.SignalDumpWithErrorHandler(func(err error) {
+			errChan <- err
+		}),
+	)
+	defer stop()
+
+	// act
+	requireNil(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+
+	// assert
+	select {
+	case err := <-errChan:
+		assertTrue(t, errors.Is(err, xconf.ErrConfigNotDumpable))
+	case <-time.After(time.Second):
+		t.Error("expected dump error")
+	}
+}
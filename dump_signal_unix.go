@@ -0,0 +1,18 @@
+//go:build !js && !windows && !plan9
+
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"os"
+	"syscall"
+)
+
+// defaultDumpSignals returns the default signals triggering a [DumpOnSignal].
+func defaultDumpSignals() []os.Signal {
+	return []os.Signal{syscall.SIGUSR1}
+}
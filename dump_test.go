@@ -0,0 +1,147 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestDump(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - json", testDumpFormat(xconf.DumpFormatJSON, `{
+  "app.name": "demo",
+  "db": {
+    "password": "*****",
+    "port": 3306
+  },
+  "db.password": "*****",
+  "tags": [
+    "a",
+    "b"
+  ]
+}
+`))
+	t.Run("success - yaml", testDumpFormat(xconf.DumpFormatYAML, `app.name: demo
+db:
+  password: '*****'
+  port: 3306
+db.password: '*****'
+tags:
+  - a
+  - b
+`))
+	t.Run("success - toml", testDumpFormat(xconf.DumpFormatTOML, `'app.name' = 'demo'
+'db.password' = '*****'
+tags = ['a', 'b']
+
+[db]
+password = '*****'
+port = 3306
+`))
+	t.Run("success - properties", testDumpFormat(xconf.DumpFormatProperties, `app.name = demo
+db = {"password":"*****","port":3306}
+db.password = *****
+tags = ["a","b"]
+`))
+	t.Run("success - dotenv", testDumpFormat(xconf.DumpFormatDotEnv, `app.name="demo"
+db.password="*****"
+db="{\"password\":\"*****\",\"port\":3306}"
+tags="[\"a\",\"b\"]"
+`))
+	t.Run("success - sensitive keys from metadata", testDumpMasksSensitiveKeys)
+	t.Run("error - unsupported format", testDumpReturnsErrUnsupportedDumpFormat)
+	t.Run("error - config not dumpable", testDumpReturnsErrConfigNotDumpable)
+}
+
+func testDumpFormat(format, expectedOutput string) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var (
+			configMap = map[string]any{
+				"app.name":    "demo",
+				"db.password": "s3cr3t",
+				"db":          map[any]any{"port": 3306, "password": "s3cr3t"},
+				"tags":        []any{"a", "b"},
+			}
+			config = xconf.NewMockConfig(
+				"app.name", "demo",
+				"db.password", "s3cr3t",
+				"db", map[any]any{"port": 3306, "password": "s3cr3t"},
+				"tags", []any{"a", "b"},
+			)
+			buf1, buf2 bytes.Buffer
+		)
+
+		// act
+		err1 := xconf.Dump(config, &buf1, format, xconf.DumpWithRedaction())
+		err2 := xconf.DumpConfigMap(configMap, &buf2, format, xconf.DumpWithRedaction("*password"))
+
+		// assert
+		assertNil(t, err1)
+		assertNil(t, err2)
+		assertEqual(t, expectedOutput, buf1.String())
+		assertEqual(t, expectedOutput, buf2.String())
+		assertEqual(t, "s3cr3t", configMap["db.password"]) // original is not altered
+	}
+}
+
+func testDumpMasksSensitiveKeys(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{"app.name": "demo", "license": "ABC-123"}),
+		xconf.DefaultConfigWithKeysMetadata(xconf.KeysMetadata{
+			"license": {Sensitive: true},
+		}),
+	)
+	requireNil(t, err)
+	var buf1, buf2 bytes.Buffer
+
+	// act
+	err1 := xconf.Dump(config, &buf1, xconf.DumpFormatDotEnv, xconf.DumpWithRedaction())
+	err2 := xconf.Dump(config, &buf2, xconf.DumpFormatDotEnv)
+
+	// assert
+	assertNil(t, err1)
+	assertNil(t, err2)
+	assertEqual(t, "app.name=\"demo\"\nlicense=\"*****\"\n", buf1.String())
+	assertEqual(t, "app.name=\"demo\"\nlicense=\"ABC-123\"\n", buf2.String())
+}
+
+func testDumpReturnsErrUnsupportedDumpFormat(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var buf bytes.Buffer
+
+	// act
+	err := xconf.DumpConfigMap(map[string]any{"foo": "bar"}, &buf, "xml")
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrUnsupportedDumpFormat))
+	assertEqual(t, 0, buf.Len())
+}
+
+func testDumpReturnsErrConfigNotDumpable(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var buf bytes.Buffer
+
+	// act
+	err := xconf.Dump(xconf.NopConfig{}, &buf, xconf.DumpFormatJSON)
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrConfigNotDumpable))
+}
@@ -0,0 +1,121 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"io"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// decodeBytes transcodes content from given source encoding to UTF-8.
+// If no encoding is provided, content is returned as it is.
+func decodeBytes(content []byte, enc encoding.Encoding) ([]byte, error) {
+	if enc == nil {
+		return content, nil
+	}
+
+	return enc.NewDecoder().Bytes(content)
+}
+
+// decodeReader returns a reader transcoding content from given source encoding to UTF-8.
+// If no encoding is provided, reader is returned as it is.
+func decodeReader(reader io.Reader, enc encoding.Encoding) io.Reader {
+	if enc == nil {
+		return reader
+	}
+
+	return transform.NewReader(reader, enc.NewDecoder())
+}
+
+// NormalizedTextEncoding returns an encoding whose decoder, after transcoding content
+// from given source encoding (if any) to UTF-8, strips a leading byte order mark
+// and converts CRLF / CR line endings to LF.
+// Without a source encoding, UTF-8 (and UTF-16 with BOM) content is expected.
+// Pass it to [DotEnvLoaderWithEncoding], [PropertiesLoaderWithEncoding],
+// [IniFileLoaderWithEncoding] or [FileLoaderWithEncoding] to have files edited
+// on Windows hosts parsed like the ones edited on Unix hosts.
+//
+// Example:
+//
+//	loader := xconf.PropertiesFileLoader(
+//		"C:\\app\\config.properties",
+//		xconf.PropertiesLoaderWithEncoding(xconf.NormalizedTextEncoding()),
+//	)
+func NormalizedTextEncoding(enc ...encoding.Encoding) encoding.Encoding {
+	normalizedEnc := normalizedTextEncoding{}
+	if len(enc) > 0 {
+		normalizedEnc.base = enc[0]
+	}
+
+	return normalizedEnc
+}
+
+// normalizedTextEncoding is the [encoding.Encoding] returned by NormalizedTextEncoding.
+type normalizedTextEncoding struct {
+	// base is the optional source encoding.
+	base encoding.Encoding
+}
+
+// NewDecoder returns a decoder which transcodes content with the base encoding,
+// strips the BOM and normalizes line endings.
+func (enc normalizedTextEncoding) NewDecoder() *encoding.Decoder {
+	transformers := make([]transform.Transformer, 0, 3)
+	if enc.base != nil {
+		transformers = append(transformers, enc.base.NewDecoder())
+	}
+	transformers = append(
+		transformers,
+		unicode.BOMOverride(transform.Nop),
+		lineEndingsNormalizer{},
+	)
+
+	return &encoding.Decoder{Transformer: transform.Chain(transformers...)}
+}
+
+// NewEncoder returns base encoding's encoder, if any.
+func (enc normalizedTextEncoding) NewEncoder() *encoding.Encoder {
+	if enc.base != nil {
+		return enc.base.NewEncoder()
+	}
+
+	return encoding.Nop.NewEncoder()
+}
+
+// lineEndingsNormalizer is a [transform.Transformer] converting CRLF / CR line endings to LF.
+type lineEndingsNormalizer struct {
+	transform.NopResetter
+}
+
+// Transform implements [transform.Transformer].
+func (lineEndingsNormalizer) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		if nDst >= len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		c := src[nSrc]
+		if c != '\r' {
+			dst[nDst] = c
+			nDst++
+			nSrc++
+
+			continue
+		}
+		if nSrc+1 == len(src) && !atEOF {
+			return nDst, nSrc, transform.ErrShortSrc // need to see if a LF follows.
+		}
+		dst[nDst] = '\n'
+		nDst++
+		nSrc++
+		if nSrc < len(src) && src[nSrc] == '\n' {
+			nSrc++
+		}
+	}
+
+	return nDst, nSrc, nil
+}
@@ -0,0 +1,126 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/actforgood/xconf"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+func TestNormalizedTextEncoding(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - dotenv, properties, ini", testNormalizedTextEncodingWithTextFormats)
+	t.Run("success - with source encoding", testNormalizedTextEncodingWithSourceEncoding)
+	t.Run("success - line endings", testNormalizedTextEncodingLineEndings)
+}
+
+func testNormalizedTextEncodingWithTextFormats(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		dotEnvContent     = "\ufeffFOO=bar\r\nYEAR=\"20\r\n22\"\r\n"
+		propertiesContent = "\ufefffoo=bar\r\nshopping_list=bread,\\\r\n  milk\r\n"
+		iniContent        = "\ufefffoo=bar\r\n[section]\r\nyear=2022\r\n"
+		iniFilePath       = filepath.Join(t.TempDir(), "config.ini")
+		enc               = xconf.NormalizedTextEncoding()
+	)
+	requireNil(t, os.WriteFile(iniFilePath, []byte(iniContent), 0o600))
+	tests := [...]struct {
+		name           string
+		subject        xconf.Loader
+		expectedResult map[string]any
+	}{
+		{
+			name:           "dotenv",
+			subject:        xconf.DotEnvReaderLoader(bytes.NewReader([]byte(dotEnvContent)), xconf.DotEnvLoaderWithEncoding(enc)),
+			expectedResult: map[string]any{"FOO": "bar", "YEAR": "20\n22"},
+		},
+		{
+			name:           "properties",
+			subject:        xconf.PropertiesBytesLoader([]byte(propertiesContent), xconf.PropertiesLoaderWithEncoding(enc)),
+			expectedResult: map[string]any{"foo": "bar", "shopping_list": "bread,milk"},
+		},
+		{
+			name:           "ini",
+			subject:        xconf.NewIniFileLoader(iniFilePath, xconf.IniFileLoaderWithEncoding(enc)),
+			expectedResult: map[string]any{"foo": "bar", "section": map[string]any{"year": "2022"}},
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			config, err := test.subject.Load()
+
+			// assert
+			assertNil(t, err)
+			assertEqual(t, test.expectedResult, config)
+		})
+	}
+}
+
+func testNormalizedTextEncodingWithSourceEncoding(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	utf16Content, err := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().Bytes(
+		[]byte("CITY=Zürich\r\nYEAR=2022\r\n"),
+	)
+	requireNil(t, err)
+	latin1Content, err := charmap.ISO8859_1.NewEncoder().Bytes([]byte("city=Zürich\r\nyear=2022\r\n"))
+	requireNil(t, err)
+
+	// act
+	utf16Config, utf16Err := xconf.DotEnvReaderLoader(
+		bytes.NewReader(utf16Content),
+		xconf.DotEnvLoaderWithEncoding(xconf.NormalizedTextEncoding()),
+	).Load()
+	latin1Config, latin1Err := xconf.PropertiesBytesLoader(
+		latin1Content,
+		xconf.PropertiesLoaderWithEncoding(xconf.NormalizedTextEncoding(charmap.ISO8859_1)),
+	).Load()
+
+	// assert
+	assertNil(t, utf16Err)
+	assertEqual(t, map[string]any{"CITY": "Zürich", "YEAR": "2022"}, utf16Config)
+	assertNil(t, latin1Err)
+	assertEqual(t, map[string]any{"city": "Zürich", "year": "2022"}, latin1Config)
+}
+
+func testNormalizedTextEncodingLineEndings(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	content := bytes.Repeat([]byte("a\r\nb\rc\n"), 2000) // bigger than transform's internal buffer.
+	expected := bytes.Repeat([]byte("a\nb\nc\n"), 2000)
+	subject := xconf.NormalizedTextEncoding()
+
+	// act
+	result, _, err := transform.Bytes(subject.NewDecoder(), content)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, expected, result)
+
+	// act
+	result, _, err = transform.Bytes(subject.NewDecoder(), []byte("a\r"))
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, []byte("a\n"), result)
+}
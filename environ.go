@@ -0,0 +1,105 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// environOptions holds ToEnviron's options.
+type environOptions struct {
+	separator     string
+	listSeparator string
+	keyCase       func(string) string
+}
+
+// ToEnvironOption defines optional function for configuring [ToEnviron].
+type ToEnvironOption func(*environOptions)
+
+// ToEnvironWithSeparator sets the separator used for nested keys, and
+// to replace "." in keys. By default, "_" is used.
+func ToEnvironWithSeparator(separator string) ToEnvironOption {
+	return func(opts *environOptions) {
+		opts.separator = separator
+	}
+}
+
+// ToEnvironWithListSeparator sets the separator used to join slices' items.
+// By default, "," is used (like [ToStringList] would split it back).
+func ToEnvironWithListSeparator(listSeparator string) ToEnvironOption {
+	return func(opts *environOptions) {
+		opts.listSeparator = listSeparator
+	}
+}
+
+// ToEnvironWithKeyCase sets the case mapping function applied on env variables' names.
+// By default, [strings.ToUpper] is used. Pass a function returning its input,
+// to preserve keys' case.
+func ToEnvironWithKeyCase(keyCase func(string) string) ToEnvironOption {
+	return func(opts *environOptions) {
+		opts.keyCase = keyCase
+	}
+}
+
+// ToEnviron converts the (flattened) configuration into "KEY=VALUE" pairs, sorted by key,
+// suitable for [os/exec.Cmd]'s Env. It's the inverse of [EnvLoader], needed when spawning
+// child processes that expect env based configuration.
+//
+// Nested keys are joined with the separator (by default "_"), and given prefix
+// (if not empty) is prepended, followed by the separator.
+// Example: prefix "APP", {"db": {"host": "x"}} => "APP_DB_HOST=x".
+// Slices' items are joined with the list separator (by default ",").
+//
+// Config must be a [DefaultConfig] (or a [MockConfig]), otherwise nil is returned.
+//
+// Example:
+//
+//	cmd := exec.Command("worker")
+//	cmd.Env = append(os.Environ(), xconf.ToEnviron(cfg, "WORKER")...)
+func ToEnviron(config Config, prefix string, opts ...ToEnvironOption) []string {
+	snapshotter, ok := config.(configMapSnapshotter)
+	if !ok {
+		return nil
+	}
+	options := environOptions{
+		separator:     "_",
+		listSeparator: ",",
+		keyCase:       strings.ToUpper,
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if prefix != "" && !strings.HasSuffix(prefix, options.separator) {
+		prefix += options.separator
+	}
+	environ := make([]string, 0)
+	appendEnviron(&environ, prefix, snapshotter.configMapSnapshot(), options)
+	sort.Strings(environ)
+
+	return environ
+}
+
+// appendEnviron appends (recursively, for nested maps) "KEY=VALUE" pairs to environ.
+func appendEnviron(environ *[]string, prefix string, configMap map[string]any, opts environOptions) {
+	for key, value := range configMap {
+		name := prefix + strings.ReplaceAll(key, ".", opts.separator)
+		switch val := value.(type) {
+		case map[string]any:
+			appendEnviron(environ, name+opts.separator, val, opts)
+		case []any, []string, []int:
+			items := cast.ToStringSlice(val)
+			*environ = append(*environ, opts.keyCase(name)+"="+strings.Join(items, opts.listSeparator))
+		default:
+			*environ = append(*environ, opts.keyCase(name)+"="+cast.ToString(val))
+		}
+	}
+}
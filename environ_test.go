@@ -0,0 +1,103 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestToEnviron(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - default options", testToEnvironWithDefaultOptions)
+	t.Run("success - custom options", testToEnvironWithCustomOptions)
+	t.Run("success - not supported config", testToEnvironWithNotSupportedConfig)
+}
+
+func testToEnvironWithDefaultOptions(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config := xconf.NewMockConfig(
+		"db", map[string]any{
+			"host":  "10.0.0.1",
+			"port":  3306,
+			"hosts": []any{"a", "b"},
+		},
+		"log.level", "debug",
+		"timeout", 5*time.Second,
+		"debug", true,
+		"empty", nil,
+	)
+
+	// act
+	environ := xconf.ToEnviron(config, "APP")
+
+	// assert
+	assertEqual(
+		t,
+		[]string{
+			"APP_DB_HOST=10.0.0.1",
+			"APP_DB_HOSTS=a,b",
+			"APP_DB_PORT=3306",
+			"APP_DEBUG=true",
+			"APP_EMPTY=",
+			"APP_LOG_LEVEL=debug",
+			"APP_TIMEOUT=5s",
+		},
+		environ,
+	)
+}
+
+func testToEnvironWithCustomOptions(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config := xconf.NewMockConfig(
+		"Db", map[string]any{"Host": "10.0.0.1"},
+		"tags", []string{"a", "b"},
+	)
+
+	// act
+	environ := xconf.ToEnviron(
+		config,
+		"",
+		xconf.ToEnvironWithSeparator("__"),
+		xconf.ToEnvironWithListSeparator(" "),
+		xconf.ToEnvironWithKeyCase(func(key string) string { return key }),
+	)
+
+	// assert
+	assertEqual(t, []string{"Db__Host=10.0.0.1", "tags=a b"}, environ)
+}
+
+func testToEnvironWithNotSupportedConfig(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config := xconf.NopConfig{}
+
+	// act
+	environ := xconf.ToEnviron(config, "APP")
+
+	// assert
+	assertNil(t, environ)
+}
+
+func ExampleToEnviron() {
+	config := xconf.NewMockConfig(
+		"db", map[string]any{"host": "10.0.0.1", "port": 3306},
+	)
+
+	fmt.Println(xconf.ToEnviron(config, "WORKER"))
+
+	// Output:
+	// [WORKER_DB_HOST=10.0.0.1 WORKER_DB_PORT=3306]
+}
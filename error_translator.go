@@ -0,0 +1,181 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// ErrorMessagePlaceholder is the placeholder which gets replaced with
+// original error's message (which holds the details), in an [ErrorMessageCatalog]'s message.
+const ErrorMessagePlaceholder = "{error}"
+
+// ErrorTranslator translates / formats an error into a user-facing message.
+type ErrorTranslator func(err error) string
+
+// ErrorMessageCatalog holds user-facing messages, keyed by (sentinel) error,
+// like [ErrUnknownConfigFileExt], [ErrPathOutsideBaseDir], [os.ErrNotExist],
+// or your own validation errors (see [Builder.Validate]).
+// A message can contain the [ErrorMessagePlaceholder].
+//
+// Example:
+//
+//	catalog := xconf.ErrorMessageCatalog{
+//		xconf.ErrUnknownConfigFileExt: "Format de fichier de configuration inconnu.",
+//		os.ErrNotExist:                "Fichier de configuration introuvable ({error}).",
+//	}
+type ErrorMessageCatalog map[error]string
+
+// Translate returns the message of the first error found in the catalog,
+// walking err's chain (errors wrapped with [errors.Unwrap]).
+// Each error of a multi error ([xerr.MultiError], [errors.Join]) is translated, messages being new line separated.
+// If no error is found in the catalog, original error's message is returned.
+// Translate is an [ErrorTranslator].
+func (catalog ErrorMessageCatalog) Translate(err error) string {
+	if err == nil {
+		return ""
+	}
+	for currErr := err; currErr != nil; currErr = errors.Unwrap(currErr) {
+		if errs := unwrapMultiError(currErr); errs != nil {
+			messages := make([]string, 0, len(errs))
+			for _, e := range errs {
+				messages = append(messages, catalog.Translate(e))
+			}
+
+			return strings.Join(messages, "\n")
+		}
+		if message, found := catalog.lookup(currErr); found {
+			return strings.ReplaceAll(message, ErrorMessagePlaceholder, err.Error())
+		}
+	}
+
+	return err.Error()
+}
+
+// lookup returns the message of given error, or of the catalog's error it is equivalent
+// to through its Is method (like [syscall.Errno] for [os.ErrNotExist]).
+func (catalog ErrorMessageCatalog) lookup(err error) (string, bool) {
+	if reflect.TypeOf(err).Comparable() { // otherwise, it cannot be a map key.
+		if message, found := catalog[err]; found {
+			return message, true
+		}
+	}
+	isErr, ok := err.(interface{ Is(error) bool })
+	if !ok {
+		return "", false
+	}
+	var (
+		message string
+		found   bool
+	)
+	for catalogErr, catalogMsg := range catalog {
+		if isErr.Is(catalogErr) && (!found || catalogMsg < message) { // be deterministic.
+			message, found = catalogMsg, true
+		}
+	}
+
+	return message, found
+}
+
+// unwrapMultiError returns the errors of a multi error, or nil if given error is not a multi error.
+func unwrapMultiError(err error) []error {
+	switch mErr := err.(type) {
+	case interface{ Errors() []error }: // xerr.MultiError
+		return mErr.Errors()
+	case interface{ Unwrap() []error }: // errors.Join
+		return mErr.Unwrap()
+	}
+
+	return nil
+}
+
+// LocalizedErrorMessageCatalogs holds [ErrorMessageCatalog]s, by language.
+type LocalizedErrorMessageCatalogs map[language.Tag]ErrorMessageCatalog
+
+// Translator returns the [ErrorTranslator] of the catalog best matching the preferred languages
+// (which can be obtained, for example, with [language.ParseAcceptLanguage]).
+// If no catalog matches, original error's message is returned by the translator.
+//
+// Example:
+//
+//	catalogs := xconf.LocalizedErrorMessageCatalogs{
+//		language.French: frCatalog,
+//		language.German: deCatalog,
+//	}
+//	preferred, _, _ := language.ParseAcceptLanguage("fr-CH, fr;q=0.9, en;q=0.8")
+//	message := catalogs.Translator(preferred...)(err)
+func (catalogs LocalizedErrorMessageCatalogs) Translator(preferred ...language.Tag) ErrorTranslator {
+	if len(catalogs) > 0 && len(preferred) > 0 {
+		tags := make([]language.Tag, 0, len(catalogs)+1)
+		tags = append(tags, language.Und) // fallback, if no catalog matches.
+		for tag := range catalogs {
+			tags = append(tags, tag)
+		}
+		if _, idx, confidence := language.NewMatcher(tags).Match(preferred...); idx > 0 && confidence > language.No {
+			return catalogs[tags[idx]].Translate
+		}
+	}
+
+	return func(err error) string {
+		if err == nil {
+			return ""
+		}
+
+		return err.Error()
+	}
+}
+
+// TranslateError returns an error whose message is the translated one,
+// original error being still reachable with [errors.Is] / [errors.As].
+// It returns nil if given error is nil.
+//
+// Example:
+//
+//	config, err := xconf.NewDefaultConfig(loader)
+//	if err != nil {
+//		return xconf.TranslateError(err, catalog.Translate) // shown to an operator.
+//	}
+func TranslateError(err error, translator ErrorTranslator) error {
+	if err == nil {
+		return nil
+	}
+
+	return translatedError{origErr: err, msg: translator(err)}
+}
+
+// TranslatingErrorHandler decorates an error handler (like the one set with
+// [DefaultConfigWithReloadErrorHandler]) to receive translated errors, see [TranslateError].
+//
+// Example:
+//
+//	xconf.DefaultConfigWithReloadErrorHandler(
+//		xconf.TranslatingErrorHandler(catalog.Translate, xconf.LogErrorHandler(loggerGetter)),
+//	)
+func TranslatingErrorHandler(translator ErrorTranslator, errHandler func(error)) func(error) {
+	return func(err error) {
+		errHandler(TranslateError(err, translator))
+	}
+}
+
+// translatedError is an error with a translated message, wrapping the original error.
+type translatedError struct {
+	origErr error
+	msg     string
+}
+
+// Error returns the translated message.
+func (err translatedError) Error() string {
+	return err.msg
+}
+
+// Unwrap returns the original error.
+func (err translatedError) Unwrap() error {
+	return err.origErr
+}
@@ -0,0 +1,171 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/actforgood/xconf"
+	"github.com/actforgood/xerr"
+	"golang.org/x/text/language"
+)
+
+var frErrorMessageCatalog = xconf.ErrorMessageCatalog{
+	xconf.ErrUnknownConfigFileExt: "Format de fichier de configuration inconnu.",
+	os.ErrNotExist:                "Fichier de configuration introuvable ({error}).",
+}
+
+func TestErrorMessageCatalog_Translate(t *testing.T) {
+	t.Parallel()
+
+	notFoundErr := fmt.Errorf("load: %w", os.ErrNotExist)
+	tests := [...]struct {
+		name           string
+		err            error
+		expectedResult string
+	}{
+		{
+			name:           "sentinel error",
+			err:            xconf.ErrUnknownConfigFileExt,
+			expectedResult: "Format de fichier de configuration inconnu.",
+		},
+		{
+			name:           "wrapped error, with placeholder",
+			err:            notFoundErr,
+			expectedResult: "Fichier de configuration introuvable (load: file does not exist).",
+		},
+		{
+			name:           "xerr wrapped error",
+			err:            xerr.Wrapf(xconf.ErrUnknownConfigFileExt, "%q", "config.txt"),
+			expectedResult: "Format de fichier de configuration inconnu.",
+		},
+		{
+			name:           "multi error",
+			err:            xerr.NewMultiError().Add(xconf.ErrUnknownConfigFileExt, xconf.ErrNullValue),
+			expectedResult: "Format de fichier de configuration inconnu.\nnull value",
+		},
+		{
+			name:           "joined errors",
+			err:            errors.Join(xconf.ErrNullValue, notFoundErr),
+			expectedResult: "null value\nFichier de configuration introuvable (load: file does not exist).",
+		},
+		{
+			name:           "not found in catalog",
+			err:            xconf.ErrNullValue,
+			expectedResult: "null value",
+		},
+		{
+			name:           "nil error",
+			err:            nil,
+			expectedResult: "",
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			result := frErrorMessageCatalog.Translate(test.err)
+
+			// assert
+			assertEqual(t, test.expectedResult, result)
+		})
+	}
+}
+
+func TestLocalizedErrorMessageCatalogs_Translator(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.LocalizedErrorMessageCatalogs{
+		language.French: frErrorMessageCatalog,
+		language.German: xconf.ErrorMessageCatalog{
+			xconf.ErrUnknownConfigFileExt: "Unbekanntes Konfigurationsdateiformat.",
+		},
+	}
+	tests := [...]struct {
+		name           string
+		acceptLanguage string
+		expectedResult string
+	}{
+		{
+			name:           "exact match",
+			acceptLanguage: "de",
+			expectedResult: "Unbekanntes Konfigurationsdateiformat.",
+		},
+		{
+			name:           "regional variant",
+			acceptLanguage: "fr-CH, fr;q=0.9, en;q=0.8",
+			expectedResult: "Format de fichier de configuration inconnu.",
+		},
+		{
+			name:           "no match",
+			acceptLanguage: "ja",
+			expectedResult: xconf.ErrUnknownConfigFileExt.Error(),
+		},
+		{
+			name:           "no preferred language",
+			acceptLanguage: "",
+			expectedResult: xconf.ErrUnknownConfigFileExt.Error(),
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			preferred, _, err := language.ParseAcceptLanguage(test.acceptLanguage)
+			requireNil(t, err)
+
+			// act
+			result := subject.Translator(preferred...)(xconf.ErrUnknownConfigFileExt)
+
+			// assert
+			assertEqual(t, test.expectedResult, result)
+		})
+	}
+}
+
+func TestTranslatingErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var handledErr error
+	subject := xconf.TranslatingErrorHandler(
+		frErrorMessageCatalog.Translate,
+		func(err error) {
+			handledErr = err
+		},
+	)
+
+	// act
+	subject(xerr.Wrapf(xconf.ErrUnknownConfigFileExt, "%q", "config.txt"))
+
+	// assert
+	assertEqual(t, "Format de fichier de configuration inconnu.", handledErr.Error())
+	assertTrue(t, errors.Is(handledErr, xconf.ErrUnknownConfigFileExt))
+	assertNil(t, xconf.TranslateError(nil, frErrorMessageCatalog.Translate))
+}
+
+func ExampleErrorMessageCatalog() {
+	catalog := xconf.ErrorMessageCatalog{
+		xconf.ErrUnknownConfigFileExt: "Format de fichier de configuration inconnu.",
+		os.ErrNotExist:                "Fichier de configuration introuvable.",
+	}
+
+	_, err := xconf.NewDefaultConfig(xconf.FileLoader("testdata/this-file-does-not-exist.json"))
+	fmt.Println(xconf.TranslateError(err, catalog.Translate))
+
+	// Output:
+	// Fichier de configuration introuvable.
+}
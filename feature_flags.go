@@ -0,0 +1,157 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+
+	"github.com/spf13/cast"
+)
+
+// Note: the types in this file mirror OpenFeature's provider contract
+// (https://openfeature.dev/specification/sections/providers), without depending
+// on the OpenFeature SDK. Wrapping a [FlagProvider] into an openfeature.FeatureProvider
+// (or an openfeature.Client into a [FlagResolver]) is a matter of a few lines in your code.
+
+const (
+	// FlagReasonStatic is the resolution reason for a flag resolved from configuration.
+	FlagReasonStatic = "STATIC"
+	// FlagReasonDefault is the resolution reason for a flag not found in configuration.
+	FlagReasonDefault = "DEFAULT"
+	// FlagReasonError is the resolution reason for a flag that could not be resolved.
+	FlagReasonError = "ERROR"
+
+	// FlagErrorCodeNotFound is the error code for a flag not found in configuration.
+	FlagErrorCodeNotFound = "FLAG_NOT_FOUND"
+	// FlagErrorCodeTypeMismatch is the error code for a flag whose value
+	// could not be converted to requested type.
+	FlagErrorCodeTypeMismatch = "TYPE_MISMATCH"
+)
+
+// FlagResolution holds the result of a flag evaluation.
+type FlagResolution struct {
+	// Value is the resolved value, or the default value.
+	Value any
+	// Reason is one of FlagReason* constants.
+	Reason string
+	// ErrorCode is one of FlagErrorCode* constants, or empty.
+	ErrorCode string
+}
+
+// FlagProvider exposes a [Config] as a feature flag provider.
+// Flags are configuration keys; values are converted to requested type.
+type FlagProvider struct {
+	config Config
+}
+
+// NewFlagProvider instantiates a new FlagProvider object that resolves
+// feature flags from given config.
+func NewFlagProvider(config Config) FlagProvider {
+	return FlagProvider{config: config}
+}
+
+// BooleanEvaluation resolves a boolean flag.
+func (provider FlagProvider) BooleanEvaluation(flag string, defaultValue bool) FlagResolution {
+	return provider.evaluate(flag, defaultValue, func(value any) (any, error) {
+		return cast.ToBoolE(value)
+	})
+}
+
+// StringEvaluation resolves a string flag.
+func (provider FlagProvider) StringEvaluation(flag string, defaultValue string) FlagResolution {
+	return provider.evaluate(flag, defaultValue, func(value any) (any, error) {
+		return cast.ToStringE(value)
+	})
+}
+
+// IntEvaluation resolves an integer flag.
+func (provider FlagProvider) IntEvaluation(flag string, defaultValue int64) FlagResolution {
+	return provider.evaluate(flag, defaultValue, func(value any) (any, error) {
+		return cast.ToInt64E(value)
+	})
+}
+
+// FloatEvaluation resolves a float flag.
+func (provider FlagProvider) FloatEvaluation(flag string, defaultValue float64) FlagResolution {
+	return provider.evaluate(flag, defaultValue, func(value any) (any, error) {
+		return cast.ToFloat64E(value)
+	})
+}
+
+// ObjectEvaluation resolves a flag, returning its value as it is.
+func (provider FlagProvider) ObjectEvaluation(flag string, defaultValue any) FlagResolution {
+	return provider.evaluate(flag, defaultValue, func(value any) (any, error) {
+		return value, nil
+	})
+}
+
+// evaluate resolves a flag, converting its value with given function.
+func (provider FlagProvider) evaluate(flag string, defaultValue any, convert func(any) (any, error)) FlagResolution {
+	value := provider.config.Get(flag)
+	if value == nil {
+		return FlagResolution{
+			Value:     defaultValue,
+			Reason:    FlagReasonDefault,
+			ErrorCode: FlagErrorCodeNotFound,
+		}
+	}
+
+	convertedValue, err := convert(value)
+	if err != nil {
+		return FlagResolution{
+			Value:     defaultValue,
+			Reason:    FlagReasonError,
+			ErrorCode: FlagErrorCodeTypeMismatch,
+		}
+	}
+
+	return FlagResolution{
+		Value:  convertedValue,
+		Reason: FlagReasonStatic,
+	}
+}
+
+// FlagResolver resolves a feature flag's value.
+// An OpenFeature client (or any other feature flag client) can be adapted to it.
+type FlagResolver interface {
+	// ResolveFlag returns flag's value, or an error.
+	ResolveFlag(ctx context.Context, flag string, defaultValue any) (any, error)
+}
+
+// The FlagResolverFunc type is an adapter to allow the use of
+// ordinary functions as [FlagResolver]s.
+//
+// Example, adapting an OpenFeature client:
+//
+//	xconf.FlagResolverFunc(func(ctx context.Context, flag string, def any) (any, error) {
+//		return ofClient.ObjectValue(ctx, flag, def, openfeature.EvaluationContext{})
+//	})
+type FlagResolverFunc func(ctx context.Context, flag string, defaultValue any) (any, error)
+
+// ResolveFlag calls fn(ctx, flag, defaultValue).
+func (fn FlagResolverFunc) ResolveFlag(ctx context.Context, flag string, defaultValue any) (any, error) {
+	return fn(ctx, flag, defaultValue)
+}
+
+// FlagsLoader loads configuration from a feature flag client.
+// The second parameter is a map of flags to be resolved and their default values.
+// Each resolved flag will be a key in the configuration map.
+func FlagsLoader(resolver FlagResolver, flags map[string]any) Loader {
+	flagsCopy := DeepCopyConfigMap(flags)
+
+	return LoaderFunc(func() (map[string]any, error) {
+		configMap := make(map[string]any, len(flagsCopy))
+		for flag, defaultValue := range flagsCopy {
+			value, err := resolver.ResolveFlag(context.Background(), flag, defaultValue)
+			if err != nil {
+				return nil, err
+			}
+			configMap[flag] = value
+		}
+
+		return configMap, nil
+	})
+}
@@ -0,0 +1,177 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestFlagProvider(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.NewFlagProvider(xconf.NewMockConfig(
+		"new_checkout", "true",
+		"theme", "dark",
+		"max_items", 50,
+		"discount", "0.15",
+		"banner", map[string]any{"text": "hello"},
+		"invalid_bool", "not a bool",
+	))
+
+	tests := [...]struct {
+		name           string
+		resolve        func() xconf.FlagResolution
+		expectedResult xconf.FlagResolution
+	}{
+		{
+			name: "bool",
+			resolve: func() xconf.FlagResolution {
+				return subject.BooleanEvaluation("new_checkout", false)
+			},
+			expectedResult: xconf.FlagResolution{Value: true, Reason: xconf.FlagReasonStatic},
+		},
+		{
+			name: "string",
+			resolve: func() xconf.FlagResolution {
+				return subject.StringEvaluation("theme", "light")
+			},
+			expectedResult: xconf.FlagResolution{Value: "dark", Reason: xconf.FlagReasonStatic},
+		},
+		{
+			name: "int",
+			resolve: func() xconf.FlagResolution {
+				return subject.IntEvaluation("max_items", 10)
+			},
+			expectedResult: xconf.FlagResolution{Value: int64(50), Reason: xconf.FlagReasonStatic},
+		},
+		{
+			name: "float",
+			resolve: func() xconf.FlagResolution {
+				return subject.FloatEvaluation("discount", 0)
+			},
+			expectedResult: xconf.FlagResolution{Value: 0.15, Reason: xconf.FlagReasonStatic},
+		},
+		{
+			name: "object",
+			resolve: func() xconf.FlagResolution {
+				return subject.ObjectEvaluation("banner", nil)
+			},
+			expectedResult: xconf.FlagResolution{
+				Value:  map[string]any{"text": "hello"},
+				Reason: xconf.FlagReasonStatic,
+			},
+		},
+		{
+			name: "not found",
+			resolve: func() xconf.FlagResolution {
+				return subject.BooleanEvaluation("unknown_flag", true)
+			},
+			expectedResult: xconf.FlagResolution{
+				Value:     true,
+				Reason:    xconf.FlagReasonDefault,
+				ErrorCode: xconf.FlagErrorCodeNotFound,
+			},
+		},
+		{
+			name: "type mismatch",
+			resolve: func() xconf.FlagResolution {
+				return subject.BooleanEvaluation("invalid_bool", true)
+			},
+			expectedResult: xconf.FlagResolution{
+				Value:     true,
+				Reason:    xconf.FlagReasonError,
+				ErrorCode: xconf.FlagErrorCodeTypeMismatch,
+			},
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			result := test.resolve()
+
+			// assert
+			assertEqual(t, test.expectedResult, result)
+		})
+	}
+}
+
+func TestFlagsLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", testFlagsLoaderSuccess)
+	t.Run("error - resolver fails", testFlagsLoaderReturnsErrFromResolver)
+}
+
+func testFlagsLoaderSuccess(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	resolver := xconf.FlagResolverFunc(func(_ context.Context, flag string, def any) (any, error) {
+		if flag == "new_checkout" {
+			return true, nil
+		}
+
+		return def, nil
+	})
+	subject := xconf.FlagsLoader(resolver, map[string]any{
+		"new_checkout": false,
+		"theme":        "light",
+	})
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"new_checkout": true, "theme": "light"}, config)
+}
+
+func testFlagsLoaderReturnsErrFromResolver(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	expectedErr := errors.New("intentionally triggered resolver error")
+	resolver := xconf.FlagResolverFunc(func(context.Context, string, any) (any, error) {
+		return nil, expectedErr
+	})
+	subject := xconf.FlagsLoader(resolver, map[string]any{"new_checkout": false})
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, errors.Is(err, expectedErr))
+}
+
+func ExampleFlagProvider() {
+	cfg, err := xconf.NewDefaultConfig(xconf.PlainLoader(map[string]any{
+		"new_checkout": "yes",
+		"max_items":    "50",
+	}))
+	if err != nil {
+		panic(err)
+	}
+	defer cfg.Close()
+	provider := xconf.NewFlagProvider(cfg)
+
+	fmt.Printf("%+v\n", provider.IntEvaluation("max_items", 10))
+	fmt.Printf("%+v\n", provider.BooleanEvaluation("dark_theme", false))
+
+	// Output:
+	// {Value:50 Reason:STATIC ErrorCode:}
+	// {Value:false Reason:DEFAULT ErrorCode:FLAG_NOT_FOUND}
+}
@@ -0,0 +1,409 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/actforgood/xerr"
+	"github.com/spf13/cast"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/resolver"
+)
+
+// ErrGRPCRedialRequired is an error passed to [GRPCClient]'s error handler when
+// a setting which cannot be updated on an existing connection (keepalive, TLS) changes.
+// A new connection must be created for the setting to take effect.
+var ErrGRPCRedialRequired = errors.New("grpc setting cannot be updated, redial required")
+
+// ErrGRPCNoAddrs is an error returned by [GRPCClient]'s DialOptions if no address is configured.
+var ErrGRPCNoAddrs = errors.New("no grpc address configured")
+
+// GRPCClient keys, relative to GRPCClient's keys prefix.
+const (
+	// GRPCAddrsKey is the key for server addresses (a list, or a comma separated string of "host:port").
+	GRPCAddrsKey = "addrs"
+	// GRPCLoadBalancingPolicyKey is the key for load balancing policy ("pick_first", "round_robin").
+	GRPCLoadBalancingPolicyKey = "load_balancing_policy"
+	// GRPCTimeoutKey is the key for calls' timeout.
+	GRPCTimeoutKey = "timeout"
+	// GRPCRetryMaxAttemptsKey is the key for retry policy's max attempts (original call included).
+	// Retry policy is enabled for a value > 1.
+	GRPCRetryMaxAttemptsKey = "retry_max_attempts"
+	// GRPCRetryInitialBackoffKey is the key for retry policy's initial backoff.
+	GRPCRetryInitialBackoffKey = "retry_initial_backoff"
+	// GRPCRetryMaxBackoffKey is the key for retry policy's max backoff.
+	GRPCRetryMaxBackoffKey = "retry_max_backoff"
+	// GRPCRetryBackoffMultiplierKey is the key for retry policy's backoff multiplier.
+	GRPCRetryBackoffMultiplierKey = "retry_backoff_multiplier"
+	// GRPCRetryStatusCodesKey is the key for retry policy's retryable status codes
+	// (a list, or a comma separated string, like "UNAVAILABLE,RESOURCE_EXHAUSTED").
+	GRPCRetryStatusCodesKey = "retry_status_codes"
+	// GRPCKeepaliveTimeKey is the key for keepalive's time.
+	GRPCKeepaliveTimeKey = "keepalive_time"
+	// GRPCKeepaliveTimeoutKey is the key for keepalive's timeout.
+	GRPCKeepaliveTimeoutKey = "keepalive_timeout"
+	// GRPCKeepalivePermitWithoutStreamKey is the key for keepalive's permit without stream flag.
+	GRPCKeepalivePermitWithoutStreamKey = "keepalive_permit_without_stream"
+	// GRPCTLSKey is the key for enabling TLS (with system's root CAs).
+	// TLS is enabled also if any of the other TLS keys is set.
+	GRPCTLSKey = "tls"
+	// GRPCTLSCAFileKey is the key for TLS root CA file.
+	GRPCTLSCAFileKey = "tls_ca_file"
+	// GRPCTLSCertFileKey is the key for TLS client certificate file.
+	GRPCTLSCertFileKey = "tls_cert_file"
+	// GRPCTLSKeyFileKey is the key for TLS client private key file.
+	GRPCTLSKeyFileKey = "tls_key_file"
+	// GRPCTLSServerNameKey is the key for TLS server name.
+	GRPCTLSServerNameKey = "tls_server_name"
+)
+
+// DefaultGRPCClientKeysPrefix is the default prefix for GRPCClient's keys.
+const DefaultGRPCClientKeysPrefix = "grpc.client."
+
+// grpcResolverScheme is the scheme of GRPCClient's resolver.
+const grpcResolverScheme = "xconf"
+
+// GRPCClient is an adapter which produces [grpc.DialOption]s and service config
+// from a [Config] (addresses, load balancing, timeout, retry policy, keepalive, TLS).
+// Register its OnConfigChange method as an observer on a [DefaultConfig]
+// with reload enabled, and addresses and service config (load balancing, timeout, retry policy)
+// are updated on existing connections, through GRPCClient's resolver.
+// Keepalive and TLS settings cannot be updated on an existing connection,
+// their change is reported to the error handler, if set, see [ErrGRPCRedialRequired].
+//
+// Example:
+//
+//	client := xconf.NewGRPCClient(xconf.GRPCClientWithErrorHandler(xconf.LogErrorHandler(logger)))
+//	opts, err := client.DialOptions(cfg)
+//	if err != nil {
+//		// handle error
+//	}
+//	conn, err := grpc.NewClient(client.Target(), opts...)
+//	if err != nil {
+//		// handle error
+//	}
+//	cfg.RegisterObserver(client.OnConfigChange)
+type GRPCClient struct {
+	// keysPrefix is the prefix of the keys.
+	keysPrefix string
+	// errHandler is an optional handler for errors occurred on config changes.
+	errHandler func(error)
+
+	// mu protects the fields below.
+	mu sync.Mutex
+	// state is the current resolver state.
+	state grpcResolverState
+	// resolvers are the resolvers built for connections.
+	resolvers []*grpcResolver
+}
+
+// grpcResolverState holds the addresses and the service config pushed to connections.
+type grpcResolverState struct {
+	addrs         []string
+	serviceConfig string
+}
+
+// NewGRPCClient instantiates a new GRPCClient object.
+// By default, [DefaultGRPCClientKeysPrefix] is used as keys prefix.
+func NewGRPCClient(opts ...GRPCClientOption) *GRPCClient {
+	client := &GRPCClient{
+		keysPrefix: DefaultGRPCClientKeysPrefix,
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client
+}
+
+// Target returns the target to create the connection for.
+// It must be used together with DialOptions.
+func (client *GRPCClient) Target() string {
+	return grpcResolverScheme + ":///" + strings.TrimSuffix(client.keysPrefix, ".")
+}
+
+// DialOptions returns the dial options built from given config:
+// the resolver (providing addresses and service config), keepalive and transport credentials.
+func (client *GRPCClient) DialOptions(config Config) ([]grpc.DialOption, error) {
+	state, err := client.readState(config)
+	if err != nil {
+		return nil, err
+	}
+	if len(state.addrs) == 0 {
+		return nil, ErrGRPCNoAddrs
+	}
+	creds, err := client.transportCredentials(config)
+	if err != nil {
+		return nil, err
+	}
+
+	client.mu.Lock()
+	client.state = state
+	client.mu.Unlock()
+
+	opts := []grpc.DialOption{
+		grpc.WithResolvers(grpcResolverBuilder{client: client}),
+		grpc.WithTransportCredentials(creds),
+	}
+	if params, ok := client.keepaliveParams(config); ok {
+		opts = append(opts, grpc.WithKeepaliveParams(params))
+	}
+
+	return opts, nil
+}
+
+// OnConfigChange is a [ConfigObserver] that updates addresses and service config on existing connections,
+// if related keys changed.
+func (client *GRPCClient) OnConfigChange(config Config, changedKeys ...string) {
+	var stateChanged bool
+	for _, changedKey := range changedKeys {
+		if !hasPrefixFold(changedKey, client.keysPrefix) {
+			continue
+		}
+		key := strings.ToLower(changedKey[len(client.keysPrefix):])
+		if strings.HasPrefix(key, "keepalive_") || strings.HasPrefix(key, GRPCTLSKey) {
+			client.handleErr(xerr.Wrapf(ErrGRPCRedialRequired, "key %q", changedKey))
+		} else {
+			stateChanged = true
+		}
+	}
+	if !stateChanged {
+		return
+	}
+
+	state, err := client.readState(config)
+	if err == nil && len(state.addrs) == 0 {
+		err = ErrGRPCNoAddrs
+	}
+	if err != nil {
+		client.handleErr(err)
+
+		return
+	}
+
+	client.mu.Lock()
+	client.state = state
+	resolvers := make([]*grpcResolver, len(client.resolvers))
+	copy(resolvers, client.resolvers)
+	client.mu.Unlock()
+
+	for _, r := range resolvers {
+		r.updateState(state)
+	}
+}
+
+// readState reads addresses and service config from given config.
+func (client *GRPCClient) readState(config Config) (grpcResolverState, error) {
+	serviceConfig, err := GRPCServiceConfig(config, client.keysPrefix)
+	if err != nil {
+		return grpcResolverState{}, err
+	}
+
+	return grpcResolverState{
+		addrs:         TrimmedStringList(config.Get(client.keysPrefix + GRPCAddrsKey)),
+		serviceConfig: serviceConfig,
+	}, nil
+}
+
+// keepaliveParams reads keepalive parameters from given config.
+// It returns false if keepalive is not configured.
+func (client *GRPCClient) keepaliveParams(config Config) (keepalive.ClientParameters, bool) {
+	params := keepalive.ClientParameters{
+		Time:                cast.ToDuration(config.Get(client.keysPrefix + GRPCKeepaliveTimeKey)),
+		Timeout:             cast.ToDuration(config.Get(client.keysPrefix + GRPCKeepaliveTimeoutKey)),
+		PermitWithoutStream: cast.ToBool(config.Get(client.keysPrefix + GRPCKeepalivePermitWithoutStreamKey)),
+	}
+
+	return params, params.Time > 0
+}
+
+// transportCredentials reads TLS settings from given config and returns the transport credentials.
+func (client *GRPCClient) transportCredentials(config Config) (credentials.TransportCredentials, error) {
+	var (
+		caFile     = cast.ToString(config.Get(client.keysPrefix + GRPCTLSCAFileKey))
+		certFile   = cast.ToString(config.Get(client.keysPrefix + GRPCTLSCertFileKey))
+		keyFile    = cast.ToString(config.Get(client.keysPrefix + GRPCTLSKeyFileKey))
+		serverName = cast.ToString(config.Get(client.keysPrefix + GRPCTLSServerNameKey))
+		enabled    = cast.ToBool(config.Get(client.keysPrefix + GRPCTLSKey))
+	)
+	if !enabled && caFile == "" && certFile == "" && keyFile == "" && serverName == "" {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if err := loadTLSFiles(tlsConfig, caFile, certFile, keyFile); err != nil {
+		return nil, err
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// handleErr calls the error handler, if set.
+func (client *GRPCClient) handleErr(err error) {
+	if client.errHandler != nil {
+		client.errHandler(err)
+	}
+}
+
+// GRPCServiceConfig produces gRPC service config JSON from given config's keys
+// with given prefix (load balancing policy, timeout, retry policy).
+// An empty JSON object is returned if no related key is configured.
+// See https://github.com/grpc/grpc/blob/master/doc/service_config.md.
+func GRPCServiceConfig(config Config, keysPrefix string) (string, error) {
+	serviceConfig := make(map[string]any)
+	if policy := cast.ToString(config.Get(keysPrefix + GRPCLoadBalancingPolicyKey)); policy != "" {
+		serviceConfig["loadBalancingConfig"] = []any{map[string]any{policy: map[string]any{}}}
+	}
+
+	methodConfig := make(map[string]any)
+	if value := config.Get(keysPrefix + GRPCTimeoutKey); value != nil {
+		timeout, err := cast.ToDurationE(value)
+		if err != nil || timeout <= 0 {
+			return "", fmt.Errorf("invalid grpc timeout value %v, must be a positive duration", value)
+		}
+		methodConfig["timeout"] = grpcDuration(timeout)
+	}
+	if value := config.Get(keysPrefix + GRPCRetryMaxAttemptsKey); value != nil {
+		maxAttempts, err := cast.ToIntE(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid grpc retry max attempts value %v, must be an integer", value)
+		}
+		if maxAttempts > 1 {
+			methodConfig["retryPolicy"] = map[string]any{
+				"maxAttempts": maxAttempts,
+				"initialBackoff": grpcDuration(
+					cast.ToDuration(config.Get(keysPrefix+GRPCRetryInitialBackoffKey, 100*time.Millisecond)),
+				),
+				"maxBackoff": grpcDuration(
+					cast.ToDuration(config.Get(keysPrefix+GRPCRetryMaxBackoffKey, time.Second)),
+				),
+				"backoffMultiplier": cast.ToFloat64(config.Get(keysPrefix+GRPCRetryBackoffMultiplierKey, 2.0)),
+				"retryableStatusCodes": TrimmedStringList(
+					config.Get(keysPrefix+GRPCRetryStatusCodesKey, "UNAVAILABLE"),
+				),
+			}
+		}
+	}
+	if len(methodConfig) > 0 {
+		methodConfig["name"] = []any{map[string]any{}} // applies to all services and methods.
+		serviceConfig["methodConfig"] = []any{methodConfig}
+	}
+
+	serviceConfigJSON, err := json.Marshal(serviceConfig)
+	if err != nil {
+		return "", err
+	}
+
+	return string(serviceConfigJSON), nil
+}
+
+// grpcDuration formats a duration as service config expects it (seconds, with "s" suffix).
+func grpcDuration(duration time.Duration) string {
+	return strconv.FormatFloat(duration.Seconds(), 'f', -1, 64) + "s"
+}
+
+// GRPCClientOption defines optional function for configuring
+// a GRPCClient object.
+type GRPCClientOption func(*GRPCClient)
+
+// GRPCClientWithKeysPrefix sets the prefix of the keys.
+// By default, [DefaultGRPCClientKeysPrefix] is used.
+func GRPCClientWithKeysPrefix(keysPrefix string) GRPCClientOption {
+	return func(client *GRPCClient) {
+		client.keysPrefix = keysPrefix
+	}
+}
+
+// GRPCClientWithErrorHandler sets the handler for errors occurred on config changes
+// (invalid values, settings which require a redial).
+// You can choose to log the error, for example with [LogErrorHandler].
+//
+// By default, error is simply ignored.
+func GRPCClientWithErrorHandler(errHandler func(error)) GRPCClientOption {
+	return func(client *GRPCClient) {
+		client.errHandler = errHandler
+	}
+}
+
+// grpcResolverBuilder builds resolvers for GRPCClient's connections.
+type grpcResolverBuilder struct {
+	client *GRPCClient
+}
+
+// Build builds a new resolver for given connection, and pushes the current state to it.
+func (builder grpcResolverBuilder) Build(
+	_ resolver.Target,
+	cc resolver.ClientConn,
+	_ resolver.BuildOptions,
+) (resolver.Resolver, error) {
+	r := &grpcResolver{client: builder.client, cc: cc}
+
+	builder.client.mu.Lock()
+	builder.client.resolvers = append(builder.client.resolvers, r)
+	state := builder.client.state
+	builder.client.mu.Unlock()
+	r.updateState(state)
+
+	return r, nil
+}
+
+// Scheme returns the scheme of GRPCClient's resolver.
+func (grpcResolverBuilder) Scheme() string {
+	return grpcResolverScheme
+}
+
+// grpcResolver pushes GRPCClient's addresses and service config to a connection.
+type grpcResolver struct {
+	client *GRPCClient
+	cc     resolver.ClientConn
+}
+
+// updateState pushes given state to the connection.
+func (r *grpcResolver) updateState(state grpcResolverState) {
+	addrs := make([]resolver.Address, len(state.addrs))
+	for idx, addr := range state.addrs {
+		addrs[idx] = resolver.Address{Addr: addr}
+	}
+	err := r.cc.UpdateState(resolver.State{
+		Addresses:     addrs,
+		ServiceConfig: r.cc.ParseServiceConfig(state.serviceConfig),
+	})
+	if err != nil {
+		r.client.handleErr(err)
+	}
+}
+
+// ResolveNow does nothing, state is pushed on config changes.
+func (*grpcResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+// Close removes the resolver from GRPCClient's resolvers.
+func (r *grpcResolver) Close() {
+	r.client.mu.Lock()
+	defer r.client.mu.Unlock()
+	for idx, clientResolver := range r.client.resolvers {
+		if clientResolver == r {
+			r.client.resolvers = append(r.client.resolvers[:idx], r.client.resolvers[idx+1:]...)
+
+			break
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestGRPCClient(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - addresses are updated on config change", testGRPCClientUpdatesAddrs)
+	t.Run("success - redial required settings are reported", testGRPCClientReportsRedialRequired)
+	t.Run("error - no addresses", testGRPCClientReturnsNoAddrsErr)
+	t.Run("error - invalid service config values", testGRPCClientReturnsInvalidServiceConfigErr)
+	t.Run("error - tls", testGRPCClientReturnsTLSErr)
+}
+
+// startGRPCHealthServer starts a gRPC server with health service,
+// serving given status for "" service.
+func startGRPCHealthServer(t *testing.T, status healthpb.HealthCheckResponse_ServingStatus) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	requireNil(t, err)
+	healthSvr := health.NewServer()
+	healthSvr.SetServingStatus("", status)
+	svr := grpc.NewServer()
+	healthpb.RegisterHealthServer(svr, healthSvr)
+	go func() {
+		_ = svr.Serve(listener)
+	}()
+	t.Cleanup(svr.Stop)
+
+	return listener.Addr().String()
+}
+
+// grpcHealthStatus returns the health status served by connection's server.
+func grpcHealthStatus(t *testing.T, conn *grpc.ClientConn) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}, grpc.WaitForReady(true))
+	requireNil(t, err)
+
+	return resp.GetStatus()
+}
+
+func testGRPCClientUpdatesAddrs(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		addr1   = startGRPCHealthServer(t, healthpb.HealthCheckResponse_SERVING)
+		addr2   = startGRPCHealthServer(t, healthpb.HealthCheckResponse_NOT_SERVING)
+		config  = xconf.NewMockConfig("grpc.client.addrs", addr1, "grpc.client.timeout", "5s")
+		subject = xconf.NewGRPCClient(xconf.GRPCClientWithErrorHandler(func(err error) {
+			t.Error("unexpected error", err)
+		}))
+	)
+	opts, err := subject.DialOptions(config)
+	requireNil(t, err)
+	conn, err := grpc.NewClient(subject.Target(), opts...)
+	requireNil(t, err)
+	defer conn.Close()
+	assertEqual(t, healthpb.HealthCheckResponse_SERVING, grpcHealthStatus(t, conn))
+	config.SetKeyValues("grpc.client.addrs", []string{addr2})
+
+	// act
+	subject.OnConfigChange(config, "grpc.client.addrs")
+
+	// assert
+	var status healthpb.HealthCheckResponse_ServingStatus
+	for i := 0; i < 200; i++ { // switching to the new address is async.
+		if status = grpcHealthStatus(t, conn); status == healthpb.HealthCheckResponse_NOT_SERVING {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	assertEqual(t, healthpb.HealthCheckResponse_NOT_SERVING, status)
+}
+
+func testGRPCClientReportsRedialRequired(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		errs    []error
+		config  = xconf.NewMockConfig("GRPC_ADDRS", "127.0.0.1:50051")
+		subject = xconf.NewGRPCClient(
+			xconf.GRPCClientWithKeysPrefix("GRPC_"),
+			xconf.GRPCClientWithErrorHandler(func(err error) {
+				errs = append(errs, err)
+			}),
+		)
+	)
+
+	// act
+	subject.OnConfigChange(config, "GRPC_KEEPALIVE_TIME", "GRPC_TLS_CA_FILE", "OTHER_KEY")
+
+	// assert
+	if assertEqual(t, 2, len(errs)) {
+		assertTrue(t, errors.Is(errs[0], xconf.ErrGRPCRedialRequired))
+		assertTrue(t, errors.Is(errs[1], xconf.ErrGRPCRedialRequired))
+	}
+}
+
+func testGRPCClientReturnsNoAddrsErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.NewGRPCClient()
+
+	// act
+	opts, err := subject.DialOptions(xconf.NewMockConfig("grpc.client.addrs", " , "))
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrGRPCNoAddrs))
+	assertNil(t, opts)
+}
+
+func testGRPCClientReturnsInvalidServiceConfigErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.NewGRPCClient()
+	configs := [...]xconf.Config{
+		xconf.NewMockConfig("grpc.client.addrs", "127.0.0.1:50051", "grpc.client.timeout", "soon"),
+		xconf.NewMockConfig("grpc.client.addrs", "127.0.0.1:50051", "grpc.client.retry_max_attempts", "many"),
+	}
+
+	for _, config := range configs {
+		// act
+		opts, err := subject.DialOptions(config)
+
+		// assert
+		assertNotNil(t, err)
+		assertNil(t, opts)
+	}
+}
+
+func testGRPCClientReturnsTLSErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.NewGRPCClient()
+	config := xconf.NewMockConfig(
+		"grpc.client.addrs", "127.0.0.1:50051",
+		"grpc.client.tls_ca_file", "testdata/this-file-does-not-exist.pem",
+	)
+
+	// act
+	opts, err := subject.DialOptions(config)
+
+	// assert
+	assertTrue(t, errors.Is(err, os.ErrNotExist))
+	assertNil(t, opts)
+}
+
+func TestGRPCServiceConfig(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config := xconf.NewMockConfig(
+		"grpc.load_balancing_policy", "round_robin",
+		"grpc.timeout", "1500ms",
+		"grpc.retry_max_attempts", 4,
+		"grpc.retry_initial_backoff", "200ms",
+		"grpc.retry_status_codes", "UNAVAILABLE, RESOURCE_EXHAUSTED",
+	)
+
+	// act
+	serviceConfig, err := xconf.GRPCServiceConfig(config, "grpc.")
+
+	// assert
+	requireNil(t, err)
+	assertEqual(
+		t,
+		`{"loadBalancingConfig":[{"round_robin":{}}],"methodConfig":[{"name":[{}],`+
+			`"retryPolicy":{"backoffMultiplier":2,"initialBackoff":"0.2s","maxAttempts":4,"maxBackoff":"1s",`+
+			`"retryableStatusCodes":["UNAVAILABLE","RESOURCE_EXHAUSTED"]},"timeout":"1.5s"}]}`,
+		serviceConfig,
+	)
+
+	// act
+	serviceConfig, err = xconf.GRPCServiceConfig(xconf.NewMockConfig(), "grpc.")
+
+	// assert
+	requireNil(t, err)
+	assertEqual(t, `{}`, serviceConfig)
+}
@@ -0,0 +1,17 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import "net/http"
+
+// newDefaultHTTPClient instantiates a new default HTTP client.
+// On js/wasm, requests are made with the Fetch API, which is used only
+// if the transport has no custom dialer.
+func newDefaultHTTPClient() *http.Client {
+	return &http.Client{
+		Transport: http.DefaultTransport.(*http.Transport).Clone(),
+	}
+}
@@ -0,0 +1,420 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// ErrHTTPServerClosed is an error returned by [HTTPServer]'s ListenAndServe
+// if the server was already shut down.
+var ErrHTTPServerClosed = errors.New("http server closed")
+
+// HTTPServer keys, relative to HTTPServer's keys prefix.
+const (
+	// HTTPServerAddrKey is the key for [http.Server]'s address.
+	HTTPServerAddrKey = "addr"
+	// HTTPServerReadTimeoutKey is the key for [http.Server]'s ReadTimeout.
+	HTTPServerReadTimeoutKey = "read_timeout"
+	// HTTPServerReadHeaderTimeoutKey is the key for [http.Server]'s ReadHeaderTimeout.
+	HTTPServerReadHeaderTimeoutKey = "read_header_timeout"
+	// HTTPServerWriteTimeoutKey is the key for [http.Server]'s WriteTimeout.
+	HTTPServerWriteTimeoutKey = "write_timeout"
+	// HTTPServerIdleTimeoutKey is the key for [http.Server]'s IdleTimeout.
+	HTTPServerIdleTimeoutKey = "idle_timeout"
+	// HTTPServerMaxHeaderBytesKey is the key for [http.Server]'s MaxHeaderBytes.
+	HTTPServerMaxHeaderBytesKey = "max_header_bytes"
+	// HTTPServerTLSCertFileKey is the key for TLS certificate file.
+	HTTPServerTLSCertFileKey = "tls_cert_file"
+	// HTTPServerTLSKeyFileKey is the key for TLS private key file.
+	HTTPServerTLSKeyFileKey = "tls_key_file"
+	// HTTPServerShutdownTimeoutKey is the key for the graceful shutdown timeout of a swapped server.
+	HTTPServerShutdownTimeoutKey = "shutdown_timeout"
+)
+
+// DefaultHTTPServerKeysPrefix is the default prefix for HTTPServer's keys.
+const DefaultHTTPServerKeysPrefix = "http.server."
+
+// httpServerDefaultShutdownTimeout is the default graceful shutdown timeout of a swapped server.
+const httpServerDefaultShutdownTimeout = 5 * time.Second
+
+// HTTPServer is an adapter which serves an [http.Handler] with an [http.Server]
+// configured from a [Config] (address, timeouts, max header bytes, TLS).
+// It is a worked example of how observers should be used for connection-level settings:
+// as an [http.Server]'s settings cannot be safely changed while it's serving,
+// register its OnConfigChange method as an observer on a [DefaultConfig] with reload enabled,
+// and, when related keys change, a new server is built and started,
+// while the old one is gracefully shut down (in-flight requests are finished).
+// If the address is not changed, the listener is handed over to the new server,
+// otherwise a new listener is bound and the old one is closed.
+//
+// Example:
+//
+//	srv := xconf.NewHTTPServer(handler, xconf.HTTPServerWithErrorHandler(xconf.LogErrorHandler(logger)))
+//	cfg.RegisterObserver(srv.OnConfigChange)
+//	go func() {
+//		if err := srv.ListenAndServe(cfg); err != nil {
+//			// handle error
+//		}
+//	}()
+//	// ...
+//	_ = srv.Shutdown(ctx)
+type HTTPServer struct {
+	// handler is the served handler.
+	handler http.Handler
+	// keysPrefix is the prefix of the keys.
+	keysPrefix string
+	// errHandler is an optional handler for errors occurred on config changes.
+	errHandler func(error)
+
+	// mu protects the fields below.
+	mu sync.Mutex
+	// settings are the current server's settings.
+	settings httpServerSettings
+	// server is the current server.
+	server *http.Server
+	// listener is the current shared listener.
+	listener *sharedListener
+	// doneC receives the result of serving.
+	doneC chan error
+	// closed indicates whether Shutdown was called.
+	closed bool
+}
+
+// httpServerSettings holds the settings an [http.Server] is built with.
+type httpServerSettings struct {
+	addr              string
+	readTimeout       time.Duration
+	readHeaderTimeout time.Duration
+	writeTimeout      time.Duration
+	idleTimeout       time.Duration
+	maxHeaderBytes    int
+	tlsCertFile       string
+	tlsKeyFile        string
+	shutdownTimeout   time.Duration
+}
+
+// NewHTTPServer instantiates a new HTTPServer object.
+// By default, [DefaultHTTPServerKeysPrefix] is used as keys prefix.
+func NewHTTPServer(handler http.Handler, opts ...HTTPServerOption) *HTTPServer {
+	srv := &HTTPServer{
+		handler:    handler,
+		keysPrefix: DefaultHTTPServerKeysPrefix,
+		doneC:      make(chan error, 1),
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(srv)
+	}
+
+	return srv
+}
+
+// ListenAndServe starts serving with settings read from given config.
+// It blocks until Shutdown is called (in which case nil is returned),
+// or until serving fails.
+func (srv *HTTPServer) ListenAndServe(config Config) error {
+	srv.mu.Lock()
+	if srv.closed {
+		srv.mu.Unlock()
+
+		return ErrHTTPServerClosed
+	}
+	err := srv.swap(srv.readSettings(config))
+	srv.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return <-srv.doneC
+}
+
+// OnConfigChange is a [ConfigObserver] that rebuilds the server if related keys changed.
+// If the new server cannot be started, the old one keeps serving,
+// and the error is passed to the error handler, if set.
+func (srv *HTTPServer) OnConfigChange(config Config, changedKeys ...string) {
+	if !srv.isAffectedBy(changedKeys) {
+		return
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.closed || srv.server == nil {
+		return
+	}
+	settings := srv.readSettings(config)
+	if settings == srv.settings {
+		return
+	}
+	if err := srv.swap(settings); err != nil && srv.errHandler != nil {
+		srv.errHandler(err)
+	}
+}
+
+// Addr returns the address the server is listening on,
+// or nil, if it's not serving.
+func (srv *HTTPServer) Addr() net.Addr {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.listener == nil {
+		return nil
+	}
+
+	return srv.listener.Addr()
+}
+
+// Shutdown gracefully shuts down the server, see [http.Server.Shutdown].
+func (srv *HTTPServer) Shutdown(ctx context.Context) error {
+	srv.mu.Lock()
+	if srv.closed {
+		srv.mu.Unlock()
+
+		return nil
+	}
+	srv.closed = true
+	server, listener := srv.server, srv.listener
+	srv.mu.Unlock()
+
+	var err error
+	if server != nil {
+		err = server.Shutdown(ctx)
+		_ = listener.Close()
+	}
+	srv.done(nil)
+
+	return err
+}
+
+// isAffectedBy checks whether any of the changed keys is a server's key.
+func (srv *HTTPServer) isAffectedBy(changedKeys []string) bool {
+	for _, changedKey := range changedKeys {
+		if hasPrefixFold(changedKey, srv.keysPrefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readSettings reads server's settings from given config.
+func (srv *HTTPServer) readSettings(config Config) httpServerSettings {
+	return httpServerSettings{
+		addr:              cast.ToString(config.Get(srv.keysPrefix+HTTPServerAddrKey, ":http")),
+		readTimeout:       cast.ToDuration(config.Get(srv.keysPrefix+HTTPServerReadTimeoutKey, time.Duration(0))),
+		readHeaderTimeout: cast.ToDuration(config.Get(srv.keysPrefix+HTTPServerReadHeaderTimeoutKey, time.Duration(0))),
+		writeTimeout:      cast.ToDuration(config.Get(srv.keysPrefix+HTTPServerWriteTimeoutKey, time.Duration(0))),
+		idleTimeout:       cast.ToDuration(config.Get(srv.keysPrefix+HTTPServerIdleTimeoutKey, time.Duration(0))),
+		maxHeaderBytes:    cast.ToInt(config.Get(srv.keysPrefix+HTTPServerMaxHeaderBytesKey, 0)),
+		tlsCertFile:       cast.ToString(config.Get(srv.keysPrefix+HTTPServerTLSCertFileKey, "")),
+		tlsKeyFile:        cast.ToString(config.Get(srv.keysPrefix+HTTPServerTLSKeyFileKey, "")),
+		shutdownTimeout: cast.ToDuration(config.Get(
+			srv.keysPrefix+HTTPServerShutdownTimeoutKey,
+			httpServerDefaultShutdownTimeout,
+		)),
+	}
+}
+
+// swap builds and starts a new server with given settings,
+// and gracefully shuts down the old one, if any.
+// It must be called under lock.
+func (srv *HTTPServer) swap(settings httpServerSettings) error {
+	listener := srv.listener
+	if listener == nil || settings.addr != srv.settings.addr {
+		netListener, err := net.Listen("tcp", settings.addr)
+		if err != nil {
+			return err
+		}
+		listener = newSharedListener(netListener)
+	}
+	var serverListener net.Listener = listener.view()
+	if settings.tlsCertFile != "" || settings.tlsKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(settings.tlsCertFile, settings.tlsKeyFile)
+		if err != nil {
+			if listener != srv.listener {
+				_ = listener.Close()
+			}
+
+			return err
+		}
+		serverListener = tls.NewListener(serverListener, &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		})
+	}
+	server := &http.Server{
+		Handler:           srv.handler,
+		ReadTimeout:       settings.readTimeout,
+		ReadHeaderTimeout: settings.readHeaderTimeout,
+		WriteTimeout:      settings.writeTimeout,
+		IdleTimeout:       settings.idleTimeout,
+		MaxHeaderBytes:    settings.maxHeaderBytes,
+	}
+	go srv.serve(server, serverListener)
+
+	oldServer, oldListener, oldShutdownTimeout := srv.server, srv.listener, srv.settings.shutdownTimeout
+	srv.server, srv.listener, srv.settings = server, listener, settings
+	if oldServer != nil {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), oldShutdownTimeout)
+			defer cancel()
+			_ = oldServer.Shutdown(ctx)
+			if oldListener != listener {
+				_ = oldListener.Close()
+			}
+		}()
+	}
+
+	return nil
+}
+
+// serve serves with given server, on given listener.
+func (srv *HTTPServer) serve(server *http.Server, listener net.Listener) {
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		srv.done(err)
+	}
+}
+
+// done passes the result of serving to ListenAndServe, if not already passed.
+func (srv *HTTPServer) done(err error) {
+	select {
+	case srv.doneC <- err:
+	default:
+	}
+}
+
+// HTTPServerOption defines optional function for configuring
+// an HTTPServer object.
+type HTTPServerOption func(*HTTPServer)
+
+// HTTPServerWithKeysPrefix sets the prefix of the keys.
+// By default, [DefaultHTTPServerKeysPrefix] is used.
+func HTTPServerWithKeysPrefix(keysPrefix string) HTTPServerOption {
+	return func(srv *HTTPServer) {
+		srv.keysPrefix = keysPrefix
+	}
+}
+
+// HTTPServerWithErrorHandler sets the handler for errors occurred while
+// rebuilding the server on config changes (like an address already in use,
+// or an invalid TLS certificate).
+// You can choose to log the error, for example with [LogErrorHandler].
+//
+// By default, error is simply ignored.
+func HTTPServerWithErrorHandler(errHandler func(error)) HTTPServerOption {
+	return func(srv *HTTPServer) {
+		srv.errHandler = errHandler
+	}
+}
+
+// sharedListener is a listener which can be shared by multiple (successive) servers.
+// A single goroutine accepts connections and hands them over to the
+// servers' listener views.
+type sharedListener struct {
+	listener   net.Listener
+	connC      chan net.Conn
+	closingC   chan struct{}
+	closeOnce  sync.Once
+	acceptDone chan struct{}
+	acceptErr  error
+}
+
+// newSharedListener instantiates a new sharedListener and starts accepting connections.
+func newSharedListener(listener net.Listener) *sharedListener {
+	sl := &sharedListener{
+		listener:   listener,
+		connC:      make(chan net.Conn),
+		closingC:   make(chan struct{}),
+		acceptDone: make(chan struct{}),
+	}
+	go sl.acceptLoop()
+
+	return sl
+}
+
+// acceptLoop accepts connections and hands them over to listener views.
+func (sl *sharedListener) acceptLoop() {
+	defer close(sl.acceptDone)
+	for {
+		conn, err := sl.listener.Accept()
+		if err != nil {
+			sl.acceptErr = err
+
+			return
+		}
+		select {
+		case sl.connC <- conn:
+		case <-sl.closingC:
+			_ = conn.Close()
+
+			return
+		}
+	}
+}
+
+// Addr returns the listener's network address.
+func (sl *sharedListener) Addr() net.Addr {
+	return sl.listener.Addr()
+}
+
+// Close closes the listener.
+func (sl *sharedListener) Close() error {
+	sl.closeOnce.Do(func() {
+		close(sl.closingC)
+	})
+
+	return sl.listener.Close()
+}
+
+// view returns a new listener view, to be passed to a server.
+func (sl *sharedListener) view() net.Listener {
+	return &sharedListenerView{
+		shared: sl,
+		doneC:  make(chan struct{}),
+	}
+}
+
+// sharedListenerView is a server's view of a shared listener.
+// Closing it does not close the shared listener.
+type sharedListenerView struct {
+	shared    *sharedListener
+	doneC     chan struct{}
+	closeOnce sync.Once
+}
+
+// Accept waits for and returns the next connection.
+func (view *sharedListenerView) Accept() (net.Conn, error) {
+	select {
+	case conn := <-view.shared.connC:
+		return conn, nil
+	case <-view.doneC:
+		return nil, net.ErrClosed
+	case <-view.shared.acceptDone:
+		return nil, view.shared.acceptErr
+	}
+}
+
+// Close stops the view from accepting connections.
+func (view *sharedListenerView) Close() error {
+	view.closeOnce.Do(func() {
+		close(view.doneC)
+	})
+
+	return nil
+}
+
+// Addr returns the listener's network address.
+func (view *sharedListenerView) Addr() net.Addr {
+	return view.shared.Addr()
+}
@@ -0,0 +1,251 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestHTTPServer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - swap on timeouts change, listener is kept", testHTTPServerSwapKeepsListener)
+	t.Run("success - swap on address change, listener is rebound", testHTTPServerSwapRebindsListener)
+	t.Run("success - not related keys are ignored", testHTTPServerIgnoresNotRelatedKeys)
+	t.Run("error - invalid TLS, old server keeps serving", testHTTPServerInvalidTLSKeepsOldServer)
+	t.Run("error - listen", testHTTPServerReturnsListenErr)
+	t.Run("error - closed", testHTTPServerReturnsErrIfClosed)
+}
+
+// startHTTPServer starts serving in background and waits for server to listen.
+func startHTTPServer(t *testing.T, subject *xconf.HTTPServer, config xconf.Config) chan error {
+	t.Helper()
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- subject.ListenAndServe(config)
+	}()
+	for i := 0; i < 200 && subject.Addr() == nil; i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if subject.Addr() == nil {
+		t.Fatal("server did not start listening")
+	}
+
+	return errC
+}
+
+// httpGet makes a GET request and returns the response body.
+func httpGet(addr net.Addr) (string, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get("http://" + addr.String())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+
+	return string(body), err
+}
+
+// helloHandler is a simple handler, used in tests.
+var helloHandler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	_, _ = w.Write([]byte("hello"))
+})
+
+func testHTTPServerSwapKeepsListener(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		startedC = make(chan struct{})
+		releaseC = make(chan struct{})
+		handler  = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/slow" {
+				close(startedC)
+				<-releaseC
+			}
+			_, _ = w.Write([]byte("hello"))
+		})
+		config = xconf.NewMockConfig(
+			"http.server.addr", "127.0.0.1:0",
+			"http.server.read_timeout", "5s",
+		)
+		subject = xconf.NewHTTPServer(handler)
+		errC    = startHTTPServer(t, subject, config)
+		addr    = subject.Addr()
+	)
+	defer func() {
+		requireNil(t, subject.Shutdown(context.Background()))
+		assertNil(t, <-errC)
+	}()
+	slowRespC := make(chan string, 1)
+	go func() {
+		client := &http.Client{Timeout: 5 * time.Second}
+		resp, err := client.Get("http://" + addr.String() + "/slow")
+		if err != nil {
+			slowRespC <- err.Error()
+
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		slowRespC <- string(body)
+	}()
+	<-startedC
+	config.SetKeyValues("http.server.write_timeout", "10s")
+
+	// act
+	subject.OnConfigChange(config, "http.server.write_timeout")
+
+	// assert
+	assertEqual(t, addr.String(), subject.Addr().String())
+	body, err := httpGet(subject.Addr())
+	assertNil(t, err)
+	assertEqual(t, "hello", body)
+	close(releaseC)
+	assertEqual(t, "hello", <-slowRespC) // in-flight request on old server finished.
+}
+
+func testHTTPServerSwapRebindsListener(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		config  = xconf.NewMockConfig("server.addr", "127.0.0.1:0")
+		subject = xconf.NewHTTPServer(
+			helloHandler,
+			xconf.HTTPServerWithKeysPrefix("server."),
+			xconf.HTTPServerWithErrorHandler(func(err error) {
+				t.Error("unexpected error", err)
+			}),
+		)
+		errC    = startHTTPServer(t, subject, config)
+		oldAddr = subject.Addr()
+	)
+	defer func() {
+		requireNil(t, subject.Shutdown(context.Background()))
+		assertNil(t, <-errC)
+	}()
+	config.SetKeyValues("server.addr", "localhost:0")
+
+	// act
+	subject.OnConfigChange(config, "SERVER.ADDR")
+
+	// assert
+	newAddr := subject.Addr()
+	assertTrue(t, oldAddr.String() != newAddr.String())
+	body, err := httpGet(newAddr)
+	assertNil(t, err)
+	assertEqual(t, "hello", body)
+	var oldAddrErr error
+	for i := 0; i < 200 && oldAddrErr == nil; i++ { // old listener gets closed async.
+		_, oldAddrErr = httpGet(oldAddr)
+		time.Sleep(5 * time.Millisecond)
+	}
+	assertNotNil(t, oldAddrErr)
+}
+
+func testHTTPServerIgnoresNotRelatedKeys(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		config  = xconf.NewMockConfig("http.server.addr", "127.0.0.1:0")
+		subject = xconf.NewHTTPServer(helloHandler)
+		errC    = startHTTPServer(t, subject, config)
+		addr    = subject.Addr()
+	)
+	defer func() {
+		requireNil(t, subject.Shutdown(context.Background()))
+		assertNil(t, <-errC)
+	}()
+	config.SetKeyValues("http.server.addr", "localhost:0")
+
+	// act
+	subject.OnConfigChange(config, "db.host")
+
+	// assert
+	assertEqual(t, addr.String(), subject.Addr().String())
+}
+
+func testHTTPServerInvalidTLSKeepsOldServer(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		handledErr error
+		config     = xconf.NewMockConfig("http.server.addr", "127.0.0.1:0")
+		subject    = xconf.NewHTTPServer(
+			helloHandler,
+			xconf.HTTPServerWithErrorHandler(func(err error) {
+				handledErr = err
+			}),
+		)
+		errC = startHTTPServer(t, subject, config)
+		addr = subject.Addr()
+	)
+	defer func() {
+		requireNil(t, subject.Shutdown(context.Background()))
+		assertNil(t, <-errC)
+	}()
+	config.SetKeyValues(
+		"http.server.tls_cert_file", "testdata/this-file-does-not-exist.crt",
+		"http.server.tls_key_file", "testdata/this-file-does-not-exist.key",
+	)
+
+	// act
+	subject.OnConfigChange(config, "http.server.tls_cert_file", "http.server.tls_key_file")
+
+	// assert
+	assertNotNil(t, handledErr)
+	assertEqual(t, addr.String(), subject.Addr().String())
+	body, err := httpGet(addr)
+	assertNil(t, err)
+	assertEqual(t, "hello", body)
+}
+
+func testHTTPServerReturnsListenErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		config  = xconf.NewMockConfig("http.server.addr", "127.0.0.1:-1")
+		subject = xconf.NewHTTPServer(helloHandler)
+	)
+
+	// act
+	err := subject.ListenAndServe(config)
+
+	// assert
+	assertNotNil(t, err)
+	assertNil(t, subject.Addr())
+}
+
+func testHTTPServerReturnsErrIfClosed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		config  = xconf.NewMockConfig("http.server.addr", "127.0.0.1:0")
+		subject = xconf.NewHTTPServer(helloHandler)
+	)
+	requireNil(t, subject.Shutdown(context.Background()))
+
+	// act
+	err := subject.ListenAndServe(config)
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrHTTPServerClosed))
+}
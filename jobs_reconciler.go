@@ -0,0 +1,222 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/actforgood/xerr"
+	"github.com/spf13/cast"
+)
+
+// ErrInvalidJobDefinition is an error returned by [JobsReconciler] if
+// the jobs' definitions list is not valid.
+var ErrInvalidJobDefinition = errors.New("invalid job definition")
+
+// ScheduledJob is a job definition, read from configuration.
+type ScheduledJob struct {
+	// Name uniquely identifies the job.
+	Name string
+	// Schedule is the job's schedule, in the format of the scheduler (like a cron expression).
+	Schedule string
+	// Params are optional job's parameters.
+	Params map[string]any
+}
+
+// JobScheduler is the contract for a job scheduler (usually, an adapter over a cron library)
+// reconciled by a [JobsReconciler].
+type JobScheduler interface {
+	// AddJob schedules a new job.
+	AddJob(job ScheduledJob) error
+	// UpdateJob reschedules an existing job, whose schedule and/or parameters changed.
+	UpdateJob(job ScheduledJob) error
+	// RemoveJob unschedules an existing job.
+	RemoveJob(name string) error
+}
+
+// JobsReconciler reconciles a [JobScheduler] with a list of job definitions
+// stored under a configuration key: jobs not scheduled yet are added, jobs no longer
+// defined are removed, and jobs whose definition changed are updated.
+// Register its OnConfigChange method as an observer on a [DefaultConfig] with reload enabled,
+// and jobs are rescheduled without restarting the app.
+//
+// The key's value is a list of objects with "name", "schedule" and optional "params" fields,
+// or a JSON string of such a list (useful for env variables). Example (YAML):
+//
+//	jobs:
+//	  - name: cleanup
+//	    schedule: "*/5 * * * *"
+//	  - name: report
+//	    schedule: "0 8 * * MON"
+//	    params:
+//	      recipients: ["ops@example.com"]
+type JobsReconciler struct {
+	// key is the key holding jobs' definitions.
+	key string
+	// scheduler is the reconciled job scheduler.
+	scheduler JobScheduler
+	// errHandler is an optional handler for errors occurred on config changes.
+	errHandler func(error)
+	// mu protects current jobs.
+	mu sync.Mutex
+	// current holds the currently scheduled jobs, by name.
+	current map[string]ScheduledJob
+}
+
+// NewJobsReconciler instantiates a new JobsReconciler object.
+func NewJobsReconciler(key string, scheduler JobScheduler, opts ...JobsReconcilerOption) *JobsReconciler {
+	reconciler := &JobsReconciler{
+		key:       key,
+		scheduler: scheduler,
+		current:   make(map[string]ScheduledJob),
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(reconciler)
+	}
+
+	return reconciler
+}
+
+// Reconcile reconciles the scheduler with the job definitions from given config.
+// Call it at your application's startup.
+// If the definitions are not valid, the scheduler is not touched.
+// Scheduler's errors are aggregated, and the scheduler is reconciled again
+// with the failed jobs on the next call.
+func (reconciler *JobsReconciler) Reconcile(config Config) error {
+	desired, err := parseScheduledJobs(config.Get(reconciler.key))
+	if err != nil {
+		return err
+	}
+
+	reconciler.mu.Lock()
+	defer reconciler.mu.Unlock()
+
+	var mErr *xerr.MultiError
+	for _, name := range sortedJobNames(reconciler.current) { // remove jobs no longer defined.
+		if _, found := desired[name]; found {
+			continue
+		}
+		if err := reconciler.scheduler.RemoveJob(name); err != nil {
+			mErr = mErr.Add(err)
+
+			continue
+		}
+		delete(reconciler.current, name)
+	}
+	for _, name := range sortedJobNames(desired) { // add new jobs, update changed ones.
+		job := desired[name]
+		currentJob, found := reconciler.current[name]
+		switch {
+		case !found:
+			err = reconciler.scheduler.AddJob(job)
+		case !reflect.DeepEqual(currentJob, job):
+			err = reconciler.scheduler.UpdateJob(job)
+		default:
+			continue
+		}
+		if err != nil {
+			mErr = mErr.Add(err)
+
+			continue
+		}
+		reconciler.current[name] = job
+	}
+
+	return mErr.ErrOrNil()
+}
+
+// OnConfigChange is a [ConfigObserver] that reconciles the scheduler if jobs' key changed.
+// Errors are passed to the error handler, if set.
+func (reconciler *JobsReconciler) OnConfigChange(config Config, changedKeys ...string) {
+	for _, changedKey := range changedKeys {
+		if strings.EqualFold(changedKey, reconciler.key) {
+			if err := reconciler.Reconcile(config); err != nil && reconciler.errHandler != nil {
+				reconciler.errHandler(err)
+			}
+
+			return
+		}
+	}
+}
+
+// parseScheduledJobs parses the jobs' definitions list into jobs, by name.
+func parseScheduledJobs(value any) (map[string]ScheduledJob, error) {
+	jobs := make(map[string]ScheduledJob)
+	if value == nil {
+		return jobs, nil
+	}
+	if strValue, ok := value.(string); ok {
+		if strings.TrimSpace(strValue) == "" {
+			return jobs, nil
+		}
+		var list []any
+		if err := json.Unmarshal([]byte(strValue), &list); err != nil {
+			return nil, xerr.Wrapf(ErrInvalidJobDefinition, "%v", err)
+		}
+		value = list
+	}
+	list, ok := value.([]any)
+	if !ok {
+		return nil, xerr.Wrapf(ErrInvalidJobDefinition, "expected a list, got %T", value)
+	}
+	for idx, item := range list {
+		definition, err := cast.ToStringMapE(item)
+		if err != nil {
+			return nil, xerr.Wrapf(ErrInvalidJobDefinition, "item #%d is not an object", idx)
+		}
+		job := ScheduledJob{
+			Name:     strings.TrimSpace(cast.ToString(definition["name"])),
+			Schedule: strings.TrimSpace(cast.ToString(definition["schedule"])),
+		}
+		if job.Name == "" || job.Schedule == "" {
+			return nil, xerr.Wrapf(ErrInvalidJobDefinition, "item #%d must have a name and a schedule", idx)
+		}
+		if _, found := jobs[job.Name]; found {
+			return nil, xerr.Wrapf(ErrInvalidJobDefinition, "duplicate job %q", job.Name)
+		}
+		if params, found := definition["params"]; found {
+			if job.Params, err = cast.ToStringMapE(params); err != nil {
+				return nil, xerr.Wrapf(ErrInvalidJobDefinition, "job %q params are not an object", job.Name)
+			}
+		}
+		jobs[job.Name] = job
+	}
+
+	return jobs, nil
+}
+
+// sortedJobNames returns the jobs' names, sorted.
+func sortedJobNames(jobs map[string]ScheduledJob) []string {
+	names := make([]string, 0, len(jobs))
+	for name := range jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// JobsReconcilerOption defines optional function for configuring
+// a JobsReconciler object.
+type JobsReconcilerOption func(*JobsReconciler)
+
+// JobsReconcilerWithErrorHandler sets the handler for errors occurred while
+// reconciling on config changes.
+// You can choose to log the error, for example with [LogErrorHandler].
+//
+// By default, error is simply ignored.
+func JobsReconcilerWithErrorHandler(errHandler func(error)) JobsReconcilerOption {
+	return func(reconciler *JobsReconciler) {
+		reconciler.errHandler = errHandler
+	}
+}
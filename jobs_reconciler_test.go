@@ -0,0 +1,180 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+// jobSchedulerMock is a mock for xconf.JobScheduler, recording operations.
+type jobSchedulerMock struct {
+	ops    []string
+	failOn string
+}
+
+func (mock *jobSchedulerMock) record(op, name string) error {
+	if name == mock.failOn {
+		return fmt.Errorf("intentionally triggered %s error for %s", op, name)
+	}
+	mock.ops = append(mock.ops, op+" "+name)
+
+	return nil
+}
+
+func (mock *jobSchedulerMock) AddJob(job xconf.ScheduledJob) error {
+	return mock.record("add", job.Name)
+}
+
+func (mock *jobSchedulerMock) UpdateJob(job xconf.ScheduledJob) error {
+	return mock.record("update", job.Name)
+}
+
+func (mock *jobSchedulerMock) RemoveJob(name string) error {
+	return mock.record("remove", name)
+}
+
+func TestJobsReconciler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - add, update, remove jobs", testJobsReconcilerReconciles)
+	t.Run("success - JSON string definitions", testJobsReconcilerJSONDefinitions)
+	t.Run("error - scheduler error, retried on next reconcile", testJobsReconcilerRetriesFailedJobs)
+	t.Run("error - invalid definitions", testJobsReconcilerReturnsInvalidDefinitionErr)
+}
+
+func testJobsReconcilerReconciles(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		scheduler = new(jobSchedulerMock)
+		config    = xconf.NewMockConfig("jobs", []any{
+			map[string]any{"name": "cleanup", "schedule": "*/5 * * * *"},
+			map[string]any{"name": "report", "schedule": "0 8 * * MON"},
+			map[string]any{"name": "backup", "schedule": "0 2 * * *"},
+		})
+		subject = xconf.NewJobsReconciler("jobs", scheduler)
+	)
+	requireNil(t, subject.Reconcile(config))
+	assertEqual(t, []string{"add backup", "add cleanup", "add report"}, scheduler.ops)
+	scheduler.ops = nil
+	config.SetKeyValues("jobs", []any{
+		map[string]any{"name": "cleanup", "schedule": "*/5 * * * *"},
+		map[string]any{"name": "report", "schedule": "0 9 * * MON"},
+		map[string]any{"name": "sync", "schedule": "@hourly", "params": map[string]any{"full": true}},
+	})
+
+	// act
+	subject.OnConfigChange(config, "other_key", "JOBS")
+
+	// assert
+	assertEqual(t, []string{"remove backup", "update report", "add sync"}, scheduler.ops)
+}
+
+func testJobsReconcilerJSONDefinitions(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		scheduler = new(jobSchedulerMock)
+		config    = xconf.NewMockConfig(
+			"JOBS", `[{"name": "cleanup", "schedule": "*/5 * * * *", "params": {"dry_run": true}}]`,
+		)
+		subject = xconf.NewJobsReconciler("JOBS", scheduler)
+	)
+
+	// act
+	err := subject.Reconcile(config)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, []string{"add cleanup"}, scheduler.ops)
+}
+
+func testJobsReconcilerRetriesFailedJobs(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		handledErr error
+		scheduler  = &jobSchedulerMock{failOn: "report"}
+		config     = xconf.NewMockConfig("jobs", []any{
+			map[string]any{"name": "cleanup", "schedule": "*/5 * * * *"},
+			map[string]any{"name": "report", "schedule": "0 8 * * MON"},
+		})
+		subject = xconf.NewJobsReconciler(
+			"jobs",
+			scheduler,
+			xconf.JobsReconcilerWithErrorHandler(func(err error) {
+				handledErr = err
+			}),
+		)
+	)
+
+	// act
+	subject.OnConfigChange(config, "jobs")
+
+	// assert
+	assertNotNil(t, handledErr)
+	assertEqual(t, []string{"add cleanup"}, scheduler.ops)
+
+	// arrange
+	scheduler.failOn = ""
+
+	// act
+	err := subject.Reconcile(config)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, []string{"add cleanup", "add report"}, scheduler.ops)
+}
+
+func testJobsReconcilerReturnsInvalidDefinitionErr(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name  string
+		value any
+	}{
+		{name: "not a list", value: map[string]any{"name": "cleanup"}},
+		{name: "invalid JSON", value: `[{"name": `},
+		{name: "item not an object", value: []any{"cleanup"}},
+		{name: "missing schedule", value: []any{map[string]any{"name": "cleanup"}}},
+		{
+			name: "duplicate name",
+			value: []any{
+				map[string]any{"name": "cleanup", "schedule": "@daily"},
+				map[string]any{"name": "cleanup", "schedule": "@hourly"},
+			},
+		},
+		{
+			name:  "params not an object",
+			value: []any{map[string]any{"name": "cleanup", "schedule": "@daily", "params": "x"}},
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			scheduler := new(jobSchedulerMock)
+			subject := xconf.NewJobsReconciler("jobs", scheduler)
+
+			// act
+			err := subject.Reconcile(xconf.NewMockConfig("jobs", test.value))
+
+			// assert
+			assertTrue(t, errors.Is(err, xconf.ErrInvalidJobDefinition))
+			assertNil(t, scheduler.ops)
+		})
+	}
+}
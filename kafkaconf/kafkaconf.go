@@ -0,0 +1,143 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+// Package kafkaconf reads commonly used Kafka client settings from configuration keys under a prefix.
+// It does not depend on a Kafka client library, so it does not build a client library's options /
+// config itself; the returned [Settings] are to be copied onto
+// sarama's (github.com/IBM/sarama) Config:
+//
+//	settings, err := kafkaconf.FromConfig(cfg, "kafka.")
+//	if err != nil {
+//		// handle error
+//	}
+//	saramaCfg := sarama.NewConfig()
+//	saramaCfg.ClientID = settings.ClientID
+//	saramaCfg.Net.DialTimeout = settings.DialTimeout
+//	saramaCfg.Net.TLS.Enable = settings.TLS
+//	saramaCfg.Net.SASL.Enable = settings.SASLMechanism != ""
+//	saramaCfg.Net.SASL.Mechanism = sarama.SASLMechanism(settings.SASLMechanism)
+//	saramaCfg.Net.SASL.User = settings.SASLUsername
+//	saramaCfg.Net.SASL.Password = settings.SASLPassword
+//	client, err := sarama.NewClient(settings.Brokers, saramaCfg)
+//
+// or onto franz-go's (github.com/twmb/franz-go) options, where rotated credentials
+// can be picked up on each new connection:
+//
+//	creds, err := kafkaconf.NewCredentialsProvider(cfg, "kafka.")
+//	if err != nil {
+//		// handle error
+//	}
+//	cfg.RegisterObserver(creds.OnConfigChange) // pick up rotated credentials.
+//	client, err := kgo.NewClient(
+//		kgo.SeedBrokers(settings.Brokers...),
+//		kgo.ClientID(settings.ClientID),
+//		kgo.DialTimeout(settings.DialTimeout),
+//		kgo.SASL(plain.Plain(func(context.Context) (plain.Auth, error) {
+//			user, pass := creds.Credentials()
+//
+//			return plain.Auth{User: user, Pass: pass}, nil
+//		})),
+//	)
+package kafkaconf
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/actforgood/xconf"
+	"github.com/spf13/cast"
+)
+
+// Keys, relative to the prefix.
+const (
+	// BrokersKey is the key for brokers (a list, or a comma separated string of "host:port").
+	BrokersKey = "brokers"
+	// ClientIDKey is the key for client id.
+	ClientIDKey = "client_id"
+	// DialTimeoutKey is the key for dial timeout.
+	DialTimeoutKey = "dial_timeout"
+	// TLSKey is the key for enabling TLS.
+	TLSKey = "tls"
+	// SASLMechanismKey is the key for SASL mechanism, see SASLMechanism* constants.
+	SASLMechanismKey = "sasl_mechanism"
+	// SASLUsernameKey is the key for SASL username (can be a secret reference, see [xconf.ResolveSecretRef]).
+	SASLUsernameKey = "sasl_username"
+	// SASLPasswordKey is the key for SASL password (can be a secret reference, see [xconf.ResolveSecretRef]).
+	SASLPasswordKey = "sasl_password"
+)
+
+// Supported SASL mechanisms.
+const (
+	// SASLMechanismPlain is the PLAIN SASL mechanism.
+	SASLMechanismPlain = "PLAIN"
+	// SASLMechanismSCRAMSHA256 is the SCRAM-SHA-256 SASL mechanism.
+	SASLMechanismSCRAMSHA256 = "SCRAM-SHA-256"
+	// SASLMechanismSCRAMSHA512 is the SCRAM-SHA-512 SASL mechanism.
+	SASLMechanismSCRAMSHA512 = "SCRAM-SHA-512"
+)
+
+// Settings holds commonly used Kafka client settings. It is not a client library's
+// options struct, its fields are meant to be copied onto one (see package's doc).
+// Zero values mean client library's defaults.
+type Settings struct {
+	// Brokers are the seed brokers' addresses.
+	Brokers []string
+	// ClientID is the client id.
+	ClientID string
+	// DialTimeout is the dial timeout.
+	DialTimeout time.Duration
+	// TLS indicates whether TLS is enabled.
+	TLS bool
+	// SASLMechanism is the SASL mechanism (upper case), empty if SASL is not enabled.
+	SASLMechanism string
+	// SASLUsername is the (resolved) SASL username.
+	SASLUsername string
+	// SASLPassword is the (resolved) SASL password.
+	SASLPassword string
+}
+
+// FromConfig reads Kafka client settings from given config's keys under given prefix.
+// SASL username and password are resolved with given resolver, if any, or with [xconf.ResolveSecretRef].
+func FromConfig(config xconf.Config, prefix string, resolver ...xconf.SecretResolver) (Settings, error) {
+	resolve := xconf.ResolveSecretRef
+	if len(resolver) > 0 {
+		resolve = resolver[0]
+	}
+	mechanism := strings.ToUpper(strings.TrimSpace(cast.ToString(config.Get(prefix + SASLMechanismKey))))
+	switch mechanism {
+	case "", SASLMechanismPlain, SASLMechanismSCRAMSHA256, SASLMechanismSCRAMSHA512:
+	default:
+		return Settings{}, fmt.Errorf("unsupported kafka SASL mechanism %q", mechanism)
+	}
+	username, err := resolve(cast.ToString(config.Get(prefix + SASLUsernameKey)))
+	if err != nil {
+		return Settings{}, err
+	}
+	password, err := resolve(cast.ToString(config.Get(prefix + SASLPasswordKey)))
+	if err != nil {
+		return Settings{}, err
+	}
+
+	return Settings{
+		Brokers:       xconf.TrimmedStringList(config.Get(prefix + BrokersKey)),
+		ClientID:      cast.ToString(config.Get(prefix + ClientIDKey)),
+		DialTimeout:   cast.ToDuration(config.Get(prefix + DialTimeoutKey)),
+		TLS:           cast.ToBool(config.Get(prefix + TLSKey)),
+		SASLMechanism: mechanism,
+		SASLUsername:  username,
+		SASLPassword:  password,
+	}, nil
+}
+
+// NewCredentialsProvider returns a credentials provider for the SASL username / password
+// keys under given prefix, see [xconf.CredentialsProvider].
+func NewCredentialsProvider(
+	config xconf.Config,
+	prefix string,
+	opts ...xconf.CredentialsProviderOption,
+) (*xconf.CredentialsProvider, error) {
+	return xconf.NewCredentialsProvider(config, prefix+SASLUsernameKey, prefix+SASLPasswordKey, opts...)
+}
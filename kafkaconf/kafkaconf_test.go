@@ -0,0 +1,92 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package kafkaconf_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+	"github.com/actforgood/xconf/kafkaconf"
+)
+
+func TestFromConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", testFromConfigSuccess)
+	t.Run("error - unsupported SASL mechanism", testFromConfigReturnsMechanismErr)
+	t.Run("error - secret resolver", testFromConfigReturnsResolverErr)
+}
+
+func testFromConfigSuccess(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config := xconf.NewMockConfig(
+		"kafka.brokers", []any{"10.0.0.1:9092", "10.0.0.2:9092"},
+		"kafka.client_id", "orders-service",
+		"kafka.dial_timeout", "5s",
+		"kafka.sasl_mechanism", "scram-sha-512",
+		"kafka.sasl_username", "orders",
+		"kafka.sasl_password", "s3cr3t",
+	)
+
+	// act
+	opts, err := kafkaconf.FromConfig(config, "kafka.")
+
+	// assert
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	expectedOpts := kafkaconf.Settings{
+		Brokers:       []string{"10.0.0.1:9092", "10.0.0.2:9092"},
+		ClientID:      "orders-service",
+		DialTimeout:   5 * time.Second,
+		SASLMechanism: kafkaconf.SASLMechanismSCRAMSHA512,
+		SASLUsername:  "orders",
+		SASLPassword:  "s3cr3t",
+	}
+	if !reflect.DeepEqual(expectedOpts, opts) {
+		t.Errorf("expected %+v, but got %+v", expectedOpts, opts)
+	}
+}
+
+func testFromConfigReturnsMechanismErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config := xconf.NewMockConfig("kafka.sasl_mechanism", "GSSAPI")
+
+	// act
+	_, err := kafkaconf.FromConfig(config, "kafka.")
+
+	// assert
+	if err == nil {
+		t.Error("expected error")
+	}
+}
+
+func testFromConfigReturnsResolverErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered resolver error")
+		resolver    = func(string) (string, error) {
+			return "", expectedErr
+		}
+	)
+
+	// act
+	_, err := kafkaconf.FromConfig(xconf.NewMockConfig(), "kafka.", resolver)
+
+	// assert
+	if !errors.Is(err, expectedErr) {
+		t.Errorf("expected error %v, but got %v", expectedErr, err)
+	}
+}
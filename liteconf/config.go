@@ -0,0 +1,183 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package liteconf
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config is a minimal configuration, loaded once (no reload, no observers).
+// It satisfies xconf.Config contract.
+type Config struct {
+	configMap map[string]any // the loaded key-value configuration map.
+}
+
+// NewConfig instantiates a new Config object, loading the configuration from given loader.
+func NewConfig(loader Loader) (*Config, error) {
+	configMap, err := loader.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{configMap: configMap}, nil
+}
+
+// Get returns a configuration value for a given key.
+// The first parameter is the key to return the value for.
+// The second parameter is optional, and represents a default
+// value in case key is not found. It also has a role in inferring
+// the type of key's value (if it exists) and thus key's value
+// will be casted to default's value type.
+// Only string, bool, int, int64, uint, float64 and time.Duration are covered.
+// If a cast error occurs, the default value is returned.
+func (cfg *Config) Get(key string, def ...any) any {
+	value, foundKey := cfg.configMap[key]
+	if len(def) > 0 {
+		defaultValue := def[0]
+		if !foundKey {
+			return defaultValue
+		}
+		if defaultValue != nil {
+			castValue, ok := castValueByDefault(value, defaultValue)
+			if !ok {
+				return defaultValue
+			}
+
+			return castValue
+		}
+	}
+
+	return value
+}
+
+// castValueByDefault casts a key's value to provided default value's type.
+// It returns false if value cannot be casted.
+func castValueByDefault(value, defaultValue any) (any, bool) {
+	switch defaultValue.(type) {
+	case string:
+		return toString(value)
+	case bool:
+		return toBool(value)
+	case int:
+		v, ok := toInt64(value)
+
+		return int(v), ok
+	case int64:
+		return toInt64(value)
+	case uint:
+		v, ok := toInt64(value)
+
+		return uint(v), ok && v >= 0
+	case float64:
+		return toFloat64(value)
+	case time.Duration:
+		return toDuration(value)
+	default:
+		return value, true // not supported cast type, return directly the value.
+	}
+}
+
+// toString casts value to string.
+func toString(value any) (any, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case int:
+		return strconv.Itoa(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	}
+
+	return "", false
+}
+
+// toBool casts value to bool.
+func toBool(value any) (any, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		b, err := strconv.ParseBool(v)
+
+		return b, err == nil
+	case int:
+		return v != 0, true
+	}
+
+	return false, false
+}
+
+// toInt64 casts value to int64.
+func toInt64(value any) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case int32:
+		return int64(v), true
+	case uint:
+		return int64(v), true
+	case float64:
+		return int64(v), float64(int64(v)) == v
+	case string:
+		i, err := strconv.ParseInt(v, 0, 64)
+
+		return i, err == nil
+	}
+
+	return 0, false
+}
+
+// toFloat64 casts value to float64.
+func toFloat64(value any) (any, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+
+		return f, err == nil
+	}
+
+	return float64(0), false
+}
+
+// toDuration casts value to time.Duration.
+// Integers (and strings without unit) are treated as nanoseconds.
+func toDuration(value any) (any, bool) {
+	switch v := value.(type) {
+	case time.Duration:
+		return v, true
+	case int:
+		return time.Duration(v), true
+	case int64:
+		return time.Duration(v), true
+	case string:
+		if !strings.ContainsAny(v, "nsuµmh") {
+			v += "ns"
+		}
+		d, err := time.ParseDuration(v)
+
+		return d, err == nil
+	}
+
+	return time.Duration(0), false
+}
@@ -0,0 +1,107 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package liteconf_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+	"github.com/actforgood/xconf/liteconf"
+)
+
+// liteconf Config satisfies xconf's Config contract.
+var _ xconf.Config = (*liteconf.Config)(nil)
+
+func TestConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - get with cast", testConfigGetWithCast)
+	t.Run("error - loader error", testConfigReturnsLoaderErr)
+}
+
+func testConfigGetWithCast(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := liteconf.NewConfig(liteconf.PlainLoader(map[string]any{
+		"string":       "foo",
+		"int_string":   "8080",
+		"hex_string":   "0x10",
+		"bool_string":  "true",
+		"float_string": "3.14",
+		"dur_string":   "1m30s",
+		"dur_nounit":   "1000",
+		"int":          42,
+		"float":        2.0,
+		"negative":     "-1",
+		"slice":        []string{"a", "b"},
+	}))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	tests := [...]struct {
+		name     string
+		key      string
+		def      []any
+		expected any
+	}{
+		{"no default", "string", nil, "foo"},
+		{"nil default", "int", []any{nil}, 42},
+		{"not found key", "not_found", nil, nil},
+		{"not found key with default", "not_found", []any{"bar"}, "bar"},
+		{"string", "string", []any{""}, "foo"},
+		{"int to string", "int", []any{""}, "42"},
+		{"string to int", "int_string", []any{0}, 8080},
+		{"hex string to int64", "hex_string", []any{int64(0)}, int64(16)},
+		{"integral float to int", "float", []any{0}, 2},
+		{"string to uint", "int_string", []any{uint(0)}, uint(8080)},
+		{"negative to uint fails", "negative", []any{uint(1)}, uint(1)},
+		{"string to bool", "bool_string", []any{false}, true},
+		{"string to float64", "float_string", []any{0.0}, 3.14},
+		{"string to duration", "dur_string", []any{time.Duration(0)}, 90 * time.Second},
+		{"string without unit to duration", "dur_nounit", []any{time.Duration(0)}, time.Microsecond},
+		{"cast fails returns default", "string", []any{7}, 7},
+		{"not supported type returns value", "slice", []any{[]string{}}, []string{"a", "b"}},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			result := subject.Get(test.key, test.def...)
+
+			// assert
+			if !reflect.DeepEqual(test.expected, result) {
+				t.Errorf("expected %+v (%T), but got %+v (%T)", test.expected, test.expected, result, result)
+			}
+		})
+	}
+}
+
+func testConfigReturnsLoaderErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	expectedErr := errors.New("intentionally triggered load error")
+
+	// act
+	subject, err := liteconf.NewConfig(liteconf.LoaderFunc(func() (map[string]any, error) {
+		return nil, expectedErr
+	}))
+
+	// assert
+	if !errors.Is(err, expectedErr) {
+		t.Errorf("expected %v, but got %v", expectedErr, err)
+	}
+	if subject != nil {
+		t.Errorf("expected nil config, but got %+v", subject)
+	}
+}
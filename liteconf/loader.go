@@ -0,0 +1,170 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+// Package liteconf is a reduced-dependency subset of xconf, meant for
+// TinyGo / embedded targets, where binary size matters.
+// It does not depend on cast, remote clients (etcd, grpc, ...), third party parsers
+// or xconf itself, only on the standard library. Dotenv and Java Properties
+// parsing is done by minimal built-in parsers, covering the commonly used syntax.
+//
+// Its Loader and Config contracts are the same as xconf's, thus liteconf loaders
+// can be used with xconf, and liteconf's Config can be used where a xconf.Config is expected:
+//
+//	cfg, err := liteconf.NewConfig(
+//		liteconf.NewMultiLoader(
+//			liteconf.PlainLoader(map[string]any{"port": 8080}), // defaults
+//			liteconf.DotEnvFileLoader("/etc/device.env"),
+//		),
+//	)
+//	if err != nil {
+//		// handle error
+//	}
+//	port := cfg.Get("port", 0).(int)
+package liteconf
+
+import (
+	"io"
+	"os"
+)
+
+// Loader is responsible for loading a configuration key value map.
+// It's the same contract as xconf.Loader.
+type Loader interface {
+	// Load returns a configuration key value map or an error.
+	// The returned map should be safe for an eventual later mutation.
+	Load() (map[string]any, error)
+}
+
+// The LoaderFunc type is an adapter to allow the use of
+// ordinary functions as Loaders.
+type LoaderFunc func() (map[string]any, error)
+
+// Load calls fn().
+func (fn LoaderFunc) Load() (map[string]any, error) {
+	return fn()
+}
+
+// PlainLoader is an explicit go configuration map retriever.
+// It simply returns a (shallow) copy of the given config map parameter.
+func PlainLoader(configMap map[string]any) Loader {
+	configMapCopy := copyConfigMap(configMap)
+
+	return LoaderFunc(func() (map[string]any, error) {
+		return copyConfigMap(configMapCopy), nil
+	})
+}
+
+// NewMultiLoader returns a loader that loads and merges configuration from multiple loaders,
+// sequentially. A later provided loader overwrites a previous provided loader's same found key.
+// The first error encountered is returned.
+func NewMultiLoader(loaders ...Loader) Loader {
+	return LoaderFunc(func() (map[string]any, error) {
+		configMap := make(map[string]any)
+		for _, loader := range loaders {
+			currentConfigMap, err := loader.Load()
+			if err != nil {
+				return nil, err
+			}
+			for key, value := range currentConfigMap {
+				configMap[key] = value
+			}
+		}
+
+		return configMap, nil
+	})
+}
+
+// EnvLoader loads configuration from OS's ENV.
+func EnvLoader() Loader {
+	return LoaderFunc(func() (map[string]any, error) {
+		envs := os.Environ()
+
+		configMap := make(map[string]any, len(envs))
+		const kvSeparator = '='
+		for _, env := range envs {
+			for i := 0; i < len(env); i++ {
+				if env[i] == kvSeparator {
+					configMap[env[:i]] = env[i+1:]
+
+					break
+				}
+			}
+		}
+
+		return configMap, nil
+	})
+}
+
+// DotEnvFileLoader loads dotenv configuration from a file.
+func DotEnvFileLoader(filePath string) Loader {
+	return LoaderFunc(func() (map[string]any, error) {
+		f, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		return DotEnvReaderLoader(f).Load()
+	})
+}
+
+// DotEnvReaderLoader loads dotenv configuration from an [io.Reader].
+func DotEnvReaderLoader(reader io.Reader) Loader {
+	return LoaderFunc(func() (map[string]any, error) {
+		if seekReader, ok := reader.(io.Seeker); ok {
+			_, _ = seekReader.Seek(0, io.SeekStart) // move to the beginning in case of a re-load needed.
+		}
+		envs, err := parseDotEnv(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		configMap := make(map[string]any, len(envs))
+		for key, value := range envs {
+			configMap[key] = value
+		}
+
+		return configMap, nil
+	})
+}
+
+// PropertiesFileLoader loads Java Properties configuration from a file.
+func PropertiesFileLoader(filePath string) Loader {
+	return LoaderFunc(func() (map[string]any, error) {
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		return PropertiesBytesLoader(content).Load()
+	})
+}
+
+// PropertiesBytesLoader loads Java Properties configuration from bytes.
+func PropertiesBytesLoader(propertiesContent []byte) Loader {
+	return LoaderFunc(func() (map[string]any, error) {
+		props, err := parseProperties(propertiesContent)
+		if err != nil {
+			return nil, err
+		}
+
+		configMap := make(map[string]any, len(props))
+		for key, value := range props {
+			configMap[key] = value
+		}
+
+		return configMap, nil
+	})
+}
+
+// copyConfigMap returns a shallow copy of given configuration map.
+func copyConfigMap(configMap map[string]any) map[string]any {
+	configMapCopy := make(map[string]any, len(configMap))
+	for key, value := range configMap {
+		configMapCopy[key] = value
+	}
+
+	return configMapCopy
+}
@@ -0,0 +1,344 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package liteconf_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/xconf"
+	"github.com/actforgood/xconf/liteconf"
+)
+
+// liteconf loaders satisfy xconf's Loader contract.
+var _ xconf.Loader = liteconf.PlainLoader(nil)
+
+func TestPlainLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		configMap = map[string]any{"foo": "bar"}
+		subject   = liteconf.PlainLoader(configMap)
+	)
+	configMap["foo"] = "modified before load"
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if !reflect.DeepEqual(map[string]any{"foo": "bar"}, config) {
+		t.Errorf("unexpected config %+v", config)
+	}
+
+	// act - mutate loaded config, and load again
+	config["foo"] = "modified after load"
+	config, _ = subject.Load()
+
+	// assert
+	if config["foo"] != "bar" {
+		t.Errorf("expected loader to be safe for mutation, but got %+v", config)
+	}
+}
+
+func TestMultiLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - later loaders overwrite keys", testMultiLoaderOverwritesKeys)
+	t.Run("error - first error is returned", testMultiLoaderReturnsErr)
+}
+
+func testMultiLoaderOverwritesKeys(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := liteconf.NewMultiLoader(
+		liteconf.PlainLoader(map[string]any{"foo": "bar", "port": 8080}),
+		liteconf.PlainLoader(map[string]any{"port": 9090}),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	expectedConfig := map[string]any{"foo": "bar", "port": 9090}
+	if !reflect.DeepEqual(expectedConfig, config) {
+		t.Errorf("expected %+v, but got %+v", expectedConfig, config)
+	}
+}
+
+func testMultiLoaderReturnsErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered load error")
+		subject     = liteconf.NewMultiLoader(
+			liteconf.PlainLoader(map[string]any{"foo": "bar"}),
+			liteconf.LoaderFunc(func() (map[string]any, error) {
+				return nil, expectedErr
+			}),
+		)
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	if !errors.Is(err, expectedErr) {
+		t.Errorf("expected %v, but got %v", expectedErr, err)
+	}
+	if config != nil {
+		t.Errorf("expected nil config, but got %+v", config)
+	}
+}
+
+func TestEnvLoader(t *testing.T) {
+	// Note: do not run this test with t.Parallel() as it sets ENVs.
+	t.Setenv("LITECONF_TEST_ENV", "foo=bar")
+
+	// act
+	config, err := liteconf.EnvLoader().Load()
+
+	// assert
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if config["LITECONF_TEST_ENV"] != "foo=bar" {
+		t.Errorf("unexpected value %+v", config["LITECONF_TEST_ENV"])
+	}
+}
+
+func TestDotEnvLoader(t *testing.T) {
+	// Note: do not run this test with t.Parallel() as it sets ENVs.
+	t.Setenv("LITECONF_TEST_HOST", "localhost")
+
+	t.Run("success - reader", testDotEnvReaderLoaderSuccess)
+	t.Run("success - file", testDotEnvFileLoaderSuccess)
+	t.Run("error - invalid content", testDotEnvReaderLoaderReturnsParseErr)
+	t.Run("error - file not found", testDotEnvFileLoaderReturnsErrNotExist)
+}
+
+func testDotEnvReaderLoaderSuccess(t *testing.T) {
+	// arrange
+	content := `# comment
+export APP_NAME=demo
+APP_PORT = 8080 # inline comment
+APP_URL=http://${LITECONF_TEST_HOST}:${APP_PORT}
+APP_LITERAL='${APP_NAME}\n'
+APP_MULTILINE="first line
+second \"line\"\tend"
+APP_YAML_LIKE: value
+`
+	subject := liteconf.DotEnvReaderLoader(strings.NewReader(content))
+	expectedConfig := map[string]any{
+		"APP_NAME":      "demo",
+		"APP_PORT":      "8080",
+		"APP_URL":       "http://localhost:8080",
+		"APP_LITERAL":   `${APP_NAME}\n`,
+		"APP_MULTILINE": "first line\nsecond \"line\"\tend",
+		"APP_YAML_LIKE": "value",
+	}
+
+	for i := 0; i < 2; i++ { // loading twice works, reader is rewound.
+		// act
+		config, err := subject.Load()
+
+		// assert
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if !reflect.DeepEqual(expectedConfig, config) {
+			t.Errorf("expected %+v, but got %+v", expectedConfig, config)
+		}
+	}
+}
+
+func testDotEnvFileLoaderSuccess(t *testing.T) {
+	// arrange
+	filePath := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(filePath, []byte("FOO=bar\r\nBAZ=\"qux\"\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	subject := liteconf.DotEnvFileLoader(filePath)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	expectedConfig := map[string]any{"FOO": "bar", "BAZ": "qux"}
+	if !reflect.DeepEqual(expectedConfig, config) {
+		t.Errorf("expected %+v, but got %+v", expectedConfig, config)
+	}
+}
+
+func testDotEnvReaderLoaderReturnsParseErr(t *testing.T) {
+	tests := [...]struct {
+		name        string
+		content     string
+		expectedErr string
+	}{
+		{
+			name:        "missing separator",
+			content:     "FOO=bar\nBAZ\n",
+			expectedErr: "liteconf - parse error: dotenv line 2: missing separator",
+		},
+		{
+			name:        "unterminated single quote",
+			content:     "FOO='bar\n",
+			expectedErr: "liteconf - parse error: dotenv line 1: unterminated single quoted value",
+		},
+		{
+			name:        "unterminated double quote",
+			content:     "FOO=bar\nBAZ=\"qux\nquux\n",
+			expectedErr: "liteconf - parse error: dotenv line 2: unterminated double quoted value",
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			// arrange
+			subject := liteconf.DotEnvReaderLoader(strings.NewReader(test.content))
+
+			// act
+			config, err := subject.Load()
+
+			// assert
+			if !errors.Is(err, liteconf.ErrParse) {
+				t.Fatalf("expected %v, but got %v", liteconf.ErrParse, err)
+			}
+			if err.Error() != test.expectedErr {
+				t.Errorf("expected %q, but got %q", test.expectedErr, err.Error())
+			}
+			if config != nil {
+				t.Errorf("expected nil config, but got %+v", config)
+			}
+		})
+	}
+}
+
+func testDotEnvFileLoaderReturnsErrNotExist(t *testing.T) {
+	// arrange
+	subject := liteconf.DotEnvFileLoader(filepath.Join(t.TempDir(), "not-existing.env"))
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected %v, but got %v", os.ErrNotExist, err)
+	}
+	if config != nil {
+		t.Errorf("expected nil config, but got %+v", config)
+	}
+}
+
+func TestPropertiesLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - bytes", testPropertiesBytesLoaderSuccess)
+	t.Run("success - file", testPropertiesFileLoaderSuccess)
+	t.Run("error - invalid unicode escape", testPropertiesBytesLoaderReturnsParseErr)
+}
+
+func testPropertiesBytesLoaderSuccess(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	content := `# comment
+! another comment
+app.name = demo
+app.port:8080
+app.description Some description
+app.url=http://localhost:${app.port}/${app.name}
+app.list = one, \
+           two, \
+           three
+app.escaped\ key = tab\there \u00e9
+app.missing=${not.found}
+app.empty=
+`
+	subject := liteconf.PropertiesBytesLoader([]byte(content))
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	expectedConfig := map[string]any{
+		"app.name":        "demo",
+		"app.port":        "8080",
+		"app.description": "Some description",
+		"app.url":         "http://localhost:8080/demo",
+		"app.list":        "one, two, three",
+		"app.escaped key": "tab\there é",
+		"app.missing":     "${not.found}",
+		"app.empty":       "",
+	}
+	if !reflect.DeepEqual(expectedConfig, config) {
+		t.Errorf("expected %+v, but got %+v", expectedConfig, config)
+	}
+}
+
+func testPropertiesFileLoaderSuccess(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	filePath := filepath.Join(t.TempDir(), "config.properties")
+	if err := os.WriteFile(filePath, []byte("foo=bar\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	subject := liteconf.PropertiesFileLoader(filePath)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if !reflect.DeepEqual(map[string]any{"foo": "bar"}, config) {
+		t.Errorf("unexpected config %+v", config)
+	}
+}
+
+func testPropertiesBytesLoaderReturnsParseErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := liteconf.PropertiesBytesLoader([]byte("foo=bar\nbaz=\\u00z9\n"))
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	if !errors.Is(err, liteconf.ErrParse) {
+		t.Fatalf("expected %v, but got %v", liteconf.ErrParse, err)
+	}
+	expectedErr := "liteconf - parse error: properties line 2: invalid unicode escape"
+	if err.Error() != expectedErr {
+		t.Errorf("expected %q, but got %q", expectedErr, err.Error())
+	}
+	if config != nil {
+		t.Errorf("expected nil config, but got %+v", config)
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package liteconf
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrParse is the error returned if dotenv / properties content cannot be parsed.
+var ErrParse = errors.New("liteconf - parse error")
+
+// parseDotEnv parses dotenv content.
+// Supported syntax: KEY=VALUE / KEY: VALUE pairs, optionally prefixed with "export ",
+// "#" comments, single quoted (literal) values, double quoted (escaped, possibly multiline) values,
+// ${VAR} / $VAR expansion in unquoted and double quoted values, from previously parsed keys or ENV.
+func parseDotEnv(reader io.Reader) (map[string]string, error) {
+	lines, err := readLines(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	envs := make(map[string]string)
+	for idx := 0; idx < len(lines); idx++ {
+		line := strings.TrimSpace(lines[idx])
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		sepIdx := strings.IndexAny(line, "=:")
+		if sepIdx <= 0 {
+			return nil, parseErr("dotenv", idx+1, "missing separator")
+		}
+		key := strings.TrimSpace(line[:sepIdx])
+		value := strings.TrimSpace(line[sepIdx+1:])
+
+		switch {
+		case strings.HasPrefix(value, "'"):
+			endIdx := strings.Index(value[1:], "'")
+			if endIdx < 0 {
+				return nil, parseErr("dotenv", idx+1, "unterminated single quoted value")
+			}
+			value = value[1 : endIdx+1]
+		case strings.HasPrefix(value, `"`):
+			startLine := idx
+			value = value[1:]
+			for !hasClosingQuote(value) {
+				if idx++; idx >= len(lines) {
+					return nil, parseErr("dotenv", startLine+1, "unterminated double quoted value")
+				}
+				value += "\n" + lines[idx]
+			}
+			value = expandVars(unescapeDoubleQuoted(value[:closingQuoteIdx(value)]), envs)
+		default:
+			if commentIdx := strings.Index(value, " #"); commentIdx >= 0 {
+				value = strings.TrimSpace(value[:commentIdx])
+			}
+			value = expandVars(value, envs)
+		}
+		envs[key] = value
+	}
+
+	return envs, nil
+}
+
+// closingQuoteIdx returns the index of the first unescaped double quote, or -1.
+func closingQuoteIdx(value string) int {
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+
+	return -1
+}
+
+// hasClosingQuote checks whether value contains an unescaped double quote.
+func hasClosingQuote(value string) bool {
+	return closingQuoteIdx(value) >= 0
+}
+
+// unescapeDoubleQuoted replaces escape sequences of a double quoted value.
+func unescapeDoubleQuoted(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			sb.WriteByte(value[i])
+
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		default:
+			sb.WriteByte(value[i])
+		}
+	}
+
+	return sb.String()
+}
+
+// expandVars replaces ${VAR} / $VAR with values from envs, or from ENV.
+func expandVars(value string, envs map[string]string) string {
+	if !strings.Contains(value, "$") {
+		return value
+	}
+
+	return os.Expand(value, func(name string) string {
+		if v, found := envs[name]; found {
+			return v
+		}
+
+		return os.Getenv(name)
+	})
+}
+
+// parseProperties parses Java Properties content.
+// Supported syntax: key=value / key:value / key value pairs, "#" / "!" comments,
+// line continuation with "\", escape sequences (including \uXXXX),
+// ${key} expansion from other keys.
+func parseProperties(content []byte) (map[string]string, error) {
+	lines, err := readLines(strings.NewReader(string(content)))
+	if err != nil {
+		return nil, err
+	}
+
+	props := make(map[string]string)
+	for idx := 0; idx < len(lines); idx++ {
+		line := strings.TrimLeft(lines[idx], " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		startLine := idx
+		for endsWithContinuation(line) {
+			line = line[:len(line)-1]
+			if idx++; idx >= len(lines) {
+				break
+			}
+			line += strings.TrimLeft(lines[idx], " \t\f")
+		}
+
+		keyEndIdx := len(line)
+		for i := 0; i < len(line); i++ {
+			if line[i] == '\\' {
+				i++
+
+				continue
+			}
+			if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+				keyEndIdx = i
+
+				break
+			}
+		}
+		rest := strings.TrimLeft(line[keyEndIdx:], " \t\f")
+		if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+			rest = strings.TrimLeft(rest[1:], " \t\f")
+		}
+		key, err := unescapeProperty(line[:keyEndIdx])
+		if err != nil {
+			return nil, parseErr("properties", startLine+1, err.Error())
+		}
+		value, err := unescapeProperty(rest)
+		if err != nil {
+			return nil, parseErr("properties", startLine+1, err.Error())
+		}
+		props[key] = value
+	}
+
+	for key, value := range props {
+		props[key] = expandProperty(value, props, 0)
+	}
+
+	return props, nil
+}
+
+// endsWithContinuation checks whether line ends with an odd number of backslashes.
+func endsWithContinuation(line string) bool {
+	cnt := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		cnt++
+	}
+
+	return cnt%2 == 1
+}
+
+// unescapeProperty replaces escape sequences of a properties key / value.
+func unescapeProperty(value string) (string, error) {
+	if !strings.Contains(value, `\`) {
+		return value, nil
+	}
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			sb.WriteByte(value[i])
+
+			continue
+		}
+		i++
+		switch value[i] {
+		case 't':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'u':
+			if i+4 >= len(value) {
+				return "", errors.New("invalid unicode escape")
+			}
+			r, err := strconv.ParseUint(value[i+1:i+5], 16, 32)
+			if err != nil {
+				return "", errors.New("invalid unicode escape")
+			}
+			sb.WriteRune(rune(r))
+			i += 4
+		default:
+			sb.WriteByte(value[i])
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// expandProperty replaces ${key} with other keys' values, recursively (up to a depth).
+func expandProperty(value string, props map[string]string, depth int) string {
+	const maxDepth = 10
+	if depth >= maxDepth || !strings.Contains(value, "${") {
+		return value
+	}
+
+	return os.Expand(value, func(name string) string {
+		if v, found := props[name]; found {
+			return expandProperty(v, props, depth+1)
+		}
+
+		return "${" + name + "}"
+	})
+}
+
+// readLines reads all the lines from given reader, without line endings.
+func readLines(reader io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 4096), 1<<20)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimSuffix(scanner.Text(), "\r"))
+	}
+
+	return lines, scanner.Err()
+}
+
+// parseErr returns a parse error for given format and line number.
+func parseErr(format string, lineNo int, reason string) error {
+	return &parseError{format: format, lineNo: lineNo, reason: reason}
+}
+
+// parseError is the error returned if content cannot be parsed.
+type parseError struct {
+	format string
+	lineNo int
+	reason string
+}
+
+// Error returns string representation of the error.
+func (err *parseError) Error() string {
+	return ErrParse.Error() + ": " + err.format + " line " + strconv.Itoa(err.lineNo) + ": " + err.reason
+}
+
+// Is makes the error match [ErrParse].
+func (err *parseError) Is(target error) bool {
+	return target == ErrParse
+}
@@ -5,6 +5,16 @@
 
 package xconf
 
+import (
+	"context"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/actforgood/xerr"
+)
+
 // Loader is responsible for loading a configuration
 // key value map.
 type Loader interface {
@@ -30,3 +40,169 @@ type LoaderFunc func() (map[string]any, error)
 func (fn LoaderFunc) Load() (map[string]any, error) {
 	return fn()
 }
+
+// LoadContext calls fn(), if ctx is not done. It implements [ContextLoader].
+func (fn LoaderFunc) LoadContext(ctx context.Context) (map[string]any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return fn()
+}
+
+// ContextLoader is implemented by loaders which can load configuration
+// under a context (all built-in loaders implement it), so that a hung
+// remote call can be canceled / timed out.
+// Decorators / composite loaders pass the context to the loaders they encapsulate.
+type ContextLoader interface {
+	// LoadContext returns a configuration key value map or an error. See [Loader].
+	LoadContext(ctx context.Context) (map[string]any, error)
+}
+
+// The ContextLoaderFunc type is an adapter to allow the use of
+// ordinary functions as (Context)Loaders. Load calls fn with [context.Background].
+type ContextLoaderFunc func(ctx context.Context) (map[string]any, error)
+
+// Load calls fn(context.Background()).
+func (fn ContextLoaderFunc) Load() (map[string]any, error) {
+	return fn(context.Background())
+}
+
+// LoadContext calls fn(ctx).
+func (fn ContextLoaderFunc) LoadContext(ctx context.Context) (map[string]any, error) {
+	return fn(ctx)
+}
+
+// LoadWithContext loads configuration from given loader under given context.
+// If loader does not implement [ContextLoader], its Load is called, if ctx is not done.
+func LoadWithContext(ctx context.Context, loader Loader) (map[string]any, error) {
+	if ctxLoader, ok := loader.(ContextLoader); ok {
+		return ctxLoader.LoadContext(ctx)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return loader.Load()
+}
+
+// LoaderUnwrapper is implemented by decorators / composite loaders
+// exposing the loader(s) they encapsulate, making a loaders graph walkable.
+type LoaderUnwrapper interface {
+	// Unwrap returns the encapsulated loader(s).
+	Unwrap() []Loader
+}
+
+// loaderMapper is implemented by built-in decorators / composite loaders able to return
+// a copy of themselves, encapsulating other loaders instead of their own
+// (the loaders returned by given function for each of their own loaders).
+type loaderMapper interface {
+	mapLoaders(fn func(Loader) Loader) Loader
+}
+
+// CloseLoaders walks the loaders graph starting from given root and
+// closes every loader implementing [io.Closer] (like [EtcdLoader] with watcher).
+// Decorators / composite loaders implementing [LoaderUnwrapper] are walked through,
+// their encapsulated loaders being closed.
+// All loaders are tried to be closed, errors being aggregated.
+//
+// Example:
+//
+//	loader := xconf.FilterKVLoader(
+//		xconf.NewMultiLoader(true, etcdLoader, xconf.EnvLoader()),
+//		filters...,
+//	)
+//	// ...
+//	err := xconf.CloseLoaders(loader) // etcdLoader gets closed.
+func CloseLoaders(root Loader) error {
+	var mErr *xerr.MultiError
+	closeLoaders(root, &mErr)
+
+	return mErr.ErrOrNil()
+}
+
+// closeLoaders closes recursively given loader(s).
+func closeLoaders(loader Loader, mErr **xerr.MultiError) {
+	if unwrapper, ok := loader.(LoaderUnwrapper); ok {
+		for _, innerLoader := range unwrapper.Unwrap() {
+			closeLoaders(innerLoader, mErr)
+		}
+
+		return
+	}
+	if closer, ok := loader.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			*mErr = (*mErr).Add(err)
+		}
+	}
+}
+
+// decoratedLoader is a function based decorator which exposes
+// the decorated loader(s), and forwards Close to it (them).
+// The load logic receives the decorated loader(s) as parameter (instead of capturing them),
+// so that the decorator can be rebuilt on top of other loaders (see [InstrumentLoaders]).
+type decoratedLoader struct {
+	// load is the decorator's load logic.
+	load func(ctx context.Context, loaders []Loader) (map[string]any, error)
+	// loaders are the original, decorated loader(s).
+	loaders []Loader
+	// name is the name of the function which created the decorator, the decorator is reported with.
+	name string
+}
+
+// decorate returns a decorator of given loader, with given load logic.
+func decorate(loader Loader, fn func(ctx context.Context, loader Loader) (map[string]any, error)) Loader {
+	return decoratedLoader{
+		load: func(ctx context.Context, loaders []Loader) (map[string]any, error) {
+			return fn(ctx, loaders[0])
+		},
+		loaders: []Loader{loader},
+		name:    funcName(fn),
+	}
+}
+
+// Load returns decorated loader(s)'s altered configuration map.
+func (decorator decoratedLoader) Load() (map[string]any, error) {
+	return decorator.load(context.Background(), decorator.loaders)
+}
+
+// LoadContext is like Load, passing given context to the decorated loader(s).
+// It implements [ContextLoader].
+func (decorator decoratedLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	return decorator.load(ctx, decorator.loaders)
+}
+
+// Unwrap returns the decorated loader(s).
+func (decorator decoratedLoader) Unwrap() []Loader {
+	return decorator.loaders
+}
+
+// Close closes the decorated loader(s).
+func (decorator decoratedLoader) Close() error {
+	return CloseLoaders(decorator)
+}
+
+// mapLoaders returns a copy of the decorator, decorating the loader(s) returned by fn.
+func (decorator decoratedLoader) mapLoaders(fn func(Loader) Loader) Loader {
+	loaders := make([]Loader, len(decorator.loaders))
+	for idx, loader := range decorator.loaders {
+		loaders[idx] = fn(loader)
+	}
+	decorator.loaders = loaders
+
+	return decorator
+}
+
+// funcName returns the name of given function, without package's path and closures' suffixes,
+// like "xconf.JSONFileLoader".
+func funcName(fn any) string {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	if idx := strings.Index(name, ".func"); idx >= 0 {
+		name = name[:idx]
+	}
+
+	return name
+}
@@ -0,0 +1,296 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	// CloudProviderEC2 identifies AWS EC2 instance metadata service (IMDSv2).
+	CloudProviderEC2 = "ec2"
+	// CloudProviderGCE identifies Google Compute Engine metadata server.
+	CloudProviderGCE = "gce"
+	// CloudProviderAzure identifies Azure instance metadata service (IMDS).
+	CloudProviderAzure = "azure"
+)
+
+const (
+	ec2MetadataDefaultHost   = "http://169.254.169.254"
+	gceMetadataDefaultHost   = "http://metadata.google.internal"
+	azureMetadataDefaultHost = "http://169.254.169.254"
+
+	// ec2MetadataTokenTTL is the IMDSv2 session token TTL, in seconds.
+	ec2MetadataTokenTTL = "300"
+	// azureMetadataAPIVersion is the Azure IMDS api version used in requests.
+	azureMetadataAPIVersion = "2021-02-01"
+)
+
+// ErrCloudMetadataNotFound is returned by [CloudMetadataLoader] when a metadata
+// path responds with 404.
+var ErrCloudMetadataNotFound = errors.New("404 - cloud metadata path not found")
+
+// ErrUnknownCloudProvider is returned by [CloudMetadataLoader] when the provider
+// is not one of CloudProvider* constants.
+var ErrUnknownCloudProvider = errors.New("unknown cloud provider")
+
+// cloudMetadataProvider holds the particularities of a cloud metadata service.
+type cloudMetadataProvider struct {
+	// defaultHost is the metadata service host.
+	defaultHost string
+	// metadataURL builds the url for a metadata path.
+	metadataURL func(host, path string) string
+	// userDataURL is the url for instance's user-data.
+	userDataURL func(host string) string
+	// userDataBase64 is a flag indicating whether user-data is base64 encoded.
+	userDataBase64 bool
+	// headers are request headers required by the metadata service.
+	headers map[string]string
+	// withToken is a flag indicating whether a session token must be obtained first (IMDSv2).
+	withToken bool
+}
+
+// cloudMetadataProviders holds supported cloud providers.
+var cloudMetadataProviders = map[string]cloudMetadataProvider{
+	CloudProviderEC2: {
+		defaultHost: ec2MetadataDefaultHost,
+		metadataURL: func(host, path string) string {
+			return host + "/latest/meta-data/" + path
+		},
+		userDataURL: func(host string) string {
+			return host + "/latest/user-data"
+		},
+		withToken: true,
+	},
+	CloudProviderGCE: {
+		defaultHost: gceMetadataDefaultHost,
+		metadataURL: func(host, path string) string {
+			return host + "/computeMetadata/v1/" + path
+		},
+		userDataURL: func(host string) string {
+			return host + "/computeMetadata/v1/instance/attributes/user-data"
+		},
+		headers: map[string]string{"Metadata-Flavor": "Google"},
+	},
+	CloudProviderAzure: {
+		defaultHost: azureMetadataDefaultHost,
+		metadataURL: func(host, path string) string {
+			return host + "/metadata/instance/" + path + "?api-version=" + azureMetadataAPIVersion + "&format=text"
+		},
+		userDataURL: func(host string) string {
+			return host + "/metadata/instance/compute/userData?api-version=" + azureMetadataAPIVersion + "&format=text"
+		},
+		userDataBase64: true,
+		headers:        map[string]string{"Metadata": "true"},
+	},
+}
+
+// CloudMetadataLoader loads configuration from a cloud instance metadata service
+// (AWS EC2 IMDSv2, GCE metadata server, Azure IMDS).
+// Selected metadata paths are exposed as configuration keys, and, optionally,
+// instance's user-data can be parsed into configuration.
+type CloudMetadataLoader struct {
+	provider       string            // one of CloudProvider* constants
+	host           string            // metadata service host
+	paths          map[string]string // configuration key => metadata path
+	userDataKey    string            // user-data configuration key (for plain format)
+	userDataFormat string            // user-data format, one of RemoteValue* constants, empty if disabled
+	httpClient     *http.Client      // the http client used for calls
+	ctx            context.Context   // requests' context
+}
+
+// NewCloudMetadataLoader instantiates a new CloudMetadataLoader object that loads
+// configuration from given cloud provider's instance metadata service.
+// The first parameter is one of CloudProvider* constants.
+func NewCloudMetadataLoader(provider string, opts ...CloudMetadataLoaderOption) CloudMetadataLoader {
+	loader := CloudMetadataLoader{
+		provider:   provider,
+		host:       cloudMetadataProviders[provider].defaultHost,
+		paths:      make(map[string]string),
+		httpClient: newDefaultHTTPClient(),
+		ctx:        context.Background(),
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&loader)
+	}
+
+	return loader
+}
+
+// Load returns a configuration key-value map from cloud metadata service,
+// or an error if something bad happens along the process.
+func (loader CloudMetadataLoader) Load() (map[string]any, error) {
+	return loader.LoadContext(loader.ctx)
+}
+
+// LoadContext is like Load, with the request(s) being canceled also when given context is done.
+// It implements [ContextLoader].
+func (loader CloudMetadataLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	ctx, cancelCtx := mergeContexts(ctx, loader.ctx)
+	defer cancelCtx()
+	loader.ctx = ctx // Note: loader is a copy.
+
+	provider, found := cloudMetadataProviders[loader.provider]
+	if !found {
+		return nil, ErrUnknownCloudProvider
+	}
+
+	headers := make(map[string]string, len(provider.headers)+1)
+	for hName, hValue := range provider.headers {
+		headers[hName] = hValue
+	}
+	if provider.withToken {
+		token, err := loader.getEC2Token()
+		if err != nil {
+			return nil, err
+		}
+		headers["X-aws-ec2-metadata-token"] = token
+	}
+
+	configMap := make(map[string]any, len(loader.paths))
+	for key, path := range loader.paths {
+		value, err := loader.get(provider.metadataURL(loader.host, path), headers)
+		if err != nil {
+			return nil, err
+		}
+		configMap[key] = string(bytes.TrimSpace(value))
+	}
+
+	if loader.userDataFormat != "" {
+		userData, err := loader.get(provider.userDataURL(loader.host), headers)
+		if err != nil {
+			return nil, err
+		}
+		if provider.userDataBase64 {
+			if userData, err = base64.StdEncoding.DecodeString(string(userData)); err != nil {
+				return nil, err
+			}
+		}
+		userDataConfigMap, err := getRemoteKVPairConfigMap(loader.userDataKey, userData, loader.userDataFormat)
+		if err != nil {
+			return nil, err
+		}
+		// Note: here, if a duplicate key exists, it will get overwritten.
+		for key, value := range userDataConfigMap {
+			configMap[key] = value
+		}
+	}
+
+	return configMap, nil
+}
+
+// getEC2Token retrieves an IMDSv2 session token.
+func (loader CloudMetadataLoader) getEC2Token() (string, error) {
+	req, err := http.NewRequestWithContext(loader.ctx, http.MethodPut, loader.host+"/latest/api/token", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", ec2MetadataTokenTTL)
+
+	token, err := loader.do(req)
+	if err != nil {
+		return "", err
+	}
+
+	return string(bytes.TrimSpace(token)), nil
+}
+
+// get performs a GET request against given url.
+func (loader CloudMetadataLoader) get(url string, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(loader.ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for hName, hValue := range headers {
+		req.Header.Set(hName, hValue)
+	}
+
+	return loader.do(req)
+}
+
+// do executes given request and returns response's body.
+func (loader CloudMetadataLoader) do(req *http.Request) ([]byte, error) {
+	resp, err := loader.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer closeResponseBody(resp)
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrCloudMetadataNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("cloud metadata service responded with status code %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// CloudMetadataLoaderOption defines optional function for configuring
+// a Cloud Metadata Loader.
+type CloudMetadataLoaderOption func(*CloudMetadataLoader)
+
+// CloudMetadataLoaderWithPath exposes a metadata path under given configuration key.
+// The path is relative to provider's metadata root.
+//
+// Example:
+//
+//	xconf.NewCloudMetadataLoader(
+//		xconf.CloudProviderEC2,
+//		xconf.CloudMetadataLoaderWithPath("INSTANCE_ID", "instance-id"),
+//		xconf.CloudMetadataLoaderWithPath("AZ", "placement/availability-zone"),
+//	)
+func CloudMetadataLoaderWithPath(key, path string) CloudMetadataLoaderOption {
+	return func(loader *CloudMetadataLoader) {
+		loader.paths[key] = path
+	}
+}
+
+// CloudMetadataLoaderWithUserData enables loading of instance's user-data.
+//
+// If format is [RemoteValueJSON] / [RemoteValueYAML], user-data will be parsed and
+// its configuration will be merged with metadata paths' configuration.
+//
+// If format is [RemoteValuePlain], configuration will contain the given key and
+// user-data's plain value.
+func CloudMetadataLoaderWithUserData(key, format string) CloudMetadataLoaderOption {
+	return func(loader *CloudMetadataLoader) {
+		loader.userDataKey = key
+		loader.userDataFormat = format
+	}
+}
+
+// CloudMetadataLoaderWithHost sets metadata service's base url.
+// By default, provider's well-known link-local address is used.
+func CloudMetadataLoaderWithHost(host string) CloudMetadataLoaderOption {
+	return func(loader *CloudMetadataLoader) {
+		loader.host = host
+	}
+}
+
+// CloudMetadataLoaderWithHTTPClient sets the http client used for calls.
+// A default one is provided if you don't use this option.
+func CloudMetadataLoaderWithHTTPClient(client *http.Client) CloudMetadataLoaderOption {
+	return func(loader *CloudMetadataLoader) {
+		loader.httpClient = client
+	}
+}
+
+// CloudMetadataLoaderWithContext sets requests' context.
+// By default, a context.Background() is used.
+func CloudMetadataLoaderWithContext(ctx context.Context) CloudMetadataLoaderOption {
+	return func(loader *CloudMetadataLoader) {
+		loader.ctx = ctx
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestCloudMetadataLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - ec2", testCloudMetadataLoaderEC2)
+	t.Run("success - gce", testCloudMetadataLoaderGCE)
+	t.Run("success - azure", testCloudMetadataLoaderAzure)
+	t.Run("error - path not found", testCloudMetadataLoaderReturnsErrNotFound)
+	t.Run("error - unexpected status", testCloudMetadataLoaderReturnsErrUnexpectedStatus)
+	t.Run("error - unknown provider", testCloudMetadataLoaderReturnsErrUnknownProvider)
+}
+
+func testCloudMetadataLoaderEC2(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	const token = "ec2-test-token"
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/latest/api/token" {
+			assertEqual(t, http.MethodPut, r.Method)
+			assertEqual(t, "300", r.Header.Get("X-aws-ec2-metadata-token-ttl-seconds"))
+			_, _ = w.Write([]byte(token))
+
+			return
+		}
+		assertEqual(t, token, r.Header.Get("X-aws-ec2-metadata-token"))
+		switch r.URL.Path {
+		case "/latest/meta-data/instance-id":
+			_, _ = w.Write([]byte("i-1234567890abcdef0\n"))
+		case "/latest/user-data":
+			_, _ = w.Write([]byte(`{"ec2_foo":"bar","ec2_year":2022}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+	subject := xconf.NewCloudMetadataLoader(
+		xconf.CloudProviderEC2,
+		xconf.CloudMetadataLoaderWithHost(svr.URL),
+		xconf.CloudMetadataLoaderWithPath("INSTANCE_ID", "instance-id"),
+		xconf.CloudMetadataLoaderWithUserData("USER_DATA", xconf.RemoteValueJSON),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"INSTANCE_ID": "i-1234567890abcdef0",
+			"ec2_foo":     "bar",
+			"ec2_year":    float64(2022),
+		},
+		config,
+	)
+}
+
+func testCloudMetadataLoaderGCE(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertEqual(t, http.MethodGet, r.Method)
+		assertEqual(t, "Google", r.Header.Get("Metadata-Flavor"))
+		switch r.URL.Path {
+		case "/computeMetadata/v1/instance/zone":
+			_, _ = w.Write([]byte("projects/123/zones/europe-west1-b"))
+		case "/computeMetadata/v1/instance/attributes/user-data":
+			_, _ = w.Write([]byte("gce_foo: bar\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+	subject := xconf.NewCloudMetadataLoader(
+		xconf.CloudProviderGCE,
+		xconf.CloudMetadataLoaderWithHost(svr.URL),
+		xconf.CloudMetadataLoaderWithHTTPClient(http.DefaultClient),
+		xconf.CloudMetadataLoaderWithPath("ZONE", "instance/zone"),
+		xconf.CloudMetadataLoaderWithUserData("USER_DATA", xconf.RemoteValueYAML),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"ZONE":    "projects/123/zones/europe-west1-b",
+			"gce_foo": "bar",
+		},
+		config,
+	)
+}
+
+func testCloudMetadataLoaderAzure(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertEqual(t, "true", r.Header.Get("Metadata"))
+		assertEqual(t, "text", r.URL.Query().Get("format"))
+		assertTrue(t, r.URL.Query().Get("api-version") != "")
+		switch r.URL.Path {
+		case "/metadata/instance/compute/location":
+			_, _ = w.Write([]byte("westeurope"))
+		case "/metadata/instance/compute/userData":
+			_, _ = w.Write([]byte("c29tZSB1c2VyIGRhdGE=")) // base64 of "some user data"
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+	subject := xconf.NewCloudMetadataLoader(
+		xconf.CloudProviderAzure,
+		xconf.CloudMetadataLoaderWithHost(svr.URL),
+		xconf.CloudMetadataLoaderWithPath("LOCATION", "compute/location"),
+		xconf.CloudMetadataLoaderWithUserData("USER_DATA", xconf.RemoteValuePlain),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"LOCATION":  "westeurope",
+			"USER_DATA": "some user data",
+		},
+		config,
+	)
+}
+
+func testCloudMetadataLoaderReturnsErrNotFound(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer svr.Close()
+	subject := xconf.NewCloudMetadataLoader(
+		xconf.CloudProviderGCE,
+		xconf.CloudMetadataLoaderWithHost(svr.URL),
+		xconf.CloudMetadataLoaderWithPath("ZONE", "instance/zone"),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, errors.Is(err, xconf.ErrCloudMetadataNotFound))
+}
+
+func testCloudMetadataLoaderReturnsErrUnexpectedStatus(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer svr.Close()
+	subject := xconf.NewCloudMetadataLoader(
+		xconf.CloudProviderEC2,
+		xconf.CloudMetadataLoaderWithHost(svr.URL),
+		xconf.CloudMetadataLoaderWithPath("INSTANCE_ID", "instance-id"),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	if assertNotNil(t, err) {
+		assertTrue(t, strings.Contains(err.Error(), "401"))
+	}
+}
+
+func testCloudMetadataLoaderReturnsErrUnknownProvider(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.NewCloudMetadataLoader("some-unknown-cloud")
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, errors.Is(err, xconf.ErrUnknownCloudProvider))
+}
+
+func ExampleCloudMetadataLoader() {
+	loader := xconf.NewCloudMetadataLoader(
+		xconf.CloudProviderEC2,
+		xconf.CloudMetadataLoaderWithPath("INSTANCE_ID", "instance-id"),
+		xconf.CloudMetadataLoaderWithPath("AZ", "placement/availability-zone"),
+		xconf.CloudMetadataLoaderWithUserData("USER_DATA", xconf.RemoteValueJSON),
+	)
+
+	configMap, err := loader.Load()
+	if err != nil {
+		panic(err)
+	}
+	for key, value := range configMap {
+		fmt.Println(key+":", value)
+	}
+}
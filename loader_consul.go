@@ -7,17 +7,16 @@ package xconf
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"io"
-	"net"
 	"net/http"
 	"os"
-	"runtime"
 	"strconv"
 	"sync"
-	"time"
+	"sync/atomic"
 )
 
 // Note: Consul API ver was 1.12 at the time this code was written.
@@ -56,6 +55,34 @@ const (
 	//
 	// [official client]: https://github.com/hashicorp/consul/blob/v1.12.0/api/api.go#L44
 	consulHTTPSSLEnvName = "CONSUL_HTTP_SSL"
+
+	// consulCACertEnvName defines an environment variable name which sets
+	// the CA file to use for talking to Consul over TLS.
+	// Note: complied with [official client].
+	//
+	// [official client]: https://github.com/hashicorp/consul/blob/v1.12.0/api/api.go
+	consulCACertEnvName = "CONSUL_CACERT"
+
+	// consulClientCertEnvName defines an environment variable name which sets
+	// the client cert file to use for talking to Consul over TLS.
+	// Note: complied with [official client].
+	//
+	// [official client]: https://github.com/hashicorp/consul/blob/v1.12.0/api/api.go
+	consulClientCertEnvName = "CONSUL_CLIENT_CERT"
+
+	// consulClientKeyEnvName defines an environment variable name which sets
+	// the client key file to use for talking to Consul over TLS.
+	// Note: complied with [official client].
+	//
+	// [official client]: https://github.com/hashicorp/consul/blob/v1.12.0/api/api.go
+	consulClientKeyEnvName = "CONSUL_CLIENT_KEY"
+
+	// consulTLSServerNameEnvName defines an environment variable name which sets
+	// the server name to use as the SNI host when connecting via TLS.
+	// Note: complied with [official client].
+	//
+	// [official client]: https://github.com/hashicorp/consul/blob/v1.12.0/api/api.go
+	consulTLSServerNameEnvName = "CONSUL_TLS_SERVER_NAME"
 )
 
 const consulDefaultHost = "http://127.0.0.1:8500"
@@ -63,24 +90,6 @@ const consulDefaultHost = "http://127.0.0.1:8500"
 // ErrConsulKeyNotFound is thrown when a Consul read key request responds with 404.
 var ErrConsulKeyNotFound = errors.New("404 - Consul Key Not Found")
 
-// newDefaultHTTPClient instantiates a new default HTTP client.
-func newDefaultHTTPClient() *http.Client {
-	return &http.Client{
-		Transport: &http.Transport{
-			DialContext: (&net.Dialer{
-				Timeout:   20 * time.Second,
-				KeepAlive: 20 * time.Second,
-			}).DialContext,
-			MaxIdleConns:          64,
-			IdleConnTimeout:       60 * time.Second,
-			TLSHandshakeTimeout:   10 * time.Second,
-			DisableKeepAlives:     false,
-			ExpectContinueTimeout: 1 * time.Second,
-			MaxIdleConnsPerHost:   runtime.GOMAXPROCS(0) + 1,
-		},
-	}
-}
-
 // consulKVPair holds the Key and base64 encoded blob of data.
 type consulKVPair struct {
 	// Key is the main key under which configs are hold.
@@ -94,11 +103,19 @@ type consulKVPair struct {
 
 // ConsulLoader loads configuration from Consul Key-Value Store.
 type ConsulLoader struct {
-	key         string       // the key to load
-	valueFormat string       // value format, one of RemoteValue* constants
-	httpClient  *http.Client // the http client used for calls
-	reqInfo     *requestInfo // extra request info
-	cache       *consulCache // cache storage
+	key         string        // the key to load
+	valueFormat string        // value format, one of RemoteValue* constants
+	httpClient  *http.Client  // the http client used for calls
+	reqInfo     *requestInfo  // extra request info
+	cache       *consulCache  // cache storage
+	tlsConfig   *tls.Config   // the TLS configuration, if custom
+	tlsFiles    consulTLS     // the TLS files / server name
+	initErr     error         // error occurred while configuring the loader, returned by Load
+	lastIndex   *atomic.Int64 // the highest ModifyIndex of last loaded key(s)
+
+	failoverHosts []string               // other clusters' hosts to fail over to
+	failoverOpts  []FailoverLoaderOption // failover options
+	failover      Loader                 // failover loader, if failover hosts are set
 }
 
 // NewConsulLoader instantiates a new ConsulLoader object that loads
@@ -109,23 +126,63 @@ func NewConsulLoader(key string, opts ...ConsulLoaderOption) ConsulLoader {
 		valueFormat: RemoteValuePlain,
 		httpClient:  newDefaultHTTPClient(),
 		reqInfo:     newRequestInfo(),
+		tlsFiles:    getDefaultConsulTLS(),
+		lastIndex:   new(atomic.Int64),
 	}
 
 	// apply options, if any.
 	for _, opt := range opts {
 		opt(&loader)
 	}
+	loader.initErr = loader.configureTLS()
+	if len(loader.failoverHosts) > 0 {
+		loader.failover = newConsulFailoverLoader(loader)
+	}
 
 	return loader
 }
 
+// newConsulFailoverLoader returns a [FailoverLoader] over given loader and
+// copies of it, for each of the failover hosts.
+func newConsulFailoverLoader(primary ConsulLoader) Loader {
+	loaders := make([]Loader, 0, len(primary.failoverHosts)+1)
+	loaders = append(loaders, primary)
+	for _, host := range primary.failoverHosts {
+		clusterLoader := primary
+		reqInfo := *primary.reqInfo
+		reqInfo.baseURL = host
+		clusterLoader.reqInfo = &reqInfo
+		if primary.cache != nil {
+			clusterLoader.cache = &consulCache{obfuscator: primary.cache.obfuscator}
+		}
+		loaders = append(loaders, clusterLoader)
+	}
+
+	return NewFailoverLoader(loaders, primary.failoverOpts...)
+}
+
 // Load returns a configuration key-value map from Consul KV Store, or an error
 // if something bad happens along the process.
 func (loader ConsulLoader) Load() (map[string]any, error) {
+	return loader.LoadContext(loader.reqInfo.ctx)
+}
+
+// LoadContext is like Load, with the request being canceled also when given context is done.
+// It implements [ContextLoader].
+func (loader ConsulLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	if loader.initErr != nil {
+		return nil, loader.initErr
+	}
+	if loader.failover != nil {
+		return LoadWithContext(ctx, loader.failover)
+	}
+
 	endpoint := loader.reqInfo.baseURL + "/v1/kv/" + loader.key
+	ctx, cancelCtx := mergeContexts(ctx, loader.reqInfo.ctx)
+	defer cancelCtx()
 
 	// build the request
-	req, err := buildConsulRequest(loader.reqInfo, endpoint)
+	req, err := buildConsulRequest(ctx, loader.reqInfo, endpoint)
 	if err != nil {
 		return nil, err
 	}
@@ -151,8 +208,25 @@ func (loader ConsulLoader) Load() (map[string]any, error) {
 	return loader.kvPairsLoad(kvPairs)
 }
 
+// SourceVersion returns the highest ModifyIndex of last loaded key(s).
+// It implements [SourceVersioner].
+func (loader ConsulLoader) SourceVersion() SourceVersion {
+	version := SourceVersion{Kind: "consul", Name: loader.key}
+	if lastIndex := loader.lastIndex.Load(); lastIndex > 0 {
+		version.Version = strconv.FormatInt(lastIndex, 10)
+	}
+
+	return version
+}
+
 // consulKVPairsLoad loads config from a Key's Value given the format provided.
 func (loader ConsulLoader) kvPairsLoad(kvPairs []consulKVPair) (map[string]any, error) {
+	var lastIndex int64
+	for _, kvPair := range kvPairs {
+		lastIndex = max(lastIndex, kvPair.ModifyIndex)
+	}
+	loader.lastIndex.Store(lastIndex)
+
 	if configMap := loader.cache.load(kvPairs); configMap != nil {
 		return configMap, nil
 	}
@@ -198,8 +272,8 @@ func (loader ConsulLoader) kvPairsLoad(kvPairs []consulKVPair) (map[string]any,
 
 // buildConsulRequest returns the http request, or an error if it could not be created.
 // Query parameters and headers are set on it, if any.
-func buildConsulRequest(reqInfo *requestInfo, endpoint string) (*http.Request, error) {
-	req, err := http.NewRequestWithContext(reqInfo.ctx, http.MethodGet, endpoint, nil)
+func buildConsulRequest(ctx context.Context, reqInfo *requestInfo, endpoint string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -249,6 +323,64 @@ func getDefaultConsulBaseURL() string {
 	return baseURL
 }
 
+// consulTLS holds the TLS files and the server name used for talking to Consul over TLS.
+type consulTLS struct {
+	caFile     string // the CA file
+	certFile   string // the client cert file
+	keyFile    string // the client key file
+	serverName string // the SNI host
+}
+
+// isZero returns true if no TLS setting is provided.
+func (ct consulTLS) isZero() bool {
+	return ct == consulTLS{}
+}
+
+// getDefaultConsulTLS tries to get TLS files / server name from ENV,
+// as in official client.
+func getDefaultConsulTLS() consulTLS {
+	return consulTLS{
+		caFile:     os.Getenv(consulCACertEnvName),
+		certFile:   os.Getenv(consulClientCertEnvName),
+		keyFile:    os.Getenv(consulClientKeyEnvName),
+		serverName: os.Getenv(consulTLSServerNameEnvName),
+	}
+}
+
+// configureTLS sets up the TLS configuration of the http client's transport
+// (which gets cloned), if a custom TLS configuration / TLS files are provided.
+func (loader *ConsulLoader) configureTLS() error {
+	if loader.tlsConfig == nil && loader.tlsFiles.isZero() {
+		return nil
+	}
+
+	tlsConfig := loader.tlsConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	} else {
+		tlsConfig = tlsConfig.Clone()
+	}
+	if loader.tlsFiles.serverName != "" {
+		tlsConfig.ServerName = loader.tlsFiles.serverName
+	}
+	if err := loadTLSFiles(
+		tlsConfig,
+		loader.tlsFiles.caFile,
+		loader.tlsFiles.certFile,
+		loader.tlsFiles.keyFile,
+	); err != nil {
+		return err
+	}
+
+	httpClient, err := withTLSConfig(loader.httpClient, tlsConfig)
+	if err != nil {
+		return err
+	}
+	loader.httpClient = httpClient
+
+	return nil
+}
+
 // requestInfo is an object holding request information.
 type requestInfo struct {
 	baseURL string            // Consul host
@@ -288,6 +420,44 @@ func ConsulLoaderWithHTTPClient(client *http.Client) ConsulLoaderOption {
 	}
 }
 
+// ConsulLoaderWithTLS sets the TLS configuration (like custom root CAs, client certificate - mTLS)
+// of the http client's transport (which gets cloned, if ConsulLoaderWithHTTPClient is used, too).
+// TLS files options / ENV (see [ConsulLoaderWithCACertFile], [ConsulLoaderWithClientCertFiles]),
+// if provided, are applied on top of it.
+// An error is returned by Load if TLS configuration is needed, and the custom http client's transport
+// is not a *[http.Transport].
+func ConsulLoaderWithTLS(tlsCfg *tls.Config) ConsulLoaderOption {
+	return func(loader *ConsulLoader) {
+		loader.tlsConfig = tlsCfg
+	}
+}
+
+// ConsulLoaderWithCACertFile sets the CA file used to verify Consul server's certificate.
+// By default, is set to CONSUL_CACERT ENV, as in official hashicorp's client.
+func ConsulLoaderWithCACertFile(caFile string) ConsulLoaderOption {
+	return func(loader *ConsulLoader) {
+		loader.tlsFiles.caFile = caFile
+	}
+}
+
+// ConsulLoaderWithClientCertFiles sets the client certificate and key files (mTLS).
+// By default, are set to CONSUL_CLIENT_CERT and CONSUL_CLIENT_KEY ENV, as in official hashicorp's client.
+func ConsulLoaderWithClientCertFiles(certFile, keyFile string) ConsulLoaderOption {
+	return func(loader *ConsulLoader) {
+		loader.tlsFiles.certFile = certFile
+		loader.tlsFiles.keyFile = keyFile
+	}
+}
+
+// ConsulLoaderWithTLSServerName sets the server name used as the SNI host / to verify
+// Consul server's certificate.
+// By default, is set to CONSUL_TLS_SERVER_NAME ENV, as in official hashicorp's client.
+func ConsulLoaderWithTLSServerName(serverName string) ConsulLoaderOption {
+	return func(loader *ConsulLoader) {
+		loader.tlsFiles.serverName = serverName
+	}
+}
+
 // ConsulLoaderWithHost sets Consul's base url.
 // By default, is set to "http://127.0.0.1:8500".
 // Consul host can also be set through CONSUL_HTTP_ADDR and CONSUL_HTTP_SSL
@@ -302,6 +472,30 @@ func ConsulLoaderWithHost(host string) ConsulLoaderOption {
 	}
 }
 
+// ConsulLoaderWithFailoverHosts sets the base urls of other, independent, Consul clusters
+// (like the ones from other regions), to fail over to, in the given order, if the primary cluster
+// (see [ConsulLoaderWithHost]) is unavailable, so a regional outage does not take configuration
+// reloads down with it. A failed cluster is skipped for a cooldown interval, after which it gets probed again
+// (see [FailoverLoader] and its options, which can be passed, too).
+// All the other options (headers, query parameters, cache, etc.) apply to all clusters.
+//
+// Example:
+//
+//	xconf.NewConsulLoader(
+//		"app/config",
+//		xconf.ConsulLoaderWithHost("http://consul.eu-west.example.com:8500"),
+//		xconf.ConsulLoaderWithFailoverHosts(
+//			[]string{"http://consul.eu-central.example.com:8500"},
+//			xconf.FailoverLoaderWithCooldown(time.Minute),
+//		),
+//	)
+func ConsulLoaderWithFailoverHosts(hosts []string, opts ...FailoverLoaderOption) ConsulLoaderOption {
+	return func(loader *ConsulLoader) {
+		loader.failoverHosts = hosts
+		loader.failoverOpts = opts
+	}
+}
+
 // ConsulLoaderWithContext sets request 's context.
 // By default, a context.Background() is used.
 func ConsulLoaderWithContext(ctx context.Context) ConsulLoaderOption {
@@ -374,6 +568,16 @@ func ConsulLoaderWithCache() ConsulLoaderOption {
 	}
 }
 
+// ConsulLoaderWithObfuscatedCache enables cache, storing the configuration map
+// serialized and obfuscated, instead of a live map, and deserializing it on each load.
+// It trades CPU for reduced plaintext exposure of secrets in core dumps of long-running processes.
+// Note: it is an obfuscation, not an encryption you can rely on, as the key is kept in memory, too.
+func ConsulLoaderWithObfuscatedCache() ConsulLoaderOption {
+	return func(loader *ConsulLoader) {
+		loader.cache = &consulCache{obfuscator: newCacheObfuscator()}
+	}
+}
+
 // ConsulLoaderWithValueFormat sets the value format for a key.
 //
 // If is set to [RemoteValueJSON], the key's value will be treated as JSON
@@ -385,12 +589,14 @@ func ConsulLoaderWithCache() ConsulLoaderOption {
 // If is set to [RemoteValuePlain], the key's value will be treated as plain content
 // and configuration will contain the key and its plain value.
 //
+// If is set to [RemoteValueTOML], [RemoteValueIni], [RemoteValueProperties], [RemoteValueDotEnv],
+// the key's value will be treated accordingly, like the files of the same format,
+// and configuration will be loaded from it.
+//
 // By default, is set to [RemoteValuePlain].
 func ConsulLoaderWithValueFormat(valueFormat string) ConsulLoaderOption {
 	return func(loader *ConsulLoader) {
-		if valueFormat == RemoteValueJSON ||
-			valueFormat == RemoteValueYAML ||
-			valueFormat == RemoteValuePlain {
+		if isValidRemoteValueFormat(valueFormat) {
 			loader.valueFormat = valueFormat
 		}
 	}
@@ -398,8 +604,9 @@ func ConsulLoaderWithValueFormat(valueFormat string) ConsulLoaderOption {
 
 // consulCache holds caching info.
 type consulCache struct {
-	configMap  map[string]any   // cached config map.
+	entry      cacheEntry       // cached config map.
 	versionIDs map[string]int64 // map of key and its version ID.
+	obfuscator *cacheObfuscator // optional cached config map obfuscator.
 	mu         sync.RWMutex     // concurrency semaphore
 }
 
@@ -409,7 +616,7 @@ func (cache *consulCache) save(configMap map[string]any, versionIDs map[string]i
 		return
 	}
 	cache.mu.Lock()
-	cache.configMap = DeepCopyConfigMap(configMap)
+	cache.entry = newCacheEntry(configMap, cache.obfuscator)
 	cache.versionIDs = versionIDs
 	cache.mu.Unlock()
 }
@@ -435,9 +642,5 @@ func (cache *consulCache) load(kvPairs []consulKVPair) map[string]any {
 		}
 	}
 
-	// return a copy not to modify this state from outside (for example from a decorator,
-	// which usually modifies directly the original returned configuration map reference
-	// - for performance reasons, so we ensure from this stateful loader that we return a
-	// new configuration map each time)
-	return DeepCopyConfigMap(cache.configMap)
+	return cache.entry.load(cache.obfuscator)
 }
@@ -7,16 +7,24 @@ package xconf_test
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/actforgood/xconf"
+	"github.com/pelletier/go-toml/v2"
 	"gopkg.in/yaml.v3"
 )
 
@@ -129,6 +137,10 @@ func TestConsulLoader(t *testing.T) {
 	t.Run("success - yaml prefix key", testConsulLoaderByFormatAndPrefix(xconf.RemoteValueYAML, true))
 	t.Run("success - plain single key", testConsulLoaderByFormatAndPrefix(xconf.RemoteValuePlain, false))
 	t.Run("success - plain prefix key", testConsulLoaderByFormatAndPrefix(xconf.RemoteValuePlain, true))
+	t.Run("success - toml single key", testConsulLoaderByFileFormat(xconf.RemoteValueTOML, "foo = \"bar\"\nyear = 2022\n"))
+	t.Run("success - ini single key", testConsulLoaderByFileFormat(xconf.RemoteValueIni, "foo = bar\nyear = 2022\n"))
+	t.Run("success - properties single key", testConsulLoaderByFileFormat(xconf.RemoteValueProperties, "foo = bar\nyear = 2022\n"))
+	t.Run("success - dotenv single key", testConsulLoaderByFileFormat(xconf.RemoteValueDotEnv, "foo=bar\nyear=2022\n"))
 	t.Run("error - key is not found", testConsulLoaderReturnsErrWhenKeyIsNotFound)
 	t.Run("error - http call fails", testConsulLoaderReturnsErrFromHTTPCall)
 	t.Run("error - cannot build request", testConsulLoaderReturnsErrFromBuildRequest)
@@ -136,10 +148,17 @@ func TestConsulLoader(t *testing.T) {
 	t.Run("error - value base64 decoding fails", testConsulLoaderReturnsErrFromValueBase64Decoding)
 	t.Run("error - json value deserialization fails", testConsulLoaderReturnsErrFromJSONValueDeserialization)
 	t.Run("error - yaml value deserialization fails", testConsulLoaderReturnsErrFromYAMLValueDeserialization)
+	t.Run("error - toml value deserialization fails", testConsulLoaderReturnsErrFromTOMLValueDeserialization)
 	t.Run("success - query and headers set on request", testConsulLoaderRequestQueryAndHeaders)
 	t.Run("success - default consul url taken from env", testConsulLoaderWithBaseURLTakenFromEnv)
-	t.Run("success - caching works", testConsulLoaderWithCache)
+	t.Run("success - caching works", testConsulLoaderWithCache(xconf.ConsulLoaderWithCache()))
+	t.Run("success - obfuscated caching works", testConsulLoaderWithCache(xconf.ConsulLoaderWithObfuscatedCache()))
 	t.Run("success - safe-mutable config map", testConsulLoaderReturnsSafeMutableConfigMap)
+	t.Run("success - fails over to other cluster", testConsulLoaderWithFailoverHosts)
+	t.Run("success - tls config", testConsulLoaderWithTLS)
+	t.Run("success - mtls files", testConsulLoaderWithTLSFiles)
+	t.Run("success - mtls files taken from env", testConsulLoaderWithTLSFilesTakenFromEnv)
+	t.Run("error - invalid tls files", testConsulLoaderReturnsErrFromTLSFiles)
 }
 
 func testConsulLoaderByFormatAndPrefix(format string, withPrefix bool) func(t *testing.T) {
@@ -169,6 +188,35 @@ func testConsulLoaderByFormatAndPrefix(format string, withPrefix bool) func(t *t
 	}
 }
 
+func testConsulLoaderByFileFormat(format, value string) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		key := "consul_" + format + "_key"
+		encodedValue := base64.StdEncoding.EncodeToString([]byte(value))
+		content := `[{"Key": "` + key + `", "Value": "` + encodedValue + `", "ModifyIndex": 20}]`
+		svr := startConsulKVMockServer(t, key, content, false)
+		defer svr.Close()
+		subject := xconf.NewConsulLoader(
+			key,
+			xconf.ConsulLoaderWithHost(svr.URL),
+			xconf.ConsulLoaderWithValueFormat(format),
+		)
+		expectedYear := any("2022")
+		if format == xconf.RemoteValueTOML {
+			expectedYear = int64(2022)
+		}
+
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, map[string]any{"foo": "bar", "year": expectedYear}, config)
+	}
+}
+
 func testConsulLoaderReturnsErrWhenKeyIsNotFound(t *testing.T) {
 	t.Parallel()
 
@@ -352,6 +400,29 @@ func testConsulLoaderReturnsErrFromYAMLValueDeserialization(t *testing.T) {
 	assertTrue(t, errors.As(err, &yamlErr))
 }
 
+func testConsulLoaderReturnsErrFromTOMLValueDeserialization(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	key := "consul_toml_key"
+	content := `[{"Key": "` + key + `", "Value": "` + base64.StdEncoding.EncodeToString([]byte("invalid toml")) + `"}]`
+	svr := startConsulKVMockServer(t, key, content, false)
+	defer svr.Close()
+	subject := xconf.NewConsulLoader(
+		key,
+		xconf.ConsulLoaderWithHost(svr.URL),
+		xconf.ConsulLoaderWithValueFormat(xconf.RemoteValueTOML),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	var tomlErr *toml.DecodeError
+	assertTrue(t, errors.As(err, &tomlErr))
+}
+
 func testConsulLoaderRequestQueryAndHeaders(t *testing.T) {
 	t.Parallel()
 
@@ -413,97 +484,99 @@ func testConsulLoaderWithBaseURLTakenFromEnv(t *testing.T) {
 	assertEqual(t, getConsulExpectedConfigMapByFormatAndPrefix(format, withPrefix), config)
 }
 
-func testConsulLoaderWithCache(t *testing.T) {
-	t.Parallel()
+func testConsulLoaderWithCache(cacheOpt xconf.ConsulLoaderOption) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
 
-	// arrange
-	format := xconf.RemoteValueJSON
-	withPrefix := true
+		// arrange
+		format := xconf.RemoteValueJSON
+		withPrefix := true
+
+		// apply this patch with a broken value, there is no other way to test that cache works,
+		// but to see that no error is returned on this broken content call.
+		brokenContent := strings.Replace(
+			consulResponseContent[format][withPrefix],
+			"ewogICJjb25zdWxfanNvbl9hYmMiOiJ4eXoiCn0=",
+			"broken",
+			1,
+		)
+		freshContent := strings.Replace(
+			consulResponseContent[format][withPrefix],
+			`"ModifyIndex": 68`,
+			`"ModifyIndex": 100`, // change the modify index
+			1,
+		)
+		freshContent = strings.Replace(
+			freshContent,
+			`ewogICJjb25zdWxfanNvbl9hYmMiOiJ4eXoiCn0=`,
+			`ewogICJjb25zdWxfanNvbl9hYmMiOiJBQkMiCn0=`, // change the content to {"consul_json_abc":"ABC"}
+			1,
+		)
+		contentByCall := []string{
+			// 1st call response
+			consulResponseContent[format][withPrefix],
+			// 2nd call response
+			brokenContent,
+			// 3rd call response
+			brokenContent,
+			// 4th call response
+			freshContent,
+		}
 
-	// apply this patch with a broken value, there is no other way to test that cache works,
-	// but to see that no error is returned on this broken content call.
-	brokenContent := strings.Replace(
-		consulResponseContent[format][withPrefix],
-		"ewogICJjb25zdWxfanNvbl9hYmMiOiJ4eXoiCn0=",
-		"broken",
-		1,
-	)
-	freshContent := strings.Replace(
-		consulResponseContent[format][withPrefix],
-		`"ModifyIndex": 68`,
-		`"ModifyIndex": 100`, // change the modify index
-		1,
-	)
-	freshContent = strings.Replace(
-		freshContent,
-		`ewogICJjb25zdWxfanNvbl9hYmMiOiJ4eXoiCn0=`,
-		`ewogICJjb25zdWxfanNvbl9hYmMiOiJBQkMiCn0=`, // change the content to {"consul_json_abc":"ABC"}
-		1,
-	)
-	contentByCall := []string{
-		// 1st call response
-		consulResponseContent[format][withPrefix],
-		// 2nd call response
-		brokenContent,
-		// 3rd call response
-		brokenContent,
-		// 4th call response
-		freshContent,
-	}
+		serverCallsCnt := 0
+		svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			if serverCallsCnt < len(contentByCall) {
+				_, _ = fmt.Fprintln(w, contentByCall[serverCallsCnt])
+			}
+			serverCallsCnt++
+		}))
+		defer svr.Close()
+		subject := xconf.NewConsulLoader(
+			"consul_json_key",
+			xconf.ConsulLoaderWithHost(svr.URL),
+			xconf.ConsulLoaderWithPrefix(),
+			cacheOpt,
+			xconf.ConsulLoaderWithValueFormat(format),
+		)
+		expectedConfigMap := getConsulExpectedConfigMapByFormatAndPrefix(format, withPrefix)
 
-	serverCallsCnt := 0
-	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		if serverCallsCnt < len(contentByCall) {
-			_, _ = fmt.Fprintln(w, contentByCall[serverCallsCnt])
-		}
-		serverCallsCnt++
-	}))
-	defer svr.Close()
-	subject := xconf.NewConsulLoader(
-		"consul_json_key",
-		xconf.ConsulLoaderWithHost(svr.URL),
-		xconf.ConsulLoaderWithPrefix(),
-		xconf.ConsulLoaderWithCache(),
-		xconf.ConsulLoaderWithValueFormat(format),
-	)
-	expectedConfigMap := getConsulExpectedConfigMapByFormatAndPrefix(format, withPrefix)
+		// act
+		config, err := subject.Load()
 
-	// act
-	config, err := subject.Load()
+		// assert
+		assertNil(t, err)
+		assertEqual(t, expectedConfigMap, config)
+		assertEqual(t, 1, serverCallsCnt)
 
-	// assert
-	assertNil(t, err)
-	assertEqual(t, expectedConfigMap, config)
-	assertEqual(t, 1, serverCallsCnt)
+		for i := 0; i < 2; i++ {
+			// act - now config should be taken from cache.
+			config, err = subject.Load()
+
+			// assert
+			assertNil(t, err) // no error is returned, server returns broken content, cache works!
+			assertEqual(t, expectedConfigMap, config)
+		}
 
-	for i := 0; i < 2; i++ {
-		// act - now config should be taken from cache.
+		// act - now config should be refreshed.
 		config, err = subject.Load()
 
 		// assert
-		assertNil(t, err) // no error is returned, server returns broken content, cache works!
-		assertEqual(t, expectedConfigMap, config)
+		assertNil(t, err)
+		assertEqual(
+			t,
+			map[string]any{
+				"consul_json_foo":           "bar",
+				"consul_json_year":          float64(2022),
+				"consul_json_temperature":   37.5,
+				"consul_json_shopping_list": []any{"bread", "milk", "eggs"},
+				"consul_json_abc":           "ABC", // this was updated
+			},
+			config,
+		)
+		assertEqual(t, 4, serverCallsCnt)
 	}
-
-	// act - now config should be refreshed.
-	config, err = subject.Load()
-
-	// assert
-	assertNil(t, err)
-	assertEqual(
-		t,
-		map[string]any{
-			"consul_json_foo":           "bar",
-			"consul_json_year":          float64(2022),
-			"consul_json_temperature":   37.5,
-			"consul_json_shopping_list": []any{"bread", "milk", "eggs"},
-			"consul_json_abc":           "ABC", // this was updated
-		},
-		config,
-	)
-	assertEqual(t, 4, serverCallsCnt)
 }
 
 func testConsulLoaderReturnsSafeMutableConfigMap(t *testing.T) {
@@ -557,6 +630,210 @@ func testConsulLoaderReturnsSafeMutableConfigMap(t *testing.T) {
 	)
 }
 
+func testConsulLoaderWithFailoverHosts(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		format     = xconf.RemoteValueJSON
+		key        = consulKeys[format]
+		content    = consulResponseContent[format][false]
+		primarySvr = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		failoverSvr = startConsulKVMockServer(t, key, content, false)
+		switches    [][2]int
+	)
+	defer primarySvr.Close()
+	defer failoverSvr.Close()
+	subject := xconf.NewConsulLoader(
+		key,
+		xconf.ConsulLoaderWithHost(primarySvr.URL),
+		xconf.ConsulLoaderWithValueFormat(format),
+		xconf.ConsulLoaderWithFailoverHosts(
+			[]string{"http://127.0.0.1:12345", failoverSvr.URL},
+			xconf.FailoverLoaderWithSwitchHandler(func(fromIdx, toIdx int, _ error) {
+				switches = append(switches, [2]int{fromIdx, toIdx})
+			}),
+		),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, getConsulExpectedConfigMapByFormatAndPrefix(format, false), config)
+	assertEqual(t, [][2]int{{0, 2}}, switches)
+}
+
+func testConsulLoaderWithTLS(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	format := xconf.RemoteValueJSON
+	key := consulKeys[format]
+	svr := startConsulKVTLSMockServer(t, key, consulResponseContent[format][false], false)
+	defer svr.Close()
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(svr.Certificate())
+	subject := xconf.NewConsulLoader(
+		key,
+		xconf.ConsulLoaderWithHost(svr.URL),
+		xconf.ConsulLoaderWithValueFormat(format),
+		xconf.ConsulLoaderWithTLS(&tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, getConsulExpectedConfigMapByFormatAndPrefix(format, false), config)
+
+	// arrange
+	subject = xconf.NewConsulLoader(
+		key,
+		xconf.ConsulLoaderWithHost(svr.URL),
+		xconf.ConsulLoaderWithValueFormat(format),
+		xconf.ConsulLoaderWithHTTPClient(&http.Client{Timeout: 5 * time.Second}),
+		xconf.ConsulLoaderWithTLS(&tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}),
+	)
+
+	// act
+	config, err = subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, getConsulExpectedConfigMapByFormatAndPrefix(format, false), config)
+}
+
+func testConsulLoaderWithTLSFiles(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	format := xconf.RemoteValueJSON
+	key := consulKeys[format]
+	svr := startConsulKVTLSMockServer(t, key, consulResponseContent[format][false], true)
+	defer svr.Close()
+	certFile, keyFile := writeTLSServerCertFiles(t, svr)
+	subject := xconf.NewConsulLoader(
+		key,
+		xconf.ConsulLoaderWithHost(svr.URL),
+		xconf.ConsulLoaderWithValueFormat(format),
+		xconf.ConsulLoaderWithCACertFile(certFile),
+		xconf.ConsulLoaderWithClientCertFiles(certFile, keyFile),
+		xconf.ConsulLoaderWithTLSServerName("example.com"),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, getConsulExpectedConfigMapByFormatAndPrefix(format, false), config)
+}
+
+func testConsulLoaderWithTLSFilesTakenFromEnv(t *testing.T) {
+	// arrange
+	format := xconf.RemoteValueJSON
+	key := consulKeys[format]
+	svr := startConsulKVTLSMockServer(t, key, consulResponseContent[format][false], true)
+	defer svr.Close()
+	certFile, keyFile := writeTLSServerCertFiles(t, svr)
+	t.Setenv("CONSUL_HTTP_ADDR", strings.TrimPrefix(svr.URL, "https://"))
+	t.Setenv("CONSUL_HTTP_SSL", "true")
+	t.Setenv("CONSUL_CACERT", certFile)
+	t.Setenv("CONSUL_CLIENT_CERT", certFile)
+	t.Setenv("CONSUL_CLIENT_KEY", keyFile)
+	t.Setenv("CONSUL_TLS_SERVER_NAME", "example.com")
+	subject := xconf.NewConsulLoader(key, xconf.ConsulLoaderWithValueFormat(format))
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, getConsulExpectedConfigMapByFormatAndPrefix(format, false), config)
+}
+
+func testConsulLoaderReturnsErrFromTLSFiles(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		invalidCAFile = filepath.Join(t.TempDir(), "ca.pem")
+		subjects      = []xconf.ConsulLoader{
+			xconf.NewConsulLoader("some-key", xconf.ConsulLoaderWithCACertFile("testdata/not_found.pem")),
+			xconf.NewConsulLoader("some-key", xconf.ConsulLoaderWithCACertFile(invalidCAFile)),
+			xconf.NewConsulLoader("some-key", xconf.ConsulLoaderWithClientCertFiles(invalidCAFile, invalidCAFile)),
+			xconf.NewConsulLoader(
+				"some-key",
+				xconf.ConsulLoaderWithHTTPClient(&http.Client{Transport: roundTripperFunc(http.DefaultTransport.RoundTrip)}),
+				xconf.ConsulLoaderWithTLS(&tls.Config{MinVersion: tls.VersionTLS12}),
+			),
+		}
+	)
+	requireNil(t, os.WriteFile(invalidCAFile, []byte("not a certificate"), 0o600))
+
+	for _, subject := range subjects {
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertNil(t, config)
+		assertNotNil(t, err)
+	}
+}
+
+// startConsulKVTLSMockServer starts a Consul key-value https mock server,
+// optionally requiring a client certificate.
+func startConsulKVTLSMockServer(t *testing.T, key, content string, requireClientCert bool) *httptest.Server {
+	t.Helper()
+
+	svr := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertEqual(t, "/v1/kv/"+key, r.URL.String())
+		if requireClientCert && !assertEqual(t, 1, len(r.TLS.PeerCertificates)) {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if _, err := fmt.Fprintln(w, content); err != nil {
+			t.Error(err)
+		}
+	}))
+	if requireClientCert {
+		svr.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert, MinVersion: tls.VersionTLS12}
+	}
+	svr.StartTLS()
+
+	return svr
+}
+
+// writeTLSServerCertFiles writes https mock server's certificate and key as PEM files,
+// and returns their paths.
+func writeTLSServerCertFiles(t *testing.T, svr *httptest.Server) (string, string) {
+	t.Helper()
+
+	var (
+		cert     = svr.TLS.Certificates[0]
+		dir      = t.TempDir()
+		certFile = filepath.Join(dir, "cert.pem")
+		keyFile  = filepath.Join(dir, "key.pem")
+	)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	requireNil(t, err)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	requireNil(t, os.WriteFile(certFile, certPEM, 0o600))
+	requireNil(t, os.WriteFile(keyFile, keyPEM, 0o600))
+
+	return certFile, keyFile
+}
+
 // startEtcdKVMockServer starts a Consul key-value http mock server.
 func startConsulKVMockServer(t *testing.T, key, content string, withPrefix bool) *httptest.Server {
 	t.Helper()
@@ -0,0 +1,203 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/actforgood/xerr"
+)
+
+var (
+	// ErrCSVColumnNotFound is an error returned by [CSVReaderLoader] if a named key / value column
+	// is not found in the header row.
+	ErrCSVColumnNotFound = errors.New("csv column not found")
+	// ErrCSVRowTooShort is an error returned by [CSVReaderLoader] if a row does not have the key / value column.
+	ErrCSVRowTooShort = errors.New("csv row does not have the key / value column")
+	// ErrCSVInvalidColumn is an error returned by [CSVReaderLoader] if a key / value column index
+	// set with [CSVLoaderWithColumns] is negative.
+	ErrCSVInvalidColumn = errors.New("invalid csv column index")
+)
+
+// utf8BOM is the UTF-8 byte order mark, spreadsheet applications may prepend to CSV exports.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// CSVLoaderOption defines optional function for configuring a CSV loader.
+type CSVLoaderOption func(*csvLoaderOptions)
+
+// csvLoaderOptions holds the configuration of a CSV loader.
+type csvLoaderOptions struct {
+	keyColumn       int    // index of the key column.
+	valueColumn     int    // index of the value column.
+	keyColumnName   string // name of the key column, looked up in the header row.
+	valueColumnName string // name of the value column, looked up in the header row.
+	hasHeader       bool   // whether first row is a header.
+	comma           rune   // field delimiter.
+	comment         rune   // comment character.
+}
+
+// CSVLoaderWithColumns sets the (0 based) indexes of the key and value columns.
+// Negative indexes make the loader return [ErrCSVInvalidColumn].
+// By default, key is the first column and value is the second one.
+func CSVLoaderWithColumns(keyColumn, valueColumn int) CSVLoaderOption {
+	return func(opts *csvLoaderOptions) {
+		opts.keyColumn = keyColumn
+		opts.valueColumn = valueColumn
+	}
+}
+
+// CSVLoaderWithHeader marks the first row as a header row, which is skipped.
+// By default, there is no header row.
+func CSVLoaderWithHeader() CSVLoaderOption {
+	return func(opts *csvLoaderOptions) {
+		opts.hasHeader = true
+	}
+}
+
+// CSVLoaderWithNamedColumns sets the names of the key and value columns,
+// looked up in the header row (implies [CSVLoaderWithHeader]).
+func CSVLoaderWithNamedColumns(keyColumn, valueColumn string) CSVLoaderOption {
+	return func(opts *csvLoaderOptions) {
+		opts.keyColumnName = keyColumn
+		opts.valueColumnName = valueColumn
+		opts.hasHeader = true
+	}
+}
+
+// CSVLoaderWithDelimiter sets the field delimiter (like ';' or '\t' for spreadsheets exports).
+// By default, ',' is used.
+func CSVLoaderWithDelimiter(comma rune) CSVLoaderOption {
+	return func(opts *csvLoaderOptions) {
+		opts.comma = comma
+	}
+}
+
+// CSVLoaderWithComment sets the comment character; lines starting with it are ignored.
+// By default, there is no comment character.
+func CSVLoaderWithComment(comment rune) CSVLoaderOption {
+	return func(opts *csvLoaderOptions) {
+		opts.comment = comment
+	}
+}
+
+// CSVFileLoader loads configuration from a CSV (tabular) file.
+// The location of the file is given as parameter. See [CSVReaderLoader].
+func CSVFileLoader(filePath string, opts ...CSVLoaderOption) Loader {
+	return LoaderFunc(func() (map[string]any, error) {
+		f, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		return CSVReaderLoader(f, opts...).Load()
+	})
+}
+
+// CSVReaderLoader loads configuration from CSV (tabular) content of an [io.Reader],
+// one key-value pair per row, useful for large flat lookup tables (feature flags, tenant settings)
+// exported from spreadsheets or databases.
+// Key and value columns can be selected (see [CSVLoaderWithColumns], [CSVLoaderWithNamedColumns]),
+// other columns being ignored. Values are (not trimmed) strings.
+// Rows with an empty key are skipped. If a key appears multiple times, the last row wins.
+// A leading UTF-8 BOM (as written by some spreadsheet applications) is ignored.
+//
+// Example:
+//
+//	// tenant,plan,max_users
+//	// acme,gold,100
+//	loader := xconf.CSVFileLoader("tenants.csv", xconf.CSVLoaderWithNamedColumns("tenant", "max_users"))
+func CSVReaderLoader(reader io.Reader, opts ...CSVLoaderOption) Loader {
+	loaderOpts := csvLoaderOptions{
+		keyColumn:   0,
+		valueColumn: 1,
+		comma:       ',',
+	}
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&loaderOpts)
+	}
+
+	return LoaderFunc(func() (map[string]any, error) {
+		if loaderOpts.keyColumnName == "" && (loaderOpts.keyColumn < 0 || loaderOpts.valueColumn < 0) {
+			return nil, xerr.Wrapf(
+				ErrCSVInvalidColumn,
+				"key column %d, value column %d",
+				loaderOpts.keyColumn, loaderOpts.valueColumn,
+			)
+		}
+		if seekReader, ok := reader.(io.Seeker); ok {
+			_, _ = seekReader.Seek(0, io.SeekStart) // move to the beginning in case of a re-load needed.
+		}
+		bufReader := bufio.NewReader(reader)
+		if bom, _ := bufReader.Peek(len(utf8BOM)); bytes.Equal(bom, utf8BOM) {
+			_, _ = bufReader.Discard(len(utf8BOM))
+		}
+		csvReader := csv.NewReader(bufReader)
+		csvReader.Comma = loaderOpts.comma
+		csvReader.Comment = loaderOpts.comment
+		csvReader.FieldsPerRecord = -1
+		csvReader.ReuseRecord = true
+
+		keyColumn, valueColumn := loaderOpts.keyColumn, loaderOpts.valueColumn
+		if loaderOpts.hasHeader {
+			header, err := csvReader.Read()
+			if errors.Is(err, io.EOF) {
+				return map[string]any{}, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			if loaderOpts.keyColumnName != "" {
+				if keyColumn = columnIndex(header, loaderOpts.keyColumnName); keyColumn < 0 {
+					return nil, xerr.Wrapf(ErrCSVColumnNotFound, "%q", loaderOpts.keyColumnName)
+				}
+				if valueColumn = columnIndex(header, loaderOpts.valueColumnName); valueColumn < 0 {
+					return nil, xerr.Wrapf(ErrCSVColumnNotFound, "%q", loaderOpts.valueColumnName)
+				}
+			}
+		}
+		lastColumn := max(keyColumn, valueColumn)
+
+		configMap := make(map[string]any)
+		for {
+			record, err := csvReader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if len(record) <= lastColumn {
+				line, _ := csvReader.FieldPos(0)
+
+				return nil, xerr.Wrapf(ErrCSVRowTooShort, "line %d", line)
+			}
+			if key := strings.TrimSpace(record[keyColumn]); key != "" {
+				configMap[key] = record[valueColumn]
+			}
+		}
+
+		return configMap, nil
+	})
+}
+
+// columnIndex returns the index of the column with given name (case-insensitive), or -1 if not found.
+func columnIndex(header []string, name string) int {
+	for idx, column := range header {
+		if strings.EqualFold(strings.TrimSpace(column), name) {
+			return idx
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,206 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestCSVFileLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - named columns", testCSVFileLoaderWithNamedColumns)
+	t.Run("error - file not found", testCSVFileLoaderReturnsErrFileNotFound)
+}
+
+func testCSVFileLoaderWithNamedColumns(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.CSVFileLoader("testdata/config.csv", xconf.CSVLoaderWithNamedColumns("Flag", "enabled"))
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"new_checkout": "true", "dark_mode": "false"}, config)
+}
+
+func testCSVFileLoaderReturnsErrFileNotFound(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.CSVFileLoader("testdata/this-file-does-not-exist.csv")
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, fs.ErrNotExist))
+	assertNil(t, config)
+}
+
+func TestCSVReaderLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - default columns, no header", testCSVReaderLoaderWithDefaults)
+	t.Run("success - indexed columns, header, delimiter, comment", testCSVReaderLoaderWithOptions)
+	t.Run("success - UTF-8 BOM is ignored", testCSVReaderLoaderIgnoresBOM)
+	t.Run("error - named column not found", testCSVReaderLoaderReturnsErrColumnNotFound)
+	t.Run("error - negative column index", testCSVReaderLoaderReturnsErrInvalidColumn)
+	t.Run("error - row too short", testCSVReaderLoaderReturnsErrRowTooShort)
+	t.Run("error - malformed content", testCSVReaderLoaderReturnsErrMalformed)
+}
+
+func testCSVReaderLoaderWithDefaults(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	content := "acme,100\n\nglobex,\"25\"\n,ignored\nacme,200,extra column\n"
+	subject := xconf.CSVReaderLoader(strings.NewReader(content))
+
+	// act
+	config, err := subject.Load()
+	config2, err2 := subject.Load() // re-load works, reader being seeked to its beginning.
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"acme": "200", "globex": "25"}, config)
+	assertNil(t, err2)
+	assertEqual(t, config, config2)
+}
+
+func testCSVReaderLoaderWithOptions(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	content := "# exported from tenants table\nplan;tenant;max_users\ngold;acme;100\nsilver;globex;25\n"
+	subject := xconf.CSVReaderLoader(
+		strings.NewReader(content),
+		xconf.CSVLoaderWithHeader(),
+		xconf.CSVLoaderWithColumns(1, 2),
+		xconf.CSVLoaderWithDelimiter(';'),
+		xconf.CSVLoaderWithComment('#'),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"acme": "100", "globex": "25"}, config)
+}
+
+func testCSVReaderLoaderIgnoresBOM(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tests := [...]struct {
+		name    string
+		content string
+		opts    []xconf.CSVLoaderOption
+	}{
+		{
+			name:    "named columns",
+			content: "\ufefftenant,max_users\nacme,100\n",
+			opts:    []xconf.CSVLoaderOption{xconf.CSVLoaderWithNamedColumns("tenant", "max_users")},
+		},
+		{
+			name:    "quoted header",
+			content: "\ufeff\"tenant\",\"max_users\"\nacme,100\n",
+			opts:    []xconf.CSVLoaderOption{xconf.CSVLoaderWithNamedColumns("tenant", "max_users")},
+		},
+		{
+			name:    "no header",
+			content: "\ufeffacme,100\n",
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			subject := xconf.CSVReaderLoader(strings.NewReader(test.content), test.opts...)
+
+			// act
+			config, err := subject.Load()
+
+			// assert
+			assertNil(t, err)
+			assertEqual(t, map[string]any{"acme": "100"}, config)
+		})
+	}
+}
+
+func testCSVReaderLoaderReturnsErrInvalidColumn(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.CSVReaderLoader(
+		strings.NewReader("acme,100\n"),
+		xconf.CSVLoaderWithColumns(-1, 1),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrCSVInvalidColumn))
+	assertNil(t, config)
+}
+
+func testCSVReaderLoaderReturnsErrColumnNotFound(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.CSVReaderLoader(
+		strings.NewReader("tenant,plan\nacme,gold\n"),
+		xconf.CSVLoaderWithNamedColumns("tenant", "max_users"),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrCSVColumnNotFound))
+	assertNil(t, config)
+}
+
+func testCSVReaderLoaderReturnsErrRowTooShort(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.CSVReaderLoader(strings.NewReader("acme,100\nglobex\n"))
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrCSVRowTooShort))
+	assertTrue(t, strings.Contains(err.Error(), "line 2"))
+	assertNil(t, config)
+}
+
+func testCSVReaderLoaderReturnsErrMalformed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.CSVReaderLoader(strings.NewReader("acme,\"100\nglobex,25\n"))
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNotNil(t, err)
+	assertNil(t, config)
+}
@@ -5,7 +5,12 @@
 
 package xconf
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+)
 
 // ErrAliasPairBroken is an error returned by AliasLoader when the variadic list of aliases
 // and their keys consists of odd no. of elements.
@@ -16,12 +21,12 @@ var ErrAliasPairBroken = errors.New("alias - missing key")
 // The second parameter represents a list of alias and keys they're for
 // under the form "aliasForKey1, key1, aliasForKey2, key2".
 func AliasLoader(loader Loader, aliasKeyKey ...string) Loader {
-	return LoaderFunc(func() (map[string]any, error) {
+	return decorate(loader, func(ctx context.Context, loader Loader) (map[string]any, error) {
 		if len(aliasKeyKey)%2 == 1 {
 			return nil, ErrAliasPairBroken
 		}
 
-		configMap, err := loader.Load()
+		configMap, err := LoadWithContext(ctx, loader)
 		if err != nil {
 			return configMap, err
 		}
@@ -38,3 +43,59 @@ func AliasLoader(loader Loader, aliasKeyKey ...string) Loader {
 		return configMap, nil
 	})
 }
+
+// TwoWayAliasLoader decorates another loader to keep aliases and the keys they're for in sync,
+// preventing drift during long migrations (for example, a key being renamed).
+// Unlike [AliasLoader], where the key's value always overwrites the alias, here a value
+// set / overridden for either the alias or the key (for example, through an env variable
+// using the new name) is propagated to the other one.
+// The second parameter represents a list of alias and keys they're for
+// under the form "aliasForKey1, key1, aliasForKey2, key2".
+//
+// If both the alias and the key are found, with different values, the one whose value changed
+// since previous load wins. If both changed (or at first load), the key's value wins.
+// As both names hold the same value, [DefaultConfig]'s observers get notified with both names, on change.
+func TwoWayAliasLoader(loader Loader, aliasKeyKey ...string) Loader {
+	var (
+		mu         sync.Mutex
+		lastValues = make(map[string]any, len(aliasKeyKey)/2) // last synced values, by alias.
+	)
+
+	return decorate(loader, func(ctx context.Context, loader Loader) (map[string]any, error) {
+		if len(aliasKeyKey)%2 == 1 {
+			return nil, ErrAliasPairBroken
+		}
+
+		configMap, err := LoadWithContext(ctx, loader)
+		if err != nil {
+			return configMap, err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for i := 0; i < len(aliasKeyKey); i += 2 {
+			alias := aliasKeyKey[i]
+			key := aliasKeyKey[i+1]
+			aliasValue, aliasFound := configMap[alias]
+			keyValue, keyFound := configMap[key]
+			switch {
+			case !aliasFound && !keyFound:
+				delete(lastValues, alias)
+
+				continue
+			case !keyFound:
+				keyValue = aliasValue
+			case aliasFound && !reflect.DeepEqual(aliasValue, keyValue):
+				lastValue, synced := lastValues[alias]
+				if synced && reflect.DeepEqual(keyValue, lastValue) { // only alias changed.
+					keyValue = aliasValue
+				}
+			}
+			configMap[key] = keyValue
+			configMap[alias] = keyValue
+			lastValues[alias] = deepCopyValue(keyValue)
+		}
+
+		return configMap, nil
+	})
+}
@@ -8,7 +8,10 @@ package xconf_test
 import (
 	"errors"
 	"fmt"
+	"sort"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/actforgood/xconf"
 )
@@ -208,3 +211,132 @@ func ExampleAliasLoader() {
 	// NEW_FOO: foo val
 	// NEW_BAZ: baz val
 }
+
+func TestTwoWayAliasLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - alias and key are kept in sync", testTwoWayAliasLoaderSync)
+	t.Run("error - invalid list (odd elements number)", testTwoWayAliasLoaderReturnsErrAliasPairBroken)
+	t.Run("success - observers are notified with both names", testTwoWayAliasLoaderNotifiesBothNames)
+}
+
+func testTwoWayAliasLoaderSync(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		configMap = map[string]any{"db_host": "old-host", "new_db_port": 3306}
+		loader    = xconf.LoaderFunc(func() (map[string]any, error) {
+			return xconf.DeepCopyConfigMap(configMap), nil
+		})
+		subject = xconf.TwoWayAliasLoader(
+			loader,
+			"new_db_host", "db_host",
+			"new_db_port", "db_port",
+			"new_db_name", "db_name", // none exists
+		)
+	)
+	tests := [...]struct {
+		name           string
+		configMap      map[string]any
+		expectedResult map[string]any
+	}{
+		{
+			name:      "first load, one of the names is set",
+			configMap: map[string]any{"db_host": "old-host", "new_db_port": 3306},
+			expectedResult: map[string]any{
+				"db_host": "old-host", "new_db_host": "old-host",
+				"db_port": 3306, "new_db_port": 3306,
+			},
+		},
+		{
+			name:      "alias changed",
+			configMap: map[string]any{"db_host": "old-host", "new_db_host": "new-host", "db_port": 3306},
+			expectedResult: map[string]any{
+				"db_host": "new-host", "new_db_host": "new-host",
+				"db_port": 3306, "new_db_port": 3306,
+			},
+		},
+		{
+			name:      "key changed",
+			configMap: map[string]any{"db_host": "newer-host", "new_db_host": "new-host", "db_port": 3307},
+			expectedResult: map[string]any{
+				"db_host": "newer-host", "new_db_host": "newer-host",
+				"db_port": 3307, "new_db_port": 3307,
+			},
+		},
+		{
+			name:      "both changed, key wins",
+			configMap: map[string]any{"db_host": "host-1", "new_db_host": "host-2"},
+			expectedResult: map[string]any{
+				"db_host": "host-1", "new_db_host": "host-1",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		configMap = test.configMap
+
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, test.expectedResult, config)
+	}
+}
+
+func testTwoWayAliasLoaderReturnsErrAliasPairBroken(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.TwoWayAliasLoader(
+		xconf.PlainLoader(map[string]any{"foo": "bar"}),
+		"alias_foo", "foo",
+		"alias_bar",
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrAliasPairBroken))
+	assertNil(t, config)
+}
+
+func testTwoWayAliasLoaderNotifiesBothNames(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		configMap atomic.Value
+		loader    = xconf.LoaderFunc(func() (map[string]any, error) {
+			return xconf.DeepCopyConfigMap(configMap.Load().(map[string]any)), nil
+		})
+		changedKeysC = make(chan []string, 1)
+	)
+	configMap.Store(map[string]any{"db_host": "old-host"})
+	config, err := xconf.NewDefaultConfig(
+		xconf.TwoWayAliasLoader(loader, "new_db_host", "db_host"),
+		xconf.DefaultConfigWithReloadInterval(50*time.Millisecond),
+	)
+	requireNil(t, err)
+	defer config.Close()
+	config.RegisterObserver(func(_ xconf.Config, changedKeys ...string) {
+		sort.Strings(changedKeys)
+		changedKeysC <- changedKeys
+	})
+	configMap.Store(map[string]any{"db_host": "old-host", "new_db_host": "new-host"})
+
+	// act
+	var changedKeys []string
+	select {
+	case changedKeys = <-changedKeysC:
+	case <-time.After(time.Second):
+	}
+
+	// assert
+	assertEqual(t, []string{"db_host", "new_db_host"}, changedKeys)
+	assertEqual(t, "new-host", config.Get("db_host"))
+	assertEqual(t, "new-host", config.Get("new_db_host"))
+}
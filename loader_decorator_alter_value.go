@@ -6,6 +6,7 @@
 package xconf
 
 import (
+	"context"
 	"strings"
 
 	"github.com/spf13/cast"
@@ -17,8 +18,8 @@ type AlterValueFunc func(value any) any
 // AlterValueLoader decorates another loader to manipulate a config's value.
 // The transformation function is applied to all passed keys.
 func AlterValueLoader(loader Loader, transformation AlterValueFunc, keys ...string) Loader {
-	return LoaderFunc(func() (map[string]any, error) {
-		configMap, err := loader.Load()
+	return decorate(loader, func(ctx context.Context, loader Loader) (map[string]any, error) {
+		configMap, err := LoadWithContext(ctx, loader)
 		if err != nil {
 			return configMap, err
 		}
@@ -33,6 +34,27 @@ func AlterValueLoader(loader Loader, transformation AlterValueFunc, keys ...stri
 	})
 }
 
+// Compose chains multiple transformation functions into a single one.
+// Transformations are applied in the given order, each one receiving
+// the previous one's output.
+//
+// Example, base64 decode and then split:
+//
+//	xconf.AlterValueLoader(
+//		loader,
+//		xconf.Compose(base64Decode, xconf.ToStringList(",")),
+//		"ENCODED_LIST",
+//	)
+func Compose(transformations ...AlterValueFunc) AlterValueFunc {
+	return func(value any) any {
+		for _, transformation := range transformations {
+			value = transformation(value)
+		}
+
+		return value
+	}
+}
+
 // ToStringList makes a slice of strings from a string value,
 // who's items are separated by given separator parameter.
 //
@@ -64,3 +86,27 @@ func ToIntList(sep string) AlterValueFunc {
 		return value
 	}
 }
+
+// ToBool makes a bool from a string value, accepting, besides
+// the standard true/false tokens, the extended spellings commonly found in
+// ops-authored files. Tokens are case-insensitive and surrounding white spaces are ignored:
+//
+//	true:  "1", "t", "true", "y", "yes", "on", "enable", "enabled"
+//	false: "0", "f", "false", "n", "no", "off", "disable", "disabled"
+//
+// If the original value is not a string, or it's not an accepted token,
+// the value remains unaltered.
+//
+// Example: "yes" => true, "Off" => false.
+func ToBool(value any) any {
+	if strValue, ok := value.(string); ok {
+		switch strings.ToLower(strings.TrimSpace(strValue)) {
+		case "1", "t", "true", "y", "yes", "on", "enable", "enabled":
+			return true
+		case "0", "f", "false", "n", "no", "off", "disable", "disabled":
+			return false
+		}
+	}
+
+	return value
+}
@@ -6,8 +6,10 @@
 package xconf_test
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/actforgood/xconf"
@@ -214,6 +216,101 @@ func TestToIntList(t *testing.T) {
 	}
 }
 
+func TestToBool(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tests := [...]struct {
+		name           string
+		inputValue     any
+		expectedResult any
+	}{
+		{name: "yes", inputValue: "yes", expectedResult: true},
+		{name: "ON, with spaces", inputValue: " ON ", expectedResult: true},
+		{name: "enabled", inputValue: "enabled", expectedResult: true},
+		{name: "y", inputValue: "Y", expectedResult: true},
+		{name: "standard true", inputValue: "true", expectedResult: true},
+		{name: "no", inputValue: "no", expectedResult: false},
+		{name: "Off", inputValue: "Off", expectedResult: false},
+		{name: "disabled", inputValue: "disabled", expectedResult: false},
+		{name: "standard 0", inputValue: "0", expectedResult: false},
+		{
+			name:           "value is not an accepted token, expect original value",
+			inputValue:     "maybe",
+			expectedResult: "maybe",
+		},
+		{
+			name:           "value is not string, expect original value",
+			inputValue:     1,
+			expectedResult: 1,
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			// act
+			result := xconf.ToBool(test.inputValue)
+
+			// assert
+			assertEqual(t, test.expectedResult, result)
+		})
+	}
+}
+
+func TestCompose(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		trimSpace = func(value any) any {
+			if strValue, ok := value.(string); ok {
+				return strings.TrimSpace(strValue)
+			}
+
+			return value
+		}
+		tests = [...]struct {
+			name            string
+			transformations []xconf.AlterValueFunc
+			inputValue      any
+			expectedResult  any
+		}{
+			{
+				name:            "transformations are applied in order",
+				transformations: []xconf.AlterValueFunc{trimSpace, xconf.ToIntList(",")},
+				inputValue:      " 10,100,1000 ",
+				expectedResult:  []int{10, 100, 1000},
+			},
+			{
+				name:            "single transformation",
+				transformations: []xconf.AlterValueFunc{xconf.ToStringList(",")},
+				inputValue:      "bread,eggs",
+				expectedResult:  []string{"bread", "eggs"},
+			},
+			{
+				name:            "no transformation, expect original value",
+				transformations: nil,
+				inputValue:      "bread,eggs",
+				expectedResult:  "bread,eggs",
+			},
+		}
+	)
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			subject := xconf.Compose(test.transformations...)
+
+			// act
+			result := subject(test.inputValue)
+
+			// assert
+			assertEqual(t, test.expectedResult, result)
+		})
+	}
+}
+
 func BenchmarkAlterValueLoader(b *testing.B) {
 	origLoader := xconf.PlainLoader(map[string]any{
 		"foo":           "foo val",
@@ -264,3 +361,32 @@ func ExampleAlterValueLoader() {
 	// shopping_list: [bread eggs milk]
 	// weekend_days: [friday saturday sunday]
 }
+
+func ExampleCompose() {
+	base64Decode := func(value any) any {
+		if strValue, ok := value.(string); ok {
+			if decoded, err := base64.StdEncoding.DecodeString(strValue); err == nil {
+				return string(decoded)
+			}
+		}
+
+		return value
+	}
+	origLoader := xconf.PlainLoader(map[string]any{
+		"shopping_list": "YnJlYWQsZWdncyxtaWxr", // base64 of "bread,eggs,milk"
+	})
+	loader := xconf.AlterValueLoader(
+		origLoader,
+		xconf.Compose(base64Decode, xconf.ToStringList(",")),
+		"shopping_list",
+	)
+
+	configMap, err := loader.Load()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(configMap["shopping_list"])
+
+	// Output:
+	// [bread eggs milk]
+}
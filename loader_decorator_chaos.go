@@ -0,0 +1,255 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrChaosInjected is the transient error returned by [ChaosLoader] when it injects a failure.
+var ErrChaosInjected = errors.New("chaos injected error")
+
+// ChaosLoader decorates another loader to inject configurable latency,
+// transient errors, and partial data into Load results, so that you can verify your
+// reload / observer / fallback handling under realistic failure conditions, before production.
+// See also [Soak].
+//
+// It is meant for tests / staging environments only.
+//
+// Example:
+//
+//	loader := xconf.NewChaosLoader(
+//		etcdLoader,
+//		xconf.ChaosLoaderWithLatency(10*time.Millisecond, 2*time.Second),
+//		xconf.ChaosLoaderWithErrorRate(0.2),
+//		xconf.ChaosLoaderWithPartialDataRate(0.1),
+//	)
+type ChaosLoader struct {
+	loader          Loader        // the decorated loader.
+	minLatency      time.Duration // minimum injected latency.
+	maxLatency      time.Duration // maximum injected latency.
+	errorRate       float64       // probability of injecting an error.
+	partialDataRate float64       // probability of dropping keys.
+	rnd             *chaosRand    // random numbers generator.
+	stats           *chaosStats   // injections' counters.
+}
+
+// NewChaosLoader instantiates a new ChaosLoader object that loads
+// the configuration from the original loader, injecting failures.
+// Without options, it behaves like the original loader.
+func NewChaosLoader(loader Loader, opts ...ChaosLoaderOption) ChaosLoader {
+	decorator := ChaosLoader{
+		loader: loader,
+		rnd:    &chaosRand{rnd: rand.New(rand.NewSource(time.Now().UnixNano()))}, //nolint:gosec // not security related.
+		stats:  new(chaosStats),
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&decorator)
+	}
+
+	return decorator
+}
+
+// Load returns decorated loader's key-value configuration map,
+// after an eventual injected latency. With the configured probabilities,
+// [ErrChaosInjected] is returned instead, or some keys are dropped from the configuration map.
+func (decorator ChaosLoader) Load() (map[string]any, error) {
+	return decorator.LoadContext(context.Background())
+}
+
+// LoadContext is like Load, passing given context to the decorated loader.
+// It implements [ContextLoader].
+func (decorator ChaosLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	atomic.AddUint64(&decorator.stats.loads, 1)
+
+	if decorator.maxLatency > 0 {
+		latency := decorator.minLatency
+		if delta := decorator.maxLatency - decorator.minLatency; delta > 0 {
+			latency += time.Duration(decorator.rnd.Int63n(int64(delta) + 1))
+		}
+		atomic.AddInt64(&decorator.stats.latency, int64(latency))
+		timer := time.NewTimer(latency)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if decorator.rnd.Float64() < decorator.errorRate {
+		atomic.AddUint64(&decorator.stats.errors, 1)
+
+		return nil, ErrChaosInjected
+	}
+
+	configMap, err := LoadWithContext(ctx, decorator.loader)
+	if err != nil || len(configMap) == 0 {
+		return configMap, err
+	}
+
+	if decorator.rnd.Float64() < decorator.partialDataRate {
+		atomic.AddUint64(&decorator.stats.partialLoads, 1)
+		decorator.dropKeys(configMap)
+	}
+
+	return configMap, nil
+}
+
+// dropKeys removes randomly (at least one of) the keys from the configuration map.
+func (decorator ChaosLoader) dropKeys(configMap map[string]any) {
+	keys := make([]string, 0, len(configMap))
+	for key := range configMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys) // for reproducible results with a seed.
+
+	dropped := false
+	for _, key := range keys {
+		if decorator.rnd.Float64() < 0.5 {
+			delete(configMap, key)
+			dropped = true
+		}
+	}
+	if !dropped {
+		delete(configMap, keys[decorator.rnd.Intn(len(keys))])
+	}
+}
+
+// Stats returns the injections' counters.
+func (decorator ChaosLoader) Stats() ChaosStats {
+	return ChaosStats{
+		Loads:          atomic.LoadUint64(&decorator.stats.loads),
+		InjectedErrors: atomic.LoadUint64(&decorator.stats.errors),
+		PartialLoads:   atomic.LoadUint64(&decorator.stats.partialLoads),
+		TotalLatency:   time.Duration(atomic.LoadInt64(&decorator.stats.latency)),
+	}
+}
+
+// Close closes the decorated loader, if it implements [io.Closer].
+func (decorator ChaosLoader) Close() error {
+	return CloseLoaders(decorator.loader)
+}
+
+// Unwrap returns the decorated loader.
+func (decorator ChaosLoader) Unwrap() []Loader {
+	return []Loader{decorator.loader}
+}
+
+// mapLoaders returns a copy of the decorator, decorating the loader returned by fn.
+func (decorator ChaosLoader) mapLoaders(fn func(Loader) Loader) Loader {
+	decorator.loader = fn(decorator.loader)
+
+	return decorator
+}
+
+// ChaosStats holds the counters of a [ChaosLoader]'s injections.
+type ChaosStats struct {
+	// Loads is the number of Load calls.
+	Loads uint64
+	// InjectedErrors is the number of Load calls for which [ErrChaosInjected] was returned.
+	InjectedErrors uint64
+	// PartialLoads is the number of Load calls for which keys were dropped.
+	PartialLoads uint64
+	// TotalLatency is the sum of injected latencies.
+	TotalLatency time.Duration
+}
+
+// chaosStats holds the injections' counters, updated atomically.
+type chaosStats struct {
+	loads        uint64
+	errors       uint64
+	partialLoads uint64
+	latency      int64
+}
+
+// chaosRand is a concurrent safe random numbers generator.
+type chaosRand struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// Float64 returns a pseudo-random number in [0.0,1.0).
+func (r *chaosRand) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rnd.Float64()
+}
+
+// Int63n returns a non-negative pseudo-random number in [0,n).
+func (r *chaosRand) Int63n(n int64) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rnd.Int63n(n)
+}
+
+// Intn returns a non-negative pseudo-random number in [0,n).
+func (r *chaosRand) Intn(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rnd.Intn(n)
+}
+
+// ChaosLoaderOption defines optional function for configuring
+// a ChaosLoader.
+type ChaosLoaderOption func(*ChaosLoader)
+
+// ChaosLoaderWithLatency sets the range of latency injected before each load.
+// A random latency in [minLatency, maxLatency] is used.
+//
+// By default, no latency is injected.
+func ChaosLoaderWithLatency(minLatency, maxLatency time.Duration) ChaosLoaderOption {
+	return func(decorator *ChaosLoader) {
+		if maxLatency < minLatency {
+			maxLatency = minLatency
+		}
+		decorator.minLatency = minLatency
+		decorator.maxLatency = maxLatency
+	}
+}
+
+// ChaosLoaderWithErrorRate sets the probability (between 0 and 1) of
+// returning [ErrChaosInjected], without calling the decorated loader.
+//
+// By default, no error is injected.
+func ChaosLoaderWithErrorRate(errorRate float64) ChaosLoaderOption {
+	return func(decorator *ChaosLoader) {
+		decorator.errorRate = errorRate
+	}
+}
+
+// ChaosLoaderWithPartialDataRate sets the probability (between 0 and 1) of
+// dropping (at least one of) the keys from the decorated loader's configuration map,
+// simulating, for example, a partially written file / a remote source in an inconsistent state.
+//
+// By default, configuration map is returned as it is.
+func ChaosLoaderWithPartialDataRate(partialDataRate float64) ChaosLoaderOption {
+	return func(decorator *ChaosLoader) {
+		decorator.partialDataRate = partialDataRate
+	}
+}
+
+// ChaosLoaderWithSeed sets the seed of the random numbers generator,
+// for reproducible failure sequences.
+//
+// By default, current time is used as seed.
+func ChaosLoaderWithSeed(seed int64) ChaosLoaderOption {
+	return func(decorator *ChaosLoader) {
+		decorator.rnd = &chaosRand{rnd: rand.New(rand.NewSource(seed))} //nolint:gosec // not security related.
+	}
+}
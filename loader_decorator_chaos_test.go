@@ -0,0 +1,136 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestChaosLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - no options, original loader's result", testChaosLoaderWithoutOptions)
+	t.Run("error - injected errors", testChaosLoaderInjectsErrors)
+	t.Run("success - injected partial data", testChaosLoaderInjectsPartialData)
+	t.Run("success - injected latency", testChaosLoaderInjectsLatency)
+	t.Run("success - same seed, same results", testChaosLoaderWithSeed)
+}
+
+func testChaosLoaderWithoutOptions(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.NewChaosLoader(xconf.PlainLoader(map[string]any{"foo": "bar", "year": 2022}))
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"foo": "bar", "year": 2022}, config)
+	assertEqual(t, xconf.ChaosStats{Loads: 1}, subject.Stats())
+}
+
+func testChaosLoaderInjectsErrors(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loadsCnt int
+		loader   = xconf.LoaderFunc(func() (map[string]any, error) {
+			loadsCnt++
+
+			return map[string]any{"foo": "bar"}, nil
+		})
+		subject = xconf.NewChaosLoader(loader, xconf.ChaosLoaderWithErrorRate(1))
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrChaosInjected))
+	assertNil(t, config)
+	assertEqual(t, 0, loadsCnt)
+	assertEqual(t, xconf.ChaosStats{Loads: 1, InjectedErrors: 1}, subject.Stats())
+}
+
+func testChaosLoaderInjectsPartialData(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	configMap := map[string]any{"a": 1, "b": 2, "c": 3, "d": 4}
+	subject := xconf.NewChaosLoader(
+		xconf.PlainLoader(configMap),
+		xconf.ChaosLoaderWithPartialDataRate(1),
+	)
+
+	for i := 1; i <= 50; i++ {
+		// act
+		config, err := subject.Load()
+
+		// assert
+		requireNil(t, err)
+		assertTrue(t, len(config) < len(configMap))
+		for key, value := range config {
+			assertEqual(t, configMap[key], value)
+		}
+		assertEqual(t, xconf.ChaosStats{Loads: uint64(i), PartialLoads: uint64(i)}, subject.Stats())
+	}
+	assertEqual(t, 4, len(configMap)) // original is untouched.
+}
+
+func testChaosLoaderInjectsLatency(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.NewChaosLoader(
+		xconf.PlainLoader(map[string]any{"foo": "bar"}),
+		xconf.ChaosLoaderWithLatency(10*time.Millisecond, 20*time.Millisecond),
+	)
+	startTime := time.Now()
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	elapsed := time.Since(startTime)
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"foo": "bar"}, config)
+	stats := subject.Stats()
+	assertTrue(t, stats.TotalLatency >= 10*time.Millisecond && stats.TotalLatency <= 20*time.Millisecond)
+	assertTrue(t, elapsed >= stats.TotalLatency)
+}
+
+func testChaosLoaderWithSeed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	newSubject := func() xconf.ChaosLoader {
+		return xconf.NewChaosLoader(
+			xconf.PlainLoader(map[string]any{"a": 1, "b": 2, "c": 3, "d": 4}),
+			xconf.ChaosLoaderWithErrorRate(0.3),
+			xconf.ChaosLoaderWithPartialDataRate(0.3),
+			xconf.ChaosLoaderWithSeed(1492),
+		)
+	}
+	subject1, subject2 := newSubject(), newSubject()
+
+	for i := 0; i < 20; i++ {
+		// act
+		config1, err1 := subject1.Load()
+		config2, err2 := subject2.Load()
+
+		// assert
+		assertEqual(t, err1, err2)
+		assertEqual(t, config1, config2)
+	}
+	assertEqual(t, subject1.Stats(), subject2.Stats())
+}
@@ -0,0 +1,338 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/actforgood/xerr"
+)
+
+// ErrDecryptFailed is an error returned by [DecryptValueLoader] if an encrypted value cannot be decrypted.
+var ErrDecryptFailed = errors.New("value decryption failed")
+
+const (
+	encryptedValuePrefix = "ENC["
+	encryptedValueSuffix = "]"
+	// sopsMetadataKey is the key under which SOPS stores its metadata.
+	sopsMetadataKey = "sops"
+	// aesGCMScheme is the (SOPS compatible) scheme of AES-GCM encrypted values' payload.
+	aesGCMScheme = "AES256_GCM"
+	// aesGCMNonceSize is the size of the nonce used by SOPS.
+	aesGCMNonceSize = 32
+)
+
+// EncryptedValue is an encrypted configuration value, as passed to a [KeyProvider].
+type EncryptedValue struct {
+	// Path holds the keys leading to the value (nested maps' keys; a root key has a single element).
+	Path []string
+	// Payload is the content between the "ENC[" and "]" markers.
+	Payload string
+	// Metadata is the SOPS metadata (the "sops" key of the configuration map), if any.
+	Metadata map[string]any
+}
+
+// KeyProvider decrypts an encrypted value's payload.
+//
+// This package ships an AES-GCM passphrase based provider ([NewAESGCMKeyProvider])
+// and a SOPS provider ([NewSOPSKeyProvider]); it does not ship age / KMS clients,
+// so it does not force a dependency on you. Adapt the one of your choice. Example, with filippo.io/age,
+// for values like "ENC[<armored age ciphertext>]":
+//
+//	provider := xconf.KeyProviderFunc(func(_ context.Context, value xconf.EncryptedValue) ([]byte, error) {
+//		reader, err := age.Decrypt(armor.NewReader(strings.NewReader(value.Payload)), identity)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return io.ReadAll(reader)
+//	})
+type KeyProvider interface {
+	Decrypt(ctx context.Context, value EncryptedValue) ([]byte, error)
+}
+
+// The KeyProviderFunc type is an adapter to allow the use of
+// ordinary functions as KeyProviders (a KMS decrypt callback, for example).
+type KeyProviderFunc func(ctx context.Context, value EncryptedValue) ([]byte, error)
+
+// Decrypt calls fn(ctx, value).
+func (fn KeyProviderFunc) Decrypt(ctx context.Context, value EncryptedValue) ([]byte, error) {
+	return fn(ctx, value)
+}
+
+// DecryptValueLoader decorates another loader to decrypt its encrypted values,
+// so that secrets can be stored in configuration files checked into git without plaintext.
+// Encrypted values are string values wrapped in "ENC[" and "]" markers, found at any depth
+// (nested maps, lists). Their payload is decrypted with given provider.
+// If payload has a "type" field (like SOPS' "ENC[AES256_GCM,data:...,iv:...,tag:...,type:int]"),
+// the decrypted value is converted to that type (int, float, bool), otherwise it is a string.
+// SOPS metadata (the "sops" key), if present, is passed to the provider and removed from the configuration.
+//
+// Example:
+//
+//	loader := xconf.DecryptValueLoader(
+//		xconf.YAMLFileLoader("config.yaml"), // contains db_password: ENC[AES256_GCM,data:...]
+//		xconf.NewAESGCMKeyProvider(os.Getenv("CONFIG_PASSPHRASE")),
+//	)
+func DecryptValueLoader(loader Loader, provider KeyProvider) Loader {
+	return decorate(loader, func(ctx context.Context, loader Loader) (map[string]any, error) {
+		configMap, err := LoadWithContext(ctx, loader)
+		if err != nil {
+			return configMap, err
+		}
+
+		decrypter := valueDecrypter{ctx: ctx, provider: provider}
+		if metadata, isMap := configMap[sopsMetadataKey].(map[string]any); isMap {
+			decrypter.metadata = metadata
+			delete(configMap, sopsMetadataKey)
+		}
+		for key, value := range configMap {
+			decryptedValue, err := decrypter.decrypt(value, []string{key})
+			if err != nil {
+				return nil, err
+			}
+			configMap[key] = decryptedValue
+		}
+
+		return configMap, nil
+	})
+}
+
+// valueDecrypter decrypts the encrypted values of a configuration map.
+type valueDecrypter struct {
+	ctx      context.Context
+	provider KeyProvider
+	metadata map[string]any
+}
+
+// decrypt returns given value, decrypted, if encrypted, or with its nested values decrypted.
+func (decrypter valueDecrypter) decrypt(value any, path []string) (any, error) {
+	switch v := value.(type) {
+	case string:
+		if !strings.HasPrefix(v, encryptedValuePrefix) || !strings.HasSuffix(v, encryptedValueSuffix) {
+			return v, nil
+		}
+		payload := v[len(encryptedValuePrefix) : len(v)-len(encryptedValueSuffix)]
+		plaintext, err := decrypter.provider.Decrypt(decrypter.ctx, EncryptedValue{
+			Path:     path,
+			Payload:  payload,
+			Metadata: decrypter.metadata,
+		})
+		if err != nil {
+			return nil, xerr.Wrapf(ErrDecryptFailed, "key %q: %v", strings.Join(path, "."), err)
+		}
+		typedValue, err := typedPlaintext(plaintext, payloadField(payload, "type"))
+		if err != nil {
+			return nil, xerr.Wrapf(ErrDecryptFailed, "key %q: %v", strings.Join(path, "."), err)
+		}
+
+		return typedValue, nil
+	case map[string]any:
+		for key, nestedValue := range v {
+			decryptedValue, err := decrypter.decrypt(nestedValue, append(path[:len(path):len(path)], key))
+			if err != nil {
+				return nil, err
+			}
+			v[key] = decryptedValue
+		}
+	case map[any]any:
+		for key, nestedValue := range v {
+			decryptedValue, err := decrypter.decrypt(nestedValue, append(path[:len(path):len(path)], fmt.Sprint(key)))
+			if err != nil {
+				return nil, err
+			}
+			v[key] = decryptedValue
+		}
+	case []any:
+		for idx, item := range v { // like SOPS, list items share list's path.
+			decryptedValue, err := decrypter.decrypt(item, path)
+			if err != nil {
+				return nil, err
+			}
+			v[idx] = decryptedValue
+		}
+	}
+
+	return value, nil
+}
+
+// payloadField returns the value of a "name:value" field of a comma separated payload.
+func payloadField(payload, name string) string {
+	for _, field := range strings.Split(payload, ",") {
+		if fieldName, fieldValue, found := strings.Cut(field, ":"); found && fieldName == name {
+			return fieldValue
+		}
+	}
+
+	return ""
+}
+
+// typedPlaintext converts plaintext to given (SOPS) type.
+func typedPlaintext(plaintext []byte, valueType string) (any, error) {
+	switch valueType {
+	case "int":
+		return strconv.Atoi(string(plaintext))
+	case "float":
+		return strconv.ParseFloat(string(plaintext), 64)
+	case "bool":
+		return strconv.ParseBool(string(plaintext))
+	default:
+		return string(plaintext), nil
+	}
+}
+
+// aesGCMKeyProvider is a [KeyProvider] decrypting AES-GCM payloads with a key derived from a passphrase.
+type aesGCMKeyProvider struct {
+	key []byte
+}
+
+// NewAESGCMKeyProvider returns a [KeyProvider] which decrypts "AES256_GCM,data:...,iv:...,tag:...,type:..."
+// payloads (see [EncryptAESGCMValue]) with a 256 bits key derived (SHA-256) from given passphrase.
+// As no key stretching is applied, passphrase should be a high entropy one (randomly generated).
+func NewAESGCMKeyProvider(passphrase string) KeyProvider {
+	key := sha256.Sum256([]byte(passphrase))
+
+	return aesGCMKeyProvider{key: key[:]}
+}
+
+// Decrypt decrypts given value's payload. It implements [KeyProvider].
+func (provider aesGCMKeyProvider) Decrypt(_ context.Context, value EncryptedValue) ([]byte, error) {
+	return decryptAESGCMPayload(provider.key, value.Payload, nil)
+}
+
+// EncryptAESGCMValue encrypts given plaintext with a key derived from given passphrase,
+// returning an "ENC[AES256_GCM,data:...,iv:...,tag:...,type:str]" value, decryptable
+// by [NewAESGCMKeyProvider]'s provider. It can be used by tooling to produce encrypted values.
+func EncryptAESGCMValue(passphrase, plaintext string) (string, error) {
+	key := sha256.Sum256([]byte(passphrase))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, aesGCMNonceSize)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aesGCMNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nil, nonce, []byte(plaintext), nil)
+	data, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	return fmt.Sprintf(
+		"%s%s,data:%s,iv:%s,tag:%s,type:str%s",
+		encryptedValuePrefix,
+		aesGCMScheme,
+		base64.StdEncoding.EncodeToString(data),
+		base64.StdEncoding.EncodeToString(nonce),
+		base64.StdEncoding.EncodeToString(tag),
+		encryptedValueSuffix,
+	), nil
+}
+
+// sopsKeyProvider is a [KeyProvider] decrypting SOPS encrypted values.
+type sopsKeyProvider struct {
+	dataKey   func(ctx context.Context, metadata map[string]any) ([]byte, error)
+	cachedMAC string // SOPS metadata's MAC the cached data key belongs to.
+	cachedKey []byte // cached data key.
+	mu        sync.Mutex
+}
+
+// NewSOPSKeyProvider returns a [KeyProvider] which decrypts the values of a SOPS encrypted document.
+// Given dataKey function returns the (decrypted) data key, from SOPS metadata (for example,
+// by decrypting "age" / "kms" entries' "enc" data key with an age identity / KMS).
+// The data key is cached for as long as the document's MAC does not change.
+// Note: the document's MAC is not verified.
+//
+// Example:
+//
+//	provider := xconf.NewSOPSKeyProvider(func(ctx context.Context, metadata map[string]any) ([]byte, error) {
+//		recipients, _ := metadata["age"].([]any)
+//		recipient, _ := recipients[0].(map[string]any)
+//		enc, _ := recipient["enc"].(string)
+//		reader, err := age.Decrypt(armor.NewReader(strings.NewReader(enc)), identity)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return io.ReadAll(reader)
+//	})
+//	loader := xconf.DecryptValueLoader(xconf.YAMLFileLoader("secrets.enc.yaml"), provider)
+func NewSOPSKeyProvider(dataKey func(ctx context.Context, metadata map[string]any) ([]byte, error)) KeyProvider {
+	return &sopsKeyProvider{dataKey: dataKey}
+}
+
+// Decrypt decrypts given value's payload. It implements [KeyProvider].
+func (provider *sopsKeyProvider) Decrypt(ctx context.Context, value EncryptedValue) ([]byte, error) {
+	if value.Metadata == nil {
+		return nil, errors.New("missing SOPS metadata")
+	}
+	key, err := provider.key(ctx, value.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	additionalData := []byte(strings.Join(value.Path, ":") + ":")
+
+	return decryptAESGCMPayload(key, value.Payload, additionalData)
+}
+
+// key returns the data key, cached one, if document's MAC did not change.
+func (provider *sopsKeyProvider) key(ctx context.Context, metadata map[string]any) ([]byte, error) {
+	mac, _ := metadata["mac"].(string)
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	if mac != "" && mac == provider.cachedMAC {
+		return provider.cachedKey, nil
+	}
+	key, err := provider.dataKey(ctx, metadata)
+	if err != nil {
+		return nil, err
+	}
+	provider.cachedMAC, provider.cachedKey = mac, key
+
+	return key, nil
+}
+
+// decryptAESGCMPayload decrypts an "AES256_GCM,data:...,iv:...,tag:...,type:..." payload.
+func decryptAESGCMPayload(key []byte, payload string, additionalData []byte) ([]byte, error) {
+	if scheme, _, _ := strings.Cut(payload, ","); scheme != aesGCMScheme {
+		return nil, fmt.Errorf("unsupported scheme %q", scheme)
+	}
+	var fields [3][]byte
+	for idx, name := range [...]string{"data", "iv", "tag"} {
+		field, err := base64.StdEncoding.DecodeString(payloadField(payload, name))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		fields[idx] = field
+	}
+	data, nonce, tag := fields[0], fields[1], fields[2]
+	if len(nonce) == 0 {
+		return nil, errors.New("missing iv")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(nonce))
+	if err != nil {
+		return nil, err
+	}
+
+	return gcm.Open(nil, nonce, append(data, tag...), additionalData)
+}
@@ -0,0 +1,209 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestDecryptValueLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - AES-GCM encrypted values are decrypted", testDecryptValueLoaderWithAESGCM)
+	t.Run("error - wrong passphrase", testDecryptValueLoaderWithWrongPassphrase)
+	t.Run("success - SOPS document", testDecryptValueLoaderWithSOPS)
+	t.Run("success - custom key provider", testDecryptValueLoaderWithCustomProvider)
+	t.Run("error - original loader", testDecryptValueLoaderReturnsErrFromDecoratedLoader)
+}
+
+func testDecryptValueLoaderWithAESGCM(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	const passphrase = "t0p-s3cr3t-r4nd0m-p4ssphr4s3"
+	encPassword, err := xconf.EncryptAESGCMValue(passphrase, "s3cr3t")
+	requireNil(t, err)
+	encPort, err := xconf.EncryptAESGCMValue(passphrase, "3306")
+	requireNil(t, err)
+	encPort = strings.Replace(encPort, "type:str", "type:int", 1)
+	encToken, err := xconf.EncryptAESGCMValue(passphrase, "abc")
+	requireNil(t, err)
+	subject := xconf.DecryptValueLoader(
+		xconf.PlainLoader(map[string]any{
+			"db": map[string]any{
+				"password": encPassword,
+				"port":     encPort,
+				"host":     "localhost",
+			},
+			"tokens":   []any{encToken, "plain"},
+			"app_name": "demo",
+		}),
+		xconf.NewAESGCMKeyProvider(passphrase),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"db": map[string]any{
+				"password": "s3cr3t",
+				"port":     3306,
+				"host":     "localhost",
+			},
+			"tokens":   []any{"abc", "plain"},
+			"app_name": "demo",
+		},
+		config,
+	)
+}
+
+func testDecryptValueLoaderWithWrongPassphrase(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	encPassword, err := xconf.EncryptAESGCMValue("t0p-s3cr3t-r4nd0m-p4ssphr4s3", "s3cr3t")
+	requireNil(t, err)
+	subject := xconf.DecryptValueLoader(
+		xconf.PlainLoader(map[string]any{"db_password": encPassword}),
+		xconf.NewAESGCMKeyProvider("wrong-passphrase"),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrDecryptFailed))
+	assertTrue(t, strings.Contains(err.Error(), `"db_password"`))
+	assertNil(t, config)
+}
+
+// sopsEncrypt encrypts a value like SOPS does, with given data key, for given path.
+func sopsEncrypt(t *testing.T, dataKey []byte, plaintext, valueType string, path ...string) string {
+	t.Helper()
+
+	block, err := aes.NewCipher(dataKey)
+	requireNil(t, err)
+	gcm, err := cipher.NewGCMWithNonceSize(block, 32)
+	requireNil(t, err)
+	nonce := make([]byte, 32)
+	nonce[0] = byte(len(path))
+	sealed := gcm.Seal(nil, nonce, []byte(plaintext), []byte(strings.Join(path, ":")+":"))
+	data, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	return "ENC[AES256_GCM,data:" + base64.StdEncoding.EncodeToString(data) +
+		",iv:" + base64.StdEncoding.EncodeToString(nonce) +
+		",tag:" + base64.StdEncoding.EncodeToString(tag) +
+		",type:" + valueType + "]"
+}
+
+func testDecryptValueLoaderWithSOPS(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		dataKey      = []byte("0123456789abcdef0123456789abcdef")
+		dataKeyCalls uint32
+		provider     = xconf.NewSOPSKeyProvider(func(_ context.Context, metadata map[string]any) ([]byte, error) {
+			atomic.AddUint32(&dataKeyCalls, 1)
+			recipients, _ := metadata["age"].([]any)
+			recipient, _ := recipients[0].(map[string]any)
+			if recipient["enc"] != "encrypted-data-key" {
+				return nil, errors.New("unexpected metadata")
+			}
+
+			return dataKey, nil
+		})
+		subject = xconf.DecryptValueLoader(
+			xconf.PlainLoader(map[string]any{
+				"db": map[string]any{
+					"password": sopsEncrypt(t, dataKey, "s3cr3t", "str", "db", "password"),
+					"debug":    sopsEncrypt(t, dataKey, "true", "bool", "db", "debug"),
+				},
+				"ratio": sopsEncrypt(t, dataKey, "0.5", "float", "ratio"),
+				"sops": map[string]any{
+					"age": []any{map[string]any{"recipient": "age1xyz", "enc": "encrypted-data-key"}},
+					"mac": "ENC[AES256_GCM,data:mac...]",
+				},
+			}),
+			provider,
+		)
+	)
+
+	for i := 0; i < 2; i++ {
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(
+			t,
+			map[string]any{
+				"db":    map[string]any{"password": "s3cr3t", "debug": true},
+				"ratio": 0.5,
+			},
+			config,
+		)
+	}
+	assertEqual(t, uint32(1), atomic.LoadUint32(&dataKeyCalls))
+}
+
+func testDecryptValueLoaderWithCustomProvider(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		paths    [][]string
+		provider = xconf.KeyProviderFunc(func(_ context.Context, value xconf.EncryptedValue) ([]byte, error) {
+			paths = append(paths, value.Path)
+
+			return []byte(strings.ToUpper(value.Payload)), nil // a "KMS" call.
+		})
+		subject = xconf.DecryptValueLoader(
+			xconf.PlainLoader(map[string]any{
+				"api": map[any]any{"key": "ENC[kms:abc]"},
+			}),
+			provider,
+		)
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"api": map[any]any{"key": "KMS:ABC"}}, config)
+	assertEqual(t, [][]string{{"api", "key"}}, paths)
+}
+
+func testDecryptValueLoaderReturnsErrFromDecoratedLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.DecryptValueLoader(
+		xconf.JSONFileLoader("testdata/this-file-does-not-exist.json"),
+		xconf.NewAESGCMKeyProvider("passphrase"),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNotNil(t, err)
+	assertNil(t, config)
+}
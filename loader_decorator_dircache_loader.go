@@ -0,0 +1,205 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DirCacheLoader decorates another loader which reads multiple files from a directory
+// (like a [SecretsDirLoader], or a [MultiLoader] of files from same directory)
+// to load configuration only if directory's content changed.
+// Directory is walked recursively and an aggregate fingerprint of its files is computed
+// (based on files' paths, sizes and modification times, or on files' content, see
+// [DirCacheLoaderWithContentHash]). If the fingerprint did not change since the previous load,
+// the previous, already processed, configuration map is returned.
+// Adding / removing / modifying a file invalidates the cache.
+type DirCacheLoader struct {
+	loader      Loader    // a "directory" loader.
+	dirPath     string    // directory's path.
+	contentHash bool      // flag indicating whether fingerprint is based on files' content.
+	cache       *dirCache // cache storage.
+}
+
+// NewDirCacheLoader instantiates a new DirCacheLoader object that loads
+// and caches the configuration from the original "directory" loader.
+// The second parameter should be the directory the original loader reads from.
+func NewDirCacheLoader(loader Loader, dirPath string, opts ...DirCacheLoaderOption) DirCacheLoader {
+	decorator := DirCacheLoader{
+		loader:  loader,
+		dirPath: dirPath,
+		cache:   new(dirCache),
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&decorator)
+	}
+
+	return decorator
+}
+
+// Load returns decorated loader's key-value configuration map.
+// If the directory's content changed since last load, the decorated loader is called,
+// if not, the previous, already processed, configuration map will be returned.
+func (decorator DirCacheLoader) Load() (map[string]any, error) {
+	return decorator.LoadContext(context.Background())
+}
+
+// LoadContext is like Load, passing given context to the decorated loader.
+// It implements [ContextLoader].
+func (decorator DirCacheLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	fingerprint, err := decorator.fingerprint()
+	if err != nil {
+		return nil, err
+	}
+	if configMap := decorator.cache.load(fingerprint); configMap != nil {
+		return configMap, nil
+	}
+
+	// coalesce concurrent reloads.
+	decorator.cache.reloadMu.Lock()
+	defer decorator.cache.reloadMu.Unlock()
+	if configMap := decorator.cache.load(fingerprint); configMap != nil {
+		return configMap, nil // another goroutine has just reloaded the configuration.
+	}
+
+	configMap, err := LoadWithContext(ctx, decorator.loader)
+	if err != nil {
+		return configMap, err
+	}
+
+	decorator.cache.save(configMap, fingerprint)
+
+	return configMap, nil
+}
+
+// fingerprint computes directory's aggregate fingerprint.
+// Note: [filepath.WalkDir] walks files in lexical order, so fingerprint is deterministic.
+func (decorator DirCacheLoader) fingerprint() (string, error) {
+	hasher := sha256.New()
+	err := filepath.WalkDir(decorator.dirPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		fInfo, err := os.Stat(path) // follow symlinks.
+		if err != nil {
+			return err
+		}
+		if fInfo.IsDir() {
+			return nil
+		}
+		_, _ = io.WriteString(hasher, path)
+		if decorator.contentHash {
+			return hashFileContent(hasher, path)
+		}
+		var buf [16]byte
+		binary.LittleEndian.PutUint64(buf[:8], uint64(fInfo.Size()))
+		binary.LittleEndian.PutUint64(buf[8:], uint64(fInfo.ModTime().UnixNano()))
+		_, _ = hasher.Write(buf[:])
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(hasher.Sum(nil)), nil
+}
+
+// hashFileContent writes file's content to the hasher.
+func hashFileContent(hasher hash.Hash, filePath string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(hasher, f)
+
+	return err
+}
+
+// Close closes the decorated loader, if it implements [io.Closer].
+func (decorator DirCacheLoader) Close() error {
+	return CloseLoaders(decorator.loader)
+}
+
+// Unwrap returns the decorated loader.
+func (decorator DirCacheLoader) Unwrap() []Loader {
+	return []Loader{decorator.loader}
+}
+
+// mapLoaders returns a copy of the decorator, decorating the loader returned by fn.
+func (decorator DirCacheLoader) mapLoaders(fn func(Loader) Loader) Loader {
+	decorator.loader = fn(decorator.loader)
+
+	return decorator
+}
+
+// dirCache holds caching info.
+type dirCache struct {
+	entry       cacheEntry       // cached config map.
+	fingerprint string           // directory's fingerprint.
+	obfuscator  *cacheObfuscator // optional cached config map obfuscator.
+	mu          sync.RWMutex     // concurrency semaphore
+	reloadMu    sync.Mutex       // reload concurrency semaphore
+}
+
+// save stores configuration key-value map and directory's fingerprint.
+func (cache *dirCache) save(configMap map[string]any, fingerprint string) {
+	cache.mu.Lock()
+	cache.entry = newCacheEntry(configMap, cache.obfuscator)
+	cache.fingerprint = fingerprint
+	cache.mu.Unlock()
+}
+
+// load retrieves configuration key-value map comparing directory's fingerprint.
+func (cache *dirCache) load(fingerprint string) map[string]any {
+	cache.mu.RLock()
+	defer cache.mu.RUnlock()
+
+	if cache.entry.isZero() || cache.fingerprint != fingerprint {
+		return nil
+	}
+
+	return cache.entry.load(cache.obfuscator)
+}
+
+// DirCacheLoaderOption defines optional function for configuring
+// a DirCacheLoader object.
+type DirCacheLoaderOption func(*DirCacheLoader)
+
+// DirCacheLoaderWithContentHash makes directory's fingerprint be computed
+// based on files' content, instead of files' sizes and modification times.
+// It's more expensive, as all files are read on each Load, but it detects
+// changes that preserve modification time (like some atomic replacements / copies).
+func DirCacheLoaderWithContentHash() DirCacheLoaderOption {
+	return func(decorator *DirCacheLoader) {
+		decorator.contentHash = true
+	}
+}
+
+// DirCacheLoaderWithObfuscation makes the cache store the configuration map serialized
+// and obfuscated, instead of a live map, deserializing it on each load.
+// It trades CPU for reduced plaintext exposure of secrets in core dumps of long-running processes.
+// Note: it is an obfuscation, not an encryption you can rely on, as the key is kept in memory, too.
+func DirCacheLoaderWithObfuscation() DirCacheLoaderOption {
+	return func(decorator *DirCacheLoader) {
+		decorator.cache.obfuscator = newCacheObfuscator()
+	}
+}
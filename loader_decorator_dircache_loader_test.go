@@ -0,0 +1,183 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestDirCacheLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - config is loaded from cache", testDirCacheLoaderSuccess)
+	t.Run("success - with content hash", testDirCacheLoaderWithContentHash)
+	t.Run("success - with obfuscation", testDirCacheLoaderWithObfuscation)
+	t.Run("error - not existing directory", testDirCacheLoaderReturnsWalkError)
+	t.Run("error - original, decorated loader", testDirCacheLoaderReturnsErrFromDecoratedLoader)
+}
+
+func testDirCacheLoaderSuccess(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	dirPath := t.TempDir()
+	requireNil(t, os.WriteFile(filepath.Join(dirPath, "db_user"), []byte("john"), 0o600))
+	requireNil(t, os.Mkdir(filepath.Join(dirPath, "db"), 0o700))
+	requireNil(t, os.WriteFile(filepath.Join(dirPath, "db", "password"), []byte("s3cr3t"), 0o600))
+	dirLoader := xconf.NewSecretsDirLoader(dirPath, xconf.SecretsDirLoaderWithRecursion())
+	loaderCallsCnt := 0
+	loader := xconf.LoaderFunc(func() (map[string]any, error) {
+		loaderCallsCnt++
+
+		return dirLoader.Load()
+	})
+	subject := xconf.NewDirCacheLoader(loader, dirPath)
+
+	// act & assert - first time content should be loaded from decorated loader.
+	config, err := subject.Load()
+	requireNil(t, err)
+	assertEqual(t, 1, loaderCallsCnt)
+	assertEqual(t, map[string]any{"db_user": "john", "db.password": "s3cr3t"}, config)
+
+	// act & assert - second time result should be taken from cache.
+	config, err = subject.Load()
+	requireNil(t, err)
+	assertEqual(t, 1, loaderCallsCnt) // still 1
+	assertEqual(t, map[string]any{"db_user": "john", "db.password": "s3cr3t"}, config)
+
+	// act & assert - a nested file is modified, result should be reloaded.
+	requireNil(t, os.WriteFile(filepath.Join(dirPath, "db", "password"), []byte("n3w s3cr3t"), 0o600))
+	future := time.Now().Add(time.Minute)
+	requireNil(t, os.Chtimes(filepath.Join(dirPath, "db", "password"), future, future))
+	config, err = subject.Load()
+	requireNil(t, err)
+	assertEqual(t, 2, loaderCallsCnt)
+	assertEqual(t, map[string]any{"db_user": "john", "db.password": "n3w s3cr3t"}, config)
+
+	// act & assert - a file is added, result should be reloaded.
+	requireNil(t, os.WriteFile(filepath.Join(dirPath, "api_key"), []byte("abc"), 0o600))
+	config, err = subject.Load()
+	requireNil(t, err)
+	assertEqual(t, 3, loaderCallsCnt)
+	assertEqual(t, map[string]any{"db_user": "john", "db.password": "n3w s3cr3t", "api_key": "abc"}, config)
+
+	// act & assert - a file is removed, result should be reloaded.
+	requireNil(t, os.Remove(filepath.Join(dirPath, "api_key")))
+	config, err = subject.Load()
+	requireNil(t, err)
+	assertEqual(t, 4, loaderCallsCnt)
+	assertEqual(t, map[string]any{"db_user": "john", "db.password": "n3w s3cr3t"}, config)
+}
+
+func testDirCacheLoaderWithObfuscation(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	dirPath := t.TempDir()
+	requireNil(t, os.WriteFile(filepath.Join(dirPath, "db_password"), []byte("s3cr3t"), 0o600))
+	dirLoader := xconf.NewSecretsDirLoader(dirPath)
+	loaderCallsCnt := 0
+	loader := xconf.LoaderFunc(func() (map[string]any, error) {
+		loaderCallsCnt++
+
+		return dirLoader.Load()
+	})
+	subject := xconf.NewDirCacheLoader(loader, dirPath, xconf.DirCacheLoaderWithObfuscation())
+
+	for i := 0; i < 3; i++ {
+		// act
+		config, err := subject.Load()
+
+		// assert
+		requireNil(t, err)
+		assertEqual(t, 1, loaderCallsCnt)
+		assertEqual(t, map[string]any{"db_password": "s3cr3t"}, config)
+
+		config["db_password"] = "modified" // safe-mutable config map.
+	}
+}
+
+func testDirCacheLoaderWithContentHash(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	dirPath := t.TempDir()
+	filePath := filepath.Join(dirPath, "token")
+	requireNil(t, os.WriteFile(filePath, []byte("abc"), 0o600))
+	fInfo, err := os.Stat(filePath)
+	requireNil(t, err)
+	loaderCallsCnt := 0
+	loader := xconf.LoaderFunc(func() (map[string]any, error) {
+		loaderCallsCnt++
+		content, err := os.ReadFile(filePath)
+
+		return map[string]any{"token": string(content)}, err
+	})
+	subject := xconf.NewDirCacheLoader(loader, dirPath, xconf.DirCacheLoaderWithContentHash())
+
+	// act & assert - first time content should be loaded from decorated loader.
+	config, err := subject.Load()
+	requireNil(t, err)
+	assertEqual(t, 1, loaderCallsCnt)
+	assertEqual(t, map[string]any{"token": "abc"}, config)
+
+	// act & assert - content is modified, preserving size and modification time.
+	requireNil(t, os.WriteFile(filePath, []byte("xyz"), 0o600))
+	requireNil(t, os.Chtimes(filePath, fInfo.ModTime(), fInfo.ModTime()))
+	config, err = subject.Load()
+	requireNil(t, err)
+	assertEqual(t, 2, loaderCallsCnt)
+	assertEqual(t, map[string]any{"token": "xyz"}, config)
+
+	// act & assert - nothing changed, result should be taken from cache.
+	config, err = subject.Load()
+	requireNil(t, err)
+	assertEqual(t, 2, loaderCallsCnt)
+	assertEqual(t, map[string]any{"token": "xyz"}, config)
+}
+
+func testDirCacheLoaderReturnsWalkError(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.NewDirCacheLoader(
+		xconf.PlainLoader(map[string]any{"foo": "bar"}),
+		"/this/path/does/not/exist",
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, os.ErrNotExist))
+	assertNil(t, config)
+}
+
+func testDirCacheLoaderReturnsErrFromDecoratedLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered decorated loader error")
+		loader      = xconf.LoaderFunc(func() (map[string]any, error) {
+			return nil, expectedErr
+		})
+		subject = xconf.NewDirCacheLoader(loader, t.TempDir())
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertNil(t, config)
+}
@@ -0,0 +1,284 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/actforgood/xerr"
+	"github.com/spf13/cast"
+)
+
+// ErrUnresolvedPlaceholder is returned by [ExpandEnvLoader] / [TemplateLoader], with [ExpandEnvLoaderWithStrict] /
+// [TemplateLoaderWithStrict] option applied, if a placeholder / referenced key cannot be resolved.
+var ErrUnresolvedPlaceholder = errors.New("unresolved placeholder")
+
+// ErrPlaceholderCycle is returned by [ExpandEnvLoader] / [TemplateLoader] if keys reference each other in a cycle.
+var ErrPlaceholderCycle = errors.New("placeholder cycle")
+
+// ExpandSource is a source placeholders are resolved against.
+type ExpandSource int
+
+const (
+	// ExpandFromKeys resolves placeholders against other (first level) keys of the same configuration.
+	ExpandFromKeys ExpandSource = iota
+	// ExpandFromEnv resolves placeholders against OS's ENV.
+	ExpandFromEnv
+)
+
+// ExpandEnvLoader decorates another loader to expand placeholders found in string values,
+// like "${DB_HOST}:${db.port}", resolving them against other keys of the same configuration
+// and / or against OS's ENV. String values of nested maps / slices are expanded, too.
+// A placeholder can specify a default value, used if it cannot be resolved: "${DB_PORT:-3306}".
+// If a value is a single placeholder which references a key, the key's value is kept as it is
+// (not converted to string), for example "${db.port}" => 3306.
+// Unresolved placeholders are left as they are, unless [ExpandEnvLoaderWithStrict] is applied.
+type ExpandEnvLoader struct {
+	// original, decorated loader.
+	loader Loader
+	// sources placeholders are resolved against, in order.
+	sources []ExpandSource
+	// placeholder's left delimiter.
+	leftDelim string
+	// placeholder's right delimiter.
+	rightDelim string
+	// flag that indicates whether an unresolved placeholder is an error.
+	strict bool
+}
+
+// NewExpandEnvLoader instantiates a new ExpandEnvLoader object that expands
+// placeholders found in string values.
+func NewExpandEnvLoader(loader Loader, opts ...ExpandEnvLoaderOption) ExpandEnvLoader {
+	expandLoader := ExpandEnvLoader{
+		loader:     loader,
+		sources:    []ExpandSource{ExpandFromKeys, ExpandFromEnv},
+		leftDelim:  "${",
+		rightDelim: "}",
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&expandLoader)
+	}
+
+	return expandLoader
+}
+
+// Load returns a configuration key-value map from original loader, with placeholders expanded.
+func (decorator ExpandEnvLoader) Load() (map[string]any, error) {
+	return decorator.LoadContext(context.Background())
+}
+
+// LoadContext is like Load, passing given context to the decorated loader.
+// It implements [ContextLoader].
+func (decorator ExpandEnvLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	configMap, err := LoadWithContext(ctx, decorator.loader)
+	if err != nil {
+		return configMap, err
+	}
+
+	exp := expansion{
+		decorator: decorator,
+		configMap: configMap,
+		resolved:  make(map[string]any, len(configMap)),
+		resolving: make(map[string]bool),
+	}
+	expandedConfigMap := make(map[string]any, len(configMap))
+	for key := range configMap {
+		value, err := exp.resolveKey(key)
+		if err != nil {
+			return nil, err
+		}
+		expandedConfigMap[key] = value
+	}
+
+	return expandedConfigMap, nil
+}
+
+// Close closes the decorated loader, if it implements [io.Closer].
+func (decorator ExpandEnvLoader) Close() error {
+	return CloseLoaders(decorator.loader)
+}
+
+// Unwrap returns the decorated loader.
+func (decorator ExpandEnvLoader) Unwrap() []Loader {
+	return []Loader{decorator.loader}
+}
+
+// mapLoaders returns a copy of the decorator, decorating the loader returned by fn.
+func (decorator ExpandEnvLoader) mapLoaders(fn func(Loader) Loader) Loader {
+	decorator.loader = fn(decorator.loader)
+
+	return decorator
+}
+
+// expansion holds the state of expanding a configuration map.
+type expansion struct {
+	decorator ExpandEnvLoader
+	configMap map[string]any  // the original configuration map.
+	resolved  map[string]any  // already expanded keys' values.
+	resolving map[string]bool // keys being expanded, for cycle detection.
+}
+
+// resolveKey returns given key's expanded value.
+func (exp expansion) resolveKey(key string) (any, error) {
+	if value, found := exp.resolved[key]; found {
+		return value, nil
+	}
+	if exp.resolving[key] {
+		return nil, xerr.Wrapf(ErrPlaceholderCycle, "key %q", key)
+	}
+	exp.resolving[key] = true
+	value, err := exp.expandValue(exp.configMap[key], key)
+	delete(exp.resolving, key)
+	if err != nil {
+		return nil, err
+	}
+	exp.resolved[key] = value
+
+	return value, nil
+}
+
+// expandValue expands placeholders in a value, traversing nested maps / slices.
+// path is the full path of the value, for error reporting purposes.
+func (exp expansion) expandValue(value any, path string) (any, error) {
+	switch val := value.(type) {
+	case string:
+		return exp.expandString(val, path)
+	case map[string]any:
+		expanded := make(map[string]any, len(val))
+		for nestedKey, nestedValue := range val {
+			expandedValue, err := exp.expandValue(nestedValue, path+"."+nestedKey)
+			if err != nil {
+				return nil, err
+			}
+			expanded[nestedKey] = expandedValue
+		}
+
+		return expanded, nil
+	case []any:
+		expanded := make([]any, len(val))
+		for idx, item := range val {
+			expandedItem, err := exp.expandValue(item, fmt.Sprintf("%s[%d]", path, idx))
+			if err != nil {
+				return nil, err
+			}
+			expanded[idx] = expandedItem
+		}
+
+		return expanded, nil
+	}
+
+	return value, nil
+}
+
+// expandString expands placeholders in a string value.
+func (exp expansion) expandString(value, path string) (any, error) {
+	leftDelim, rightDelim := exp.decorator.leftDelim, exp.decorator.rightDelim
+	if !strings.Contains(value, leftDelim) {
+		return value, nil
+	}
+
+	var sb strings.Builder
+	for first := true; ; first = false {
+		startIdx := strings.Index(value, leftDelim)
+		if startIdx < 0 {
+			break
+		}
+		endIdx := strings.Index(value[startIdx+len(leftDelim):], rightDelim)
+		if endIdx < 0 {
+			break
+		}
+		endIdx += startIdx + len(leftDelim)
+		placeholder := value[startIdx+len(leftDelim) : endIdx]
+		isWholeValue := first && startIdx == 0 && endIdx+len(rightDelim) == len(value)
+
+		resolvedValue, found, err := exp.resolvePlaceholder(placeholder)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			if exp.decorator.strict {
+				return nil, xerr.Wrapf(ErrUnresolvedPlaceholder, "%q in key %q", placeholder, path)
+			}
+			resolvedValue = value[startIdx : endIdx+len(rightDelim)] // leave it as it is.
+		}
+		if isWholeValue {
+			return resolvedValue, nil
+		}
+		sb.WriteString(value[:startIdx])
+		sb.WriteString(cast.ToString(resolvedValue))
+		value = value[endIdx+len(rightDelim):]
+	}
+	sb.WriteString(value)
+
+	return sb.String(), nil
+}
+
+// resolvePlaceholder resolves a placeholder (the content between delimiters) against the sources.
+// It also handles the "name:-default" syntax.
+func (exp expansion) resolvePlaceholder(placeholder string) (any, bool, error) {
+	name, defaultValue, hasDefault := strings.Cut(placeholder, ":-")
+	name = strings.TrimSpace(name)
+	for _, source := range exp.decorator.sources {
+		switch source {
+		case ExpandFromKeys:
+			if _, found := exp.configMap[name]; found {
+				value, err := exp.resolveKey(name)
+
+				return value, err == nil, err
+			}
+		case ExpandFromEnv:
+			if value, found := os.LookupEnv(name); found {
+				return value, true, nil
+			}
+		}
+	}
+	if hasDefault {
+		return defaultValue, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// ExpandEnvLoaderOption defines optional function for configuring
+// an ExpandEnv Loader.
+type ExpandEnvLoaderOption func(*ExpandEnvLoader)
+
+// ExpandEnvLoaderWithSources sets the sources placeholders are resolved against,
+// in the order they are tried.
+// By default, placeholders are resolved against other keys first, and then against OS's ENV.
+func ExpandEnvLoaderWithSources(sources ...ExpandSource) ExpandEnvLoaderOption {
+	return func(loader *ExpandEnvLoader) {
+		if len(sources) > 0 {
+			loader.sources = sources
+		}
+	}
+}
+
+// ExpandEnvLoaderWithDelimiters sets the placeholder's syntax, like "%{" and "}", or "{{" and "}}".
+// By default, placeholders look like "${NAME}".
+func ExpandEnvLoaderWithDelimiters(left, right string) ExpandEnvLoaderOption {
+	return func(loader *ExpandEnvLoader) {
+		if left != "" && right != "" {
+			loader.leftDelim = left
+			loader.rightDelim = right
+		}
+	}
+}
+
+// ExpandEnvLoaderWithStrict makes the loader return an [ErrUnresolvedPlaceholder] based error
+// if a placeholder (without a default value) cannot be resolved.
+// By default, unresolved placeholders are left as they are.
+func ExpandEnvLoaderWithStrict() ExpandEnvLoaderOption {
+	return func(loader *ExpandEnvLoader) {
+		loader.strict = true
+	}
+}
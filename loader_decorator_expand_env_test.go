@@ -0,0 +1,150 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestExpandEnvLoader(t *testing.T) {
+	// Note: do not run this test with t.Parallel() as it sets ENVs.
+	t.Setenv("XCONF_EXPAND_DB_HOST", "10.0.0.1")
+	t.Setenv("XCONF_EXPAND_PORT", "from env")
+
+	t.Run("success - keys and env", testExpandEnvLoaderKeysAndEnv)
+	t.Run("success - env only source", testExpandEnvLoaderEnvOnlySource)
+	t.Run("success - custom delimiters", testExpandEnvLoaderCustomDelimiters)
+	t.Run("error - strict unresolved placeholder", testExpandEnvLoaderReturnsErrUnresolved)
+	t.Run("error - placeholder cycle", testExpandEnvLoaderReturnsErrCycle)
+	t.Run("error - original loader", testExpandEnvLoaderReturnsErrFromLoader)
+}
+
+func testExpandEnvLoaderKeysAndEnv(t *testing.T) {
+	// arrange
+	subject := xconf.NewExpandEnvLoader(xconf.PlainLoader(map[string]any{
+		"XCONF_EXPAND_PORT": 3306,
+		"db.dsn":            "mysql://${XCONF_EXPAND_DB_HOST}:${XCONF_EXPAND_PORT}/${db.name}",
+		"db.name":           "${APP_NAME:-demo}",
+		"db.port":           "${XCONF_EXPAND_PORT}",
+		"db.unresolved":     "${XCONF_EXPAND_NOT_FOUND} stays",
+		"db.replicas": []any{
+			"${XCONF_EXPAND_DB_HOST}",
+			map[string]any{"host": "${db.name}-replica"},
+		},
+		"db.pool": map[string]any{"size": "${POOL_SIZE:-10}"},
+		"app.ttl": 30,
+	}))
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"XCONF_EXPAND_PORT": 3306,
+			"db.dsn":            "mysql://10.0.0.1:3306/demo",
+			"db.name":           "demo",
+			"db.port":           3306, // key's value is kept as it is.
+			"db.unresolved":     "${XCONF_EXPAND_NOT_FOUND} stays",
+			"db.replicas": []any{
+				"10.0.0.1",
+				map[string]any{"host": "demo-replica"},
+			},
+			"db.pool": map[string]any{"size": "10"},
+			"app.ttl": 30,
+		},
+		config,
+	)
+}
+
+func testExpandEnvLoaderEnvOnlySource(t *testing.T) {
+	// arrange
+	subject := xconf.NewExpandEnvLoader(
+		xconf.PlainLoader(map[string]any{
+			"XCONF_EXPAND_PORT": 3306,
+			"db.port":           "${XCONF_EXPAND_PORT}",
+		}),
+		xconf.ExpandEnvLoaderWithSources(xconf.ExpandFromEnv),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, "from env", config["db.port"])
+}
+
+func testExpandEnvLoaderCustomDelimiters(t *testing.T) {
+	// arrange
+	subject := xconf.NewExpandEnvLoader(
+		xconf.PlainLoader(map[string]any{
+			"host": "{{XCONF_EXPAND_DB_HOST}}",
+			"raw":  "${XCONF_EXPAND_DB_HOST}",
+		}),
+		xconf.ExpandEnvLoaderWithDelimiters("{{", "}}"),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"host": "10.0.0.1", "raw": "${XCONF_EXPAND_DB_HOST}"}, config)
+}
+
+func testExpandEnvLoaderReturnsErrUnresolved(t *testing.T) {
+	// arrange
+	subject := xconf.NewExpandEnvLoader(
+		xconf.PlainLoader(map[string]any{
+			"db": map[string]any{"host": "${XCONF_EXPAND_NOT_FOUND}"},
+		}),
+		xconf.ExpandEnvLoaderWithStrict(),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrUnresolvedPlaceholder))
+	assertEqual(t, `"XCONF_EXPAND_NOT_FOUND" in key "db.host": unresolved placeholder`, err.Error())
+	assertNil(t, config)
+}
+
+func testExpandEnvLoaderReturnsErrCycle(t *testing.T) {
+	// arrange
+	subject := xconf.NewExpandEnvLoader(xconf.PlainLoader(map[string]any{
+		"a": "${b}",
+		"b": "x${a}",
+	}))
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrPlaceholderCycle))
+	assertNil(t, config)
+}
+
+func testExpandEnvLoaderReturnsErrFromLoader(t *testing.T) {
+	// arrange
+	expectedErr := errors.New("intentionally triggered load error")
+	subject := xconf.NewExpandEnvLoader(xconf.LoaderFunc(func() (map[string]any, error) {
+		return nil, expectedErr
+	}))
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertNil(t, config)
+}
@@ -6,6 +6,7 @@
 package xconf
 
 import (
+	"context"
 	"os"
 	"sync"
 	"time"
@@ -15,74 +16,241 @@ import (
 // if the file was modified. If the file was not modified since the previous load,
 // the file won't be read and parsed again. You can improve performance this way,
 // if you plan to load configuration multiple times (like using it in DefaultConfig with reload enabled).
+//
+// Concurrent Load calls which find the cache invalidated are coalesced,
+// only one of them calling the decorated loader.
 type FileCacheLoader struct {
-	loader   Loader     // a "file" loader, like JSONFileLoader, YAMLFileLoader, etc...
-	filePath string     // file's path.
-	cache    *fileCache // cache storage.
+	loader        Loader                            // a "file" loader, like JSONFileLoader, YAMLFileLoader, etc...
+	filePaths     []string                          // file's path, followed by extra files' paths, if any.
+	statFn        func(string) (os.FileInfo, error) // function to retrieve file's info.
+	checkInterval time.Duration                     // minimum interval between files' checks.
+	staleOnError  func(error) bool                  // classifies errors for which cache is served.
+	cache         *fileCache                        // cache storage.
 }
 
 // NewFileCacheLoader instantiates a new FileCacheLoader object that loads
 // and caches the configuration from the original "file" loader.
 // The second parameter should be the same file as the original loader's one.
-func NewFileCacheLoader(loader Loader, filePath string) FileCacheLoader {
-	return FileCacheLoader{
-		loader:   loader,
-		filePath: filePath,
-		cache:    new(fileCache),
+func NewFileCacheLoader(loader Loader, filePath string, opts ...FileCacheLoaderOption) FileCacheLoader {
+	decorator := FileCacheLoader{
+		loader:    loader,
+		filePaths: []string{filePath},
+		statFn:    os.Stat,
+		cache:     new(fileCache),
 	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&decorator)
+	}
+
+	return decorator
 }
 
 // Load returns decorated loader's key-value configuration map.
 // If the file was modified since last load, that file will be read and parsed again,
 // if not, the previous, already processed, configuration map will be returned.
 func (decorator FileCacheLoader) Load() (map[string]any, error) {
-	fInfo, err := os.Stat(decorator.filePath)
-	if err != nil {
-		return nil, err
+	return decorator.LoadContext(context.Background())
+}
+
+// LoadContext is like Load, passing given context to the decorated loader.
+// It implements [ContextLoader].
+func (decorator FileCacheLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	if configMap := decorator.cache.loadIfCheckedWithin(decorator.checkInterval); configMap != nil {
+		return configMap, nil
 	}
-	fModifiedAt := fInfo.ModTime()
 
-	if configMap := decorator.cache.load(fModifiedAt); configMap != nil {
+	modTimes, err := decorator.modTimes()
+	if err != nil {
+		return decorator.handleErr(nil, err)
+	}
+	if configMap := decorator.cache.load(modTimes); configMap != nil {
 		return configMap, nil
 	}
 
-	configMap, err := decorator.loader.Load()
+	// coalesce concurrent reloads.
+	decorator.cache.reloadMu.Lock()
+	defer decorator.cache.reloadMu.Unlock()
+	if configMap := decorator.cache.load(modTimes); configMap != nil {
+		return configMap, nil // another goroutine has just reloaded the configuration.
+	}
+
+	configMap, err := LoadWithContext(ctx, decorator.loader)
 	if err != nil {
-		return configMap, err
+		return decorator.handleErr(configMap, err)
 	}
 
-	decorator.cache.save(configMap, fModifiedAt)
+	decorator.cache.save(configMap, modTimes)
 
 	return configMap, nil
 }
 
+// modTimes returns files' modification times.
+func (decorator FileCacheLoader) modTimes() ([]time.Time, error) {
+	modTimes := make([]time.Time, len(decorator.filePaths))
+	for idx, filePath := range decorator.filePaths {
+		fInfo, err := decorator.statFn(filePath)
+		if err != nil {
+			return nil, err
+		}
+		modTimes[idx] = fInfo.ModTime()
+	}
+
+	return modTimes, nil
+}
+
+// handleErr returns the cached configuration, if error is classified as such,
+// otherwise it returns given configuration and error.
+func (decorator FileCacheLoader) handleErr(configMap map[string]any, err error) (map[string]any, error) {
+	if decorator.staleOnError != nil && decorator.staleOnError(err) {
+		if cachedConfigMap := decorator.cache.loadAny(); cachedConfigMap != nil {
+			return cachedConfigMap, nil
+		}
+	}
+
+	return configMap, err
+}
+
+// Close closes the decorated loader, if it implements [io.Closer].
+func (decorator FileCacheLoader) Close() error {
+	return CloseLoaders(decorator.loader)
+}
+
+// Unwrap returns the decorated loader.
+func (decorator FileCacheLoader) Unwrap() []Loader {
+	return []Loader{decorator.loader}
+}
+
+// mapLoaders returns a copy of the decorator, decorating the loader returned by fn.
+func (decorator FileCacheLoader) mapLoaders(fn func(Loader) Loader) Loader {
+	decorator.loader = fn(decorator.loader)
+
+	return decorator
+}
+
 // fileCache holds caching info.
 type fileCache struct {
-	configMap    map[string]any // cached config map.
-	lastModified time.Time      // file's last modified time.
-	mu           sync.RWMutex   // concurrency semaphore
+	entry         cacheEntry       // cached config map.
+	lastModified  []time.Time      // files' last modified time.
+	lastCheckedAt time.Time        // last time files were checked.
+	obfuscator    *cacheObfuscator // optional cached config map obfuscator.
+	mu            sync.RWMutex     // concurrency semaphore
+	reloadMu      sync.Mutex       // reload concurrency semaphore
 }
 
-// save stores configuration key-value map and file's last modified time.
-func (cache *fileCache) save(configMap map[string]any, lastModified time.Time) {
+// save stores configuration key-value map and files' last modified time.
+func (cache *fileCache) save(configMap map[string]any, lastModified []time.Time) {
 	cache.mu.Lock()
-	cache.configMap = DeepCopyConfigMap(configMap)
+	cache.entry = newCacheEntry(configMap, cache.obfuscator)
 	cache.lastModified = lastModified
+	cache.lastCheckedAt = time.Now()
 	cache.mu.Unlock()
 }
 
-// load retrieves configuration key-value map comparing file's modified time.
-func (cache *fileCache) load(currentLastModified time.Time) map[string]any {
+// load retrieves configuration key-value map comparing files' modified time.
+func (cache *fileCache) load(currentLastModified []time.Time) map[string]any {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if len(currentLastModified) != len(cache.lastModified) {
+		return nil // nothing cached yet.
+	}
+	for idx := range currentLastModified {
+		if currentLastModified[idx].After(cache.lastModified[idx]) {
+			return nil
+		}
+	}
+	cache.lastCheckedAt = time.Now()
+
+	return cache.entry.load(cache.obfuscator)
+}
+
+// loadIfCheckedWithin retrieves configuration key-value map if files were checked
+// within given interval.
+func (cache *fileCache) loadIfCheckedWithin(interval time.Duration) map[string]any {
+	if interval <= 0 {
+		return nil
+	}
+
 	cache.mu.RLock()
 	defer cache.mu.RUnlock()
 
-	if !currentLastModified.After(cache.lastModified) {
-		// return a copy not to modify this state from outside (for example from a decorator,
-		// which usually modifies directly the original returned configuration map reference
-		// - for performance reasons, so we ensure from this stateful loader that we return a
-		// new configuration map each time)
-		return DeepCopyConfigMap(cache.configMap)
+	if cache.entry.isZero() || time.Since(cache.lastCheckedAt) >= interval {
+		return nil
 	}
 
-	return nil
+	return cache.entry.load(cache.obfuscator)
+}
+
+// loadAny retrieves configuration key-value map, if any was cached.
+func (cache *fileCache) loadAny() map[string]any {
+	cache.mu.RLock()
+	defer cache.mu.RUnlock()
+
+	if cache.entry.isZero() {
+		return nil
+	}
+
+	return cache.entry.load(cache.obfuscator)
+}
+
+// FileCacheLoaderOption defines optional function for configuring
+// a FileCacheLoader object.
+type FileCacheLoaderOption func(*FileCacheLoader)
+
+// FileCacheLoaderWithStatFunc sets a custom function to retrieve file's info
+// (like modification time). By default, [os.Stat] is used.
+// You can use it, for example, to stat files from an [io/fs.FS] with [io/fs.Stat].
+func FileCacheLoaderWithStatFunc(statFn func(filePath string) (os.FileInfo, error)) FileCacheLoaderOption {
+	return func(decorator *FileCacheLoader) {
+		decorator.statFn = statFn
+	}
+}
+
+// FileCacheLoaderWithCheckInterval sets a minimum interval between files' checks.
+// Load calls occurring within this interval since last check are served from the cache,
+// without stat-ing the files. Useful under heavy concurrent Load calls.
+//
+// By default, files are checked on every Load call.
+func FileCacheLoaderWithCheckInterval(checkInterval time.Duration) FileCacheLoaderOption {
+	return func(decorator *FileCacheLoader) {
+		decorator.checkInterval = checkInterval
+	}
+}
+
+// FileCacheLoaderWithStaleOnError sets a classifier for errors (returned by stat function or
+// the decorated loader) for which previous cached configuration is served, if any, instead of
+// returning the error.
+//
+// Example, serve cached configuration if file is temporarily not accessible,
+// but return the error if it's missing:
+//
+//	xconf.FileCacheLoaderWithStaleOnError(func(err error) bool {
+//		return errors.Is(err, fs.ErrPermission)
+//	})
+//
+// By default, errors are returned.
+func FileCacheLoaderWithStaleOnError(classifier func(err error) bool) FileCacheLoaderOption {
+	return func(decorator *FileCacheLoader) {
+		decorator.staleOnError = classifier
+	}
+}
+
+// FileCacheLoaderWithExtraFiles sets extra files whose modification invalidates the cache too.
+// Useful for loaders that read multiple files which change together (like a certificate and its key).
+func FileCacheLoaderWithExtraFiles(filePaths ...string) FileCacheLoaderOption {
+	return func(decorator *FileCacheLoader) {
+		decorator.filePaths = append(decorator.filePaths, filePaths...)
+	}
+}
+
+// FileCacheLoaderWithObfuscation makes the cache store the configuration map serialized
+// and obfuscated, instead of a live map, deserializing it on each load.
+// It trades CPU for reduced plaintext exposure of secrets in core dumps of long-running processes.
+// Note: it is an obfuscation, not an encryption you can rely on, as the key is kept in memory, too.
+func FileCacheLoaderWithObfuscation() FileCacheLoaderOption {
+	return func(decorator *FileCacheLoader) {
+		decorator.cache.obfuscator = newCacheObfuscator()
+	}
 }
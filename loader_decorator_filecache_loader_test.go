@@ -8,6 +8,7 @@ package xconf_test
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"strconv"
 	"sync"
@@ -25,6 +26,218 @@ func TestFileCacheLoader(t *testing.T) {
 	t.Run("error - fstat", testFileCacheLoaderReturnsFstatError)
 	t.Run("error - original, decorated loader", testFileCacheLoaderReturnsErrFromDecoratedLoader)
 	t.Run("success - safe-mutable config map", testFileCacheLoaderReturnsSafeMutableConfigMap)
+	t.Run("success - with check interval", testFileCacheLoaderWithCheckInterval)
+	t.Run("success - with stale on error", testFileCacheLoaderWithStaleOnError)
+	t.Run("success - with extra files", testFileCacheLoaderWithExtraFiles)
+	t.Run("success - with obfuscation", testFileCacheLoaderWithObfuscation)
+	t.Run("success - with obfuscation, unserializable values are not cached", testFileCacheLoaderWithObfuscationNoCache)
+}
+
+func testFileCacheLoaderWithObfuscation(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		now            = time.Now()
+		statCallsCnt   int
+		loaderCallsCnt int
+		configMap      = map[string]any{
+			"db": map[string]any{
+				"password": "s3cr3t",
+				"hosts":    []any{"10.0.0.1", "10.0.0.2"},
+			},
+			"timeout":    5 * time.Second,
+			"started_at": now.UTC(),
+			"port":       3306,
+		}
+		statFn = statFuncMock(map[string]time.Time{"config.json": now}, nil, &statCallsCnt)
+		loader = xconf.LoaderFunc(func() (map[string]any, error) {
+			loaderCallsCnt++
+
+			return xconf.DeepCopyConfigMap(configMap), nil
+		})
+		subject = xconf.NewFileCacheLoader(
+			loader,
+			"config.json",
+			xconf.FileCacheLoaderWithStatFunc(statFn),
+			xconf.FileCacheLoaderWithObfuscation(),
+		)
+	)
+
+	for i := 0; i < 3; i++ {
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, configMap, config)
+		assertEqual(t, 1, loaderCallsCnt)
+
+		config["port"] = 3307 // safe-mutable config map.
+		config["db"].(map[string]any)["password"] = "modified"
+	}
+}
+
+func testFileCacheLoaderWithObfuscationNoCache(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		now            = time.Now()
+		statCallsCnt   int
+		loaderCallsCnt int
+		statFn         = statFuncMock(map[string]time.Time{"config.json": now}, nil, &statCallsCnt)
+		loader         = xconf.LoaderFunc(func() (map[string]any, error) {
+			loaderCallsCnt++
+
+			return map[string]any{"func": func() {}}, nil
+		})
+		subject = xconf.NewFileCacheLoader(
+			loader,
+			"config.json",
+			xconf.FileCacheLoaderWithStatFunc(statFn),
+			xconf.FileCacheLoaderWithObfuscation(),
+		)
+	)
+
+	for i := 1; i <= 3; i++ {
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, 1, len(config))
+		assertEqual(t, i, loaderCallsCnt)
+	}
+}
+
+// fileInfoMock is a mock for os.FileInfo, with a settable modification time.
+type fileInfoMock struct {
+	os.FileInfo
+	modTime time.Time
+}
+
+func (mock fileInfoMock) ModTime() time.Time {
+	return mock.modTime
+}
+
+// statFuncMock returns a stat function which counts its calls and returns
+// the modification time / error stored for a file.
+func statFuncMock(modTimes map[string]time.Time, errs map[string]error, callsCnt *int) func(string) (os.FileInfo, error) {
+	return func(filePath string) (os.FileInfo, error) {
+		*callsCnt++
+		if err := errs[filePath]; err != nil {
+			return nil, err
+		}
+
+		return fileInfoMock{modTime: modTimes[filePath]}, nil
+	}
+}
+
+func testFileCacheLoaderWithCheckInterval(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		now          = time.Now()
+		statCallsCnt int
+		statFn       = statFuncMock(map[string]time.Time{"config.json": now}, nil, &statCallsCnt)
+		subject      = xconf.NewFileCacheLoader(
+			xconf.PlainLoader(map[string]any{"foo": "bar"}),
+			"config.json",
+			xconf.FileCacheLoaderWithStatFunc(statFn),
+			xconf.FileCacheLoaderWithCheckInterval(time.Hour),
+		)
+	)
+
+	for i := 0; i < 5; i++ {
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, map[string]any{"foo": "bar"}, config)
+	}
+	assertEqual(t, 1, statCallsCnt)
+}
+
+func testFileCacheLoaderWithStaleOnError(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		now          = time.Now()
+		statCallsCnt int
+		modTimes     = map[string]time.Time{"config.json": now}
+		statErrs     = map[string]error{}
+		statFn       = statFuncMock(modTimes, statErrs, &statCallsCnt)
+		subject      = xconf.NewFileCacheLoader(
+			xconf.PlainLoader(map[string]any{"foo": "bar"}),
+			"config.json",
+			xconf.FileCacheLoaderWithStatFunc(statFn),
+			xconf.FileCacheLoaderWithStaleOnError(func(err error) bool {
+				return errors.Is(err, fs.ErrPermission)
+			}),
+		)
+	)
+
+	// act & assert - first time content is loaded.
+	config, err := subject.Load()
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"foo": "bar"}, config)
+
+	// act & assert - permission error, stale content is served.
+	statErrs["config.json"] = &fs.PathError{Op: "stat", Path: "config.json", Err: fs.ErrPermission}
+	config, err = subject.Load()
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"foo": "bar"}, config)
+
+	// act & assert - missing file, error is returned.
+	statErrs["config.json"] = &fs.PathError{Op: "stat", Path: "config.json", Err: fs.ErrNotExist}
+	config, err = subject.Load()
+	assertTrue(t, errors.Is(err, fs.ErrNotExist))
+	assertNil(t, config)
+}
+
+func testFileCacheLoaderWithExtraFiles(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		now            = time.Now()
+		statCallsCnt   int
+		loaderCallsCnt int
+		modTimes       = map[string]time.Time{"cert.pem": now, "key.pem": now}
+		statFn         = statFuncMock(modTimes, nil, &statCallsCnt)
+		loader         = xconf.LoaderFunc(func() (map[string]any, error) {
+			loaderCallsCnt++
+
+			return map[string]any{"calls": loaderCallsCnt}, nil
+		})
+		subject = xconf.NewFileCacheLoader(
+			loader,
+			"cert.pem",
+			xconf.FileCacheLoaderWithStatFunc(statFn),
+			xconf.FileCacheLoaderWithExtraFiles("key.pem"),
+		)
+	)
+
+	// act & assert - first time content is loaded.
+	config, err := subject.Load()
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"calls": 1}, config)
+
+	// act & assert - content is served from cache.
+	config, err = subject.Load()
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"calls": 1}, config)
+
+	// act & assert - extra file is modified, content is reloaded.
+	modTimes["key.pem"] = now.Add(time.Second)
+	config, err = subject.Load()
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"calls": 2}, config)
+	assertEqual(t, 6, statCallsCnt)
 }
 
 func testFileCacheLoaderSuccess(t *testing.T) {
@@ -5,7 +5,10 @@
 
 package xconf
 
-import "strings"
+import (
+	"context"
+	"strings"
+)
 
 // FilterType is just an alias for byte.
 type FilterType byte
@@ -87,8 +90,8 @@ func FilterKVLoader(loader Loader, filters ...FilterKV) Loader {
 	// make 2 buckets of filters.
 	blacklistFilters, whitelistFilters := filterBuckets(filters...)
 
-	return LoaderFunc(func() (map[string]any, error) {
-		configMap, err := loader.Load()
+	return decorate(loader, func(ctx context.Context, loader Loader) (map[string]any, error) {
+		configMap, err := LoadWithContext(ctx, loader)
 		if err != nil {
 			return configMap, err
 		}
@@ -191,3 +194,25 @@ func FilterEmptyValue(_ string, value any) bool {
 
 	return false
 }
+
+// FilterSubtree returns true if a key is the root of given subtree, or it is a flat key
+// derived from that subtree (see [FlattenLoader]). Separator of nested keys is "." by default,
+// another one can be passed as the second parameter.
+// It can be used as a [FilterKV] like:
+//
+//	xconf.FilterKVBlacklistFunc(xconf.FilterSubtree("internal"))
+//
+// A deeper subtree (like "app.internal") nested in its parent's configuration ("app")
+// is not matched (parent's value is not altered); use [PruneSubtreeLoader] to get rid of
+// a subtree, regardless of keys having been flattened or not.
+func FilterSubtree(subtree string, separator ...string) func(key string, value any) bool {
+	sep := "."
+	if len(separator) > 0 {
+		sep = separator[0]
+	}
+	subtreePrefix := subtree + sep
+
+	return func(key string, _ any) bool {
+		return key == subtree || strings.HasPrefix(key, subtreePrefix)
+	}
+}
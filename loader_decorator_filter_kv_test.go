@@ -516,6 +516,111 @@ func ExampleFilterKeyWithSuffix() {
 	// REDIS_SERVICE_PORT: 6379
 }
 
+func TestFilterSubtree(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tests := [...]struct {
+		name           string
+		subtree        string
+		separator      []string
+		inputKey       string
+		inputValue     any
+		expectedResult bool
+		expectedValue  any
+	}{
+		{
+			name:           "key is subtree's root, return true",
+			subtree:        "internal",
+			inputKey:       "internal",
+			inputValue:     map[string]any{"foo": "bar"},
+			expectedResult: true,
+			expectedValue:  map[string]any{"foo": "bar"},
+		},
+		{
+			name:           "key is subtree's flat derivative, return true",
+			subtree:        "internal",
+			inputKey:       "internal.foo",
+			inputValue:     "bar",
+			expectedResult: true,
+			expectedValue:  "bar",
+		},
+		{
+			name:           "key is subtree's flat derivative, custom separator, return true",
+			subtree:        "internal",
+			separator:      []string{"/"},
+			inputKey:       "internal/foo",
+			inputValue:     "bar",
+			expectedResult: true,
+			expectedValue:  "bar",
+		},
+		{
+			name:           "key only shares the prefix, return false",
+			subtree:        "internal",
+			inputKey:       "internals",
+			inputValue:     "bar",
+			expectedResult: false,
+			expectedValue:  "bar",
+		},
+		{
+			name:     "key is subtree's parent, return false, value is not altered",
+			subtree:  "app.internal.secrets",
+			inputKey: "app",
+			inputValue: map[string]any{
+				"name": "demo",
+				"internal": map[any]any{
+					"secrets": map[string]any{"token": "abc"},
+					"debug":   true,
+				},
+			},
+			expectedResult: false,
+			expectedValue: map[string]any{
+				"name": "demo",
+				"internal": map[any]any{
+					"secrets": map[string]any{"token": "abc"},
+					"debug":   true,
+				},
+			},
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			subject := xconf.FilterSubtree(test.subtree, test.separator...)
+
+			// act
+			result := subject(test.inputKey, test.inputValue)
+
+			// assert
+			assertEqual(t, test.expectedResult, result)
+			assertEqual(t, test.expectedValue, test.inputValue)
+		})
+	}
+}
+
+func ExampleFilterSubtree() {
+	origLoader := xconf.NewFlattenLoader(xconf.PlainLoader(map[string]any{
+		"app":      map[string]any{"name": "demo"},
+		"internal": map[string]any{"debug": true},
+	}))
+	loader := xconf.FilterKVLoader(
+		origLoader,
+		xconf.FilterKVBlacklistFunc(xconf.FilterSubtree("internal")),
+	)
+
+	configMap, _ := loader.Load()
+	for key, value := range configMap {
+		fmt.Println(key+":", value)
+	}
+
+	// Unordered output:
+	// app: map[name:demo]
+	// app.name: demo
+}
+
 func ExampleFilterKeyWithPrefix() {
 	origLoader := xconf.PlainLoader(map[string]any{
 		"APP_FOO_1": "bar 1",
@@ -5,7 +5,11 @@
 
 package xconf
 
-import "github.com/spf13/cast"
+import (
+	"context"
+
+	"github.com/spf13/cast"
+)
 
 // FlattenLoader decorates another loader to add shortcuts to leaves' information
 // in a nested configuration key.
@@ -52,7 +56,13 @@ func NewFlattenLoader(loader Loader, opts ...FlattenLoaderOption) FlattenLoader
 // Load returns a configuration key-value map from original loader, enriched with
 // shortcuts to leaves' information in nested configuration key(s).
 func (decorator FlattenLoader) Load() (map[string]any, error) {
-	configMap, err := decorator.loader.Load()
+	return decorator.LoadContext(context.Background())
+}
+
+// LoadContext is like Load, passing given context to the decorated loader.
+// It implements [ContextLoader].
+func (decorator FlattenLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	configMap, err := LoadWithContext(ctx, decorator.loader)
 	if err != nil {
 		return configMap, err
 	}
@@ -112,6 +122,23 @@ func (decorator FlattenLoader) flattenConfigMap(
 	}
 }
 
+// Close closes the decorated loader, if it implements [io.Closer].
+func (decorator FlattenLoader) Close() error {
+	return CloseLoaders(decorator.loader)
+}
+
+// Unwrap returns the decorated loader.
+func (decorator FlattenLoader) Unwrap() []Loader {
+	return []Loader{decorator.loader}
+}
+
+// mapLoaders returns a copy of the decorator, decorating the loader returned by fn.
+func (decorator FlattenLoader) mapLoaders(fn func(Loader) Loader) Loader {
+	decorator.loader = fn(decorator.loader)
+
+	return decorator
+}
+
 // FlattenLoaderOption defines optional function for configuring
 // a Flatten Loader.
 type FlattenLoaderOption func(*FlattenLoader)
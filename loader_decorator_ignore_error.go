@@ -6,6 +6,7 @@
 package xconf
 
 import (
+	"context"
 	"errors"
 )
 
@@ -14,8 +15,8 @@ import (
 // You can ignore, for example, [os.ErrNotExist] for a file based Loader if that file is not
 // mandatory to exist, or Consul's [ErrConsulKeyNotFound], etc.
 func IgnoreErrorLoader(loader Loader, errs ...error) Loader {
-	return LoaderFunc(func() (map[string]any, error) {
-		configMap, err := loader.Load()
+	return decorate(loader, func(ctx context.Context, loader Loader) (map[string]any, error) {
+		configMap, err := LoadWithContext(ctx, loader)
 		if err != nil {
 			for _, ignoreErr := range errs {
 				if errors.Is(err, ignoreErr) {
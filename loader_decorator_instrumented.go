@@ -0,0 +1,242 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// InstrumentedLoader decorates another loader, timing its Load and recording
+// the number of loaded keys and the error, if any.
+// Wrap the nodes of interest of a (deep) loaders graph with it (or instrument the whole graph
+// with [InstrumentLoaders] / [DefaultConfigWithLoaderInstrumentation]), and retrieve the
+// per node report after each reload with [LoaderReports] / DefaultConfig's LoaderReports,
+// in order to identify slow sources.
+//
+// Example:
+//
+//	loader := xconf.NewInstrumentedLoader(
+//		xconf.NewMultiLoader(
+//			true,
+//			xconf.NewInstrumentedLoader(xconf.JSONFileLoader("config.json"), "file"),
+//			xconf.NewInstrumentedLoader(xconf.NewConsulLoader("APP"), "consul"),
+//		),
+//		"root",
+//	)
+type InstrumentedLoader struct {
+	loader Loader              // the decorated loader.
+	name   string              // the name the node is reported with.
+	report *instrumentedReport // the last Load's report.
+}
+
+// instrumentedReport holds the last Load's report, concurrent safe.
+type instrumentedReport struct {
+	mu     sync.RWMutex
+	report LoaderReport
+}
+
+// LoaderReport holds the report of an [InstrumentedLoader]'s last Load.
+type LoaderReport struct {
+	// Name is the name the instrumented loader was given.
+	Name string
+	// Depth is the number of instrumented loaders encapsulating this one.
+	Depth int
+	// LoadedAt is the moment the last Load started (zero value, if never loaded).
+	LoadedAt time.Time
+	// Duration is the duration of the last Load, including encapsulated loaders' durations.
+	Duration time.Duration
+	// Keys is the number of keys returned by the last Load.
+	Keys int
+	// Err is the error returned by the last Load, if any.
+	Err error
+}
+
+// NewInstrumentedLoader instantiates a new InstrumentedLoader object that loads
+// the configuration from the original loader, reporting it with given name.
+func NewInstrumentedLoader(loader Loader, name string) InstrumentedLoader {
+	return InstrumentedLoader{
+		loader: loader,
+		name:   name,
+		report: &instrumentedReport{report: LoaderReport{Name: name}},
+	}
+}
+
+// Load returns decorated loader's key-value configuration map, recording
+// the duration, number of keys and error.
+func (decorator InstrumentedLoader) Load() (map[string]any, error) {
+	return decorator.LoadContext(context.Background())
+}
+
+// LoadContext is like Load, passing given context to the decorated loader.
+// It implements [ContextLoader].
+func (decorator InstrumentedLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	return decorator.record(func() (map[string]any, error) {
+		return LoadWithContext(ctx, decorator.loader)
+	})
+}
+
+// LoadPrefix returns decorated loader's configuration keys having given prefix, if decorated
+// loader is a [PrefixLoader], or its whole configuration otherwise, recording
+// the duration, number of keys and error.
+// It implements [PrefixLoader].
+func (decorator InstrumentedLoader) LoadPrefix(ctx context.Context, prefix string) (map[string]any, error) {
+	prefixLoader, ok := decorator.loader.(PrefixLoader)
+	if !ok {
+		return decorator.LoadContext(ctx)
+	}
+
+	return decorator.record(func() (map[string]any, error) {
+		return prefixLoader.LoadPrefix(ctx, prefix)
+	})
+}
+
+// record calls given load function, recording its duration, number of keys and error.
+func (decorator InstrumentedLoader) record(load func() (map[string]any, error)) (map[string]any, error) {
+	start := time.Now()
+	configMap, err := load()
+	duration := time.Since(start)
+
+	decorator.report.mu.Lock()
+	decorator.report.report = LoaderReport{
+		Name:     decorator.name,
+		LoadedAt: start,
+		Duration: duration,
+		Keys:     len(configMap),
+		Err:      err,
+	}
+	decorator.report.mu.Unlock()
+
+	return configMap, err
+}
+
+// KeySource returns the source given key was loaded from, if decorated loader
+// is a [KeySourcer]. It implements [KeySourcer].
+func (decorator InstrumentedLoader) KeySource(key string) (string, bool) {
+	if sourcer, ok := decorator.loader.(KeySourcer); ok {
+		return sourcer.KeySource(key)
+	}
+
+	return "", false
+}
+
+// Report returns the last Load's report.
+func (decorator InstrumentedLoader) Report() LoaderReport {
+	decorator.report.mu.RLock()
+	defer decorator.report.mu.RUnlock()
+
+	return decorator.report.report
+}
+
+// Close closes the decorated loader, if it implements [io.Closer].
+func (decorator InstrumentedLoader) Close() error {
+	return CloseLoaders(decorator.loader)
+}
+
+// Unwrap returns the decorated loader.
+func (decorator InstrumentedLoader) Unwrap() []Loader {
+	return []Loader{decorator.loader}
+}
+
+// mapLoaders returns a copy of the decorator, decorating the loader returned by fn.
+func (decorator InstrumentedLoader) mapLoaders(fn func(Loader) Loader) Loader {
+	decorator.loader = fn(decorator.loader)
+
+	return decorator
+}
+
+// InstrumentLoaders walks the loaders graph starting from given root (see [LoaderUnwrapper]),
+// and returns a copy of it, with every node decorated with an [InstrumentedLoader], named after
+// the node's type / the function which created it (like "xconf.MultiLoader", "xconf.JSONFileLoader").
+// Nodes which are already instrumented keep their name and are not instrumented twice.
+// Built-in decorators / composite loaders are rebuilt on top of their instrumented loaders;
+// custom ones are instrumented as a whole (the loaders they encapsulate are not instrumented).
+//
+// Example:
+//
+//	loader := xconf.InstrumentLoaders(xconf.NewMultiLoader(
+//		true,
+//		xconf.JSONFileLoader("config.json"),
+//		xconf.NewConsulLoader("APP"),
+//	))
+//	// ... after Load
+//	for _, report := range xconf.LoaderReports(loader) {
+//		fmt.Println(strings.Repeat("  ", report.Depth), report.Name, report.Duration, report.Err)
+//	}
+func InstrumentLoaders(root Loader) Loader {
+	if instrumented, ok := root.(InstrumentedLoader); ok {
+		return instrumented.mapLoaders(instrumentInnerLoaders)
+	}
+
+	return NewInstrumentedLoader(instrumentInnerLoaders(root), loaderName(root))
+}
+
+// instrumentInnerLoaders instruments the loaders encapsulated by given loader, if it's a built-in
+// decorator / composite loader.
+func instrumentInnerLoaders(loader Loader) Loader {
+	if mapper, ok := loader.(loaderMapper); ok {
+		return mapper.mapLoaders(InstrumentLoaders)
+	}
+
+	return loader
+}
+
+// loaderName returns the name an instrumented loader is reported with.
+func loaderName(loader Loader) string {
+	switch l := loader.(type) {
+	case LoaderFunc:
+		return funcName(l)
+	case ContextLoaderFunc:
+		return funcName(l)
+	case decoratedLoader:
+		return l.name
+	default:
+		return fmt.Sprintf("%T", loader)
+	}
+}
+
+// DefaultConfigWithLoaderInstrumentation instruments the whole loaders graph
+// (see [InstrumentLoaders]), so that the per node reports of each (re)load are
+// retrievable with DefaultConfig's LoaderReports, without wrapping nodes manually.
+// By default, only manually wrapped [InstrumentedLoader]s are reported.
+func DefaultConfigWithLoaderInstrumentation() DefaultConfigOption {
+	return func(config *DefaultConfig) {
+		config.loader = InstrumentLoaders(config.loader)
+	}
+}
+
+// LoaderReports walks the loaders graph starting from given root (see [LoaderUnwrapper]),
+// and returns the reports of the [InstrumentedLoader]s found, in depth-first order
+// (an instrumented loader comes before the ones it encapsulates).
+func LoaderReports(root Loader) []LoaderReport {
+	var reports []LoaderReport
+	collectLoaderReports(root, 0, &reports)
+
+	return reports
+}
+
+// collectLoaderReports collects recursively given loader(s) reports.
+func collectLoaderReports(loader Loader, depth int, reports *[]LoaderReport) {
+	if instrumented, ok := loader.(InstrumentedLoader); ok {
+		report := instrumented.Report()
+		report.Depth = depth
+		*reports = append(*reports, report)
+		depth++
+	}
+	if unwrapper, ok := loader.(LoaderUnwrapper); ok {
+		for _, innerLoader := range unwrapper.Unwrap() {
+			collectLoaderReports(innerLoader, depth, reports)
+		}
+	}
+}
+
+// LoaderReports returns the reports of the [InstrumentedLoader]s found
+// in config's loaders graph, reflecting the last (re)load. See [LoaderReports].
+func (cfg *defaultConfig) LoaderReports() []LoaderReport {
+	return LoaderReports(cfg.loader)
+}
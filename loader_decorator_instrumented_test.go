@@ -0,0 +1,221 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestInstrumentedLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - reports are collected from the whole graph", testInstrumentedLoaderReports)
+	t.Run("success - reports are retrievable via DefaultConfig", testInstrumentedLoaderDefaultConfigReports)
+	t.Run("success - whole graph is instrumented", testInstrumentLoaders)
+	t.Run("success - DefaultConfig with loader instrumentation", testDefaultConfigWithLoaderInstrumentation)
+}
+
+func testInstrumentedLoaderReports(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered decorated loader error")
+		slowLoader  = xconf.LoaderFunc(func() (map[string]any, error) {
+			time.Sleep(30 * time.Millisecond)
+
+			return map[string]any{"foo": "bar", "abc": "xyz"}, nil
+		})
+		subject = xconf.NewInstrumentedLoader(
+			xconf.NewMultiLoader(
+				true,
+				xconf.NewInstrumentedLoader(slowLoader, "slow"),
+				xconf.IgnoreErrorLoader(
+					xconf.NewInstrumentedLoader(
+						xconf.LoaderFunc(func() (map[string]any, error) {
+							return nil, expectedErr
+						}),
+						"failing",
+					),
+					expectedErr,
+				),
+				xconf.PlainLoader(map[string]any{"baz": 1}),
+			),
+			"root",
+		)
+	)
+
+	// act
+	reports := xconf.LoaderReports(subject)
+
+	// assert
+	if assertEqual(t, 3, len(reports)) {
+		assertEqual(t, "root", reports[0].Name)
+		assertTrue(t, reports[0].LoadedAt.IsZero())
+		assertEqual(t, "slow", reports[1].Name)
+		assertEqual(t, "failing", reports[2].Name)
+	}
+
+	// act
+	beforeLoadTime := time.Now()
+	config, err := subject.Load()
+	reports = xconf.LoaderReports(subject)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"foo": "bar", "abc": "xyz", "baz": 1}, config)
+	if assertEqual(t, 3, len(reports)) {
+		assertEqual(t, 0, reports[0].Depth)
+		assertEqual(t, 3, reports[0].Keys)
+		assertNil(t, reports[0].Err)
+		assertTrue(t, reports[0].Duration >= 30*time.Millisecond)
+		assertTrue(t, !reports[0].LoadedAt.Before(beforeLoadTime))
+
+		assertEqual(t, 1, reports[1].Depth)
+		assertEqual(t, 2, reports[1].Keys)
+		assertNil(t, reports[1].Err)
+		assertTrue(t, reports[1].Duration >= 30*time.Millisecond)
+		assertTrue(t, reports[1].Duration <= reports[0].Duration)
+
+		assertEqual(t, 1, reports[2].Depth)
+		assertEqual(t, 0, reports[2].Keys)
+		assertTrue(t, errors.Is(reports[2].Err, expectedErr))
+	}
+}
+
+func testInstrumentedLoaderDefaultConfigReports(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(
+		xconf.NewInstrumentedLoader(xconf.PlainLoader(map[string]any{"foo": "bar"}), "plain"),
+	)
+	requireNil(t, err)
+
+	// act
+	reports := subject.LoaderReports()
+
+	// assert
+	if assertEqual(t, 1, len(reports)) {
+		assertEqual(t, "plain", reports[0].Name)
+		assertEqual(t, 1, reports[0].Keys)
+		assertTrue(t, !reports[0].LoadedAt.IsZero())
+	}
+}
+
+func testInstrumentLoaders(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered decorated loader error")
+		slowLoader  = xconf.LoaderFunc(func() (map[string]any, error) {
+			time.Sleep(30 * time.Millisecond)
+
+			return map[string]any{"foo": "bar"}, nil
+		})
+		subject = xconf.InstrumentLoaders(
+			xconf.NewMultiLoader(
+				true,
+				xconf.NamespaceLoader(slowLoader, "slow"),
+				xconf.NewInstrumentedLoader(
+					xconf.IgnoreErrorLoader(
+						xconf.NewFailoverLoader([]xconf.Loader{
+							xconf.LoaderFunc(func() (map[string]any, error) {
+								return nil, expectedErr
+							}),
+						}),
+						expectedErr,
+					),
+					"optional",
+				),
+				xconf.PlainLoader(map[string]any{"baz": 1}),
+			),
+		)
+	)
+
+	// act
+	config, err := subject.Load()
+	reports := xconf.LoaderReports(subject)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"slow.foo": "bar", "baz": 1}, config)
+	if assertEqual(t, 7, len(reports)) {
+		assertEqual(t, "xconf.MultiLoader", reports[0].Name)
+		assertEqual(t, 0, reports[0].Depth)
+		assertEqual(t, 2, reports[0].Keys)
+		assertTrue(t, reports[0].Duration >= 30*time.Millisecond)
+
+		assertEqual(t, "xconf.NamespaceLoader", reports[1].Name)
+		assertEqual(t, 1, reports[1].Depth)
+		assertEqual(t, 1, reports[1].Keys)
+		assertTrue(t, reports[1].Duration >= 30*time.Millisecond)
+
+		assertEqual(t, "xconf_test.testInstrumentLoaders", reports[2].Name)
+		assertEqual(t, 2, reports[2].Depth)
+		assertEqual(t, 1, reports[2].Keys)
+		assertTrue(t, reports[2].Duration >= 30*time.Millisecond)
+
+		assertEqual(t, "optional", reports[3].Name) // already instrumented node is not instrumented twice.
+		assertEqual(t, 1, reports[3].Depth)
+		assertEqual(t, 0, reports[3].Keys)
+		assertNil(t, reports[3].Err)
+
+		assertEqual(t, "xconf.FailoverLoader", reports[4].Name)
+		assertEqual(t, 2, reports[4].Depth)
+		assertTrue(t, errors.Is(reports[4].Err, expectedErr))
+
+		assertEqual(t, "xconf_test.testInstrumentLoaders", reports[5].Name)
+		assertEqual(t, 3, reports[5].Depth)
+		assertTrue(t, errors.Is(reports[5].Err, expectedErr))
+
+		assertEqual(t, "xconf.PlainLoader", reports[6].Name)
+		assertEqual(t, 1, reports[6].Depth)
+		assertEqual(t, 1, reports[6].Keys)
+	}
+}
+
+func testDefaultConfigWithLoaderInstrumentation(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xconf.NewDefaultConfig(
+		xconf.NewMultiLoader(
+			true,
+			xconf.PlainLoader(map[string]any{"foo": "bar"}),
+			xconf.NewFlattenLoader(xconf.PlainLoader(map[string]any{"db": map[string]any{"port": 3306}})),
+		),
+		xconf.DefaultConfigWithLoaderInstrumentation(),
+		xconf.DefaultConfigWithReloadInterval(time.Minute),
+	)
+	requireNil(t, err)
+	defer subject.Close()
+
+	// act
+	errReload := subject.ReloadPrefix(context.Background(), "db.")
+	reports := subject.LoaderReports()
+
+	// assert
+	assertNil(t, errReload)
+	assertEqual(t, 3306, subject.Get("db.port"))
+	if assertEqual(t, 4, len(reports)) {
+		assertEqual(t, "xconf.MultiLoader", reports[0].Name)
+		assertEqual(t, "xconf.PlainLoader", reports[1].Name)
+		assertEqual(t, "xconf.FlattenLoader", reports[2].Name)
+		assertEqual(t, "xconf.PlainLoader", reports[3].Name)
+		assertEqual(t, 2, reports[3].Depth)
+		assertEqual(t, 1, reports[3].Keys)
+		for _, report := range reports {
+			assertTrue(t, !report.LoadedAt.IsZero())
+		}
+	}
+}
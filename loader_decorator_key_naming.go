@@ -0,0 +1,119 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/actforgood/xerr"
+)
+
+// ErrKeyNamingViolation is the error (wrapped along with key and broken rule)
+// reported by [KeyNamingLoader] for a key violating the naming conventions.
+var ErrKeyNamingViolation = errors.New("key naming convention violation")
+
+// KeyNamingRules describes an organization's key naming conventions.
+// A key is checked by its full path, nested maps' keys being joined with Separator
+// (for example "db.pool.max_conns").
+type KeyNamingRules struct {
+	// Pattern, if set, must be matched by every key's full path.
+	// Example: regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*)*$`).
+	Pattern *regexp.Regexp
+	// MaxDepth, if > 0, is the maximum number of Separator delimited segments of a key's full path.
+	MaxDepth int
+	// ReservedPrefixes are prefixes (matched case-insensitive) no key may start with.
+	ReservedPrefixes []string
+	// Separator joins nested maps' keys, and delimits flat keys' segments. Defaults to ".".
+	Separator string
+}
+
+// KeyNamingLoader decorates another loader to check, at load time, that keys respect
+// the naming conventions, preventing the configuration namespace from degrading over time.
+// Keys in nested maps are checked, too.
+//
+// If warnHandler is nil, the configuration is rejected if any key violates the rules,
+// the returned error aggregating all the violations (see [ErrKeyNamingViolation]).
+// Otherwise, the violations are passed to warnHandler (you can log them, for example),
+// and the configuration is returned as it is.
+//
+// Example:
+//
+//	loader := xconf.KeyNamingLoader(
+//		xconf.JSONFileLoader("config.json"),
+//		xconf.KeyNamingRules{
+//			Pattern:          regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*)*$`),
+//			MaxDepth:         4,
+//			ReservedPrefixes: []string{"xconf.", "internal."},
+//		},
+//		nil, // reject
+//	)
+func KeyNamingLoader(loader Loader, rules KeyNamingRules, warnHandler func(error)) Loader {
+	if rules.Separator == "" {
+		rules.Separator = "."
+	}
+
+	return decorate(loader, func(ctx context.Context, loader Loader) (map[string]any, error) {
+		configMap, err := LoadWithContext(ctx, loader)
+		if err != nil {
+			return configMap, err
+		}
+
+		paths := make([]string, 0, len(configMap))
+		collectKeyPaths(configMap, "", rules.Separator, &paths)
+		sort.Strings(paths)
+		var mErr *xerr.MultiError
+		for _, path := range paths {
+			if err := rules.check(path); err != nil {
+				mErr = mErr.Add(err)
+			}
+		}
+		if err := mErr.ErrOrNil(); err != nil {
+			if warnHandler == nil {
+				return nil, err
+			}
+			warnHandler(err)
+		}
+
+		return configMap, nil
+	})
+}
+
+// check returns an error if key's full path violates the rules.
+func (rules KeyNamingRules) check(path string) error {
+	if rules.Pattern != nil && !rules.Pattern.MatchString(path) {
+		return xerr.Wrapf(ErrKeyNamingViolation, "key %q does not match pattern `%s`", path, rules.Pattern)
+	}
+	if depth := strings.Count(path, rules.Separator) + 1; rules.MaxDepth > 0 && depth > rules.MaxDepth {
+		return xerr.Wrapf(ErrKeyNamingViolation, "key %q has depth %d, max %d", path, depth, rules.MaxDepth)
+	}
+	for _, prefix := range rules.ReservedPrefixes {
+		if hasPrefixFold(path, prefix) {
+			return xerr.Wrapf(ErrKeyNamingViolation, "key %q has reserved prefix %q", path, prefix)
+		}
+	}
+
+	return nil
+}
+
+// collectKeyPaths collects the full paths of the leaf keys (non-map / empty map values) from given map.
+func collectKeyPaths(configMap map[string]any, parentPath, separator string, paths *[]string) {
+	for key, value := range configMap {
+		path := key
+		if parentPath != "" {
+			path = parentPath + separator + key
+		}
+		if nestedMap, ok := value.(map[string]any); ok && len(nestedMap) > 0 {
+			collectKeyPaths(nestedMap, path, separator, paths)
+
+			continue
+		}
+		*paths = append(*paths, path)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/actforgood/xconf"
+	"github.com/actforgood/xerr"
+)
+
+var keyNamingRules = xconf.KeyNamingRules{
+	Pattern:          regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*)*$`),
+	MaxDepth:         3,
+	ReservedPrefixes: []string{"xconf."},
+}
+
+func TestKeyNamingLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - valid keys", testKeyNamingLoaderWithValidKeys)
+	t.Run("error - violations rejected", testKeyNamingLoaderRejectsViolations)
+	t.Run("success - violations warned", testKeyNamingLoaderWarnsViolations)
+	t.Run("success - custom separator", testKeyNamingLoaderWithCustomSeparator)
+	t.Run("error - decorated loader", testKeyNamingLoaderReturnsErrFromDecoratedLoader)
+}
+
+func testKeyNamingLoaderWithValidKeys(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	configMap := map[string]any{
+		"app_name":  "demo",
+		"db":        map[string]any{"pool": map[string]any{"max_conns": 10}},
+		"http.port": 8080,
+		"tags":      map[string]any{},
+	}
+	subject := xconf.KeyNamingLoader(xconf.PlainLoader(configMap), keyNamingRules, nil)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, configMap, config)
+}
+
+func testKeyNamingLoaderRejectsViolations(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.KeyNamingLoader(
+		xconf.PlainLoader(map[string]any{
+			"AppName":     "demo",
+			"db":          map[string]any{"pool": map[string]any{"limits": map[string]any{"max_conns": 10}}},
+			"XCONF.debug": true,
+			"http.port":   8080,
+		}),
+		keyNamingRules,
+		nil,
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, errors.Is(err, xconf.ErrKeyNamingViolation))
+	var mErr *xerr.MultiError
+	if assertTrue(t, errors.As(err, &mErr)) {
+		errs := mErr.Errors()
+		if assertEqual(t, 3, len(errs)) {
+			assertEqual(t, `key "AppName" does not match pattern `+"`"+keyNamingRules.Pattern.String()+"`"+`: `+
+				xconf.ErrKeyNamingViolation.Error(), errs[0].Error())
+			assertEqual(t, `key "XCONF.debug" does not match pattern `+"`"+keyNamingRules.Pattern.String()+"`"+`: `+
+				xconf.ErrKeyNamingViolation.Error(), errs[1].Error())
+			assertEqual(t, `key "db.pool.limits.max_conns" has depth 4, max 3: `+
+				xconf.ErrKeyNamingViolation.Error(), errs[2].Error())
+		}
+	}
+}
+
+func testKeyNamingLoaderWarnsViolations(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		warnedErr error
+		configMap = map[string]any{"xconf.debug": true, "port": 8080}
+		subject   = xconf.KeyNamingLoader(
+			xconf.PlainLoader(configMap),
+			keyNamingRules,
+			func(err error) {
+				warnedErr = err
+			},
+		)
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, configMap, config)
+	assertTrue(t, errors.Is(warnedErr, xconf.ErrKeyNamingViolation))
+	assertEqual(t, `key "xconf.debug" has reserved prefix "xconf.": `+xconf.ErrKeyNamingViolation.Error(), warnedErr.Error())
+}
+
+func testKeyNamingLoaderWithCustomSeparator(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.KeyNamingLoader(
+		xconf.PlainLoader(map[string]any{
+			"APP_DB_HOST": "localhost",
+			"APP":         map[string]any{"DB": map[string]any{"PORT": 3306}},
+		}),
+		xconf.KeyNamingRules{
+			Pattern:   regexp.MustCompile(`^APP_[A-Z0-9_]+$`),
+			MaxDepth:  3,
+			Separator: "_",
+		},
+		nil,
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 2, len(config))
+}
+
+func testKeyNamingLoaderReturnsErrFromDecoratedLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered decorated loader error")
+		loader      = xconf.LoaderFunc(func() (map[string]any, error) {
+			return nil, expectedErr
+		})
+		subject = xconf.KeyNamingLoader(loader, keyNamingRules, nil)
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertNil(t, config)
+}
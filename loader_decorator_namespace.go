@@ -0,0 +1,40 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import "context"
+
+// NamespaceLoader decorates another loader to prefix every key from its
+// configuration map with given namespace and a separator (default is ".").
+// This way, multiple instances of the same config file / remote prefix can be
+// mounted under different namespaces (for example through a [MultiLoader]).
+//
+// Example:
+//
+//	xconf.NamespaceLoader(xconf.JSONFileLoader("redis.json"), "cache")    // "host" => "cache.host"
+//	xconf.NamespaceLoader(xconf.JSONFileLoader("redis.json"), "QUEUE", "_") // "host" => "QUEUE_host"
+//
+// Only first level keys are prefixed, nested maps are kept as they are.
+func NamespaceLoader(loader Loader, namespace string, separator ...string) Loader {
+	keyPrefix := namespace + "."
+	if len(separator) > 0 {
+		keyPrefix = namespace + separator[0]
+	}
+
+	return decorate(loader, func(ctx context.Context, loader Loader) (map[string]any, error) {
+		configMap, err := LoadWithContext(ctx, loader)
+		if err != nil {
+			return configMap, err
+		}
+
+		namespacedConfigMap := make(map[string]any, len(configMap))
+		for key, value := range configMap {
+			namespacedConfigMap[keyPrefix+key] = value
+		}
+
+		return namespacedConfigMap, nil
+	})
+}
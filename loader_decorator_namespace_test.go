@@ -0,0 +1,116 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestNamespaceLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - default separator", testNamespaceLoaderWithDefaultSeparator)
+	t.Run("success - custom separator", testNamespaceLoaderWithCustomSeparator)
+	t.Run("error - original, decorated loader", testNamespaceLoaderReturnsErrFromDecoratedLoader)
+}
+
+func testNamespaceLoaderWithDefaultSeparator(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loader = xconf.PlainLoader(map[string]any{
+			"host": "127.0.0.1",
+			"db":   map[string]any{"index": 1},
+		})
+		subject = xconf.NamespaceLoader(loader, "redis")
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"redis.host": "127.0.0.1",
+			"redis.db":   map[string]any{"index": 1},
+		},
+		config,
+	)
+}
+
+func testNamespaceLoaderWithCustomSeparator(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loader = xconf.PlainLoader(map[string]any{
+			"HOST": "127.0.0.1",
+			"PORT": 6379,
+		})
+		subject = xconf.NamespaceLoader(loader, "REDIS", "_")
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"REDIS_HOST": "127.0.0.1",
+			"REDIS_PORT": 6379,
+		},
+		config,
+	)
+}
+
+func testNamespaceLoaderReturnsErrFromDecoratedLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered decorated loader error")
+		loader      = xconf.LoaderFunc(func() (map[string]any, error) {
+			return nil, expectedErr
+		})
+		subject = xconf.NamespaceLoader(loader, "redis")
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertNil(t, config)
+}
+
+func ExampleNamespaceLoader() {
+	redisLoader := xconf.PlainLoader(map[string]any{
+		"host": "127.0.0.1",
+	})
+	loader := xconf.NewMultiLoader(
+		false,
+		xconf.NamespaceLoader(redisLoader, "cache"),
+		xconf.NamespaceLoader(redisLoader, "queue"),
+	)
+
+	configMap, err := loader.Load()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(configMap["cache.host"], configMap["queue.host"])
+
+	// Output:
+	// 127.0.0.1 127.0.0.1
+}
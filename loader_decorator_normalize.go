@@ -0,0 +1,100 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeFunc is a function that normalizes a string config's value.
+type NormalizeFunc func(value string) string
+
+// NormalizeLoader decorates another loader to normalize string values,
+// preventing subtle issues caused by stray spaces, quotes, or different Unicode forms
+// of the same text. It is most useful for env / properties / dotenv sources.
+//
+// Normalization functions are applied in the given order, on all string values,
+// including the ones found in nested maps and slices.
+// If no normalization function is provided, [NormalizeTrimSpace], [NormalizeUnquote]
+// and [NormalizeNFC] are applied.
+func NormalizeLoader(loader Loader, normalizers ...NormalizeFunc) Loader {
+	if len(normalizers) == 0 {
+		normalizers = []NormalizeFunc{NormalizeTrimSpace, NormalizeUnquote, NormalizeNFC}
+	}
+
+	return decorate(loader, func(ctx context.Context, loader Loader) (map[string]any, error) {
+		configMap, err := LoadWithContext(ctx, loader)
+		if err != nil {
+			return configMap, err
+		}
+
+		for key, value := range configMap {
+			configMap[key] = normalizeValue(value, normalizers)
+		}
+
+		return configMap, nil
+	})
+}
+
+// normalizeValue applies normalization functions on a string value,
+// or on string values found in a nested map / slice.
+func normalizeValue(value any, normalizers []NormalizeFunc) any {
+	switch val := value.(type) {
+	case string:
+		for _, normalize := range normalizers {
+			val = normalize(val)
+		}
+
+		return val
+	case []string:
+		for idx := range val {
+			val[idx] = normalizeValue(val[idx], normalizers).(string)
+		}
+	case []any:
+		for idx := range val {
+			val[idx] = normalizeValue(val[idx], normalizers)
+		}
+	case map[string]any:
+		for nestedKey, nestedValue := range val {
+			val[nestedKey] = normalizeValue(nestedValue, normalizers)
+		}
+	case map[any]any:
+		for nestedKey, nestedValue := range val {
+			val[nestedKey] = normalizeValue(nestedValue, normalizers)
+		}
+	}
+
+	return value
+}
+
+// NormalizeTrimSpace removes leading and trailing white spaces.
+func NormalizeTrimSpace(value string) string {
+	return strings.TrimSpace(value)
+}
+
+// NormalizeUnquote removes a pair of surrounding double / single quotes.
+// Escaped characters are not interpreted.
+//
+// Example: `"foo"` => `foo`, `'bar'` => `bar`, `"baz'` => `"baz'`.
+func NormalizeUnquote(value string) string {
+	if len(value) >= 2 {
+		first, last := value[0], value[len(value)-1]
+		if first == last && (first == '"' || first == '\'') {
+			return value[1 : len(value)-1]
+		}
+	}
+
+	return value
+}
+
+// NormalizeNFC converts value to Unicode Normalization Form C.
+// This way, for example, "e" followed by a combining acute accent and "é" become the same.
+func NormalizeNFC(value string) string {
+	return norm.NFC.String(value)
+}
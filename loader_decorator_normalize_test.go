@@ -0,0 +1,123 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestNormalizeLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - default normalizers", testNormalizeLoaderWithDefaultNormalizers)
+	t.Run("success - custom normalizers", testNormalizeLoaderWithCustomNormalizers)
+	t.Run("error - original, decorated loader", testNormalizeLoaderReturnsErrFromDecoratedLoader)
+}
+
+func testNormalizeLoaderWithDefaultNormalizers(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loader = xconf.PlainLoader(map[string]any{
+			"trim":    "  value \t\n",
+			"quoted":  ` "quoted value" `,
+			"single":  "'single quoted'",
+			"broken":  `"broken'`,
+			"nfc":     "cafe\u0301", // decomposed form
+			"int":     10,
+			"slice":   []string{" a ", `"b"`},
+			"anylist": []any{" c ", 1},
+			"nested":  map[string]any{"key": " 'nested' "},
+		})
+		subject = xconf.NormalizeLoader(loader)
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"trim":    "value",
+			"quoted":  "quoted value",
+			"single":  "single quoted",
+			"broken":  `"broken'`,
+			"nfc":     "caf\u00e9",
+			"int":     10,
+			"slice":   []string{"a", "b"},
+			"anylist": []any{"c", 1},
+			"nested":  map[string]any{"key": "nested"},
+		},
+		config,
+	)
+}
+
+func testNormalizeLoaderWithCustomNormalizers(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loader = xconf.PlainLoader(map[string]any{
+			"foo": ` " bar " `,
+		})
+		subject = xconf.NormalizeLoader(
+			loader,
+			xconf.NormalizeTrimSpace,
+			xconf.NormalizeUnquote,
+			xconf.NormalizeTrimSpace,
+		)
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"foo": "bar"}, config)
+}
+
+func testNormalizeLoaderReturnsErrFromDecoratedLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered decorated loader error")
+		loader      = xconf.LoaderFunc(func() (map[string]any, error) {
+			return nil, expectedErr
+		})
+		subject = xconf.NormalizeLoader(loader)
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertNil(t, config)
+}
+
+func ExampleNormalizeLoader() {
+	origLoader := xconf.PlainLoader(map[string]any{
+		"DB_HOST": ` "127.0.0.1" `, // stray spaces and quotes, as they may come from env.
+	})
+	loader := xconf.NormalizeLoader(origLoader)
+
+	configMap, err := loader.Load()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%q\n", configMap["DB_HOST"])
+
+	// Output:
+	// "127.0.0.1"
+}
@@ -0,0 +1,99 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/actforgood/xerr"
+)
+
+// ErrNullValue is an error returned by [NullPolicyLoader] with [NullAsError] policy,
+// if a null value is found.
+var ErrNullValue = errors.New("null value")
+
+// NullPolicy describes how null values (like JSON's null / YAML's null, ~, or empty value)
+// are treated.
+type NullPolicy int
+
+const (
+	// NullAsMissing removes keys with null values, like they were not set at all,
+	// so that Get returns the provided default value for them.
+	// Null items in slices are kept, as removing them would shift the indexes.
+	NullAsMissing NullPolicy = iota
+	// NullKeep keeps null values, as nil. Note that Get with a non-nil default
+	// value casts nil to default's type zero value (not to the default value itself).
+	NullKeep
+	// NullAsError rejects the configuration if a null value is found, see [ErrNullValue].
+	NullAsError
+)
+
+// NullPolicyLoader decorates another loader to apply a null values policy at load time.
+// Null values are searched for in nested maps and slices, too.
+//
+// Note: only formats which have a null notion produce nil values (JSON, JSON5, YAML);
+// others (env, dotenv, ini, properties, flags) produce empty strings instead, which are not nulls.
+func NullPolicyLoader(loader Loader, policy NullPolicy) Loader {
+	return decorate(loader, func(ctx context.Context, loader Loader) (map[string]any, error) {
+		configMap, err := LoadWithContext(ctx, loader)
+		if err != nil || policy == NullKeep {
+			return configMap, err
+		}
+
+		for key, value := range configMap {
+			if err := applyNullPolicy(configMap, key, value, key, policy); err != nil {
+				return nil, err
+			}
+		}
+
+		return configMap, nil
+	})
+}
+
+// applyNullPolicy applies the null policy on a key's value, in given map.
+// path is the full path of the key, for error reporting purposes.
+func applyNullPolicy(configMap map[string]any, key string, value any, path string, policy NullPolicy) error {
+	if value == nil {
+		if policy == NullAsError {
+			return xerr.Wrapf(ErrNullValue, "key %q", path)
+		}
+		delete(configMap, key)
+
+		return nil
+	}
+
+	switch val := value.(type) {
+	case map[string]any:
+		for nestedKey, nestedValue := range val {
+			if err := applyNullPolicy(val, nestedKey, nestedValue, path+"."+nestedKey, policy); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for idx, item := range val {
+			itemPath := fmt.Sprintf("%s[%d]", path, idx)
+			if item == nil {
+				if policy == NullAsError {
+					return xerr.Wrapf(ErrNullValue, "key %q", itemPath)
+				}
+
+				continue
+			}
+			if nestedMap, isMap := item.(map[string]any); isMap {
+				for nestedKey, nestedValue := range nestedMap {
+					err := applyNullPolicy(nestedMap, nestedKey, nestedValue, itemPath+"."+nestedKey, policy)
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
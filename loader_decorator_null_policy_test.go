@@ -0,0 +1,177 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestNullPolicyLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - null as missing", testNullPolicyLoaderAsMissing)
+	t.Run("success - keep null", testNullPolicyLoaderKeep)
+	t.Run("error - null as error", testNullPolicyLoaderAsError)
+	t.Run("success - formats without null", testNullPolicyLoaderFormatsWithoutNull)
+	t.Run("error - decorated loader", testNullPolicyLoaderReturnsErrFromDecoratedLoader)
+}
+
+// nullsFormatsLoaders returns loaders for formats which have a null notion.
+func nullsFormatsLoaders() map[string]xconf.Loader {
+	return map[string]xconf.Loader{
+		"json": xconf.JSONFileLoader("testdata/config_nulls.json"),
+		"yaml": xconf.YAMLFileLoader("testdata/config_nulls.yaml"),
+	}
+}
+
+func testNullPolicyLoaderAsMissing(t *testing.T) {
+	t.Parallel()
+
+	for format, loader := range nullsFormatsLoaders() {
+		format, loader := format, loader // capture range variables
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := xconf.NullPolicyLoader(loader, xconf.NullAsMissing)
+
+			// act
+			config, err := subject.Load()
+
+			// assert
+			requireNil(t, err)
+			assertEqual(
+				t,
+				map[string]any{
+					"host":     "localhost",
+					"db":       map[string]any{"user": "root"},
+					"replicas": []any{"a", nil},
+				},
+				config,
+			)
+
+			// Get with default returns the default for a null key.
+			cfg, err := xconf.NewDefaultConfig(subject)
+			requireNil(t, err)
+			assertEqual(t, 3306, cfg.Get("port", 3306))
+		})
+	}
+}
+
+func testNullPolicyLoaderKeep(t *testing.T) {
+	t.Parallel()
+
+	for format, loader := range nullsFormatsLoaders() {
+		format, loader := format, loader // capture range variables
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := xconf.NullPolicyLoader(loader, xconf.NullKeep)
+
+			// act
+			config, err := subject.Load()
+
+			// assert
+			requireNil(t, err)
+			assertEqual(
+				t,
+				map[string]any{
+					"host":     "localhost",
+					"port":     nil,
+					"db":       map[string]any{"user": "root", "password": nil},
+					"replicas": []any{"a", nil},
+				},
+				config,
+			)
+
+			// Get with default casts nil to default's type zero value.
+			cfg, err := xconf.NewDefaultConfig(subject)
+			requireNil(t, err)
+			assertEqual(t, 0, cfg.Get("port", 3306))
+		})
+	}
+}
+
+func testNullPolicyLoaderAsError(t *testing.T) {
+	t.Parallel()
+
+	for format, loader := range nullsFormatsLoaders() {
+		format, loader := format, loader // capture range variables
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := xconf.NullPolicyLoader(loader, xconf.NullAsError)
+
+			// act
+			config, err := subject.Load()
+
+			// assert
+			assertTrue(t, errors.Is(err, xconf.ErrNullValue))
+			assertNil(t, config)
+		})
+	}
+
+	t.Run("slice item", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := xconf.NullPolicyLoader(
+			xconf.PlainLoader(map[string]any{"replicas": []any{"a", nil}}),
+			xconf.NullAsError,
+		)
+
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertTrue(t, errors.Is(err, xconf.ErrNullValue))
+		assertEqual(t, `key "replicas[1]": null value`, err.Error())
+		assertNil(t, config)
+	})
+}
+
+func testNullPolicyLoaderFormatsWithoutNull(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.NullPolicyLoader(
+		xconf.DotEnvReaderLoader(strings.NewReader("PORT=\nHOST=localhost\n")),
+		xconf.NullAsError,
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	requireNil(t, err)
+	assertEqual(t, map[string]any{"PORT": "", "HOST": "localhost"}, config)
+}
+
+func testNullPolicyLoaderReturnsErrFromDecoratedLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered decorated loader error")
+		loader      = xconf.LoaderFunc(func() (map[string]any, error) {
+			return nil, expectedErr
+		})
+		subject = xconf.NullPolicyLoader(loader, xconf.NullAsMissing)
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertNil(t, config)
+}
@@ -0,0 +1,387 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/actforgood/xerr"
+)
+
+// OverlayMode is the kind of patch document an [OverlayLoader] applies.
+type OverlayMode int
+
+const (
+	// OverlayMergePatch treats the patch as a RFC 7386 JSON Merge Patch:
+	// objects are merged recursively, null values delete keys,
+	// any other values replace existing ones.
+	OverlayMergePatch OverlayMode = iota
+	// OverlayJSONPatch treats the patch as a RFC 6902 JSON Patch:
+	// a list of add / remove / replace / move / copy / test operations,
+	// found under [JSONPatchOperationsKey] key (see [JSONPatchReaderLoader]).
+	OverlayJSONPatch
+)
+
+// JSONPatchOperationsKey is the key under which [JSONPatchFileLoader] / [JSONPatchReaderLoader]
+// store the list of RFC 6902 JSON Patch operations.
+const JSONPatchOperationsKey = "jsonpatch_operations"
+
+// ErrInvalidJSONPatch is an error returned by an [OverlayLoader] in [OverlayJSONPatch] mode,
+// if the patch is malformed, or an operation cannot be applied.
+var ErrInvalidJSONPatch = errors.New("invalid json patch")
+
+// ErrJSONPatchTestFailed is an error returned by an [OverlayLoader] in [OverlayJSONPatch] mode,
+// if a "test" operation fails.
+var ErrJSONPatchTestFailed = errors.New("json patch test operation failed")
+
+// OverlayLoader applies a patch document, loaded from patch loader, on top of
+// the base loader's configuration. This way, small targeted overrides can be
+// stored separately from the full configuration document.
+//
+// In [OverlayMergePatch] mode, the patch loader can be any loader (a JSON / YAML one, for example).
+// In [OverlayJSONPatch] mode, the patch loader is expected to be a [JSONPatchFileLoader] /
+// [JSONPatchReaderLoader], and operations' paths are JSON Pointers (RFC 6901), like "/db/hosts/0".
+func OverlayLoader(base, patch Loader, mode OverlayMode) Loader {
+	return decoratedLoader{
+		load: func(ctx context.Context, loaders []Loader) (map[string]any, error) {
+			base, patch := loaders[0], loaders[1]
+			configMap, err := LoadWithContext(ctx, base)
+			if err != nil {
+				return configMap, err
+			}
+			patchMap, err := LoadWithContext(ctx, patch)
+			if err != nil {
+				return nil, err
+			}
+			if configMap == nil {
+				configMap = make(map[string]any)
+			}
+
+			if mode == OverlayJSONPatch {
+				return applyJSONPatch(configMap, patchMap[JSONPatchOperationsKey])
+			}
+
+			return applyMergePatch(configMap, patchMap).(map[string]any), nil
+		},
+		loaders: []Loader{base, patch},
+		name:    "xconf.OverlayLoader",
+	}
+}
+
+// JSONPatchFileLoader loads a RFC 6902 JSON Patch document from a file,
+// to be used with an [OverlayLoader] in [OverlayJSONPatch] mode.
+func JSONPatchFileLoader(filePath string) Loader {
+	return LoaderFunc(func() (map[string]any, error) {
+		f, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		return JSONPatchReaderLoader(f).Load()
+	})
+}
+
+// JSONPatchReaderLoader loads a RFC 6902 JSON Patch document from an [io.Reader],
+// to be used with an [OverlayLoader] in [OverlayJSONPatch] mode.
+// The list of operations is returned under [JSONPatchOperationsKey] key.
+func JSONPatchReaderLoader(reader io.Reader) Loader {
+	return LoaderFunc(func() (map[string]any, error) {
+		if seekReader, ok := reader.(io.Seeker); ok {
+			_, _ = seekReader.Seek(0, io.SeekStart) // move to the beginning in case of a re-load needed.
+		}
+		var operations []any
+		dec := json.NewDecoder(reader)
+		if err := dec.Decode(&operations); err != nil {
+			return nil, err
+		}
+
+		return map[string]any{JSONPatchOperationsKey: operations}, nil
+	})
+}
+
+// applyMergePatch applies (in place, if possible) a RFC 7386 Merge Patch on target.
+func applyMergePatch(target, patch any) any {
+	patchMap, isMap := patch.(map[string]any)
+	if !isMap {
+		return patch
+	}
+	targetMap, isMap := target.(map[string]any)
+	if !isMap {
+		targetMap = make(map[string]any, len(patchMap))
+	}
+	for key, value := range patchMap {
+		if value == nil {
+			delete(targetMap, key)
+		} else {
+			targetMap[key] = applyMergePatch(targetMap[key], value)
+		}
+	}
+
+	return targetMap
+}
+
+// applyJSONPatch applies RFC 6902 JSON Patch operations on given configuration map.
+func applyJSONPatch(configMap map[string]any, operations any) (map[string]any, error) {
+	ops, isList := operations.([]any)
+	if !isList {
+		return nil, xerr.Wrapf(ErrInvalidJSONPatch, "operations list not found")
+	}
+
+	var (
+		doc any = configMap
+		err error
+	)
+	for idx, operation := range ops {
+		op, isMap := operation.(map[string]any)
+		if !isMap {
+			return nil, xerr.Wrapf(ErrInvalidJSONPatch, "operation #%d is not an object", idx)
+		}
+		if doc, err = applyJSONPatchOperation(doc, op); err != nil {
+			return nil, xerr.Wrapf(err, "operation #%d", idx)
+		}
+	}
+	patchedConfigMap, isMap := doc.(map[string]any)
+	if !isMap {
+		return nil, xerr.Wrapf(ErrInvalidJSONPatch, "patched document is not an object")
+	}
+
+	return patchedConfigMap, nil
+}
+
+// applyJSONPatchOperation applies a JSON Patch operation on given document.
+// It returns the (possibly new) document.
+func applyJSONPatchOperation(doc any, op map[string]any) (any, error) {
+	path, err := parseJSONPointer(op["path"])
+	if err != nil {
+		return nil, err
+	}
+
+	switch op["op"] {
+	case "add":
+		value, found := op["value"]
+		if !found {
+			return nil, xerr.Wrapf(ErrInvalidJSONPatch, "missing value")
+		}
+
+		return jsonPatchAdd(doc, path, deepCopyValue(value), false)
+	case "replace":
+		value, found := op["value"]
+		if !found {
+			return nil, xerr.Wrapf(ErrInvalidJSONPatch, "missing value")
+		}
+
+		return jsonPatchAdd(doc, path, deepCopyValue(value), true)
+	case "remove":
+		doc, _, err = jsonPatchRemove(doc, path)
+
+		return doc, err
+	case "move", "copy":
+		from, err := parseJSONPointer(op["from"])
+		if err != nil {
+			return nil, err
+		}
+		value, err := jsonPatchGet(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if op["op"] == "move" {
+			if doc, _, err = jsonPatchRemove(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			value = deepCopyValue(value)
+		}
+
+		return jsonPatchAdd(doc, path, value, false)
+	case "test":
+		value, err := jsonPatchGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(value, op["value"]) {
+			return nil, ErrJSONPatchTestFailed
+		}
+
+		return doc, nil
+	}
+
+	return nil, xerr.Wrapf(ErrInvalidJSONPatch, "unknown op %v", op["op"])
+}
+
+// parseJSONPointer parses a RFC 6901 JSON Pointer into reference tokens.
+func parseJSONPointer(pointer any) ([]string, error) {
+	strPointer, isStr := pointer.(string)
+	if !isStr {
+		return nil, xerr.Wrapf(ErrInvalidJSONPatch, "missing path")
+	}
+	if strPointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(strPointer, "/") {
+		return nil, xerr.Wrapf(ErrInvalidJSONPatch, "invalid path %q", strPointer)
+	}
+	tokens := strings.Split(strPointer[1:], "/")
+	for idx, token := range tokens {
+		tokens[idx] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+// jsonPatchGet returns the value found at given path.
+func jsonPatchGet(doc any, path []string) (any, error) {
+	for _, token := range path {
+		switch container := doc.(type) {
+		case map[string]any:
+			value, found := container[token]
+			if !found {
+				return nil, xerr.Wrapf(ErrInvalidJSONPatch, "path not found %q", token)
+			}
+			doc = value
+		case []any:
+			idx, err := jsonPatchIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = container[idx]
+		default:
+			return nil, xerr.Wrapf(ErrInvalidJSONPatch, "path not found %q", token)
+		}
+	}
+
+	return doc, nil
+}
+
+// jsonPatchAdd adds (or replaces, if replace flag is set) the value at given path.
+// It returns the (possibly new) document.
+func jsonPatchAdd(doc any, path []string, value any, replace bool) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	token, isLast := path[0], len(path) == 1
+
+	switch container := doc.(type) {
+	case map[string]any:
+		child, found := container[token]
+		if isLast {
+			if replace && !found {
+				return nil, xerr.Wrapf(ErrInvalidJSONPatch, "path not found %q", token)
+			}
+			container[token] = value
+
+			return container, nil
+		}
+		if !found {
+			return nil, xerr.Wrapf(ErrInvalidJSONPatch, "path not found %q", token)
+		}
+		newChild, err := jsonPatchAdd(child, path[1:], value, replace)
+		if err != nil {
+			return nil, err
+		}
+		container[token] = newChild
+
+		return container, nil
+	case []any:
+		if isLast && !replace {
+			if token == "-" {
+				return append(container, value), nil
+			}
+			idx, err := jsonPatchIndex(token, len(container))
+			if err != nil {
+				return nil, err
+			}
+			container = append(container, nil)
+			copy(container[idx+1:], container[idx:])
+			container[idx] = value
+
+			return container, nil
+		}
+		idx, err := jsonPatchIndex(token, len(container)-1)
+		if err != nil {
+			return nil, err
+		}
+		if isLast {
+			container[idx] = value
+
+			return container, nil
+		}
+		newChild, err := jsonPatchAdd(container[idx], path[1:], value, replace)
+		if err != nil {
+			return nil, err
+		}
+		container[idx] = newChild
+
+		return container, nil
+	}
+
+	return nil, xerr.Wrapf(ErrInvalidJSONPatch, "path not found %q", token)
+}
+
+// jsonPatchRemove removes the value at given path.
+// It returns the (possibly new) document, and the removed value.
+func jsonPatchRemove(doc any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, xerr.Wrapf(ErrInvalidJSONPatch, "cannot remove the whole document")
+	}
+	token, isLast := path[0], len(path) == 1
+
+	switch container := doc.(type) {
+	case map[string]any:
+		child, found := container[token]
+		if !found {
+			return nil, nil, xerr.Wrapf(ErrInvalidJSONPatch, "path not found %q", token)
+		}
+		if isLast {
+			delete(container, token)
+
+			return container, child, nil
+		}
+		newChild, removed, err := jsonPatchRemove(child, path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		container[token] = newChild
+
+		return container, removed, nil
+	case []any:
+		idx, err := jsonPatchIndex(token, len(container)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		if isLast {
+			removed := container[idx]
+
+			return append(container[:idx], container[idx+1:]...), removed, nil
+		}
+		newChild, removed, err := jsonPatchRemove(container[idx], path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		container[idx] = newChild
+
+		return container, removed, nil
+	}
+
+	return nil, nil, xerr.Wrapf(ErrInvalidJSONPatch, "path not found %q", token)
+}
+
+// jsonPatchIndex parses an array index token, which must be in [0, maxIdx] interval.
+func jsonPatchIndex(token string, maxIdx int) (int, error) {
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 || idx > maxIdx || (len(token) > 1 && token[0] == '0') {
+		return 0, xerr.Wrapf(ErrInvalidJSONPatch, "invalid array index %q", token)
+	}
+
+	return idx, nil
+}
@@ -0,0 +1,241 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestOverlayLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - merge patch", testOverlayLoaderWithMergePatch)
+	t.Run("success - json patch", testOverlayLoaderWithJSONPatch)
+	t.Run("error - json patch", testOverlayLoaderWithJSONPatchReturnsErr)
+	t.Run("error - base loader", testOverlayLoaderReturnsErrFromBaseLoader)
+	t.Run("error - patch loader", testOverlayLoaderReturnsErrFromPatchLoader)
+}
+
+// getOverlayBaseLoader returns the base loader used in OverlayLoader tests.
+func getOverlayBaseLoader() xconf.Loader {
+	return xconf.LoaderFunc(func() (map[string]any, error) {
+		return map[string]any{
+			"db": map[string]any{
+				"host":  "10.0.0.1",
+				"port":  5432.0,
+				"hosts": []any{"a", "b", "c"},
+			},
+			"log_level": "info",
+			"debug":     false,
+		}, nil
+	})
+}
+
+func testOverlayLoaderWithMergePatch(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		patch = xconf.JSONReaderLoader(strings.NewReader(`{
+			"db": {"host": "10.0.0.2", "port": null, "pool": {"size": 20}},
+			"log_level": null,
+			"debug": true
+		}`))
+		subject = xconf.OverlayLoader(getOverlayBaseLoader(), patch, xconf.OverlayMergePatch)
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"db": map[string]any{
+				"host":  "10.0.0.2",
+				"hosts": []any{"a", "b", "c"},
+				"pool":  map[string]any{"size": 20.0},
+			},
+			"debug": true,
+		},
+		config,
+	)
+}
+
+func testOverlayLoaderWithJSONPatch(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		patch = xconf.JSONPatchReaderLoader(strings.NewReader(`[
+			{"op": "test", "path": "/db/host", "value": "10.0.0.1"},
+			{"op": "replace", "path": "/db/host", "value": "10.0.0.2"},
+			{"op": "remove", "path": "/db/hosts/1"},
+			{"op": "add", "path": "/db/hosts/-", "value": "d"},
+			{"op": "add", "path": "/db/hosts/0", "value": "z"},
+			{"op": "copy", "from": "/db/port", "path": "/db/replica_port"},
+			{"op": "move", "from": "/log_level", "path": "/log~1level"},
+			{"op": "add", "path": "/db/pool", "value": {"size": 20}}
+		]`))
+		subject = xconf.OverlayLoader(getOverlayBaseLoader(), patch, xconf.OverlayJSONPatch)
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"db": map[string]any{
+				"host":         "10.0.0.2",
+				"port":         5432.0,
+				"replica_port": 5432.0,
+				"hosts":        []any{"z", "a", "c", "d"},
+				"pool":         map[string]any{"size": 20.0},
+			},
+			"log/level": "info",
+			"debug":     false,
+		},
+		config,
+	)
+}
+
+func testOverlayLoaderWithJSONPatchReturnsErr(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name        string
+		patch       string
+		expectedErr error
+	}{
+		{
+			name:        "test operation fails",
+			patch:       `[{"op": "test", "path": "/db/host", "value": "10.0.0.9"}]`,
+			expectedErr: xconf.ErrJSONPatchTestFailed,
+		},
+		{
+			name:        "unknown operation",
+			patch:       `[{"op": "upsert", "path": "/db/host", "value": "10.0.0.9"}]`,
+			expectedErr: xconf.ErrInvalidJSONPatch,
+		},
+		{
+			name:        "replace not existing key",
+			patch:       `[{"op": "replace", "path": "/db/user", "value": "admin"}]`,
+			expectedErr: xconf.ErrInvalidJSONPatch,
+		},
+		{
+			name:        "add to not existing parent",
+			patch:       `[{"op": "add", "path": "/cache/host", "value": "10.0.0.9"}]`,
+			expectedErr: xconf.ErrInvalidJSONPatch,
+		},
+		{
+			name:        "invalid array index",
+			patch:       `[{"op": "remove", "path": "/db/hosts/3"}]`,
+			expectedErr: xconf.ErrInvalidJSONPatch,
+		},
+		{
+			name:        "invalid path",
+			patch:       `[{"op": "remove", "path": "db"}]`,
+			expectedErr: xconf.ErrInvalidJSONPatch,
+		},
+		{
+			name:        "missing value",
+			patch:       `[{"op": "add", "path": "/db/user"}]`,
+			expectedErr: xconf.ErrInvalidJSONPatch,
+		},
+		{
+			name:        "whole document replaced with non-object",
+			patch:       `[{"op": "replace", "path": "", "value": 1}]`,
+			expectedErr: xconf.ErrInvalidJSONPatch,
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var (
+				patch   = xconf.JSONPatchReaderLoader(strings.NewReader(test.patch))
+				subject = xconf.OverlayLoader(getOverlayBaseLoader(), patch, xconf.OverlayJSONPatch)
+			)
+
+			// act
+			config, err := subject.Load()
+
+			// assert
+			assertTrue(t, errors.Is(err, test.expectedErr))
+			assertNil(t, config)
+		})
+	}
+}
+
+func testOverlayLoaderReturnsErrFromBaseLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered base loader error")
+		base        = xconf.LoaderFunc(func() (map[string]any, error) {
+			return nil, expectedErr
+		})
+		patch   = xconf.PlainLoader(map[string]any{"foo": "bar"})
+		subject = xconf.OverlayLoader(base, patch, xconf.OverlayMergePatch)
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertNil(t, config)
+}
+
+func testOverlayLoaderReturnsErrFromPatchLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered patch loader error")
+		patch       = xconf.LoaderFunc(func() (map[string]any, error) {
+			return nil, expectedErr
+		})
+		subject = xconf.OverlayLoader(getOverlayBaseLoader(), patch, xconf.OverlayJSONPatch)
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertNil(t, config)
+}
+
+func ExampleOverlayLoader() {
+	base := xconf.PlainLoader(map[string]any{
+		"db": map[string]any{"host": "10.0.0.1", "port": 5432},
+	})
+	patch := xconf.JSONReaderLoader(strings.NewReader(`{"db": {"host": "10.0.0.2"}}`))
+	loader := xconf.OverlayLoader(base, patch, xconf.OverlayMergePatch)
+
+	configMap, err := loader.Load()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(configMap["db"])
+
+	// Output:
+	// map[host:10.0.0.2 port:5432]
+}
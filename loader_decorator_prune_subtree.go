@@ -0,0 +1,70 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"strings"
+)
+
+// PruneSubtreeLoader decorates another loader to remove given subtree from its configuration map,
+// regardless of keys having been flattened or not (see [FlattenLoader]):
+//   - subtree's root key and the flat keys derived from it are removed,
+//   - a deeper subtree (like "app.internal") is also removed from its parent's
+//     nested configuration ("app"), while parent's key itself is kept.
+//
+// Separator of nested keys is "." by default, another one can be passed as the third parameter.
+//
+// Example:
+//
+//	loader := xconf.PruneSubtreeLoader(xconf.YAMLFileLoader("config.yaml"), "app.internal")
+func PruneSubtreeLoader(loader Loader, subtree string, separator ...string) Loader {
+	sep := "."
+	if len(separator) > 0 {
+		sep = separator[0]
+	}
+	subtreePrefix := subtree + sep
+
+	return decorate(loader, func(ctx context.Context, loader Loader) (map[string]any, error) {
+		configMap, err := LoadWithContext(ctx, loader)
+		if err != nil {
+			return configMap, err
+		}
+
+		for key, value := range configMap {
+			if key == subtree || strings.HasPrefix(key, subtreePrefix) {
+				delete(configMap, key)
+
+				continue
+			}
+			if parentPrefix := key + sep; strings.HasPrefix(subtree, parentPrefix) {
+				removeNestedSubtree(value, strings.Split(strings.TrimPrefix(subtree, parentPrefix), sep))
+			}
+		}
+
+		return configMap, nil
+	})
+}
+
+// removeNestedSubtree removes the subtree found at given path from a nested configuration.
+func removeNestedSubtree(value any, path []string) {
+	switch nested := value.(type) {
+	case map[string]any:
+		if len(path) == 1 {
+			delete(nested, path[0])
+
+			return
+		}
+		removeNestedSubtree(nested[path[0]], path[1:])
+	case map[any]any:
+		if len(path) == 1 {
+			delete(nested, path[0])
+
+			return
+		}
+		removeNestedSubtree(nested[path[0]], path[1:])
+	}
+}
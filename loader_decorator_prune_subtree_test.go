@@ -0,0 +1,156 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestPruneSubtreeLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - flat and nested subtree is removed", testPruneSubtreeLoaderRemovesSubtree)
+	t.Run("success - custom separator", testPruneSubtreeLoaderWithCustomSeparator)
+	t.Run("success - nested path does not exist", testPruneSubtreeLoaderWithMissingNestedPath)
+	t.Run("error - original, decorated loader", testPruneSubtreeLoaderReturnsErrFromDecoratedLoader)
+}
+
+func testPruneSubtreeLoaderRemovesSubtree(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loader = xconf.NewFlattenLoader(xconf.PlainLoader(map[string]any{
+			"app": map[string]any{
+				"name": "demo",
+				"internal": map[any]any{
+					"secrets": map[string]any{"token": "abc"},
+					"debug":   true,
+				},
+			},
+			"app.internals": "kept",
+		}))
+		subject = xconf.PruneSubtreeLoader(loader, "app.internal.secrets")
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"app": map[string]any{
+				"name":     "demo",
+				"internal": map[any]any{"debug": true},
+			},
+			"app.name":           "demo",
+			"app.internal.debug": true,
+			"app.internals":      "kept",
+		},
+		config,
+	)
+}
+
+func testPruneSubtreeLoaderWithCustomSeparator(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loader = xconf.PlainLoader(map[string]any{
+			"app": map[string]any{
+				"name":     "demo",
+				"internal": map[string]any{"debug": true},
+			},
+			"app/internal/debug": true,
+		})
+		subject = xconf.PruneSubtreeLoader(loader, "app/internal", "/")
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{"app": map[string]any{"name": "demo"}},
+		config,
+	)
+}
+
+func testPruneSubtreeLoaderWithMissingNestedPath(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loader = xconf.PlainLoader(map[string]any{
+			"app":  "not a map",
+			"APP":  map[string]any{"name": "demo"},
+			"apps": map[string]any{"internal": "kept"},
+		})
+		subject = xconf.PruneSubtreeLoader(loader, "app.internal.secrets")
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"app":  "not a map",
+			"APP":  map[string]any{"name": "demo"},
+			"apps": map[string]any{"internal": "kept"},
+		},
+		config,
+	)
+}
+
+func testPruneSubtreeLoaderReturnsErrFromDecoratedLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered loader error")
+		loader      = xconf.LoaderFunc(func() (map[string]any, error) {
+			return nil, expectedErr
+		})
+		subject = xconf.PruneSubtreeLoader(loader, "internal")
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertNil(t, config)
+}
+
+func ExamplePruneSubtreeLoader() {
+	origLoader := xconf.NewFlattenLoader(xconf.PlainLoader(map[string]any{
+		"app": map[string]any{
+			"name":     "demo",
+			"internal": map[string]any{"token": "abc"},
+		},
+	}))
+	loader := xconf.PruneSubtreeLoader(origLoader, "app.internal")
+
+	configMap, _ := loader.Load()
+	for key, value := range configMap {
+		fmt.Println(key+":", value)
+	}
+
+	// Unordered output:
+	// app: map[name:demo]
+	// app.name: demo
+}
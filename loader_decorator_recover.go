@@ -0,0 +1,40 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"errors"
+
+	"github.com/actforgood/xerr"
+)
+
+// ErrLoaderPanicked is the error (wrapped along with panic's value and stack trace)
+// returned by [RecoverLoader] and [MultiLoader] if a loader panics.
+var ErrLoaderPanicked = errors.New("loader panicked")
+
+// RecoverLoader decorates another loader to convert a panic occurred inside it
+// into an error, so a buggy (third-party / custom) loader can't crash, for example,
+// the reload goroutine of a [DefaultConfig].
+// The returned error wraps [ErrLoaderPanicked], the panic's value, and the stack trace
+// (print it with "%+v" verb).
+func RecoverLoader(loader Loader) Loader {
+	return decorate(loader, func(ctx context.Context, loader Loader) (map[string]any, error) {
+		return safeLoad(ctx, loader)
+	})
+}
+
+// safeLoad calls loader's Load (under given context), recovering from a panic.
+func safeLoad(ctx context.Context, loader Loader) (configMap map[string]any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			configMap = nil
+			err = xerr.Wrapf(ErrLoaderPanicked, "%v", r)
+		}
+	}()
+
+	return LoadWithContext(ctx, loader)
+}
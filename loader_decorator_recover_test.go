@@ -0,0 +1,79 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestRecoverLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - no panic", testRecoverLoaderWithoutPanic)
+	t.Run("error - panic is converted to error", testRecoverLoaderWithPanic)
+	t.Run("error - original, decorated loader", testRecoverLoaderReturnsErrFromDecoratedLoader)
+}
+
+func testRecoverLoaderWithoutPanic(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.RecoverLoader(xconf.PlainLoader(map[string]any{"foo": "bar"}))
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"foo": "bar"}, config)
+}
+
+func testRecoverLoaderWithPanic(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	loader := xconf.LoaderFunc(func() (map[string]any, error) {
+		var configMap map[string]any
+		configMap["foo"] = "bar" // assignment to entry in nil map
+
+		return configMap, nil
+	})
+	subject := xconf.RecoverLoader(loader)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, errors.Is(err, xconf.ErrLoaderPanicked))
+	assertTrue(t, strings.Contains(err.Error(), "assignment to entry in nil map"))
+	assertTrue(t, strings.Contains(fmt.Sprintf("%+v", err), "testRecoverLoaderWithPanic"))
+}
+
+func testRecoverLoaderReturnsErrFromDecoratedLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered decorated loader error")
+		loader      = xconf.LoaderFunc(func() (map[string]any, error) {
+			return nil, expectedErr
+		})
+		subject = xconf.RecoverLoader(loader)
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertNil(t, config)
+}
@@ -0,0 +1,95 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/actforgood/xerr"
+)
+
+// RenameKeyLoader decorates another loader to rename keys, based on given mapping (old key => new key).
+// Unlike [AliasLoader], old keys are not kept. Keys not found in mapping are kept as they are.
+// If two keys end up with the same name, a [KeyConflictError] is returned, so that
+// keys produced by different loaders can be renamed before a [MultiLoader] merges them, safely.
+//
+// Example:
+//
+//	xconf.RenameKeyLoader(xconf.JSONFileLoader("legacy.json"), map[string]string{"db_host": "db.host"})
+//
+// Only first level keys are renamed, nested maps are kept as they are.
+func RenameKeyLoader(loader Loader, mapping map[string]string) Loader {
+	return renameKeysLoader(loader, func(key string) string {
+		if newKey, found := mapping[key]; found {
+			return newKey
+		}
+
+		return key
+	})
+}
+
+// RenameKeyRegexpLoader decorates another loader to rename keys matching given regular expression,
+// with the replacement (which can reference pattern's capturing groups, see [regexp.Regexp.ReplaceAllString]).
+// Keys not matching the pattern are kept as they are.
+// If two keys end up with the same name, a [KeyConflictError] is returned.
+//
+// Example:
+//
+//	// "APP_DB_HOST" => "db_host"
+//	xconf.RenameKeyRegexpLoader(xconf.EnvLoader(), regexp.MustCompile(`^APP_(DB_\w+)$`), "${1}")
+//
+// Only first level keys are renamed, nested maps are kept as they are.
+func RenameKeyRegexpLoader(loader Loader, pattern *regexp.Regexp, replacement string) Loader {
+	return renameKeysLoader(loader, func(key string) string {
+		return pattern.ReplaceAllString(key, replacement)
+	})
+}
+
+// StripPrefixLoader decorates another loader to strip given prefix from the keys having it
+// (see [NamespaceLoader] for the opposite). Keys not having the prefix are kept as they are.
+// If two keys end up with the same name, a [KeyConflictError] is returned.
+//
+// Example:
+//
+//	xconf.StripPrefixLoader(xconf.JSONFileLoader("config.json"), "legacy.") // "legacy.host" => "host"
+//
+// Only first level keys are renamed, nested maps are kept as they are.
+func StripPrefixLoader(loader Loader, prefix string) Loader {
+	return renameKeysLoader(loader, func(key string) string {
+		return strings.TrimPrefix(key, prefix)
+	})
+}
+
+// renameKeysLoader decorates another loader to rename its keys with given function.
+func renameKeysLoader(loader Loader, rename func(key string) string) Loader {
+	return decorate(loader, func(ctx context.Context, loader Loader) (map[string]any, error) {
+		configMap, err := LoadWithContext(ctx, loader)
+		if err != nil {
+			return configMap, err
+		}
+
+		var (
+			renamedConfigMap = make(map[string]any, len(configMap))
+			mErr             *xerr.MultiError
+		)
+		for key, value := range configMap {
+			newKey := rename(key)
+			if _, found := renamedConfigMap[newKey]; found {
+				mErr = mErr.Add(NewKeyConflictError(newKey))
+
+				continue
+			}
+			renamedConfigMap[newKey] = value
+		}
+		if err := mErr.ErrOrNil(); err != nil {
+			return nil, err
+		}
+
+		return renamedConfigMap, nil
+	})
+}
@@ -0,0 +1,190 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestRenameKeyLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - mapping", testRenameKeyLoaderWithMapping)
+	t.Run("success - regexp", testRenameKeyRegexpLoader)
+	t.Run("success - strip prefix", testStripPrefixLoader)
+	t.Run("success - namespaced loaders merged", testRenameKeyLoadersMergedByMultiLoader)
+	t.Run("error - key conflict", testRenameKeyLoaderReturnsErrKeyConflict)
+	t.Run("error - original, decorated loader", testRenameKeyLoaderReturnsErrFromDecoratedLoader)
+}
+
+func testRenameKeyLoaderWithMapping(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loader = xconf.PlainLoader(map[string]any{
+			"db_host": "127.0.0.1",
+			"db_port": 3306,
+			"db":      map[string]any{"name": "demo"},
+		})
+		subject = xconf.RenameKeyLoader(loader, map[string]string{
+			"db_host": "mysql.host",
+			"db_port": "mysql.port",
+			"missing": "not.added",
+		})
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"mysql.host": "127.0.0.1",
+			"mysql.port": 3306,
+			"db":         map[string]any{"name": "demo"},
+		},
+		config,
+	)
+}
+
+func testRenameKeyRegexpLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loader = xconf.PlainLoader(map[string]any{
+			"APP_DB_HOST": "127.0.0.1",
+			"APP_DB_PORT": 3306,
+			"HOME":        "/home/demo",
+		})
+		subject = xconf.RenameKeyRegexpLoader(loader, regexp.MustCompile(`^APP_DB_(\w+)$`), "db.${1}")
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"db.HOST": "127.0.0.1",
+			"db.PORT": 3306,
+			"HOME":    "/home/demo",
+		},
+		config,
+	)
+}
+
+func testStripPrefixLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loader = xconf.PlainLoader(map[string]any{
+			"redis.host": "127.0.0.1",
+			"redis.port": 6379,
+			"timeout":    5,
+		})
+		subject = xconf.StripPrefixLoader(loader, "redis.")
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"host":    "127.0.0.1",
+			"port":    6379,
+			"timeout": 5,
+		},
+		config,
+	)
+}
+
+func testRenameKeyLoadersMergedByMultiLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		primary = xconf.PlainLoader(map[string]any{"host": "10.0.0.1", "port": 5432})
+		replica = xconf.PlainLoader(map[string]any{"host": "10.0.0.2", "port": 5432})
+		subject = xconf.NewMultiLoader(
+			false,
+			xconf.NamespaceLoader(primary, "primary"),
+			xconf.RenameKeyLoader(replica, map[string]string{"host": "replica.host", "port": "replica.port"}),
+		)
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"primary.host": "10.0.0.1",
+			"primary.port": 5432,
+			"replica.host": "10.0.0.2",
+			"replica.port": 5432,
+		},
+		config,
+	)
+}
+
+func testRenameKeyLoaderReturnsErrKeyConflict(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loader = xconf.PlainLoader(map[string]any{
+			"redis.host": "127.0.0.1",
+			"host":       "localhost",
+		})
+		subject = xconf.StripPrefixLoader(loader, "redis.")
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	var conflictErr xconf.KeyConflictError
+	if assertTrue(t, errors.As(err, &conflictErr)) {
+		assertEqual(t, xconf.NewKeyConflictError("host"), conflictErr)
+	}
+	assertNil(t, config)
+}
+
+func testRenameKeyLoaderReturnsErrFromDecoratedLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered loader error")
+		loader      = xconf.LoaderFunc(func() (map[string]any, error) {
+			return nil, expectedErr
+		})
+		subject = xconf.RenameKeyLoader(loader, map[string]string{"foo": "bar"})
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertNil(t, config)
+}
@@ -0,0 +1,329 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/actforgood/xerr"
+	"github.com/spf13/cast"
+)
+
+// TemplateLoader decorates another loader to render string values as Go templates ([text/template]),
+// like "amqp://{{ .rabbit_user }}:{{ .rabbit_pass }}@{{ .rabbit_host }}/".
+// String values of nested maps / slices are rendered, too.
+//
+// Template's data is the configuration map itself, keys referenced by a template being rendered first
+// (in case they are templates themselves). Keys which are not valid Go identifiers (like "db.host")
+// can be referenced with the "key" function: {{ key "db.host" }}.
+// If keys reference each other in a cycle, an [ErrPlaceholderCycle] based error is returned.
+//
+// Besides template's builtin functions, following functions are available:
+//   - key - returns a key's (rendered) value: {{ key "db.host" }};
+//   - env - returns an OS's ENV value: {{ env "HOSTNAME" }};
+//   - default - returns the value, or the default one if value is empty: {{ .db_port | default 3306 }};
+//   - upper, lower, trim - change a string: {{ upper .region }};
+//   - join - joins a list's items: {{ join "," .brokers }}.
+//
+// Other functions can be added with [TemplateLoaderWithFuncs].
+// Rendered values are strings.
+type TemplateLoader struct {
+	// original, decorated loader.
+	loader Loader
+	// template's left delimiter.
+	leftDelim string
+	// template's right delimiter.
+	rightDelim string
+	// custom functions.
+	funcs template.FuncMap
+	// flag that indicates whether a missing key is an error.
+	strict bool
+}
+
+// NewTemplateLoader instantiates a new TemplateLoader object that renders
+// string values as Go templates.
+func NewTemplateLoader(loader Loader, opts ...TemplateLoaderOption) TemplateLoader {
+	templateLoader := TemplateLoader{
+		loader:     loader,
+		leftDelim:  "{{",
+		rightDelim: "}}",
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&templateLoader)
+	}
+
+	return templateLoader
+}
+
+// Load returns a configuration key-value map from original loader, with templates rendered.
+func (decorator TemplateLoader) Load() (map[string]any, error) {
+	return decorator.LoadContext(context.Background())
+}
+
+// LoadContext is like Load, passing given context to the decorated loader.
+// It implements [ContextLoader].
+func (decorator TemplateLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	configMap, err := LoadWithContext(ctx, decorator.loader)
+	if err != nil {
+		return configMap, err
+	}
+
+	rnd := &rendering{
+		decorator: decorator,
+		configMap: configMap,
+		data:      make(map[string]any, len(configMap)),
+		resolved:  make(map[string]bool, len(configMap)),
+		resolving: make(map[string]bool),
+	}
+	for key, value := range configMap {
+		rnd.data[key] = value
+	}
+	for key := range configMap {
+		if _, err := rnd.resolveKey(key); err != nil {
+			return nil, err
+		}
+	}
+
+	return rnd.data, nil
+}
+
+// Close closes the decorated loader, if it implements [io.Closer].
+func (decorator TemplateLoader) Close() error {
+	return CloseLoaders(decorator.loader)
+}
+
+// Unwrap returns the decorated loader.
+func (decorator TemplateLoader) Unwrap() []Loader {
+	return []Loader{decorator.loader}
+}
+
+// mapLoaders returns a copy of the decorator, decorating the loader returned by fn.
+func (decorator TemplateLoader) mapLoaders(fn func(Loader) Loader) Loader {
+	decorator.loader = fn(decorator.loader)
+
+	return decorator
+}
+
+// rendering holds the state of rendering a configuration map's templates.
+type rendering struct {
+	decorator TemplateLoader
+	configMap map[string]any  // the original configuration map.
+	data      map[string]any  // template's data, holding rendered values for resolved keys.
+	resolved  map[string]bool // already rendered keys.
+	resolving map[string]bool // keys being rendered, for cycle detection.
+}
+
+// resolveKey returns given key's rendered value.
+func (rnd *rendering) resolveKey(key string) (any, error) {
+	if rnd.resolved[key] {
+		return rnd.data[key], nil
+	}
+	if rnd.resolving[key] {
+		return nil, xerr.Wrapf(ErrPlaceholderCycle, "key %q", key)
+	}
+	rnd.resolving[key] = true
+	value, err := rnd.renderValue(rnd.configMap[key], key)
+	delete(rnd.resolving, key)
+	if err != nil {
+		return nil, err
+	}
+	rnd.data[key] = value
+	rnd.resolved[key] = true
+
+	return value, nil
+}
+
+// renderValue renders templates in a value, traversing nested maps / slices.
+// path is the full path of the value, for error reporting purposes.
+func (rnd *rendering) renderValue(value any, path string) (any, error) {
+	switch val := value.(type) {
+	case string:
+		return rnd.renderString(val, path)
+	case map[string]any:
+		rendered := make(map[string]any, len(val))
+		for nestedKey, nestedValue := range val {
+			renderedValue, err := rnd.renderValue(nestedValue, path+"."+nestedKey)
+			if err != nil {
+				return nil, err
+			}
+			rendered[nestedKey] = renderedValue
+		}
+
+		return rendered, nil
+	case []any:
+		rendered := make([]any, len(val))
+		for idx, item := range val {
+			renderedItem, err := rnd.renderValue(item, fmt.Sprintf("%s[%d]", path, idx))
+			if err != nil {
+				return nil, err
+			}
+			rendered[idx] = renderedItem
+		}
+
+		return rendered, nil
+	}
+
+	return value, nil
+}
+
+// renderString renders a string value, if it is a template.
+func (rnd *rendering) renderString(value, path string) (any, error) {
+	if !strings.Contains(value, rnd.decorator.leftDelim) {
+		return value, nil
+	}
+
+	tmpl := template.New(path).
+		Delims(rnd.decorator.leftDelim, rnd.decorator.rightDelim).
+		Funcs(rnd.funcs()).
+		Funcs(rnd.decorator.funcs)
+	if rnd.decorator.strict {
+		tmpl = tmpl.Option("missingkey=error")
+	}
+	tmpl, err := tmpl.Parse(value)
+	if err != nil {
+		return nil, xerr.Wrapf(err, "key %q", path)
+	}
+
+	// render first the keys referenced by the template.
+	for _, referencedKey := range templateFields(tmpl.Tree.Root, nil, true) {
+		if _, found := rnd.configMap[referencedKey]; found {
+			if _, err := rnd.resolveKey(referencedKey); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, rnd.data); err != nil {
+		return nil, xerr.Wrapf(err, "key %q", path)
+	}
+
+	return sb.String(), nil
+}
+
+// funcs returns the builtin functions available to templates.
+func (rnd *rendering) funcs() template.FuncMap {
+	return template.FuncMap{
+		"key": func(key string) (any, error) {
+			if _, found := rnd.configMap[key]; !found {
+				if rnd.decorator.strict {
+					return nil, xerr.Wrapf(ErrUnresolvedPlaceholder, "key %q", key)
+				}
+
+				return "", nil
+			}
+
+			return rnd.resolveKey(key)
+		},
+		"env": os.Getenv,
+		"default": func(defaultValue, value any) any {
+			if value == nil || cast.ToString(value) == "" {
+				return defaultValue
+			}
+
+			return value
+		},
+		"upper": func(value any) string { return strings.ToUpper(cast.ToString(value)) },
+		"lower": func(value any) string { return strings.ToLower(cast.ToString(value)) },
+		"trim":  func(value any) string { return strings.TrimSpace(cast.ToString(value)) },
+		"join": func(sep string, value any) string {
+			return strings.Join(cast.ToStringSlice(value), sep)
+		},
+	}
+}
+
+// templateFields appends to keys the (first level) data fields referenced
+// in a template's node, like "db" for {{ .db.host }} or {{ $.db }}.
+// Fields are collected only where dot is template's data (not inside range / with blocks).
+func templateFields(node parse.Node, keys []string, isRootDot bool) []string {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return keys
+		}
+		for _, nestedNode := range n.Nodes {
+			keys = templateFields(nestedNode, keys, isRootDot)
+		}
+	case *parse.ActionNode:
+		keys = templateFields(n.Pipe, keys, isRootDot)
+	case *parse.PipeNode:
+		if n == nil {
+			return keys
+		}
+		for _, cmd := range n.Cmds {
+			keys = templateFields(cmd, keys, isRootDot)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			keys = templateFields(arg, keys, isRootDot)
+		}
+	case *parse.ChainNode:
+		keys = templateFields(n.Node, keys, isRootDot)
+	case *parse.FieldNode:
+		if isRootDot {
+			keys = append(keys, n.Ident[0])
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			keys = append(keys, n.Ident[1])
+		}
+	case *parse.IfNode:
+		keys = templateFields(n.Pipe, keys, isRootDot)
+		keys = templateFields(n.List, keys, isRootDot)
+		keys = templateFields(n.ElseList, keys, isRootDot)
+	case *parse.RangeNode:
+		keys = templateFields(n.Pipe, keys, isRootDot)
+		keys = templateFields(n.List, keys, false) // dot is the current item.
+		keys = templateFields(n.ElseList, keys, isRootDot)
+	case *parse.WithNode:
+		keys = templateFields(n.Pipe, keys, isRootDot)
+		keys = templateFields(n.List, keys, false) // dot is pipeline's value.
+		keys = templateFields(n.ElseList, keys, isRootDot)
+	case *parse.TemplateNode:
+		keys = templateFields(n.Pipe, keys, isRootDot)
+	}
+
+	return keys
+}
+
+// TemplateLoaderOption defines optional function for configuring
+// a Template Loader.
+type TemplateLoaderOption func(*TemplateLoader)
+
+// TemplateLoaderWithDelimiters sets the template's delimiters, like "[[" and "]]".
+// By default, "{{" and "}}" are used.
+func TemplateLoaderWithDelimiters(left, right string) TemplateLoaderOption {
+	return func(loader *TemplateLoader) {
+		if left != "" && right != "" {
+			loader.leftDelim = left
+			loader.rightDelim = right
+		}
+	}
+}
+
+// TemplateLoaderWithFuncs adds functions available to templates
+// (builtin ones with same name are overwritten).
+func TemplateLoaderWithFuncs(funcs template.FuncMap) TemplateLoaderOption {
+	return func(loader *TemplateLoader) {
+		loader.funcs = funcs
+	}
+}
+
+// TemplateLoaderWithStrict makes the loader return an error if a template
+// references a missing key. By default, a missing key renders as "<no value>"
+// (or as empty string, for "key" function).
+func TemplateLoaderWithStrict() TemplateLoaderOption {
+	return func(loader *TemplateLoader) {
+		loader.strict = true
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/actforgood/xerr"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrTranslationFailed is an error returned by [TranslationLoader] if a key
+// cannot be translated (its value cannot be converted to the requested type,
+// the type is unknown, or a target key's parent is not a map).
+var ErrTranslationFailed = errors.New("key translation failed")
+
+// translationTypes holds the supported translation types, and a value of each type,
+// used for casting.
+var translationTypes = map[string]any{
+	"string":   "",
+	"int":      0,
+	"int64":    int64(0),
+	"uint":     uint(0),
+	"float64":  float64(0),
+	"bool":     false,
+	"duration": time.Duration(0),
+	"time":     time.Time{},
+	"[]string": []string{},
+	"[]int":    []int{},
+}
+
+// TranslationRule describes how a key is translated into a standard schema.
+type TranslationRule struct {
+	// From is the source key.
+	From string `json:"from" yaml:"from"`
+	// To is the target key. Leave it empty to keep the source key (to only convert its value).
+	To string `json:"to,omitempty" yaml:"to,omitempty"`
+	// Type is the type the value is converted to, if not empty.
+	// One of: string, int, int64, uint, float64, bool, duration, time, []string, []int.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+}
+
+// TranslationTable is a list of translation rules which rename / nest / retype keys,
+// normalizing a configuration into a standard schema.
+type TranslationTable struct {
+	// NestDelimiter, if not empty, makes target keys containing it be nested into maps
+	// (like "db.host" becoming the "host" key of the map under "db" key).
+	NestDelimiter string `json:"nestDelimiter,omitempty" yaml:"nestDelimiter,omitempty"`
+	// Rules are the translation rules, applied in order.
+	Rules []TranslationRule `json:"rules" yaml:"rules"`
+}
+
+// ReadTranslationTable reads a translation table (mapping document) in YAML / JSON format.
+//
+// Example of document:
+//
+//	nestDelimiter: "."
+//	rules:
+//	  - from: DB_HOST
+//	    to: db.host
+//	  - from: DB_PORT
+//	    to: db.port
+//	    type: int
+//	  - from: REQUEST_TIMEOUT
+//	    to: http.timeout
+//	    type: duration
+func ReadTranslationTable(reader io.Reader) (TranslationTable, error) {
+	var table TranslationTable
+	err := yaml.NewDecoder(reader).Decode(&table)
+
+	return table, err
+}
+
+// ReadTranslationTableFile reads a translation table from a YAML / JSON file.
+// See [ReadTranslationTable].
+func ReadTranslationTableFile(filePath string) (TranslationTable, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return TranslationTable{}, err
+	}
+	defer f.Close()
+
+	return ReadTranslationTable(f)
+}
+
+// TranslationLoader decorates another loader to translate its keys according
+// to given translation tables, applied in order (so that a platform team can ship
+// one canonical table, and a service can compose it with its own, for example).
+// A rule whose source key is not found is skipped.
+//
+// Example:
+//
+//	table, err := xconf.ReadTranslationTableFile("/etc/platform/translation.yaml")
+//	if err != nil {
+//		panic(err)
+//	}
+//	loader := xconf.TranslationLoader(xconf.EnvLoader(), table)
+func TranslationLoader(loader Loader, tables ...TranslationTable) Loader {
+	return decorate(loader, func(ctx context.Context, loader Loader) (map[string]any, error) {
+		configMap, err := LoadWithContext(ctx, loader)
+		if err != nil {
+			return configMap, err
+		}
+
+		for _, table := range tables {
+			if err := table.apply(configMap); err != nil {
+				return nil, err
+			}
+		}
+
+		return configMap, nil
+	})
+}
+
+// apply translates configuration map's keys according to table's rules.
+func (table TranslationTable) apply(configMap map[string]any) error {
+	for _, rule := range table.Rules {
+		value, found := configMap[rule.From]
+		if !found {
+			continue
+		}
+		if rule.Type != "" {
+			typeValue, known := translationTypes[rule.Type]
+			if !known {
+				return xerr.Wrapf(ErrTranslationFailed, "key %q has unknown type %q", rule.From, rule.Type)
+			}
+			castValue, err := castValueByDefault(value, typeValue)
+			if err != nil {
+				return xerr.Wrapf(ErrTranslationFailed, "key %q cannot be converted to %s", rule.From, rule.Type)
+			}
+			value = castValue
+		}
+		if rule.To == "" || rule.To == rule.From {
+			configMap[rule.From] = value
+
+			continue
+		}
+
+		delete(configMap, rule.From)
+		if err := table.set(configMap, rule.To, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// set sets the value for given target key, nesting it, if needed.
+func (table TranslationTable) set(configMap map[string]any, key string, value any) error {
+	if table.NestDelimiter == "" || !strings.Contains(key, table.NestDelimiter) {
+		configMap[key] = value
+
+		return nil
+	}
+
+	segments := strings.Split(key, table.NestDelimiter)
+	currentMap := configMap
+	for _, segment := range segments[:len(segments)-1] {
+		nestedValue, found := currentMap[segment]
+		if !found {
+			nestedMap := make(map[string]any)
+			currentMap[segment] = nestedMap
+			currentMap = nestedMap
+
+			continue
+		}
+		nestedMap, isMap := nestedValue.(map[string]any)
+		if !isMap {
+			return xerr.Wrapf(ErrTranslationFailed, "key %q cannot be nested under %q", key, segment)
+		}
+		currentMap = nestedMap
+	}
+	currentMap[segments[len(segments)-1]] = value
+
+	return nil
+}
@@ -0,0 +1,189 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestTranslationLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - keys are renamed, nested, retyped", testTranslationLoaderSuccess)
+	t.Run("success - tables are composed", testTranslationLoaderComposesTables)
+	t.Run("error - unknown type", testTranslationLoaderReturnsErrForUnknownType)
+	t.Run("error - value cannot be converted", testTranslationLoaderReturnsErrForCastFailure)
+	t.Run("error - target key cannot be nested", testTranslationLoaderReturnsErrForNestingConflict)
+	t.Run("error - original, decorated loader", testTranslationLoaderReturnsErrFromDecoratedLoader)
+	t.Run("error - translation table file not found", testReadTranslationTableFileReturnsErr)
+}
+
+func testTranslationLoaderSuccess(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	table, err := xconf.ReadTranslationTableFile("testdata/translation.yaml")
+	requireNil(t, err)
+	subject := xconf.TranslationLoader(
+		xconf.PlainLoader(map[string]any{
+			"DB_HOST":         "127.0.0.1",
+			"DB_PORT":         "3306",
+			"REQUEST_TIMEOUT": "5s",
+			"DEBUG":           "true",
+			"APP_NAME":        "demo",
+		}),
+		table,
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"db":       map[string]any{"host": "127.0.0.1", "port": 3306},
+			"http":     map[string]any{"timeout": 5 * time.Second},
+			"DEBUG":    true,
+			"APP_NAME": "demo",
+		},
+		config,
+	)
+}
+
+func testTranslationLoaderComposesTables(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	platformTable, err := xconf.ReadTranslationTable(strings.NewReader(`{
+		"rules": [
+			{"from": "DB_HOST", "to": "db_host"},
+			{"from": "DB_PORT", "to": "db_port", "type": "uint"}
+		]
+	}`))
+	requireNil(t, err)
+	serviceTable := xconf.TranslationTable{
+		NestDelimiter: "_",
+		Rules: []xconf.TranslationRule{
+			{From: "db_host", To: "database_host"},
+			{From: "LEGACY_DB_HOST", To: "database_host"},
+		},
+	}
+	subject := xconf.TranslationLoader(
+		xconf.PlainLoader(map[string]any{
+			"DB_HOST": "127.0.0.1",
+			"DB_PORT": 3306,
+		}),
+		platformTable,
+		serviceTable,
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"database": map[string]any{"host": "127.0.0.1"},
+			"db_port":  uint(3306),
+		},
+		config,
+	)
+}
+
+func testTranslationLoaderReturnsErrForUnknownType(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.TranslationLoader(
+		xconf.PlainLoader(map[string]any{"foo": "bar"}),
+		xconf.TranslationTable{Rules: []xconf.TranslationRule{{From: "foo", Type: "complex128"}}},
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, errors.Is(err, xconf.ErrTranslationFailed))
+}
+
+func testTranslationLoaderReturnsErrForCastFailure(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.TranslationLoader(
+		xconf.PlainLoader(map[string]any{"port": "not a number"}),
+		xconf.TranslationTable{Rules: []xconf.TranslationRule{{From: "port", Type: "int"}}},
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, errors.Is(err, xconf.ErrTranslationFailed))
+}
+
+func testTranslationLoaderReturnsErrForNestingConflict(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.TranslationLoader(
+		xconf.PlainLoader(map[string]any{"db": "a string", "DB_HOST": "127.0.0.1"}),
+		xconf.TranslationTable{
+			NestDelimiter: ".",
+			Rules:         []xconf.TranslationRule{{From: "DB_HOST", To: "db.host"}},
+		},
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, errors.Is(err, xconf.ErrTranslationFailed))
+}
+
+func testTranslationLoaderReturnsErrFromDecoratedLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered decorated loader error")
+		loader      = xconf.LoaderFunc(func() (map[string]any, error) {
+			return nil, expectedErr
+		})
+		subject = xconf.TranslationLoader(loader, xconf.TranslationTable{})
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, errors.Is(err, expectedErr))
+}
+
+func testReadTranslationTableFileReturnsErr(t *testing.T) {
+	t.Parallel()
+
+	// act
+	table, err := xconf.ReadTranslationTableFile("testdata/not_found.yaml")
+
+	// assert
+	assertTrue(t, errors.Is(err, fs.ErrNotExist))
+	assertEqual(t, xconf.TranslationTable{}, table)
+}
@@ -0,0 +1,150 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"github.com/actforgood/xerr"
+	"github.com/spf13/cast"
+)
+
+// ErrValidation is the error all built-in validation rules' errors wrap.
+var ErrValidation = errors.New("configuration validation failed")
+
+// ValidationRule validates a configuration map, returning an error if it is invalid.
+// You can write your own rules, besides the built-in ones
+// ([RequiredKeys], [KeyType], [KeyPattern], [KeyRange]).
+type ValidationRule func(configMap map[string]any) error
+
+// ValidateLoader decorates another loader to validate its configuration map against given rules.
+// All rules are checked, their errors being returned as a multi error, so that all violations
+// are reported at once.
+//
+// Example:
+//
+//	loader := xconf.ValidateLoader(
+//		xconf.NewMultiLoader(true, xconf.YAMLFileLoader("config.yaml"), xconf.EnvLoader()),
+//		xconf.RequiredKeys("db.host", "db.port"),
+//		xconf.KeyType("db.port", 0),
+//		xconf.KeyRange("db.port", 1, 65535),
+//		xconf.KeyPattern("app.env", regexp.MustCompile(`^(dev|staging|prod)$`)),
+//		func(configMap map[string]any) error { /* your own validation */ },
+//	)
+func ValidateLoader(loader Loader, rules ...ValidationRule) Loader {
+	return decorate(loader, func(ctx context.Context, loader Loader) (map[string]any, error) {
+		configMap, err := LoadWithContext(ctx, loader)
+		if err != nil {
+			return configMap, err
+		}
+		if err := validateConfigMap(configMap, rules); err != nil {
+			return nil, err
+		}
+
+		return configMap, nil
+	})
+}
+
+// DefaultConfigWithValidation validates every loaded configuration against given rules
+// (see [ValidateLoader]). A configuration which fails validation is rejected:
+// the error is returned by [NewDefaultConfig], or, on reload, passed to the reload error handler,
+// previous configuration remaining active.
+// Rules receive the configuration map as loaded, with runtime overrides applied (see [DefaultConfig.Set]),
+// before keys' case normalization (see [DefaultConfigWithIgnoreCaseSensitivity]), on every change:
+// reload, [DefaultConfig.ReloadPrefix], [DefaultConfig.Set] / [DefaultConfig.Unset].
+//
+// By default, configuration is not validated.
+func DefaultConfigWithValidation(rules ...ValidationRule) DefaultConfigOption {
+	return func(config *DefaultConfig) {
+		config.validationRules = append(config.validationRules, rules...)
+	}
+}
+
+// validateConfigMap checks all rules against given configuration map, and returns their errors, if any.
+func validateConfigMap(configMap map[string]any, rules []ValidationRule) error {
+	var mErr *xerr.MultiError
+	for _, rule := range rules {
+		err := rule(configMap)
+		var ruleMultiErr *xerr.MultiError
+		if errors.As(err, &ruleMultiErr) {
+			mErr = mErr.Add(ruleMultiErr.Errors()...) // flatten, for a readable error.
+		} else {
+			mErr = mErr.Add(err)
+		}
+	}
+
+	return mErr.ErrOrNil()
+}
+
+// RequiredKeys is a validation rule which checks that given keys are present,
+// with a non-nil value.
+func RequiredKeys(keys ...string) ValidationRule {
+	return func(configMap map[string]any) error {
+		var mErr *xerr.MultiError
+		for _, key := range keys {
+			if value, found := configMap[key]; !found || value == nil {
+				mErr = mErr.Add(xerr.Wrapf(ErrValidation, "key %q is required", key))
+			}
+		}
+
+		return mErr.ErrOrNil()
+	}
+}
+
+// KeyType is a validation rule which checks that given key's value can be casted
+// to sample's type, the way Get does it (see [DefaultConfig.Get]).
+// A missing key is not checked (see [RequiredKeys]).
+//
+// Example: xconf.KeyType("db.port", 0), xconf.KeyType("http.timeout", time.Second).
+func KeyType(key string, sample any) ValidationRule {
+	return func(configMap map[string]any) error {
+		value, found := configMap[key]
+		if !found {
+			return nil
+		}
+		if _, err := castValueByDefault(value, sample); err != nil {
+			return xerr.Wrapf(ErrValidation, "key %q: cannot cast %#v (%T) to %T", key, value, value, sample)
+		}
+
+		return nil
+	}
+}
+
+// KeyPattern is a validation rule which checks that given key's (string) value matches the pattern.
+// A missing key is not checked (see [RequiredKeys]).
+func KeyPattern(key string, pattern *regexp.Regexp) ValidationRule {
+	return func(configMap map[string]any) error {
+		value, found := configMap[key]
+		if !found {
+			return nil
+		}
+		strValue, err := cast.ToStringE(value)
+		if err != nil || !pattern.MatchString(strValue) {
+			return xerr.Wrapf(ErrValidation, "key %q: value %#v does not match pattern `%s`", key, value, pattern)
+		}
+
+		return nil
+	}
+}
+
+// KeyRange is a validation rule which checks that given key's (numeric) value is within [min, max].
+// A missing key is not checked (see [RequiredKeys]).
+func KeyRange(key string, min, max float64) ValidationRule {
+	return func(configMap map[string]any) error {
+		value, found := configMap[key]
+		if !found {
+			return nil
+		}
+		number, err := cast.ToFloat64E(value)
+		if err != nil || number < min || number > max {
+			return xerr.Wrapf(ErrValidation, "key %q: value %#v is not in range [%v, %v]", key, value, min, max)
+		}
+
+		return nil
+	}
+}
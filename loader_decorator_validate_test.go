@@ -0,0 +1,171 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestValidateLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - valid configuration", testValidateLoaderValidConfig)
+	t.Run("error - all violations are reported", testValidateLoaderReturnsAllViolations)
+	t.Run("error - original loader", testValidateLoaderReturnsErrFromLoader)
+}
+
+func testValidateLoaderValidConfig(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	configMap := map[string]any{
+		"db.host":      "10.0.0.1",
+		"db.port":      "3306",
+		"http.timeout": "30s",
+		"app.env":      "prod",
+	}
+	subject := xconf.ValidateLoader(
+		xconf.PlainLoader(configMap),
+		xconf.RequiredKeys("db.host", "db.port"),
+		xconf.KeyType("db.port", 0),
+		xconf.KeyType("http.timeout", time.Second),
+		xconf.KeyType("not.found", 0),
+		xconf.KeyRange("db.port", 1, 65535),
+		xconf.KeyPattern("app.env", regexp.MustCompile(`^(dev|staging|prod)$`)),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, configMap, config)
+}
+
+func testValidateLoaderReturnsAllViolations(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		customErr = errors.New("custom rule violation")
+		subject   = xconf.ValidateLoader(
+			xconf.PlainLoader(map[string]any{
+				"db.host":      nil,
+				"db.port":      70000,
+				"http.timeout": "30seconds",
+				"app.env":      "test",
+			}),
+			xconf.RequiredKeys("db.host", "db.name"),
+			xconf.KeyType("http.timeout", time.Second),
+			xconf.KeyRange("db.port", 1, 65535),
+			xconf.KeyPattern("app.env", regexp.MustCompile(`^(dev|staging|prod)$`)),
+			func(map[string]any) error { return customErr },
+		)
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, errors.Is(err, xconf.ErrValidation))
+	assertTrue(t, errors.Is(err, customErr))
+	assertEqual(
+		t,
+		`key "db.host" is required: configuration validation failed`+"\n"+
+			`key "db.name" is required: configuration validation failed`+"\n"+
+			`key "http.timeout": cannot cast "30seconds" (string) to time.Duration: configuration validation failed`+"\n"+
+			`key "db.port": value 70000 is not in range [1, 65535]: configuration validation failed`+"\n"+
+			"key \"app.env\": value \"test\" does not match pattern `^(dev|staging|prod)$`: configuration validation failed\n"+
+			"custom rule violation",
+		err.Error(),
+	)
+}
+
+func testValidateLoaderReturnsErrFromLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	expectedErr := errors.New("intentionally triggered load error")
+	subject := xconf.ValidateLoader(
+		xconf.LoaderFunc(func() (map[string]any, error) {
+			return nil, expectedErr
+		}),
+		xconf.RequiredKeys("foo"),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertTrue(t, !errors.Is(err, xconf.ErrValidation))
+	assertNil(t, config)
+}
+
+func TestDefaultConfig_WithValidation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("error - initial load", testDefaultConfigWithValidationReturnsErr)
+	t.Run("success - invalid reload keeps previous config", testDefaultConfigWithValidationKeepsPreviousConfig)
+}
+
+func testDefaultConfigWithValidationReturnsErr(t *testing.T) {
+	t.Parallel()
+
+	// act
+	config, err := xconf.NewDefaultConfig(
+		xconf.PlainLoader(map[string]any{"foo": "bar"}),
+		xconf.DefaultConfigWithValidation(xconf.RequiredKeys("baz")),
+	)
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrValidation))
+	assertNil(t, config)
+}
+
+func testDefaultConfigWithValidationKeepsPreviousConfig(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loadsCnt uint32
+		loader   = xconf.LoaderFunc(func() (map[string]any, error) {
+			if atomic.AddUint32(&loadsCnt, 1) == 1 {
+				return map[string]any{"port": 8080}, nil
+			}
+
+			return map[string]any{"port": "invalid"}, nil
+		})
+		reloadErr atomic.Value
+	)
+	config, err := xconf.NewDefaultConfig(
+		loader,
+		xconf.DefaultConfigWithReloadInterval(10*time.Millisecond),
+		xconf.DefaultConfigWithIgnoreCaseSensitivity(),
+		xconf.DefaultConfigWithValidation(xconf.KeyType("port", 0)),
+		xconf.DefaultConfigWithReloadErrorHandler(func(err error) {
+			reloadErr.Store(err)
+		}),
+	)
+	requireNil(t, err)
+	defer config.Close()
+
+	// act
+	time.Sleep(35 * time.Millisecond)
+
+	// assert
+	assertEqual(t, 8080, config.Get("port"))
+	if err, ok := reloadErr.Load().(error); assertTrue(t, ok) {
+		assertTrue(t, errors.Is(err, xconf.ErrValidation))
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DirLoader loads and merges configuration from all the files matching a glob pattern,
+// like "conf.d/*.yaml", following the config-directory (conf.d) convention.
+// Files are loaded with [FileLoader] (so their format is given by their extension),
+// in lexicographic order of their paths, a later file overriding a previous file's same found key.
+//
+// Hidden files/directories (starting with ".") are skipped - this also
+// covers editors' swap files and Kubernetes "..data" / "..<timestamp>" internal entries.
+// If no file matches the pattern, an empty configuration is returned.
+//
+// It can be wrapped in a [DirCacheLoader], if called multiple times.
+type DirLoader struct {
+	// pattern is the glob pattern files are matched against.
+	pattern string
+	// recursive is a flag indicating whether subdirectories should be traversed.
+	recursive bool
+	// ignoreErrors is a flag indicating whether files which fail to load are skipped.
+	ignoreErrors bool
+	// fileOpts are the options files' loaders are configured with.
+	fileOpts []FileLoaderOption
+}
+
+// NewDirLoader instantiates a new DirLoader object that loads
+// configuration from files matching given glob pattern (see [filepath.Match] for its syntax).
+//
+// Example:
+//
+//	// loads conf.d/00-defaults.yaml, conf.d/10-db.yaml, conf.d/99-local.yaml, in this order.
+//	loader := xconf.NewDirLoader("conf.d/*.yaml")
+func NewDirLoader(pattern string, opts ...DirLoaderOption) DirLoader {
+	loader := DirLoader{
+		pattern: pattern,
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&loader)
+	}
+
+	return loader
+}
+
+// Load returns a merged configuration key-value map of all matching files,
+// or an error if something bad happens along the process.
+func (loader DirLoader) Load() (map[string]any, error) {
+	return loader.LoadContext(context.Background())
+}
+
+// LoadContext is like Load, stopping if ctx is done. It implements [ContextLoader].
+func (loader DirLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	filePaths, err := loader.matchFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	configMap := make(map[string]any)
+	for _, filePath := range filePaths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		fileConfigMap, err := LoadWithContext(ctx, FileLoader(filePath, loader.fileOpts...))
+		if err != nil {
+			if loader.ignoreErrors {
+				continue
+			}
+
+			return nil, err
+		}
+		for key, value := range fileConfigMap {
+			configMap[key] = value
+		}
+	}
+
+	return configMap, nil
+}
+
+// matchFiles returns the sorted paths of the (non-hidden) files matching the pattern.
+func (loader DirLoader) matchFiles() ([]string, error) {
+	if !loader.recursive {
+		matches, err := filepath.Glob(loader.pattern)
+		if err != nil {
+			return nil, err
+		}
+		filePaths := make([]string, 0, len(matches))
+		for _, match := range matches {
+			if isHiddenPath(match) {
+				continue
+			}
+			if fInfo, err := os.Stat(match); err == nil && !fInfo.IsDir() { // follow symlinks.
+				filePaths = append(filePaths, match)
+			}
+		}
+
+		return filePaths, nil
+	}
+
+	dirPath, namePattern := filepath.Split(loader.pattern)
+	if _, err := filepath.Match(namePattern, ""); err != nil {
+		return nil, err
+	}
+	if dirPath == "" {
+		dirPath = "."
+	}
+	var filePaths []string
+	err := filepath.WalkDir(dirPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dirPath && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		if matched, _ := filepath.Match(namePattern, entry.Name()); matched {
+			filePaths = append(filePaths, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(filePaths)
+
+	return filePaths, nil
+}
+
+// isHiddenPath checks whether path's last element is hidden (starts with ".").
+func isHiddenPath(path string) bool {
+	return strings.HasPrefix(filepath.Base(path), ".")
+}
+
+// DirLoaderOption defines optional function for configuring
+// a Dir Loader.
+type DirLoaderOption func(*DirLoader)
+
+// DirLoaderWithRecursion enables subdirectories traversal, files from pattern's directory
+// and its subdirectories being matched against pattern's last element (like "*.yaml").
+// Example: with "conf.d/*.yaml" pattern, "conf.d/db/primary.yaml" is loaded, too.
+//
+// By default, subdirectories are ignored.
+func DirLoaderWithRecursion() DirLoaderOption {
+	return func(loader *DirLoader) {
+		loader.recursive = true
+	}
+}
+
+// DirLoaderWithErrorTolerance makes the loader skip the files which fail to load
+// (like a file with invalid content or unknown extension), instead of returning the error.
+// Note: an error listing the files (like a bad pattern, or a non-readable directory) is still returned.
+//
+// By default, first file error is returned.
+func DirLoaderWithErrorTolerance() DirLoaderOption {
+	return func(loader *DirLoader) {
+		loader.ignoreErrors = true
+	}
+}
+
+// DirLoaderWithFileLoaderOptions sets the options each file's [FileLoader] is configured with
+// (like [FileLoaderWithContentSniffing], [FileLoaderWithEncoding]).
+func DirLoaderWithFileLoaderOptions(opts ...FileLoaderOption) DirLoaderOption {
+	return func(loader *DirLoader) {
+		loader.fileOpts = opts
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestDirLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - matching files merged in order", testDirLoaderMergesMatchingFiles)
+	t.Run("success - recursive", testDirLoaderRecursive)
+	t.Run("success - no matching file", testDirLoaderNoMatchingFile)
+	t.Run("success - error tolerance", testDirLoaderWithErrorTolerance)
+	t.Run("error - invalid file", testDirLoaderReturnsFileErr)
+	t.Run("error - bad pattern", testDirLoaderReturnsErrBadPattern)
+	t.Run("error - canceled context", testDirLoaderReturnsCtxErr)
+}
+
+// setUpConfDir creates a conf.d like directory structure.
+func setUpConfDir(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"10-db.yaml":           "db_host: db.local\ndb_port: 5432\n",
+		"00-defaults.yaml":     "app: demo\ndb_host: localhost\nlog_level: info\n",
+		"99-local.yml":         "log_level: debug\n",
+		"20-cache.json":        `{"cache_ttl": 60, "log_level": "warn"}`,
+		"README.txt":           "not a config file",
+		".10-db.yaml.swp":      "db_host: should be skipped\n",
+		"extra/50-extra.yaml":  "db_port: 6432\n",
+		".hidden/99-skip.yaml": "app: should be skipped\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal("prerequisite failed:", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal("prerequisite failed:", err)
+		}
+	}
+
+	return dir
+}
+
+func testDirLoaderMergesMatchingFiles(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	dir := setUpConfDir(t)
+	subject := xconf.NewDirLoader(filepath.Join(dir, "*.y*ml"))
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"app":       "demo",
+			"db_host":   "db.local",
+			"db_port":   5432,
+			"log_level": "debug",
+		},
+		config,
+	)
+}
+
+func testDirLoaderRecursive(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	dir := setUpConfDir(t)
+	subject := xconf.NewDirLoader(filepath.Join(dir, "*.yaml"), xconf.DirLoaderWithRecursion())
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"app":       "demo",
+			"db_host":   "db.local",
+			"db_port":   6432,
+			"log_level": "info",
+		},
+		config,
+	)
+}
+
+func testDirLoaderNoMatchingFile(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.NewDirLoader(filepath.Join(t.TempDir(), "*.yaml"))
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{}, config)
+}
+
+func testDirLoaderWithErrorTolerance(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	dir := setUpConfDir(t)
+	subject := xconf.NewDirLoader(filepath.Join(dir, "*"), xconf.DirLoaderWithErrorTolerance())
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"app":       "demo",
+			"db_host":   "db.local",
+			"db_port":   5432,
+			"cache_ttl": float64(60),
+			"log_level": "debug",
+		},
+		config,
+	)
+}
+
+func testDirLoaderReturnsFileErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	dir := setUpConfDir(t)
+	subject := xconf.NewDirLoader(filepath.Join(dir, "*"))
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrUnknownConfigFileExt))
+	assertNil(t, config)
+}
+
+func testDirLoaderReturnsErrBadPattern(t *testing.T) {
+	t.Parallel()
+
+	for _, recursive := range [...]bool{false, true} {
+		// arrange
+		var opts []xconf.DirLoaderOption
+		if recursive {
+			opts = append(opts, xconf.DirLoaderWithRecursion())
+		}
+		subject := xconf.NewDirLoader(filepath.Join(t.TempDir(), "[*.yaml"), opts...)
+
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertTrue(t, errors.Is(err, filepath.ErrBadPattern))
+		assertNil(t, config)
+	}
+}
+
+func testDirLoaderReturnsCtxErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	dir := setUpConfDir(t)
+	subject := xconf.NewDirLoader(filepath.Join(dir, "*.yaml"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// act
+	config, err := subject.LoadContext(ctx)
+
+	// assert
+	assertTrue(t, errors.Is(err, context.Canceled))
+	assertNil(t, config)
+}
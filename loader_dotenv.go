@@ -10,11 +10,29 @@ import (
 	"os"
 
 	"github.com/joho/godotenv"
+	"golang.org/x/text/encoding"
 )
 
+// DotEnvLoaderOption defines optional function for configuring a .env loader.
+type DotEnvLoaderOption func(*dotEnvLoaderOptions)
+
+// dotEnvLoaderOptions holds the configuration of a .env loader.
+type dotEnvLoaderOptions struct {
+	enc encoding.Encoding // source encoding of the content, if not UTF-8.
+}
+
+// DotEnvLoaderWithEncoding sets the source encoding of the content,
+// for example [golang.org/x/text/encoding/unicode.UTF16] with BOM,
+// content being transcoded to UTF-8. By default, UTF-8 is assumed.
+func DotEnvLoaderWithEncoding(enc encoding.Encoding) DotEnvLoaderOption {
+	return func(opts *dotEnvLoaderOptions) {
+		opts.enc = enc
+	}
+}
+
 // DotEnvFileLoader loads .env configuration from a file.
 // The location of .env content based file is given as parameter.
-func DotEnvFileLoader(filePath string) Loader {
+func DotEnvFileLoader(filePath string, opts ...DotEnvLoaderOption) Loader {
 	return LoaderFunc(func() (map[string]any, error) {
 		f, err := os.Open(filePath)
 		if err != nil {
@@ -22,17 +40,23 @@ func DotEnvFileLoader(filePath string) Loader {
 		}
 		defer f.Close()
 
-		return DotEnvReaderLoader(f).Load()
+		return DotEnvReaderLoader(f, opts...).Load()
 	})
 }
 
 // DotEnvReaderLoader loads .env configuration from an [io.Reader].
-func DotEnvReaderLoader(reader io.Reader) Loader {
+func DotEnvReaderLoader(reader io.Reader, opts ...DotEnvLoaderOption) Loader {
+	var loaderOpts dotEnvLoaderOptions
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&loaderOpts)
+	}
+
 	return LoaderFunc(func() (map[string]any, error) {
 		if seekReader, ok := reader.(io.Seeker); ok {
 			_, _ = seekReader.Seek(0, io.SeekStart) // move to the beginning in case of a re-load needed.
 		}
-		envs, err := godotenv.Parse(reader)
+		envs, err := godotenv.Parse(decodeReader(reader, loaderOpts.enc))
 		if err != nil {
 			return nil, err
 		}
@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/actforgood/xconf"
+	"golang.org/x/text/encoding/unicode"
 )
 
 var dotEnvConfigMap = map[string]any{
@@ -29,6 +30,33 @@ func TestDotEnvReaderLoader(t *testing.T) {
 	t.Run("success - valid .env content", testDotEnvReaderLoaderWithValidContent)
 	t.Run("error - invalid .env content", testDotEnvReaderLoaderWithInvalidContent)
 	t.Run("success - safe-mutable config map", testDotEnvReaderLoaderReturnsSafeMutableConfigMap)
+	t.Run("success - utf-16 encoded content", testDotEnvReaderLoaderWithUTF16Content)
+}
+
+func testDotEnvReaderLoaderWithUTF16Content(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	enc := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)
+	content, err := enc.NewEncoder().Bytes([]byte("DOTENV_CITY=Zürich\nDOTENV_YEAR=2022"))
+	requireNil(t, err)
+	subject := xconf.DotEnvReaderLoader(bytes.NewReader(content), xconf.DotEnvLoaderWithEncoding(enc))
+
+	for i := 0; i < 2; i++ { // re-load works too
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(
+			t,
+			map[string]any{
+				"DOTENV_CITY": "Zürich",
+				"DOTENV_YEAR": "2022",
+			},
+			config,
+		)
+	}
 }
 
 func testDotEnvReaderLoaderWithValidContent(t *testing.T) {
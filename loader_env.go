@@ -7,10 +7,65 @@ package xconf
 
 import (
 	"os"
+	"strings"
 )
 
+// envLoaderOptions holds EnvLoader's options.
+type envLoaderOptions struct {
+	prefix       string
+	stripPrefix  bool
+	keyTransform func(string) string
+	separator    string
+	replacement  string
+}
+
+// EnvLoaderOption defines optional function for configuring [EnvLoader].
+type EnvLoaderOption func(*envLoaderOptions)
+
+// EnvLoaderWithPrefix makes the loader load only the env variables having given prefix.
+// If stripPrefix is true, the prefix is removed from the loaded keys.
+func EnvLoaderWithPrefix(prefix string, stripPrefix bool) EnvLoaderOption {
+	return func(opts *envLoaderOptions) {
+		opts.prefix = prefix
+		opts.stripPrefix = stripPrefix
+	}
+}
+
+// EnvLoaderWithKeyTransform sets a function applied on the loaded keys,
+// like [strings.ToLower].
+// It is applied after prefix stripping and separator mapping.
+func EnvLoaderWithKeyTransform(keyTransform func(string) string) EnvLoaderOption {
+	return func(opts *envLoaderOptions) {
+		opts.keyTransform = keyTransform
+	}
+}
+
+// EnvLoaderWithSeparatorMapping replaces given separator with given replacement
+// in the loaded keys, like "__" with "." (so that "DB__HOST" becomes "DB.HOST").
+func EnvLoaderWithSeparatorMapping(separator, replacement string) EnvLoaderOption {
+	return func(opts *envLoaderOptions) {
+		opts.separator = separator
+		opts.replacement = replacement
+	}
+}
+
 // EnvLoader loads configuration from OS's ENV.
-func EnvLoader() Loader {
+//
+// Example, loading "APP_DB__HOST" as "db.host":
+//
+//	loader := xconf.EnvLoader(
+//		xconf.EnvLoaderWithPrefix("APP_", true),
+//		xconf.EnvLoaderWithSeparatorMapping("__", "."),
+//		xconf.EnvLoaderWithKeyTransform(strings.ToLower),
+//	)
+func EnvLoader(opts ...EnvLoaderOption) Loader {
+	var options envLoaderOptions
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	return LoaderFunc(func() (map[string]any, error) {
 		envs := os.Environ()
 
@@ -19,7 +74,9 @@ func EnvLoader() Loader {
 		for _, env := range envs {
 			for i := 0; i < len(env); i++ {
 				if env[i] == kvSeparator {
-					configMap[env[:i]] = env[i+1:]
+					if key, ok := options.key(env[:i]); ok {
+						configMap[key] = env[i+1:]
+					}
 
 					break
 				}
@@ -29,3 +86,24 @@ func EnvLoader() Loader {
 		return configMap, nil
 	})
 }
+
+// key returns the configuration key for given env name,
+// and whether the env should be loaded.
+func (opts envLoaderOptions) key(envName string) (string, bool) {
+	if opts.prefix != "" {
+		if !strings.HasPrefix(envName, opts.prefix) {
+			return "", false
+		}
+		if opts.stripPrefix {
+			envName = envName[len(opts.prefix):]
+		}
+	}
+	if opts.separator != "" {
+		envName = strings.ReplaceAll(envName, opts.separator, opts.replacement)
+	}
+	if opts.keyTransform != nil {
+		envName = opts.keyTransform(envName)
+	}
+
+	return envName, envName != ""
+}
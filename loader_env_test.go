@@ -11,6 +11,7 @@ import (
 	"math/big"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/actforgood/xconf"
@@ -19,6 +20,8 @@ import (
 func TestEnvLoader(t *testing.T) {
 	t.Run("success - os env gets loaded", testEnvLoaderSuccess)
 	t.Run("success - safe-mutable config map", testEnvLoaderReturnsSafeMutableConfigMap)
+	t.Run("success - with prefix, separator mapping, key transform", testEnvLoaderWithOptions)
+	t.Run("success - with prefix, not stripped", testEnvLoaderWithPrefixNotStripped)
 }
 
 func testEnvLoaderSuccess(t *testing.T) {
@@ -65,6 +68,51 @@ func testEnvLoaderReturnsSafeMutableConfigMap(t *testing.T) {
 	}
 }
 
+func testEnvLoaderWithOptions(t *testing.T) {
+	// arrange
+	prefix := getRandomEnvName() + "_"
+	subject := xconf.EnvLoader(
+		xconf.EnvLoaderWithPrefix(prefix, true),
+		xconf.EnvLoaderWithSeparatorMapping("__", "."),
+		xconf.EnvLoaderWithKeyTransform(strings.ToLower),
+	)
+	t.Setenv(prefix+"DB__HOST", "127.0.0.1")
+	t.Setenv(prefix+"DB__MYSQL__PORT", "3306")
+	t.Setenv(prefix+"LOG_LEVEL", "info")
+	t.Setenv(prefix, "empty key")
+	t.Setenv("XCONF_TEST_ENV_LOADER_OTHER", "bar")
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"db.host":       "127.0.0.1",
+			"db.mysql.port": "3306",
+			"log_level":     "info",
+		},
+		config,
+	)
+}
+
+func testEnvLoaderWithPrefixNotStripped(t *testing.T) {
+	// arrange
+	prefix := getRandomEnvName() + "_"
+	subject := xconf.EnvLoader(xconf.EnvLoaderWithPrefix(prefix, false))
+	t.Setenv(prefix+"DB__HOST", "127.0.0.1")
+	t.Setenv("XCONF_TEST_ENV_LOADER_OTHER", "bar")
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{prefix + "DB__HOST": "127.0.0.1"}, config)
+}
+
 // setUpEnv sets OS env with provided value.
 // Returns the previous value, if env name already exists.
 func setUpEnv(envName, value string) string {
@@ -1,3 +1,5 @@
+//go:build !js
+
 // Copyright The ActForGood Authors.
 // Use of this source code is governed by an MIT-style
 // license that can be found in the LICENSE file or at
@@ -8,14 +10,18 @@ package xconf
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/actforgood/xerr"
 	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	clientv3 "go.etcd.io/etcd/client/v3"
 )
 
@@ -30,6 +36,10 @@ const (
 	etcdEndpointsEnvName = "ETCD_ENDPOINTS"
 )
 
+// ErrEtcdWatcherStale is an error returned by [EtcdLoader] with watcher enabled,
+// while watching key(s) changes is interrupted, and thus configuration may be outdated.
+var ErrEtcdWatcherStale = errors.New("etcd watcher is stale")
+
 // EtcdLoader loads configuration from etcd.
 // Close it if watcher option is enabled, in order to properly release resources.
 type EtcdLoader struct {
@@ -46,6 +56,10 @@ func NewEtcdLoader(key string, opts ...EtcdLoaderOption) EtcdLoader {
 			valueFormat: RemoteValuePlain,
 			ctx:         context.Background(),
 			clientCfg:   clientv3.Config{DialTimeout: 10 * time.Second},
+
+			watchBackoffMin: 500 * time.Millisecond,
+			watchBackoffMax: 30 * time.Second,
+			lastRevision:    new(atomic.Int64),
 		},
 	}
 
@@ -59,16 +73,56 @@ func NewEtcdLoader(key string, opts ...EtcdLoaderOption) EtcdLoader {
 	if loader.strategy == nil {
 		loader.strategy = etcdSimpleLoadStrategy{info: loader.strategyInfo}
 	}
+	if len(loader.strategyInfo.failoverClusters) > 0 {
+		loader.strategy = newEtcdFailoverStrategy(loader.strategy, loader.strategyInfo)
+	}
 
 	return loader
 }
 
+// newEtcdFailoverStrategy returns a [FailoverLoader] over given strategy and
+// same kind of strategies for each of the failover clusters.
+func newEtcdFailoverStrategy(primary Loader, info *etcdStrategyInfo) Loader {
+	strategies := make([]Loader, 0, len(info.failoverClusters)+1)
+	strategies = append(strategies, primary)
+	_, isWatcher := primary.(*etcdWatcherLoadStrategy)
+	for _, endpoints := range info.failoverClusters {
+		clusterInfo := *info
+		clusterInfo.clientCfg.Endpoints = endpoints
+		clusterInfo.failoverClusters = nil
+		if isWatcher {
+			strategies = append(strategies, &etcdWatcherLoadStrategy{info: &clusterInfo})
+		} else {
+			strategies = append(strategies, etcdSimpleLoadStrategy{info: &clusterInfo})
+		}
+	}
+
+	return NewFailoverLoader(strategies, info.failoverOpts...)
+}
+
 // Load returns a configuration key-value map from etcd, or an error
 // if something bad happens along the process.
 func (loader EtcdLoader) Load() (map[string]any, error) {
 	return loader.strategy.Load()
 }
 
+// LoadContext is like Load, with the call(s) being canceled also when given context is done.
+// It implements [ContextLoader].
+func (loader EtcdLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	return LoadWithContext(ctx, loader.strategy)
+}
+
+// SourceVersion returns the etcd revision last key(s) were loaded at.
+// It implements [SourceVersioner].
+func (loader EtcdLoader) SourceVersion() SourceVersion {
+	version := SourceVersion{Kind: "etcd", Name: loader.strategyInfo.key}
+	if lastRevision := loader.strategyInfo.lastRevision.Load(); lastRevision > 0 {
+		version.Version = strconv.FormatInt(lastRevision, 10)
+	}
+
+	return version
+}
+
 // Close needs to be called in case watch key changes were enabled.
 // It releases associated resources.
 func (loader EtcdLoader) Close() error {
@@ -106,6 +160,31 @@ func EtcdLoaderWithEndpoints(endpoints []string) EtcdLoaderOption {
 	}
 }
 
+// EtcdLoaderWithFailoverClusters sets the endpoints of other, independent, etcd clusters
+// (like replicas from other regions), to fail over to, in the given order, if the primary cluster
+// (see [EtcdLoaderWithEndpoints]) is unavailable, so a regional outage does not take configuration
+// reloads down with it. A failed cluster is skipped for a cooldown interval, after which it gets probed again
+// (see [FailoverLoader] and its options, which can be passed, too).
+// All the other options (authentication, TLS, watcher, etc.) apply to all clusters.
+// Note: if watcher is enabled, each cluster gets its own watcher, once it was used.
+//
+// Example:
+//
+//	xconf.NewEtcdLoader(
+//		"app/config",
+//		xconf.EtcdLoaderWithEndpoints([]string{"etcd-1.eu-west.example.com:2379", "etcd-2.eu-west.example.com:2379"}),
+//		xconf.EtcdLoaderWithFailoverClusters(
+//			[][]string{{"etcd-1.eu-central.example.com:2379", "etcd-2.eu-central.example.com:2379"}},
+//			xconf.FailoverLoaderWithCooldown(time.Minute),
+//		),
+//	)
+func EtcdLoaderWithFailoverClusters(clusters [][]string, opts ...FailoverLoaderOption) EtcdLoaderOption {
+	return func(loader *EtcdLoader) {
+		loader.strategyInfo.failoverClusters = clusters
+		loader.strategyInfo.failoverOpts = opts
+	}
+}
+
 // EtcdLoaderWithPrefix sets the WithPrefix() option on etcd client.
 // The loaded key will be treated as a prefix, and thus all the keys
 // having that prefix will be returned.
@@ -151,12 +230,14 @@ func EtcdLoaderWithTLS(tlsCfg *tls.Config) EtcdLoaderOption {
 // If is set to [RemoteValuePlain], the key's value will be treated as plain content
 // and configuration will contain the key and its plain value.
 //
+// If is set to [RemoteValueTOML], [RemoteValueIni], [RemoteValueProperties], [RemoteValueDotEnv],
+// the key's value will be treated accordingly, like the files of the same format,
+// and configuration will be loaded from it.
+//
 // By default, is set to [RemoteValuePlain].
 func EtcdLoaderWithValueFormat(valueFormat string) EtcdLoaderOption {
 	return func(loader *EtcdLoader) {
-		if valueFormat == RemoteValueJSON ||
-			valueFormat == RemoteValueYAML ||
-			valueFormat == RemoteValuePlain {
+		if isValidRemoteValueFormat(valueFormat) {
 			loader.strategyInfo.valueFormat = valueFormat
 		}
 	}
@@ -167,6 +248,9 @@ func EtcdLoaderWithValueFormat(valueFormat string) EtcdLoaderOption {
 // If you plan to load configuration only once, or rarely, don't use this feature.
 // If you use this feature, call Close() method on the loader to gracefully release resources
 // (at your application shutdown).
+// If watching stops, it is re-established with exponential backoff, and meanwhile Load returns
+// [ErrEtcdWatcherStale] along with the last known configuration
+// (see also [EtcdLoaderWithWatcherBackoff], [EtcdLoaderWithWatcherHealthHandler]).
 func EtcdLoaderWithWatcher() EtcdLoaderOption {
 	return func(loader *EtcdLoader) {
 		loader.strategy = &etcdWatcherLoadStrategy{
@@ -175,13 +259,43 @@ func EtcdLoaderWithWatcher() EtcdLoaderOption {
 	}
 }
 
+// EtcdLoaderWithWatcherBackoff sets the exponential backoff limits used to re-establish
+// watching, if it stops (the watch channel gets closed / canceled due to network issues,
+// leader loss, etc.). Meanwhile, Load returns [ErrEtcdWatcherStale].
+// Has effect only together with [EtcdLoaderWithWatcher].
+// By default, backoff starts at 500ms and is capped at 30s.
+func EtcdLoaderWithWatcherBackoff(minBackoff, maxBackoff time.Duration) EtcdLoaderOption {
+	return func(loader *EtcdLoader) {
+		if minBackoff > 0 && maxBackoff >= minBackoff {
+			loader.strategyInfo.watchBackoffMin = minBackoff
+			loader.strategyInfo.watchBackoffMax = maxBackoff
+		}
+	}
+}
+
+// EtcdLoaderWithWatcherHealthHandler sets a handler to be called when watching
+// stops (with the reason), and when it gets re-established (with nil error).
+// You can log the error / expose a health check based on it, for example.
+// Has effect only together with [EtcdLoaderWithWatcher].
+func EtcdLoaderWithWatcherHealthHandler(handler func(err error)) EtcdLoaderOption {
+	return func(loader *EtcdLoader) {
+		loader.strategyInfo.watchHealthHandler = handler
+	}
+}
+
 // etcdStrategyInfo holds common info needed for strategies.
 type etcdStrategyInfo struct {
-	key          string              // the key to load
-	valueFormat  string              // value format, one of RemoteValue* constants
-	clientCfg    clientv3.Config     // client config
-	clientOpOpts []clientv3.OpOption // client operation options
-	ctx          context.Context     // request context
+	key                string                 // the key to load
+	valueFormat        string                 // value format, one of RemoteValue* constants
+	clientCfg          clientv3.Config        // client config
+	clientOpOpts       []clientv3.OpOption    // client operation options
+	ctx                context.Context        // request context
+	watchBackoffMin    time.Duration          // initial backoff for re-establishing watching
+	watchBackoffMax    time.Duration          // maximum backoff for re-establishing watching
+	watchHealthHandler func(err error)        // optional watching health handler
+	failoverClusters   [][]string             // other clusters' endpoints to fail over to
+	failoverOpts       []FailoverLoaderOption // failover options
+	lastRevision       *atomic.Int64          // the revision of last loaded key(s)
 }
 
 // etcdSimpleLoadStrategy loads configuration
@@ -192,6 +306,14 @@ type etcdSimpleLoadStrategy struct {
 
 // Load retrieves configuration by a simple client call.
 func (loaderStrategy etcdSimpleLoadStrategy) Load() (map[string]any, error) {
+	return loaderStrategy.LoadContext(loaderStrategy.info.ctx)
+}
+
+// LoadContext is like Load, with the call being canceled also when given context is done.
+func (loaderStrategy etcdSimpleLoadStrategy) LoadContext(ctx context.Context) (map[string]any, error) {
+	ctx, cancelCtx := mergeContexts(ctx, loaderStrategy.info.ctx)
+	defer cancelCtx()
+
 	cli, err := clientv3.New(loaderStrategy.info.clientCfg)
 	if err != nil {
 		return nil, err
@@ -199,13 +321,16 @@ func (loaderStrategy etcdSimpleLoadStrategy) Load() (map[string]any, error) {
 	defer cli.Close()
 
 	resp, err := cli.KV.Get(
-		loaderStrategy.info.ctx,
+		ctx,
 		loaderStrategy.info.key,
 		loaderStrategy.info.clientOpOpts...,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if resp.Header != nil {
+		loaderStrategy.info.lastRevision.Store(resp.Header.Revision)
+	}
 
 	return etcdKVPairsLoad(resp.Kvs, loaderStrategy.info.valueFormat)
 }
@@ -240,34 +365,54 @@ func etcdKVPairsLoad(kvPairs []*mvccpb.KeyValue, format string) (map[string]any,
 // etcdWatcherLoadStrategy loads initial configuration
 // by making a grpc call, and after that listens for
 // key changes asynchronously.
+// If watching stops (the watch channel gets closed / canceled), it is
+// re-established with exponential backoff. Meanwhile, the strategy is
+// considered stale, and Load returns [ErrEtcdWatcherStale].
 type etcdWatcherLoadStrategy struct {
-	info      *etcdStrategyInfo
-	configMap map[string]any   // "live" configuration map
-	client    *clientv3.Client // underlying client
-	mErr      *xerr.MultiError // error(s) occurred during watching, between 2 Loads.
-	mu        sync.RWMutex     // concurrency semaphore
-	wg        sync.WaitGroup   // wait group to wait for watching goroutine to finish
+	info       *etcdStrategyInfo
+	configMap  map[string]any   // "live" configuration map
+	client     *clientv3.Client // underlying client
+	revision   int64            // the last seen etcd revision
+	staleSince time.Time        // the moment watching stopped, zero value if watching is healthy
+	mErr       *xerr.MultiError // error(s) occurred during watching, between 2 Loads.
+	closing    chan struct{}    // channel to notify watching goroutine to stop
+	mu         sync.RWMutex     // concurrency semaphore
+	wg         sync.WaitGroup   // wait group to wait for watching goroutine to finish
 }
 
 // Load returns a copy of the stored configuration map,
 // or an error if something bad happens along the process.
+// If watching is stale, [ErrEtcdWatcherStale] is returned (along with the last known configuration map).
 func (loaderStrategy *etcdWatcherLoadStrategy) Load() (map[string]any, error) {
-	if err := loaderStrategy.init(); err != nil {
+	return loaderStrategy.LoadContext(loaderStrategy.info.ctx)
+}
+
+// LoadContext is like Load, with the initial call being canceled also when given context is done.
+func (loaderStrategy *etcdWatcherLoadStrategy) LoadContext(ctx context.Context) (map[string]any, error) {
+	if err := loaderStrategy.init(ctx); err != nil {
 		return nil, err
 	}
 
-	loaderStrategy.mu.RLock()
+	loaderStrategy.mu.Lock()
 	configMap := DeepCopyConfigMap(loaderStrategy.configMap)
+	if !loaderStrategy.staleSince.IsZero() {
+		loaderStrategy.mErr = loaderStrategy.mErr.Add(xerr.Wrapf(
+			ErrEtcdWatcherStale,
+			"since %s",
+			loaderStrategy.staleSince.Format(time.RFC3339),
+		))
+	}
 	err := loaderStrategy.mErr.ErrOrNil()
-	loaderStrategy.mErr.Reset()
-	loaderStrategy.mu.RUnlock()
+	loaderStrategy.mErr = nil
+	loaderStrategy.info.lastRevision.Store(loaderStrategy.revision)
+	loaderStrategy.mu.Unlock()
 
 	return configMap, err
 }
 
 // init initializes the client, populates initial configuration map
 // and starts watching for keys changes.
-func (loaderStrategy *etcdWatcherLoadStrategy) init() error {
+func (loaderStrategy *etcdWatcherLoadStrategy) init(ctx context.Context) error {
 	loaderStrategy.mu.Lock()
 	defer loaderStrategy.mu.Unlock()
 
@@ -276,24 +421,22 @@ func (loaderStrategy *etcdWatcherLoadStrategy) init() error {
 		if err != nil {
 			return err
 		}
-		loaderStrategy.client = cli
 
 		// populate config for the first time.
-		resp, err := cli.KV.Get(
-			loaderStrategy.info.ctx,
-			loaderStrategy.info.key,
-			loaderStrategy.info.clientOpOpts...,
-		)
-		if err != nil {
-			return err
-		}
-		configMap, err := etcdKVPairsLoad(resp.Kvs, loaderStrategy.info.valueFormat)
+		ctx, cancelCtx := mergeContexts(ctx, loaderStrategy.info.ctx)
+		configMap, revision, err := loaderStrategy.list(ctx, cli)
+		cancelCtx()
 		if err != nil {
+			_ = cli.Close()
+
 			return err
 		}
+		loaderStrategy.client = cli
 		loaderStrategy.configMap = configMap
+		loaderStrategy.revision = revision
 
 		// listen for changes.
+		loaderStrategy.closing = make(chan struct{})
 		loaderStrategy.wg.Add(1)
 		go loaderStrategy.watchKeysAsync()
 	}
@@ -301,60 +444,212 @@ func (loaderStrategy *etcdWatcherLoadStrategy) init() error {
 	return nil
 }
 
-// watchKeysAsync listens for key(s) changes.
-func (loaderStrategy *etcdWatcherLoadStrategy) watchKeysAsync() {
-	defer loaderStrategy.wg.Done()
-
-	watchChan := loaderStrategy.client.Watch(
-		loaderStrategy.info.ctx,
+// list retrieves the key(s) configuration map, and the revision it was retrieved at.
+func (loaderStrategy *etcdWatcherLoadStrategy) list(ctx context.Context, cli *clientv3.Client) (map[string]any, int64, error) {
+	resp, err := cli.KV.Get(
+		ctx,
 		loaderStrategy.info.key,
 		loaderStrategy.info.clientOpOpts...,
 	)
+	if err != nil {
+		return nil, 0, err
+	}
+	configMap, err := etcdKVPairsLoad(resp.Kvs, loaderStrategy.info.valueFormat)
+	if err != nil {
+		return nil, 0, err
+	}
+	if configMap == nil {
+		configMap = make(map[string]any)
+	}
+	var revision int64
+	if resp.Header != nil {
+		revision = resp.Header.Revision
+	}
+
+	return configMap, revision, nil
+}
+
+// resync replaces the configuration map with the one retrieved at current revision,
+// reconciling changes (including deletes) missed while not watching.
+func (loaderStrategy *etcdWatcherLoadStrategy) resync() error {
+	configMap, revision, err := loaderStrategy.list(loaderStrategy.info.ctx, loaderStrategy.client)
+	if err != nil {
+		return err
+	}
+
+	loaderStrategy.mu.Lock()
+	loaderStrategy.configMap = configMap
+	loaderStrategy.revision = revision
+	loaderStrategy.mu.Unlock()
+
+	return nil
+}
+
+// watchKeysAsync listens for key(s) changes, re-establishing
+// watching with exponential backoff, if it stops.
+// If revisions needed to resume watching were compacted, key(s) are re-listed
+// at current revision, and watching is resumed from there.
+func (loaderStrategy *etcdWatcherLoadStrategy) watchKeysAsync() {
+	defer loaderStrategy.wg.Done()
+
+	backoff := loaderStrategy.info.watchBackoffMin
+	for {
+		established, err := loaderStrategy.watchKeys()
+		if loaderStrategy.isClosing() {
+			return
+		}
+		if errors.Is(err, rpctypes.ErrCompacted) {
+			if err = loaderStrategy.resync(); err == nil {
+				continue
+			}
+		}
+		loaderStrategy.markStale(err)
+		if loaderStrategy.info.ctx.Err() != nil {
+			return // request context is done, watching cannot be re-established.
+		}
+		if established {
+			backoff = loaderStrategy.info.watchBackoffMin
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-loaderStrategy.closing:
+			timer.Stop()
+
+			return
+		case <-loaderStrategy.info.ctx.Done():
+			timer.Stop()
+
+			return
+		case <-timer.C:
+		}
+		backoff = min(2*backoff, loaderStrategy.info.watchBackoffMax)
+	}
+}
+
+// watchKeys watches key(s) changes, starting with the revision following the last seen one.
+// It returns when watching stops, with the reason and a flag indicating whether watching
+// was successfully established.
+func (loaderStrategy *etcdWatcherLoadStrategy) watchKeys() (bool, error) {
+	loaderStrategy.mu.RLock()
+	revision := loaderStrategy.revision
+	loaderStrategy.mu.RUnlock()
+
+	watchOpts := make([]clientv3.OpOption, 0, len(loaderStrategy.info.clientOpOpts)+2)
+	watchOpts = append(watchOpts, loaderStrategy.info.clientOpOpts...)
+	watchOpts = append(watchOpts, clientv3.WithCreatedNotify())
+	if revision > 0 {
+		watchOpts = append(watchOpts, clientv3.WithRev(revision+1))
+	}
+	ctx, cancelCtx := context.WithCancel(clientv3.WithRequireLeader(loaderStrategy.info.ctx))
+	defer cancelCtx()
+
+	var established bool
+	watchChan := loaderStrategy.client.Watch(ctx, loaderStrategy.info.key, watchOpts...)
 	for entry := range watchChan {
-		if entry.Canceled {
+		if err := entry.Err(); err != nil {
+			return established, err
+		}
+		if entry.Created {
+			established = true
+			loaderStrategy.markHealthy()
+
 			continue
 		}
-		for _, event := range entry.Events {
-			kvPair := event.Kv
-			if event.Type == mvccpb.DELETE { // key was deleted.
-				loaderStrategy.mu.Lock()
-				delete(loaderStrategy.configMap, string(kvPair.Key))
-				loaderStrategy.mu.Unlock()
+		loaderStrategy.applyEvents(entry.Events)
+		loaderStrategy.mu.Lock()
+		loaderStrategy.revision = max(loaderStrategy.revision, entry.Header.Revision)
+		loaderStrategy.mu.Unlock()
+	}
+	if err := ctx.Err(); err != nil {
+		return established, err
+	}
 
-				continue
-			}
+	return established, ErrEtcdWatcherStale
+}
 
-			// key was created/modified.
-			currentKeyConfigMap, err := getRemoteKVPairConfigMap(
-				string(kvPair.Key),
-				kvPair.Value,
-				loaderStrategy.info.valueFormat,
-			)
+// applyEvents updates the configuration map with watched events.
+func (loaderStrategy *etcdWatcherLoadStrategy) applyEvents(events []*clientv3.Event) {
+	for _, event := range events {
+		kvPair := event.Kv
+		if event.Type == mvccpb.DELETE { // key was deleted.
 			loaderStrategy.mu.Lock()
-			if err != nil {
-				loaderStrategy.mErr = loaderStrategy.mErr.Add(err)
-			} else {
-				// merge configs from different keys.
-				for key, value := range currentKeyConfigMap {
-					loaderStrategy.configMap[key] = value
-				}
-			}
+			delete(loaderStrategy.configMap, string(kvPair.Key))
 			loaderStrategy.mu.Unlock()
+
+			continue
 		}
+
+		// key was created/modified.
+		currentKeyConfigMap, err := getRemoteKVPairConfigMap(
+			string(kvPair.Key),
+			kvPair.Value,
+			loaderStrategy.info.valueFormat,
+		)
+		loaderStrategy.mu.Lock()
+		if err != nil {
+			loaderStrategy.mErr = loaderStrategy.mErr.Add(err)
+		} else {
+			// merge configs from different keys.
+			for key, value := range currentKeyConfigMap {
+				loaderStrategy.configMap[key] = value
+			}
+		}
+		loaderStrategy.mu.Unlock()
+	}
+}
+
+// markStale marks watching as stale, storing the reason it stopped,
+// and notifies the health handler, if any.
+func (loaderStrategy *etcdWatcherLoadStrategy) markStale(reason error) {
+	loaderStrategy.mu.Lock()
+	if loaderStrategy.staleSince.IsZero() {
+		loaderStrategy.staleSince = time.Now()
+	}
+	loaderStrategy.mErr = loaderStrategy.mErr.Add(reason)
+	loaderStrategy.mu.Unlock()
+
+	if loaderStrategy.info.watchHealthHandler != nil {
+		loaderStrategy.info.watchHealthHandler(reason)
+	}
+}
+
+// markHealthy marks watching as healthy, and notifies the health handler,
+// if any, in case watching was stale before.
+func (loaderStrategy *etcdWatcherLoadStrategy) markHealthy() {
+	loaderStrategy.mu.Lock()
+	wasStale := !loaderStrategy.staleSince.IsZero()
+	loaderStrategy.staleSince = time.Time{}
+	loaderStrategy.mu.Unlock()
+
+	if wasStale && loaderStrategy.info.watchHealthHandler != nil {
+		loaderStrategy.info.watchHealthHandler(nil)
+	}
+}
+
+// isClosing checks if Close was called.
+func (loaderStrategy *etcdWatcherLoadStrategy) isClosing() bool {
+	select {
+	case <-loaderStrategy.closing:
+		return true
+	default:
+		return false
 	}
 }
 
 // Close closes the underlying client connection.
 func (loaderStrategy *etcdWatcherLoadStrategy) Close() error {
-	loaderStrategy.mu.RLock()
-	defer loaderStrategy.mu.RUnlock()
-
-	if loaderStrategy.client != nil {
-		err := loaderStrategy.client.Close()
-		loaderStrategy.wg.Wait()
+	loaderStrategy.mu.Lock()
+	if loaderStrategy.client == nil || loaderStrategy.isClosing() {
+		loaderStrategy.mu.Unlock()
 
-		return err
+		return nil
 	}
+	close(loaderStrategy.closing)
+	loaderStrategy.mu.Unlock()
 
-	return nil
+	err := loaderStrategy.client.Close()
+	loaderStrategy.wg.Wait()
+
+	return err
 }
@@ -1,3 +1,5 @@
+//go:build !js
+
 // Copyright The ActForGood Authors.
 // Use of this source code is governed by an MIT-style
 // license that can be found in the LICENSE file or at
@@ -12,6 +14,7 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -51,6 +54,35 @@ func (svr *etcdKVServer) Compact(context.Context, *pb.CompactionRequest) (*pb.Co
 	return &pb.CompactionResponse{}, nil
 }
 
+type etcdWatchServer struct {
+	watchCallback func(pb.Watch_WatchServer) error
+}
+
+func (svr *etcdWatchServer) Watch(stream pb.Watch_WatchServer) error {
+	if svr.watchCallback != nil {
+		return svr.watchCallback(stream)
+	}
+
+	return etcdWatchCreated(stream)
+}
+
+// etcdWatchCreated acknowledges a watch creation request
+// and blocks until the stream is done.
+func etcdWatchCreated(stream pb.Watch_WatchServer) error {
+	if _, err := stream.Recv(); err != nil {
+		return err
+	}
+	if err := stream.Send(&pb.WatchResponse{
+		Header:  &pb.ResponseHeader{Revision: 1},
+		Created: true,
+	}); err != nil {
+		return err
+	}
+	<-stream.Context().Done()
+
+	return nil
+}
+
 type etcdAuthServer struct {
 	*pb.UnimplementedAuthServer
 	authenticateCallback func(context.Context, *pb.AuthenticateRequest) (*pb.AuthenticateResponse, error)
@@ -76,6 +108,19 @@ func startEtcdKVMockServer(
 ) (*grpc.Server, string) {
 	t.Helper()
 
+	return startEtcdKVWatchMockServer(t, key, returnedKvs, returnedErr, nil)
+}
+
+// startEtcdKVWatchMockServer starts an etcd key-value and watch grpc mock server.
+func startEtcdKVWatchMockServer(
+	t *testing.T,
+	key string,
+	returnedKvs []*mvccpb.KeyValue,
+	returnedErr error,
+	watchCallback func(pb.Watch_WatchServer) error,
+) (*grpc.Server, string) {
+	t.Helper()
+
 	rangeCallback := func(_ context.Context, rr *pb.RangeRequest) (*pb.RangeResponse, error) {
 		assertEqual(t, key, string(rr.Key))
 
@@ -89,13 +134,25 @@ func startEtcdKVMockServer(
 			Count: int64(len(returnedKvs)),
 		}, nil
 	}
-	kvSvr := etcdKVServer{rangeCallback: rangeCallback}
+
+	return serveEtcdMock(
+		t,
+		&etcdKVServer{rangeCallback: rangeCallback},
+		&etcdWatchServer{watchCallback: watchCallback},
+	)
+}
+
+// serveEtcdMock starts a grpc server with given etcd key-value and watch mock servers.
+func serveEtcdMock(t *testing.T, kvSvr *etcdKVServer, watchSvr *etcdWatchServer) (*grpc.Server, string) {
+	t.Helper()
+
 	ln, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
 		t.Fatal(err)
 	}
 	svr := grpc.NewServer()
-	pb.RegisterKVServer(svr, &kvSvr)
+	pb.RegisterKVServer(svr, kvSvr)
+	pb.RegisterWatchServer(svr, watchSvr)
 	go func(svr *grpc.Server, l net.Listener) {
 		_ = svr.Serve(l)
 	}(svr, ln)
@@ -219,6 +276,53 @@ func TestEtcdLoader(t *testing.T) {
 		testEtcdLoaderReturnsErrFromJSONValueDeserialization(true),
 	)
 	t.Run("success - safe-mutable config map", testEtcdLoaderReturnsSafeMutableConfigMap)
+	t.Run("success - with watcher - watching is re-established", testEtcdLoaderWithWatcherReestablishesWatching)
+	t.Run("error - with watcher - context is done", testEtcdLoaderWithWatcherReturnsStaleErrOnCtxDone)
+	t.Run("success - with watcher - recovers from compaction", testEtcdLoaderWithWatcherRecoversFromCompaction)
+	t.Run("success - fails over to other cluster", testEtcdLoaderWithFailoverClusters(false))
+	t.Run("success - with watcher - fails over to other cluster", testEtcdLoaderWithFailoverClusters(true))
+}
+
+func testEtcdLoaderWithFailoverClusters(withWatcher bool) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var (
+			format             = xconf.RemoteValueJSON
+			key                = etcdKeys[format]
+			primarySvr, addr1  = startEtcdKVMockServer(t, key, nil, errors.New("etcd intentionally triggered call error"))
+			failoverSvr, addr2 = startEtcdKVMockServer(t, key, etcdResponseKeys[format][false], nil)
+			switches           [][2]int
+		)
+		defer primarySvr.Stop()
+		defer failoverSvr.Stop()
+		opts := []xconf.EtcdLoaderOption{
+			xconf.EtcdLoaderWithEndpoints([]string{addr1}),
+			xconf.EtcdLoaderWithValueFormat(format),
+			xconf.EtcdLoaderWithFailoverClusters(
+				[][]string{{addr2}},
+				xconf.FailoverLoaderWithSwitchHandler(func(fromIdx, toIdx int, _ error) {
+					switches = append(switches, [2]int{fromIdx, toIdx})
+				}),
+			),
+		}
+		if withWatcher {
+			opts = append(opts, xconf.EtcdLoaderWithWatcher())
+		}
+		subject := xconf.NewEtcdLoader(key, opts...)
+		defer func() {
+			assertNil(t, subject.Close())
+		}()
+
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, getEtcdExpectedConfigMapByFormatAndPrefix(format, false), config)
+		assertEqual(t, [][2]int{{0, 1}}, switches)
+	}
 }
 
 func testEtcdLoaderByFormatAndPrefix(format string, withPrefix bool) func(t *testing.T) {
@@ -500,6 +604,212 @@ func testEtcdLoaderWithWatcher(t *testing.T) {
 	assertEqual(t, getEtcdExpectedConfigMapByFormatAndPrefix(format, withPrefix), config)
 }
 
+func testEtcdLoaderWithWatcherReestablishesWatching(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		format       = xconf.RemoteValuePlain
+		key          = etcdKeys[format]
+		content      = etcdResponseKeys[format][false]
+		watchCallCnt uint32
+		watchFn      = func(stream pb.Watch_WatchServer) error {
+			if atomic.AddUint32(&watchCallCnt, 1) > 1 {
+				return etcdWatchCreated(stream)
+			}
+			// first watch gets canceled.
+			if _, err := stream.Recv(); err != nil {
+				return err
+			}
+			for _, resp := range []*pb.WatchResponse{
+				{Header: &pb.ResponseHeader{Revision: 1}, Created: true},
+				{Header: &pb.ResponseHeader{Revision: 1}, Canceled: true},
+			} {
+				if err := stream.Send(resp); err != nil {
+					return err
+				}
+			}
+			<-stream.Context().Done()
+
+			return nil
+		}
+		healthErrs  = make(chan error, 2)
+		healthFn    = func(err error) { healthErrs <- err }
+		svr, addr   = startEtcdKVWatchMockServer(t, key, content, nil, watchFn)
+		ctx, cancel = context.WithTimeout(context.Background(), 15*time.Second)
+		subject     = xconf.NewEtcdLoader(
+			key,
+			xconf.EtcdLoaderWithEndpoints([]string{addr}),
+			xconf.EtcdLoaderWithContext(ctx),
+			xconf.EtcdLoaderWithValueFormat(format),
+			xconf.EtcdLoaderWithWatcher(),
+			xconf.EtcdLoaderWithWatcherBackoff(300*time.Millisecond, time.Second),
+			xconf.EtcdLoaderWithWatcherHealthHandler(healthFn),
+		)
+		expectedConfig = getEtcdExpectedConfigMapByFormatAndPrefix(format, false)
+	)
+	defer func() {
+		assertNil(t, subject.Close())
+		cancel()
+		svr.Stop()
+	}()
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, expectedConfig, config)
+
+	// act - wait for watching to stop.
+	err = <-healthErrs
+	config, err2 := subject.Load()
+
+	// assert
+	assertNotNil(t, err)
+	assertTrue(t, errors.Is(err2, xconf.ErrEtcdWatcherStale))
+	assertEqual(t, expectedConfig, config) // last known config is returned.
+
+	// act - wait for watching to be re-established.
+	err = <-healthErrs
+	config, err2 = subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertNil(t, err2)
+	assertEqual(t, expectedConfig, config)
+	assertEqual(t, uint32(2), atomic.LoadUint32(&watchCallCnt))
+}
+
+func testEtcdLoaderWithWatcherReturnsStaleErrOnCtxDone(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		format      = xconf.RemoteValuePlain
+		key         = etcdKeys[format]
+		content     = etcdResponseKeys[format][false]
+		healthErrs  = make(chan error, 1)
+		healthFn    = func(err error) { healthErrs <- err }
+		svr, addr   = startEtcdKVMockServer(t, key, content, nil)
+		ctx, cancel = context.WithCancel(context.Background())
+		subject     = xconf.NewEtcdLoader(
+			key,
+			xconf.EtcdLoaderWithEndpoints([]string{addr}),
+			xconf.EtcdLoaderWithContext(ctx),
+			xconf.EtcdLoaderWithValueFormat(format),
+			xconf.EtcdLoaderWithWatcher(),
+			xconf.EtcdLoaderWithWatcherHealthHandler(healthFn),
+		)
+	)
+	defer func() {
+		_ = subject.Close()
+		svr.Stop()
+	}()
+	_, err := subject.Load()
+	requireNil(t, err)
+
+	// act
+	cancel()
+	healthErr := <-healthErrs
+	_, err = subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(healthErr, context.Canceled))
+	assertTrue(t, errors.Is(err, xconf.ErrEtcdWatcherStale))
+	assertTrue(t, errors.Is(err, context.Canceled))
+}
+
+func testEtcdLoaderWithWatcherRecoversFromCompaction(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		format       = xconf.RemoteValuePlain
+		key          = etcdKeys[format]
+		content      = etcdResponseKeys[format][true]
+		rangeCallCnt uint32
+		rangeFn      = func(context.Context, *pb.RangeRequest) (*pb.RangeResponse, error) {
+			if atomic.AddUint32(&rangeCallCnt, 1) == 1 {
+				return &pb.RangeResponse{
+					Header: &pb.ResponseHeader{Revision: 3},
+					Kvs:    content,
+					Count:  int64(len(content)),
+				}, nil
+			}
+
+			// subkey was deleted and key was modified while revisions got compacted.
+			return &pb.RangeResponse{
+				Header: &pb.ResponseHeader{Revision: 10},
+				Kvs:    []*mvccpb.KeyValue{{Key: []byte(key), Value: []byte("2000")}},
+				Count:  1,
+			}, nil
+		}
+		watchCallCnt   uint32
+		startRevisions = make(chan int64, 2)
+		watchFn        = func(stream pb.Watch_WatchServer) error {
+			req, err := stream.Recv()
+			if err != nil {
+				return err
+			}
+			startRevisions <- req.GetCreateRequest().GetStartRevision()
+			resps := []*pb.WatchResponse{{Header: &pb.ResponseHeader{Revision: 10}, Created: true}}
+			if atomic.AddUint32(&watchCallCnt, 1) == 1 {
+				resps = append(resps, &pb.WatchResponse{
+					Header:          &pb.ResponseHeader{Revision: 10},
+					Canceled:        true,
+					CompactRevision: 8,
+				})
+			}
+			for _, resp := range resps {
+				if err := stream.Send(resp); err != nil {
+					return err
+				}
+			}
+			<-stream.Context().Done()
+
+			return nil
+		}
+		healthErrs  = make(chan error, 1)
+		healthFn    = func(err error) { healthErrs <- err }
+		svr, addr   = serveEtcdMock(t, &etcdKVServer{rangeCallback: rangeFn}, &etcdWatchServer{watchCallback: watchFn})
+		ctx, cancel = context.WithTimeout(context.Background(), 15*time.Second)
+		subject     = xconf.NewEtcdLoader(
+			key,
+			xconf.EtcdLoaderWithEndpoints([]string{addr}),
+			xconf.EtcdLoaderWithContext(ctx),
+			xconf.EtcdLoaderWithValueFormat(format),
+			xconf.EtcdLoaderWithPrefix(),
+			xconf.EtcdLoaderWithWatcher(),
+			xconf.EtcdLoaderWithWatcherHealthHandler(healthFn),
+		)
+	)
+	defer func() {
+		assertNil(t, subject.Close())
+		cancel()
+		svr.Stop()
+	}()
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, getEtcdExpectedConfigMapByFormatAndPrefix(format, true), config)
+	assertEqual(t, int64(4), <-startRevisions)
+
+	// act - wait for watching to be resumed after compaction.
+	startRevision := <-startRevisions
+	config, err = subject.Load()
+
+	// assert
+	assertEqual(t, int64(11), startRevision)
+	assertNil(t, err)
+	assertEqual(t, map[string]any{key: "2000"}, config)
+	assertEqual(t, uint32(2), atomic.LoadUint32(&rangeCallCnt))
+	assertEqual(t, 0, len(healthErrs)) // watching did not go stale.
+}
+
 func testEtcdLoaderReturnsSafeMutableConfigMap(t *testing.T) {
 	t.Parallel()
 
@@ -607,6 +917,7 @@ func benchmarkEtcdLoader(format string, withWatcher bool) func(b *testing.B) {
 		}
 		svr := grpc.NewServer()
 		pb.RegisterKVServer(svr, &kvSvr)
+		pb.RegisterWatchServer(svr, &etcdWatchServer{})
 		go func(svr *grpc.Server, l net.Listener) {
 			_ = svr.Serve(l)
 		}(svr, ln)
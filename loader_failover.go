@@ -0,0 +1,269 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/actforgood/xerr"
+)
+
+const (
+	// failoverDefaultCooldown is the default interval a failed loader is skipped for.
+	failoverDefaultCooldown = 30 * time.Second
+	// failoverLatencyWeight is the weight of the last measured latency in loaders' latency moving average.
+	failoverLatencyWeight = 0.3
+)
+
+// FailoverLoader is a composite loader that returns configuration from the first
+// healthy loader, in the order they were provided (like independent clusters of
+// a remote configuration store, from different regions).
+// A loader which fails is considered unhealthy and is skipped for a cooldown interval,
+// after which it gets probed again, at next Load (so a recovered primary source
+// is preferred again). If all healthy loaders fail, unhealthy ones are tried, too,
+// as a last resort.
+// Healthy loaders can be ordered by locality and / or measured latency, instead of
+// the given order, see [FailoverLoaderWithZones], [FailoverLoaderWithLatencyAwareness].
+// A panic occurred in a loader is recovered and returned as an error (see [ErrLoaderPanicked]).
+type FailoverLoader struct {
+	// loaders to load configuration from, in failover order.
+	loaders []Loader
+	// cooldown is the interval a failed loader is skipped for.
+	cooldown time.Duration
+	// switchHandler is an optional handler called when the active loader changes.
+	switchHandler func(fromIdx, toIdx int, err error)
+	// localZone is the zone the application runs in.
+	localZone string
+	// zones hold the zone of each loader.
+	zones []string
+	// latencyAware is a flag indicating whether loaders are ordered by measured latency.
+	latencyAware bool
+	// tiers hold the locality tier of each loader (0 - local zone, 1 - other zone), if zones are set.
+	tiers []int
+	// state holds loaders' health.
+	state *failoverState
+}
+
+// NewFailoverLoader instantiates a new FailoverLoader object that loads
+// configuration from the first healthy loader of the provided ones.
+//
+// Example:
+//
+//	loader := xconf.NewFailoverLoader([]xconf.Loader{
+//		xconf.NewConsulLoader("app/config", xconf.ConsulLoaderWithHost("http://consul.eu-west.example.com:8500")),
+//		xconf.NewConsulLoader("app/config", xconf.ConsulLoaderWithHost("http://consul.eu-central.example.com:8500")),
+//	})
+func NewFailoverLoader(loaders []Loader, opts ...FailoverLoaderOption) FailoverLoader {
+	loader := FailoverLoader{
+		loaders:  loaders,
+		cooldown: failoverDefaultCooldown,
+		state: &failoverState{
+			unhealthyUntil: make([]time.Time, len(loaders)),
+			latencies:      make([]time.Duration, len(loaders)),
+		},
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&loader)
+	}
+	if len(loader.zones) > 0 {
+		loader.tiers = make([]int, len(loaders))
+		for idx := range loader.tiers {
+			if idx >= len(loader.zones) || loader.zones[idx] != loader.localZone {
+				loader.tiers[idx] = 1
+			}
+		}
+	}
+
+	return loader
+}
+
+// Load returns the configuration key-value map of the first healthy loader,
+// or an error (containing all loaders' errors) if all of them fail.
+func (loader FailoverLoader) Load() (map[string]any, error) {
+	return loader.LoadContext(context.Background())
+}
+
+// LoadContext is like Load, passing given context to the encapsulated loaders.
+// If the context is done, its error is returned, without marking any loader as unhealthy.
+// It implements [ContextLoader].
+func (loader FailoverLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	var mErr *xerr.MultiError
+	for _, idx := range loader.state.order(time.Now(), loader.tiers, loader.latencyAware) {
+		start := time.Now()
+		configMap, err := safeLoad(ctx, loader.loaders[idx])
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				// loader failed because of the context, not because it is unhealthy.
+				return nil, ctxErr
+			}
+			mErr = mErr.Add(err)
+			loader.state.markUnhealthy(idx, time.Now().Add(loader.cooldown))
+
+			continue
+		}
+
+		fromIdx, switched := loader.state.markHealthy(idx, time.Since(start))
+		if switched && loader.switchHandler != nil {
+			loader.switchHandler(fromIdx, idx, mErr.ErrOrNil())
+		}
+
+		return configMap, nil
+	}
+
+	return nil, mErr.ErrOrNil()
+}
+
+// Close closes the encapsulated loaders which implement [io.Closer].
+// It implements [io.Closer].
+func (loader FailoverLoader) Close() error {
+	return CloseLoaders(loader)
+}
+
+// Unwrap returns the encapsulated loaders.
+func (loader FailoverLoader) Unwrap() []Loader {
+	return loader.loaders
+}
+
+// mapLoaders returns a copy of the loader, encapsulating the loaders returned by fn.
+func (loader FailoverLoader) mapLoaders(fn func(Loader) Loader) Loader {
+	loaders := make([]Loader, len(loader.loaders))
+	for idx, innerLoader := range loader.loaders {
+		loaders[idx] = fn(innerLoader)
+	}
+	loader.loaders = loaders
+
+	return loader
+}
+
+// FailoverLoaderOption defines optional function for configuring
+// a Failover Loader.
+type FailoverLoaderOption func(*FailoverLoader)
+
+// FailoverLoaderWithCooldown sets the interval a failed loader is skipped for.
+// By default, is set to 30s.
+func FailoverLoaderWithCooldown(cooldown time.Duration) FailoverLoaderOption {
+	return func(loader *FailoverLoader) {
+		if cooldown >= 0 {
+			loader.cooldown = cooldown
+		}
+	}
+}
+
+// FailoverLoaderWithSwitchHandler sets a handler to be called when the active loader changes
+// (with the indexes of the previous and the new active loader, and the errors which caused the switch, if any).
+// You can log the switch / expose a health check based on it, for example.
+func FailoverLoaderWithSwitchHandler(handler func(fromIdx, toIdx int, err error)) FailoverLoaderOption {
+	return func(loader *FailoverLoader) {
+		loader.switchHandler = handler
+	}
+}
+
+// FailoverLoaderWithZones sets the zone the application runs in, and the zone of each loader
+// (in the order loaders were provided), so that healthy loaders from the same zone
+// are preferred (reducing cross-zone traffic), falling back to the ones from other zones.
+// A loader with no zone provided is considered from another zone.
+//
+// Example:
+//
+//	xconf.NewConsulLoader(
+//		"app/config",
+//		xconf.ConsulLoaderWithHost("http://consul-a.example.com:8500"),
+//		xconf.ConsulLoaderWithFailoverHosts(
+//			[]string{"http://consul-b.example.com:8500", "http://consul-c.example.com:8500"},
+//			xconf.FailoverLoaderWithZones(os.Getenv("ZONE"), "eu-west-1a", "eu-west-1b", "eu-west-1c"),
+//			xconf.FailoverLoaderWithLatencyAwareness(),
+//		),
+//	)
+func FailoverLoaderWithZones(localZone string, zones ...string) FailoverLoaderOption {
+	return func(loader *FailoverLoader) {
+		loader.localZone = localZone
+		loader.zones = zones
+	}
+}
+
+// FailoverLoaderWithLatencyAwareness orders healthy loaders (within the same locality, if
+// [FailoverLoaderWithZones] is applied, too) by their measured latency (a moving average of their
+// successful loads' durations), instead of the given order, reducing tail latency of frequent reloads.
+// A loader whose latency was not measured yet is preferred, in order to get measured.
+func FailoverLoaderWithLatencyAwareness() FailoverLoaderOption {
+	return func(loader *FailoverLoader) {
+		loader.latencyAware = true
+	}
+}
+
+// failoverState holds the health of a FailoverLoader's loaders.
+type failoverState struct {
+	// unhealthyUntil holds, for each loader, the moment it can be probed again.
+	unhealthyUntil []time.Time
+	// latencies holds, for each loader, the moving average of its successful loads' durations.
+	latencies []time.Duration
+	// activeIdx is the index of the loader which provided last configuration.
+	activeIdx int
+	// mu is a concurrency semaphore.
+	mu sync.Mutex
+}
+
+// order returns the loaders' indexes in the order they should be tried:
+// the healthy ones first (ordered by locality tier, and latency, if requested), then the unhealthy ones.
+func (state *failoverState) order(now time.Time, tiers []int, byLatency bool) []int {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	idxs := make([]int, 0, len(state.unhealthyUntil))
+	for idx, until := range state.unhealthyUntil {
+		if !now.Before(until) {
+			idxs = append(idxs, idx)
+		}
+	}
+	if tiers != nil || byLatency {
+		sort.SliceStable(idxs, func(i, j int) bool {
+			if tiers != nil && tiers[idxs[i]] != tiers[idxs[j]] {
+				return tiers[idxs[i]] < tiers[idxs[j]]
+			}
+
+			return byLatency && state.latencies[idxs[i]] < state.latencies[idxs[j]]
+		})
+	}
+	for idx, until := range state.unhealthyUntil {
+		if now.Before(until) {
+			idxs = append(idxs, idx)
+		}
+	}
+
+	return idxs
+}
+
+// markUnhealthy marks a loader as unhealthy, until given moment.
+func (state *failoverState) markUnhealthy(idx int, until time.Time) {
+	state.mu.Lock()
+	state.unhealthyUntil[idx] = until
+	state.mu.Unlock()
+}
+
+// markHealthy marks a loader as healthy and active, updating its latency.
+// It returns the previous active loader's index, and whether the active loader changed.
+func (state *failoverState) markHealthy(idx int, latency time.Duration) (int, bool) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	state.unhealthyUntil[idx] = time.Time{}
+	if state.latencies[idx] == 0 {
+		state.latencies[idx] = latency
+	} else {
+		state.latencies[idx] = time.Duration(
+			failoverLatencyWeight*float64(latency) + (1-failoverLatencyWeight)*float64(state.latencies[idx]),
+		)
+	}
+	fromIdx := state.activeIdx
+	state.activeIdx = idx
+
+	return fromIdx, fromIdx != idx
+}
@@ -0,0 +1,292 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestFailoverLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - primary loader", testFailoverLoaderPrimary)
+	t.Run("success - fails over and back", testFailoverLoaderFailsOverAndBack)
+	t.Run("success - unhealthy loaders are tried as last resort", testFailoverLoaderTriesUnhealthyAsLastResort)
+	t.Run("error - all loaders fail", testFailoverLoaderReturnsErrWhenAllFail)
+	t.Run("error - canceled context does not mark loaders unhealthy", testFailoverLoaderReturnsCtxErr)
+	t.Run("success - same zone loader is preferred", testFailoverLoaderWithZones)
+	t.Run("success - fastest loader is preferred", testFailoverLoaderWithLatencyAwareness)
+}
+
+func testFailoverLoaderPrimary(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		primary   = &countingLoader{Loader: xconf.PlainLoader(map[string]any{"region": "eu-west"})}
+		secondary = &countingLoader{Loader: xconf.PlainLoader(map[string]any{"region": "eu-central"})}
+		subject   = xconf.NewFailoverLoader([]xconf.Loader{primary, secondary})
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"region": "eu-west"}, config)
+	assertEqual(t, 1, primary.LoadsCount())
+	assertEqual(t, 0, secondary.LoadsCount())
+}
+
+func testFailoverLoaderFailsOverAndBack(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		primaryDown int32 = 1
+		outageErr         = errors.New("intentionally triggered outage error")
+		primary           = &countingLoader{Loader: xconf.LoaderFunc(func() (map[string]any, error) {
+			if atomic.LoadInt32(&primaryDown) == 1 {
+				return nil, outageErr
+			}
+
+			return map[string]any{"region": "eu-west"}, nil
+		})}
+		secondary = &countingLoader{Loader: xconf.PlainLoader(map[string]any{"region": "eu-central"})}
+		switches  [][2]int
+		switchErr error
+		subject   = xconf.NewFailoverLoader(
+			[]xconf.Loader{primary, secondary},
+			xconf.FailoverLoaderWithCooldown(30*time.Millisecond),
+			xconf.FailoverLoaderWithSwitchHandler(func(fromIdx, toIdx int, err error) {
+				switches = append(switches, [2]int{fromIdx, toIdx})
+				switchErr = err
+			}),
+		)
+	)
+
+	// act - primary is down.
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"region": "eu-central"}, config)
+	assertEqual(t, [][2]int{{0, 1}}, switches)
+	assertTrue(t, errors.Is(switchErr, outageErr))
+
+	// act - primary is skipped during cooldown.
+	config, err = subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"region": "eu-central"}, config)
+	assertEqual(t, 1, primary.LoadsCount())
+	assertEqual(t, 2, secondary.LoadsCount())
+
+	// act - primary recovers, and it is probed after cooldown.
+	atomic.StoreInt32(&primaryDown, 0)
+	time.Sleep(40 * time.Millisecond)
+	config, err = subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"region": "eu-west"}, config)
+	assertEqual(t, 2, primary.LoadsCount())
+	assertEqual(t, 2, secondary.LoadsCount())
+	assertEqual(t, [][2]int{{0, 1}, {1, 0}}, switches)
+	assertNil(t, switchErr)
+}
+
+func testFailoverLoaderTriesUnhealthyAsLastResort(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loadsCnt uint32
+		primary  = xconf.LoaderFunc(func() (map[string]any, error) {
+			if atomic.AddUint32(&loadsCnt, 1) == 1 {
+				return nil, errors.New("intentionally triggered outage error")
+			}
+
+			return map[string]any{"region": "eu-west"}, nil
+		})
+		secondaryErr = errors.New("intentionally triggered secondary error")
+		secondary    = xconf.LoaderFunc(func() (map[string]any, error) {
+			return nil, secondaryErr
+		})
+		subject = xconf.NewFailoverLoader([]xconf.Loader{primary, secondary})
+	)
+	_, err := subject.Load()
+	assertNotNil(t, err)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"region": "eu-west"}, config)
+}
+
+func testFailoverLoaderReturnsErrWhenAllFail(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		err1    = errors.New("intentionally triggered error 1")
+		err2    = errors.New("intentionally triggered error 2")
+		subject = xconf.NewFailoverLoader([]xconf.Loader{
+			xconf.LoaderFunc(func() (map[string]any, error) { return nil, err1 }),
+			xconf.LoaderFunc(func() (map[string]any, error) { panic("intentionally triggered panic") }),
+			xconf.LoaderFunc(func() (map[string]any, error) { return nil, err2 }),
+		})
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, errors.Is(err, err1))
+	assertTrue(t, errors.Is(err, xconf.ErrLoaderPanicked))
+	assertTrue(t, errors.Is(err, err2))
+}
+
+func testFailoverLoaderReturnsCtxErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loadsCnt  [2]uint32
+		newLoader = func(idx int, region string) xconf.Loader {
+			return xconf.ContextLoaderFunc(func(ctx context.Context) (map[string]any, error) {
+				atomic.AddUint32(&loadsCnt[idx], 1)
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+
+				return map[string]any{"region": region}, nil
+			})
+		}
+		switched bool
+		subject  = xconf.NewFailoverLoader(
+			[]xconf.Loader{newLoader(0, "eu-west"), newLoader(1, "eu-central")},
+			xconf.FailoverLoaderWithSwitchHandler(func(int, int, error) {
+				switched = true
+			}),
+		)
+		ctx, cancelCtx = context.WithCancel(context.Background())
+	)
+	cancelCtx()
+
+	// act
+	config, err := subject.LoadContext(ctx)
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, errors.Is(err, context.Canceled))
+	assertEqual(t, uint32(0), atomic.LoadUint32(&loadsCnt[1]))
+
+	// act - primary is not skipped afterwards.
+	config, err = subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"region": "eu-west"}, config)
+	assertEqual(t, uint32(2), atomic.LoadUint32(&loadsCnt[0]))
+	assertEqual(t, uint32(0), atomic.LoadUint32(&loadsCnt[1]))
+	assertTrue(t, !switched)
+}
+
+func testFailoverLoaderWithZones(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		localZoneDown int32 = 1
+		zoneA               = xconf.LoaderFunc(func() (map[string]any, error) {
+			return map[string]any{"zone": "a"}, nil
+		})
+		zoneB = xconf.LoaderFunc(func() (map[string]any, error) {
+			if atomic.LoadInt32(&localZoneDown) == 1 {
+				return nil, errors.New("intentionally triggered outage error")
+			}
+
+			return map[string]any{"zone": "b"}, nil
+		})
+		zoneC   = xconf.PlainLoader(map[string]any{"zone": "c"})
+		subject = xconf.NewFailoverLoader(
+			[]xconf.Loader{zoneA, zoneB, zoneC},
+			xconf.FailoverLoaderWithZones("b", "a", "b", "c"),
+		)
+	)
+
+	// act - local zone is down, falls back to the other zones, in given order.
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"zone": "a"}, config)
+
+	// act - local zone is preferred, once it is healthy.
+	atomic.StoreInt32(&localZoneDown, 0)
+	subject = xconf.NewFailoverLoader(
+		[]xconf.Loader{zoneA, zoneB, zoneC},
+		xconf.FailoverLoaderWithZones("b", "a", "b", "c"),
+	)
+	config, err = subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"zone": "b"}, config)
+}
+
+func testFailoverLoaderWithLatencyAwareness(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	newLoader := func(latency time.Duration, value string) *countingLoader {
+		return &countingLoader{Loader: xconf.LoaderFunc(func() (map[string]any, error) {
+			time.Sleep(latency)
+
+			return map[string]any{"endpoint": value}, nil
+		})}
+	}
+	var (
+		slowLocal = newLoader(20*time.Millisecond, "slow-local")
+		fastLocal = newLoader(time.Millisecond, "fast-local")
+		remote    = newLoader(0, "remote")
+		subject   = xconf.NewFailoverLoader(
+			[]xconf.Loader{slowLocal, remote, fastLocal},
+			xconf.FailoverLoaderWithZones("a", "a", "b", "a"),
+			xconf.FailoverLoaderWithLatencyAwareness(),
+		)
+	)
+
+	// act - latencies get measured.
+	config1, err1 := subject.Load()
+	config2, err2 := subject.Load()
+	config3, err3 := subject.Load()
+	config4, err4 := subject.Load()
+
+	// assert
+	assertNil(t, err1)
+	assertNil(t, err2)
+	assertNil(t, err3)
+	assertNil(t, err4)
+	assertEqual(t, map[string]any{"endpoint": "slow-local"}, config1)
+	assertEqual(t, map[string]any{"endpoint": "fast-local"}, config2)
+	assertEqual(t, map[string]any{"endpoint": "fast-local"}, config3)
+	assertEqual(t, map[string]any{"endpoint": "fast-local"}, config4)
+	assertEqual(t, 1, slowLocal.LoadsCount())
+	assertEqual(t, 3, fastLocal.LoadsCount())
+	assertEqual(t, 0, remote.LoadsCount())
+}
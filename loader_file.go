@@ -6,37 +6,434 @@
 package xconf
 
 import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
 	"errors"
+	"io"
+	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/actforgood/xerr"
+	"github.com/klauspost/compress/zstd"
+	"golang.org/x/text/encoding"
+	"gopkg.in/ini.v1"
 )
 
 // ErrUnknownConfigFileExt is an error returned by [FileLoader] if file extension
 // does not match any supported format.
 var ErrUnknownConfigFileExt = errors.New("unknown configuration file extension")
 
+// ErrPathOutsideBaseDir is an error returned by [FileLoader] configured with
+// [FileLoaderWithBaseDir] if file's path (or the path a symlink points to) is outside base directory.
+var ErrPathOutsideBaseDir = errors.New("file path is outside base directory")
+
+// FileLoaderFactory returns the loader of the file located at given path.
+type FileLoaderFactory func(filePath string) Loader
+
+// fileLoaderFactories holds the custom file loader factories, by extension.
+var fileLoaderFactories struct {
+	factories map[string]FileLoaderFactory
+	mu        sync.RWMutex
+}
+
+// RegisterFileLoaderFactory registers a [FileLoaderFactory] for given file extension (like ".conf"),
+// used by [FileLoader] (and [Builder]'s File) for files having that extension.
+// It can plug in an application's own format, map an extension to a supported format,
+// or overwrite the default loader of a supported extension. A nil factory unregisters the extension.
+// Custom factories are not used for compressed / content sniffed files.
+//
+// Example, parsing ".conf" files as ini:
+//
+//	xconf.RegisterFileLoaderFactory(".conf", func(filePath string) xconf.Loader {
+//		return xconf.NewIniFileLoader(filePath)
+//	})
+func RegisterFileLoaderFactory(fileExtension string, factory FileLoaderFactory) {
+	if fileExtension != "" && !strings.HasPrefix(fileExtension, ".") {
+		fileExtension = "." + fileExtension
+	}
+
+	fileLoaderFactories.mu.Lock()
+	defer fileLoaderFactories.mu.Unlock()
+	if factory == nil {
+		delete(fileLoaderFactories.factories, fileExtension)
+
+		return
+	}
+	if fileLoaderFactories.factories == nil {
+		fileLoaderFactories.factories = make(map[string]FileLoaderFactory)
+	}
+	fileLoaderFactories.factories[fileExtension] = factory
+}
+
+// registeredFileLoaderFactory returns the custom factory for given extension, if any.
+func registeredFileLoaderFactory(fileExtension string) (FileLoaderFactory, bool) {
+	fileLoaderFactories.mu.RLock()
+	defer fileLoaderFactories.mu.RUnlock()
+	factory, found := fileLoaderFactories.factories[fileExtension]
+
+	return factory, found
+}
+
 // FileLoader is a factory for appropriate XFileLoader based on file's extension.
 // This is useful when you don't want to tie an application to a certain config format.
-// Supported extensions are: .json, .yml, .yaml, .ini, .properties, .env, .toml.
-func FileLoader(filePath string) Loader {
+// Supported extensions are: .json, .json5, .jsonc, .yml, .yaml, .ini, .properties, .env, .toml,
+// and the ones registered with [RegisterFileLoaderFactory].
+//
+// Compressed files are supported too, the format being given by the extension
+// preceding the compression one, like "config.yaml.gz".
+// Supported compression extensions are: .gz (gzip), .bz2 (bzip2), .zst (zstd).
+// See [FileLoaderWithDecompressor] for other compression algorithms.
+//
+// See [FileLoaderWithContentSniffing] for files with missing / unknown extension.
+func FileLoader(filePath string, opts ...FileLoaderOption) Loader {
+	loaderOpts := fileLoaderOptions{
+		decompressors: map[string]Decompressor{
+			".gz":  gzipDecompressor,
+			".bz2": bzip2Decompressor,
+			".zst": zstdDecompressor,
+		},
+	}
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&loaderOpts)
+	}
+
+	if loaderOpts.baseDir != "" {
+		return baseDirFileLoader(loaderOpts.baseDir, filePath, loaderOpts)
+	}
+
 	fileExtension := filepath.Ext(filePath)
+	if factory, found := registeredFileLoaderFactory(fileExtension); found {
+		return factory(filePath)
+	}
+	if decompressor, found := loaderOpts.decompressors[fileExtension]; found {
+		return compressedFileLoader(filePath, decompressor, loaderOpts)
+	}
+	if loader := fileLoaderByExt(fileExtension, filePath, loaderOpts.enc); loader != nil {
+		return loader
+	}
+	if loaderOpts.sniffContent {
+		return sniffedFileLoader(filePath, loaderOpts.enc)
+	}
+
+	return LoaderFunc(func() (map[string]any, error) {
+		return nil, ErrUnknownConfigFileExt
+	})
+}
+
+// baseDirFileLoader returns a loader which checks, each time it loads the configuration,
+// that file's path is inside base directory, before loading it.
+// A relative file path is considered relative to base directory.
+func baseDirFileLoader(baseDir, filePath string, opts fileLoaderOptions) Loader {
+	if !filepath.IsAbs(filePath) {
+		filePath = filepath.Join(baseDir, filePath)
+	}
+	opts.baseDir = ""
+	loader := FileLoader(filePath, func(loaderOpts *fileLoaderOptions) {
+		*loaderOpts = opts
+	})
+
+	return LoaderFunc(func() (map[string]any, error) {
+		if err := checkPathInBaseDir(baseDir, filePath); err != nil {
+			return nil, err
+		}
+
+		return loader.Load()
+	})
+}
+
+// checkPathInBaseDir checks that the path, both lexically and with symlinks resolved,
+// is inside base directory.
+func checkPathInBaseDir(baseDir, filePath string) error {
+	absBaseDir, err := filepath.Abs(baseDir)
+	if err != nil {
+		return err
+	}
+	absFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return err
+	}
+	if !isPathInDir(absBaseDir, absFilePath) {
+		return xerr.Wrapf(ErrPathOutsideBaseDir, "%q", filePath)
+	}
+
+	realBaseDir, err := filepath.EvalSymlinks(absBaseDir)
+	if err != nil {
+		return err
+	}
+	realFilePath, err := filepath.EvalSymlinks(absFilePath)
+	if err != nil {
+		return err
+	}
+	if !isPathInDir(realBaseDir, realFilePath) {
+		return xerr.Wrapf(ErrPathOutsideBaseDir, "%q resolves to %q", filePath, realFilePath)
+	}
+
+	return nil
+}
+
+// isPathInDir checks whether (absolute, cleaned) path is inside (absolute, cleaned) directory.
+func isPathInDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// fileLoaderByExt returns the loader for given extension, or nil if extension is not supported.
+// The source encoding, if any, is used for text formats (dotenv, properties, ini).
+func fileLoaderByExt(fileExtension, filePath string, enc encoding.Encoding) Loader {
 	switch fileExtension {
 	case ".json":
 		return JSONFileLoader(filePath)
+	case ".json5", ".jsonc":
+		return JSON5FileLoader(filePath)
 	case ".yml":
 		return YAMLFileLoader(filePath)
 	case ".yaml":
 		return YAMLFileLoader(filePath)
 	case ".env":
-		return DotEnvFileLoader(filePath)
+		return DotEnvFileLoader(filePath, DotEnvLoaderWithEncoding(enc))
 	case ".ini":
-		return NewIniFileLoader(filePath)
+		return NewIniFileLoader(filePath, IniFileLoaderWithEncoding(enc))
 	case ".toml":
 		return TOMLFileLoader(filePath)
 	case ".properties":
-		return PropertiesFileLoader(filePath)
+		return PropertiesFileLoader(filePath, PropertiesLoaderWithEncoding(enc))
 	}
 
+	return nil
+}
+
+// bytesLoaderByExt returns the loader of given content for given extension,
+// or nil if extension is not supported.
+// The source encoding, if any, is used for text formats (dotenv, properties, ini).
+func bytesLoaderByExt(fileExtension string, content []byte, enc encoding.Encoding) Loader {
+	switch fileExtension {
+	case ".json":
+		return JSONReaderLoader(bytes.NewReader(content))
+	case ".json5", ".jsonc":
+		return JSON5ReaderLoader(bytes.NewReader(content))
+	case ".yml", ".yaml":
+		return YAMLReaderLoader(bytes.NewReader(content))
+	case ".env":
+		return DotEnvReaderLoader(bytes.NewReader(content), DotEnvLoaderWithEncoding(enc))
+	case ".ini":
+		return LoaderFunc(func() (map[string]any, error) {
+			decodedContent, err := decodeBytes(content, enc)
+			if err != nil {
+				return nil, err
+			}
+
+			return loadIniConfigMap(ini.LoadOptions{}, decodedContent)
+		})
+	case ".toml":
+		return TOMLReaderLoader(bytes.NewReader(content))
+	case ".properties":
+		return PropertiesBytesLoader(content, PropertiesLoaderWithEncoding(enc))
+	}
+
+	return nil
+}
+
+// compressedFileLoader returns a loader which decompresses the file
+// and parses its content based on the extension preceding the compression one.
+func compressedFileLoader(filePath string, decompressor Decompressor, opts fileLoaderOptions) Loader {
+	fileExtension := filepath.Ext(strings.TrimSuffix(filePath, filepath.Ext(filePath)))
+
 	return LoaderFunc(func() (map[string]any, error) {
+		f, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		reader, err := decompressor(f)
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(reader)
+		if closer, ok := reader.(io.Closer); ok {
+			_ = closer.Close()
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		loader := bytesLoaderByExt(fileExtension, content, opts.enc)
+		if loader == nil && opts.sniffContent {
+			loader = bytesLoaderByExt(sniffConfigFormat(bytes.NewReader(content)), content, opts.enc)
+		}
+		if loader == nil {
+			return nil, ErrUnknownConfigFileExt
+		}
+
+		return loader.Load()
+	})
+}
+
+// sniffedFileLoader returns a loader which detects file's format from its content,
+// each time it loads the configuration.
+func sniffedFileLoader(filePath string, enc encoding.Encoding) Loader {
+	return LoaderFunc(func() (map[string]any, error) {
+		f, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		fileExtension := sniffConfigFormat(f)
+		_ = f.Close()
+
+		if loader := fileLoaderByExt(fileExtension, filePath, enc); loader != nil {
+			return loader.Load()
+		}
+
 		return nil, ErrUnknownConfigFileExt
 	})
 }
+
+// sniffMaxLines is the maximum number of lines inspected by sniffConfigFormat.
+const sniffMaxLines = 100
+
+// sniffConfigFormat detects content's format by inspecting its first meaningful lines.
+// It returns the extension associated with the format, or empty string if format
+// could not be detected:
+//   - a JSON object start "{" means .json;
+//   - a YAML document marker "---" or a "key: value" line means .yaml;
+//   - an ini section header "[section]" means .ini;
+//   - "key=value" lines (not followed by a section header) mean .env.
+func sniffConfigFormat(reader io.Reader) string {
+	var (
+		scanner = bufio.NewScanner(reader)
+		format  string
+	)
+	for lineNo := 0; lineNo < sniffMaxLines && scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if lineNo == 0 {
+			line = strings.TrimPrefix(line, "\ufeff") // UTF-8 BOM
+		}
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue // skip empty lines and comments.
+		}
+
+		switch {
+		case line[0] == '[' && strings.HasSuffix(line, "]"):
+			return ".ini" // ini can start with default section's key=value lines, see below.
+		case format != "":
+			continue
+		case line[0] == '{':
+			return ".json"
+		case strings.HasPrefix(line, "---"):
+			return ".yaml"
+		}
+		colonIdx := strings.Index(line, ":")
+		equalIdx := strings.Index(line, "=")
+		switch {
+		case colonIdx > 0 && (equalIdx < 0 || colonIdx < equalIdx):
+			return ".yaml"
+		case equalIdx > 0:
+			format = ".env" // keep looking for an ini section header.
+		default:
+			return ""
+		}
+	}
+
+	return format
+}
+
+// FileLoaderOption defines optional function for configuring
+// a FileLoader.
+type FileLoaderOption func(*fileLoaderOptions)
+
+// fileLoaderOptions holds FileLoader's configurable options.
+type fileLoaderOptions struct {
+	// sniffContent is a flag indicating whether to detect format from content.
+	sniffContent bool
+	// decompressors holds the decompression functions by compression extension.
+	decompressors map[string]Decompressor
+	// baseDir is the directory files are restricted to.
+	baseDir string
+	// enc is the source encoding of text formats' files, if not UTF-8.
+	enc encoding.Encoding
+}
+
+// Decompressor returns a reader of decompressed content of given reader.
+// If returned reader is an [io.Closer], it gets closed after content is read.
+type Decompressor func(io.Reader) (io.Reader, error)
+
+// gzipDecompressor is the [Decompressor] for gzip compressed content.
+func gzipDecompressor(reader io.Reader) (io.Reader, error) {
+	return gzip.NewReader(reader)
+}
+
+// bzip2Decompressor is the [Decompressor] for bzip2 compressed content.
+func bzip2Decompressor(reader io.Reader) (io.Reader, error) {
+	return bzip2.NewReader(reader), nil
+}
+
+// zstdDecompressor is the [Decompressor] for zstd compressed content.
+func zstdDecompressor(reader io.Reader) (io.Reader, error) {
+	dec, err := zstd.NewReader(reader, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+
+	return dec.IOReadCloser(), nil // closing it releases decoder's resources.
+}
+
+// FileLoaderWithContentSniffing enables format detection based on file's content,
+// when the extension is missing or unknown (for example for a file named just "config").
+// Detected formats are JSON (object), YAML, ini and dotenv (key=value).
+// If format cannot be detected, [ErrUnknownConfigFileExt] is returned.
+func FileLoaderWithContentSniffing() FileLoaderOption {
+	return func(opts *fileLoaderOptions) {
+		opts.sniffContent = true
+	}
+}
+
+// FileLoaderWithDecompressor registers a [Decompressor] for given compression extension
+// (like ".xz"), or overwrites the default one for ".gz" / ".bz2" / ".zst".
+//
+// Example, with xz from github.com/ulikunitz/xz:
+//
+//	loader := xconf.FileLoader(
+//		"config.yaml.xz",
+//		xconf.FileLoaderWithDecompressor(".xz", func(r io.Reader) (io.Reader, error) {
+//			return xz.NewReader(r)
+//		}),
+//	)
+func FileLoaderWithDecompressor(compressionExt string, decompressor Decompressor) FileLoaderOption {
+	return func(opts *fileLoaderOptions) {
+		opts.decompressors[compressionExt] = decompressor
+	}
+}
+
+// FileLoaderWithBaseDir restricts the file to be inside given base directory, rejecting
+// paths escaping it through ".." elements or symlinks (checked each time configuration is loaded).
+// A relative file path is considered relative to base directory.
+// Use it when file's path is user supplied (for example, from a CLI flag), so that
+// it can't be abused to read arbitrary files, in multi-tenant environments.
+// If the check fails, [ErrPathOutsideBaseDir] is returned.
+//
+// Note: the check and the file's opening are not atomic, so the file must not be located
+// in a directory writable by untrusted users.
+func FileLoaderWithBaseDir(baseDir string) FileLoaderOption {
+	return func(opts *fileLoaderOptions) {
+		opts.baseDir = baseDir
+	}
+}
+
+// FileLoaderWithEncoding sets the source encoding of the file, for text formats
+// (dotenv, properties, ini), content being transcoded to UTF-8. By default, UTF-8 is assumed.
+// Use [NormalizedTextEncoding] to also strip BOM and normalize CRLF line endings,
+// for files edited on Windows hosts.
+func FileLoaderWithEncoding(enc encoding.Encoding) FileLoaderOption {
+	return func(opts *fileLoaderOptions) {
+		opts.enc = enc
+	}
+}
@@ -6,8 +6,13 @@
 package xconf_test
 
 import (
+	"compress/gzip"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/actforgood/xconf"
@@ -25,7 +30,16 @@ func TestFileLoader(t *testing.T) {
 	t.Run("success - with .ini", testFileLoaderWithIni)
 	t.Run("success - with .toml", testFileLoaderWithTOML)
 	t.Run("success - with .properties", testFileLoaderWithProperties)
+	t.Run("success - with .json5, .jsonc", testFileLoaderWithJSON5)
+	t.Run("success - with compressed files", testFileLoaderWithCompressedFiles)
+	t.Run("success - with custom decompressor", testFileLoaderWithCustomDecompressor)
 	t.Run("error - unknown extension", testFileLoaderWithUnknownExt)
+	t.Run("success - with content sniffing", testFileLoaderWithContentSniffing)
+	t.Run("error - with content sniffing, unknown format", testFileLoaderWithContentSniffingUnknownFormat)
+	t.Run("success - with base dir", testFileLoaderWithBaseDir)
+	t.Run("error - with base dir, path outside it", testFileLoaderWithBaseDirPathOutside)
+	t.Run("success - with encoding", testFileLoaderWithEncoding)
+	t.Run("success - with registered factory", testFileLoaderWithRegisteredFactory)
 }
 
 func testFileLoaderWithJSON(t *testing.T) {
@@ -126,6 +140,95 @@ func testFileLoaderWithProperties(t *testing.T) {
 	assertEqual(t, propertiesConfigMap, config)
 }
 
+func testFileLoaderWithJSON5(t *testing.T) {
+	t.Parallel()
+
+	for _, filePath := range []string{json5FilePath, jsoncFilePath} {
+		// arrange
+		subject := xconf.FileLoader(filePath)
+
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, jsonConfigMap, config)
+	}
+}
+
+func testFileLoaderWithCompressedFiles(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tests := [...]struct {
+		name           string
+		filePath       string
+		expectedResult map[string]any
+	}{
+		{
+			name:           ".yaml.gz",
+			filePath:       "testdata/config.yaml.gz",
+			expectedResult: yamlConfigMap,
+		},
+		{
+			name:           ".ini.gz",
+			filePath:       "testdata/config.ini.gz",
+			expectedResult: iniConfigMap,
+		},
+		{
+			name:           ".json.bz2",
+			filePath:       "testdata/config.json.bz2",
+			expectedResult: jsonConfigMap,
+		},
+		{
+			name:           ".yaml.zst",
+			filePath:       "testdata/config.yaml.zst",
+			expectedResult: yamlConfigMap,
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			subject := xconf.FileLoader(test.filePath)
+
+			// act
+			config, err := subject.Load()
+
+			// assert
+			assertNil(t, err)
+			assertEqual(t, test.expectedResult, config)
+		})
+	}
+}
+
+func testFileLoaderWithCustomDecompressor(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	content, err := os.ReadFile("testdata/config.yaml.gz")
+	requireNil(t, err)
+	dirPath := t.TempDir()
+	filePath := filepath.Join(dirPath, "config.custom")
+	requireNil(t, os.WriteFile(filePath, content, 0o600))
+	subject := xconf.FileLoader(
+		filePath,
+		xconf.FileLoaderWithDecompressor(".custom", func(r io.Reader) (io.Reader, error) {
+			return gzip.NewReader(r)
+		}),
+		xconf.FileLoaderWithContentSniffing(), // "config" has no extension
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, yamlConfigMap, config)
+}
+
 func testFileLoaderWithUnknownExt(t *testing.T) {
 	t.Parallel()
 
@@ -168,6 +271,174 @@ func testFileLoaderWithUnknownExt(t *testing.T) {
 	}
 }
 
+func testFileLoaderWithContentSniffing(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tests := [...]struct {
+		name           string
+		sourceFilePath string
+		expectedResult map[string]any
+	}{
+		{
+			name:           "json",
+			sourceFilePath: jsonFilePath,
+			expectedResult: jsonConfigMap,
+		},
+		{
+			name:           "yaml",
+			sourceFilePath: yamlFilePath,
+			expectedResult: yamlConfigMap,
+		},
+		{
+			name:           "ini",
+			sourceFilePath: iniFilePath,
+			expectedResult: iniConfigMap,
+		},
+		{
+			name:           "dotenv",
+			sourceFilePath: dotEnvFilePath,
+			expectedResult: dotEnvConfigMap,
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			content, err := os.ReadFile(test.sourceFilePath)
+			requireNil(t, err)
+			filePath := filepath.Join(t.TempDir(), "config")
+			requireNil(t, os.WriteFile(filePath, content, 0o600))
+			subject := xconf.FileLoader(filePath, xconf.FileLoaderWithContentSniffing())
+
+			// act
+			config, err := subject.Load()
+
+			// assert
+			assertNil(t, err)
+			assertEqual(t, test.expectedResult, config)
+		})
+	}
+}
+
+func testFileLoaderWithContentSniffingUnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	filePath := filepath.Join(t.TempDir(), "config.txt")
+	requireNil(t, os.WriteFile(filePath, []byte("# some comment\nsome text"), 0o600))
+	subject := xconf.FileLoader(filePath, xconf.FileLoaderWithContentSniffing())
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, errors.Is(err, xconf.ErrUnknownConfigFileExt))
+}
+
+func testFileLoaderWithBaseDir(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	baseDir := t.TempDir()
+	requireNil(t, os.Mkdir(filepath.Join(baseDir, "app"), 0o700))
+	requireNil(t, os.WriteFile(filepath.Join(baseDir, "app", "config.json"), []byte(`{"foo": "bar"}`), 0o600))
+	requireSymlink(t, filepath.Join(baseDir, "app", "config.json"), filepath.Join(baseDir, "link.json"))
+	filePaths := [...]string{
+		"app/config.json",
+		"app/../app/config.json",
+		filepath.Join(baseDir, "app", "config.json"),
+		"link.json", // symlink inside base dir.
+	}
+
+	for _, filePath := range filePaths {
+		subject := xconf.FileLoader(filePath, xconf.FileLoaderWithBaseDir(baseDir))
+
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, map[string]any{"foo": "bar"}, config)
+	}
+}
+
+func testFileLoaderWithBaseDirPathOutside(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		baseDir     = t.TempDir()
+		outsideDir  = t.TempDir()
+		outsideFile = filepath.Join(outsideDir, "secrets.json")
+	)
+	requireNil(t, os.WriteFile(outsideFile, []byte(`{"password": "secret"}`), 0o600))
+	requireSymlink(t, outsideFile, filepath.Join(baseDir, "link.json"))
+	requireSymlink(t, outsideDir, filepath.Join(baseDir, "linkdir"))
+	tests := [...]struct {
+		name     string
+		filePath string
+	}{
+		{name: "dot dot escape", filePath: filepath.Join("..", filepath.Base(outsideDir), "secrets.json")},
+		{name: "absolute path", filePath: outsideFile},
+		{name: "file symlink escape", filePath: "link.json"},
+		{name: "dir symlink escape", filePath: filepath.Join("linkdir", "secrets.json")},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := xconf.FileLoader(test.filePath, xconf.FileLoaderWithBaseDir(baseDir))
+
+			// act
+			config, err := subject.Load()
+
+			// assert
+			assertNil(t, config)
+			assertTrue(t, errors.Is(err, xconf.ErrPathOutsideBaseDir))
+		})
+	}
+}
+
+func testFileLoaderWithEncoding(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	dir := t.TempDir()
+	content := "\ufefffoo=bar\r\nyear=2022\r\n"
+	for _, fileName := range [...]string{"config.env", "config.properties", "config.ini"} {
+		filePath := filepath.Join(dir, fileName)
+		requireNil(t, os.WriteFile(filePath, []byte(content), 0o600))
+		subject := xconf.FileLoader(filePath, xconf.FileLoaderWithEncoding(xconf.NormalizedTextEncoding()))
+
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, map[string]any{"foo": "bar", "year": "2022"}, config)
+	}
+}
+
+// requireSymlink creates a symlink, skipping the test if symlinks are not supported
+// (like on Windows, without developer mode / privileges).
+func requireSymlink(t *testing.T, oldName, newName string) {
+	t.Helper()
+
+	if err := os.Symlink(oldName, newName); err != nil {
+		if runtime.GOOS == "windows" {
+			t.Skip("symlinks are not supported", err)
+		}
+		t.Fatal(err)
+	}
+}
+
 func ExampleFileLoader() {
 	exampleFiles := []string{
 		"testdata/config.json",
@@ -196,3 +467,32 @@ func ExampleFileLoader() {
 	// 4
 	// 7
 }
+
+func testFileLoaderWithRegisteredFactory(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	content, err := os.ReadFile(iniFilePath)
+	requireNil(t, err)
+	confFilePath := filepath.Join(t.TempDir(), "config.xconftest")
+	requireNil(t, os.WriteFile(confFilePath, content, 0o600))
+	factoryCallsCnt := 0
+	xconf.RegisterFileLoaderFactory("xconftest", func(filePath string) xconf.Loader {
+		factoryCallsCnt++
+
+		return xconf.NewIniFileLoader(filePath)
+	})
+	subject := xconf.FileLoader(confFilePath)
+
+	// act
+	config, err := subject.Load()
+	xconf.RegisterFileLoaderFactory(".xconftest", nil)
+	unregisteredConfig, unregisteredErr := xconf.FileLoader(confFilePath).Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, iniConfigMap, config)
+	assertEqual(t, 1, factoryCallsCnt)
+	assertTrue(t, errors.Is(unregisteredErr, xconf.ErrUnknownConfigFileExt))
+	assertNil(t, unregisteredConfig)
+}
@@ -0,0 +1,65 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestFileLoader_windows(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - drive letter paths", testFileLoaderWithDriveLetterPaths)
+	t.Run("error - with base dir, path on another volume", testFileLoaderWithBaseDirPathOnAnotherVolume)
+}
+
+func testFileLoaderWithDriveLetterPaths(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	absFilePath, err := filepath.Abs(jsonFilePath)
+	requireNil(t, err)
+	filePaths := [...]string{
+		absFilePath,                   // like C:\path\to\testdata\config.json
+		filepath.ToSlash(absFilePath), // like C:/path/to/testdata/config.json
+		strings.ToLower(absFilePath[:1]) + absFilePath[1:], // like c:\path\to\testdata\config.json
+	}
+
+	for _, filePath := range filePaths {
+		subject := xconf.FileLoader(filePath, xconf.FileLoaderWithBaseDir(filepath.Dir(absFilePath)))
+
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, jsonConfigMap, config)
+	}
+}
+
+func testFileLoaderWithBaseDirPathOnAnotherVolume(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	baseDir := t.TempDir()
+	volume := "Z:"
+	if strings.EqualFold(filepath.VolumeName(baseDir), volume) {
+		volume = "Y:"
+	}
+	subject := xconf.FileLoader(volume+`\config.json`, xconf.FileLoaderWithBaseDir(baseDir))
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, errors.Is(err, xconf.ErrPathOutsideBaseDir))
+}
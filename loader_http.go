@@ -0,0 +1,161 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ErrHTTPConfigNotFound is returned by [HTTPLoader] when the configuration document is not found.
+var ErrHTTPConfigNotFound = errors.New("404 - http configuration not found")
+
+// HTTPLoader loads configuration from a JSON / YAML document served over HTTP(S).
+// Document's ETag is used in subsequent loads (If-None-Match), thus an unchanged document
+// is not downloaded and parsed again.
+//
+// On js/wasm (browser), requests are made with the Fetch API, so the same configuration
+// layer can be reused by Go WASM frontends.
+type HTTPLoader struct {
+	url         string       // the document's url
+	valueFormat string       // document's format, RemoteValueJSON or RemoteValueYAML
+	headers     http.Header  // extra request headers
+	httpClient  *http.Client // the http client used for calls
+	ctx         context.Context
+	cache       *httpCache // ETag cache
+}
+
+// httpCache holds the last loaded document's ETag and configuration.
+type httpCache struct {
+	mu        sync.RWMutex
+	etag      string
+	configMap map[string]any
+}
+
+// NewHTTPLoader instantiates a new HTTPLoader object that loads
+// configuration from the document served at given url.
+func NewHTTPLoader(url string, opts ...HTTPLoaderOption) HTTPLoader {
+	loader := HTTPLoader{
+		url:         url,
+		valueFormat: RemoteValueJSON,
+		headers:     make(http.Header),
+		httpClient:  newDefaultHTTPClient(),
+		ctx:         context.Background(),
+		cache:       new(httpCache),
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&loader)
+	}
+
+	return loader
+}
+
+// Load returns a configuration key-value map from the HTTP document, or an error
+// if something bad happens along the process.
+func (loader HTTPLoader) Load() (map[string]any, error) {
+	return loader.LoadContext(loader.ctx)
+}
+
+// LoadContext is like Load, with the request(s) being canceled also when given context is done.
+// It implements [ContextLoader].
+func (loader HTTPLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	ctx, cancelCtx := mergeContexts(ctx, loader.ctx)
+	defer cancelCtx()
+	loader.ctx = ctx // Note: loader is a copy.
+
+	req, err := http.NewRequestWithContext(loader.ctx, http.MethodGet, loader.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Go-ActForGood-Xconf/1.0")
+	for hName, hValues := range loader.headers {
+		req.Header[hName] = hValues
+	}
+	loader.cache.mu.RLock()
+	etag := loader.cache.etag
+	loader.cache.mu.RUnlock()
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := loader.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer closeResponseBody(resp)
+
+	if resp.StatusCode == http.StatusNotModified && etag != "" {
+		loader.cache.mu.RLock()
+		defer loader.cache.mu.RUnlock()
+
+		return DeepCopyConfigMap(loader.cache.configMap), nil
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrHTTPConfigNotFound
+	default:
+		return nil, fmt.Errorf("http configuration responded with status code %d", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	configMap, err := getRemoteKVPairConfigMap("", content, loader.valueFormat)
+	if err != nil {
+		return nil, err
+	}
+	loader.cache.mu.Lock()
+	loader.cache.etag = resp.Header.Get("ETag")
+	loader.cache.configMap = DeepCopyConfigMap(configMap)
+	loader.cache.mu.Unlock()
+
+	return configMap, nil
+}
+
+// HTTPLoaderOption defines optional function for configuring
+// a HTTP Loader.
+type HTTPLoaderOption func(*HTTPLoader)
+
+// HTTPLoaderWithValueFormat sets the document's format, [RemoteValueJSON] or [RemoteValueYAML].
+// By default, is set to [RemoteValueJSON].
+func HTTPLoaderWithValueFormat(valueFormat string) HTTPLoaderOption {
+	return func(loader *HTTPLoader) {
+		if valueFormat == RemoteValueJSON || valueFormat == RemoteValueYAML {
+			loader.valueFormat = valueFormat
+		}
+	}
+}
+
+// HTTPLoaderWithHeader sets a request header, like "Authorization".
+func HTTPLoaderWithHeader(name, value string) HTTPLoaderOption {
+	return func(loader *HTTPLoader) {
+		loader.headers.Set(name, value)
+	}
+}
+
+// HTTPLoaderWithHTTPClient sets the http client used for calls.
+// A default one is provided if you don't use this option.
+func HTTPLoaderWithHTTPClient(client *http.Client) HTTPLoaderOption {
+	return func(loader *HTTPLoader) {
+		loader.httpClient = client
+	}
+}
+
+// HTTPLoaderWithContext sets request 's context.
+// By default, a context.Background() is used.
+func HTTPLoaderWithContext(ctx context.Context) HTTPLoaderOption {
+	return func(loader *HTTPLoader) {
+		loader.ctx = ctx
+	}
+}
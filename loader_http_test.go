@@ -0,0 +1,119 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestHTTPLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - json with etag caching", testHTTPLoaderJSONWithETag)
+	t.Run("success - yaml with header", testHTTPLoaderYAMLWithHeader)
+	t.Run("error - not found", testHTTPLoaderReturnsErrNotFound)
+	t.Run("error - unexpected status code", testHTTPLoaderReturnsErrStatusCode)
+}
+
+func testHTTPLoaderJSONWithETag(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		downloadsCnt uint32
+		svr          = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+
+				return
+			}
+			atomic.AddUint32(&downloadsCnt, 1)
+			w.Header().Set("ETag", `"v1"`)
+			_, _ = w.Write([]byte(`{"foo":"bar","year":2022}`))
+		}))
+		subject = xconf.NewHTTPLoader(svr.URL + "/config.json")
+	)
+	defer svr.Close()
+
+	for i := 0; i < 3; i++ {
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, map[string]any{"foo": "bar", "year": float64(2022)}, config)
+		config["foo"] = "modified" // cached configuration should not be affected.
+	}
+	assertEqual(t, uint32(1), atomic.LoadUint32(&downloadsCnt))
+}
+
+func testHTTPLoaderYAMLWithHeader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer my-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+		_, _ = w.Write([]byte("foo: bar\nyear: 2022\n"))
+	}))
+	defer svr.Close()
+	subject := xconf.NewHTTPLoader(
+		svr.URL+"/config.yaml",
+		xconf.HTTPLoaderWithValueFormat(xconf.RemoteValueYAML),
+		xconf.HTTPLoaderWithHeader("Authorization", "Bearer my-token"),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"foo": "bar", "year": 2022}, config)
+}
+
+func testHTTPLoaderReturnsErrNotFound(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	svr := httptest.NewServer(http.NotFoundHandler())
+	defer svr.Close()
+	subject := xconf.NewHTTPLoader(svr.URL + "/config.json")
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrHTTPConfigNotFound))
+	assertNil(t, config)
+}
+
+func testHTTPLoaderReturnsErrStatusCode(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer svr.Close()
+	subject := xconf.NewHTTPLoader(svr.URL+"/config.json", xconf.HTTPLoaderWithHTTPClient(http.DefaultClient))
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNotNil(t, err)
+	assertEqual(t, "http configuration responded with status code 500", err.Error())
+	assertNil(t, config)
+}
@@ -6,6 +6,10 @@
 package xconf
 
 import (
+	"context"
+	"os"
+
+	"golang.org/x/text/encoding"
 	"gopkg.in/ini.v1"
 )
 
@@ -16,6 +20,8 @@ type IniFileLoader struct {
 	filePath string
 	// loadOpts are the original package parse options.
 	loadOpts ini.LoadOptions
+	// enc is the source encoding of the file, if not UTF-8.
+	enc encoding.Encoding
 }
 
 // NewIniFileLoader instantiates a new IniFileLoader object that loads
@@ -38,7 +44,35 @@ func NewIniFileLoader(filePath string, opts ...IniFileLoaderOption) IniFileLoade
 // Load returns a configuration key-value map from a INI file,
 // or an error if something bad happens along the process.
 func (loader IniFileLoader) Load() (map[string]any, error) {
-	cfg, err := ini.LoadSources(loader.loadOpts, loader.filePath)
+	if loader.enc == nil {
+		return loadIniConfigMap(loader.loadOpts, loader.filePath)
+	}
+
+	content, err := os.ReadFile(loader.filePath)
+	if err != nil {
+		return nil, err
+	}
+	content, err = decodeBytes(content, loader.enc)
+	if err != nil {
+		return nil, err
+	}
+
+	return loadIniConfigMap(loader.loadOpts, content)
+}
+
+// LoadContext calls Load, if ctx is not done. It implements [ContextLoader].
+func (loader IniFileLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return loader.Load()
+}
+
+// loadIniConfigMap parses given ini source (file path / content bytes)
+// into a configuration map.
+func loadIniConfigMap(loadOpts ini.LoadOptions, source any) (map[string]any, error) {
+	cfg, err := ini.LoadSources(loadOpts, source)
 	if err != nil {
 		return nil, err
 	}
@@ -73,3 +107,12 @@ func IniFileLoaderWithLoadOptions(iniLoadOpts ini.LoadOptions) IniFileLoaderOpti
 		loader.loadOpts = iniLoadOpts
 	}
 }
+
+// IniFileLoaderWithEncoding sets the source encoding of the file, for example
+// [golang.org/x/text/encoding/charmap.ISO8859_1]. Content is transcoded to UTF-8.
+// By default, UTF-8 is assumed.
+func IniFileLoaderWithEncoding(enc encoding.Encoding) IniFileLoaderOption {
+	return func(loader *IniFileLoader) {
+		loader.enc = enc
+	}
+}
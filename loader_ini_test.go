@@ -8,9 +8,11 @@ package xconf_test
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/actforgood/xconf"
+	"golang.org/x/text/encoding/charmap"
 	"gopkg.in/ini.v1"
 )
 
@@ -35,6 +37,23 @@ func TestIniFileLoader_withValidFile(t *testing.T) {
 	t.Run("error - not found file", testIniFileLoaderWithNotFoundFile)
 	t.Run("success - custom ini load options applied", testIniFileLoaderWithCustomIniLoadOptions)
 	t.Run("success - safe-mutable config map", testIniFileLoaderReturnsSafeMutableConfigMap)
+	t.Run("success - latin-1 encoded file", testIniFileLoaderWithLatin1File)
+}
+
+func testIniFileLoaderWithLatin1File(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	filePath := filepath.Join(t.TempDir(), "config.ini")
+	requireNil(t, os.WriteFile(filePath, []byte("[city]\nname=Z\xfcrich"), 0o600))
+	subject := xconf.NewIniFileLoader(filePath, xconf.IniFileLoaderWithEncoding(charmap.ISO8859_1))
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"city": map[string]any{"name": "Zürich"}}, config)
 }
 
 func testIniFileLoaderWithValidFile(t *testing.T) {
@@ -0,0 +1,270 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidJSON5 is an error returned by [JSON5ReaderLoader] if content
+// cannot be converted to standard JSON.
+var ErrInvalidJSON5 = errors.New("invalid json5 content")
+
+// JSON5FileLoader loads JSON5 (and thus JSONC) configuration from a file.
+// The location of JSON5 content based file is given as parameter.
+func JSON5FileLoader(filePath string) Loader {
+	return LoaderFunc(func() (map[string]any, error) {
+		f, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		return JSON5ReaderLoader(f).Load()
+	})
+}
+
+// JSON5ReaderLoader loads JSON5 (and thus JSONC) configuration from an [io.Reader].
+// Supported JSON5 features, on top of JSON, are:
+//   - single-line and multi-line comments;
+//   - trailing commas;
+//   - unquoted (identifier) object keys;
+//   - single quoted strings, and multi-line strings (escaped new lines);
+//   - hexadecimal numbers, leading / trailing decimal point, explicit plus sign.
+//
+// Infinity and NaN are not supported, as they cannot be represented in JSON.
+func JSON5ReaderLoader(reader io.Reader) Loader {
+	return LoaderFunc(func() (map[string]any, error) {
+		if seekReader, ok := reader.(io.Seeker); ok {
+			_, _ = seekReader.Seek(0, io.SeekStart) // move to the beginning in case of a re-load needed.
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		jsonContent, err := json5ToJSON(content)
+		if err != nil {
+			return nil, err
+		}
+
+		return JSONReaderLoader(bytes.NewReader(jsonContent)).Load()
+	})
+}
+
+// json5ToJSON converts JSON5 content to standard JSON.
+func json5ToJSON(content []byte) ([]byte, error) {
+	out := make([]byte, 0, len(content))
+	for idx := 0; idx < len(content); {
+		char := content[idx]
+		switch {
+		case char == '/':
+			next, err := skipJSON5Comment(content, idx)
+			if err != nil {
+				return nil, err
+			}
+			idx = next
+		case char == '"' || char == '\'':
+			next, str, err := convertJSON5String(content, idx)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, str...)
+			idx = next
+		case char == ',':
+			next, err := skipJSON5Insignificant(content, idx+1)
+			if err != nil {
+				return nil, err
+			}
+			if next >= len(content) || (content[next] != '}' && content[next] != ']') {
+				out = append(out, char) // keep only non-trailing commas.
+			}
+			idx++
+		case isJSON5IdentifierStart(char):
+			end := idx + 1
+			for end < len(content) && isJSON5IdentifierPart(content[end]) {
+				end++
+			}
+			identifier := string(content[idx:end])
+			switch identifier {
+			case "true", "false", "null":
+				out = append(out, identifier...)
+			case "Infinity", "NaN":
+				return nil, ErrInvalidJSON5
+			default: // unquoted key.
+				out = append(out, strconv.Quote(identifier)...)
+			}
+			idx = end
+		case char == '+' || char == '-' || char == '.' || (char >= '0' && char <= '9'):
+			next, number, err := convertJSON5Number(content, idx)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, number...)
+			idx = next
+		default:
+			out = append(out, char)
+			idx++
+		}
+	}
+
+	return out, nil
+}
+
+// skipJSON5Comment returns the index after the comment starting at given index.
+func skipJSON5Comment(content []byte, idx int) (int, error) {
+	if idx+1 >= len(content) {
+		return 0, ErrInvalidJSON5
+	}
+	switch content[idx+1] {
+	case '/':
+		end := bytes.IndexByte(content[idx:], '\n')
+		if end < 0 {
+			return len(content), nil
+		}
+
+		return idx + end, nil // keep the new line.
+	case '*':
+		end := bytes.Index(content[idx+2:], []byte("*/"))
+		if end < 0 {
+			return 0, ErrInvalidJSON5
+		}
+
+		return idx + 2 + end + 2, nil
+	}
+
+	return 0, ErrInvalidJSON5
+}
+
+// skipJSON5Insignificant returns the index of the next character which is
+// not a white space or part of a comment.
+func skipJSON5Insignificant(content []byte, idx int) (int, error) {
+	for idx < len(content) {
+		switch content[idx] {
+		case ' ', '\t', '\n', '\r':
+			idx++
+		case '/':
+			next, err := skipJSON5Comment(content, idx)
+			if err != nil {
+				return 0, err
+			}
+			idx = next
+		default:
+			return idx, nil
+		}
+	}
+
+	return idx, nil
+}
+
+// convertJSON5String converts the (single or double quoted) string starting
+// at given index to a JSON string. It returns the index after the string.
+func convertJSON5String(content []byte, idx int) (int, []byte, error) {
+	quote := content[idx]
+	out := []byte{'"'}
+	for idx++; idx < len(content); idx++ {
+		char := content[idx]
+		switch {
+		case char == quote:
+			return idx + 1, append(out, '"'), nil
+		case char == '"':
+			out = append(out, '\\', '"')
+		case char == '\\':
+			idx++
+			if idx >= len(content) {
+				return 0, nil, ErrInvalidJSON5
+			}
+			switch escaped := content[idx]; escaped {
+			case '\n': // line continuation.
+			case '\r':
+				if idx+1 < len(content) && content[idx+1] == '\n' {
+					idx++
+				}
+			case '\'':
+				out = append(out, '\'')
+			case '0':
+				out = append(out, `\u0000`...)
+			case 'v':
+				out = append(out, `\u000b`...)
+			case 'x':
+				if idx+2 >= len(content) {
+					return 0, nil, ErrInvalidJSON5
+				}
+				out = append(out, `\u00`...)
+				out = append(out, content[idx+1:idx+3]...)
+				idx += 2
+			default:
+				out = append(out, '\\', escaped)
+			}
+		default:
+			out = append(out, char)
+		}
+	}
+
+	return 0, nil, ErrInvalidJSON5
+}
+
+// convertJSON5Number converts the number starting at given index to a JSON number.
+// It returns the index after the number.
+func convertJSON5Number(content []byte, idx int) (int, []byte, error) {
+	var out []byte
+	switch content[idx] {
+	case '-':
+		out = append(out, '-')
+		idx++
+	case '+':
+		idx++
+	}
+	if idx < len(content) && isJSON5IdentifierStart(content[idx]) {
+		return 0, nil, ErrInvalidJSON5 // +/-Infinity, +/-NaN.
+	}
+
+	end := idx
+	for end < len(content) {
+		char := content[end]
+		if isJSON5IdentifierPart(char) || char == '.' ||
+			((char == '+' || char == '-') && (content[end-1] == 'e' || content[end-1] == 'E')) {
+			end++
+
+			continue
+		}
+
+		break
+	}
+	number := string(content[idx:end])
+	if strings.HasPrefix(number, "0x") || strings.HasPrefix(number, "0X") {
+		value, err := strconv.ParseUint(number[2:], 16, 64)
+		if err != nil {
+			return 0, nil, ErrInvalidJSON5
+		}
+
+		return end, strconv.AppendUint(out, value, 10), nil
+	}
+	if strings.HasPrefix(number, ".") {
+		number = "0" + number
+	}
+	number = strings.Replace(number, ".e", ".0e", 1)
+	number = strings.Replace(number, ".E", ".0E", 1)
+	if strings.HasSuffix(number, ".") {
+		number += "0"
+	}
+
+	return end, append(out, number...), nil
+}
+
+// isJSON5IdentifierStart checks if given character can start an identifier.
+func isJSON5IdentifierStart(char byte) bool {
+	return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || char == '_' || char == '$'
+}
+
+// isJSON5IdentifierPart checks if given character can be part of an identifier.
+func isJSON5IdentifierPart(char byte) bool {
+	return isJSON5IdentifierStart(char) || (char >= '0' && char <= '9')
+}
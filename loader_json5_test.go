@@ -0,0 +1,167 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+const (
+	json5FilePath = "testdata/config.json5"
+	jsoncFilePath = "testdata/config.jsonc"
+)
+
+func TestJSON5ReaderLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - valid json5 content", testJSON5ReaderLoaderWithValidContent)
+	t.Run("error - invalid json5 content", testJSON5ReaderLoaderWithInvalidContent)
+}
+
+func testJSON5ReaderLoaderWithValidContent(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		content = `{
+	// comment
+	unquoted_key: 'single "quoted" \'string\'',
+	$dollar_key: "multi\
+line",
+	"hex": -0xFF,
+	leading_dot: .5,
+	trailing_dot: 5.,
+	exponent: 1.e2,
+	escapes: '\x41\tB',
+	nested: {list: [1, 2, /* three */ 3,], bool: true, nil: null,},
+}`
+		reader         = bytes.NewReader([]byte(content))
+		subject        = xconf.JSON5ReaderLoader(reader)
+		expectedConfig = map[string]any{
+			"unquoted_key": `single "quoted" 'string'`,
+			"$dollar_key":  "multiline",
+			"hex":          float64(-255),
+			"leading_dot":  0.5,
+			"trailing_dot": float64(5),
+			"exponent":     float64(100),
+			"escapes":      "A\tB",
+			"nested": map[string]any{
+				"list": []any{float64(1), float64(2), float64(3)},
+				"bool": true,
+				"nil":  nil,
+			},
+		}
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, expectedConfig, config)
+}
+
+func testJSON5ReaderLoaderWithInvalidContent(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "unterminated comment",
+			content: "{/* foo: 'bar'}",
+		},
+		{
+			name:    "unterminated string",
+			content: "{foo: 'bar}",
+		},
+		{
+			name:    "infinity",
+			content: "{foo: -Infinity}",
+		},
+		{
+			name:    "NaN",
+			content: "{foo: NaN}",
+		},
+		{
+			name:    "invalid hex",
+			content: "{foo: 0xZZ}",
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := xconf.JSON5ReaderLoader(bytes.NewReader([]byte(test.content)))
+
+			// act
+			config, err := subject.Load()
+
+			// assert
+			assertNil(t, config)
+			assertTrue(t, errors.Is(err, xconf.ErrInvalidJSON5))
+		})
+	}
+}
+
+func TestJSON5FileLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - valid json5 file", testJSON5FileLoaderWithJSON5File)
+	t.Run("success - valid jsonc file", testJSON5FileLoaderWithJSONCFile)
+	t.Run("error - not found file", testJSON5FileLoaderWithNotFoundFile)
+}
+
+func testJSON5FileLoaderWithJSON5File(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.JSON5FileLoader(json5FilePath)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, jsonConfigMap, config)
+}
+
+func testJSON5FileLoaderWithJSONCFile(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.JSON5FileLoader(jsoncFilePath)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, jsonConfigMap, config)
+}
+
+func testJSON5FileLoaderWithNotFoundFile(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.JSON5FileLoader("testdata/path/does/not/exist/config.json5")
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, os.IsNotExist(err))
+}
@@ -0,0 +1,973 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/actforgood/xerr"
+	"gopkg.in/yaml.v3"
+)
+
+// Note: Kubernetes API is consumed directly, in order not to depend on client-go.
+// Kubernetes API ver was 1.28 at the time this code was written.
+// API ref: https://kubernetes.io/docs/reference/using-api/api-concepts/ .
+
+const (
+	k8sServiceHostEnvName  = "KUBERNETES_SERVICE_HOST"
+	k8sServicePortEnvName  = "KUBERNETES_SERVICE_PORT"
+	kubeconfigEnvName      = "KUBECONFIG"
+	k8sServiceAccountDir   = "/var/run/secrets/kubernetes.io/serviceaccount"
+	k8sDefaultNamespace    = "default"
+	k8sWatchEventBookmark  = "BOOKMARK"
+	k8sWatchEventDeleted   = "DELETED"
+	k8sWatchEventError     = "ERROR"
+	k8sWatchTimeoutSeconds = "300"
+)
+
+// ErrKubernetesObjectNotFound is returned by [KubernetesLoader] when the ConfigMap / Secret is not found.
+var ErrKubernetesObjectNotFound = errors.New("404 - Kubernetes object not found")
+
+// ErrKubernetesWatcherStale is an error returned by [KubernetesLoader] with watcher enabled,
+// while watching object changes is interrupted, and thus configuration may be outdated.
+var ErrKubernetesWatcherStale = errors.New("kubernetes watcher is stale")
+
+// KubernetesLoader loads configuration from a Kubernetes ConfigMap or Secret,
+// read through the Kubernetes API server.
+// Each data key is loaded according to its format (see [KubernetesLoaderWithValueFormat],
+// [KubernetesLoaderWithKeyFormat]).
+//
+// By default, in-cluster configuration is used (service account's token, CA and namespace),
+// if the process runs inside a pod, otherwise the kubeconfig file from KUBECONFIG ENV,
+// or "~/.kube/config" is used. From kubeconfig, token and client certificate authentication
+// methods are supported (exec / auth-provider plugins are not).
+// Close it if watcher option is enabled, in order to properly release resources.
+type KubernetesLoader struct {
+	info    *k8sInfo
+	watcher *k8sWatcher // watcher, if enabled
+}
+
+// k8sInfo holds the info needed to load the object.
+type k8sInfo struct {
+	name               string            // the object's name
+	namespace          string            // the object's namespace, if explicitly set
+	secret             bool              // whether the object is a Secret, or a ConfigMap
+	valueFormat        string            // default data keys' value format, one of RemoteValue* constants
+	keyFormats         map[string]string // data keys' specific value format
+	server             string            // API server's address, if explicitly set
+	token              string            // bearer token, if explicitly set
+	kubeconfigPath     string            // kubeconfig file path, if explicitly set
+	kubeconfigContext  string            // kubeconfig context, if explicitly set
+	tlsConfig          *tls.Config       // the TLS configuration, if custom
+	httpClient         *http.Client      // the http client, if custom
+	ctx                context.Context   // request context
+	watchBackoffMin    time.Duration     // initial backoff for re-establishing watching
+	watchBackoffMax    time.Duration     // maximum backoff for re-establishing watching
+	watchHealthHandler func(err error)   // optional watching health handler
+
+	mu   sync.Mutex // protects conn
+	conn *k8sConn   // resolved connection info
+}
+
+// k8sConn holds resolved API server connection info.
+type k8sConn struct {
+	server     string
+	token      string
+	tokenFile  string
+	namespace  string
+	httpClient *http.Client
+}
+
+// NewKubernetesLoader instantiates a new KubernetesLoader object that loads
+// configuration from the ConfigMap (or Secret, see [KubernetesLoaderWithSecret])
+// with given name.
+func NewKubernetesLoader(name string, opts ...KubernetesLoaderOption) KubernetesLoader {
+	loader := KubernetesLoader{
+		info: &k8sInfo{
+			name:        name,
+			valueFormat: RemoteValuePlain,
+			keyFormats:  make(map[string]string),
+			ctx:         context.Background(),
+
+			watchBackoffMin: 500 * time.Millisecond,
+			watchBackoffMax: 30 * time.Second,
+		},
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&loader)
+	}
+
+	return loader
+}
+
+// Load returns a configuration key-value map from a Kubernetes ConfigMap / Secret,
+// or an error if something bad happens along the process.
+func (loader KubernetesLoader) Load() (map[string]any, error) {
+	return loader.LoadContext(loader.info.ctx)
+}
+
+// LoadContext is like Load, with the (initial) request being canceled also when given context is done.
+// It implements [ContextLoader].
+func (loader KubernetesLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	if loader.watcher != nil {
+		return loader.watcher.load(ctx)
+	}
+
+	obj, err := loader.info.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return loader.info.objectConfigMap(obj)
+}
+
+// Close needs to be called in case watch object changes was enabled.
+// It releases associated resources.
+func (loader KubernetesLoader) Close() error {
+	if loader.watcher != nil {
+		loader.watcher.close()
+	}
+
+	return nil
+}
+
+// k8sObject holds the fields of interest of a ConfigMap / Secret.
+type k8sObject struct {
+	Metadata struct {
+		ResourceVersion string `json:"resourceVersion"`
+	} `json:"metadata"`
+	Data       map[string]string `json:"data"`
+	BinaryData map[string]string `json:"binaryData"`
+}
+
+// k8sStatus holds the fields of interest of an API server's Status response.
+type k8sStatus struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+// k8sWatchEvent is an event received while watching an object.
+type k8sWatchEvent struct {
+	Type   string          `json:"type"`
+	Object json.RawMessage `json:"object"`
+}
+
+// resource returns the object's resource name.
+func (info *k8sInfo) resource() string {
+	if info.secret {
+		return "secrets"
+	}
+
+	return "configmaps"
+}
+
+// get retrieves the object, the request being canceled also when given context is done.
+func (info *k8sInfo) get(ctx context.Context) (k8sObject, error) {
+	var obj k8sObject
+	conn, err := info.connection()
+	if err != nil {
+		return obj, err
+	}
+	endpoint := conn.server + "/api/v1/namespaces/" + url.PathEscape(conn.namespace) +
+		"/" + info.resource() + "/" + url.PathEscape(info.name)
+	ctx, cancelCtx := mergeContexts(ctx, info.ctx)
+	defer cancelCtx()
+	resp, err := info.do(ctx, conn, endpoint)
+	if err != nil {
+		return obj, err
+	}
+	defer closeResponseBody(resp)
+
+	err = json.NewDecoder(resp.Body).Decode(&obj)
+
+	return obj, err
+}
+
+// watch watches the object, starting with given resource version,
+// passing received events to handler. It returns when watching stops.
+func (info *k8sInfo) watch(ctx context.Context, resourceVersion string, handler func(k8sWatchEvent) error) error {
+	conn, err := info.connection()
+	if err != nil {
+		return err
+	}
+	query := url.Values{}
+	query.Set("watch", "true")
+	query.Set("fieldSelector", "metadata.name="+info.name)
+	query.Set("resourceVersion", resourceVersion)
+	query.Set("allowWatchBookmarks", "true")
+	query.Set("timeoutSeconds", k8sWatchTimeoutSeconds)
+	endpoint := conn.server + "/api/v1/namespaces/" + url.PathEscape(conn.namespace) +
+		"/" + info.resource() + "?" + query.Encode()
+	resp, err := info.do(ctx, conn, endpoint)
+	if err != nil {
+		return err
+	}
+	defer closeResponseBody(resp)
+
+	decoder := json.NewDecoder(bufio.NewReader(resp.Body))
+	for {
+		var event k8sWatchEvent
+		if err := decoder.Decode(&event); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil // server closed the stream (timeout), watching should be resumed.
+			}
+
+			return err
+		}
+		if err := handler(event); err != nil {
+			return err
+		}
+	}
+}
+
+// do makes a Kubernetes API GET call, returning the response if it is 200 OK.
+func (info *k8sInfo) do(ctx context.Context, conn *k8sConn, endpoint string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Go-ActForGood-Xconf/1.0")
+	req.Header.Set("Accept", "application/json")
+	token := conn.token
+	if conn.tokenFile != "" { // re-read each time, as projected tokens get rotated.
+		content, err := os.ReadFile(conn.tokenFile)
+		if err != nil {
+			return nil, err
+		}
+		token = strings.TrimSpace(string(content))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := conn.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		closeResponseBody(resp)
+
+		return nil, ErrKubernetesObjectNotFound
+	case resp.StatusCode != http.StatusOK:
+		var status k8sStatus
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&status)
+		closeResponseBody(resp)
+
+		return nil, k8sResponseError{statusCode: resp.StatusCode, message: status.Message}
+	}
+
+	return resp, nil
+}
+
+// objectConfigMap returns the configuration map from an object's data keys.
+func (info *k8sInfo) objectConfigMap(obj k8sObject) (map[string]any, error) {
+	data := make(map[string][]byte, len(obj.Data)+len(obj.BinaryData))
+	for key, value := range obj.Data {
+		if !info.secret {
+			data[key] = []byte(value)
+
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, err
+		}
+		data[key] = decoded
+	}
+	for key, value := range obj.BinaryData {
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, err
+		}
+		data[key] = decoded
+	}
+
+	dataKeys := make([]string, 0, len(data))
+	for key := range data {
+		dataKeys = append(dataKeys, key)
+	}
+	sort.Strings(dataKeys) // for deterministic override of duplicate keys.
+
+	configMap := make(map[string]any, len(data))
+	for _, dataKey := range dataKeys {
+		format, found := info.keyFormats[dataKey]
+		if !found {
+			format = info.valueFormat
+		}
+		currentKeyConfigMap, err := getRemoteKVPairConfigMap(dataKey, data[dataKey], format)
+		if err != nil {
+			return nil, xerr.Wrapf(err, "data key %q", dataKey)
+		}
+		// merge configs from different data keys.
+		// Note: here, if a duplicate key exists, it will get overwritten.
+		for key, value := range currentKeyConfigMap {
+			configMap[key] = value
+		}
+	}
+
+	return configMap, nil
+}
+
+// connection returns the API server connection info, resolving it, if needed.
+func (info *k8sInfo) connection() (*k8sConn, error) {
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	if info.conn != nil {
+		return info.conn, nil
+	}
+
+	var (
+		conn      *k8sConn
+		tlsConfig *tls.Config
+		err       error
+	)
+	switch {
+	case info.server != "":
+		conn = &k8sConn{server: info.server, token: info.token}
+	case info.kubeconfigPath == "" && os.Getenv(k8sServiceHostEnvName) != "":
+		conn, tlsConfig, err = k8sInClusterConnection()
+	default:
+		conn, tlsConfig, err = k8sKubeconfigConnection(info.kubeconfigPath, info.kubeconfigContext)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.token != "" {
+		conn.token, conn.tokenFile = info.token, ""
+	}
+	if info.namespace != "" {
+		conn.namespace = info.namespace
+	}
+	if conn.namespace == "" {
+		conn.namespace = k8sDefaultNamespace
+	}
+	if info.tlsConfig != nil {
+		tlsConfig = mergeK8sTLSConfig(info.tlsConfig, tlsConfig)
+	}
+	conn.httpClient = info.httpClient
+	if conn.httpClient == nil {
+		conn.httpClient = newDefaultHTTPClient()
+	}
+	if tlsConfig != nil {
+		if conn.httpClient, err = withTLSConfig(conn.httpClient, tlsConfig); err != nil {
+			return nil, err
+		}
+	}
+	info.conn = conn
+
+	return conn, nil
+}
+
+// mergeK8sTLSConfig returns a clone of custom TLS configuration,
+// completed with resolved CA / client certificates, if not set.
+func mergeK8sTLSConfig(custom, resolved *tls.Config) *tls.Config {
+	tlsConfig := custom.Clone()
+	if resolved != nil {
+		if tlsConfig.RootCAs == nil {
+			tlsConfig.RootCAs = resolved.RootCAs
+		}
+		if len(tlsConfig.Certificates) == 0 {
+			tlsConfig.Certificates = resolved.Certificates
+		}
+	}
+
+	return tlsConfig
+}
+
+// k8sInClusterConnection returns the connection info from pod's service account.
+func k8sInClusterConnection() (*k8sConn, *tls.Config, error) {
+	port := os.Getenv(k8sServicePortEnvName)
+	if port == "" {
+		port = "443"
+	}
+	conn := &k8sConn{
+		server:    "https://" + net.JoinHostPort(os.Getenv(k8sServiceHostEnvName), port),
+		tokenFile: filepath.Join(k8sServiceAccountDir, "token"),
+	}
+	if namespace, err := os.ReadFile(filepath.Join(k8sServiceAccountDir, "namespace")); err == nil {
+		conn.namespace = strings.TrimSpace(string(namespace))
+	}
+	caPEM, err := os.ReadFile(filepath.Join(k8sServiceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, nil, err
+	}
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(caPEM) {
+		return nil, nil, errors.New("kubernetes - invalid service account CA certificate")
+	}
+
+	return conn, &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}, nil
+}
+
+// kubeconfig holds the fields of interest of a kubeconfig file.
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Contexts       []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			User      string `yaml:"user"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+	Clusters []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string `yaml:"token"`
+			TokenFile             string `yaml:"tokenFile"`
+			ClientCertificate     string `yaml:"client-certificate"`
+			ClientCertificateData string `yaml:"client-certificate-data"`
+			ClientKey             string `yaml:"client-key"`
+			ClientKeyData         string `yaml:"client-key-data"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+}
+
+// k8sKubeconfigConnection returns the connection info from a kubeconfig file's context.
+// If path is empty, KUBECONFIG ENV (first path), or "~/.kube/config" is used.
+// If context name is empty, kubeconfig's current context is used.
+func k8sKubeconfigConnection(path, contextName string) (*k8sConn, *tls.Config, error) {
+	if path == "" {
+		path = getDefaultKubeconfigPath()
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var kCfg kubeconfig
+	if err := yaml.Unmarshal(content, &kCfg); err != nil {
+		return nil, nil, err
+	}
+	if contextName == "" {
+		contextName = kCfg.CurrentContext
+	}
+	baseDir := filepath.Dir(path) // relative paths are relative to kubeconfig file.
+
+	conn := &k8sConn{}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	ctxIdx := -1
+	for idx := range kCfg.Contexts {
+		if kCfg.Contexts[idx].Name == contextName {
+			ctxIdx = idx
+
+			break
+		}
+	}
+	if ctxIdx < 0 {
+		return nil, nil, fmt.Errorf("kubernetes - context %q not found in kubeconfig %q", contextName, path)
+	}
+	kCtx := kCfg.Contexts[ctxIdx].Context
+	conn.namespace = kCtx.Namespace
+
+	for _, cluster := range kCfg.Clusters {
+		if cluster.Name != kCtx.Cluster {
+			continue
+		}
+		conn.server = strings.TrimRight(cluster.Cluster.Server, "/")
+		tlsConfig.InsecureSkipVerify = cluster.Cluster.InsecureSkipTLSVerify //nolint:gosec // user's choice
+		caPEM, err := kubeconfigData(cluster.Cluster.CertificateAuthorityData, cluster.Cluster.CertificateAuthority, baseDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		if caPEM != nil {
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(caPEM) {
+				return nil, nil, errors.New("kubernetes - invalid kubeconfig CA certificate")
+			}
+		}
+	}
+	if conn.server == "" {
+		return nil, nil, fmt.Errorf("kubernetes - cluster %q not found in kubeconfig %q", kCtx.Cluster, path)
+	}
+
+	for _, user := range kCfg.Users {
+		if user.Name != kCtx.User {
+			continue
+		}
+		conn.token = user.User.Token
+		if user.User.TokenFile != "" {
+			conn.tokenFile = kubeconfigPath(user.User.TokenFile, baseDir)
+		}
+		certPEM, err := kubeconfigData(user.User.ClientCertificateData, user.User.ClientCertificate, baseDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		keyPEM, err := kubeconfigData(user.User.ClientKeyData, user.User.ClientKey, baseDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		if certPEM != nil && keyPEM != nil {
+			cert, err := tls.X509KeyPair(certPEM, keyPEM)
+			if err != nil {
+				return nil, nil, err
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+	}
+
+	return conn, tlsConfig, nil
+}
+
+// kubeconfigData returns the base64 decoded data, if set, or the content of the file at given path, if set.
+func kubeconfigData(data, path, baseDir string) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	if path != "" {
+		return os.ReadFile(kubeconfigPath(path, baseDir))
+	}
+
+	return nil, nil
+}
+
+// kubeconfigPath returns the path, relative to baseDir, if it's not absolute.
+func kubeconfigPath(path, baseDir string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(baseDir, path)
+}
+
+// getDefaultKubeconfigPath tries to get kubeconfig path from ENV.
+// It defaults on "~/.kube/config".
+func getDefaultKubeconfigPath() string {
+	if paths := os.Getenv(kubeconfigEnvName); paths != "" {
+		return filepath.SplitList(paths)[0]
+	}
+	home, _ := os.UserHomeDir()
+
+	return filepath.Join(home, ".kube", "config")
+}
+
+// k8sResponseError is the error returned for a Kubernetes API non 200 OK / 404 response.
+type k8sResponseError struct {
+	statusCode int
+	message    string
+}
+
+// Error returns string representation of the error.
+func (err k8sResponseError) Error() string {
+	return fmt.Sprintf("Kubernetes responded with status code %d: %s", err.statusCode, err.message)
+}
+
+// k8sWatcher loads initial configuration by retrieving the object,
+// and after that watches for object changes asynchronously.
+// If watching stops, it is re-established with exponential backoff.
+// Meanwhile, the watcher is considered stale, and Load returns [ErrKubernetesWatcherStale].
+type k8sWatcher struct {
+	info            *k8sInfo
+	configMap       map[string]any     // "live" configuration map
+	resourceVersion string             // the last seen resource version
+	staleSince      time.Time          // the moment watching stopped, zero value if watching is healthy
+	mErr            *xerr.MultiError   // error(s) occurred during watching, between 2 Loads.
+	started         bool               // whether watching goroutine was started
+	ctx             context.Context    // watching context
+	cancelCtx       context.CancelFunc // cancels watching context
+	mu              sync.RWMutex       // concurrency semaphore
+	wg              sync.WaitGroup     // wait group to wait for watching goroutine to finish
+}
+
+// load returns a copy of the stored configuration map,
+// or an error if something bad happens along the process.
+// If watching is stale, [ErrKubernetesWatcherStale] is returned (along with the last known configuration map).
+// The initial request is canceled also when given context is done.
+func (w *k8sWatcher) load(ctx context.Context) (map[string]any, error) {
+	if err := w.init(ctx); err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	configMap := DeepCopyConfigMap(w.configMap)
+	if !w.staleSince.IsZero() {
+		w.mErr = w.mErr.Add(xerr.Wrapf(
+			ErrKubernetesWatcherStale,
+			"since %s",
+			w.staleSince.Format(time.RFC3339),
+		))
+	}
+	err := w.mErr.ErrOrNil()
+	w.mErr = nil
+	w.mu.Unlock()
+
+	return configMap, err
+}
+
+// init populates initial configuration map and starts watching for object changes.
+func (w *k8sWatcher) init(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.started {
+		obj, err := w.info.get(ctx)
+		if err != nil {
+			return err
+		}
+		configMap, err := w.info.objectConfigMap(obj)
+		if err != nil {
+			return err
+		}
+		w.configMap = configMap
+		w.resourceVersion = obj.Metadata.ResourceVersion
+
+		// listen for changes.
+		w.ctx, w.cancelCtx = context.WithCancel(w.info.ctx)
+		w.started = true
+		w.wg.Add(1)
+		go w.watchAsync()
+	}
+
+	return nil
+}
+
+// resync replaces the configuration map with the one retrieved at current resource version,
+// reconciling changes missed while not watching.
+func (w *k8sWatcher) resync() error {
+	obj, err := w.info.get(w.info.ctx)
+	if errors.Is(err, ErrKubernetesObjectNotFound) {
+		w.mu.Lock()
+		w.configMap = make(map[string]any)
+		w.resourceVersion = ""
+		w.mu.Unlock()
+
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	configMap, err := w.info.objectConfigMap(obj)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	w.configMap = configMap
+	w.resourceVersion = obj.Metadata.ResourceVersion
+	w.mu.Unlock()
+
+	return nil
+}
+
+// watchAsync watches object changes, re-establishing watching with exponential backoff, if it stops.
+// If the resource version needed to resume watching is too old, the object is retrieved again,
+// and watching is resumed from there.
+func (w *k8sWatcher) watchAsync() {
+	defer w.wg.Done()
+
+	backoff := w.info.watchBackoffMin
+	for {
+		established, err := w.watchObject()
+		if w.ctx.Err() != nil {
+			return // closed, or request context is done, watching cannot be re-established.
+		}
+		if err == nil {
+			continue // stream ended gracefully (server side timeout), resume watching.
+		}
+		var respErr k8sResponseError
+		if errors.As(err, &respErr) && respErr.statusCode == http.StatusGone {
+			if err = w.resync(); err == nil {
+				continue
+			}
+		}
+		w.markStale(err)
+		if established {
+			backoff = w.info.watchBackoffMin
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-w.ctx.Done():
+			timer.Stop()
+
+			return
+		case <-timer.C:
+		}
+		backoff = min(2*backoff, w.info.watchBackoffMax)
+	}
+}
+
+// watchObject watches object changes, starting with the last seen resource version.
+// It returns when watching stops, with the reason (nil if the server ended the stream gracefully)
+// and a flag indicating whether watching was successfully established.
+func (w *k8sWatcher) watchObject() (bool, error) {
+	w.mu.RLock()
+	resourceVersion := w.resourceVersion
+	w.mu.RUnlock()
+
+	var established bool
+	err := w.info.watch(w.ctx, resourceVersion, func(event k8sWatchEvent) error {
+		if !established {
+			established = true
+			w.markHealthy()
+		}
+
+		return w.applyEvent(event)
+	})
+	if err == nil && !established {
+		established = true // stream ended without events, but it was established.
+		w.markHealthy()
+	}
+
+	return established, err
+}
+
+// applyEvent updates the configuration map with a watched event.
+func (w *k8sWatcher) applyEvent(event k8sWatchEvent) error {
+	if event.Type == k8sWatchEventError {
+		var status k8sStatus
+		if err := json.Unmarshal(event.Object, &status); err != nil {
+			return err
+		}
+
+		return k8sResponseError{statusCode: status.Code, message: status.Message}
+	}
+
+	var obj k8sObject
+	if err := json.Unmarshal(event.Object, &obj); err != nil {
+		return err
+	}
+	if event.Type == k8sWatchEventBookmark {
+		w.mu.Lock()
+		w.resourceVersion = obj.Metadata.ResourceVersion
+		w.mu.Unlock()
+
+		return nil
+	}
+
+	configMap := make(map[string]any)
+	if event.Type != k8sWatchEventDeleted {
+		var err error
+		if configMap, err = w.info.objectConfigMap(obj); err != nil {
+			w.mu.Lock()
+			w.mErr = w.mErr.Add(err)
+			w.resourceVersion = obj.Metadata.ResourceVersion
+			w.mu.Unlock()
+
+			return nil
+		}
+	}
+	w.mu.Lock()
+	w.configMap = configMap
+	w.resourceVersion = obj.Metadata.ResourceVersion
+	w.mu.Unlock()
+
+	return nil
+}
+
+// markStale marks watching as stale, storing the reason it stopped,
+// and notifies the health handler, if any.
+func (w *k8sWatcher) markStale(reason error) {
+	w.mu.Lock()
+	if w.staleSince.IsZero() {
+		w.staleSince = time.Now()
+	}
+	w.mErr = w.mErr.Add(reason)
+	w.mu.Unlock()
+
+	if w.info.watchHealthHandler != nil {
+		w.info.watchHealthHandler(reason)
+	}
+}
+
+// markHealthy marks watching as healthy, and notifies the health handler, if any,
+// if watching was stale.
+func (w *k8sWatcher) markHealthy() {
+	w.mu.Lock()
+	wasStale := !w.staleSince.IsZero()
+	w.staleSince = time.Time{}
+	w.mu.Unlock()
+
+	if wasStale && w.info.watchHealthHandler != nil {
+		w.info.watchHealthHandler(nil)
+	}
+}
+
+// close stops watching, and waits for watching goroutine to finish.
+func (w *k8sWatcher) close() {
+	w.mu.RLock()
+	started := w.started
+	cancelCtx := w.cancelCtx
+	w.mu.RUnlock()
+
+	if started {
+		cancelCtx()
+		w.wg.Wait()
+	}
+}
+
+// KubernetesLoaderOption defines optional function for configuring
+// a Kubernetes Loader.
+type KubernetesLoaderOption func(*KubernetesLoader)
+
+// KubernetesLoaderWithSecret makes the loader read a Secret, instead of a ConfigMap.
+func KubernetesLoaderWithSecret() KubernetesLoaderOption {
+	return func(loader *KubernetesLoader) {
+		loader.info.secret = true
+	}
+}
+
+// KubernetesLoaderWithNamespace sets the object's namespace.
+// By default, the service account's namespace is used, if in-cluster,
+// or the kubeconfig context's namespace, otherwise ("default" if not set).
+func KubernetesLoaderWithNamespace(namespace string) KubernetesLoaderOption {
+	return func(loader *KubernetesLoader) {
+		loader.info.namespace = namespace
+	}
+}
+
+// KubernetesLoaderWithValueFormat sets the value format for all data keys
+// (for which a specific format was not set with [KubernetesLoaderWithKeyFormat]).
+//
+// If is set to [RemoteValueJSON], the data key's value will be treated as JSON
+// and configuration will be loaded from it.
+//
+// If is set to [RemoteValueYAML], the data key's value will be treated as YAML
+// and configuration will be loaded from it.
+//
+// If is set to [RemoteValuePlain], the data key's value will be treated as plain content
+// and configuration will contain the data key and its plain value.
+//
+// If is set to [RemoteValueTOML], [RemoteValueIni], [RemoteValueProperties], [RemoteValueDotEnv],
+// the data key's value will be treated accordingly, like the files of the same format,
+// and configuration will be loaded from it.
+//
+// By default, is set to [RemoteValuePlain].
+func KubernetesLoaderWithValueFormat(valueFormat string) KubernetesLoaderOption {
+	return func(loader *KubernetesLoader) {
+		if isValidRemoteValueFormat(valueFormat) {
+			loader.info.valueFormat = valueFormat
+		}
+	}
+}
+
+// KubernetesLoaderWithKeyFormat sets the value format for a specific data key,
+// like [RemoteValueYAML] for an "app.yaml" data key.
+// See [KubernetesLoaderWithValueFormat] for possible values.
+func KubernetesLoaderWithKeyFormat(dataKey, valueFormat string) KubernetesLoaderOption {
+	return func(loader *KubernetesLoader) {
+		if isValidRemoteValueFormat(valueFormat) {
+			loader.info.keyFormats[dataKey] = valueFormat
+		}
+	}
+}
+
+// KubernetesLoaderWithKubeconfig sets the kubeconfig file, and optionally, the context to use
+// (by default, kubeconfig's current context is used).
+// By default, in-cluster configuration is used, if the process runs inside a pod,
+// otherwise the kubeconfig file from KUBECONFIG ENV, or "~/.kube/config" is used.
+func KubernetesLoaderWithKubeconfig(path string, contextName ...string) KubernetesLoaderOption {
+	return func(loader *KubernetesLoader) {
+		loader.info.kubeconfigPath = path
+		if len(contextName) > 0 {
+			loader.info.kubeconfigContext = contextName[0]
+		}
+	}
+}
+
+// KubernetesLoaderWithAPIServer sets explicitly the API server's address (like "https://10.0.0.1:6443")
+// and the bearer token to authenticate with (can be empty), bypassing in-cluster / kubeconfig configuration.
+func KubernetesLoaderWithAPIServer(server, token string) KubernetesLoaderOption {
+	return func(loader *KubernetesLoader) {
+		loader.info.server = strings.TrimRight(server, "/")
+		loader.info.token = token
+	}
+}
+
+// KubernetesLoaderWithTLSConfig sets the TLS configuration (like custom CA, client certificate)
+// of the http client's transport (which gets cloned, if KubernetesLoaderWithHTTPClient is used, too).
+// CA and client certificate from in-cluster / kubeconfig configuration are used, if not set.
+// An error is returned by Load if TLS configuration is needed, and the custom http client's transport
+// is not a *[http.Transport].
+func KubernetesLoaderWithTLSConfig(tlsConfig *tls.Config) KubernetesLoaderOption {
+	return func(loader *KubernetesLoader) {
+		loader.info.tlsConfig = tlsConfig
+	}
+}
+
+// KubernetesLoaderWithHTTPClient sets the http client used for calls.
+// A default one is provided if you don't use this option.
+// Note: the client should not have a timeout set, if watcher is enabled.
+func KubernetesLoaderWithHTTPClient(client *http.Client) KubernetesLoaderOption {
+	return func(loader *KubernetesLoader) {
+		loader.info.httpClient = client
+	}
+}
+
+// KubernetesLoaderWithContext sets request 's context.
+// By default, a context.Background() is used.
+func KubernetesLoaderWithContext(ctx context.Context) KubernetesLoaderOption {
+	return func(loader *KubernetesLoader) {
+		loader.info.ctx = ctx
+	}
+}
+
+// KubernetesLoaderWithWatcher enables watch for object changes (list-watch, like an informer).
+// Use this if you intend to load configuration intensively, multiple times.
+// If you plan to load configuration only once, or rarely, don't use this feature.
+// If you use this feature, call Close() method on the loader to gracefully release resources
+// (at your application shutdown).
+// If watching stops, it is re-established with exponential backoff, and meanwhile Load returns
+// [ErrKubernetesWatcherStale] along with the last known configuration
+// (see also [KubernetesLoaderWithWatcherBackoff], [KubernetesLoaderWithWatcherHealthHandler]).
+// If the object gets deleted, an empty configuration is loaded.
+func KubernetesLoaderWithWatcher() KubernetesLoaderOption {
+	return func(loader *KubernetesLoader) {
+		loader.watcher = &k8sWatcher{info: loader.info}
+	}
+}
+
+// KubernetesLoaderWithWatcherBackoff sets the exponential backoff limits used to re-establish
+// watching, if it stops. Meanwhile, Load returns [ErrKubernetesWatcherStale].
+// Has effect only together with [KubernetesLoaderWithWatcher].
+// By default, backoff starts at 500ms and is capped at 30s.
+func KubernetesLoaderWithWatcherBackoff(minBackoff, maxBackoff time.Duration) KubernetesLoaderOption {
+	return func(loader *KubernetesLoader) {
+		if minBackoff > 0 && maxBackoff >= minBackoff {
+			loader.info.watchBackoffMin = minBackoff
+			loader.info.watchBackoffMax = maxBackoff
+		}
+	}
+}
+
+// KubernetesLoaderWithWatcherHealthHandler sets a handler to be called when watching
+// stops (with the reason), and when it gets re-established (with nil error).
+// You can log the error / expose a health check based on it, for example.
+// Has effect only together with [KubernetesLoaderWithWatcher].
+func KubernetesLoaderWithWatcherHealthHandler(handler func(err error)) KubernetesLoaderOption {
+	return func(loader *KubernetesLoader) {
+		loader.info.watchHealthHandler = handler
+	}
+}
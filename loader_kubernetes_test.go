@@ -0,0 +1,357 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestKubernetesLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - configmap with key formats", testKubernetesLoaderConfigMap)
+	t.Run("success - secret", testKubernetesLoaderSecret)
+	t.Run("success - kubeconfig", testKubernetesLoaderKubeconfig)
+	t.Run("success - watcher", testKubernetesLoaderWithWatcher)
+	t.Run("error - object not found", testKubernetesLoaderReturnsErrNotFound)
+	t.Run("error - forbidden", testKubernetesLoaderReturnsErrForbidden)
+}
+
+// k8sMockServer is a Kubernetes API server mock.
+type k8sMockServer struct {
+	token     string
+	objPath   string       // the object's path, like "/api/v1/namespaces/ns/configmaps/name"
+	obj       atomic.Value // the object's JSON
+	events    chan string  // watch events' JSON
+	watchCnt  uint32
+	objGetCnt uint32
+}
+
+func (mock *k8sMockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+mock.token {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"kind":"Status","message":"forbidden","code":403}`))
+
+		return
+	}
+	if r.URL.Query().Get("watch") == "true" && r.URL.Path+"/"+nameFromFieldSelector(r) == mock.objPath {
+		atomic.AddUint32(&mock.watchCnt, 1)
+		flusher := w.(http.Flusher)
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event := <-mock.events:
+				_, _ = w.Write([]byte(event + "\n"))
+				flusher.Flush()
+			}
+		}
+	}
+	if r.URL.Path != mock.objPath {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"kind":"Status","message":"not found","code":404}`))
+
+		return
+	}
+	atomic.AddUint32(&mock.objGetCnt, 1)
+	_, _ = w.Write([]byte(mock.obj.Load().(string)))
+}
+
+func nameFromFieldSelector(r *http.Request) string {
+	const prefix = "metadata.name="
+	fieldSelector := r.URL.Query().Get("fieldSelector")
+	if len(fieldSelector) < len(prefix) {
+		return ""
+	}
+
+	return fieldSelector[len(prefix):]
+}
+
+// newK8sMockServer instantiates a new Kubernetes API server mock, serving given object.
+func newK8sMockServer(token, objPath, obj string) *k8sMockServer {
+	mock := &k8sMockServer{
+		token:   token,
+		objPath: objPath,
+		events:  make(chan string, 10),
+	}
+	mock.obj.Store(obj)
+
+	return mock
+}
+
+func testKubernetesLoaderConfigMap(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	svr := httptest.NewServer(newK8sMockServer(
+		"my-token",
+		"/api/v1/namespaces/my-ns/configmaps/my-app",
+		`{"kind":"ConfigMap","metadata":{"name":"my-app","resourceVersion":"10"},"data":{`+
+			`"app.yaml":"db:\n  host: localhost\n  port: 3306\n",`+
+			`"features.json":"{\"new_ui\":true}",`+
+			`"LOG_LEVEL":" debug\n"}}`,
+	))
+	defer svr.Close()
+	subject := xconf.NewKubernetesLoader(
+		"my-app",
+		xconf.KubernetesLoaderWithAPIServer(svr.URL+"/", "my-token"),
+		xconf.KubernetesLoaderWithNamespace("my-ns"),
+		xconf.KubernetesLoaderWithKeyFormat("app.yaml", xconf.RemoteValueYAML),
+		xconf.KubernetesLoaderWithKeyFormat("features.json", xconf.RemoteValueJSON),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"db":        map[string]any{"host": "localhost", "port": 3306},
+			"new_ui":    true,
+			"LOG_LEVEL": "debug",
+		},
+		config,
+	)
+}
+
+func testKubernetesLoaderSecret(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		encode = base64.StdEncoding.EncodeToString
+		svr    = httptest.NewServer(newK8sMockServer(
+			"my-token",
+			"/api/v1/namespaces/default/secrets/my-app",
+			`{"kind":"Secret","metadata":{"name":"my-app","resourceVersion":"10"},"data":{`+
+				`"DB_PASSWORD":"`+encode([]byte("s3cr3t"))+`",`+
+				`"creds.json":"`+encode([]byte(`{"api_key":"abc"}`))+`"}}`,
+		))
+	)
+	defer svr.Close()
+	subject := xconf.NewKubernetesLoader(
+		"my-app",
+		xconf.KubernetesLoaderWithSecret(),
+		xconf.KubernetesLoaderWithAPIServer(svr.URL, "my-token"),
+		xconf.KubernetesLoaderWithKeyFormat("creds.json", xconf.RemoteValueJSON),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"DB_PASSWORD": "s3cr3t", "api_key": "abc"}, config)
+}
+
+func testKubernetesLoaderKubeconfig(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	svr := httptest.NewTLSServer(newK8sMockServer(
+		"kubeconfig-token",
+		"/api/v1/namespaces/ctx-ns/configmaps/my-app",
+		`{"kind":"ConfigMap","metadata":{"name":"my-app"},"data":{"foo":"bar"}}`,
+	))
+	defer svr.Close()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: svr.Certificate().Raw})
+	kubeconfigPath := filepath.Join(t.TempDir(), "kubeconfig")
+	requireNil(t, os.WriteFile(kubeconfigPath, []byte(`apiVersion: v1
+kind: Config
+current-context: other
+clusters:
+- name: test-cluster
+  cluster:
+    server: `+svr.URL+`
+    certificate-authority-data: `+base64.StdEncoding.EncodeToString(caPEM)+`
+users:
+- name: test-user
+  user:
+    token: kubeconfig-token
+contexts:
+- name: other
+  context:
+    cluster: unknown-cluster
+    user: unknown-user
+- name: test
+  context:
+    cluster: test-cluster
+    user: test-user
+    namespace: ctx-ns
+`), 0o600))
+	subject := xconf.NewKubernetesLoader(
+		"my-app",
+		xconf.KubernetesLoaderWithKubeconfig(kubeconfigPath, "test"),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"foo": "bar"}, config)
+
+	// arrange
+	subject = xconf.NewKubernetesLoader(
+		"my-app",
+		xconf.KubernetesLoaderWithKubeconfig(kubeconfigPath, "test"),
+		xconf.KubernetesLoaderWithHTTPClient(&http.Client{}), // kubeconfig's CA is applied on default transport.
+	)
+
+	// act
+	config, err = subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"foo": "bar"}, config)
+
+	// arrange
+	subject = xconf.NewKubernetesLoader(
+		"my-app",
+		xconf.KubernetesLoaderWithKubeconfig(kubeconfigPath, "test"),
+		xconf.KubernetesLoaderWithHTTPClient(&http.Client{Transport: roundTripperFunc(http.DefaultTransport.RoundTrip)}),
+	)
+
+	// act
+	config, err = subject.Load()
+
+	// assert
+	if assertNotNil(t, err) {
+		assertTrue(t, strings.Contains(err.Error(), "cannot apply TLS configuration"))
+	}
+	assertNil(t, config)
+
+	// arrange
+	subject = xconf.NewKubernetesLoader(
+		"my-app",
+		xconf.KubernetesLoaderWithKubeconfig(kubeconfigPath), // current context's cluster does not exist.
+	)
+
+	// act
+	config, err = subject.Load()
+
+	// assert
+	assertNotNil(t, err)
+	assertNil(t, config)
+}
+
+func testKubernetesLoaderWithWatcher(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		mock = newK8sMockServer(
+			"my-token",
+			"/api/v1/namespaces/default/configmaps/my-app",
+			`{"kind":"ConfigMap","metadata":{"name":"my-app","resourceVersion":"10"},"data":{"foo":"bar"}}`,
+		)
+		svr     = httptest.NewServer(mock)
+		subject = xconf.NewKubernetesLoader(
+			"my-app",
+			xconf.KubernetesLoaderWithAPIServer(svr.URL, "my-token"),
+			xconf.KubernetesLoaderWithWatcher(),
+		)
+	)
+	defer svr.Close()
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"foo": "bar"}, config)
+
+	// act
+	mock.events <- `{"type":"MODIFIED","object":{"kind":"ConfigMap",` +
+		`"metadata":{"name":"my-app","resourceVersion":"11"},"data":{"foo":"baz","abc":"xyz"}}}`
+	time.Sleep(150 * time.Millisecond)
+	config, err = subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"foo": "baz", "abc": "xyz"}, config)
+
+	// act
+	mock.events <- `{"type":"DELETED","object":{"kind":"ConfigMap",` +
+		`"metadata":{"name":"my-app","resourceVersion":"12"},"data":{"foo":"baz","abc":"xyz"}}}`
+	time.Sleep(150 * time.Millisecond)
+	config, err = subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{}, config)
+	assertEqual(t, uint32(1), atomic.LoadUint32(&mock.objGetCnt))
+	assertEqual(t, uint32(1), atomic.LoadUint32(&mock.watchCnt))
+
+	// act
+	err = subject.Close()
+
+	// assert
+	assertNil(t, err)
+}
+
+func testKubernetesLoaderReturnsErrNotFound(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	svr := httptest.NewServer(newK8sMockServer(
+		"my-token",
+		"/api/v1/namespaces/default/configmaps/other-app",
+		`{"kind":"ConfigMap","metadata":{"name":"other-app"},"data":{"foo":"bar"}}`,
+	))
+	defer svr.Close()
+	subject := xconf.NewKubernetesLoader(
+		"my-app",
+		xconf.KubernetesLoaderWithAPIServer(svr.URL, "my-token"),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrKubernetesObjectNotFound))
+	assertNil(t, config)
+}
+
+func testKubernetesLoaderReturnsErrForbidden(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	svr := httptest.NewServer(newK8sMockServer(
+		"my-token",
+		"/api/v1/namespaces/default/configmaps/my-app",
+		`{"kind":"ConfigMap","metadata":{"name":"my-app"},"data":{"foo":"bar"}}`,
+	))
+	defer svr.Close()
+	subject := xconf.NewKubernetesLoader(
+		"my-app",
+		xconf.KubernetesLoaderWithAPIServer(svr.URL, "wrong-token"),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNotNil(t, err)
+	assertEqual(t, "Kubernetes responded with status code 403: forbidden", err.Error())
+	assertNil(t, config)
+}
@@ -0,0 +1,49 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+// Labels of the layers of a [NewLayeredLoader], as reported by its KeySource.
+const (
+	LayerDefaults = "defaults"
+	LayerFile     = "file"
+	LayerEnv      = "env"
+	LayerFlags    = "flags"
+)
+
+// DefaultsLoader returns a loader for the default values of the configuration,
+// meant to be the lowest precedence layer (see [NewLayeredLoader]).
+// Like [PlainLoader], it returns a copy of given map.
+func DefaultsLoader(defaults map[string]any) Loader {
+	return PlainLoader(defaults)
+}
+
+// NewLayeredLoader returns a [Precedence] loader encoding the common precedence order:
+// flags > env > file > defaults.
+// Pass nil for a layer you do not have (like no flags), it will be skipped.
+//
+// Example:
+//
+//	loader := xconf.NewLayeredLoader(
+//		xconf.DefaultsLoader(map[string]any{"port": 8080, "log_level": "info"}),
+//		xconf.YAMLFileLoader("config.yaml"),
+//		xconf.EnvLoader(),
+//		xconf.FlagSetLoader(flag.CommandLine),
+//	)
+func NewLayeredLoader(defaults, file, env, flags Loader, opts ...PrecedenceOption) Precedence {
+	layers := make([]PrecedenceLayer, 0, 4)
+	for _, layer := range [...]PrecedenceLayer{
+		{Label: LayerDefaults, Loader: defaults},
+		{Label: LayerFile, Loader: file},
+		{Label: LayerEnv, Loader: env},
+		{Label: LayerFlags, Loader: flags},
+	} {
+		if layer.Loader != nil {
+			layers = append(layers, layer)
+		}
+	}
+
+	return NewPrecedence(layers, opts...)
+}
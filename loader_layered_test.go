@@ -0,0 +1,94 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestNewLayeredLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - flags > env > file > defaults", testNewLayeredLoaderPrecedence)
+	t.Run("success - nil layers are skipped", testNewLayeredLoaderSkipsNilLayers)
+	t.Run("error - all layers are nil", testNewLayeredLoaderReturnsErrWithNoLayers)
+}
+
+func testNewLayeredLoaderPrecedence(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	defaults := map[string]any{"port": 8080, "log_level": "info", "name": "app", "debug": false}
+	subject := xconf.NewLayeredLoader(
+		xconf.DefaultsLoader(defaults),
+		xconf.PlainLoader(map[string]any{"port": 9090, "log_level": "warn", "name": "file-app"}),
+		xconf.PlainLoader(map[string]any{"port": "9091", "log_level": "error"}),
+		xconf.PlainLoader(map[string]any{"port": "9092"}),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{"port": "9092", "log_level": "error", "name": "file-app", "debug": false},
+		config,
+	)
+	assertEqual(t, []string{xconf.LayerDefaults, xconf.LayerFile, xconf.LayerEnv, xconf.LayerFlags}, subject.Labels())
+	for key, expectedLayer := range map[string]string{
+		"port":      xconf.LayerFlags,
+		"log_level": xconf.LayerEnv,
+		"name":      xconf.LayerFile,
+		"debug":     xconf.LayerDefaults,
+	} {
+		layer, found := subject.KeySource(key)
+		assertTrue(t, found)
+		assertEqual(t, expectedLayer, layer)
+	}
+	defaults["debug"] = true // defaults loader is not affected by outside changes.
+	config, err = subject.Load()
+	assertNil(t, err)
+	assertEqual(t, false, config["debug"])
+}
+
+func testNewLayeredLoaderSkipsNilLayers(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.NewLayeredLoader(
+		xconf.DefaultsLoader(map[string]any{"port": 8080}),
+		nil,
+		xconf.PlainLoader(map[string]any{"port": "9091"}),
+		nil,
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"port": "9091"}, config)
+	assertEqual(t, []string{xconf.LayerDefaults, xconf.LayerEnv}, subject.Labels())
+}
+
+func testNewLayeredLoaderReturnsErrWithNoLayers(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.NewLayeredLoader(nil, nil, nil, nil)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, errors.Is(err, xconf.ErrInvalidPrecedence))
+}
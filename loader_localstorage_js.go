@@ -0,0 +1,117 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"errors"
+	"strings"
+	"syscall/js"
+)
+
+// ErrLocalStorageUnavailable is returned by [LocalStorageLoader] if browser's
+// localStorage is not available (not a browser environment, or storage is disabled).
+var ErrLocalStorageUnavailable = errors.New("localStorage is not available")
+
+// LocalStorageLoader loads configuration from browser's localStorage (js/wasm only).
+// By default, each item makes up a key with its plain value.
+type LocalStorageLoader struct {
+	prefix      string // the items' keys prefix
+	valueFormat string // items' value format, one of RemoteValue* constants
+}
+
+// NewLocalStorageLoader instantiates a new LocalStorageLoader object that loads
+// configuration from browser's localStorage.
+func NewLocalStorageLoader(opts ...LocalStorageLoaderOption) LocalStorageLoader {
+	loader := LocalStorageLoader{
+		valueFormat: RemoteValuePlain,
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&loader)
+	}
+
+	return loader
+}
+
+// Load returns a configuration key-value map from localStorage items, or an error
+// if something bad happens along the process.
+func (loader LocalStorageLoader) Load() (configMap map[string]any, err error) {
+	defer func() { // accessing localStorage may throw (ex: SecurityError), which panics.
+		if r := recover(); r != nil {
+			configMap, err = nil, ErrLocalStorageUnavailable
+		}
+	}()
+
+	storage := js.Global().Get("localStorage")
+	if storage.IsUndefined() || storage.IsNull() {
+		return nil, ErrLocalStorageUnavailable
+	}
+
+	length := storage.Get("length").Int()
+	configMap = make(map[string]any, length)
+	for idx := 0; idx < length; idx++ {
+		itemKey := storage.Call("key", idx)
+		if itemKey.IsNull() {
+			continue
+		}
+		key := itemKey.String()
+		if !strings.HasPrefix(key, loader.prefix) {
+			continue
+		}
+		value := storage.Call("getItem", key)
+		if value.IsNull() {
+			continue
+		}
+		itemConfigMap, err := getRemoteKVPairConfigMap(key, []byte(value.String()), loader.valueFormat)
+		if err != nil {
+			return nil, err
+		}
+		// merge configs from different items.
+		// Note: here, if a duplicate key exists, it will get overwritten.
+		for k, v := range itemConfigMap {
+			configMap[k] = v
+		}
+	}
+
+	return configMap, nil
+}
+
+// LocalStorageLoaderOption defines optional function for configuring
+// a LocalStorage Loader.
+type LocalStorageLoaderOption func(*LocalStorageLoader)
+
+// LocalStorageLoaderWithPrefix loads only the items whose keys have given prefix.
+// By default, all items are loaded.
+func LocalStorageLoaderWithPrefix(prefix string) LocalStorageLoaderOption {
+	return func(loader *LocalStorageLoader) {
+		loader.prefix = prefix
+	}
+}
+
+// LocalStorageLoaderWithValueFormat sets the items' value format.
+//
+// If is set to [RemoteValueJSON], the item's value will be treated as JSON
+// and configuration will be loaded from it.
+//
+// If is set to [RemoteValueYAML], the item's value will be treated as YAML
+// and configuration will be loaded from it.
+//
+// If is set to [RemoteValuePlain], the item's value will be treated as plain content
+// and configuration will contain the item's key and its plain value.
+//
+// If is set to [RemoteValueTOML], [RemoteValueIni], [RemoteValueProperties], [RemoteValueDotEnv],
+// the item's value will be treated accordingly, like the files of the same format,
+// and configuration will be loaded from it.
+//
+// By default, is set to [RemoteValuePlain].
+func LocalStorageLoaderWithValueFormat(valueFormat string) LocalStorageLoaderOption {
+	return func(loader *LocalStorageLoader) {
+		if isValidRemoteValueFormat(valueFormat) {
+			loader.valueFormat = valueFormat
+		}
+	}
+}
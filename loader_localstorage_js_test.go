@@ -0,0 +1,80 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"syscall/js"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+// newLocalStorageMock returns a JS object mimicking browser's localStorage, holding given items.
+func newLocalStorageMock(items map[string]any) js.Value {
+	return js.Global().Get("Function").New("items", `
+		const keys = Object.keys(items).sort();
+		return {
+			get length() { return keys.length; },
+			key(idx) { return idx < keys.length ? keys[idx] : null; },
+			getItem(key) { return key in items ? items[key] : null; },
+		};
+	`).Invoke(js.ValueOf(items))
+}
+
+func TestLocalStorageLoader(t *testing.T) {
+	// Note: tests are not run in parallel, as they change the global localStorage.
+
+	t.Run("success - plain items with prefix", testLocalStorageLoaderPlainWithPrefix)
+	t.Run("success - json items", testLocalStorageLoaderJSON)
+	t.Run("error - localStorage unavailable", testLocalStorageLoaderReturnsErrUnavailable)
+}
+
+func testLocalStorageLoaderPlainWithPrefix(t *testing.T) {
+	// arrange
+	js.Global().Set("localStorage", newLocalStorageMock(map[string]any{
+		"app.theme":   "dark",
+		"app.lang":    "en",
+		"other.thing": "ignored",
+	}))
+	defer js.Global().Delete("localStorage")
+	subject := xconf.NewLocalStorageLoader(xconf.LocalStorageLoaderWithPrefix("app."))
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"app.theme": "dark", "app.lang": "en"}, config)
+}
+
+func testLocalStorageLoaderJSON(t *testing.T) {
+	// arrange
+	js.Global().Set("localStorage", newLocalStorageMock(map[string]any{
+		"config": `{"theme":"dark","features":{"new_ui":true}}`,
+	}))
+	defer js.Global().Delete("localStorage")
+	subject := xconf.NewLocalStorageLoader(xconf.LocalStorageLoaderWithValueFormat(xconf.RemoteValueJSON))
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"theme": "dark", "features": map[string]any{"new_ui": true}}, config)
+}
+
+func testLocalStorageLoaderReturnsErrUnavailable(t *testing.T) {
+	// arrange
+	subject := xconf.NewLocalStorageLoader()
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrLocalStorageUnavailable))
+	assertNil(t, config)
+}
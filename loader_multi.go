@@ -6,7 +6,9 @@
 package xconf
 
 import (
+	"context"
 	"fmt"
+	"reflect"
 	"strings"
 	"sync"
 
@@ -34,12 +36,22 @@ func (e KeyConflictError) Error() string {
 
 // MultiLoader is a composite loader that returns
 // configurations from multiple loaders.
+// Loaders are loaded concurrently (see [MultiLoaderWithConcurrency] to limit it).
+// A panic occurred in a loader is recovered and returned as an error (see [ErrLoaderPanicked]).
 type MultiLoader struct {
 	// loaders to load configuration from.
 	loaders []Loader
 	// allowKeyOverwrite is a flag that indicates whether a duplicate key
 	// is allowed to be overwritten.
 	allowKeyOverwrite bool
+	// provenance keeps track of which loader provided which keys, see LoadPrefix.
+	provenance *multiLoaderProvenance
+	// deepMerge is a flag that indicates whether nested maps are merged recursively.
+	deepMerge bool
+	// sliceMerge is the strategy for merging slices, when deepMerge is set.
+	sliceMerge SliceMergeStrategy
+	// concurrency is the maximum number of loaders loading at the same time, 0 meaning unbounded.
+	concurrency int
 }
 
 // NewMultiLoader instantiates a new MultiLoader object that loads
@@ -55,12 +67,43 @@ func NewMultiLoader(allowKeyOverwrite bool, loaders ...Loader) MultiLoader {
 	return MultiLoader{
 		loaders:           loaders,
 		allowKeyOverwrite: allowKeyOverwrite,
+		provenance:        new(multiLoaderProvenance),
 	}
 }
 
+// NewMultiLoaderWithOptions instantiates a new MultiLoader object that loads
+// and merges configuration from multiple loaders, configured through options.
+// By default, a duplicate key results in a [KeyConflictError] (see [MultiLoaderWithKeyOverwrite]).
+//
+// Example, deep merging two YAML files which both define a "db" subtree:
+//
+//	loader := xconf.NewMultiLoaderWithOptions(
+//		[]xconf.Loader{xconf.YAMLFileLoader("base.yaml"), xconf.YAMLFileLoader("prod.yaml")},
+//		xconf.MultiLoaderWithDeepMerge(),
+//		xconf.MultiLoaderWithSliceMerge(xconf.SliceMergeUnique),
+//	)
+func NewMultiLoaderWithOptions(loaders []Loader, opts ...MultiLoaderOption) MultiLoader {
+	loader := NewMultiLoader(false, loaders...)
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&loader)
+	}
+
+	return loader
+}
+
 // Load returns a merged configuration key-value map of all encapsulated loaders,
 // or an error if something bad happens along the process.
 func (loader MultiLoader) Load() (map[string]any, error) {
+	return loader.LoadContext(context.Background())
+}
+
+// LoadContext is like Load, passing given context to the encapsulated loaders.
+// If the context is done before all loaders finish (or start, if concurrency is limited),
+// context's error is returned.
+// It implements [ContextLoader].
+func (loader MultiLoader) LoadContext(ctx context.Context) (map[string]any, error) {
 	var (
 		wg        sync.WaitGroup
 		mu        sync.Mutex
@@ -72,11 +115,37 @@ func (loader MultiLoader) Load() (map[string]any, error) {
 	)
 
 	// load async each loader.
-	for idx, loader := range loader.loaders {
+	sem := newLoadSemaphore(loader.concurrency)
+	for idx, ldr := range loader.loaders {
+		if err := sem.acquire(ctx); err != nil {
+			mu.Lock()
+			results[idx] = loadResult{err: err} // loader was not started.
+			mu.Unlock()
+
+			continue
+		}
 		wg.Add(1)
-		go loadAsync(loader, idx, &wg, &mu, results)
+		go func(idx int, ldr Loader) {
+			defer sem.release()
+			loadAsync(ctx, ldr, idx, &wg, &mu, results)
+		}(idx, ldr)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select { // do not wait for loaders not honoring the context.
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-done:
+	}
+
+	// collect keys' provenance before merging, as first loader's config map may get altered.
+	var loadersKeys []map[string]struct{}
+	if loader.provenance.isEnabled() {
+		loadersKeys = collectLoadersKeys(results, "")
 	}
-	wg.Wait()
 
 	// micro-optimization not to make extra allocation(s) (see benchmarks):
 	// when allowKeyOverwrite is true we can append directly to first loader's config map
@@ -110,17 +179,197 @@ func (loader MultiLoader) Load() (map[string]any, error) {
 				unqKeys[unqKey] = struct{}{}
 			}
 
-			configMap[key] = value
+			loader.merge(configMap, key, value)
 		}
 	}
 
 	if err := mErr.ErrOrNil(); err != nil {
 		return nil, err
 	}
+	if loadersKeys != nil {
+		loader.provenance.set(loadersKeys)
+	}
 
 	return configMap, nil
 }
 
+// Close closes the encapsulated loaders which implement [io.Closer].
+// All loaders are tried to be closed, errors being aggregated.
+func (loader MultiLoader) Close() error {
+	return CloseLoaders(loader)
+}
+
+// Unwrap returns the encapsulated loaders.
+func (loader MultiLoader) Unwrap() []Loader {
+	return loader.loaders
+}
+
+// mapLoaders returns a copy of the loader, encapsulating the loaders returned by fn.
+func (loader MultiLoader) mapLoaders(fn func(Loader) Loader) Loader {
+	loaders := make([]Loader, len(loader.loaders))
+	for idx, innerLoader := range loader.loaders {
+		loaders[idx] = fn(innerLoader)
+	}
+	loader.loaders = loaders
+
+	return loader
+}
+
+// merge sets key's value in configMap, merging it with key's existing value, if deep merge is configured.
+func (loader MultiLoader) merge(configMap map[string]any, key string, value any) {
+	if existingValue, found := configMap[key]; found && loader.deepMerge {
+		value = deepMergeValues(existingValue, value, loader.sliceMerge)
+	}
+	configMap[key] = value
+}
+
+// deepMergeValues merges value over existing value: nested maps are merged recursively,
+// slices according to given strategy, any other value replaces the existing one.
+// Existing value is not altered, merged maps / slices being copies.
+func deepMergeValues(existingValue, value any, sliceMerge SliceMergeStrategy) any {
+	switch val := value.(type) {
+	case map[string]any:
+		existingMap, isMap := existingValue.(map[string]any)
+		if !isMap {
+			return value
+		}
+		merged := make(map[string]any, len(existingMap)+len(val))
+		for key, existingNestedValue := range existingMap {
+			merged[key] = existingNestedValue
+		}
+		for key, nestedValue := range val {
+			if existingNestedValue, found := merged[key]; found {
+				nestedValue = deepMergeValues(existingNestedValue, nestedValue, sliceMerge)
+			}
+			merged[key] = nestedValue
+		}
+
+		return merged
+	case []any:
+		existingSlice, isSlice := existingValue.([]any)
+		if !isSlice || sliceMerge == SliceMergeReplace {
+			return value
+		}
+		merged := make([]any, len(existingSlice), len(existingSlice)+len(val))
+		copy(merged, existingSlice)
+		for _, item := range val {
+			if sliceMerge == SliceMergeUnique && containsDeepEqual(merged, item) {
+				continue
+			}
+			merged = append(merged, item)
+		}
+
+		return merged
+	}
+
+	return value
+}
+
+// containsDeepEqual checks whether items contain given item.
+func containsDeepEqual(items []any, item any) bool {
+	for _, it := range items {
+		if reflect.DeepEqual(it, item) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SliceMergeStrategy defines how a [MultiLoader] configured with [MultiLoaderWithDeepMerge]
+// merges slices found under the same key.
+type SliceMergeStrategy int
+
+const (
+	// SliceMergeReplace replaces previous loader's slice with later loader's one.
+	SliceMergeReplace SliceMergeStrategy = iota
+	// SliceMergeAppend appends later loader's slice items to previous loader's slice.
+	SliceMergeAppend
+	// SliceMergeUnique appends later loader's slice items not found in previous loader's slice.
+	SliceMergeUnique
+)
+
+// MultiLoaderOption defines optional function for configuring
+// a Multi Loader.
+type MultiLoaderOption func(*MultiLoader)
+
+// MultiLoaderWithKeyOverwrite allows a duplicate key to be overwritten,
+// a later provided loader overwriting a previous provided loader's same found key.
+// By default, a [KeyConflictError] is returned.
+func MultiLoaderWithKeyOverwrite() MultiLoaderOption {
+	return func(loader *MultiLoader) {
+		loader.allowKeyOverwrite = true
+	}
+}
+
+// MultiLoaderWithDeepMerge makes nested maps found under the same key be merged recursively,
+// instead of later loader's value replacing the whole previous loader's value.
+// For example, "db: {host: localhost, port: 5432}" and "db: {host: db.prod}" result in
+// "db: {host: db.prod, port: 5432}".
+// Slices are replaced, by default (see [MultiLoaderWithSliceMerge]). Key overwrite is implied.
+func MultiLoaderWithDeepMerge() MultiLoaderOption {
+	return func(loader *MultiLoader) {
+		loader.deepMerge = true
+		loader.allowKeyOverwrite = true
+	}
+}
+
+// MultiLoaderWithConcurrency limits the number of loaders loading at the same time
+// (like a worker pool's size), for backends which behave badly when hit in parallel
+// (rate-limited APIs, files behind NFS, ...). A value <= 0 means unbounded, which is the default.
+func MultiLoaderWithConcurrency(concurrency int) MultiLoaderOption {
+	return func(loader *MultiLoader) {
+		loader.concurrency = max(concurrency, 0)
+	}
+}
+
+// MultiLoaderWithSequentialLoad makes the loaders load one at a time, in the order they were provided.
+// It is equivalent to MultiLoaderWithConcurrency(1).
+func MultiLoaderWithSequentialLoad() MultiLoaderOption {
+	return MultiLoaderWithConcurrency(1)
+}
+
+// MultiLoaderWithSliceMerge sets the strategy for merging slices, when deep merge is enabled
+// (see [MultiLoaderWithDeepMerge]). By default, [SliceMergeReplace] is used.
+func MultiLoaderWithSliceMerge(strategy SliceMergeStrategy) MultiLoaderOption {
+	return func(loader *MultiLoader) {
+		loader.sliceMerge = strategy
+	}
+}
+
+// loadSemaphore limits the number of concurrent loads. A nil semaphore does not limit them.
+type loadSemaphore chan struct{}
+
+// newLoadSemaphore returns a semaphore allowing given number of concurrent loads (unbounded, if <= 0).
+func newLoadSemaphore(concurrency int) loadSemaphore {
+	if concurrency <= 0 {
+		return nil
+	}
+
+	return make(loadSemaphore, concurrency)
+}
+
+// acquire blocks until a load is allowed, or until given context is done,
+// in which case context's error is returned.
+func (sem loadSemaphore) acquire(ctx context.Context) error {
+	if sem == nil {
+		return nil
+	}
+	select {
+	case sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release marks a load as finished.
+func (sem loadSemaphore) release() {
+	if sem != nil {
+		<-sem
+	}
+}
+
 // loadResult encapsulates the result from a Loader.
 type loadResult struct {
 	configMap map[string]any // configMap is the loaded key-value configuration.
@@ -130,13 +379,14 @@ type loadResult struct {
 // loadAsync calls a Loader asynchronous.
 // Result is put in a results slice.
 func loadAsync(
+	ctx context.Context,
 	loader Loader,
 	idx int,
 	wg *sync.WaitGroup,
 	mu *sync.Mutex,
 	results []loadResult,
 ) {
-	configMap, err := loader.Load()
+	configMap, err := safeLoad(ctx, loader) // a panic would crash the app as it occurs in a goroutine.
 	result := loadResult{
 		configMap: configMap,
 		err:       err,
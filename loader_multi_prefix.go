@@ -0,0 +1,229 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/actforgood/xerr"
+)
+
+// PrefixLoader can be implemented by a loader able to (re)load only the keys having a prefix,
+// querying only the sources responsible for them. See DefaultConfig's ReloadPrefix.
+type PrefixLoader interface {
+	// LoadPrefix returns the configuration keys having given prefix (matched case-insensitive),
+	// and their values. The returned map may contain other keys, too, callers should filter them.
+	LoadPrefix(ctx context.Context, prefix string) (map[string]any, error)
+}
+
+// LoadPrefix returns a merged configuration key-value map of the keys having given prefix
+// (matched case-insensitive), re-querying only the encapsulated loaders responsible for them,
+// meaning the loaders which provided such keys at the previous load.
+// If no loader is known to be responsible for the prefix (like at first call), all loaders are queried.
+// Encapsulated loaders which implement [PrefixLoader] are asked only for the prefix, too.
+// It implements [PrefixLoader].
+//
+// Note: keeping track of keys' provenance starts with the first LoadPrefix call,
+// it has no cost if LoadPrefix is never called.
+func (loader MultiLoader) LoadPrefix(ctx context.Context, prefix string) (map[string]any, error) {
+	if loader.provenance != nil {
+		atomic.StoreInt32(&loader.provenance.enabled, 1)
+	}
+	var (
+		responsible = loader.provenance.responsibleLoaders(prefix, len(loader.loaders))
+		results     = make([]loadResult, len(loader.loaders))
+		wg          sync.WaitGroup
+		mu          sync.Mutex
+		done        = make(chan struct{})
+	)
+	sem := newLoadSemaphore(loader.concurrency)
+	for _, idx := range responsible {
+		if err := sem.acquire(ctx); err != nil {
+			mu.Lock()
+			results[idx] = loadResult{err: err} // loader was not started.
+			mu.Unlock()
+
+			continue
+		}
+		wg.Add(1)
+		go func(idx int) {
+			defer sem.release()
+			loadPrefixAsync(ctx, loader.loaders[idx], prefix, idx, &wg, &mu, results)
+		}(idx)
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-done:
+	}
+
+	var (
+		configMap = make(map[string]any)
+		unqKeys   = make(map[string]struct{})
+		mErr      *xerr.MultiError
+	)
+	// merge the results in the order loaders were provided.
+	for _, idx := range responsible {
+		loadResult := results[idx]
+		if loadResult.err != nil {
+			mErr = mErr.Add(loadResult.err)
+
+			continue
+		}
+		for key, value := range loadResult.configMap {
+			if !hasPrefixFold(key, prefix) {
+				continue
+			}
+			if !loader.allowKeyOverwrite {
+				unqKey := strings.ToLower(key)
+				if _, found := unqKeys[unqKey]; found {
+					mErr = mErr.Add(NewKeyConflictError(key))
+
+					continue
+				}
+				unqKeys[unqKey] = struct{}{}
+			}
+
+			loader.merge(configMap, key, value)
+		}
+	}
+	if err := mErr.ErrOrNil(); err != nil {
+		return nil, err
+	}
+	loader.provenance.update(prefix, responsible, collectLoadersKeys(results, prefix))
+
+	return configMap, nil
+}
+
+// loadPrefixAsync calls a Loader asynchronous, asking only for the prefix, if it is a [PrefixLoader].
+// Result is put in a results slice.
+func loadPrefixAsync(
+	ctx context.Context,
+	loader Loader,
+	prefix string,
+	idx int,
+	wg *sync.WaitGroup,
+	mu *sync.Mutex,
+	results []loadResult,
+) {
+	prefixLoader, isPrefixLoader := loader.(PrefixLoader)
+	if !isPrefixLoader {
+		loadAsync(ctx, loader, idx, wg, mu, results)
+
+		return
+	}
+
+	configMap, err := safeLoad(ctx, ContextLoaderFunc(func(ctx context.Context) (map[string]any, error) {
+		return prefixLoader.LoadPrefix(ctx, prefix)
+	}))
+	mu.Lock()
+	results[idx] = loadResult{configMap: configMap, err: err}
+	mu.Unlock()
+	wg.Done()
+}
+
+// collectLoadersKeys returns, for each loader's result, its keys having given prefix
+// (all keys, if prefix is empty). A failed / missing result has nil keys.
+func collectLoadersKeys(results []loadResult, prefix string) []map[string]struct{} {
+	loadersKeys := make([]map[string]struct{}, len(results))
+	for idx, result := range results {
+		if result.err != nil || result.configMap == nil {
+			continue
+		}
+		keys := make(map[string]struct{}, len(result.configMap))
+		for key := range result.configMap {
+			if prefix == "" || hasPrefixFold(key, prefix) {
+				keys[key] = struct{}{}
+			}
+		}
+		loadersKeys[idx] = keys
+	}
+
+	return loadersKeys
+}
+
+// multiLoaderProvenance keeps track of the keys each of a MultiLoader's loaders provided.
+type multiLoaderProvenance struct {
+	// enabled is a flag indicating whether provenance is tracked (it gets enabled by LoadPrefix).
+	enabled int32
+	// mu is a concurrency semaphore for accessing loadersKeys.
+	mu sync.RWMutex
+	// loadersKeys holds, for each loader, the keys it provided.
+	loadersKeys []map[string]struct{}
+}
+
+// isEnabled returns true if provenance is tracked.
+func (provenance *multiLoaderProvenance) isEnabled() bool {
+	return provenance != nil && atomic.LoadInt32(&provenance.enabled) == 1
+}
+
+// set replaces all loaders' keys.
+func (provenance *multiLoaderProvenance) set(loadersKeys []map[string]struct{}) {
+	provenance.mu.Lock()
+	provenance.loadersKeys = loadersKeys
+	provenance.mu.Unlock()
+}
+
+// update replaces, for given loaders, their keys having given prefix.
+func (provenance *multiLoaderProvenance) update(prefix string, idxs []int, loadersKeys []map[string]struct{}) {
+	if provenance == nil {
+		return
+	}
+	provenance.mu.Lock()
+	defer provenance.mu.Unlock()
+
+	if len(provenance.loadersKeys) != len(loadersKeys) {
+		provenance.loadersKeys = make([]map[string]struct{}, len(loadersKeys))
+	}
+	for _, idx := range idxs {
+		keys := provenance.loadersKeys[idx]
+		if keys == nil {
+			keys = make(map[string]struct{}, len(loadersKeys[idx]))
+			provenance.loadersKeys[idx] = keys
+		}
+		for key := range keys {
+			if hasPrefixFold(key, prefix) {
+				delete(keys, key)
+			}
+		}
+		for key := range loadersKeys[idx] {
+			keys[key] = struct{}{}
+		}
+	}
+}
+
+// responsibleLoaders returns the indexes of the loaders which provided keys having given prefix.
+// If none is known, all loaders' indexes are returned.
+func (provenance *multiLoaderProvenance) responsibleLoaders(prefix string, loadersCnt int) []int {
+	idxs := make([]int, 0, loadersCnt)
+	if provenance != nil {
+		provenance.mu.RLock()
+		for idx, keys := range provenance.loadersKeys {
+			for key := range keys {
+				if hasPrefixFold(key, prefix) {
+					idxs = append(idxs, idx)
+
+					break
+				}
+			}
+		}
+		provenance.mu.RUnlock()
+	}
+	if len(idxs) == 0 {
+		for idx := 0; idx < loadersCnt; idx++ {
+			idxs = append(idxs, idx)
+		}
+	}
+
+	return idxs
+}
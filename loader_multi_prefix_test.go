@@ -0,0 +1,155 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+// countingLoader is a Loader which counts its loads.
+type countingLoader struct {
+	xconf.Loader
+	loadsCnt uint32
+}
+
+func (loader *countingLoader) Load() (map[string]any, error) {
+	atomic.AddUint32(&loader.loadsCnt, 1)
+
+	return loader.Loader.Load()
+}
+
+func (loader *countingLoader) LoadsCount() int {
+	return int(atomic.LoadUint32(&loader.loadsCnt))
+}
+
+func TestMultiLoader_LoadPrefix(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - only responsible loaders are queried", testMultiLoaderLoadPrefixQueriesResponsibleLoaders)
+	t.Run("success - nested prefix loader", testMultiLoaderLoadPrefixNested)
+	t.Run("error - key conflict", testMultiLoaderLoadPrefixReturnsKeyConflictErr)
+	t.Run("error - context canceled", testMultiLoaderLoadPrefixReturnsCtxErr)
+}
+
+func testMultiLoaderLoadPrefixQueriesResponsibleLoaders(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		features = &countingLoader{Loader: xconf.PlainLoader(map[string]any{
+			"feature.a": true,
+			"feature.b": false,
+			"other":     "x",
+		})}
+		overrides = &countingLoader{Loader: xconf.PlainLoader(map[string]any{"Feature.b": true})}
+		db        = &countingLoader{Loader: xconf.PlainLoader(map[string]any{"db.host": "10.0.0.1"})}
+		subject   = xconf.NewMultiLoader(true, features, overrides, db)
+	)
+
+	// act - first call, provenance is unknown.
+	configMap, err := subject.LoadPrefix(context.Background(), "feature.")
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"feature.a": true, "feature.b": false, "Feature.b": true}, configMap)
+	assertEqual(t, 1, features.LoadsCount())
+	assertEqual(t, 1, overrides.LoadsCount())
+	assertEqual(t, 1, db.LoadsCount())
+
+	// act - provenance is known.
+	configMap, err = subject.LoadPrefix(context.Background(), "FEATURE.")
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 3, len(configMap))
+	assertEqual(t, 2, features.LoadsCount())
+	assertEqual(t, 2, overrides.LoadsCount())
+	assertEqual(t, 1, db.LoadsCount())
+
+	// act - a full load updates provenance.
+	_, err = subject.Load()
+	requireNil(t, err)
+	configMap, err = subject.LoadPrefix(context.Background(), "db.")
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"db.host": "10.0.0.1"}, configMap)
+	assertEqual(t, 3, features.LoadsCount())
+	assertEqual(t, 3, overrides.LoadsCount())
+	assertEqual(t, 3, db.LoadsCount())
+}
+
+func testMultiLoaderLoadPrefixNested(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		features = &countingLoader{Loader: xconf.PlainLoader(map[string]any{"feature.a": true})}
+		db       = &countingLoader{Loader: xconf.PlainLoader(map[string]any{"db.host": "10.0.0.1"})}
+		subject  = xconf.NewMultiLoader(
+			true,
+			xconf.NewMultiLoader(true, features, db),
+			xconf.PlainLoader(map[string]any{"app.name": "demo"}),
+		)
+	)
+	_, _ = subject.LoadPrefix(context.Background(), "feature.")
+
+	// act
+	configMap, err := subject.LoadPrefix(context.Background(), "feature.")
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"feature.a": true}, configMap)
+	assertEqual(t, 2, features.LoadsCount())
+	assertEqual(t, 1, db.LoadsCount())
+}
+
+func testMultiLoaderLoadPrefixReturnsKeyConflictErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.NewMultiLoader(
+		false,
+		xconf.PlainLoader(map[string]any{"feature.a": true, "db.host": "10.0.0.1"}),
+		xconf.PlainLoader(map[string]any{"feature.a": false, "db.host": "10.0.0.1"}),
+	)
+
+	// act
+	configMap, err := subject.LoadPrefix(context.Background(), "feature.")
+
+	// assert
+	assertEqual(t, xconf.NewKeyConflictError("feature.a").Error(), err.Error())
+	assertNil(t, configMap)
+}
+
+func testMultiLoaderLoadPrefixReturnsCtxErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		slowLoader = xconf.LoaderFunc(func() (map[string]any, error) {
+			time.Sleep(100 * time.Millisecond)
+
+			return map[string]any{"feature.a": true}, nil
+		})
+		subject     = xconf.NewMultiLoader(true, slowLoader)
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	)
+	defer cancel()
+
+	// act
+	configMap, err := subject.LoadPrefix(ctx, "feature.")
+
+	// assert
+	assertTrue(t, errors.Is(err, context.DeadlineExceeded))
+	assertNil(t, configMap)
+}
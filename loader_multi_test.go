@@ -6,9 +6,12 @@
 package xconf_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/actforgood/xconf"
 )
@@ -20,6 +23,256 @@ func TestMultiLoader(t *testing.T) {
 	t.Run("error - from loaders", testMultiLoaderReturnsLoadErr)
 	t.Run("error - key conflict", testMultiLoaderReturnsKeyConflictErr)
 	t.Run("success - safe-mutable config map", testMultiLoaderReturnsSafeMutableConfigMap)
+	t.Run("close - closer loaders are closed", testMultiLoaderClose)
+	t.Run("error - panic in a loader is recovered", testMultiLoaderRecoversPanic)
+	t.Run("success - deep merge", testMultiLoaderWithDeepMerge)
+	t.Run("error - options, key conflict", testMultiLoaderWithOptionsReturnsKeyConflictErr)
+	t.Run("success - concurrency limit", testMultiLoaderWithConcurrency)
+	t.Run("error - concurrency limit, context done while waiting", testMultiLoaderWithConcurrencyReturnsCtxErr)
+}
+
+func testMultiLoaderWithConcurrencyReturnsCtxErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		secondLoadsCnt uint32
+		hung           = xconf.LoaderFunc(func() (map[string]any, error) {
+			time.Sleep(300 * time.Millisecond) // does not honor the context.
+
+			return map[string]any{"key1": 1}, nil
+		})
+		second = xconf.ContextLoaderFunc(func(context.Context) (map[string]any, error) {
+			atomic.AddUint32(&secondLoadsCnt, 1)
+
+			return map[string]any{"key2": 2}, nil
+		})
+		subject = xconf.NewMultiLoaderWithOptions(
+			[]xconf.Loader{hung, second},
+			xconf.MultiLoaderWithSequentialLoad(),
+		)
+	)
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelCtx()
+
+	// act
+	startTime := time.Now()
+	config, err := subject.LoadContext(ctx)
+	duration := time.Since(startTime)
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, errors.Is(err, context.DeadlineExceeded))
+	assertTrue(t, duration < 200*time.Millisecond)
+	assertEqual(t, uint32(0), atomic.LoadUint32(&secondLoadsCnt))
+
+	// arrange
+	ctx, cancelCtx = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelCtx()
+
+	// act
+	startTime = time.Now()
+	prefixConfig, prefixErr := subject.LoadPrefix(ctx, "key")
+	duration = time.Since(startTime)
+
+	// assert
+	assertNil(t, prefixConfig)
+	assertTrue(t, errors.Is(prefixErr, context.DeadlineExceeded))
+	assertTrue(t, duration < 200*time.Millisecond)
+	assertEqual(t, uint32(0), atomic.LoadUint32(&secondLoadsCnt))
+}
+
+func testMultiLoaderWithConcurrency(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name                  string
+		opt                   xconf.MultiLoaderOption
+		expectedMaxConcurrent int32
+	}{
+		{name: "sequential", opt: xconf.MultiLoaderWithSequentialLoad(), expectedMaxConcurrent: 1},
+		{name: "worker pool", opt: xconf.MultiLoaderWithConcurrency(2), expectedMaxConcurrent: 2},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var (
+				running, maxRunning int32
+				loaders             = make([]xconf.Loader, 6)
+			)
+			for i := range loaders {
+				idx := i // capture range variable
+				loaders[idx] = xconf.LoaderFunc(func() (map[string]any, error) {
+					current := atomic.AddInt32(&running, 1)
+					for {
+						prevMax := atomic.LoadInt32(&maxRunning)
+						if current <= prevMax || atomic.CompareAndSwapInt32(&maxRunning, prevMax, current) {
+							break
+						}
+					}
+					time.Sleep(10 * time.Millisecond)
+					atomic.AddInt32(&running, -1)
+
+					return map[string]any{fmt.Sprintf("key%d", idx): idx}, nil
+				})
+			}
+			subject := xconf.NewMultiLoaderWithOptions(loaders, test.opt)
+
+			// act
+			config, err := subject.Load()
+			prefixConfig, prefixErr := subject.LoadPrefix(context.Background(), "key")
+
+			// assert
+			assertNil(t, err)
+			assertEqual(t, len(loaders), len(config))
+			assertNil(t, prefixErr)
+			assertEqual(t, len(loaders), len(prefixConfig))
+			assertEqual(t, test.expectedMaxConcurrent, atomic.LoadInt32(&maxRunning))
+		})
+	}
+}
+
+func testMultiLoaderWithDeepMerge(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	newLoaders := func() []xconf.Loader {
+		return []xconf.Loader{
+			xconf.PlainLoader(map[string]any{
+				"db": map[string]any{
+					"host":    "localhost",
+					"port":    5432,
+					"options": map[string]any{"sslmode": "disable", "timeout": 5},
+					"hosts":   []any{"h1", "h2"},
+				},
+				"log_level": "info",
+			}),
+			xconf.PlainLoader(map[string]any{
+				"db": map[string]any{
+					"host":    "db.prod",
+					"options": map[string]any{"sslmode": "require"},
+					"hosts":   []any{"h2", "h3"},
+				},
+				"cache": "redis",
+			}),
+			xconf.PlainLoader(map[string]any{"log_level": map[string]any{"root": "error"}}),
+		}
+	}
+	tests := [...]struct {
+		name          string
+		sliceMerge    xconf.SliceMergeStrategy
+		expectedHosts []any
+	}{
+		{name: "replace slices", sliceMerge: xconf.SliceMergeReplace, expectedHosts: []any{"h2", "h3"}},
+		{name: "append slices", sliceMerge: xconf.SliceMergeAppend, expectedHosts: []any{"h1", "h2", "h2", "h3"}},
+		{name: "unique slices", sliceMerge: xconf.SliceMergeUnique, expectedHosts: []any{"h1", "h2", "h3"}},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			loaders := newLoaders()
+			subject := xconf.NewMultiLoaderWithOptions(
+				loaders,
+				xconf.MultiLoaderWithDeepMerge(),
+				xconf.MultiLoaderWithSliceMerge(test.sliceMerge),
+			)
+
+			// act
+			config, err := subject.Load()
+
+			// assert
+			assertNil(t, err)
+			assertEqual(
+				t,
+				map[string]any{
+					"db": map[string]any{
+						"host":    "db.prod",
+						"port":    5432,
+						"options": map[string]any{"sslmode": "require", "timeout": 5},
+						"hosts":   test.expectedHosts,
+					},
+					"cache":     "redis",
+					"log_level": map[string]any{"root": "error"},
+				},
+				config,
+			)
+			// first loader's nested values are not altered.
+			firstConfig, _ := loaders[0].Load()
+			assertEqual(t, "localhost", firstConfig["db"].(map[string]any)["host"])
+			assertEqual(t, []any{"h1", "h2"}, firstConfig["db"].(map[string]any)["hosts"])
+		})
+	}
+}
+
+func testMultiLoaderWithOptionsReturnsKeyConflictErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	loaders := []xconf.Loader{
+		xconf.PlainLoader(map[string]any{"foo": "bar"}),
+		xconf.PlainLoader(map[string]any{"foo": "baz"}),
+	}
+	subject := xconf.NewMultiLoaderWithOptions(loaders)
+	subjectWithOverwrite := xconf.NewMultiLoaderWithOptions(loaders, xconf.MultiLoaderWithKeyOverwrite())
+
+	// act
+	config, err := subject.Load()
+	configWithOverwrite, errWithOverwrite := subjectWithOverwrite.Load()
+
+	// assert
+	var conflictErr xconf.KeyConflictError
+	assertTrue(t, errors.As(err, &conflictErr))
+	assertNil(t, config)
+	assertNil(t, errWithOverwrite)
+	assertEqual(t, map[string]any{"foo": "baz"}, configWithOverwrite)
+}
+
+func testMultiLoaderRecoversPanic(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loader1 = xconf.PlainLoader(map[string]any{"foo": "bar"})
+		loader2 = xconf.LoaderFunc(func() (map[string]any, error) {
+			panic("intentionally triggered panic")
+		})
+		subject = xconf.NewMultiLoader(true, loader1, loader2)
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, errors.Is(err, xconf.ErrLoaderPanicked))
+}
+
+func testMultiLoaderClose(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered close error")
+		loader1     = &closerLoaderMock{Loader: xconf.PlainLoader(nil), closeErr: expectedErr}
+		loader2     = xconf.PlainLoader(nil)
+		loader3     = &closerLoaderMock{Loader: xconf.PlainLoader(nil)}
+		subject     = xconf.NewMultiLoader(true, loader1, loader2, loader3)
+	)
+
+	// act
+	err := subject.Close()
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertEqual(t, uint32(1), atomic.LoadUint32(&loader1.closeCallsCnt))
+	assertEqual(t, uint32(1), atomic.LoadUint32(&loader3.closeCallsCnt))
 }
 
 func testMultiLoaderSuccess(t *testing.T) {
@@ -0,0 +1,168 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/actforgood/xerr"
+)
+
+// ErrInvalidOverride is an error returned by [OverridesLoader] if an override
+// is not in "key=value" format.
+var ErrInvalidOverride = errors.New("invalid override, expected key=value")
+
+// overrides' flags.
+const (
+	overrideFlagShort     = "-X"
+	overrideFlagSet       = "set"
+	overrideFlagSetString = "set-string"
+)
+
+// OverridesLoader loads ad-hoc (operator) overrides from command line arguments,
+// Helm-like: repeated "-X key=value", "--set key=value", "--set-string key=value".
+// Multiple overrides can be passed in a single flag, comma separated ("--set a=1,b=2",
+// a comma inside a value can be escaped with "\,"). Other arguments are ignored,
+// so you can pass os.Args[1:] directly.
+//
+// Keys are split by separator (by default, ".") into nested maps ("db.host=x" => {"db": {"host": "x"}}).
+// Values' types are inferred (except for "--set-string"): "true"/"false" => bool,
+// integers => int, decimals => float64, "null" => nil, "{a,b}" => []any, otherwise string.
+//
+// It's intended to be the highest precedence layer. As overrides are nested, apply them with an
+// [OverlayLoader] in [OverlayMergePatch] mode, so that they do not replace entire subtrees:
+//
+//	loader := xconf.OverlayLoader(baseLoader, xconf.OverridesLoader(os.Args[1:]), xconf.OverlayMergePatch)
+func OverridesLoader(args []string, separator ...string) Loader {
+	sep := "."
+	if len(separator) > 0 {
+		sep = separator[0]
+	}
+
+	return LoaderFunc(func() (map[string]any, error) {
+		configMap := make(map[string]any)
+		for idx := 0; idx < len(args); idx++ {
+			flagName, value, hasValue := parseOverrideFlag(args[idx])
+			if flagName == "" {
+				continue
+			}
+			if !hasValue {
+				if idx+1 >= len(args) {
+					return nil, xerr.Wrapf(ErrInvalidOverride, "missing value for %s", args[idx])
+				}
+				idx++
+				value = args[idx]
+			}
+			for _, override := range splitOverrides(value) {
+				key, rawValue, found := strings.Cut(override, "=")
+				if !found || key == "" {
+					return nil, xerr.Wrapf(ErrInvalidOverride, "%q", override)
+				}
+				var overrideValue any = rawValue
+				if flagName != overrideFlagSetString {
+					overrideValue = inferOverrideValue(rawValue)
+				}
+				setNestedValue(configMap, strings.Split(key, sep), overrideValue)
+			}
+		}
+
+		return configMap, nil
+	})
+}
+
+// parseOverrideFlag returns the override flag name found in given argument,
+// and its value, if given in "flag=value" format.
+// An empty flag name is returned if argument is not an override flag.
+func parseOverrideFlag(arg string) (string, string, bool) {
+	name, value, hasValue := strings.Cut(arg, "=")
+	if name == overrideFlagShort {
+		return overrideFlagShort, value, hasValue
+	}
+	if strings.HasPrefix(name, "-") {
+		name = strings.TrimPrefix(strings.TrimPrefix(name, "-"), "-")
+		if name == overrideFlagSet || name == overrideFlagSetString {
+			return name, value, hasValue
+		}
+	}
+
+	return "", "", false
+}
+
+// splitOverrides splits comma separated overrides, taking into account
+// escaped commas and commas inside lists ("{a,b}").
+func splitOverrides(value string) []string {
+	var (
+		overrides []string
+		current   strings.Builder
+		depth     int
+	)
+	for idx := 0; idx < len(value); idx++ {
+		char := value[idx]
+		switch {
+		case char == '\\' && idx+1 < len(value) && value[idx+1] == ',':
+			current.WriteByte(',')
+			idx++
+		case char == '{':
+			depth++
+			current.WriteByte(char)
+		case char == '}':
+			depth--
+			current.WriteByte(char)
+		case char == ',' && depth <= 0:
+			overrides = append(overrides, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(char)
+		}
+	}
+
+	return append(overrides, current.String())
+}
+
+// inferOverrideValue infers the type of an override's value.
+func inferOverrideValue(value string) any {
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	if intValue, err := strconv.Atoi(value); err == nil {
+		return intValue
+	}
+	if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+		return floatValue
+	}
+	if len(value) >= 2 && value[0] == '{' && value[len(value)-1] == '}' {
+		list := make([]any, 0)
+		if items := value[1 : len(value)-1]; items != "" {
+			for _, item := range splitOverrides(items) {
+				list = append(list, inferOverrideValue(item))
+			}
+		}
+
+		return list
+	}
+
+	return value
+}
+
+// setNestedValue sets the value at given path, creating nested maps if needed.
+func setNestedValue(configMap map[string]any, path []string, value any) {
+	for _, key := range path[:len(path)-1] {
+		nestedMap, isMap := configMap[key].(map[string]any)
+		if !isMap {
+			nestedMap = make(map[string]any)
+			configMap[key] = nestedMap
+		}
+		configMap = nestedMap
+	}
+	configMap[path[len(path)-1]] = value
+}
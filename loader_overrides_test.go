@@ -0,0 +1,122 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestOverridesLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - nested keys, inferred types", testOverridesLoaderNestedKeysInferredTypes)
+	t.Run("success - custom separator", testOverridesLoaderWithCustomSeparator)
+	t.Run("error - invalid override", testOverridesLoaderReturnsErr)
+}
+
+func testOverridesLoaderNestedKeysInferredTypes(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.OverridesLoader([]string{
+		"serve",
+		"-X", "db.host=10.0.0.2",
+		"--set", "db.port=5432,db.ratio=0.75,debug=true",
+		"--set=db.replicas={a,b,c}",
+		"-set-string", "db.version=1.10",
+		"-X=feature.note=hello\\, world",
+		"--verbose",
+		"--set", "db.password=null,empty={}",
+	})
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"db": map[string]any{
+				"host":     "10.0.0.2",
+				"port":     5432,
+				"ratio":    0.75,
+				"replicas": []any{"a", "b", "c"},
+				"version":  "1.10",
+				"password": nil,
+			},
+			"debug":   true,
+			"feature": map[string]any{"note": "hello, world"},
+			"empty":   []any{},
+		},
+		config,
+	)
+}
+
+func testOverridesLoaderWithCustomSeparator(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.OverridesLoader([]string{"--set", "DB_HOST=10.0.0.2"}, "__")
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"DB_HOST": "10.0.0.2"}, config)
+}
+
+func testOverridesLoaderReturnsErr(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name string
+		args []string
+	}{
+		{name: "not key=value", args: []string{"--set", "db.host"}},
+		{name: "empty key", args: []string{"-X", "=value"}},
+		{name: "missing value", args: []string{"--set"}},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := xconf.OverridesLoader(test.args)
+
+			// act
+			config, err := subject.Load()
+
+			// assert
+			assertTrue(t, errors.Is(err, xconf.ErrInvalidOverride))
+			assertNil(t, config)
+		})
+	}
+}
+
+func ExampleOverridesLoader() {
+	base := xconf.PlainLoader(map[string]any{
+		"db": map[string]any{"host": "10.0.0.1", "port": 5432},
+	})
+	args := []string{"--set", "db.host=10.0.0.2"} // os.Args[1:]
+	loader := xconf.OverlayLoader(base, xconf.OverridesLoader(args), xconf.OverlayMergePatch)
+
+	configMap, err := loader.Load()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(configMap["db"])
+
+	// Output:
+	// map[host:10.0.0.2 port:5432]
+}
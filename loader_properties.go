@@ -9,29 +9,57 @@ import (
 	"os"
 
 	"github.com/magiconair/properties"
+	"golang.org/x/text/encoding"
 )
 
+// PropertiesLoaderOption defines optional function for configuring a Properties loader.
+type PropertiesLoaderOption func(*propertiesLoaderOptions)
+
+// propertiesLoaderOptions holds the configuration of a Properties loader.
+type propertiesLoaderOptions struct {
+	enc encoding.Encoding // source encoding of the content, if not UTF-8.
+}
+
+// PropertiesLoaderWithEncoding sets the source encoding of the content,
+// for example [golang.org/x/text/encoding/charmap.ISO8859_1] for legacy Latin-1 files,
+// content being transcoded to UTF-8. By default, UTF-8 is assumed.
+func PropertiesLoaderWithEncoding(enc encoding.Encoding) PropertiesLoaderOption {
+	return func(opts *propertiesLoaderOptions) {
+		opts.enc = enc
+	}
+}
+
 // PropertiesFileLoader loads Java Properties configuration from a file.
 // The location of properties content based file is given as parameter.
-func PropertiesFileLoader(filePath string) Loader {
+func PropertiesFileLoader(filePath string, opts ...PropertiesLoaderOption) Loader {
 	return LoaderFunc(func() (map[string]any, error) {
 		content, err := os.ReadFile(filePath)
 		if err != nil {
 			return nil, err
 		}
 
-		return PropertiesBytesLoader(content).Load()
+		return PropertiesBytesLoader(content, opts...).Load()
 	})
 }
 
 // PropertiesBytesLoader loads Properties configuration from bytes.
-func PropertiesBytesLoader(propertiesContent []byte) Loader {
+func PropertiesBytesLoader(propertiesContent []byte, opts ...PropertiesLoaderOption) Loader {
+	var loaderOpts propertiesLoaderOptions
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&loaderOpts)
+	}
+
 	return LoaderFunc(func() (map[string]any, error) {
+		content, err := decodeBytes(propertiesContent, loaderOpts.enc)
+		if err != nil {
+			return nil, err
+		}
 		loader := properties.Loader{
 			Encoding:         properties.UTF8,
 			DisableExpansion: false,
 		}
-		cfg, err := loader.LoadBytes(propertiesContent)
+		cfg, err := loader.LoadBytes(content)
 		if err != nil {
 			return nil, err
 		}
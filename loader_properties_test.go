@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/actforgood/xconf"
+	"golang.org/x/text/encoding/charmap"
 )
 
 var propertiesConfigMap = map[string]any{
@@ -121,6 +122,31 @@ func TestPropertiesBytesLoader(t *testing.T) {
 	t.Run("success - valid content", testPropertiesBytesLoaderWithValidContent)
 	t.Run("error - invalid content", testPropertiesBytesLoaderWithInvalidContent)
 	t.Run("success - safe-mutable config map", testPropertiesBytesLoaderReturnsSafeMutableConfigMap)
+	t.Run("success - latin-1 encoded content", testPropertiesBytesLoaderWithLatin1Content)
+}
+
+func testPropertiesBytesLoaderWithLatin1Content(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		content = []byte("properties_city=Z\xfcrich\nproperties_drink=caf\xe9")
+		subject = xconf.PropertiesBytesLoader(content, xconf.PropertiesLoaderWithEncoding(charmap.ISO8859_1))
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"properties_city":  "Zürich",
+			"properties_drink": "café",
+		},
+		config,
+	)
 }
 
 func testPropertiesBytesLoaderWithValidContent(t *testing.T) {
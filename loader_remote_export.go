@@ -0,0 +1,129 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+)
+
+// consulExportKVPair is a key-value pair as dumped by "consul kv export".
+type consulExportKVPair struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"` // base64 encoded, decoded by json pkg.
+}
+
+// etcdExportKVPair is a key-value pair as dumped by "etcdctl get --prefix -w json".
+type etcdExportKVPair struct {
+	Key   []byte `json:"key"`   // base64 encoded, decoded by json pkg.
+	Value []byte `json:"value"` // base64 encoded, decoded by json pkg.
+}
+
+// ConsulExportFileLoader loads configuration from a "consul kv export" JSON dump file,
+// so that a configuration captured from a cluster can be replayed locally, without a running Consul.
+// See [ConsulExportReaderLoader] for more details.
+func ConsulExportFileLoader(filePath, keyPrefix, valueFormat string) Loader {
+	return LoaderFunc(func() (map[string]any, error) {
+		f, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		return ConsulExportReaderLoader(f, keyPrefix, valueFormat).Load()
+	})
+}
+
+// ConsulExportReaderLoader loads configuration from a "consul kv export" JSON dump,
+// read from an [io.Reader].
+// Only keys starting with keyPrefix are taken into account (pass exact key / empty string
+// to load a single key / all keys from the dump).
+// Value format can be any of RemoteValue* constants (like [RemoteValueJSON], [RemoteValueTOML]),
+// as for [ConsulLoader]. Configurations from different keys are merged, in dump's order.
+func ConsulExportReaderLoader(reader io.Reader, keyPrefix, valueFormat string) Loader {
+	return LoaderFunc(func() (map[string]any, error) {
+		if seekReader, ok := reader.(io.Seeker); ok {
+			_, _ = seekReader.Seek(0, io.SeekStart) // move to the beginning in case of a re-load needed.
+		}
+		var kvPairs []consulExportKVPair
+		if err := json.NewDecoder(reader).Decode(&kvPairs); err != nil {
+			return nil, err
+		}
+
+		configMap := make(map[string]any)
+		for _, kvPair := range kvPairs {
+			if err := mergeExportKVPair(configMap, kvPair.Key, kvPair.Value, keyPrefix, valueFormat); err != nil {
+				return nil, err
+			}
+		}
+
+		return configMap, nil
+	})
+}
+
+// EtcdExportFileLoader loads configuration from an "etcdctl get --prefix -w json" dump file,
+// so that a configuration captured from a cluster can be replayed locally, without a running Etcd.
+// See [EtcdExportReaderLoader] for more details.
+func EtcdExportFileLoader(filePath, keyPrefix, valueFormat string) Loader {
+	return LoaderFunc(func() (map[string]any, error) {
+		f, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		return EtcdExportReaderLoader(f, keyPrefix, valueFormat).Load()
+	})
+}
+
+// EtcdExportReaderLoader loads configuration from an "etcdctl get --prefix -w json" dump,
+// read from an [io.Reader].
+// Only keys starting with keyPrefix are taken into account (pass exact key / empty string
+// to load a single key / all keys from the dump).
+// Value format can be any of RemoteValue* constants (like [RemoteValueJSON], [RemoteValueTOML]),
+// as for [EtcdLoader]. Configurations from different keys are merged, in dump's order.
+func EtcdExportReaderLoader(reader io.Reader, keyPrefix, valueFormat string) Loader {
+	return LoaderFunc(func() (map[string]any, error) {
+		if seekReader, ok := reader.(io.Seeker); ok {
+			_, _ = seekReader.Seek(0, io.SeekStart) // move to the beginning in case of a re-load needed.
+		}
+		var dump struct {
+			KVs []etcdExportKVPair `json:"kvs"`
+		}
+		if err := json.NewDecoder(reader).Decode(&dump); err != nil {
+			return nil, err
+		}
+
+		configMap := make(map[string]any)
+		for _, kvPair := range dump.KVs {
+			if err := mergeExportKVPair(configMap, string(kvPair.Key), kvPair.Value, keyPrefix, valueFormat); err != nil {
+				return nil, err
+			}
+		}
+
+		return configMap, nil
+	})
+}
+
+// mergeExportKVPair merges an exported key's configuration into given configuration map,
+// if key has the given prefix.
+// Note: here, if a duplicate key exists, it will get overwritten.
+func mergeExportKVPair(configMap map[string]any, key string, value []byte, keyPrefix, format string) error {
+	if !strings.HasPrefix(key, keyPrefix) {
+		return nil
+	}
+	keyConfigMap, err := getRemoteKVPairConfigMap(key, value, format)
+	if err != nil {
+		return err
+	}
+	for configKey, configValue := range keyConfigMap {
+		configMap[configKey] = configValue
+	}
+
+	return nil
+}
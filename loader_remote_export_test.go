@@ -0,0 +1,149 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+const (
+	consulExportFilePath = "testdata/consul_export.json"
+	etcdExportFilePath   = "testdata/etcd_export.json"
+)
+
+func TestConsulExportFileLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - keys with prefix", testRemoteExportLoaderWithPrefix(xconf.ConsulExportFileLoader, consulExportFilePath))
+	t.Run("success - plain format, exact key", testRemoteExportLoaderPlainFormat(xconf.ConsulExportFileLoader, consulExportFilePath))
+	t.Run("error - not existing file", testRemoteExportLoaderReturnsErrForNotExistingFile(xconf.ConsulExportFileLoader))
+	t.Run("error - invalid dump", testConsulExportReaderLoaderReturnsErrForInvalidDump)
+}
+
+func TestEtcdExportFileLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - keys with prefix", testRemoteExportLoaderWithPrefix(xconf.EtcdExportFileLoader, etcdExportFilePath))
+	t.Run("success - plain format, exact key", testRemoteExportLoaderPlainFormat(xconf.EtcdExportFileLoader, etcdExportFilePath))
+	t.Run("error - not existing file", testRemoteExportLoaderReturnsErrForNotExistingFile(xconf.EtcdExportFileLoader))
+	t.Run("error - invalid dump", testEtcdExportReaderLoaderReturnsErrForInvalidDump)
+}
+
+type remoteExportFileLoaderFactory func(filePath, keyPrefix, valueFormat string) xconf.Loader
+
+func testRemoteExportLoaderWithPrefix(factory remoteExportFileLoaderFactory, filePath string) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := factory(filePath, "app/config", xconf.RemoteValueJSON)
+
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(
+			t,
+			map[string]any{
+				"app_host":  "10.0.0.1",
+				"app_port":  float64(8080),
+				"app_debug": true,
+			},
+			config,
+		)
+	}
+}
+
+func testRemoteExportLoaderPlainFormat(factory remoteExportFileLoaderFactory, filePath string) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := factory(filePath, "other/config", xconf.RemoteValuePlain)
+
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, map[string]any{"other/config": `{"other_foo": "bar"}`}, config)
+	}
+}
+
+func testRemoteExportLoaderReturnsErrForNotExistingFile(factory remoteExportFileLoaderFactory) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := factory("testdata/this-file-does-not-exist.json", "", xconf.RemoteValuePlain)
+
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertTrue(t, errors.Is(err, os.ErrNotExist))
+		assertNil(t, config)
+	}
+}
+
+func testConsulExportReaderLoaderReturnsErrForInvalidDump(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.ConsulExportReaderLoader(strings.NewReader(`{"key": "not an array"}`), "", xconf.RemoteValuePlain)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	var jsonErr *json.UnmarshalTypeError
+	assertTrue(t, errors.As(err, &jsonErr))
+	assertNil(t, config)
+}
+
+func testEtcdExportReaderLoaderReturnsErrForInvalidDump(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.EtcdExportReaderLoader(
+		strings.NewReader(`{"kvs": [{"key": "YQ==", "value": "e2ludmFsaWQ="}]}`), // a => {invalid
+		"",
+		xconf.RemoteValueJSON,
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNotNil(t, err)
+	assertNil(t, config)
+}
+
+func TestConsulExportReaderLoader_withReload(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	f, err := os.Open(consulExportFilePath)
+	requireNil(t, err)
+	defer f.Close()
+	subject := xconf.ConsulExportReaderLoader(f, "app/", xconf.RemoteValueJSON)
+
+	for i := 0; i < 2; i++ {
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, "10.0.0.1", config["app_host"])
+	}
+}
@@ -0,0 +1,545 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Note: S3 API is consumed directly (REST + AWS Signature Version 4),
+// in order not to depend on the (heavy) official SDK.
+// It is compatible with MinIO and other S3-compatible object storages.
+
+const (
+	s3DefaultRegion = "us-east-1"
+	// s3EmptyPayloadHash is the hex encoded SHA256 of an empty payload.
+	s3EmptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	s3SigningAlgorithm = "AWS4-HMAC-SHA256"
+	s3AmzDateFormat    = "20060102T150405Z"
+	s3ShortDateFormat  = "20060102"
+
+	awsAccessKeyIDEnvName     = "AWS_ACCESS_KEY_ID"
+	awsSecretAccessKeyEnvName = "AWS_SECRET_ACCESS_KEY"
+	awsSessionTokenEnvName    = "AWS_SESSION_TOKEN"
+	awsRegionEnvName          = "AWS_REGION"
+	awsDefaultRegionEnvName   = "AWS_DEFAULT_REGION"
+)
+
+// ErrS3ObjectNotFound is returned by [S3Loader] when the object (bucket) is not found.
+var ErrS3ObjectNotFound = errors.New("404 - S3 object not found")
+
+// S3Loader loads configuration from an S3 compatible object storage.
+// A single object can be loaded, or all objects having a prefix (configurations get merged,
+// in lexicographic order of objects' keys).
+// Object's ETag is used in subsequent loads (If-None-Match), thus an unchanged object
+// is not downloaded and parsed again.
+type S3Loader struct {
+	bucket      string       // the bucket
+	key         string       // the object key, or the prefix
+	prefix      bool         // flag indicating whether key is a prefix
+	valueFormat string       // value format, one of RemoteValue* constants
+	region      string       // bucket's region
+	endpoint    string       // custom endpoint (MinIO, etc.), path-style requests are made
+	creds       s3Creds      // credentials
+	httpClient  *http.Client // the http client used for calls
+	ctx         context.Context
+	cache       *s3Cache // ETag cache
+}
+
+// s3Creds holds S3 credentials.
+type s3Creds struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+// NewS3Loader instantiates a new S3Loader object that loads
+// configuration from an object in S3 bucket.
+// By default, credentials and region are taken from standard AWS environment variables
+// (AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN, AWS_REGION / AWS_DEFAULT_REGION).
+// If no credentials are found, anonymous requests are made.
+func NewS3Loader(bucket, key string, opts ...S3LoaderOption) S3Loader {
+	loader := S3Loader{
+		bucket:      bucket,
+		key:         key,
+		valueFormat: RemoteValuePlain,
+		region:      getDefaultAWSRegion(),
+		creds: s3Creds{
+			accessKeyID:     os.Getenv(awsAccessKeyIDEnvName),
+			secretAccessKey: os.Getenv(awsSecretAccessKeyEnvName),
+			sessionToken:    os.Getenv(awsSessionTokenEnvName),
+		},
+		httpClient: newDefaultHTTPClient(),
+		ctx:        context.Background(),
+		cache:      &s3Cache{objects: make(map[string]s3CachedObject)},
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&loader)
+	}
+
+	return loader
+}
+
+// Load returns a configuration key-value map from S3 object(s), or an error
+// if something bad happens along the process.
+func (loader S3Loader) Load() (map[string]any, error) {
+	return loader.LoadContext(loader.ctx)
+}
+
+// LoadContext is like Load, with the request(s) being canceled also when given context is done.
+// It implements [ContextLoader].
+func (loader S3Loader) LoadContext(ctx context.Context) (map[string]any, error) {
+	ctx, cancelCtx := mergeContexts(ctx, loader.ctx)
+	defer cancelCtx()
+	loader.ctx = ctx // Note: loader is a copy.
+
+	objectKeys := []string{loader.key}
+	if loader.prefix {
+		var err error
+		if objectKeys, err = loader.listObjects(); err != nil {
+			return nil, err
+		}
+	}
+
+	configMap := make(map[string]any)
+	seenKeys := make(map[string]struct{}, len(objectKeys))
+	for _, objectKey := range objectKeys {
+		objConfigMap, err := loader.getObject(objectKey)
+		if err != nil {
+			return nil, err
+		}
+		seenKeys[objectKey] = struct{}{}
+		// merge configs from different objects.
+		// Note: here, if a duplicate key exists, it will get overwritten.
+		for key, value := range objConfigMap {
+			configMap[key] = value
+		}
+	}
+	loader.cache.retain(seenKeys)
+
+	return configMap, nil
+}
+
+// getObject retrieves an object and returns its configuration map.
+// If object did not change since last retrieval, cached configuration is returned.
+func (loader S3Loader) getObject(objectKey string) (map[string]any, error) {
+	cached, isCached := loader.cache.load(objectKey)
+	headers := make(map[string]string, 1)
+	if isCached {
+		headers["If-None-Match"] = cached.etag
+	}
+
+	u, err := loader.objectURL(objectKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := loader.do(u, headers)
+	if err != nil {
+		return nil, err
+	}
+	defer closeResponseBody(resp)
+
+	if resp.StatusCode == http.StatusNotModified && isCached {
+		return DeepCopyConfigMap(cached.configMap), nil
+	}
+	if err := checkS3ResponseStatus(resp); err != nil {
+		return nil, err
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	configMap, err := getRemoteKVPairConfigMap(objectKey, content, loader.valueFormat)
+	if err != nil {
+		return nil, err
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		loader.cache.save(objectKey, etag, configMap)
+	}
+
+	return configMap, nil
+}
+
+// s3ListBucketResult is the ListObjectsV2 response.
+type s3ListBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// listObjects returns the (lexicographically sorted) keys of the objects having loader's key as prefix.
+func (loader S3Loader) listObjects() ([]string, error) {
+	var (
+		objectKeys        []string
+		continuationToken string
+	)
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", loader.key)
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+
+		result, err := loader.listObjectsPage(query)
+		if err != nil {
+			return nil, err
+		}
+		for _, content := range result.Contents {
+			if !strings.HasSuffix(content.Key, "/") { // skip "directories"
+				objectKeys = append(objectKeys, content.Key)
+			}
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		continuationToken = result.NextContinuationToken
+	}
+	sort.Strings(objectKeys)
+
+	return objectKeys, nil
+}
+
+// listObjectsPage retrieves a page of objects.
+func (loader S3Loader) listObjectsPage(query url.Values) (s3ListBucketResult, error) {
+	var result s3ListBucketResult
+	u, err := loader.objectURL("", query)
+	if err != nil {
+		return result, err
+	}
+	resp, err := loader.do(u, nil)
+	if err != nil {
+		return result, err
+	}
+	defer closeResponseBody(resp)
+	if err := checkS3ResponseStatus(resp); err != nil {
+		return result, err
+	}
+	err = xml.NewDecoder(resp.Body).Decode(&result)
+
+	return result, err
+}
+
+// objectURL returns the url for an object (or for the bucket, if objectKey is empty).
+func (loader S3Loader) objectURL(objectKey string, query url.Values) (*url.URL, error) {
+	var u *url.URL
+	if loader.endpoint != "" { // path-style
+		var err error
+		if u, err = url.Parse(strings.TrimSuffix(loader.endpoint, "/")); err != nil {
+			return nil, fmt.Errorf("invalid S3 endpoint %q: %w", loader.endpoint, err)
+		}
+		u.Path += "/" + loader.bucket + "/" + objectKey
+	} else { // virtual-hosted-style
+		u = &url.URL{
+			Scheme: "https",
+			Host:   loader.bucket + ".s3." + loader.region + ".amazonaws.com",
+			Path:   "/" + objectKey,
+		}
+	}
+	if query != nil {
+		u.RawQuery = s3CanonicalQuery(query)
+	}
+
+	return u, nil
+}
+
+// do signs and performs a GET request.
+func (loader S3Loader) do(u *url.URL, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(loader.ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Go-ActForGood-Xconf/1.0")
+	for hName, hValue := range headers {
+		req.Header.Set(hName, hValue)
+	}
+	if loader.creds.accessKeyID != "" {
+		signS3Request(req, loader.creds, loader.region, time.Now().UTC())
+	}
+
+	return loader.httpClient.Do(req)
+}
+
+// checkS3ResponseStatus returns an error if response's status code is not 200 OK.
+func checkS3ResponseStatus(resp *http.Response) error {
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrS3ObjectNotFound
+	case resp.StatusCode != http.StatusOK:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+		return fmt.Errorf("S3 responded with status code %d: %s", resp.StatusCode, body)
+	}
+
+	return nil
+}
+
+// signS3Request signs a request with AWS Signature Version 4.
+func signS3Request(req *http.Request, creds s3Creds, region string, now time.Time) {
+	amzDate := now.Format(s3AmzDateFormat)
+	shortDate := now.Format(s3ShortDateFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", s3EmptyPayloadHash)
+	if creds.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.sessionToken)
+	}
+
+	signedHeaders := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if creds.sessionToken != "" {
+		signedHeaders = append(signedHeaders, "x-amz-security-token")
+	}
+	var canonicalHeaders strings.Builder
+	for _, hName := range signedHeaders {
+		hValue := req.Header.Get(hName)
+		if hName == "host" {
+			hValue = req.URL.Host
+		}
+		canonicalHeaders.WriteString(hName + ":" + strings.TrimSpace(hValue) + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3CanonicalURI(req.URL.Path),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		s3EmptyPayloadHash,
+	}, "\n")
+	scope := shortDate + "/" + region + "/s3/aws4_request"
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := s3SigningAlgorithm + "\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalRequestHash[:])
+
+	signingKey := hmacSHA256([]byte("AWS4"+creds.secretAccessKey), shortDate)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set(
+		"Authorization",
+		s3SigningAlgorithm+" Credential="+creds.accessKeyID+"/"+scope+
+			", SignedHeaders="+strings.Join(signedHeaders, ";")+
+			", Signature="+signature,
+	)
+}
+
+// hmacSHA256 computes HMAC-SHA256 of data with given key.
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+
+	return h.Sum(nil)
+}
+
+// s3CanonicalURI URI-encodes each path segment.
+func s3CanonicalURI(path string) string {
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = s3URIEncode(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// s3CanonicalQuery returns the sorted, URI-encoded query string.
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			pairs = append(pairs, s3URIEncode(key)+"="+s3URIEncode(value))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// s3URIEncode encodes a string as required by AWS Signature Version 4
+// (every byte except unreserved characters 'A'-'Z', 'a'-'z', '0'-'9', '-', '.', '_', '~').
+func s3URIEncode(s string) string {
+	const hexChars = "0123456789ABCDEF"
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '.' || c == '_' || c == '~' {
+			sb.WriteByte(c)
+		} else {
+			sb.WriteByte('%')
+			sb.WriteByte(hexChars[c>>4])
+			sb.WriteByte(hexChars[c&15])
+		}
+	}
+
+	return sb.String()
+}
+
+// getDefaultAWSRegion tries to get the region from ENV.
+// It defaults on "us-east-1".
+func getDefaultAWSRegion() string {
+	if region := os.Getenv(awsRegionEnvName); region != "" {
+		return region
+	}
+	if region := os.Getenv(awsDefaultRegionEnvName); region != "" {
+		return region
+	}
+
+	return s3DefaultRegion
+}
+
+// s3CachedObject holds an object's ETag and its configuration map.
+type s3CachedObject struct {
+	etag      string
+	configMap map[string]any
+}
+
+// s3Cache holds caching info.
+type s3Cache struct {
+	objects map[string]s3CachedObject // object key => cached object.
+	mu      sync.RWMutex              // concurrency semaphore
+}
+
+// save stores an object's configuration map and ETag.
+func (cache *s3Cache) save(objectKey, etag string, configMap map[string]any) {
+	cache.mu.Lock()
+	cache.objects[objectKey] = s3CachedObject{
+		etag:      etag,
+		configMap: DeepCopyConfigMap(configMap),
+	}
+	cache.mu.Unlock()
+}
+
+// load retrieves an object's cached info.
+func (cache *s3Cache) load(objectKey string) (s3CachedObject, bool) {
+	cache.mu.RLock()
+	obj, found := cache.objects[objectKey]
+	cache.mu.RUnlock()
+
+	return obj, found
+}
+
+// retain removes from cache objects that are not present anymore.
+func (cache *s3Cache) retain(objectKeys map[string]struct{}) {
+	cache.mu.Lock()
+	for objectKey := range cache.objects {
+		if _, found := objectKeys[objectKey]; !found {
+			delete(cache.objects, objectKey)
+		}
+	}
+	cache.mu.Unlock()
+}
+
+// S3LoaderOption defines optional function for configuring
+// a S3 Loader.
+type S3LoaderOption func(*S3Loader)
+
+// S3LoaderWithPrefix treats the key as a prefix, and thus all the objects
+// having that prefix will be loaded and their configurations merged
+// (in lexicographic order of their keys).
+func S3LoaderWithPrefix() S3LoaderOption {
+	return func(loader *S3Loader) {
+		loader.prefix = true
+	}
+}
+
+// S3LoaderWithValueFormat sets the value format for object(s) content.
+//
+// If is set to [RemoteValueJSON], the content will be treated as JSON
+// and configuration will be loaded from it.
+//
+// If is set to [RemoteValueYAML], the content will be treated as YAML
+// and configuration will be loaded from it.
+//
+// If is set to [RemoteValuePlain], the content will be treated as plain text
+// and configuration will contain the object key and its plain content.
+//
+// If is set to [RemoteValueTOML], [RemoteValueIni], [RemoteValueProperties], [RemoteValueDotEnv],
+// the content will be treated accordingly, like the files of the same format,
+// and configuration will be loaded from it.
+//
+// By default, is set to [RemoteValuePlain].
+func S3LoaderWithValueFormat(valueFormat string) S3LoaderOption {
+	return func(loader *S3Loader) {
+		if isValidRemoteValueFormat(valueFormat) {
+			loader.valueFormat = valueFormat
+		}
+	}
+}
+
+// S3LoaderWithCredentials sets the credentials used to sign requests.
+// Session token is optional (can be empty) and should be set for temporary credentials.
+func S3LoaderWithCredentials(accessKeyID, secretAccessKey, sessionToken string) S3LoaderOption {
+	return func(loader *S3Loader) {
+		loader.creds = s3Creds{
+			accessKeyID:     accessKeyID,
+			secretAccessKey: secretAccessKey,
+			sessionToken:    sessionToken,
+		}
+	}
+}
+
+// S3LoaderWithRegion sets bucket's region.
+// By default, is taken from AWS_REGION / AWS_DEFAULT_REGION env, or is "us-east-1".
+func S3LoaderWithRegion(region string) S3LoaderOption {
+	return func(loader *S3Loader) {
+		loader.region = region
+	}
+}
+
+// S3LoaderWithEndpoint sets a custom endpoint, for S3 compatible storages like MinIO.
+// Path-style requests are made against it.
+//
+// Example:
+//
+//	xconf.S3LoaderWithEndpoint("http://minio.example.com:9000")
+func S3LoaderWithEndpoint(endpoint string) S3LoaderOption {
+	return func(loader *S3Loader) {
+		loader.endpoint = endpoint
+	}
+}
+
+// S3LoaderWithHTTPClient sets the http client used for calls.
+// A default one is provided if you don't use this option.
+func S3LoaderWithHTTPClient(client *http.Client) S3LoaderOption {
+	return func(loader *S3Loader) {
+		loader.httpClient = client
+	}
+}
+
+// S3LoaderWithContext sets requests' context.
+// By default, a context.Background() is used.
+func S3LoaderWithContext(ctx context.Context) S3LoaderOption {
+	return func(loader *S3Loader) {
+		loader.ctx = ctx
+	}
+}
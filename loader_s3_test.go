@@ -0,0 +1,298 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestS3Loader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - single object with ETag caching", testS3LoaderSingleObjectWithETagCaching)
+	t.Run("success - prefix objects are merged", testS3LoaderWithPrefix)
+	t.Run("success - anonymous request", testS3LoaderAnonymous)
+	t.Run("error - object not found", testS3LoaderReturnsErrNotFound)
+	t.Run("error - access denied", testS3LoaderReturnsErrAccessDenied)
+	t.Run("error - invalid endpoint", testS3LoaderReturnsErrInvalidEndpoint)
+	t.Run("success - safe-mutable config map", testS3LoaderReturnsSafeMutableConfigMap)
+}
+
+var s3AuthorizationRegex = regexp.MustCompile(
+	`^AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/\d{8}/eu-west-1/s3/aws4_request, ` +
+		`SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token, Signature=[0-9a-f]{64}$`,
+)
+
+// startS3MockServer starts a S3 mock server serving given objects.
+func startS3MockServer(t *testing.T, objects map[string]string, getCallsCnt *uint32) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			assertTrue(t, s3AuthorizationRegex.MatchString(auth))
+			assertEqual(t, "session-token", r.Header.Get("X-Amz-Security-Token"))
+			assertTrue(t, r.Header.Get("X-Amz-Date") != "")
+		}
+
+		if r.URL.Path == "/my-bucket/" && r.URL.Query().Get("list-type") == "2" {
+			prefix := r.URL.Query().Get("prefix")
+			var sb strings.Builder
+			sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?><ListBucketResult>`)
+			for key := range objects {
+				if strings.HasPrefix(key, prefix) {
+					sb.WriteString("<Contents><Key>" + key + "</Key></Contents>")
+				}
+			}
+			sb.WriteString(`<IsTruncated>false</IsTruncated></ListBucketResult>`)
+			_, _ = w.Write([]byte(sb.String()))
+
+			return
+		}
+
+		key := strings.TrimPrefix(r.URL.Path, "/my-bucket/")
+		content, found := objects[key]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+		etag := fmt.Sprintf(`"%x"`, len(content))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+		if getCallsCnt != nil {
+			atomic.AddUint32(getCallsCnt, 1)
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(content))
+	}))
+}
+
+func testS3LoaderSingleObjectWithETagCaching(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var getCallsCnt uint32
+	svr := startS3MockServer(t, map[string]string{
+		"app/config.json": `{"s3_foo":"bar","s3_year":2022}`,
+	}, &getCallsCnt)
+	defer svr.Close()
+	subject := xconf.NewS3Loader(
+		"my-bucket",
+		"app/config.json",
+		xconf.S3LoaderWithEndpoint(svr.URL),
+		xconf.S3LoaderWithRegion("eu-west-1"),
+		xconf.S3LoaderWithCredentials("AKIDEXAMPLE", "secret", "session-token"),
+		xconf.S3LoaderWithValueFormat(xconf.RemoteValueJSON),
+	)
+	expectedConfig := map[string]any{
+		"s3_foo":  "bar",
+		"s3_year": float64(2022),
+	}
+
+	for i := 0; i < 3; i++ {
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, expectedConfig, config)
+	}
+	assertEqual(t, uint32(1), atomic.LoadUint32(&getCallsCnt))
+}
+
+func testS3LoaderWithPrefix(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	svr := startS3MockServer(t, map[string]string{
+		"app/10-base.yaml":     "s3_foo: bar\ns3_year: 2022\n",
+		"app/20-override.yaml": "s3_year: 2023\n",
+		"other/config.yaml":    "s3_other: should not be loaded\n",
+	}, nil)
+	defer svr.Close()
+	subject := xconf.NewS3Loader(
+		"my-bucket",
+		"app/",
+		xconf.S3LoaderWithEndpoint(svr.URL),
+		xconf.S3LoaderWithRegion("eu-west-1"),
+		xconf.S3LoaderWithCredentials("AKIDEXAMPLE", "secret", "session-token"),
+		xconf.S3LoaderWithValueFormat(xconf.RemoteValueYAML),
+		xconf.S3LoaderWithPrefix(),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"s3_foo":  "bar",
+			"s3_year": 2023,
+		},
+		config,
+	)
+}
+
+func testS3LoaderAnonymous(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	svr := startS3MockServer(t, map[string]string{"greeting": "hello\n"}, nil)
+	defer svr.Close()
+	subject := xconf.NewS3Loader(
+		"my-bucket",
+		"greeting",
+		xconf.S3LoaderWithEndpoint(svr.URL),
+		xconf.S3LoaderWithCredentials("", "", ""),
+		xconf.S3LoaderWithHTTPClient(http.DefaultClient),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"greeting": "hello"}, config)
+}
+
+func testS3LoaderReturnsErrNotFound(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	svr := startS3MockServer(t, map[string]string{}, nil)
+	defer svr.Close()
+	subject := xconf.NewS3Loader(
+		"my-bucket",
+		"this/object/does/not/exist",
+		xconf.S3LoaderWithEndpoint(svr.URL),
+		xconf.S3LoaderWithCredentials("", "", ""),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, errors.Is(err, xconf.ErrS3ObjectNotFound))
+}
+
+func testS3LoaderReturnsErrInvalidEndpoint(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.NewS3Loader(
+		"my-bucket",
+		"config.json",
+		xconf.S3LoaderWithEndpoint("http://[::1"),
+		xconf.S3LoaderWithCredentials("", "", ""),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	if assertNotNil(t, err) {
+		assertTrue(t, strings.Contains(err.Error(), "invalid S3 endpoint"))
+	}
+}
+
+func testS3LoaderReturnsErrAccessDenied(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("<Error><Code>AccessDenied</Code></Error>"))
+	}))
+	defer svr.Close()
+	subject := xconf.NewS3Loader(
+		"my-bucket",
+		"app/",
+		xconf.S3LoaderWithEndpoint(svr.URL),
+		xconf.S3LoaderWithPrefix(),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	if assertNotNil(t, err) {
+		assertTrue(t, strings.Contains(err.Error(), "403"))
+		assertTrue(t, strings.Contains(err.Error(), "AccessDenied"))
+	}
+}
+
+func testS3LoaderReturnsSafeMutableConfigMap(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	svr := startS3MockServer(t, map[string]string{
+		"config.json": `{"s3_list":["bread","milk"]}`,
+	}, nil)
+	defer svr.Close()
+	subject := xconf.NewS3Loader(
+		"my-bucket",
+		"config.json",
+		xconf.S3LoaderWithEndpoint(svr.URL),
+		xconf.S3LoaderWithCredentials("", "", ""),
+		xconf.S3LoaderWithValueFormat(xconf.RemoteValueJSON),
+	)
+	expectedConfig := map[string]any{"s3_list": []any{"bread", "milk"}}
+
+	// act
+	config1, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, expectedConfig, config1)
+
+	// modify first returned value, expect second returned (cached) value to be initial one.
+	config1["s3_list"].([]any)[0] = "eggs"
+	config1["s3_new"] = "new"
+
+	// act
+	config2, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, expectedConfig, config2)
+}
+
+func ExampleS3Loader() {
+	// load and merge all yaml objects under "my-app/" prefix from a MinIO bucket.
+	loader := xconf.NewS3Loader(
+		"configs",
+		"my-app/",
+		xconf.S3LoaderWithEndpoint("http://127.0.0.1:9000"),
+		xconf.S3LoaderWithCredentials("minioadmin", "minioadmin", ""),
+		xconf.S3LoaderWithValueFormat(xconf.RemoteValueYAML),
+		xconf.S3LoaderWithPrefix(),
+	)
+
+	configMap, err := loader.Load()
+	if err != nil {
+		panic(err)
+	}
+	for key, value := range configMap {
+		fmt.Println(key+":", value)
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/actforgood/xerr"
+)
+
+// ErrScriptTimeout is an error returned by [ScriptLoader] if script's evaluation
+// does not finish within configured timeout.
+var ErrScriptTimeout = errors.New("script evaluation timed out")
+
+// ErrScriptResultTooLarge is an error returned by [ScriptLoader] if script's
+// computed configuration map has more keys than allowed.
+var ErrScriptResultTooLarge = errors.New("script result too large")
+
+// ErrScriptReservedGlobal is an error returned by [ScriptLoader] if an input
+// is exposed under a reserved global name, like [ScriptEnvGlobal].
+var ErrScriptReservedGlobal = errors.New("script global name is reserved")
+
+// ScriptEvaluator evaluates a script and returns the configuration map it computes.
+// Globals are predeclared values the script has access to.
+// Evaluation should stop once the context is done.
+//
+// A sandboxed Starlark (go.starlark.net) evaluator, limited also in execution steps,
+// is provided by [github.com/actforgood/xconf/starlarkconf] package.
+// Other engines can be adapted, enforcing also engine specific limits.
+type ScriptEvaluator interface {
+	Eval(ctx context.Context, script string, globals map[string]any) (map[string]any, error)
+}
+
+// The ScriptEvaluatorFunc type is an adapter to allow the use of
+// ordinary functions as ScriptEvaluators.
+type ScriptEvaluatorFunc func(ctx context.Context, script string, globals map[string]any) (map[string]any, error)
+
+// Eval calls fn(ctx, script, globals).
+func (fn ScriptEvaluatorFunc) Eval(
+	ctx context.Context,
+	script string,
+	globals map[string]any,
+) (map[string]any, error) {
+	return fn(ctx, script, globals)
+}
+
+const (
+	// ScriptEnvGlobal is the global under which [ScriptLoader] exposes
+	// the allowed environment variables, as a map[string]any.
+	ScriptEnvGlobal = "env"
+
+	// scriptDefaultTimeout is the default script's evaluation timeout.
+	scriptDefaultTimeout = time.Second
+)
+
+// ScriptLoader loads configuration computed by a script, for advanced cases,
+// like region-dependent computed endpoints.
+// The script has access (as globals) only to explicitly allowed environment variables
+// and explicitly provided loaders' outputs.
+// Its evaluation is limited in time, and its result in size (number of keys);
+// memory used during evaluation is not limited by the loader, see the evaluator's limits.
+type ScriptLoader struct {
+	evaluator ScriptEvaluator   // the scripting engine.
+	script    string            // the script's source.
+	timeout   time.Duration     // the maximum duration of script's evaluation.
+	maxKeys   int               // the maximum number of keys the script can compute, 0 for no limit.
+	envNames  []string          // the environment variables exposed to the script.
+	inputs    map[string]Loader // the loaders whose outputs are exposed to the script, by global name.
+	initErr   error             // error occurred while configuring the loader, returned by Load.
+}
+
+// NewScriptLoader instantiates a new ScriptLoader object that loads
+// configuration computed by given script, evaluated with given evaluator.
+// By default, script's evaluation is limited to 1 second, and script
+// has no access to environment variables, or other loaders.
+func NewScriptLoader(evaluator ScriptEvaluator, script string, opts ...ScriptLoaderOption) ScriptLoader {
+	loader := ScriptLoader{
+		evaluator: evaluator,
+		script:    script,
+		timeout:   scriptDefaultTimeout,
+		inputs:    make(map[string]Loader),
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&loader)
+	}
+
+	return loader
+}
+
+// Load returns the configuration key-value map computed by the script,
+// or an error if something bad happens along the process.
+func (loader ScriptLoader) Load() (map[string]any, error) {
+	return loader.LoadContext(context.Background())
+}
+
+// LoadContext is like Load, passing given context to the input loaders and to the evaluator.
+// It implements [ContextLoader].
+func (loader ScriptLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	if loader.initErr != nil {
+		return nil, loader.initErr
+	}
+	globals := make(map[string]any, len(loader.inputs)+1)
+	env := make(map[string]any, len(loader.envNames))
+	for _, envName := range loader.envNames {
+		if value, found := os.LookupEnv(envName); found {
+			env[envName] = value
+		}
+	}
+	globals[ScriptEnvGlobal] = env
+	for name, input := range loader.inputs {
+		configMap, err := LoadWithContext(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		globals[name] = configMap
+	}
+
+	configMap, err := loader.eval(ctx, globals)
+	if err != nil {
+		return nil, err
+	}
+	if loader.maxKeys > 0 && len(configMap) > loader.maxKeys {
+		return nil, xerr.Wrapf(ErrScriptResultTooLarge, "%d keys, max allowed %d", len(configMap), loader.maxKeys)
+	}
+
+	return configMap, nil
+}
+
+// eval evaluates the script within the configured timeout.
+// Note: if the evaluator does not respect context's cancellation,
+// its goroutine is abandoned (but loader does not wait after it).
+func (loader ScriptLoader) eval(parentCtx context.Context, globals map[string]any) (map[string]any, error) {
+	ctx, cancelCtx := context.WithTimeout(parentCtx, loader.timeout)
+	defer cancelCtx()
+
+	type evalResult struct {
+		configMap map[string]any
+		err       error
+	}
+	resultChan := make(chan evalResult, 1)
+	go func() {
+		configMap, err := loader.evaluator.Eval(ctx, loader.script, globals)
+		resultChan <- evalResult{configMap: configMap, err: err}
+	}()
+
+	select {
+	case result := <-resultChan:
+		if result.err != nil && ctx.Err() != nil {
+			return nil, scriptCtxErr(parentCtx)
+		}
+
+		return result.configMap, result.err
+	case <-ctx.Done():
+		return nil, scriptCtxErr(parentCtx)
+	}
+}
+
+// scriptCtxErr returns the error for an evaluation whose context is done:
+// parent context's error, if it is done, or [ErrScriptTimeout] otherwise.
+func scriptCtxErr(parentCtx context.Context) error {
+	if err := parentCtx.Err(); err != nil {
+		return err
+	}
+
+	return ErrScriptTimeout
+}
+
+// Unwrap returns the input loaders, ordered by their global name.
+func (loader ScriptLoader) Unwrap() []Loader {
+	names := make([]string, 0, len(loader.inputs))
+	for name := range loader.inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	inputs := make([]Loader, len(names))
+	for idx, name := range names {
+		inputs[idx] = loader.inputs[name]
+	}
+
+	return inputs
+}
+
+// mapLoaders returns a copy of the loader, with the input loaders replaced by the ones returned by fn.
+func (loader ScriptLoader) mapLoaders(fn func(Loader) Loader) Loader {
+	inputs := make(map[string]Loader, len(loader.inputs))
+	for name, input := range loader.inputs {
+		inputs[name] = fn(input)
+	}
+	loader.inputs = inputs
+
+	return loader
+}
+
+// Close closes the input loaders.
+func (loader ScriptLoader) Close() error {
+	return CloseLoaders(loader)
+}
+
+// ScriptLoaderOption defines optional function for configuring
+// a Script Loader.
+type ScriptLoaderOption func(*ScriptLoader)
+
+// ScriptLoaderWithTimeout sets the maximum duration of script's evaluation.
+// If exceeded, [ErrScriptTimeout] is returned.
+// By default, is set to 1 second.
+func ScriptLoaderWithTimeout(timeout time.Duration) ScriptLoaderOption {
+	return func(loader *ScriptLoader) {
+		if timeout > 0 {
+			loader.timeout = timeout
+		}
+	}
+}
+
+// ScriptLoaderWithMaxKeys sets the maximum number of keys the script can compute.
+// If exceeded, [ErrScriptResultTooLarge] is returned.
+// Note: it limits the result's size, not the memory used during script's evaluation.
+// By default, there is no limit.
+func ScriptLoaderWithMaxKeys(maxKeys int) ScriptLoaderOption {
+	return func(loader *ScriptLoader) {
+		loader.maxKeys = maxKeys
+	}
+}
+
+// ScriptLoaderWithEnv exposes given environment variables to the script,
+// under [ScriptEnvGlobal] global. Other environment variables are not accessible.
+func ScriptLoaderWithEnv(envNames ...string) ScriptLoaderOption {
+	return func(loader *ScriptLoader) {
+		loader.envNames = append(loader.envNames, envNames...)
+	}
+}
+
+// ScriptLoaderWithInput exposes given loader's output to the script,
+// under given global name. The name cannot be [ScriptEnvGlobal],
+// otherwise [ErrScriptReservedGlobal] is returned by Load.
+func ScriptLoaderWithInput(name string, input Loader) ScriptLoaderOption {
+	return func(loader *ScriptLoader) {
+		if name == ScriptEnvGlobal {
+			loader.initErr = xerr.Wrapf(ErrScriptReservedGlobal, "%q", name)
+
+			return
+		}
+		loader.inputs[name] = input
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+// regionEndpointEvaluator is a fake script evaluator which computes
+// an endpoint based on region env and a domain from an input loader.
+var regionEndpointEvaluator = xconf.ScriptEvaluatorFunc(func(
+	_ context.Context,
+	script string,
+	globals map[string]any,
+) (map[string]any, error) {
+	env := globals[xconf.ScriptEnvGlobal].(map[string]any)
+	base, _ := globals["base"].(map[string]any)
+
+	return map[string]any{
+		"endpoint": fmt.Sprintf(script, env["XCONF_SCRIPT_REGION"], base["domain"]),
+		"env_home": env["HOME"],
+	}, nil
+})
+
+func TestScriptLoader(t *testing.T) {
+	t.Setenv("XCONF_SCRIPT_REGION", "eu-west-1")
+
+	t.Run("success - env and inputs are exposed", testScriptLoaderExposesEnvAndInputs)
+	t.Run("error - timeout", testScriptLoaderReturnsTimeoutErr)
+	t.Run("error - too many keys", testScriptLoaderReturnsResultTooLargeErr)
+	t.Run("error - evaluator", testScriptLoaderReturnsErrFromEvaluator)
+	t.Run("error - input loader", testScriptLoaderReturnsErrFromInputLoader)
+	t.Run("error - reserved input name", testScriptLoaderReturnsErrReservedGlobal)
+}
+
+func testScriptLoaderExposesEnvAndInputs(t *testing.T) {
+	// arrange
+	subject := xconf.NewScriptLoader(
+		regionEndpointEvaluator,
+		"https://api.%s.%s",
+		xconf.ScriptLoaderWithEnv("XCONF_SCRIPT_REGION"),
+		xconf.ScriptLoaderWithInput("base", xconf.PlainLoader(map[string]any{"domain": "example.com"})),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"endpoint": "https://api.eu-west-1.example.com",
+			"env_home": nil, // not allowed env is not exposed.
+		},
+		config,
+	)
+}
+
+func testScriptLoaderReturnsTimeoutErr(t *testing.T) {
+	// arrange
+	var (
+		evaluator = xconf.ScriptEvaluatorFunc(func(
+			ctx context.Context,
+			_ string,
+			_ map[string]any,
+		) (map[string]any, error) {
+			<-ctx.Done() // simulate an infinite loop script, which respects context.
+
+			return nil, ctx.Err()
+		})
+		subject = xconf.NewScriptLoader(
+			evaluator,
+			"while True: pass",
+			xconf.ScriptLoaderWithTimeout(50*time.Millisecond),
+		)
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrScriptTimeout))
+	assertNil(t, config)
+}
+
+func testScriptLoaderReturnsResultTooLargeErr(t *testing.T) {
+	// arrange
+	subject := xconf.NewScriptLoader(
+		regionEndpointEvaluator,
+		"https://api.%s.%s",
+		xconf.ScriptLoaderWithMaxKeys(1),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrScriptResultTooLarge))
+	assertNil(t, config)
+}
+
+func testScriptLoaderReturnsErrFromEvaluator(t *testing.T) {
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered script error")
+		evaluator   = xconf.ScriptEvaluatorFunc(func(
+			context.Context,
+			string,
+			map[string]any,
+		) (map[string]any, error) {
+			return nil, expectedErr
+		})
+		subject = xconf.NewScriptLoader(evaluator, "fail()")
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertNil(t, config)
+}
+
+func testScriptLoaderReturnsErrFromInputLoader(t *testing.T) {
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered input loader error")
+		input       = xconf.LoaderFunc(func() (map[string]any, error) {
+			return nil, expectedErr
+		})
+		subject = xconf.NewScriptLoader(
+			regionEndpointEvaluator,
+			"https://api.%s.%s",
+			xconf.ScriptLoaderWithInput("base", input),
+		)
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertNil(t, config)
+}
+
+func testScriptLoaderReturnsErrReservedGlobal(t *testing.T) {
+	// arrange
+	subject := xconf.NewScriptLoader(
+		regionEndpointEvaluator,
+		"https://api.%s.%s",
+		xconf.ScriptLoaderWithEnv("XCONF_SCRIPT_REGION"),
+		xconf.ScriptLoaderWithInput(xconf.ScriptEnvGlobal, xconf.PlainLoader(map[string]any{"XCONF_SCRIPT_REGION": "us"})),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrScriptReservedGlobal))
+	assertNil(t, config)
+}
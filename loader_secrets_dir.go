@@ -0,0 +1,131 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SecretsDirLoader loads configuration from a directory where each file
+// represents a key (file's name) and its value (file's trimmed content).
+// This is the layout used by Docker Swarm secrets (/run/secrets),
+// Kubernetes projected secrets/config maps volumes, systemd credentials, etc.
+//
+// Hidden files/directories (starting with ".") are skipped - this also
+// covers Kubernetes "..data" / "..<timestamp>" internal entries.
+type SecretsDirLoader struct {
+	// dirPath is the directory to load secrets from.
+	dirPath string
+	// recursive is a flag indicating whether subdirectories should be traversed.
+	recursive bool
+	// keyPrefix is a prefix added to all keys.
+	keyPrefix string
+}
+
+// NewSecretsDirLoader instantiates a new SecretsDirLoader object that loads
+// configuration from given directory.
+func NewSecretsDirLoader(dirPath string, opts ...SecretsDirLoaderOption) SecretsDirLoader {
+	loader := SecretsDirLoader{
+		dirPath: dirPath,
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&loader)
+	}
+
+	return loader
+}
+
+// Load returns a configuration key-value map from the secrets directory,
+// or an error if something bad happens along the process.
+func (loader SecretsDirLoader) Load() (map[string]any, error) {
+	configMap := make(map[string]any)
+	if err := loader.loadDir(loader.dirPath, "", configMap); err != nil {
+		return nil, err
+	}
+
+	return configMap, nil
+}
+
+// LoadContext calls Load, if ctx is not done. It implements [ContextLoader].
+func (loader SecretsDirLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return loader.Load()
+}
+
+// loadDir loads recursively files from a directory into configMap.
+func (loader SecretsDirLoader) loadDir(dirPath, keyPrefix string, configMap map[string]any) error {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		path := filepath.Join(dirPath, name)
+		fInfo, err := os.Stat(path) // follow symlinks.
+		if err != nil {
+			return err
+		}
+
+		if fInfo.IsDir() {
+			if loader.recursive {
+				if err := loader.loadDir(path, keyPrefix+name+".", configMap); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		configMap[loader.keyPrefix+keyPrefix+name] = string(bytes.TrimSpace(content))
+	}
+
+	return nil
+}
+
+// SecretsDirLoaderOption defines optional function for configuring
+// a Secrets Dir Loader.
+type SecretsDirLoaderOption func(*SecretsDirLoader)
+
+// SecretsDirLoaderWithRecursion enables subdirectories traversal.
+// Keys of files found in subdirectories are composed of subdirectories
+// names and file's name, separated by "."(dot).
+// Example: "db/password" file results in "db.password" key.
+//
+// By default, subdirectories are ignored.
+func SecretsDirLoaderWithRecursion() SecretsDirLoaderOption {
+	return func(loader *SecretsDirLoader) {
+		loader.recursive = true
+	}
+}
+
+// SecretsDirLoaderWithKeyPrefix sets a prefix to be added to all keys.
+//
+// Example:
+//
+//	// "/run/secrets/db_password" file results in "SECRET_db_password" key.
+//	xconf.NewSecretsDirLoader("/run/secrets", xconf.SecretsDirLoaderWithKeyPrefix("SECRET_"))
+func SecretsDirLoaderWithKeyPrefix(prefix string) SecretsDirLoaderOption {
+	return func(loader *SecretsDirLoader) {
+		loader.keyPrefix = prefix
+	}
+}
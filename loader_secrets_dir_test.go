@@ -0,0 +1,127 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestSecretsDirLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - flat directory", testSecretsDirLoaderFlat)
+	t.Run("success - recursive with prefix", testSecretsDirLoaderRecursiveWithPrefix)
+	t.Run("error - directory does not exist", testSecretsDirLoaderReturnsErrDirNotExist)
+}
+
+// setUpSecretsDir creates a secrets directory structure.
+func setUpSecretsDir(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"db_password":        "s3cr3t\n",
+		"api_key":            "  abc-xyz  ",
+		".hidden":            "should be skipped",
+		"redis/password":     "redis-pwd\n",
+		"redis/tls/ca":       "ca-content",
+		"..data/db_password": "k8s internal entry, should be skipped",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal("prerequisite failed:", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal("prerequisite failed:", err)
+		}
+	}
+
+	return dir
+}
+
+func testSecretsDirLoaderFlat(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.NewSecretsDirLoader(setUpSecretsDir(t))
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"db_password": "s3cr3t",
+			"api_key":     "abc-xyz",
+		},
+		config,
+	)
+}
+
+func testSecretsDirLoaderRecursiveWithPrefix(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.NewSecretsDirLoader(
+		setUpSecretsDir(t),
+		xconf.SecretsDirLoaderWithRecursion(),
+		xconf.SecretsDirLoaderWithKeyPrefix("secret."),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"secret.db_password":    "s3cr3t",
+			"secret.api_key":        "abc-xyz",
+			"secret.redis.password": "redis-pwd",
+			"secret.redis.tls.ca":   "ca-content",
+		},
+		config,
+	)
+}
+
+func testSecretsDirLoaderReturnsErrDirNotExist(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.NewSecretsDirLoader("/this/secrets/dir/does/not/exist")
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, errors.Is(err, os.ErrNotExist))
+}
+
+func ExampleSecretsDirLoader() {
+	loader := xconf.NewSecretsDirLoader(
+		"/run/secrets",
+		xconf.SecretsDirLoaderWithKeyPrefix("SECRET_"),
+	)
+
+	configMap, err := loader.Load()
+	if err != nil {
+		panic(err)
+	}
+	for key := range configMap {
+		fmt.Println(key)
+	}
+}
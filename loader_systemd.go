@@ -0,0 +1,218 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// systemdCredentialsDirEnvName defines the environment variable name set by systemd
+// for a service with LoadCredential=/SetCredential= directives.
+const systemdCredentialsDirEnvName = "CREDENTIALS_DIRECTORY"
+
+// ErrSystemdCredentialsDirNotSet is returned by [SystemdCredentialsLoader]
+// if CREDENTIALS_DIRECTORY environment variable is not set.
+var ErrSystemdCredentialsDirNotSet = errors.New("systemd credentials directory is not set")
+
+// SystemdCredentialsLoader loads configuration from systemd's credentials directory,
+// given by CREDENTIALS_DIRECTORY environment variable.
+// Each credential's name is the key, and its (trimmed) content is the value.
+// Keys can be prefixed with an optional prefix.
+//
+// If CREDENTIALS_DIRECTORY is not set (service was not started with credentials),
+// [ErrSystemdCredentialsDirNotSet] is returned. You can ignore it with [IgnoreErrorLoader].
+func SystemdCredentialsLoader(keyPrefix ...string) Loader {
+	return LoaderFunc(func() (map[string]any, error) {
+		credsDir := os.Getenv(systemdCredentialsDirEnvName)
+		if credsDir == "" {
+			return nil, ErrSystemdCredentialsDirNotSet
+		}
+		var opts []SecretsDirLoaderOption
+		if len(keyPrefix) > 0 {
+			opts = append(opts, SecretsDirLoaderWithKeyPrefix(keyPrefix[0]))
+		}
+
+		return NewSecretsDirLoader(credsDir, opts...).Load()
+	})
+}
+
+// SystemdEnvFileLoader loads configuration from a systemd EnvironmentFile=.
+// The location of the file is given as parameter.
+func SystemdEnvFileLoader(filePath string) Loader {
+	return LoaderFunc(func() (map[string]any, error) {
+		f, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		return SystemdEnvReaderLoader(f).Load()
+	})
+}
+
+// SystemdEnvReaderLoader loads systemd EnvironmentFile= configuration from an [io.Reader].
+//
+// The parsing follows systemd's rules:
+//   - empty lines and lines starting with "#" or ";" are ignored.
+//   - whitespace around key and "=" is stripped.
+//   - values can be enclosed in single quotes (taken literally) or
+//     double quotes (where \", \\, \$ and \` escapes are recognized).
+//   - unquoted values have trailing whitespace stripped and a backslash escapes the next character.
+//   - a backslash at the end of a line continues the value on the next line.
+//
+// No variable expansion is performed.
+func SystemdEnvReaderLoader(reader io.Reader) Loader {
+	return LoaderFunc(func() (map[string]any, error) {
+		if seekReader, ok := reader.(io.Seeker); ok {
+			_, _ = seekReader.Seek(0, io.SeekStart) // move to the beginning in case of a re-load needed.
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		return parseSystemdEnv(string(content)), nil
+	})
+}
+
+// systemd EnvironmentFile parser states.
+const (
+	sdEnvStatePreKey = iota
+	sdEnvStateKey
+	sdEnvStatePreValue
+	sdEnvStateValue
+	sdEnvStateValueEscape
+	sdEnvStateSingleQuoteValue
+	sdEnvStateDoubleQuoteValue
+	sdEnvStateDoubleQuoteValueEscape
+	sdEnvStateComment
+	sdEnvStateCommentEscape
+)
+
+// parseSystemdEnv parses EnvironmentFile content, mimicking systemd's state machine.
+func parseSystemdEnv(content string) map[string]any {
+	var (
+		configMap = make(map[string]any)
+		state     = sdEnvStatePreKey
+		key       strings.Builder
+		value     strings.Builder
+		// valueEnd is the length of value without trailing unescaped whitespace.
+		valueEnd int
+	)
+	emit := func() {
+		if k := strings.TrimSpace(key.String()); k != "" {
+			configMap[k] = value.String()[:valueEnd]
+		}
+		key.Reset()
+		value.Reset()
+		valueEnd = 0
+	}
+	appendValue := func(c byte, isWhitespace bool) {
+		value.WriteByte(c)
+		if !isWhitespace {
+			valueEnd = value.Len()
+		}
+	}
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		isNewLine := c == '\n'
+		isWhitespace := c == ' ' || c == '\t'
+
+		switch state {
+		case sdEnvStatePreKey:
+			switch {
+			case c == '#' || c == ';':
+				state = sdEnvStateComment
+			case !isNewLine && !isWhitespace:
+				state = sdEnvStateKey
+				key.WriteByte(c)
+			}
+		case sdEnvStateKey:
+			switch {
+			case isNewLine: // no assignment, discard.
+				key.Reset()
+				state = sdEnvStatePreKey
+			case c == '=':
+				state = sdEnvStatePreValue
+			default:
+				key.WriteByte(c)
+			}
+		case sdEnvStatePreValue:
+			switch {
+			case isNewLine:
+				emit()
+				state = sdEnvStatePreKey
+			case c == '\'':
+				state = sdEnvStateSingleQuoteValue
+			case c == '"':
+				state = sdEnvStateDoubleQuoteValue
+			case c == '\\':
+				state = sdEnvStateValueEscape
+			case !isWhitespace:
+				state = sdEnvStateValue
+				appendValue(c, false)
+			}
+		case sdEnvStateValue:
+			switch {
+			case isNewLine:
+				emit()
+				state = sdEnvStatePreKey
+			case c == '\\':
+				state = sdEnvStateValueEscape
+			default:
+				appendValue(c, isWhitespace)
+			}
+		case sdEnvStateValueEscape:
+			state = sdEnvStateValue
+			if !isNewLine { // escaped new line means line continuation.
+				appendValue(c, false)
+			}
+		case sdEnvStateSingleQuoteValue:
+			if c == '\'' {
+				state = sdEnvStatePreValue
+			} else {
+				appendValue(c, false)
+			}
+		case sdEnvStateDoubleQuoteValue:
+			switch c {
+			case '"':
+				state = sdEnvStatePreValue
+			case '\\':
+				state = sdEnvStateDoubleQuoteValueEscape
+			default:
+				appendValue(c, false)
+			}
+		case sdEnvStateDoubleQuoteValueEscape:
+			state = sdEnvStateDoubleQuoteValue
+			switch {
+			case c == '"' || c == '\\' || c == '$' || c == '`':
+				appendValue(c, false)
+			case !isNewLine:
+				appendValue('\\', false)
+				appendValue(c, false)
+			}
+		case sdEnvStateComment:
+			switch {
+			case c == '\\':
+				state = sdEnvStateCommentEscape
+			case isNewLine:
+				state = sdEnvStatePreKey
+			}
+		case sdEnvStateCommentEscape:
+			state = sdEnvStateComment
+		}
+	}
+
+	return configMap
+}
@@ -0,0 +1,153 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestSystemdCredentialsLoader(t *testing.T) {
+	// Note: do not run this test with t.Parallel() as it sets ENVs.
+
+	t.Run("success - credentials are loaded", testSystemdCredentialsLoaderSuccess)
+	t.Run("error - credentials directory not set", testSystemdCredentialsLoaderReturnsErrDirNotSet)
+}
+
+func testSystemdCredentialsLoaderSuccess(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "db_password"), []byte("s3cr3t\n"), 0o600); err != nil {
+		t.Fatal("prerequisite failed:", err)
+	}
+	t.Setenv("CREDENTIALS_DIRECTORY", dir)
+	subject := xconf.SystemdCredentialsLoader("CRED_")
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"CRED_db_password": "s3cr3t"}, config)
+}
+
+func testSystemdCredentialsLoaderReturnsErrDirNotSet(t *testing.T) {
+	// arrange
+	t.Setenv("CREDENTIALS_DIRECTORY", "")
+	subject := xconf.SystemdCredentialsLoader()
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, errors.Is(err, xconf.ErrSystemdCredentialsDirNotSet))
+}
+
+func TestSystemdEnvReaderLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	content := `# a comment
+; another comment \
+continued comment
+EMPTY=
+  SPACED_KEY  =  spaced value
+SINGLE='single $quoted \n value'
+DOUBLE="double \"quoted\" \$value \n"
+UNQUOTED=escaped\ space\\
+CONTINUED=first \
+line
+MULTI_LINE="line 1
+line 2"
+CONCAT='a'"b"
+NO_ASSIGNMENT
+WINDOWS=crlf` + "\r\n"
+	subject := xconf.SystemdEnvReaderLoader(strings.NewReader(content))
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{
+			"EMPTY":      "",
+			"SPACED_KEY": "spaced value",
+			"SINGLE":     `single $quoted \n value`,
+			"DOUBLE":     `double "quoted" $value \n`,
+			"UNQUOTED":   `escaped space\`,
+			"CONTINUED":  "first line",
+			"MULTI_LINE": "line 1\nline 2",
+			"CONCAT":     "ab",
+			"WINDOWS":    "crlf",
+		},
+		config,
+	)
+}
+
+func TestSystemdEnvFileLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - valid file", testSystemdEnvFileLoaderSuccess)
+	t.Run("error - file does not exist", testSystemdEnvFileLoaderReturnsErrFileNotExist)
+}
+
+func testSystemdEnvFileLoaderSuccess(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	filePath := filepath.Join(t.TempDir(), "app.env")
+	if err := os.WriteFile(filePath, []byte("FOO=bar\nYEAR=2022\n"), 0o600); err != nil {
+		t.Fatal("prerequisite failed:", err)
+	}
+	subject := xconf.SystemdEnvFileLoader(filePath)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"FOO": "bar", "YEAR": "2022"}, config)
+}
+
+func testSystemdEnvFileLoaderReturnsErrFileNotExist(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.SystemdEnvFileLoader("/this/env/file/does/not/exist")
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, errors.Is(err, os.ErrNotExist))
+}
+
+func ExampleSystemdEnvReaderLoader() {
+	loader := xconf.SystemdEnvReaderLoader(strings.NewReader(`# app settings
+APP_NAME="my \"awesome\" app"
+APP_GREETING='Hello $USER'`))
+
+	configMap, err := loader.Load()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(configMap["APP_NAME"])
+	fmt.Println(configMap["APP_GREETING"])
+
+	// Output:
+	// my "awesome" app
+	// Hello $USER
+}
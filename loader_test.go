@@ -0,0 +1,229 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+// closerLoaderMock is a Loader which implements io.Closer.
+type closerLoaderMock struct {
+	xconf.Loader
+	closeCallsCnt uint32
+	closeErr      error
+}
+
+func (mock *closerLoaderMock) Close() error {
+	atomic.AddUint32(&mock.closeCallsCnt, 1)
+
+	return mock.closeErr
+}
+
+func TestCloseLoaders(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - whole graph is closed", testCloseLoadersClosesWholeGraph)
+	t.Run("error - from closer loaders", testCloseLoadersReturnsErrFromLoaders)
+	t.Run("success - decorators forward close", testCloseLoadersDecoratorsForwardClose)
+}
+
+func testCloseLoadersClosesWholeGraph(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		closer1 = &closerLoaderMock{Loader: xconf.PlainLoader(map[string]any{"foo": "bar"})}
+		closer2 = &closerLoaderMock{Loader: xconf.PlainLoader(map[string]any{"baz": "qux"})}
+		closer3 = &closerLoaderMock{Loader: xconf.JSONFileLoader(jsonFilePath)}
+		subject = xconf.FilterKVLoader(
+			xconf.NewMultiLoader(
+				true,
+				xconf.NamespaceLoader(closer1, "ns"),
+				xconf.NewFlattenLoader(xconf.AliasLoader(closer2, "alias", "baz")),
+				xconf.NewFileCacheLoader(closer3, jsonFilePath),
+				xconf.EnvLoader(),
+			),
+			xconf.FilterKVBlacklistFunc(xconf.FilterEmptyValue),
+		)
+	)
+
+	// act
+	err := xconf.CloseLoaders(subject)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, uint32(1), atomic.LoadUint32(&closer1.closeCallsCnt))
+	assertEqual(t, uint32(1), atomic.LoadUint32(&closer2.closeCallsCnt))
+	assertEqual(t, uint32(1), atomic.LoadUint32(&closer3.closeCallsCnt))
+}
+
+func testCloseLoadersReturnsErrFromLoaders(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr1 = errors.New("intentionally triggered close error 1")
+		expectedErr2 = errors.New("intentionally triggered close error 2")
+		closer1      = &closerLoaderMock{Loader: xconf.PlainLoader(nil), closeErr: expectedErr1}
+		closer2      = &closerLoaderMock{Loader: xconf.PlainLoader(nil), closeErr: expectedErr2}
+		subject      = xconf.NewMultiLoader(true, closer1, closer2)
+	)
+
+	// act
+	err := xconf.CloseLoaders(subject)
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr1))
+	assertTrue(t, errors.Is(err, expectedErr2))
+}
+
+func testCloseLoadersDecoratorsForwardClose(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	closer := &closerLoaderMock{Loader: xconf.PlainLoader(map[string]any{"foo": "bar"})}
+	decorators := [...]xconf.Loader{
+		xconf.AliasLoader(closer, "alias", "foo"),
+		xconf.TwoWayAliasLoader(closer, "alias", "foo"),
+		xconf.AlterValueLoader(closer, xconf.ToStringList(","), "foo"),
+		xconf.FilterKVLoader(closer),
+		xconf.IgnoreErrorLoader(closer),
+		xconf.NamespaceLoader(closer, "ns"),
+		xconf.RenameKeyLoader(closer, map[string]string{"foo": "bar"}),
+		xconf.RenameKeyRegexpLoader(closer, regexp.MustCompile("^foo$"), "bar"),
+		xconf.StripPrefixLoader(closer, "ns."),
+		xconf.NormalizeLoader(closer),
+		xconf.NullPolicyLoader(closer, xconf.NullAsMissing),
+		xconf.KeyNamingLoader(closer, xconf.KeyNamingRules{}, nil),
+		xconf.NewFlattenLoader(closer),
+		xconf.NewExpandEnvLoader(closer),
+		xconf.NewTemplateLoader(closer),
+		xconf.TranslationLoader(closer, xconf.TranslationTable{}),
+		xconf.DecryptValueLoader(closer, xconf.NewAESGCMKeyProvider("passphrase")),
+		xconf.ValidateLoader(closer, xconf.RequiredKeys("foo")),
+		xconf.NewFileCacheLoader(closer, jsonFilePath),
+		xconf.NewDirCacheLoader(closer, "testdata"),
+		xconf.NewChaosLoader(closer),
+		xconf.NewInstrumentedLoader(closer, "instrumented"),
+		xconf.NewMultiLoader(true, closer),
+		xconf.NewFailoverLoader([]xconf.Loader{closer}),
+		xconf.NewPrecedence([]xconf.PrecedenceLayer{{Label: "layer", Loader: closer}}),
+		xconf.OverlayLoader(closer, xconf.PlainLoader(nil), xconf.OverlayMergePatch),
+		xconf.NewScriptLoader(nil, "", xconf.ScriptLoaderWithInput("input", closer)),
+	}
+
+	for idx, decorator := range decorators {
+		subject, ok := decorator.(io.Closer)
+		if !assertTrue(t, ok) {
+			continue
+		}
+
+		// act
+		err := subject.Close()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, uint32(idx+1), atomic.LoadUint32(&closer.closeCallsCnt))
+	}
+}
+
+func TestLoadWithContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - context is passed through decorators", testLoadWithContextPassesContext)
+	t.Run("error - canceled context, plain loader is not called", testLoadWithContextCanceledLoader)
+	t.Run("error - canceled context stops injected latency", testLoadWithContextCanceledLatency)
+}
+
+type ctxKey struct{}
+
+func testLoadWithContextPassesContext(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		ctx    = context.WithValue(context.Background(), ctxKey{}, "request-1")
+		loader = xconf.ContextLoaderFunc(func(ctx context.Context) (map[string]any, error) {
+			return map[string]any{"foo": ctx.Value(ctxKey{})}, nil
+		})
+		subject = xconf.FilterKVLoader(
+			xconf.NewMultiLoader(
+				true,
+				xconf.NamespaceLoader(xconf.AliasLoader(loader, "bar", "foo"), "ns"),
+				xconf.NewFlattenLoader(loader),
+			),
+		)
+	)
+
+	// act
+	config, err := xconf.LoadWithContext(ctx, subject)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]any{"ns.foo": "request-1", "ns.bar": "request-1", "foo": "request-1"},
+		config,
+	)
+}
+
+func testLoadWithContextCanceledLoader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loadsCnt    int
+		ctx, cancel = context.WithCancel(context.Background())
+		subject     = xconf.NewMultiLoader(
+			true,
+			xconf.LoaderFunc(func() (map[string]any, error) {
+				loadsCnt++
+
+				return map[string]any{"foo": "bar"}, nil
+			}),
+		)
+	)
+	cancel()
+
+	// act
+	config, err := xconf.LoadWithContext(ctx, subject)
+
+	// assert
+	assertTrue(t, errors.Is(err, context.Canceled))
+	assertNil(t, config)
+	assertEqual(t, 0, loadsCnt)
+}
+
+func testLoadWithContextCanceledLatency(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+		subject     = xconf.NewChaosLoader(
+			xconf.PlainLoader(map[string]any{"foo": "bar"}),
+			xconf.ChaosLoaderWithLatency(time.Minute, time.Minute),
+		)
+	)
+	defer cancel()
+
+	// act
+	startTime := time.Now()
+	config, err := xconf.LoadWithContext(ctx, subject)
+
+	// assert
+	assertTrue(t, errors.Is(err, context.DeadlineExceeded))
+	assertNil(t, config)
+	assertTrue(t, time.Since(startTime) < time.Minute)
+}
@@ -0,0 +1,358 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Note: Vault HTTP API is consumed directly, in order not to depend on the official client.
+// Vault API ver was 1.15 at the time this code was written.
+
+const (
+	vaultAddrEnvName      = "VAULT_ADDR"
+	vaultTokenEnvName     = "VAULT_TOKEN"
+	vaultNamespaceEnvName = "VAULT_NAMESPACE"
+	vaultDefaultAddr      = "https://127.0.0.1:8200"
+	vaultDefaultMountPath = "secret"
+	vaultDefaultAppRole   = "approle"
+
+	// VaultHeaderToken is the header name for setting a token.
+	VaultHeaderToken = "X-Vault-Token"
+	// VaultHeaderNamespace is the header name for setting a namespace (enterprise).
+	VaultHeaderNamespace = "X-Vault-Namespace"
+)
+
+// ErrVaultSecretNotFound is returned by [VaultLoader] when the secret is not found.
+var ErrVaultSecretNotFound = errors.New("404 - Vault secret not found")
+
+// VaultLoader loads configuration from HashiCorp Vault's KV (v1 / v2) secrets engine.
+// The secret's key-value pairs make up the configuration.
+// Token and AppRole authentication methods are supported.
+//
+// By default, Vault address, token and namespace are taken from the standard
+// VAULT_ADDR, VAULT_TOKEN and VAULT_NAMESPACE environment variables,
+// KV v2 secrets engine mounted at "secret/" is used.
+type VaultLoader struct {
+	path       string        // the secret's path, relative to mount path
+	mountPath  string        // the secrets engine's mount path
+	kvVersion  int           // the KV secrets engine version, 1 or 2
+	addr       string        // Vault's address
+	namespace  string        // the namespace (enterprise)
+	token      string        // the auth token, if token auth method is used
+	appRole    *vaultAppRole // the AppRole auth method, if used
+	httpClient *http.Client  // the http client used for calls
+	tlsConfig  *tls.Config   // the TLS configuration, if custom
+	initErr    error         // error occurred while configuring the loader, returned by Load
+	ctx        context.Context
+}
+
+// vaultAppRole holds AppRole auth method info, and the token obtained by logging in.
+type vaultAppRole struct {
+	mountPath string
+	roleID    string
+	secretID  string
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewVaultLoader instantiates a new VaultLoader object that loads
+// configuration from a Vault secret, found at given path.
+func NewVaultLoader(path string, opts ...VaultLoaderOption) VaultLoader {
+	loader := VaultLoader{
+		path:       strings.Trim(path, "/"),
+		mountPath:  vaultDefaultMountPath,
+		kvVersion:  2,
+		addr:       getDefaultVaultAddr(),
+		namespace:  os.Getenv(vaultNamespaceEnvName),
+		token:      os.Getenv(vaultTokenEnvName),
+		httpClient: newDefaultHTTPClient(),
+		ctx:        context.Background(),
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&loader)
+	}
+	if loader.tlsConfig != nil {
+		loader.httpClient, loader.initErr = withTLSConfig(loader.httpClient, loader.tlsConfig)
+	}
+
+	return loader
+}
+
+// Load returns a configuration key-value map from a Vault secret, or an error
+// if something bad happens along the process.
+func (loader VaultLoader) Load() (map[string]any, error) {
+	return loader.LoadContext(loader.ctx)
+}
+
+// LoadContext is like Load, with the request(s) being canceled also when given context is done.
+// It implements [ContextLoader].
+func (loader VaultLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	if loader.initErr != nil {
+		return nil, loader.initErr
+	}
+	ctx, cancelCtx := mergeContexts(ctx, loader.ctx)
+	defer cancelCtx()
+	loader.ctx = ctx // Note: loader is a copy.
+
+	configMap, err := loader.readSecret()
+	var respErr vaultResponseError
+	if errors.As(err, &respErr) && respErr.statusCode == http.StatusForbidden && loader.appRole != nil {
+		loader.appRole.invalidateToken() // token may have been revoked, login again.
+		configMap, err = loader.readSecret()
+	}
+
+	return configMap, err
+}
+
+// readSecret reads the secret.
+func (loader VaultLoader) readSecret() (map[string]any, error) {
+	token, err := loader.authToken()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := loader.addr + "/v1/" + loader.mountPath + "/" + loader.path
+	if loader.kvVersion == 2 {
+		endpoint = loader.addr + "/v1/" + loader.mountPath + "/data/" + loader.path
+	}
+	var secret struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := loader.do(http.MethodGet, endpoint, token, nil, &secret); err != nil {
+		return nil, err
+	}
+
+	var configMap map[string]any
+	if loader.kvVersion == 2 {
+		var versionedData struct {
+			Data map[string]any `json:"data"`
+		}
+		if err := json.Unmarshal(secret.Data, &versionedData); err != nil {
+			return nil, err
+		}
+		configMap = versionedData.Data
+	} else if err := json.Unmarshal(secret.Data, &configMap); err != nil {
+		return nil, err
+	}
+	if configMap == nil { // KV v2 returns null data for a deleted secret version.
+		return nil, ErrVaultSecretNotFound
+	}
+
+	return configMap, nil
+}
+
+// authToken returns the token to authenticate requests with,
+// logging in with AppRole, if configured and there is no valid token.
+func (loader VaultLoader) authToken() (string, error) {
+	if loader.appRole == nil {
+		return loader.token, nil
+	}
+
+	loader.appRole.mu.Lock()
+	defer loader.appRole.mu.Unlock()
+	if loader.appRole.token != "" && time.Now().Before(loader.appRole.expiresAt) {
+		return loader.appRole.token, nil
+	}
+
+	reqBody, err := json.Marshal(map[string]string{
+		"role_id":   loader.appRole.roleID,
+		"secret_id": loader.appRole.secretID,
+	})
+	if err != nil {
+		return "", err
+	}
+	var login struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int64  `json:"lease_duration"`
+		} `json:"auth"`
+	}
+	endpoint := loader.addr + "/v1/auth/" + loader.appRole.mountPath + "/login"
+	if err := loader.do(http.MethodPost, endpoint, "", reqBody, &login); err != nil {
+		return "", err
+	}
+
+	loader.appRole.token = login.Auth.ClientToken
+	// renew the token when 90% of its lease elapsed.
+	ttl := time.Duration(login.Auth.LeaseDuration) * time.Second
+	loader.appRole.expiresAt = time.Now().Add(ttl - ttl/10)
+
+	return loader.appRole.token, nil
+}
+
+// invalidateToken discards the token obtained by logging in.
+func (appRole *vaultAppRole) invalidateToken() {
+	appRole.mu.Lock()
+	appRole.token = ""
+	appRole.mu.Unlock()
+}
+
+// do makes a Vault API call and decodes the JSON response into result.
+func (loader VaultLoader) do(method, endpoint, token string, reqBody []byte, result any) error {
+	var body io.Reader
+	if reqBody != nil {
+		body = bytes.NewReader(reqBody)
+	}
+	req, err := http.NewRequestWithContext(loader.ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "Go-ActForGood-Xconf/1.0")
+	if token != "" {
+		req.Header.Set(VaultHeaderToken, token)
+	}
+	if loader.namespace != "" {
+		req.Header.Set(VaultHeaderNamespace, loader.namespace)
+	}
+
+	resp, err := loader.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer closeResponseBody(resp)
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrVaultSecretNotFound
+	case resp.StatusCode != http.StatusOK:
+		var errResp struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&errResp)
+
+		return vaultResponseError{statusCode: resp.StatusCode, errs: errResp.Errors}
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// vaultResponseError is the error returned for a Vault API non 200 OK / 404 response.
+type vaultResponseError struct {
+	statusCode int
+	errs       []string
+}
+
+// Error returns string representation of the error.
+func (err vaultResponseError) Error() string {
+	return fmt.Sprintf("Vault responded with status code %d: %s", err.statusCode, strings.Join(err.errs, "; "))
+}
+
+// getDefaultVaultAddr tries to get Vault address from ENV.
+// It defaults on localhost address.
+func getDefaultVaultAddr() string {
+	if addr := os.Getenv(vaultAddrEnvName); addr != "" {
+		return strings.TrimRight(addr, "/")
+	}
+
+	return vaultDefaultAddr
+}
+
+// VaultLoaderOption defines optional function for configuring
+// a Vault Loader.
+type VaultLoaderOption func(*VaultLoader)
+
+// VaultLoaderWithAddress sets Vault's address.
+// By default, is set to VAULT_ADDR ENV, or "https://127.0.0.1:8200".
+//
+// Example:
+//
+//	xconf.VaultLoaderWithAddress("https://vault.example.com:8200")
+func VaultLoaderWithAddress(addr string) VaultLoaderOption {
+	return func(loader *VaultLoader) {
+		loader.addr = strings.TrimRight(addr, "/")
+	}
+}
+
+// VaultLoaderWithToken sets the token used to authenticate (token auth method).
+// By default, is set to VAULT_TOKEN ENV.
+func VaultLoaderWithToken(token string) VaultLoaderOption {
+	return func(loader *VaultLoader) {
+		loader.token = token
+	}
+}
+
+// VaultLoaderWithAppRole enables AppRole auth method, with given role and secret IDs.
+// The auth method's mount path is optional, by default "approle" is used.
+// The token obtained by logging in is reused until 90% of its lease elapsed.
+func VaultLoaderWithAppRole(roleID, secretID string, mountPath ...string) VaultLoaderOption {
+	return func(loader *VaultLoader) {
+		appRole := &vaultAppRole{
+			mountPath: vaultDefaultAppRole,
+			roleID:    roleID,
+			secretID:  secretID,
+		}
+		if len(mountPath) > 0 && mountPath[0] != "" {
+			appRole.mountPath = strings.Trim(mountPath[0], "/")
+		}
+		loader.appRole = appRole
+	}
+}
+
+// VaultLoaderWithMountPath sets the KV secrets engine's mount path.
+// By default, is set to "secret".
+func VaultLoaderWithMountPath(mountPath string) VaultLoaderOption {
+	return func(loader *VaultLoader) {
+		loader.mountPath = strings.Trim(mountPath, "/")
+	}
+}
+
+// VaultLoaderWithKVVersion sets the KV secrets engine's version, 1 or 2.
+// By default, is set to 2.
+func VaultLoaderWithKVVersion(kvVersion int) VaultLoaderOption {
+	return func(loader *VaultLoader) {
+		if kvVersion == 1 || kvVersion == 2 {
+			loader.kvVersion = kvVersion
+		}
+	}
+}
+
+// VaultLoaderWithNamespace sets the namespace (enterprise).
+// By default, is set to VAULT_NAMESPACE ENV.
+func VaultLoaderWithNamespace(namespace string) VaultLoaderOption {
+	return func(loader *VaultLoader) {
+		loader.namespace = namespace
+	}
+}
+
+// VaultLoaderWithTLSConfig sets the TLS configuration (like custom CA, client certificate)
+// of the http client's transport (which gets cloned, if VaultLoaderWithHTTPClient is used, too;
+// an error is returned by Load if that client's transport is not a *[http.Transport]).
+func VaultLoaderWithTLSConfig(tlsConfig *tls.Config) VaultLoaderOption {
+	return func(loader *VaultLoader) {
+		loader.tlsConfig = tlsConfig
+	}
+}
+
+// VaultLoaderWithHTTPClient sets the http client used for calls.
+// A default one is provided if you don't use this option.
+func VaultLoaderWithHTTPClient(client *http.Client) VaultLoaderOption {
+	return func(loader *VaultLoader) {
+		loader.httpClient = client
+	}
+}
+
+// VaultLoaderWithContext sets request 's context.
+// By default, a context.Background() is used.
+func VaultLoaderWithContext(ctx context.Context) VaultLoaderOption {
+	return func(loader *VaultLoader) {
+		loader.ctx = ctx
+	}
+}
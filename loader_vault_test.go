@@ -0,0 +1,313 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestVaultLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - kv v2 with token", testVaultLoaderKVv2WithToken)
+	t.Run("success - kv v1 with namespace", testVaultLoaderKVv1WithNamespace)
+	t.Run("success - approle login, token reused and renewed", testVaultLoaderWithAppRole)
+	t.Run("success - tls", testVaultLoaderWithTLS)
+	t.Run("error - tls, custom round tripper", testVaultLoaderWithTLSReturnsErrForCustomRoundTripper)
+	t.Run("error - secret not found", testVaultLoaderReturnsErrNotFound)
+	t.Run("error - permission denied", testVaultLoaderReturnsErrPermissionDenied)
+}
+
+// vaultMockServer is a Vault mock server.
+type vaultMockServer struct {
+	validToken  atomic.Value // the currently valid token
+	loginsCnt   uint32
+	secretsCnt  uint32
+	namespace   string
+	secretPaths map[string]string // path => JSON response's "data"
+}
+
+func (mock *vaultMockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get(xconf.VaultHeaderNamespace) != mock.namespace {
+		w.WriteHeader(http.StatusForbidden)
+
+		return
+	}
+	if r.URL.Path == "/v1/auth/my-approle/login" && r.Method == http.MethodPost {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["role_id"] != "my-role" || body["secret_id"] != "my-secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":["invalid role or secret ID"]}`))
+
+			return
+		}
+		cnt := atomic.AddUint32(&mock.loginsCnt, 1)
+		token := "approle-token-" + string(rune('0'+cnt))
+		mock.validToken.Store(token)
+		_, _ = w.Write([]byte(`{"auth":{"client_token":"` + token + `","lease_duration":3600}}`))
+
+		return
+	}
+	if token, _ := mock.validToken.Load().(string); r.Header.Get(xconf.VaultHeaderToken) != token {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+
+		return
+	}
+	data, found := mock.secretPaths[r.URL.Path]
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errors":[]}`))
+
+		return
+	}
+	atomic.AddUint32(&mock.secretsCnt, 1)
+	_, _ = w.Write([]byte(`{"request_id":"abc","lease_duration":0,"data":` + data + `}`))
+}
+
+// newVaultMockServer instantiates a new Vault mock server, accepting given token.
+func newVaultMockServer(token string) *vaultMockServer {
+	mock := &vaultMockServer{
+		secretPaths: map[string]string{
+			"/v1/secret/data/myapp/config": `{"data":{"db_password":"s3cr3t","db_port":3306},` +
+				`"metadata":{"version":3}}`,
+			"/v1/kv/myapp/config": `{"db_password":"s3cr3t-v1"}`,
+		},
+	}
+	mock.validToken.Store(token)
+
+	return mock
+}
+
+func testVaultLoaderKVv2WithToken(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	svr := httptest.NewServer(newVaultMockServer("my-token"))
+	defer svr.Close()
+	subject := xconf.NewVaultLoader(
+		"/myapp/config",
+		xconf.VaultLoaderWithAddress(svr.URL+"/"),
+		xconf.VaultLoaderWithToken("my-token"),
+		xconf.VaultLoaderWithNamespace(""),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"db_password": "s3cr3t", "db_port": float64(3306)}, config)
+}
+
+func testVaultLoaderKVv1WithNamespace(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	mock := newVaultMockServer("my-token")
+	mock.namespace = "team-a"
+	svr := httptest.NewServer(mock)
+	defer svr.Close()
+	subject := xconf.NewVaultLoader(
+		"myapp/config",
+		xconf.VaultLoaderWithAddress(svr.URL),
+		xconf.VaultLoaderWithToken("my-token"),
+		xconf.VaultLoaderWithMountPath("/kv/"),
+		xconf.VaultLoaderWithKVVersion(1),
+		xconf.VaultLoaderWithNamespace("team-a"),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]any{"db_password": "s3cr3t-v1"}, config)
+}
+
+func testVaultLoaderWithAppRole(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	mock := newVaultMockServer("")
+	svr := httptest.NewServer(mock)
+	defer svr.Close()
+	subject := xconf.NewVaultLoader(
+		"myapp/config",
+		xconf.VaultLoaderWithAddress(svr.URL),
+		xconf.VaultLoaderWithAppRole("my-role", "my-secret", "my-approle"),
+		xconf.VaultLoaderWithNamespace(""),
+	)
+
+	for i := 0; i < 2; i++ {
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, "s3cr3t", config["db_password"])
+	}
+	assertEqual(t, uint32(1), atomic.LoadUint32(&mock.loginsCnt))
+
+	// arrange
+	mock.validToken.Store("revoked")
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, "s3cr3t", config["db_password"])
+	assertEqual(t, uint32(2), atomic.LoadUint32(&mock.loginsCnt))
+	assertEqual(t, uint32(3), atomic.LoadUint32(&mock.secretsCnt))
+}
+
+func testVaultLoaderWithTLS(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name string
+		opts []xconf.VaultLoaderOption
+	}{
+		{name: "default http client"},
+		{
+			name: "custom http client without transport",
+			opts: []xconf.VaultLoaderOption{
+				xconf.VaultLoaderWithHTTPClient(&http.Client{Timeout: 5 * time.Second}),
+			},
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			svr := httptest.NewTLSServer(newVaultMockServer("my-token"))
+			defer svr.Close()
+			rootCAs := x509.NewCertPool()
+			rootCAs.AddCert(svr.Certificate())
+			subject := xconf.NewVaultLoader(
+				"myapp/config",
+				append(
+					test.opts,
+					xconf.VaultLoaderWithAddress(svr.URL),
+					xconf.VaultLoaderWithToken("my-token"),
+					xconf.VaultLoaderWithNamespace(""),
+					xconf.VaultLoaderWithTLSConfig(&tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}),
+				)...,
+			)
+
+			// act
+			config, err := subject.Load()
+
+			// assert
+			assertNil(t, err)
+			assertEqual(t, "s3cr3t", config["db_password"])
+		})
+	}
+}
+
+func testVaultLoaderWithTLSReturnsErrForCustomRoundTripper(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.NewVaultLoader(
+		"myapp/config",
+		xconf.VaultLoaderWithAddress("https://127.0.0.1:8200"),
+		xconf.VaultLoaderWithToken("my-token"),
+		xconf.VaultLoaderWithHTTPClient(&http.Client{Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+			t.Error("round tripper should not be called")
+
+			return nil, errors.New("not implemented")
+		})}),
+		xconf.VaultLoaderWithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	if assertNotNil(t, err) {
+		assertTrue(t, strings.Contains(err.Error(), "cannot apply TLS configuration"))
+	}
+}
+
+// roundTripperFunc is a custom [http.RoundTripper], used in tests.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+func testVaultLoaderReturnsErrNotFound(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	svr := httptest.NewServer(newVaultMockServer("my-token"))
+	defer svr.Close()
+	subject := xconf.NewVaultLoader(
+		"myapp/does-not-exist",
+		xconf.VaultLoaderWithAddress(svr.URL),
+		xconf.VaultLoaderWithToken("my-token"),
+		xconf.VaultLoaderWithNamespace(""),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, xconf.ErrVaultSecretNotFound))
+	assertNil(t, config)
+}
+
+func testVaultLoaderReturnsErrPermissionDenied(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	svr := httptest.NewServer(newVaultMockServer("my-token"))
+	defer svr.Close()
+	subjects := [...]xconf.Loader{
+		xconf.NewVaultLoader(
+			"myapp/config",
+			xconf.VaultLoaderWithAddress(svr.URL),
+			xconf.VaultLoaderWithToken("wrong-token"),
+			xconf.VaultLoaderWithNamespace(""),
+		),
+		xconf.NewVaultLoader(
+			"myapp/config",
+			xconf.VaultLoaderWithAddress(svr.URL),
+			xconf.VaultLoaderWithAppRole("my-role", "wrong-secret", "my-approle"),
+			xconf.VaultLoaderWithNamespace(""),
+		),
+	}
+	expectedErrs := [...]string{"permission denied", "invalid role or secret ID"}
+
+	for idx, subject := range subjects {
+		// act
+		config, err := subject.Load()
+
+		// assert
+		assertNil(t, config)
+		if assertNotNil(t, err) {
+			assertTrue(t, strings.Contains(err.Error(), expectedErrs[idx]))
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"time"
+)
+
+const (
+	// lockFileVersion is the current lock file format version.
+	lockFileVersion = 1
+	// lockFilePermission is the permission lock files are written with.
+	lockFilePermission = 0o600
+)
+
+// SourceVersion describes the version of the configuration a source provided.
+type SourceVersion struct {
+	// Kind is the kind of the source, like "consul", "etcd", "file".
+	Kind string `json:"kind"`
+	// Name identifies the source, like the key / file path.
+	Name string `json:"name"`
+	// Version is the version of the configuration, like Consul's ModifyIndex,
+	// etcd's revision, file's content hash.
+	Version string `json:"version"`
+}
+
+// SourceVersioner is implemented by loaders able to report the version
+// of the configuration they last loaded (like [ConsulLoader], [EtcdLoader]).
+// An empty Version is returned if nothing was loaded yet.
+type SourceVersioner interface {
+	SourceVersion() SourceVersion
+}
+
+// LockFile is the content of a configuration lock (freeze) file,
+// capturing the exact effective configuration, and the versions of its sources.
+type LockFile struct {
+	// Version is the lock file format version.
+	Version int `json:"version"`
+	// CreatedAt is the moment the lock file was written.
+	CreatedAt time.Time `json:"createdAt"`
+	// Sources hold the versions of the configuration sources.
+	Sources []SourceVersion `json:"sources,omitempty"`
+	// Config is the effective key-value configuration map.
+	Config map[string]any `json:"config"`
+}
+
+// lockFileOptions holds the options for writing a lock file.
+type lockFileOptions struct {
+	// filePaths are the configuration files to hash.
+	filePaths []string
+}
+
+// LockFileOption defines optional function for configuring
+// a lock file's content.
+type LockFileOption func(*lockFileOptions)
+
+// LockFileWithFileHashes adds to lock file's sources the SHA-256 hashes
+// of given configuration files' content.
+func LockFileWithFileHashes(filePaths ...string) LockFileOption {
+	return func(opts *lockFileOptions) {
+		opts.filePaths = append(opts.filePaths, filePaths...)
+	}
+}
+
+// WriteLockFile writes to given path a lock (freeze) file capturing the exact
+// effective configuration, and the versions of the sources found in config's
+// loaders graph (see [SourceVersioner]), so that a configuration (like a customer
+// environment's one) can be reproduced exactly, with [LockFileLoader].
+//
+// Lock file is a JSON document, so, for example, numbers are loaded back as float64.
+// File is written with 0600 permissions, as configuration may contain secrets.
+//
+// Usage example:
+//
+//	err := cfg.WriteLockFile("xconf.lock", xconf.LockFileWithFileHashes("config.yaml"))
+//	// ...
+//	// and, to reproduce it:
+//	cfg, err := xconf.NewDefaultConfig(xconf.LockFileLoader("xconf.lock"))
+func (cfg *defaultConfig) WriteLockFile(filePath string, opts ...LockFileOption) error {
+	var lockOpts lockFileOptions
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&lockOpts)
+	}
+
+	lock := LockFile{
+		Version:   lockFileVersion,
+		CreatedAt: time.Now().UTC(),
+		Config:    cfg.configMapSnapshot(),
+	}
+	collectSourceVersions(cfg.loader, &lock.Sources)
+	for _, configFilePath := range lockOpts.filePaths {
+		hash, err := hashFile(configFilePath)
+		if err != nil {
+			return err
+		}
+		lock.Sources = append(lock.Sources, SourceVersion{
+			Kind:    "file",
+			Name:    configFilePath,
+			Version: "sha256:" + hash,
+		})
+	}
+
+	content, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpFilePath := filePath + ".tmp"
+	if err := os.WriteFile(tmpFilePath, content, lockFilePermission); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpFilePath, filePath); err != nil {
+		_ = os.Remove(tmpFilePath)
+
+		return err
+	}
+
+	return nil
+}
+
+// ReadLockFile reads a lock file written with [DefaultConfig.WriteLockFile].
+func ReadLockFile(filePath string) (LockFile, error) {
+	var lock LockFile
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return lock, err
+	}
+	err = json.Unmarshal(content, &lock)
+
+	return lock, err
+}
+
+// LockFileLoader loads the configuration captured in a lock file
+// written with [DefaultConfig.WriteLockFile].
+func LockFileLoader(filePath string) Loader {
+	return LoaderFunc(func() (map[string]any, error) {
+		lock, err := ReadLockFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		return lock.Config, nil
+	})
+}
+
+// collectSourceVersions collects recursively given loader(s) source versions.
+func collectSourceVersions(loader Loader, versions *[]SourceVersion) {
+	if versioner, ok := loader.(SourceVersioner); ok {
+		if version := versioner.SourceVersion(); version.Version != "" {
+			*versions = append(*versions, version)
+		}
+	}
+	if unwrapper, ok := loader.(LoaderUnwrapper); ok {
+		for _, innerLoader := range unwrapper.Unwrap() {
+			collectSourceVersions(innerLoader, versions)
+		}
+	}
+}
+
+// hashFile returns the hex encoded SHA-256 hash of given file's content.
+func hashFile(filePath string) (string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:]), nil
+}
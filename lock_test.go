@@ -0,0 +1,116 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestDefaultConfig_WriteLockFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - config and sources versions are captured and restored", testDefaultConfigWriteLockFile)
+	t.Run("error - file to hash not found", testDefaultConfigWriteLockFileReturnsErrFromFileHash)
+	t.Run("error - lock file not found", testLockFileLoaderReturnsErrFromMissingFile)
+}
+
+func testDefaultConfigWriteLockFile(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		format  = xconf.RemoteValueJSON
+		key     = consulKeys[format]
+		svr     = startConsulKVMockServer(t, key, consulResponseContent[format][true], true)
+		dir     = t.TempDir()
+		lockFP  = filepath.Join(dir, "xconf.lock")
+		localFP = filepath.Join(dir, "local.env")
+	)
+	defer svr.Close()
+	requireNil(t, os.WriteFile(localFP, []byte("APP_NAME=demo\n"), 0o600))
+	config, err := xconf.NewDefaultConfig(xconf.NewMultiLoader(
+		true,
+		xconf.NewConsulLoader(
+			key,
+			xconf.ConsulLoaderWithHost(svr.URL),
+			xconf.ConsulLoaderWithValueFormat(format),
+			xconf.ConsulLoaderWithPrefix(),
+		),
+		xconf.DotEnvFileLoader(localFP),
+	))
+	requireNil(t, err)
+
+	// act
+	err = config.WriteLockFile(lockFP, xconf.LockFileWithFileHashes(localFP))
+
+	// assert
+	requireNil(t, err)
+	lock, err := xconf.ReadLockFile(lockFP)
+	requireNil(t, err)
+	assertEqual(t, 1, lock.Version)
+	assertTrue(t, !lock.CreatedAt.IsZero())
+	assertEqual(t, []xconf.SourceVersion{
+		{Kind: "consul", Name: key, Version: "68"},
+		{
+			Kind:    "file",
+			Name:    localFP,
+			Version: "sha256:e48d4ab3401da7eba78b93191b497db7aa233cffd4ebc601ddec1e6f10c23bc5",
+		},
+	}, lock.Sources)
+	expectedConfigMap := getConsulExpectedConfigMapByFormatAndPrefix(format, true)
+	expectedConfigMap["APP_NAME"] = "demo"
+	assertEqual(t, expectedConfigMap, lock.Config)
+	fileInfo, err := os.Stat(lockFP)
+	requireNil(t, err)
+	assertEqual(t, fs.FileMode(0o600), fileInfo.Mode().Perm())
+
+	// act - configuration is restored from lock file.
+	restoredConfig, err := xconf.NewDefaultConfig(xconf.LockFileLoader(lockFP))
+
+	// assert
+	requireNil(t, err)
+	assertEqual(t, "demo", restoredConfig.Get("APP_NAME"))
+	assertEqual(t, "xyz", restoredConfig.Get("consul_json_abc"))
+	assertEqual(t, float64(2022), restoredConfig.Get("consul_json_year"))
+}
+
+func testDefaultConfigWriteLockFileReturnsErrFromFileHash(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		lockFP    = filepath.Join(t.TempDir(), "xconf.lock")
+		config, _ = xconf.NewDefaultConfig(xconf.PlainLoader(map[string]any{"foo": "bar"}))
+	)
+
+	// act
+	err := config.WriteLockFile(lockFP, xconf.LockFileWithFileHashes("testdata/not_found.yaml"))
+
+	// assert
+	assertTrue(t, errors.Is(err, fs.ErrNotExist))
+	_, err = os.Stat(lockFP)
+	assertTrue(t, errors.Is(err, fs.ErrNotExist))
+}
+
+func testLockFileLoaderReturnsErrFromMissingFile(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.LockFileLoader("testdata/not_found.lock")
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertNil(t, config)
+	assertTrue(t, errors.Is(err, fs.ErrNotExist))
+}
@@ -0,0 +1,243 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/actforgood/xerr"
+)
+
+// ErrInvalidPrecedence is the error returned by [Precedence]'s Load, if its layers are misconfigured
+// (no layers, empty / duplicate label, nil loader).
+var ErrInvalidPrecedence = errors.New("invalid precedence")
+
+// PrecedenceLayer is a labeled configuration layer of a [Precedence].
+type PrecedenceLayer struct {
+	// Label identifies the layer, like "defaults", "file", "env", "flags".
+	// It must be unique and not empty.
+	Label string
+	// Loader is the layer's configuration source.
+	Loader Loader
+}
+
+// Precedence is a loader which merges configuration from labeled layers,
+// according to a strict total order. Resolution is specified as follows:
+//
+//  1. Layers are given from the lowest to the highest precedence.
+//  2. A key's value is the one from the highest precedence layer defining the key.
+//     A key defined with a nil value is defined (see [NullPolicyLoader] to treat it otherwise).
+//  3. Keys are compared case-sensitively, unless [PrecedenceWithIgnoreCase] is used,
+//     in which case keys differing only by case are the same key, and the key's spelling
+//     from the winning layer is kept.
+//  4. With ignore case, if a layer defines more spellings of the same key, the one
+//     sorting last (byte-wise) wins inside that layer (for example "foo" wins over "Foo" and "FOO").
+//  5. If any layer fails to load, the whole Load fails (no partial configuration is returned),
+//     the error being prefixed with the layer's label.
+//
+// The resolution does not depend on layers' loading order / timing, or on maps' iteration order.
+// It implements [KeySourcer], the source being the label of the layer a key was resolved from,
+// on the last successful Load.
+//
+// Example:
+//
+//	loader := xconf.NewPrecedence(
+//		[]xconf.PrecedenceLayer{
+//			{Label: "defaults", Loader: xconf.PlainLoader(defaults)},
+//			{Label: "file", Loader: xconf.YAMLFileLoader("config.yaml")},
+//			{Label: "env", Loader: xconf.EnvLoader()},
+//		},
+//		xconf.PrecedenceWithIgnoreCase(),
+//	)
+type Precedence struct {
+	layers     []PrecedenceLayer  // layers, from lowest to highest precedence.
+	ignoreCase bool               // whether keys are compared case-insensitively.
+	sources    *precedenceSources // keys' resolved layer labels, on last Load.
+}
+
+// precedenceSources holds the labels of the layers keys were resolved from.
+type precedenceSources struct {
+	mu     sync.RWMutex
+	labels map[string]string
+}
+
+// NewPrecedence instantiates a new Precedence object that loads configuration
+// from given layers, ordered from the lowest to the highest precedence.
+func NewPrecedence(layers []PrecedenceLayer, opts ...PrecedenceOption) Precedence {
+	loader := Precedence{
+		layers:  append([]PrecedenceLayer(nil), layers...),
+		sources: new(precedenceSources),
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&loader)
+	}
+
+	return loader
+}
+
+// Load returns the configuration key-value map resolved from all the layers,
+// or an error if something bad happens along the process.
+func (loader Precedence) Load() (map[string]any, error) {
+	return loader.LoadContext(context.Background())
+}
+
+// LoadContext is like Load, passing given context to the layers' loaders.
+// It implements [ContextLoader].
+func (loader Precedence) LoadContext(ctx context.Context) (map[string]any, error) {
+	if err := loader.validate(); err != nil {
+		return nil, err
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results = make([]loadResult, len(loader.layers))
+		mErr    *xerr.MultiError
+	)
+	for idx, layer := range loader.layers {
+		wg.Add(1)
+		go loadAsync(ctx, layer.Loader, idx, &wg, &mu, results)
+	}
+	wg.Wait()
+	for idx, result := range results {
+		if result.err != nil {
+			mErr = mErr.Add(xerr.Wrapf(result.err, "layer %q", loader.layers[idx].Label))
+		}
+	}
+	if err := mErr.ErrOrNil(); err != nil {
+		return nil, err
+	}
+
+	var (
+		configMap = make(map[string]any)
+		labels    = make(map[string]string)
+		spellings = make(map[string]string) // normalized key => key's spelling in config map.
+	)
+	for idx, result := range results { // from the lowest to the highest precedence.
+		keys := make([]string, 0, len(result.configMap))
+		for key := range result.configMap {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			normalizedKey := loader.normalizeKey(key)
+			if spelling, found := spellings[normalizedKey]; found {
+				delete(configMap, spelling)
+			}
+			spellings[normalizedKey] = key
+			configMap[key] = result.configMap[key]
+			labels[normalizedKey] = loader.layers[idx].Label
+		}
+	}
+
+	loader.sources.mu.Lock()
+	loader.sources.labels = labels
+	loader.sources.mu.Unlock()
+
+	return configMap, nil
+}
+
+// validate checks the layers are properly configured.
+func (loader Precedence) validate() error {
+	if len(loader.layers) == 0 {
+		return xerr.Wrapf(ErrInvalidPrecedence, "no layers")
+	}
+	labels := make(map[string]struct{}, len(loader.layers))
+	for idx, layer := range loader.layers {
+		if layer.Label == "" {
+			return xerr.Wrapf(ErrInvalidPrecedence, "layer #%d has empty label", idx)
+		}
+		if _, found := labels[layer.Label]; found {
+			return xerr.Wrapf(ErrInvalidPrecedence, "duplicate layer label %q", layer.Label)
+		}
+		if layer.Loader == nil {
+			return xerr.Wrapf(ErrInvalidPrecedence, "layer %q has nil loader", layer.Label)
+		}
+		labels[layer.Label] = struct{}{}
+	}
+
+	return nil
+}
+
+// normalizeKey returns the key used for comparing keys.
+func (loader Precedence) normalizeKey(key string) string {
+	if loader.ignoreCase {
+		return strings.ToUpper(key)
+	}
+
+	return key
+}
+
+// KeySource returns the label of the layer given key was resolved from, on last successful Load.
+func (loader Precedence) KeySource(key string) (string, bool) {
+	loader.sources.mu.RLock()
+	defer loader.sources.mu.RUnlock()
+
+	label, found := loader.sources.labels[loader.normalizeKey(key)]
+
+	return label, found
+}
+
+// Labels returns the layers' labels, from the lowest to the highest precedence.
+func (loader Precedence) Labels() []string {
+	labels := make([]string, len(loader.layers))
+	for idx, layer := range loader.layers {
+		labels[idx] = layer.Label
+	}
+
+	return labels
+}
+
+// Close closes the layers' loaders which implement [io.Closer].
+// All loaders are tried to be closed, errors being aggregated.
+func (loader Precedence) Close() error {
+	return CloseLoaders(loader)
+}
+
+// Unwrap returns the layers' loaders.
+func (loader Precedence) Unwrap() []Loader {
+	loaders := make([]Loader, 0, len(loader.layers))
+	for _, layer := range loader.layers {
+		if layer.Loader != nil {
+			loaders = append(loaders, layer.Loader)
+		}
+	}
+
+	return loaders
+}
+
+// mapLoaders returns a copy of the Precedence, with layers' loaders replaced by the ones returned by fn.
+func (loader Precedence) mapLoaders(fn func(Loader) Loader) Loader {
+	layers := make([]PrecedenceLayer, len(loader.layers))
+	for idx, layer := range loader.layers {
+		if layer.Loader != nil {
+			layer.Loader = fn(layer.Loader)
+		}
+		layers[idx] = layer
+	}
+	loader.layers = layers
+
+	return loader
+}
+
+// PrecedenceOption defines optional function for configuring a Precedence.
+type PrecedenceOption func(*Precedence)
+
+// PrecedenceWithIgnoreCase makes keys differing only by case be treated as the same key.
+// Use it together with [DefaultConfigWithIgnoreCaseSensitivity], in order to have a
+// deterministic resolution of such keys.
+// By default, keys are compared case-sensitively.
+func PrecedenceWithIgnoreCase() PrecedenceOption {
+	return func(loader *Precedence) {
+		loader.ignoreCase = true
+	}
+}
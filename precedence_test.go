@@ -0,0 +1,238 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestPrecedence(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - resolution rules", testPrecedenceResolutionRules)
+	t.Run("success - resolution is deterministic", testPrecedenceIsDeterministic)
+	t.Run("success - key source", testPrecedenceKeySource)
+	t.Run("error - layer load error", testPrecedenceReturnsLayerErr)
+	t.Run("error - invalid layers", testPrecedenceReturnsErrInvalidPrecedence)
+}
+
+func testPrecedenceResolutionRules(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name           string
+		layers         []map[string]any
+		ignoreCase     bool
+		expectedConfig map[string]any
+	}{
+		{
+			name: "highest layer wins",
+			layers: []map[string]any{
+				{"foo": "defaults", "bar": "defaults"},
+				{"foo": "file"},
+				{"foo": "env", "baz": "env"},
+			},
+			expectedConfig: map[string]any{"foo": "env", "bar": "defaults", "baz": "env"},
+		},
+		{
+			name: "nil value is a defined value",
+			layers: []map[string]any{
+				{"foo": "defaults"},
+				{"foo": nil},
+			},
+			expectedConfig: map[string]any{"foo": nil},
+		},
+		{
+			name: "nil layer configuration",
+			layers: []map[string]any{
+				{"foo": "defaults"},
+				nil,
+			},
+			expectedConfig: map[string]any{"foo": "defaults"},
+		},
+		{
+			name: "case sensitive",
+			layers: []map[string]any{
+				{"foo": "defaults"},
+				{"FOO": "env"},
+			},
+			expectedConfig: map[string]any{"foo": "defaults", "FOO": "env"},
+		},
+		{
+			name: "ignore case, winning layer's spelling is kept",
+			layers: []map[string]any{
+				{"foo": "defaults", "bar": "defaults"},
+				{"FOO": "env"},
+			},
+			ignoreCase:     true,
+			expectedConfig: map[string]any{"FOO": "env", "bar": "defaults"},
+		},
+		{
+			name: "ignore case, last sorting spelling wins inside a layer",
+			layers: []map[string]any{
+				{"FOO": "upper", "foo": "lower", "Foo": "title"},
+			},
+			ignoreCase:     true,
+			expectedConfig: map[string]any{"foo": "lower"},
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			layers := make([]xconf.PrecedenceLayer, len(test.layers))
+			for idx, configMap := range test.layers {
+				layers[idx] = xconf.PrecedenceLayer{
+					Label:  string(rune('a' + idx)),
+					Loader: xconf.PlainLoader(configMap),
+				}
+			}
+			var opts []xconf.PrecedenceOption
+			if test.ignoreCase {
+				opts = append(opts, xconf.PrecedenceWithIgnoreCase())
+			}
+			subject := xconf.NewPrecedence(layers, opts...)
+
+			// act
+			config, err := subject.Load()
+
+			// assert
+			assertNil(t, err)
+			assertEqual(t, test.expectedConfig, config)
+		})
+	}
+}
+
+func testPrecedenceIsDeterministic(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	slowLoader := func(configMap map[string]any, delay time.Duration) xconf.Loader {
+		return xconf.LoaderFunc(func() (map[string]any, error) {
+			time.Sleep(delay)
+
+			return xconf.DeepCopyConfigMap(configMap), nil
+		})
+	}
+	subject := xconf.NewPrecedence(
+		[]xconf.PrecedenceLayer{
+			{Label: "defaults", Loader: slowLoader(map[string]any{"key": "defaults", "Key": "defaults"}, 5*time.Millisecond)},
+			{Label: "file", Loader: slowLoader(map[string]any{"KEY": "file", "kEY": "file"}, 0)},
+		},
+		xconf.PrecedenceWithIgnoreCase(),
+	)
+
+	for i := 0; i < 50; i++ {
+		// act
+		config, err := subject.Load()
+
+		// assert
+		requireNil(t, err)
+		assertEqual(t, map[string]any{"kEY": "file"}, config)
+	}
+}
+
+func testPrecedenceKeySource(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xconf.NewPrecedence(
+		[]xconf.PrecedenceLayer{
+			{Label: "defaults", Loader: xconf.PlainLoader(map[string]any{"foo": "1", "bar": "2"})},
+			{Label: "env", Loader: xconf.PlainLoader(map[string]any{"FOO": "3"})},
+		},
+		xconf.PrecedenceWithIgnoreCase(),
+	)
+
+	// act
+	_, found := subject.KeySource("foo")
+
+	// assert
+	assertTrue(t, !found)
+	assertEqual(t, []string{"defaults", "env"}, subject.Labels())
+
+	// arrange
+	cfg, err := xconf.NewDefaultConfig(subject, xconf.DefaultConfigWithIgnoreCaseSensitivity())
+	requireNil(t, err)
+
+	// act
+	fooSource, fooFound := subject.KeySource("foo")
+	barSource, barFound := subject.KeySource("BAR")
+
+	// assert
+	assertEqual(t, "3", cfg.Get("foo"))
+	assertTrue(t, fooFound)
+	assertEqual(t, "env", fooSource)
+	assertTrue(t, barFound)
+	assertEqual(t, "defaults", barSource)
+}
+
+func testPrecedenceReturnsLayerErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered layer error")
+		subject     = xconf.NewPrecedence([]xconf.PrecedenceLayer{
+			{Label: "defaults", Loader: xconf.PlainLoader(map[string]any{"foo": "bar"})},
+			{Label: "remote", Loader: xconf.LoaderFunc(func() (map[string]any, error) {
+				return nil, expectedErr
+			})},
+		})
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertEqual(t, `layer "remote": intentionally triggered layer error`, err.Error())
+	assertNil(t, config)
+}
+
+func testPrecedenceReturnsErrInvalidPrecedence(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name   string
+		layers []xconf.PrecedenceLayer
+	}{
+		{name: "no layers", layers: nil},
+		{name: "empty label", layers: []xconf.PrecedenceLayer{{Label: "", Loader: xconf.PlainLoader(nil)}}},
+		{
+			name: "duplicate label",
+			layers: []xconf.PrecedenceLayer{
+				{Label: "env", Loader: xconf.PlainLoader(nil)},
+				{Label: "env", Loader: xconf.PlainLoader(nil)},
+			},
+		},
+		{name: "nil loader", layers: []xconf.PrecedenceLayer{{Label: "env"}}},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := xconf.NewPrecedence(test.layers)
+
+			// act
+			config, err := subject.Load()
+
+			// assert
+			assertTrue(t, errors.Is(err, xconf.ErrInvalidPrecedence))
+			assertNil(t, config)
+		})
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+// Package redisconf reads commonly used Redis client settings from configuration keys under a prefix.
+// It does not depend on a Redis client library, so it does not build a client library's options /
+// config itself; the returned [Settings] are to be copied 1:1 onto
+// go-redis's (github.com/redis/go-redis) UniversalOptions:
+//
+//	settings, err := redisconf.FromConfig(cfg, "redis.")
+//	if err != nil {
+//		// handle error
+//	}
+//	creds, err := redisconf.NewCredentialsProvider(cfg, "redis.")
+//	if err != nil {
+//		// handle error
+//	}
+//	cfg.RegisterObserver(creds.OnConfigChange) // pick up rotated credentials.
+//	client := redis.NewUniversalClient(&redis.UniversalOptions{
+//		Addrs:               settings.Addrs,
+//		MasterName:          settings.MasterName,
+//		DB:                  settings.DB,
+//		CredentialsProvider: creds.Credentials,
+//		DialTimeout:         settings.DialTimeout,
+//		ReadTimeout:         settings.ReadTimeout,
+//		WriteTimeout:        settings.WriteTimeout,
+//		PoolSize:            settings.PoolSize,
+//		MinIdleConns:        settings.MinIdleConns,
+//		MaxRetries:          settings.MaxRetries,
+//		TLSConfig:           settings.TLSConfig(),
+//	})
+package redisconf
+
+import (
+	"crypto/tls"
+	"time"
+
+	"github.com/actforgood/xconf"
+	"github.com/spf13/cast"
+)
+
+// Keys, relative to the prefix.
+const (
+	// AddrsKey is the key for addresses (a list, or a comma separated string of "host:port").
+	AddrsKey = "addrs"
+	// UsernameKey is the key for username (can be a secret reference, see [xconf.ResolveSecretRef]).
+	UsernameKey = "username"
+	// PasswordKey is the key for password (can be a secret reference, see [xconf.ResolveSecretRef]).
+	PasswordKey = "password"
+	// DBKey is the key for database index.
+	DBKey = "db"
+	// MasterNameKey is the key for Sentinel master name.
+	MasterNameKey = "master_name"
+	// DialTimeoutKey is the key for dial timeout.
+	DialTimeoutKey = "dial_timeout"
+	// ReadTimeoutKey is the key for read timeout.
+	ReadTimeoutKey = "read_timeout"
+	// WriteTimeoutKey is the key for write timeout.
+	WriteTimeoutKey = "write_timeout"
+	// PoolSizeKey is the key for connection pool size.
+	PoolSizeKey = "pool_size"
+	// MinIdleConnsKey is the key for minimum idle connections.
+	MinIdleConnsKey = "min_idle_conns"
+	// MaxRetriesKey is the key for commands' max retries.
+	MaxRetriesKey = "max_retries"
+	// TLSKey is the key for enabling TLS.
+	TLSKey = "tls"
+)
+
+// Settings holds commonly used Redis client settings. It is not a client library's
+// options struct, its fields are meant to be copied onto one (see package's doc).
+// Zero values mean client library's defaults.
+type Settings struct {
+	// Addrs are the addresses: a single one for a standalone server,
+	// multiple ones for a cluster / Sentinel.
+	Addrs []string
+	// Username is the (resolved) username.
+	Username string
+	// Password is the (resolved) password.
+	Password string
+	// DB is the database index.
+	DB int
+	// MasterName is the Sentinel master name.
+	MasterName string
+	// DialTimeout is the dial timeout.
+	DialTimeout time.Duration
+	// ReadTimeout is the read timeout.
+	ReadTimeout time.Duration
+	// WriteTimeout is the write timeout.
+	WriteTimeout time.Duration
+	// PoolSize is the connection pool size.
+	PoolSize int
+	// MinIdleConns is the minimum number of idle connections.
+	MinIdleConns int
+	// MaxRetries is the commands' max retries.
+	MaxRetries int
+	// TLS indicates whether TLS is enabled.
+	TLS bool
+}
+
+// TLSConfig returns a TLS config if TLS is enabled, nil otherwise.
+func (settings Settings) TLSConfig() *tls.Config {
+	if !settings.TLS {
+		return nil
+	}
+
+	return &tls.Config{MinVersion: tls.VersionTLS12}
+}
+
+// FromConfig reads Redis client settings from given config's keys under given prefix.
+// Username and password are resolved with given resolver, if any, or with [xconf.ResolveSecretRef].
+func FromConfig(config xconf.Config, prefix string, resolver ...xconf.SecretResolver) (Settings, error) {
+	resolve := xconf.ResolveSecretRef
+	if len(resolver) > 0 {
+		resolve = resolver[0]
+	}
+	username, err := resolve(cast.ToString(config.Get(prefix + UsernameKey)))
+	if err != nil {
+		return Settings{}, err
+	}
+	password, err := resolve(cast.ToString(config.Get(prefix + PasswordKey)))
+	if err != nil {
+		return Settings{}, err
+	}
+
+	return Settings{
+		Addrs:        xconf.TrimmedStringList(config.Get(prefix + AddrsKey)),
+		Username:     username,
+		Password:     password,
+		DB:           cast.ToInt(config.Get(prefix + DBKey)),
+		MasterName:   cast.ToString(config.Get(prefix + MasterNameKey)),
+		DialTimeout:  cast.ToDuration(config.Get(prefix + DialTimeoutKey)),
+		ReadTimeout:  cast.ToDuration(config.Get(prefix + ReadTimeoutKey)),
+		WriteTimeout: cast.ToDuration(config.Get(prefix + WriteTimeoutKey)),
+		PoolSize:     cast.ToInt(config.Get(prefix + PoolSizeKey)),
+		MinIdleConns: cast.ToInt(config.Get(prefix + MinIdleConnsKey)),
+		MaxRetries:   cast.ToInt(config.Get(prefix + MaxRetriesKey)),
+		TLS:          cast.ToBool(config.Get(prefix + TLSKey)),
+	}, nil
+}
+
+// NewCredentialsProvider returns a credentials provider for the username / password
+// keys under given prefix, see [xconf.CredentialsProvider].
+func NewCredentialsProvider(
+	config xconf.Config,
+	prefix string,
+	opts ...xconf.CredentialsProviderOption,
+) (*xconf.CredentialsProvider, error) {
+	return xconf.NewCredentialsProvider(config, prefix+UsernameKey, prefix+PasswordKey, opts...)
+}
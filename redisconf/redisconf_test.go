@@ -0,0 +1,89 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package redisconf_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+	"github.com/actforgood/xconf/redisconf"
+)
+
+func TestFromConfig(t *testing.T) {
+	// Note: do not run this test with t.Parallel() as it sets ENVs.
+	t.Setenv("XCONF_REDIS_PASSWORD", "s3cr3t")
+
+	t.Run("success", testFromConfigSuccess)
+	t.Run("error - secret resolver", testFromConfigReturnsResolverErr)
+}
+
+func testFromConfigSuccess(t *testing.T) {
+	// arrange
+	config := xconf.NewMockConfig(
+		"redis.addrs", "10.0.0.1:6379, 10.0.0.2:6379",
+		"redis.username", "app",
+		"redis.password", "env:XCONF_REDIS_PASSWORD",
+		"redis.db", "2",
+		"redis.dial_timeout", "3s",
+		"redis.pool_size", 20,
+		"redis.tls", true,
+	)
+
+	// act
+	opts, err := redisconf.FromConfig(config, "redis.")
+
+	// assert
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	expectedOpts := redisconf.Settings{
+		Addrs:       []string{"10.0.0.1:6379", "10.0.0.2:6379"},
+		Username:    "app",
+		Password:    "s3cr3t",
+		DB:          2,
+		DialTimeout: 3 * time.Second,
+		PoolSize:    20,
+		TLS:         true,
+	}
+	if !reflect.DeepEqual(expectedOpts, opts) {
+		t.Errorf("expected %+v, but got %+v", expectedOpts, opts)
+	}
+	if opts.TLSConfig() == nil {
+		t.Error("expected TLS config")
+	}
+
+	// act
+	creds, err := redisconf.NewCredentialsProvider(config, "redis.")
+
+	// assert
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if username, password := creds.Credentials(); username != "app" || password != "s3cr3t" {
+		t.Errorf("unexpected credentials %q / %q", username, password)
+	}
+}
+
+func testFromConfigReturnsResolverErr(t *testing.T) {
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered resolver error")
+		resolver    = func(string) (string, error) {
+			return "", expectedErr
+		}
+	)
+
+	// act
+	_, err := redisconf.FromConfig(xconf.NewMockConfig(), "redis.", resolver)
+
+	// assert
+	if !errors.Is(err, expectedErr) {
+		t.Errorf("expected error %v, but got %v", expectedErr, err)
+	}
+}
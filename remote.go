@@ -7,8 +7,10 @@ package xconf
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 
+	"gopkg.in/ini.v1"
 	"gopkg.in/yaml.v3"
 )
 
@@ -19,8 +21,27 @@ const (
 	RemoteValueYAML = "yaml"
 	// RemoteValuePlain indicates that content under a key is plain text.
 	RemoteValuePlain = "plain"
+	// RemoteValueTOML indicates that content under a key is in TOML format.
+	RemoteValueTOML = "toml"
+	// RemoteValueIni indicates that content under a key is in INI format.
+	RemoteValueIni = "ini"
+	// RemoteValueProperties indicates that content under a key is in java style properties format.
+	RemoteValueProperties = "properties"
+	// RemoteValueDotEnv indicates that content under a key is in .env format.
+	RemoteValueDotEnv = "dotenv"
 )
 
+// isValidRemoteValueFormat checks whether given format is one of RemoteValue* constants.
+func isValidRemoteValueFormat(valueFormat string) bool {
+	switch valueFormat {
+	case RemoteValueJSON, RemoteValueYAML, RemoteValuePlain,
+		RemoteValueTOML, RemoteValueIni, RemoteValueProperties, RemoteValueDotEnv:
+		return true
+	}
+
+	return false
+}
+
 // getRemoteKVPairConfigMap returns configuration map for a key, according to format.
 func getRemoteKVPairConfigMap(key string, value []byte, format string) (map[string]any, error) {
 	var (
@@ -36,6 +57,14 @@ func getRemoteKVPairConfigMap(key string, value []byte, format string) (map[stri
 		if err = yaml.Unmarshal(value, &configMap); err != nil {
 			return nil, err
 		}
+	case RemoteValueTOML:
+		return TOMLReaderLoader(bytes.NewReader(value)).Load()
+	case RemoteValueIni:
+		return loadIniConfigMap(ini.LoadOptions{}, value)
+	case RemoteValueProperties:
+		return PropertiesBytesLoader(value).Load()
+	case RemoteValueDotEnv:
+		return DotEnvReaderLoader(bytes.NewReader(value)).Load()
 	default: // plain
 		configMap = map[string]any{
 			key: string(bytes.TrimSpace(value)),
@@ -44,3 +73,22 @@ func getRemoteKVPairConfigMap(key string, value []byte, format string) (map[stri
 
 	return configMap, nil
 }
+
+// mergeContexts returns a copy of ctx which is also canceled when loaderCtx
+// (the context a remote loader was configured with) is done.
+// The returned cancel function should be called to release resources.
+func mergeContexts(ctx, loaderCtx context.Context) (context.Context, context.CancelFunc) {
+	if loaderCtx == nil || loaderCtx == ctx || loaderCtx.Done() == nil {
+		return ctx, func() {}
+	}
+
+	mergedCtx, cancelCtx := context.WithCancelCause(ctx)
+	stop := context.AfterFunc(loaderCtx, func() {
+		cancelCtx(context.Cause(loaderCtx))
+	})
+
+	return mergedCtx, func() {
+		stop()
+		cancelCtx(context.Canceled)
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+const (
+	// RuntimeGOMAXPROCSKey is the default key for [runtime.GOMAXPROCS].
+	RuntimeGOMAXPROCSKey = "runtime.gomaxprocs"
+	// RuntimeGOGCKey is the default key for [debug.SetGCPercent].
+	RuntimeGOGCKey = "runtime.gogc"
+	// RuntimeGOMEMLIMITKey is the default key for [debug.SetMemoryLimit].
+	RuntimeGOMEMLIMITKey = "runtime.gomemlimit"
+)
+
+// RuntimeTuner applies runtime tuning settings read from a [Config]:
+// GOMAXPROCS, GC percent (GOGC) and soft memory limit (GOMEMLIMIT).
+// Register its OnConfigChange method as an observer on a [DefaultConfig]
+// with reload enabled, and settings can be adjusted without restarting the app.
+//
+// Missing keys leave the corresponding setting untouched.
+// Invalid values are not applied and are reported to the error handler, if set.
+type RuntimeTuner struct {
+	// gomaxprocsKey is the key for GOMAXPROCS.
+	gomaxprocsKey string
+	// gogcKey is the key for GC percent.
+	gogcKey string
+	// gomemlimitKey is the key for memory limit.
+	gomemlimitKey string
+	// errHandler is an optional handler for invalid values.
+	errHandler func(error)
+	// infoHandler is an optional handler for applied settings.
+	infoHandler func(setting string, oldValue, newValue int64)
+}
+
+// NewRuntimeTuner instantiates a new RuntimeTuner object.
+// By default, Runtime*Key constants are used as keys.
+func NewRuntimeTuner(opts ...RuntimeTunerOption) *RuntimeTuner {
+	tuner := &RuntimeTuner{
+		gomaxprocsKey: RuntimeGOMAXPROCSKey,
+		gogcKey:       RuntimeGOGCKey,
+		gomemlimitKey: RuntimeGOMEMLIMITKey,
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(tuner)
+	}
+
+	return tuner
+}
+
+// Apply applies all settings from given config.
+// Call it at your application's startup.
+func (tuner *RuntimeTuner) Apply(config Config) {
+	tuner.applyGOMAXPROCS(config)
+	tuner.applyGOGC(config)
+	tuner.applyGOMEMLIMIT(config)
+}
+
+// OnConfigChange is a [ConfigObserver] that applies settings whose keys changed.
+func (tuner *RuntimeTuner) OnConfigChange(config Config, changedKeys ...string) {
+	for _, changedKey := range changedKeys {
+		switch {
+		case strings.EqualFold(changedKey, tuner.gomaxprocsKey):
+			tuner.applyGOMAXPROCS(config)
+		case strings.EqualFold(changedKey, tuner.gogcKey):
+			tuner.applyGOGC(config)
+		case strings.EqualFold(changedKey, tuner.gomemlimitKey):
+			tuner.applyGOMEMLIMIT(config)
+		}
+	}
+}
+
+// applyGOMAXPROCS sets GOMAXPROCS.
+func (tuner *RuntimeTuner) applyGOMAXPROCS(config Config) {
+	value := config.Get(tuner.gomaxprocsKey)
+	if value == nil {
+		return
+	}
+	procs, err := cast.ToIntE(value)
+	if err != nil || procs < 1 {
+		tuner.handleErr(fmt.Errorf("invalid GOMAXPROCS value %v, must be a positive integer", value))
+
+		return
+	}
+	oldProcs := runtime.GOMAXPROCS(procs)
+	tuner.handleInfo("GOMAXPROCS", int64(oldProcs), int64(procs))
+}
+
+// applyGOGC sets GC percent.
+// Value "off" disables GC, as GOGC env does.
+func (tuner *RuntimeTuner) applyGOGC(config Config) {
+	value := config.Get(tuner.gogcKey)
+	if value == nil {
+		return
+	}
+	var (
+		percent int
+		err     error
+	)
+	if strValue, ok := value.(string); ok && strings.EqualFold(strings.TrimSpace(strValue), "off") {
+		percent = -1
+	} else {
+		percent, err = cast.ToIntE(value)
+	}
+	if err != nil || percent < -1 {
+		tuner.handleErr(fmt.Errorf("invalid GOGC value %v, must be an integer >= -1 or \"off\"", value))
+
+		return
+	}
+	oldPercent := debug.SetGCPercent(percent)
+	tuner.handleInfo("GOGC", int64(oldPercent), int64(percent))
+}
+
+// applyGOMEMLIMIT sets soft memory limit.
+// Value can be a number of bytes, or a string with a unit suffix, as GOMEMLIMIT env accepts
+// (B, KiB, MiB, GiB, TiB), or "off".
+func (tuner *RuntimeTuner) applyGOMEMLIMIT(config Config) {
+	value := config.Get(tuner.gomemlimitKey)
+	if value == nil {
+		return
+	}
+	limit, err := parseMemoryLimit(value)
+	if err != nil {
+		tuner.handleErr(fmt.Errorf("invalid GOMEMLIMIT value %v: %w", value, err))
+
+		return
+	}
+	oldLimit := debug.SetMemoryLimit(limit)
+	tuner.handleInfo("GOMEMLIMIT", oldLimit, limit)
+}
+
+// handleErr calls the error handler, if set.
+func (tuner *RuntimeTuner) handleErr(err error) {
+	if tuner.errHandler != nil {
+		tuner.errHandler(err)
+	}
+}
+
+// handleInfo calls the info handler, if set, and if setting's value changed.
+func (tuner *RuntimeTuner) handleInfo(setting string, oldValue, newValue int64) {
+	if tuner.infoHandler != nil && oldValue != newValue {
+		tuner.infoHandler(setting, oldValue, newValue)
+	}
+}
+
+// memoryLimitUnits holds GOMEMLIMIT's accepted units.
+var memoryLimitUnits = [...]struct {
+	suffix     string
+	multiplier int64
+}{
+	{"TiB", 1 << 40},
+	{"GiB", 1 << 30},
+	{"MiB", 1 << 20},
+	{"KiB", 1 << 10},
+	{"B", 1},
+}
+
+var (
+	// errNegativeMemoryLimit is returned by parseMemoryLimit for negative values.
+	errNegativeMemoryLimit = errors.New("must be >= 0")
+	// errMemoryLimitOverflow is returned by parseMemoryLimit for values exceeding int64's range, in bytes.
+	errMemoryLimitOverflow = errors.New("must not exceed 8EiB")
+)
+
+// parseMemoryLimit parses a memory limit value.
+func parseMemoryLimit(value any) (int64, error) {
+	strValue, ok := value.(string)
+	if !ok {
+		limit, err := cast.ToInt64E(value)
+		if err == nil && limit < 0 {
+			err = errNegativeMemoryLimit
+		}
+
+		return limit, err
+	}
+
+	strValue = strings.TrimSpace(strValue)
+	if strings.EqualFold(strValue, "off") {
+		return math.MaxInt64, nil // as runtime's default.
+	}
+	multiplier := int64(1)
+	for _, unit := range memoryLimitUnits {
+		if strings.HasSuffix(strValue, unit.suffix) {
+			strValue = strings.TrimSpace(strings.TrimSuffix(strValue, unit.suffix))
+			multiplier = unit.multiplier
+
+			break
+		}
+	}
+	limit, err := strconv.ParseInt(strValue, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if limit < 0 {
+		return 0, errNegativeMemoryLimit
+	}
+	if limit > math.MaxInt64/multiplier {
+		return 0, errMemoryLimitOverflow
+	}
+
+	return limit * multiplier, nil
+}
+
+// RuntimeTunerOption defines optional function for configuring
+// a RuntimeTuner object.
+type RuntimeTunerOption func(*RuntimeTuner)
+
+// RuntimeTunerWithKeys sets custom keys for settings.
+// An empty key preserves the default one.
+func RuntimeTunerWithKeys(gomaxprocsKey, gogcKey, gomemlimitKey string) RuntimeTunerOption {
+	return func(tuner *RuntimeTuner) {
+		if gomaxprocsKey != "" {
+			tuner.gomaxprocsKey = gomaxprocsKey
+		}
+		if gogcKey != "" {
+			tuner.gogcKey = gogcKey
+		}
+		if gomemlimitKey != "" {
+			tuner.gomemlimitKey = gomemlimitKey
+		}
+	}
+}
+
+// RuntimeTunerWithErrorHandler sets the handler for invalid settings' values.
+// You can choose to log the error, for example with [LogErrorHandler].
+//
+// By default, error is simply ignored.
+func RuntimeTunerWithErrorHandler(errHandler func(error)) RuntimeTunerOption {
+	return func(tuner *RuntimeTuner) {
+		tuner.errHandler = errHandler
+	}
+}
+
+// RuntimeTunerWithInfoHandler sets the handler called when a setting's value changes.
+// You can choose to log the change, for example.
+func RuntimeTunerWithInfoHandler(infoHandler func(setting string, oldValue, newValue int64)) RuntimeTunerOption {
+	return func(tuner *RuntimeTuner) {
+		tuner.infoHandler = infoHandler
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"math"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestRuntimeTuner(t *testing.T) {
+	// Note: do not run this test with t.Parallel() as it modifies runtime settings.
+
+	t.Run("apply", testRuntimeTunerApply)
+	t.Run("observer", testRuntimeTunerOnConfigChange)
+	t.Run("invalid values", testRuntimeTunerInvalidValues)
+	t.Run("memory limit overflow", testRuntimeTunerMemoryLimitOverflow)
+	t.Run("missing keys", testRuntimeTunerMissingKeys)
+}
+
+// restoreRuntimeSettings captures current runtime settings and returns
+// a function to restore them.
+func restoreRuntimeSettings() func() {
+	procs := runtime.GOMAXPROCS(0)
+	gcPercent := debug.SetGCPercent(100)
+	debug.SetGCPercent(gcPercent)
+	memLimit := debug.SetMemoryLimit(-1)
+
+	return func() {
+		runtime.GOMAXPROCS(procs)
+		debug.SetGCPercent(gcPercent)
+		debug.SetMemoryLimit(memLimit)
+	}
+}
+
+func testRuntimeTunerApply(t *testing.T) {
+	// arrange
+	defer restoreRuntimeSettings()()
+	var changes []string
+	config := xconf.NewMockConfig(
+		"runtime.gomaxprocs", "2",
+		"runtime.gogc", 150,
+		"runtime.gomemlimit", "512MiB",
+	)
+	subject := xconf.NewRuntimeTuner(
+		xconf.RuntimeTunerWithInfoHandler(func(setting string, _, _ int64) {
+			changes = append(changes, setting)
+		}),
+	)
+
+	// act
+	subject.Apply(config)
+
+	// assert
+	assertEqual(t, 2, runtime.GOMAXPROCS(0))
+	assertEqual(t, 150, debug.SetGCPercent(150))
+	assertEqual(t, int64(512<<20), debug.SetMemoryLimit(-1))
+	assertTrue(t, len(changes) > 0)
+}
+
+func testRuntimeTunerOnConfigChange(t *testing.T) {
+	// arrange
+	defer restoreRuntimeSettings()()
+	config := xconf.NewMockConfig(
+		"GOGC", "off",
+		"GOMEMLIMIT", 1<<30,
+	)
+	subject := xconf.NewRuntimeTuner(xconf.RuntimeTunerWithKeys("", "GOGC", "GOMEMLIMIT"))
+
+	// act
+	subject.OnConfigChange(config, "gogc", "gomemlimit")
+
+	// assert
+	assertEqual(t, -1, debug.SetGCPercent(-1))
+	assertEqual(t, int64(1<<30), debug.SetMemoryLimit(-1))
+
+	// arrange
+	config.SetKeyValues("GOMEMLIMIT", "off")
+
+	// act
+	subject.OnConfigChange(config, "GOMEMLIMIT")
+
+	// assert
+	assertEqual(t, int64(math.MaxInt64), debug.SetMemoryLimit(-1))
+}
+
+func testRuntimeTunerInvalidValues(t *testing.T) {
+	// arrange
+	defer restoreRuntimeSettings()()
+	procs := runtime.GOMAXPROCS(0)
+	memLimit := debug.SetMemoryLimit(-1)
+	var errs []error
+	config := xconf.NewMockConfig(
+		"runtime.gomaxprocs", 0,
+		"runtime.gogc", "-5",
+		"runtime.gomemlimit", "12XB",
+	)
+	subject := xconf.NewRuntimeTuner(
+		xconf.RuntimeTunerWithErrorHandler(func(err error) {
+			errs = append(errs, err)
+		}),
+	)
+
+	// act
+	subject.Apply(config)
+
+	// assert
+	if assertEqual(t, 3, len(errs)) {
+		assertTrue(t, strings.Contains(errs[0].Error(), "GOMAXPROCS"))
+		assertTrue(t, strings.Contains(errs[1].Error(), "GOGC"))
+		assertTrue(t, strings.Contains(errs[2].Error(), "GOMEMLIMIT"))
+	}
+	assertEqual(t, procs, runtime.GOMAXPROCS(0))
+	assertEqual(t, memLimit, debug.SetMemoryLimit(-1))
+}
+
+func testRuntimeTunerMemoryLimitOverflow(t *testing.T) {
+	// arrange
+	defer restoreRuntimeSettings()()
+	memLimit := debug.SetMemoryLimit(-1)
+	var errs []error
+	config := xconf.NewMockConfig("runtime.gomemlimit", "20000000000GiB")
+	subject := xconf.NewRuntimeTuner(
+		xconf.RuntimeTunerWithErrorHandler(func(err error) {
+			errs = append(errs, err)
+		}),
+	)
+
+	// act
+	subject.Apply(config)
+
+	// assert
+	if assertEqual(t, 1, len(errs)) {
+		assertTrue(t, strings.Contains(errs[0].Error(), "GOMEMLIMIT"))
+		assertTrue(t, strings.Contains(errs[0].Error(), "must not exceed"))
+	}
+	assertEqual(t, memLimit, debug.SetMemoryLimit(-1))
+}
+
+func testRuntimeTunerMissingKeys(t *testing.T) {
+	// arrange
+	defer restoreRuntimeSettings()()
+	procs := runtime.GOMAXPROCS(0)
+	subject := xconf.NewRuntimeTuner(
+		xconf.RuntimeTunerWithErrorHandler(func(err error) {
+			t.Error("unexpected error", err)
+		}),
+	)
+
+	// act
+	subject.Apply(xconf.NewMockConfig())
+
+	// assert
+	assertEqual(t, procs, runtime.GOMAXPROCS(0))
+}
@@ -0,0 +1,56 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/actforgood/xerr"
+)
+
+// ErrSecretNotFound is an error returned by [ResolveSecretRef]
+// if the referenced env variable is not set.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// secret references' schemes.
+const (
+	secretRefEnvScheme  = "env:"
+	secretRefFileScheme = "file:"
+)
+
+// SecretResolver resolves a configured secret value, which may be a reference
+// to the secret stored elsewhere, into the secret itself.
+type SecretResolver func(value string) (string, error)
+
+// ResolveSecretRef is the default [SecretResolver]. It resolves:
+//   - "env:NAME" references, to the value of the NAME env variable;
+//   - "file:/path/to/secret" references, to the trimmed content of the file
+//     (like the ones mounted by Docker / Kubernetes secrets);
+//   - any other value, to itself (the secret is configured in clear).
+func ResolveSecretRef(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, secretRefEnvScheme):
+		name := strings.TrimPrefix(value, secretRefEnvScheme)
+		secret, found := os.LookupEnv(name)
+		if !found {
+			return "", xerr.Wrapf(ErrSecretNotFound, "env %q", name)
+		}
+
+		return secret, nil
+	case strings.HasPrefix(value, secretRefFileScheme):
+		content, err := os.ReadFile(strings.TrimPrefix(value, secretRefFileScheme))
+		if err != nil {
+			return "", err
+		}
+
+		return string(bytes.TrimSpace(content)), nil
+	default:
+		return value, nil
+	}
+}
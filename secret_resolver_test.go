@@ -0,0 +1,71 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestResolveSecretRef(t *testing.T) {
+	// Note: do not run this test with t.Parallel() as it sets ENVs.
+	t.Setenv("XCONF_RESOLVE_SECRET_REF", "env secret")
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	requireNil(t, os.WriteFile(secretFile, []byte(" file secret\n"), 0o600))
+
+	tests := [...]struct {
+		name           string
+		value          string
+		expectedSecret string
+		expectedErr    error
+	}{
+		{
+			name:           "env reference",
+			value:          "env:XCONF_RESOLVE_SECRET_REF",
+			expectedSecret: "env secret",
+		},
+		{
+			name:           "file reference",
+			value:          "file:" + secretFile,
+			expectedSecret: "file secret",
+		},
+		{
+			name:           "clear value",
+			value:          "clear secret",
+			expectedSecret: "clear secret",
+		},
+		{
+			name:        "not set env",
+			value:       "env:XCONF_RESOLVE_SECRET_REF_NOT_SET",
+			expectedErr: xconf.ErrSecretNotFound,
+		},
+		{
+			name:        "not existing file",
+			value:       "file:testdata/this-file-does-not-exist",
+			expectedErr: os.ErrNotExist,
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			// act
+			secret, err := xconf.ResolveSecretRef(test.value)
+
+			// assert
+			assertEqual(t, test.expectedSecret, secret)
+			if test.expectedErr != nil {
+				assertTrue(t, errors.Is(err, test.expectedErr))
+			} else {
+				assertNil(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import "log/slog"
+
+// SlogErrorHandler is a handler which can be used in a xconf.DefaultConfig
+// object as a reload error handler. It logs the error with a [slog.Logger].
+// Passed parameter is a function that returns the logger (like [slog.Default]),
+// this way logger can be instantiated / replaced after Config.
+func SlogErrorHandler(loggerGetter func() *slog.Logger) func(error) {
+	return func(err error) {
+		loggerGetter().Error(
+			"[xconf] could not reload configuration",
+			slog.Any("error", err),
+		)
+	}
+}
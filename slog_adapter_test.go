@@ -0,0 +1,38 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestSlogErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf          bytes.Buffer
+		logger       = slog.New(slog.NewTextHandler(&buf, nil))
+		loggerGetter = func() *slog.Logger { return logger }
+		subject      = xconf.SlogErrorHandler(loggerGetter)
+		err          = errors.New("reload test error")
+	)
+
+	// act
+	subject(err)
+
+	// assert
+	output := buf.String()
+	assertTrue(t, strings.Contains(output, "level=ERROR"))
+	assertTrue(t, strings.Contains(output, "could not reload configuration"))
+	assertTrue(t, strings.Contains(output, `error="reload test error"`))
+}
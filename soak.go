@@ -0,0 +1,101 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// soakDefaultCheckInterval is the check interval a [Soak] run uses, if a non-positive one is given.
+const soakDefaultCheckInterval = 100 * time.Millisecond
+
+// SoakReport is the result of a [Soak] run.
+type SoakReport struct {
+	// Duration is the soak run's duration.
+	Duration time.Duration
+	// Checks is the number of times the check function was called.
+	Checks int
+	// FailedChecks is the number of times the check function returned an error.
+	FailedChecks int
+	// FirstErr is the first error returned by the check function, if any.
+	FirstErr error
+	// Notifications is the number of times observers were notified about changed keys.
+	Notifications int
+}
+
+// Failed returns true if any check failed.
+func (report SoakReport) Failed() bool {
+	return report.FailedChecks > 0
+}
+
+// Soak runs a long-running soak test over given config, until context is done:
+// check function is called at given interval (if not positive, 100ms is used),
+// and also on each observers' notification (as your observers get notified, from the reload goroutine).
+// Typically, the config has reload enabled, and its loader is a [ChaosLoader],
+// and check verifies your invariants (like a required key is never missing,
+// or your component's state is consistent with the configuration).
+//
+// Example:
+//
+//	chaosLoader := xconf.NewChaosLoader(loader, xconf.ChaosLoaderWithErrorRate(0.3))
+//	config, _ := xconf.NewDefaultConfig(chaosLoader, xconf.DefaultConfigWithReloadInterval(10*time.Millisecond))
+//	defer config.Close()
+//	config.RegisterObserver(myComponent.OnConfigChange)
+//	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+//	defer cancel()
+//	report := xconf.Soak(ctx, config, 5*time.Millisecond, func(cfg xconf.Config) error {
+//		return myComponent.Check()
+//	})
+//	if report.Failed() {
+//		t.Fatal(report.FirstErr)
+//	}
+func Soak(ctx context.Context, config *DefaultConfig, checkInterval time.Duration, check func(Config) error) SoakReport {
+	var (
+		mu        sync.Mutex
+		report    SoakReport
+		startTime = time.Now()
+		runCheck  = func() {
+			err := check(config)
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks++
+			if err != nil {
+				report.FailedChecks++
+				if report.FirstErr == nil {
+					report.FirstErr = err
+				}
+			}
+		}
+	)
+
+	handle := config.RegisterObserver(func(cfg Config, _ ...string) {
+		mu.Lock()
+		report.Notifications++
+		mu.Unlock()
+		runCheck()
+	})
+	defer config.UnregisterObserver(handle)
+
+	if checkInterval <= 0 {
+		checkInterval = soakDefaultCheckInterval
+	}
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			mu.Lock()
+			defer mu.Unlock()
+			report.Duration = time.Since(startTime)
+
+			return report
+		case <-ticker.C:
+			runCheck()
+		}
+	}
+}
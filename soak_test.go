@@ -0,0 +1,116 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestSoak(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - invariants hold", testSoakInvariantsHold)
+	t.Run("success - non-positive check interval", testSoakWithNonPositiveCheckInterval)
+	t.Run("error - invariants broken", testSoakInvariantsBroken)
+}
+
+func testSoakInvariantsHold(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		version    uint64
+		baseLoader = xconf.LoaderFunc(func() (map[string]any, error) {
+			return map[string]any{"version": atomic.AddUint64(&version, 1), "foo": "bar"}, nil
+		})
+		chaosLoader = xconf.NewChaosLoader(
+			baseLoader,
+			xconf.ChaosLoaderWithErrorRate(0.5),
+			xconf.ChaosLoaderWithSeed(1497),
+		)
+	)
+	config, err := xconf.NewDefaultConfig(
+		xconf.NewMultiLoader(true, xconf.PlainLoader(map[string]any{"foo": "bar"}), chaosLoader),
+		xconf.DefaultConfigWithReloadInterval(time.Millisecond),
+	)
+	requireNil(t, err)
+	defer config.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// act
+	report := xconf.Soak(ctx, config, 2*time.Millisecond, func(cfg xconf.Config) error {
+		if cfg.Get("foo") != "bar" {
+			return errors.New("foo is missing")
+		}
+
+		return nil
+	})
+
+	// assert
+	assertTrue(t, !report.Failed())
+	assertNil(t, report.FirstErr)
+	assertTrue(t, report.Checks > 0)
+	assertTrue(t, report.Notifications > 0)
+	assertTrue(t, report.Duration >= 100*time.Millisecond)
+	assertTrue(t, chaosLoader.Stats().InjectedErrors > 0)
+}
+
+func testSoakWithNonPositiveCheckInterval(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config, err := xconf.NewDefaultConfig(xconf.PlainLoader(map[string]any{"foo": "bar"}))
+	requireNil(t, err)
+	defer config.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+
+	// act
+	report := xconf.Soak(ctx, config, 0, func(xconf.Config) error {
+		return nil
+	})
+
+	// assert: default interval (100ms) is used.
+	assertTrue(t, !report.Failed())
+	assertTrue(t, report.Checks >= 1 && report.Checks <= 3)
+}
+
+func testSoakInvariantsBroken(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	chaosLoader := xconf.NewChaosLoader(
+		xconf.PlainLoader(map[string]any{"foo": "bar", "baz": "qux"}),
+		xconf.ChaosLoaderWithPartialDataRate(0.5),
+		xconf.ChaosLoaderWithSeed(1497),
+	)
+	config, err := xconf.NewDefaultConfig(chaosLoader, xconf.DefaultConfigWithReloadInterval(time.Millisecond))
+	requireNil(t, err)
+	defer config.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// act
+	report := xconf.Soak(ctx, config, time.Millisecond, func(cfg xconf.Config) error {
+		if cfg.Get("foo") != "bar" || cfg.Get("baz") != "qux" {
+			return errors.New("missing key")
+		}
+
+		return nil
+	})
+
+	// assert
+	assertTrue(t, report.Failed())
+	assertNotNil(t, report.FirstErr)
+	assertTrue(t, report.FailedChecks <= report.Checks)
+}
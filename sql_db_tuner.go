@@ -0,0 +1,188 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// SQLDBTuner keys, relative to SQLDBTuner's keys prefix.
+const (
+	// SQLDBMaxOpenConnsKey is the key for [sql.DB.SetMaxOpenConns].
+	SQLDBMaxOpenConnsKey = "max_open_conns"
+	// SQLDBMaxIdleConnsKey is the key for [sql.DB.SetMaxIdleConns].
+	SQLDBMaxIdleConnsKey = "max_idle_conns"
+	// SQLDBConnMaxLifetimeKey is the key for [sql.DB.SetConnMaxLifetime].
+	SQLDBConnMaxLifetimeKey = "conn_max_lifetime"
+	// SQLDBConnMaxIdleTimeKey is the key for [sql.DB.SetConnMaxIdleTime].
+	SQLDBConnMaxIdleTimeKey = "conn_max_idle_time"
+)
+
+// DefaultSQLDBKeysPrefix is the default prefix for SQLDBTuner's keys.
+const DefaultSQLDBKeysPrefix = "db."
+
+// SQLDBTuner applies connection pool settings read from a [Config] on a [sql.DB]:
+// max open connections, max idle connections, connection max lifetime and max idle time.
+// Register its OnConfigChange method as an observer on a [DefaultConfig]
+// with reload enabled, and settings can be adjusted without restarting the app.
+//
+// Missing keys leave the corresponding setting untouched.
+// Invalid values are not applied and are reported to the error handler, if set.
+type SQLDBTuner struct {
+	// db is the tuned database handle.
+	db *sql.DB
+	// keysPrefix is the prefix of the keys.
+	keysPrefix string
+	// errHandler is an optional handler for invalid values.
+	errHandler func(error)
+}
+
+// NewSQLDBTuner instantiates a new SQLDBTuner object.
+// By default, [DefaultSQLDBKeysPrefix] is used as keys prefix.
+func NewSQLDBTuner(db *sql.DB, opts ...SQLDBTunerOption) *SQLDBTuner {
+	tuner := &SQLDBTuner{
+		db:         db,
+		keysPrefix: DefaultSQLDBKeysPrefix,
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(tuner)
+	}
+
+	return tuner
+}
+
+// sqlDBSetting describes a connection pool setting.
+type sqlDBSetting struct {
+	// key is the setting's key, relative to keys prefix.
+	key string
+	// description is the setting's description, used in errors.
+	description string
+	// isDuration tells whether value is a duration, otherwise it's an integer.
+	isDuration bool
+	// apply sets the value on the database handle.
+	apply func(db *sql.DB, value int64)
+}
+
+// sqlDBSettings are the connection pool settings, in the order they are applied.
+// Max open connections is applied before max idle connections, as the latter
+// is capped by the former.
+var sqlDBSettings = [...]sqlDBSetting{
+	{
+		// a value <= 0 means unlimited.
+		key:         SQLDBMaxOpenConnsKey,
+		description: "max open conns",
+		apply:       func(db *sql.DB, value int64) { db.SetMaxOpenConns(int(value)) },
+	},
+	{
+		// a value <= 0 means no idle connections are retained.
+		key:         SQLDBMaxIdleConnsKey,
+		description: "max idle conns",
+		apply:       func(db *sql.DB, value int64) { db.SetMaxIdleConns(int(value)) },
+	},
+	{
+		// a value <= 0 means connections are not closed due to their age.
+		key:         SQLDBConnMaxLifetimeKey,
+		description: "conn max lifetime",
+		isDuration:  true,
+		apply:       func(db *sql.DB, value int64) { db.SetConnMaxLifetime(time.Duration(value)) },
+	},
+	{
+		// a value <= 0 means connections are not closed due to their idle time.
+		key:         SQLDBConnMaxIdleTimeKey,
+		description: "conn max idle time",
+		isDuration:  true,
+		apply:       func(db *sql.DB, value int64) { db.SetConnMaxIdleTime(time.Duration(value)) },
+	},
+}
+
+// Apply applies all settings from given config.
+// Call it right after opening the database handle.
+//
+// Durations can be a duration string ("1h") or a number of nanoseconds.
+func (tuner *SQLDBTuner) Apply(config Config) {
+	for _, setting := range sqlDBSettings {
+		tuner.applySetting(config, setting)
+	}
+}
+
+// OnConfigChange is a [ConfigObserver] that re-applies all settings if any of their keys changed.
+// Settings are re-applied all together, in a fixed order, as they depend on each other
+// (max idle connections is capped by max open connections).
+func (tuner *SQLDBTuner) OnConfigChange(config Config, changedKeys ...string) {
+	for _, changedKey := range changedKeys {
+		for _, setting := range sqlDBSettings {
+			if strings.EqualFold(changedKey, tuner.keysPrefix+setting.key) {
+				tuner.Apply(config)
+
+				return
+			}
+		}
+	}
+}
+
+// applySetting reads given setting from config and sets it on the database handle.
+func (tuner *SQLDBTuner) applySetting(config Config, setting sqlDBSetting) {
+	value := config.Get(tuner.keysPrefix + setting.key)
+	if value == nil {
+		return
+	}
+
+	if setting.isDuration {
+		duration, err := cast.ToDurationE(value)
+		if err != nil {
+			tuner.handleErr(fmt.Errorf("invalid %s value %v, must be a duration", setting.description, value))
+
+			return
+		}
+		setting.apply(tuner.db, int64(duration))
+
+		return
+	}
+
+	number, err := cast.ToIntE(value)
+	if err != nil {
+		tuner.handleErr(fmt.Errorf("invalid %s value %v, must be an integer", setting.description, value))
+
+		return
+	}
+	setting.apply(tuner.db, int64(number))
+}
+
+// handleErr calls the error handler, if set.
+func (tuner *SQLDBTuner) handleErr(err error) {
+	if tuner.errHandler != nil {
+		tuner.errHandler(err)
+	}
+}
+
+// SQLDBTunerOption defines optional function for configuring
+// a SQLDBTuner object.
+type SQLDBTunerOption func(*SQLDBTuner)
+
+// SQLDBTunerWithKeysPrefix sets the prefix of the keys.
+// By default, [DefaultSQLDBKeysPrefix] is used.
+func SQLDBTunerWithKeysPrefix(keysPrefix string) SQLDBTunerOption {
+	return func(tuner *SQLDBTuner) {
+		tuner.keysPrefix = keysPrefix
+	}
+}
+
+// SQLDBTunerWithErrorHandler sets the handler for invalid settings' values.
+// You can choose to log the error, for example with [LogErrorHandler].
+//
+// By default, error is simply ignored.
+func SQLDBTunerWithErrorHandler(errHandler func(error)) SQLDBTunerOption {
+	return func(tuner *SQLDBTuner) {
+		tuner.errHandler = errHandler
+	}
+}
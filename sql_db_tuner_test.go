@@ -0,0 +1,191 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+// fakeSQLDriver is a no-op sql driver, used in tests.
+type fakeSQLDriver struct{}
+
+func (fakeSQLDriver) Open(string) (driver.Conn, error) {
+	return fakeSQLConn{}, nil
+}
+
+// fakeSQLConn is a no-op sql connection, used in tests.
+type fakeSQLConn struct{}
+
+func (fakeSQLConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (fakeSQLConn) Close() error {
+	return nil
+}
+
+func (fakeSQLConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not implemented")
+}
+
+func init() {
+	sql.Register("xconf_fake", fakeSQLDriver{})
+}
+
+func TestSQLDBTuner(t *testing.T) {
+	t.Parallel()
+
+	t.Run("apply", testSQLDBTunerApply)
+	t.Run("observer", testSQLDBTunerOnConfigChange)
+	t.Run("observer - settings order", testSQLDBTunerOnConfigChangeAppliesSettingsInOrder)
+	t.Run("invalid values", testSQLDBTunerInvalidValues)
+}
+
+// openFakeDB opens a database handle with a fake driver.
+func openFakeDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("xconf_fake", "")
+	requireNil(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
+
+// idleConnsAfterUsing opens and releases given number of connections,
+// returning the idle connections count.
+func idleConnsAfterUsing(t *testing.T, db *sql.DB, conns int) int {
+	t.Helper()
+
+	opened := make([]*sql.Conn, 0, conns)
+	for i := 0; i < conns; i++ {
+		conn, err := db.Conn(context.Background())
+		requireNil(t, err)
+		opened = append(opened, conn)
+	}
+	for _, conn := range opened {
+		_ = conn.Close()
+	}
+
+	return db.Stats().Idle
+}
+
+func testSQLDBTunerApply(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		db     = openFakeDB(t)
+		config = xconf.NewMockConfig(
+			"db.max_open_conns", "10",
+			"db.max_idle_conns", 1,
+			"db.conn_max_lifetime", "1h",
+			"db.conn_max_idle_time", "5m",
+		)
+		subject = xconf.NewSQLDBTuner(db, xconf.SQLDBTunerWithErrorHandler(func(err error) {
+			t.Error("unexpected error", err)
+		}))
+	)
+
+	// act
+	subject.Apply(config)
+
+	// assert
+	assertEqual(t, 10, db.Stats().MaxOpenConnections)
+	assertEqual(t, 1, idleConnsAfterUsing(t, db, 3))
+}
+
+func testSQLDBTunerOnConfigChange(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		db      = openFakeDB(t)
+		config  = xconf.NewMockConfig("mysql.max_open_conns", 5)
+		subject = xconf.NewSQLDBTuner(db, xconf.SQLDBTunerWithKeysPrefix("mysql."))
+	)
+	subject.Apply(config)
+	config.SetKeyValues("mysql.max_open_conns", 20)
+
+	// act
+	subject.OnConfigChange(config, "MYSQL.MAX_OPEN_CONNS")
+
+	// assert
+	assertEqual(t, 20, db.Stats().MaxOpenConnections)
+
+	// arrange
+	config.SetKeyValues("mysql.max_open_conns", 30)
+
+	// act
+	subject.OnConfigChange(config, "other_key")
+
+	// assert
+	assertEqual(t, 20, db.Stats().MaxOpenConnections)
+}
+
+func testSQLDBTunerOnConfigChangeAppliesSettingsInOrder(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		db     = openFakeDB(t)
+		config = xconf.NewMockConfig(
+			"db.max_open_conns", 1,
+			"db.max_idle_conns", 1,
+		)
+		subject = xconf.NewSQLDBTuner(db)
+	)
+	subject.Apply(config)
+	config.SetKeyValues(
+		"db.max_open_conns", 5,
+		"db.max_idle_conns", 5,
+	)
+
+	// act
+	subject.OnConfigChange(config, "db.max_idle_conns", "db.max_open_conns")
+
+	// assert
+	assertEqual(t, 5, db.Stats().MaxOpenConnections)
+	assertEqual(t, 5, idleConnsAfterUsing(t, db, 5))
+}
+
+func testSQLDBTunerInvalidValues(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		errs   []error
+		db     = openFakeDB(t)
+		config = xconf.NewMockConfig(
+			"db.max_open_conns", "ten",
+			"db.max_idle_conns", "one",
+			"db.conn_max_lifetime", "forever",
+			"db.conn_max_idle_time", "a while",
+		)
+		subject = xconf.NewSQLDBTuner(db, xconf.SQLDBTunerWithErrorHandler(func(err error) {
+			errs = append(errs, err)
+		}))
+	)
+
+	// act
+	subject.Apply(config)
+
+	// assert
+	if assertEqual(t, 4, len(errs)) {
+		assertTrue(t, strings.Contains(errs[0].Error(), "max open conns"))
+		assertTrue(t, strings.Contains(errs[1].Error(), "max idle conns"))
+		assertTrue(t, strings.Contains(errs[2].Error(), "conn max lifetime"))
+		assertTrue(t, strings.Contains(errs[3].Error(), "conn max idle time"))
+	}
+	assertEqual(t, 0, db.Stats().MaxOpenConnections)
+}
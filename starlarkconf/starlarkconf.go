@@ -0,0 +1,277 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+// Package starlarkconf provides a Starlark (go.starlark.net) based [xconf.ScriptEvaluator],
+// to be used with [xconf.ScriptLoader].
+// Starlark is deterministic and hermetic: a script has no access to file system, network,
+// or environment, other than the globals [xconf.ScriptLoader] explicitly exposes.
+// The script computes the configuration into the "config" global, which must be a dict:
+//
+//	loader := xconf.NewScriptLoader(
+//		starlarkconf.NewEvaluator(starlarkconf.WithMaxExecutionSteps(100_000)),
+//		`
+//	region = env.get("AWS_REGION", "eu-west-1")
+//	config = {
+//		"api.endpoint": "https://api.%s.%s" % (region, base["domain"]),
+//		"replicas": 3 if region.startswith("eu-") else 1,
+//	}
+//	`,
+//		xconf.ScriptLoaderWithEnv("AWS_REGION"),
+//		xconf.ScriptLoaderWithInput("base", xconf.YAMLFileLoader("base.yaml")),
+//		xconf.ScriptLoaderWithTimeout(500*time.Millisecond),
+//		xconf.ScriptLoaderWithMaxKeys(100),
+//	)
+//
+// Script's evaluation is limited in time (see [xconf.ScriptLoaderWithTimeout]) and in
+// execution steps (see [WithMaxExecutionSteps]).
+// Note: go.starlark.net does not account memory allocations, so the memory a script uses cannot
+// be strictly limited; it is bounded only indirectly, by the execution steps limit, and by
+// the engine's own cap on single allocations (like string / list repetition).
+// Result's size can be limited with [xconf.ScriptLoaderWithMaxKeys].
+package starlarkconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/actforgood/xconf"
+	"github.com/actforgood/xerr"
+	"go.starlark.net/starlark"
+)
+
+// ConfigGlobal is the global a script computes the configuration into.
+const ConfigGlobal = "config"
+
+// DefaultMaxExecutionSteps is the default maximum number of execution steps of a script.
+const DefaultMaxExecutionSteps = 1_000_000
+
+var (
+	// ErrTooManySteps is returned by [Evaluator] if script's evaluation
+	// exceeds the maximum number of execution steps.
+	ErrTooManySteps = errors.New("script exceeded max execution steps")
+	// ErrInvalidConfig is returned by [Evaluator] if script does not compute
+	// the configuration into a dict with string keys, under [ConfigGlobal] global,
+	// or if it contains values which cannot be converted to Go values.
+	ErrInvalidConfig = errors.New("script computed an invalid config")
+)
+
+// Evaluator is a [xconf.ScriptEvaluator] evaluating Starlark scripts.
+type Evaluator struct {
+	maxSteps uint64 // the maximum number of execution steps, 0 for no limit.
+}
+
+var _ xconf.ScriptEvaluator = Evaluator{} // Evaluator implements ScriptEvaluator.
+
+// NewEvaluator instantiates a new Starlark Evaluator object.
+// By default, a script's evaluation is limited to [DefaultMaxExecutionSteps] execution steps.
+func NewEvaluator(opts ...Option) Evaluator {
+	evaluator := Evaluator{
+		maxSteps: DefaultMaxExecutionSteps,
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(&evaluator)
+	}
+
+	return evaluator
+}
+
+// Eval executes the script, with given globals predeclared (and frozen),
+// and returns the configuration the script computed into [ConfigGlobal] global.
+// Evaluation is canceled once the context is done.
+// It implements [xconf.ScriptEvaluator].
+//
+// Note: Go values of types not having a Starlark correspondent are exposed as strings.
+func (evaluator Evaluator) Eval(
+	ctx context.Context,
+	script string,
+	globals map[string]any,
+) (map[string]any, error) {
+	predeclared := make(starlark.StringDict, len(globals))
+	for name, value := range globals {
+		predeclared[name] = toStarlarkValue(value)
+	}
+	predeclared.Freeze()
+
+	thread := &starlark.Thread{
+		Name:  "xconf",
+		Print: func(*starlark.Thread, string) {}, // discard output.
+		// Note: Load is not set, so scripts cannot load other modules.
+	}
+	if evaluator.maxSteps > 0 {
+		thread.SetMaxExecutionSteps(evaluator.maxSteps)
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			thread.Cancel(ctx.Err().Error())
+		case <-done:
+		}
+	}()
+
+	result, err := starlark.ExecFile(thread, "config.star", script, predeclared)
+	if err != nil {
+		if evaluator.maxSteps > 0 && thread.ExecutionSteps() >= evaluator.maxSteps {
+			return nil, xerr.Wrapf(ErrTooManySteps, "%v", err)
+		}
+
+		return nil, err
+	}
+
+	dict, ok := result[ConfigGlobal].(*starlark.Dict)
+	if !ok {
+		return nil, xerr.Wrapf(ErrInvalidConfig, "global %q must be a dict", ConfigGlobal)
+	}
+	configMap, err := fromStarlarkDict(dict)
+	if err != nil {
+		return nil, xerr.Wrapf(ErrInvalidConfig, "%v", err)
+	}
+
+	return configMap, nil
+}
+
+// Option defines optional function for configuring a Starlark Evaluator.
+type Option func(*Evaluator)
+
+// WithMaxExecutionSteps sets the maximum number of execution steps of a script
+// (roughly, the number of executed bytecode instructions).
+// If exceeded, [ErrTooManySteps] is returned. A value of 0 means no limit.
+// By default, is set to [DefaultMaxExecutionSteps].
+func WithMaxExecutionSteps(maxSteps uint64) Option {
+	return func(evaluator *Evaluator) {
+		evaluator.maxSteps = maxSteps
+	}
+}
+
+// toStarlarkValue converts a Go value into a Starlark value.
+// Values of types not having a Starlark correspondent are converted to strings.
+func toStarlarkValue(value any) starlark.Value {
+	switch v := value.(type) {
+	case nil:
+		return starlark.None
+	case string:
+		return starlark.String(v)
+	case bool:
+		return starlark.Bool(v)
+	case int:
+		return starlark.MakeInt(v)
+	case int8:
+		return starlark.MakeInt64(int64(v))
+	case int16:
+		return starlark.MakeInt64(int64(v))
+	case int32:
+		return starlark.MakeInt64(int64(v))
+	case int64:
+		return starlark.MakeInt64(v)
+	case uint:
+		return starlark.MakeUint(v)
+	case uint8:
+		return starlark.MakeUint64(uint64(v))
+	case uint16:
+		return starlark.MakeUint64(uint64(v))
+	case uint32:
+		return starlark.MakeUint64(uint64(v))
+	case uint64:
+		return starlark.MakeUint64(v)
+	case float32:
+		return starlark.Float(v)
+	case float64:
+		return starlark.Float(v)
+	case []any:
+		elems := make([]starlark.Value, len(v))
+		for idx, elem := range v {
+			elems[idx] = toStarlarkValue(elem)
+		}
+
+		return starlark.NewList(elems)
+	case []string:
+		elems := make([]starlark.Value, len(v))
+		for idx, elem := range v {
+			elems[idx] = starlark.String(elem)
+		}
+
+		return starlark.NewList(elems)
+	case map[string]any:
+		dict := starlark.NewDict(len(v))
+		for _, key := range sortedKeys(v) { // deterministic iteration order.
+			_ = dict.SetKey(starlark.String(key), toStarlarkValue(v[key]))
+		}
+
+		return dict
+	default:
+		return starlark.String(fmt.Sprint(v))
+	}
+}
+
+// fromStarlarkDict converts a Starlark dict with string keys into a Go map.
+func fromStarlarkDict(dict *starlark.Dict) (map[string]any, error) {
+	configMap := make(map[string]any, dict.Len())
+	for _, item := range dict.Items() {
+		key, ok := starlark.AsString(item[0])
+		if !ok {
+			return nil, fmt.Errorf("key %s is not a string", item[0])
+		}
+		value, err := fromStarlarkValue(item[1])
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", key, err)
+		}
+		configMap[key] = value
+	}
+
+	return configMap, nil
+}
+
+// fromStarlarkValue converts a Starlark value into a Go value.
+func fromStarlarkValue(value starlark.Value) (any, error) {
+	switch v := value.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.String:
+		return string(v), nil
+	case starlark.Bool:
+		return bool(v), nil
+	case starlark.Int:
+		i, ok := v.Int64()
+		if !ok || i < math.MinInt || i > math.MaxInt {
+			return nil, fmt.Errorf("int %s overflows", v)
+		}
+
+		return int(i), nil
+	case starlark.Float:
+		return float64(v), nil
+	case *starlark.Dict:
+		return fromStarlarkDict(v)
+	case starlark.Indexable: // list, tuple
+		list := make([]any, v.Len())
+		for idx := range list {
+			elem, err := fromStarlarkValue(v.Index(idx))
+			if err != nil {
+				return nil, err
+			}
+			list[idx] = elem
+		}
+
+		return list, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", value.Type())
+	}
+}
+
+// sortedKeys returns map's keys, sorted.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,189 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package starlarkconf_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+	"github.com/actforgood/xconf/starlarkconf"
+)
+
+func TestEvaluator(t *testing.T) {
+	// Note: do not run this test with t.Parallel() as it sets ENVs.
+	t.Setenv("XCONF_STARLARK_REGION", "eu-west-1")
+
+	t.Run("success - env and inputs are exposed", testEvaluatorWithScriptLoader)
+	t.Run("error - too many steps", testEvaluatorReturnsErrTooManySteps)
+	t.Run("error - timeout", testEvaluatorReturnsTimeoutErr)
+	t.Run("error - invalid config", testEvaluatorReturnsErrInvalidConfig)
+	t.Run("error - script", testEvaluatorReturnsScriptErr)
+}
+
+func testEvaluatorWithScriptLoader(t *testing.T) {
+	// arrange
+	subject := xconf.NewScriptLoader(
+		starlarkconf.NewEvaluator(),
+		`
+region = env.get("XCONF_STARLARK_REGION", "us-east-1")
+print("not visible")
+config = {
+	"api.endpoint": "https://api.%s.%s" % (region, base["domain"]),
+	"replicas": 3 if region.startswith("eu-") else 1,
+	"ratio": base["ratio"] * 2,
+	"zones": [region + suffix for suffix in base["zones"]],
+	"db": {"host": base["db"]["host"], "tls": True},
+	"home": env.get("HOME"),
+}
+`,
+		xconf.ScriptLoaderWithEnv("XCONF_STARLARK_REGION"),
+		xconf.ScriptLoaderWithInput("base", xconf.PlainLoader(map[string]any{
+			"domain": "example.com",
+			"ratio":  0.25,
+			"zones":  []any{"a", "b"},
+			"db":     map[string]any{"host": "10.0.0.1"},
+		})),
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	expectedConfig := map[string]any{
+		"api.endpoint": "https://api.eu-west-1.example.com",
+		"replicas":     3,
+		"ratio":        0.5,
+		"zones":        []any{"eu-west-1a", "eu-west-1b"},
+		"db":           map[string]any{"host": "10.0.0.1", "tls": true},
+		"home":         nil, // not allowed env is not exposed.
+	}
+	if !reflect.DeepEqual(expectedConfig, config) {
+		t.Errorf("expected %+v, but got %+v", expectedConfig, config)
+	}
+}
+
+func testEvaluatorReturnsErrTooManySteps(t *testing.T) {
+	// arrange
+	subject := xconf.NewScriptLoader(
+		starlarkconf.NewEvaluator(starlarkconf.WithMaxExecutionSteps(1000)),
+		`
+def compute():
+	total = 0
+	for i in range(1000000):
+		total += i
+	return total
+
+config = {"total": compute()}
+`,
+	)
+
+	// act
+	config, err := subject.Load()
+
+	// assert
+	if !errors.Is(err, starlarkconf.ErrTooManySteps) {
+		t.Errorf("expected %v, but got %v", starlarkconf.ErrTooManySteps, err)
+	}
+	if config != nil {
+		t.Errorf("expected nil config, but got %+v", config)
+	}
+}
+
+func testEvaluatorReturnsTimeoutErr(t *testing.T) {
+	// arrange
+	subject := xconf.NewScriptLoader(
+		starlarkconf.NewEvaluator(starlarkconf.WithMaxExecutionSteps(0)),
+		`
+def forever():
+	for i in range(1 << 62):
+		pass
+
+config = {"never": forever()}
+`,
+		xconf.ScriptLoaderWithTimeout(50*time.Millisecond),
+	)
+
+	// act
+	config, err := subject.LoadContext(context.Background())
+
+	// assert
+	if !errors.Is(err, xconf.ErrScriptTimeout) {
+		t.Errorf("expected %v, but got %v", xconf.ErrScriptTimeout, err)
+	}
+	if config != nil {
+		t.Errorf("expected nil config, but got %+v", config)
+	}
+}
+
+func testEvaluatorReturnsErrInvalidConfig(t *testing.T) {
+	tests := [...]struct {
+		name   string
+		script string
+	}{
+		{name: "missing", script: `cfg = {"foo": "bar"}`},
+		{name: "not a dict", script: `config = ["foo", "bar"]`},
+		{name: "not a string key", script: `config = {1: "bar"}`},
+		{name: "unsupported value", script: `config = {"foo": len}`},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			// arrange
+			subject := xconf.NewScriptLoader(starlarkconf.NewEvaluator(), test.script)
+
+			// act
+			config, err := subject.Load()
+
+			// assert
+			if !errors.Is(err, starlarkconf.ErrInvalidConfig) {
+				t.Errorf("expected %v, but got %v", starlarkconf.ErrInvalidConfig, err)
+			}
+			if config != nil {
+				t.Errorf("expected nil config, but got %+v", config)
+			}
+		})
+	}
+}
+
+func testEvaluatorReturnsScriptErr(t *testing.T) {
+	tests := [...]struct {
+		name          string
+		script        string
+		expectedInErr string
+	}{
+		{name: "syntax", script: `config = {`, expectedInErr: "config.star"},
+		{name: "load is not allowed", script: `load("other.star", "x")`, expectedInErr: "load"},
+		{name: "frozen globals", script: `env["FOO"] = "bar"`, expectedInErr: "frozen"},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			// arrange
+			subject := xconf.NewScriptLoader(starlarkconf.NewEvaluator(), test.script)
+
+			// act
+			config, err := subject.Load()
+
+			// assert
+			if err == nil || !strings.Contains(err.Error(), test.expectedInErr) {
+				t.Errorf("expected error containing %q, but got %v", test.expectedInErr, err)
+			}
+			if config != nil {
+				t.Errorf("expected nil config, but got %+v", config)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// Switch is an on/off flag driven by a boolean configuration key,
+// like "maintenance.enabled".
+//
+// If the config is a [DefaultConfig], the Switch registers itself as an observer,
+// so, if reload is enabled, its state follows the configuration without restarting the app.
+// For other Config implementations, call OnConfigChange yourself.
+type Switch struct {
+	// key is the boolean configuration key.
+	key string
+	// on holds the current state.
+	on int32
+	// changes is the channel on which the new state is sent when it changes.
+	changes chan bool
+}
+
+// NewSwitch instantiates a new Switch object, reading its initial state
+// from given config's key.
+// A missing key means the switch is off.
+func NewSwitch(config Config, key string) *Switch {
+	sw := &Switch{
+		key:     key,
+		changes: make(chan bool, 1),
+	}
+	sw.on = sw.read(config)
+	if observable, ok := config.(interface {
+		RegisterObserver(ConfigObserver) ObserverHandle
+	}); ok {
+		observable.RegisterObserver(sw.OnConfigChange)
+	}
+
+	return sw
+}
+
+// On returns true if the switch is on.
+func (sw *Switch) On() bool {
+	return atomic.LoadInt32(&sw.on) == 1
+}
+
+// Changes returns a channel on which the new state is sent, each time it changes.
+// The channel holds only the latest state, a slow consumer misses intermediate ones.
+func (sw *Switch) Changes() <-chan bool {
+	return sw.changes
+}
+
+// OnConfigChange is a [ConfigObserver] that updates the state if switch's key changed.
+func (sw *Switch) OnConfigChange(config Config, changedKeys ...string) {
+	for _, changedKey := range changedKeys {
+		if !strings.EqualFold(changedKey, sw.key) {
+			continue
+		}
+		newState := sw.read(config)
+		if atomic.SwapInt32(&sw.on, newState) != newState {
+			sw.notify(newState == 1)
+		}
+
+		return
+	}
+}
+
+// Handler decorates a handler to respond with 503 Service Unavailable
+// while the switch is on.
+//
+// Example:
+//
+//	maintenance := xconf.NewSwitch(config, "maintenance.enabled")
+//	srv := &http.Server{Handler: maintenance.Handler(mux)}
+func (sw *Switch) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sw.On() {
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// read returns the state read from config.
+func (sw *Switch) read(config Config) int32 {
+	if config.Get(sw.key, false).(bool) {
+		return 1
+	}
+
+	return 0
+}
+
+// notify sends the new state on changes channel, replacing a not consumed one.
+func (sw *Switch) notify(on bool) {
+	for {
+		select {
+		case sw.changes <- on:
+			return
+		default:
+			select {
+			case <-sw.changes:
+			default:
+			}
+		}
+	}
+}
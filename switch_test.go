@@ -0,0 +1,117 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestSwitch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("observer", testSwitchOnConfigChange)
+	t.Run("auto registered on DefaultConfig", testSwitchWithDefaultConfig)
+	t.Run("handler", testSwitchHandler)
+}
+
+func testSwitchOnConfigChange(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config := xconf.NewMockConfig("maintenance.enabled", "false")
+	subject := xconf.NewSwitch(config, "maintenance.enabled")
+
+	// act & assert
+	assertTrue(t, !subject.On())
+
+	config.SetKeyValues("maintenance.enabled", true)
+	subject.OnConfigChange(config, "some_other_key")
+	assertTrue(t, !subject.On())
+
+	subject.OnConfigChange(config, "some_other_key", "MAINTENANCE.ENABLED")
+	assertTrue(t, subject.On())
+	select {
+	case on := <-subject.Changes():
+		assertTrue(t, on)
+	default:
+		t.Error("expected a change to be sent")
+	}
+
+	config.SetKeyValues("maintenance.enabled", false)
+	subject.OnConfigChange(config, "maintenance.enabled")
+	config.SetKeyValues("maintenance.enabled", true)
+	subject.OnConfigChange(config, "maintenance.enabled")
+	subject.OnConfigChange(config, "maintenance.enabled") // no change
+	assertTrue(t, subject.On())
+	assertEqual(t, 1, len(subject.Changes())) // only latest state is kept
+	assertTrue(t, <-subject.Changes())
+}
+
+func testSwitchWithDefaultConfig(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var enabled int32
+	loader := xconf.LoaderFunc(func() (map[string]any, error) {
+		return map[string]any{"maintenance.enabled": atomic.LoadInt32(&enabled) == 1}, nil
+	})
+	config, err := xconf.NewDefaultConfig(
+		loader,
+		xconf.DefaultConfigWithReloadInterval(10*time.Millisecond),
+	)
+	requireNil(t, err)
+	defer config.Close()
+	subject := xconf.NewSwitch(config, "maintenance.enabled")
+	assertTrue(t, !subject.On())
+
+	// act
+	atomic.StoreInt32(&enabled, 1)
+
+	// assert
+	select {
+	case on := <-subject.Changes():
+		assertTrue(t, on)
+		assertTrue(t, subject.On())
+	case <-time.After(time.Second):
+		t.Error("timeout waiting for switch to turn on")
+	}
+}
+
+func testSwitchHandler(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config := xconf.NewMockConfig("maintenance.enabled", true)
+	sw := xconf.NewSwitch(config, "maintenance.enabled")
+	subject := sw.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	// act
+	rec := httptest.NewRecorder()
+	subject.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// assert
+	assertEqual(t, http.StatusServiceUnavailable, rec.Code)
+
+	// arrange
+	config.SetKeyValues("maintenance.enabled", false)
+	sw.OnConfigChange(config, "maintenance.enabled")
+
+	// act
+	rec = httptest.NewRecorder()
+	subject.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// assert
+	assertEqual(t, http.StatusOK, rec.Code)
+	assertEqual(t, "ok", rec.Body.String())
+}
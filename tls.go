@@ -0,0 +1,60 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// loadTLSFiles sets on given TLS configuration the root CAs read from caFile,
+// and the client certificate read from certFile and keyFile, if provided.
+func loadTLSFiles(tlsConfig *tls.Config, caFile, certFile, keyFile string) error {
+	if caFile != "" {
+		caPEM, err := os.ReadFile(caFile)
+		if err != nil {
+			return err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caPEM) {
+			return fmt.Errorf("no valid certificate found in %q", caFile)
+		}
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return nil
+}
+
+// withTLSConfig returns a copy of given http client, whose transport uses given TLS configuration.
+// A nil transport is replaced with a clone of [http.DefaultTransport].
+// An error is returned if client's transport is not a *[http.Transport],
+// as TLS configuration cannot be applied on a custom [http.RoundTripper].
+func withTLSConfig(client *http.Client, tlsConfig *tls.Config) (*http.Client, error) {
+	var transport *http.Transport
+	switch clientTransport := client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = clientTransport.Clone()
+	default:
+		return nil, fmt.Errorf("cannot apply TLS configuration on http client's transport of type %T", clientTransport)
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	tlsClient := *client
+	tlsClient.Transport = transport
+
+	return &tlsClient, nil
+}
@@ -0,0 +1,190 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/actforgood/xerr"
+)
+
+// KeyTypes describes the inferred Go type of a configuration key.
+type KeyTypes struct {
+	// Key is the configuration key.
+	Key string
+	// Type is the Go type of the key's effective value (the one from the
+	// last source the key was found in).
+	Type string
+	// SourceTypes are the Go types of the key's value in each source,
+	// in the order sources were provided. An empty string means the key
+	// was not found in that source.
+	SourceTypes []string
+	// Inconsistent indicates whether the key's value has different kinds
+	// of types across sources (for example, string in env and int in a YAML file).
+	// Different numeric types (like int and float64) are not considered inconsistent,
+	// neither are nil values.
+	Inconsistent bool
+}
+
+// String returns string representation of the KeyTypes.
+// Example: "db.port: int (#0: string, #1: int) INCONSISTENT".
+func (keyTypes KeyTypes) String() string {
+	var sb strings.Builder
+	sb.WriteString(keyTypes.Key)
+	sb.WriteString(": ")
+	sb.WriteString(keyTypes.Type)
+	if len(keyTypes.SourceTypes) > 1 {
+		sb.WriteString(" (")
+		first := true
+		for idx, sourceType := range keyTypes.SourceTypes {
+			if sourceType == "" {
+				continue
+			}
+			if !first {
+				sb.WriteString(", ")
+			}
+			first = false
+			sb.WriteString(fmt.Sprintf("#%d: %s", idx, sourceType))
+		}
+		sb.WriteByte(')')
+	}
+	if keyTypes.Inconsistent {
+		sb.WriteString(" INCONSISTENT")
+	}
+
+	return sb.String()
+}
+
+// TypesReport is the list of configuration keys' inferred types, sorted by key.
+type TypesReport []KeyTypes
+
+// Inconsistencies returns only the keys with inconsistent types across sources.
+func (report TypesReport) Inconsistencies() TypesReport {
+	inconsistencies := make(TypesReport, 0)
+	for _, keyTypes := range report {
+		if keyTypes.Inconsistent {
+			inconsistencies = append(inconsistencies, keyTypes)
+		}
+	}
+
+	return inconsistencies
+}
+
+// String returns string representation of the TypesReport, one key per line.
+func (report TypesReport) String() string {
+	var sb strings.Builder
+	for idx, keyTypes := range report {
+		if idx > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(keyTypes.String())
+	}
+
+	return sb.String()
+}
+
+// ReportTypes loads configuration from given sources and reports the inferred
+// Go type of every key, and the keys whose types drift across sources.
+// Sources should be given in precedence order (like for a [MultiLoader] with key overwrite allowed),
+// the last source a key is found in giving the key's effective type.
+// It helps cleaning up type drift before enabling strict validation.
+//
+// Example:
+//
+//	report, err := xconf.ReportTypes(
+//		xconf.YAMLFileLoader("config.yaml"),
+//		xconf.EnvLoader(),
+//	)
+//	if err != nil {
+//		// handle error
+//	}
+//	fmt.Println(report.Inconsistencies())
+func ReportTypes(sources ...Loader) (TypesReport, error) {
+	var (
+		mErr     *xerr.MultiError
+		keyTypes = make(map[string]*KeyTypes)
+	)
+	for idx, source := range sources {
+		configMap, err := safeLoad(context.Background(), source)
+		if err != nil {
+			mErr = mErr.Add(err)
+
+			continue
+		}
+		for key, value := range configMap {
+			types, found := keyTypes[key]
+			if !found {
+				types = &KeyTypes{
+					Key:         key,
+					SourceTypes: make([]string, len(sources)),
+				}
+				keyTypes[key] = types
+			}
+			types.Type = typeName(value)
+			types.SourceTypes[idx] = types.Type
+		}
+	}
+	if err := mErr.ErrOrNil(); err != nil {
+		return nil, err
+	}
+
+	report := make(TypesReport, 0, len(keyTypes))
+	for _, types := range keyTypes {
+		types.Inconsistent = hasTypeDrift(types.SourceTypes)
+		report = append(report, *types)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].Key < report[j].Key
+	})
+
+	return report, nil
+}
+
+// typeName returns the Go type name of a value.
+func typeName(value any) string {
+	if value == nil {
+		return "nil"
+	}
+
+	return fmt.Sprintf("%T", value)
+}
+
+// hasTypeDrift checks whether given types have different kinds.
+func hasTypeDrift(types []string) bool {
+	var firstKind string
+	for _, typ := range types {
+		kind := typeKind(typ)
+		if kind == "" {
+			continue
+		}
+		if firstKind == "" {
+			firstKind = kind
+		} else if kind != firstKind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// typeKind returns the kind of given type name,
+// numeric types being considered of the same kind.
+// An empty string is returned for missing / nil types.
+func typeKind(typ string) string {
+	switch typ {
+	case "", "nil":
+		return ""
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64":
+		return "number"
+	}
+
+	return typ
+}
@@ -0,0 +1,96 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xconf/blob/main/LICENSE.
+
+package xconf_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/actforgood/xconf"
+)
+
+func TestReportTypes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - types and inconsistencies", testReportTypesSuccess)
+	t.Run("error - source", testReportTypesReturnsErrFromSource)
+}
+
+func testReportTypesSuccess(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		yamlSource = xconf.PlainLoader(map[string]any{
+			"port":    8080,
+			"ratio":   0.5,
+			"debug":   true,
+			"hosts":   []any{"a", "b"},
+			"timeout": nil,
+		})
+		envSource = xconf.PlainLoader(map[string]any{
+			"port":    "8081",
+			"ratio":   1,
+			"timeout": "5s",
+			"name":    "app",
+		})
+	)
+
+	// act
+	report, err := xconf.ReportTypes(yamlSource, envSource)
+
+	// assert
+	requireNil(t, err)
+	assertEqual(
+		t,
+		xconf.TypesReport{
+			{Key: "debug", Type: "bool", SourceTypes: []string{"bool", ""}},
+			{Key: "hosts", Type: "[]interface {}", SourceTypes: []string{"[]interface {}", ""}},
+			{Key: "name", Type: "string", SourceTypes: []string{"", "string"}},
+			{Key: "port", Type: "string", SourceTypes: []string{"int", "string"}, Inconsistent: true},
+			{Key: "ratio", Type: "int", SourceTypes: []string{"float64", "int"}},
+			{Key: "timeout", Type: "string", SourceTypes: []string{"nil", "string"}},
+		},
+		report,
+	)
+	assertEqual(t, 1, len(report.Inconsistencies()))
+	assertEqual(t, "port: string (#0: int, #1: string) INCONSISTENT", report.Inconsistencies().String())
+}
+
+func testReportTypesReturnsErrFromSource(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered source error")
+		source      = xconf.LoaderFunc(func() (map[string]any, error) {
+			return nil, expectedErr
+		})
+	)
+
+	// act
+	report, err := xconf.ReportTypes(xconf.PlainLoader(map[string]any{"foo": "bar"}), source)
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertNil(t, report)
+}
+
+func ExampleReportTypes() {
+	report, err := xconf.ReportTypes(
+		xconf.PlainLoader(map[string]any{"APP_PORT": 8080, "APP_DEBUG": true}), // ex: from a YAML file
+		xconf.PlainLoader(map[string]any{"APP_PORT": "8081"}),                  // ex: from env
+	)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(report)
+
+	// Output:
+	// APP_DEBUG: bool (#0: bool)
+	// APP_PORT: string (#0: int, #1: string) INCONSISTENT
+}
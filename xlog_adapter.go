@@ -6,6 +6,10 @@
 package xconf
 
 import (
+	"path"
+	"strings"
+	"sync"
+
 	"github.com/actforgood/xlog"
 )
 
@@ -52,3 +56,187 @@ func LogErrorHandler(loggerGetter func() xlog.Logger) func(error) {
 		)
 	}
 }
+
+// XlogLogger adapts a xlog.Logger to the [Logger] contract, so that it can be used
+// with [DefaultConfigWithLogger]. The message is logged under xlog.MessageKey.
+func XlogLogger(logger xlog.Logger) Logger {
+	return xlogLogger{logger: logger}
+}
+
+// xlogLogger is a xlog.Logger adapter to [Logger].
+type xlogLogger struct {
+	logger xlog.Logger
+}
+
+// Info logs an informational message. It implements [Logger].
+func (adapter xlogLogger) Info(msg string, keyValues ...any) {
+	adapter.logger.Info(append([]any{xlog.MessageKey, msg}, keyValues...)...)
+}
+
+// Error logs an error message. It implements [Logger].
+func (adapter xlogLogger) Error(msg string, keyValues ...any) {
+	for idx := 1; idx < len(keyValues); idx += 2 {
+		if err, isErr := keyValues[idx].(error); isErr && keyValues[idx-1] == "error" {
+			keyValues = append([]any(nil), keyValues...) // do not alter caller's arguments.
+			keyValues[idx-1], keyValues[idx] = xlog.ErrorKey, xlog.StackErr(err)
+
+			break
+		}
+	}
+	adapter.logger.Error(append([]any{xlog.MessageKey, msg}, keyValues...)...)
+}
+
+// DeprecationActionLog returns a [DeprecationAction] which logs (with WARN level)
+// reads of keys past their removal version, once per key.
+func DeprecationActionLog(logger xlog.Logger) DeprecationAction {
+	var loggedKeys sync.Map
+
+	return func(usage DeprecatedKeyUsage) error {
+		if _, logged := loggedKeys.LoadOrStore(usage.Key, struct{}{}); !logged {
+			logger.Warn(
+				xlog.MessageKey, "[xconf] key is past its removal version",
+				"key", usage.Key,
+				"removalVersion", usage.Meta.RemovalVersion,
+				"currentVersion", usage.CurrentVersion,
+				"note", usage.Meta.DeprecationNote,
+			)
+		}
+
+		return nil
+	}
+}
+
+// DefaultRedactionPatterns are the key patterns used by [LogEffectiveConfig]
+// to mask values, if no other patterns are provided.
+var DefaultRedactionPatterns = []string{
+	"*password*",
+	"*passwd*",
+	"*secret*",
+	"*token*",
+	"*apikey*",
+	"*api_key*",
+	"*private_key*",
+	"*credential*",
+}
+
+// redactedValue is the value that replaces a masked one.
+const redactedValue = "*****"
+
+// LogEffectiveConfig logs (with INFO level) the whole effective configuration
+// of a Config object, under "config" key. It's meant to be called once,
+// at application's startup.
+// Values of keys matching (case-insensitive) any of the redaction patterns
+// are masked. Patterns follow [path.Match] syntax, like "*password*".
+// If no pattern is provided, [DefaultRedactionPatterns] are used.
+// Values of keys marked as sensitive through keys' metadata (see [KeyMeta]) are masked, too.
+// If config's loader knows keys' provenance (implements [KeySourcer], like [Precedence]),
+// the sources of the (first level) keys are logged, too, under "sources" key (a key to source map).
+//
+// Config must be a [DefaultConfig] (or a [MockConfig]), otherwise nothing is logged.
+func LogEffectiveConfig(config Config, logger xlog.Logger, redactionPatterns ...string) {
+	snapshotter, ok := config.(configMapSnapshotter)
+	if !ok {
+		return
+	}
+	if len(redactionPatterns) == 0 {
+		redactionPatterns = DefaultRedactionPatterns
+	}
+	configMap := snapshotter.configMapSnapshot()
+	if masker, ok := config.(secretsMasker); ok {
+		masker.maskSecrets(configMap)
+	}
+	redactConfigMap(configMap, redactionPatterns)
+	if provider, ok := config.(keyMetaProvider); ok {
+		redactSensitiveKeys(configMap, provider)
+	}
+
+	keyValues := []any{
+		xlog.MessageKey, "[xconf] effective configuration",
+		"config", configMap,
+	}
+	if provider, ok := config.(keySourcesProvider); ok {
+		if sources := provider.keySources(configMap); len(sources) > 0 {
+			keyValues = append(keyValues, "sources", sources)
+		}
+	}
+
+	logger.Info(keyValues...)
+}
+
+// keySourcesProvider is implemented by configs able to provide the sources keys were loaded from.
+type keySourcesProvider interface {
+	keySources(configMap map[string]any) map[string]string
+}
+
+// keySources returns the known sources of given configuration map's keys,
+// if config's loader implements [KeySourcer].
+func (cfg *defaultConfig) keySources(configMap map[string]any) map[string]string {
+	sourcer, ok := cfg.loader.(KeySourcer)
+	if !ok {
+		return nil
+	}
+	sources := make(map[string]string, len(configMap))
+	for key := range configMap {
+		if source, found := sourcer.KeySource(key); found {
+			sources[key] = source
+		}
+	}
+
+	return sources
+}
+
+// redactConfigMap masks (in place) values of keys matching any of the patterns.
+// Nested maps are traversed, their keys being matched also by their path
+// (nested keys joined with "."), like "db.password".
+func redactConfigMap(configMap map[string]any, patterns []string) {
+	redactNestedConfigMap(configMap, "", patterns)
+}
+
+// redactNestedConfigMap masks (in place) values of keys matching any of the patterns,
+// prefix being the path of the configuration map.
+func redactNestedConfigMap(configMap map[string]any, prefix string, patterns []string) {
+	for key, value := range configMap {
+		if isRedactedKey(key, patterns) || (prefix != "" && isRedactedKey(prefix+key, patterns)) {
+			configMap[key] = redactedValue
+
+			continue
+		}
+		redactNestedValue(value, prefix+key+".", patterns)
+	}
+}
+
+// redactNestedValue masks (in place) values of keys matching any of the patterns, found
+// in a nested map, or in the maps of a list, prefix being the path of the value.
+// List's elements share the path of the list (like "databases.password" for "databases: [{password: p}]").
+func redactNestedValue(value any, prefix string, patterns []string) {
+	switch val := value.(type) {
+	case map[string]any:
+		redactNestedConfigMap(val, prefix, patterns)
+	case map[any]any:
+		for nestedKey, nestedValue := range val {
+			strNestedKey, isStr := nestedKey.(string)
+			if isStr && (isRedactedKey(strNestedKey, patterns) || isRedactedKey(prefix+strNestedKey, patterns)) {
+				val[nestedKey] = redactedValue
+
+				continue
+			}
+			redactNestedValue(nestedValue, prefix+strNestedKey+".", patterns)
+		}
+	case []any:
+		for _, elem := range val {
+			redactNestedValue(elem, prefix, patterns)
+		}
+	}
+}
+
+// isRedactedKey checks if a key matches (case-insensitive) any of the patterns.
+func isRedactedKey(key string, patterns []string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(strings.ToLower(pattern), key); matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -172,3 +172,207 @@ func TestLogErrorHandler(t *testing.T) {
 	// assert
 	assertEqual(t, 1, logger.LogCallsCount(xlog.LevelError))
 }
+
+func TestXlogLogger(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger  = xlog.NewMockLogger()
+		subject = xconf.XlogLogger(logger)
+		err     = errors.New("reload test error")
+	)
+	defer logger.Close()
+	logger.SetLogCallback(xlog.LevelInfo, func(keyValues ...any) {
+		assertEqual(t, []any{xlog.MessageKey, "[xconf] configuration changed", "changedKeys", []string{"foo"}}, keyValues)
+	})
+	logger.SetLogCallback(xlog.LevelError, func(keyValues ...any) {
+		if assertEqual(t, 6, len(keyValues)) {
+			assertEqual(t, xlog.MessageKey, keyValues[0])
+			assertEqual(t, "[xconf] could not reload configuration", keyValues[1])
+			assertEqual(t, xlog.ErrorKey, keyValues[2])
+			if errMsg, ok := keyValues[3].(string); assertTrue(t, ok) {
+				assertTrue(t, strings.Contains(errMsg, err.Error()))
+			}
+			assertEqual(t, "duration", keyValues[4])
+		}
+	})
+	errKeyValues := []any{"error", err, "duration", time.Second}
+
+	// act
+	subject.Info("[xconf] configuration changed", "changedKeys", []string{"foo"})
+	subject.Error("[xconf] could not reload configuration", errKeyValues...)
+
+	// assert
+	assertEqual(t, 1, logger.LogCallsCount(xlog.LevelInfo))
+	assertEqual(t, 1, logger.LogCallsCount(xlog.LevelError))
+	assertEqual(t, []any{"error", err, "duration", time.Second}, errKeyValues) // not altered.
+}
+
+func TestLogEffectiveConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("default redaction patterns", testLogEffectiveConfigWithDefaultPatterns)
+	t.Run("custom redaction patterns", testLogEffectiveConfigWithCustomPatterns)
+	t.Run("sensitive keys metadata", testLogEffectiveConfigWithSensitiveKeysMetadata)
+	t.Run("keys' sources", testLogEffectiveConfigWithKeySources)
+	t.Run("unsupported config - nothing is logged", testLogEffectiveConfigWithUnsupportedConfig)
+}
+
+func testLogEffectiveConfigWithDefaultPatterns(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loader = xconf.PlainLoader(map[string]any{
+			"APP_NAME":    "demo",
+			"DB_PASSWORD": "s3cr3t",
+			"db": map[string]any{
+				"host":         "127.0.0.1",
+				"Access_Token": "abc",
+			},
+		})
+		config, _      = xconf.NewDefaultConfig(loader)
+		logger         = xlog.NewMockLogger()
+		expectedConfig = map[string]any{
+			"APP_NAME":    "demo",
+			"DB_PASSWORD": "*****",
+			"db": map[string]any{
+				"host":         "127.0.0.1",
+				"Access_Token": "*****",
+			},
+		}
+	)
+	defer logger.Close()
+	logger.SetLogCallback(xlog.LevelInfo, func(keyValues ...any) {
+		if assertEqual(t, 4, len(keyValues)) {
+			assertEqual(t, xlog.MessageKey, keyValues[0])
+			assertEqual(t, "[xconf] effective configuration", keyValues[1])
+			assertEqual(t, "config", keyValues[2])
+			assertEqual(t, expectedConfig, keyValues[3])
+		}
+	})
+
+	// act
+	xconf.LogEffectiveConfig(config, logger)
+
+	// assert
+	assertEqual(t, 1, logger.LogCallsCount(xlog.LevelInfo))
+	assertEqual(t, "s3cr3t", config.Get("DB_PASSWORD")) // original config is not altered
+}
+
+func testLogEffectiveConfigWithCustomPatterns(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		config = xconf.NewMockConfig(
+			"db.dsn", "user:pass@tcp(localhost:3306)/db",
+			"db.password", "s3cr3t",
+		)
+		logger         = xlog.NewMockLogger()
+		expectedConfig = map[string]any{
+			"db.dsn":      "*****",
+			"db.password": "s3cr3t",
+		}
+	)
+	defer logger.Close()
+	logger.SetLogCallback(xlog.LevelInfo, func(keyValues ...any) {
+		if assertEqual(t, 4, len(keyValues)) {
+			assertEqual(t, expectedConfig, keyValues[3])
+		}
+	})
+
+	// act
+	xconf.LogEffectiveConfig(config, logger, "*.DSN")
+
+	// assert
+	assertEqual(t, 1, logger.LogCallsCount(xlog.LevelInfo))
+}
+
+func testLogEffectiveConfigWithSensitiveKeysMetadata(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loader = xconf.PlainLoader(map[string]any{
+			"app.name":   "demo",
+			"stripe.key": "sk_live_123",
+		})
+		config, _ = xconf.NewDefaultConfig(
+			loader,
+			xconf.DefaultConfigWithKeysMetadata(xconf.KeysMetadata{
+				"stripe.*": {Sensitive: true},
+			}),
+		)
+		logger         = xlog.NewMockLogger()
+		expectedConfig = map[string]any{
+			"app.name":   "demo",
+			"stripe.key": "*****",
+		}
+	)
+	defer logger.Close()
+	logger.SetLogCallback(xlog.LevelInfo, func(keyValues ...any) {
+		if assertEqual(t, 4, len(keyValues)) {
+			assertEqual(t, expectedConfig, keyValues[3])
+		}
+	})
+
+	// act
+	xconf.LogEffectiveConfig(config, logger)
+
+	// assert
+	assertEqual(t, 1, logger.LogCallsCount(xlog.LevelInfo))
+}
+
+func testLogEffectiveConfigWithKeySources(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loader = xconf.NewPrecedence([]xconf.PrecedenceLayer{
+			{Label: "defaults", Loader: xconf.PlainLoader(map[string]any{"port": 80, "host": "localhost"})},
+			{Label: "env", Loader: xconf.PlainLoader(map[string]any{"port": 8080, "api_token": "abc"})},
+		})
+		config, _      = xconf.NewDefaultConfig(loader)
+		logger         = xlog.NewMockLogger()
+		expectedConfig = map[string]any{
+			"port":      8080,
+			"host":      "localhost",
+			"api_token": "*****",
+		}
+		expectedSources = map[string]string{
+			"port":      "env",
+			"host":      "defaults",
+			"api_token": "env",
+		}
+	)
+	defer logger.Close()
+	logger.SetLogCallback(xlog.LevelInfo, func(keyValues ...any) {
+		if assertEqual(t, 6, len(keyValues)) {
+			assertEqual(t, expectedConfig, keyValues[3])
+			assertEqual(t, "sources", keyValues[4])
+			assertEqual(t, expectedSources, keyValues[5])
+		}
+	})
+
+	// act
+	xconf.LogEffectiveConfig(config, logger)
+
+	// assert
+	assertEqual(t, 1, logger.LogCallsCount(xlog.LevelInfo))
+}
+
+func testLogEffectiveConfigWithUnsupportedConfig(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	logger := xlog.NewMockLogger()
+	defer logger.Close()
+
+	// act
+	xconf.LogEffectiveConfig(xconf.NopConfig{}, logger)
+
+	// assert
+	assertEqual(t, 0, logger.LogCallsCount(xlog.LevelInfo))
+}